@@ -57,6 +57,39 @@ func main() {
 	if app.DayRotationService != nil {
 		go app.DayRotationService.StartScheduler(rotationCtx)
 	}
+	if app.DamSafetyService != nil {
+		go app.DamSafetyService.StartScheduler(rotationCtx)
+	}
+	if app.ExecControlService != nil {
+		go app.ExecControlService.StartScheduler(rotationCtx)
+	}
+	if app.FullTextSearchService != nil {
+		go app.FullTextSearchService.StartIndexer(rotationCtx)
+	}
+	if app.FileLifecycleService != nil {
+		go app.FileLifecycleService.StartScheduler(rotationCtx)
+	}
+	if app.UploadSessionService != nil {
+		go app.UploadSessionService.StartScheduler(rotationCtx)
+	}
+	if app.NotificationService != nil {
+		go app.NotificationService.StartScheduler(rotationCtx)
+	}
+	if app.HRMTimesheetService != nil {
+		go app.HRMTimesheetService.StartScheduler(rotationCtx)
+	}
+	if app.HRMVacationService != nil {
+		go app.HRMVacationService.StartScheduler(rotationCtx)
+	}
+	if app.HRMLifecycleService != nil {
+		go app.HRMLifecycleService.StartScheduler(rotationCtx)
+	}
+	if app.HRMTrainingService != nil {
+		go app.HRMTrainingService.StartScheduler(rotationCtx)
+	}
+	if app.TelegramBot != nil {
+		go app.TelegramBot.Start(rotationCtx)
+	}
 
 	// Start HTTP server with graceful shutdown
 	log.Info("starting http server", "address", app.Config.HttpServer.Address)
@@ -33,6 +33,13 @@ type Config struct {
 	LexParser      `yaml:"lex_parser"`
 	Redis          `yaml:"redis"`
 	ASUTP          `yaml:"asutp"`
+	DocumentSigning `yaml:"document_signing"`
+	FileStorage    `yaml:"file_storage"`
+	ResumableUpload `yaml:"resumable_upload"`
+	Notifications  `yaml:"notifications"`
+	SMTP           `yaml:"smtp"`
+	Telegram       `yaml:"telegram"`
+	AccessControl  `yaml:"access_control"`
 	ModsnowToken   string `yaml:"modsnow_token" env-required:"true"`
 }
 
@@ -99,6 +106,75 @@ type ASUTP struct {
 	TTL   int    `yaml:"ttl_seconds" env-default:"300"`
 }
 
+// DocumentSigning configures the seals of document signatures. With SealKey
+// set, seal digests are keyed with HMAC-SHA256 so they cannot be recomputed
+// from the database alone; changing the key makes older seals unverifiable.
+type DocumentSigning struct {
+	SealKey string `yaml:"seal_key" env-default:""`
+}
+
+// FileStorage configures the scanning of uploads: "none", "stub" (flags the
+// EICAR test file, for local use) or "clamav", which streams uploads to the
+// clamd daemon at ClamdAddress (host:port or unix socket path).
+type FileStorage struct {
+	Scanner      string `yaml:"scanner" env-default:"none"`
+	ClamdAddress string `yaml:"clamd_address" env-default:""`
+}
+
+// ResumableUpload limits uploads sent in chunks: MaxSize caps the file size
+// in bytes, and sessions idle for SessionTTL are aborted.
+type ResumableUpload struct {
+	MaxSize    int64         `yaml:"max_size" env-default:"10737418240"`
+	SessionTTL time.Duration `yaml:"session_ttl" env-default:"24h"`
+}
+
+// Notifications configures the delivery of email and Telegram notifications.
+// PublicURL, the address of the web app, turns notification links into
+// absolute ones. Failed deliveries are retried with a growing delay until
+// MaxAttempts.
+type Notifications struct {
+	PublicURL    string        `yaml:"public_url" env-default:""`
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"30s"`
+	MaxAttempts  int           `yaml:"max_attempts" env-default:"8"`
+}
+
+// SMTP is the mail server of email notifications; leave Host empty to turn
+// email off. Port 465 uses implicit TLS, other ports STARTTLS when offered.
+type SMTP struct {
+	Host     string `yaml:"host" env-default:""`
+	Port     int    `yaml:"port" env-default:"587"`
+	Username string `yaml:"username" env-default:""`
+	Password string `yaml:"password" env-default:""`
+	From     string `yaml:"from" env-default:""`
+}
+
+// Telegram is the bot that sends notifications and answers commands; leave
+// APIKey empty to turn Telegram off. APIURL points to a self-hosted Bot API
+// server. Mode "polling" works from one instance only; set "webhook" with
+// WebhookURL and WebhookSecret when several instances run. The morning GES
+// report goes to ReportRoles at ReportTime; empty ReportTime turns it off.
+type Telegram struct {
+	APIKey        string        `yaml:"api_key" env-default:""`
+	APIURL        string        `yaml:"api_url" env-default:""`
+	BotUsername   string        `yaml:"bot_username" env-default:""`
+	Mode          string        `yaml:"mode" env-default:"polling"`
+	WebhookURL    string        `yaml:"webhook_url" env-default:""`
+	WebhookSecret string        `yaml:"webhook_secret" env-default:""`
+	PollTimeout   time.Duration `yaml:"poll_timeout" env-default:"25s"`
+	LinkCodeTTL   time.Duration `yaml:"link_code_ttl" env-default:"10m"`
+	ReportTime    string        `yaml:"report_time" env-default:"08:00"`
+	ReportRoles   []string      `yaml:"report_roles" env-default:"sc,rais"`
+}
+
+// AccessControl is the ingestion of turnstile and door controller events;
+// controllers send APIKey in X-API-Key, and leaving it empty turns the
+// ingestion off. An employee still inside a zone after PresenceTTL is taken
+// to have left without badging out.
+type AccessControl struct {
+	APIKey      string        `yaml:"api_key" env-default:""`
+	PresenceTTL time.Duration `yaml:"presence_ttl" env-default:"16h"`
+}
+
 func MustLoad() *Config {
 	configPath := os.Getenv("CONFIG_PATH")
 	if configPath == "" {
@@ -0,0 +1,68 @@
+package runoffforecast
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/runoff"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Forecaster interface {
+	Forecast(ctx context.Context, resID int, asOf time.Time) (*runoff.Forecast, error)
+}
+
+// New: ?id=<reservoir id>[&date=YYYY-MM-DD]. The date defaults to today in loc.
+func New(log *slog.Logger, forecaster Forecaster, loc *time.Location) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.data.runoff-forecast.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.Atoi(r.URL.Query().Get("id"))
+		if err != nil {
+			log.Error("invalid 'id' parameter", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid or missing 'id' parameter"))
+			return
+		}
+
+		asOf := time.Now().In(loc)
+		if s := r.URL.Query().Get("date"); s != "" {
+			asOf, err = time.ParseInLocation("2006-01-02", s, loc)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'date' format (expected YYYY-MM-DD)"))
+				return
+			}
+		}
+
+		forecast, err := forecaster.Forecast(r.Context(), id, asOf)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				log.Warn("reservoir not found", slog.Int("id", id))
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Reservoir not found"))
+				return
+			}
+			log.Error("failed to build runoff forecast", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to build runoff forecast"))
+			return
+		}
+
+		render.JSON(w, r, forecast)
+	}
+}
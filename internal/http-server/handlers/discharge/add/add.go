@@ -40,6 +40,11 @@ type OngoingChecker interface {
 	EnsureNoOngoingDischarge(ctx context.Context, orgID int64, force bool, newStartTime time.Time) error
 }
 
+// ApprovalRequester asks the approvers to approve a new discharge.
+type ApprovalRequester interface {
+	RequestApproval(ctx context.Context, dischargeID, orgID int64, flowRate float64) error
+}
+
 // BackdateRotator emulates the dayrotation ticker for a freshly created
 // discharge whose start_time predates one or more 05:00 cutoffs. Returns the
 // final clone's ID; if cutoffs is empty, returns the input ID unchanged.
@@ -47,7 +52,7 @@ type BackdateRotator interface {
 	RotateBackdatedDischarge(ctx context.Context, dischargeID int64, cutoffs []time.Time) (int64, error)
 }
 
-func New(log *slog.Logger, adder DischargeAdder, checker OngoingChecker, rotator BackdateRotator, loc *time.Location) http.HandlerFunc {
+func New(log *slog.Logger, adder DischargeAdder, checker OngoingChecker, approvals ApprovalRequester, rotator BackdateRotator, loc *time.Location) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.discharge.add.New"
 		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
@@ -175,6 +180,11 @@ func New(log *slog.Logger, adder DischargeAdder, checker OngoingChecker, rotator
 			id = finalID
 		}
 
+		if err := approvals.RequestApproval(r.Context(), id, req.OrganizationID, req.FlowRate); err != nil {
+			log.Error("failed to request discharge approval", sl.Err(err), slog.Int64("id", id))
+			// The discharge is recorded; approvers still see it in the list
+		}
+
 		log.Info("discharge added successfully",
 			slog.Int64("id", id),
 			slog.Int("files", len(req.FileIDs)),
@@ -47,6 +47,12 @@ func (m *mockOngoingChecker) EnsureNoOngoingDischarge(ctx context.Context, orgID
 	return nil
 }
 
+type noopApprovals struct{}
+
+func (noopApprovals) RequestApproval(_ context.Context, _, _ int64, _ float64) error {
+	return nil
+}
+
 // mockBackdateRotator records calls to RotateBackdatedDischarge. Default
 // behavior: passthrough (returns dischargeID unchanged) — matches the
 // "no rotation needed" path so existing tests don't need to set rotateFunc.
@@ -103,7 +109,7 @@ func TestNew_OngoingDischargeConflict(t *testing.T) {
 		req = req.WithContext(contextWithClaims(req.Context(), 1))
 
 		rr := httptest.NewRecorder()
-		handler := New(logger, adder, checker, &mockBackdateRotator{}, time.UTC)
+		handler := New(logger, adder, checker, noopApprovals{}, &mockBackdateRotator{}, time.UTC)
 		handler.ServeHTTP(rr, req)
 
 		if rr.Code != http.StatusConflict {
@@ -125,7 +131,7 @@ func TestNew_OngoingDischargeConflict(t *testing.T) {
 		req = req.WithContext(contextWithClaims(req.Context(), 1))
 
 		rr := httptest.NewRecorder()
-		handler := New(logger, adder, checker, &mockBackdateRotator{}, time.UTC)
+		handler := New(logger, adder, checker, noopApprovals{}, &mockBackdateRotator{}, time.UTC)
 		handler.ServeHTTP(rr, req)
 
 		if rr.Code != http.StatusCreated {
@@ -152,7 +158,7 @@ func TestNew_OngoingDischargeConflict(t *testing.T) {
 		req = req.WithContext(contextWithClaims(req.Context(), 1))
 
 		rr := httptest.NewRecorder()
-		handler := New(logger, adder, noConflictChecker, &mockBackdateRotator{}, time.UTC)
+		handler := New(logger, adder, noConflictChecker, noopApprovals{}, &mockBackdateRotator{}, time.UTC)
 		handler.ServeHTTP(rr, req)
 
 		if rr.Code != http.StatusCreated {
@@ -183,7 +189,7 @@ func TestNew_BackdateRotation(t *testing.T) {
 		req.Header.Set("Content-Type", "application/json")
 		req = req.WithContext(contextWithClaims(req.Context(), 1))
 		rr := httptest.NewRecorder()
-		handler := New(logger, adder, noConflictChecker, rotator, tashkent)
+		handler := New(logger, adder, noConflictChecker, noopApprovals{}, rotator, tashkent)
 		handler.ServeHTTP(rr, req)
 		return rr
 	}
@@ -260,7 +266,7 @@ func TestNew_BackdateRotation(t *testing.T) {
 		req.Header.Set("Content-Type", "application/json")
 		req = req.WithContext(contextWithClaims(req.Context(), 1))
 		rr := httptest.NewRecorder()
-		handler := New(logger, freshAdder, noConflictChecker, rotator, tashkent)
+		handler := New(logger, freshAdder, noConflictChecker, noopApprovals{}, rotator, tashkent)
 		handler.ServeHTTP(rr, req)
 		if rr.Code != http.StatusBadRequest {
 			t.Fatalf("status: want 400, got %d body=%s", rr.Code, rr.Body.String())
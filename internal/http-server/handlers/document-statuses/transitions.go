@@ -0,0 +1,191 @@
+package documentstatuses
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	mwdockind "srmt-admin/internal/http-server/middleware/document-kind"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	document_status "srmt-admin/internal/lib/model/document-status"
+	docworkflow "srmt-admin/internal/lib/service/document-workflow"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type transitionGetter interface {
+	Transitions(ctx context.Context, docType string) ([]document_status.Transition, error)
+}
+
+type transitionReplacer interface {
+	ReplaceTransitions(ctx context.Context, docType string, edges []document_status.TransitionInput) error
+}
+
+type allowedNextGetter interface {
+	AllowedNext(ctx context.Context, docType string, docID int64, actor docworkflow.Actor) ([]document_status.Transition, error)
+}
+
+// ActorFromContext builds the workflow actor from the request's JWT claims.
+func ActorFromContext(ctx context.Context) (docworkflow.Actor, bool) {
+	claims, ok := mwauth.ClaimsFromContext(ctx)
+	if !ok || claims == nil {
+		return docworkflow.Actor{}, false
+	}
+	return docworkflow.Actor{UserID: claims.UserID, Roles: claims.Roles}, true
+}
+
+// WriteTransitionError renders a refused status transition as a 409 with the
+// reason code and the statuses the caller may move the document to instead.
+// Returns false when err is not a transition error.
+func WriteTransitionError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var tErr *docworkflow.TransitionError
+	if !errors.As(err, &tErr) {
+		return false
+	}
+
+	details := make([]resp.Detail, 0, len(tErr.Allowed))
+	for _, t := range tErr.Allowed {
+		details = append(details, resp.Detail{
+			"status_id":           t.ToStatus.ID,
+			"code":                t.ToStatus.Code,
+			"name":                t.ToStatus.Name,
+			"require_comment":     t.RequireComment,
+			"require_attachments": t.RequireAttachments,
+		})
+	}
+
+	render.Status(r, http.StatusConflict)
+	render.JSON(w, r, resp.ConflictStructured(tErr.Code, tErr.Error(), details))
+	return true
+}
+
+// GetTransitions returns the configured status graph, optionally filtered by
+// ?document_type=.
+func GetTransitions(log *slog.Logger, getter transitionGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.document-statuses.get-transitions"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		docType := r.URL.Query().Get("document_type")
+		transitions, err := getter.Transitions(r.Context(), docType)
+		if err != nil {
+			if errors.Is(err, docworkflow.ErrInvalidDocumentType) {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid document type"))
+				return
+			}
+			log.Error("failed to get status transitions", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve status transitions"))
+			return
+		}
+
+		render.JSON(w, r, transitions)
+	}
+}
+
+// ReplaceTransitions replaces the status graph of the document type given in
+// the {type} URL parameter.
+func ReplaceTransitions(log *slog.Logger, replacer transitionReplacer) http.HandlerFunc {
+	validate := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.document-statuses.replace-transitions"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		docType := chi.URLParam(r, "type")
+
+		var req document_status.ReplaceTransitionsRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+
+		if err := validate.Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			log.Error("validation failed", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		if err := replacer.ReplaceTransitions(r.Context(), docType, req.Transitions); err != nil {
+			if errors.Is(err, docworkflow.ErrInvalidDocumentType) {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid document type"))
+				return
+			}
+			if errors.Is(err, docworkflow.ErrInvalidGraph) {
+				log.Warn("invalid status graph", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest(err.Error()))
+				return
+			}
+			log.Error("failed to replace status transitions", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to save status transitions"))
+			return
+		}
+
+		log.Info("status transitions replaced", slog.String("document_type", docType), slog.Int("count", len(req.Transitions)))
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// GetAllowedNext returns the statuses the caller may move the document to.
+func GetAllowedNext(log *slog.Logger, getter allowedNextGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.document-statuses.get-allowed-next"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		kind, ok := mwdockind.KindFromContext(r.Context())
+		if !ok {
+			log.Error("document kind is not resolved for the route")
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Document kind is not resolved"))
+			return
+		}
+		docType := kind.Code
+		log = log.With(slog.String("document_type", docType))
+
+		actor, ok := ActorFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Warn("invalid 'id' parameter", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'id' parameter"))
+			return
+		}
+
+		transitions, err := getter.AllowedNext(r.Context(), docType, id, actor)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Document not found"))
+				return
+			}
+			log.Error("failed to get allowed transitions", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve allowed transitions"))
+			return
+		}
+
+		render.JSON(w, r, transitions)
+	}
+}
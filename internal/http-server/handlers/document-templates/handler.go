@@ -0,0 +1,248 @@
+package documenttemplates
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/helpers"
+	"srmt-admin/internal/lib/logger/sl"
+	document_template "srmt-admin/internal/lib/model/document-template"
+	"srmt-admin/internal/lib/model/file"
+	"srmt-admin/internal/lib/service/auth"
+	doctemplates "srmt-admin/internal/lib/service/document-templates"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type TemplateLister interface {
+	Templates(ctx context.Context, kind *string, activeOnly bool) ([]document_template.Model, error)
+}
+
+type TemplateCreator interface {
+	CreateTemplate(ctx context.Context, kind, name string, description *string, fileName string, data []byte, userID int64) (*document_template.Model, error)
+}
+
+type TemplateEditor interface {
+	EditTemplate(ctx context.Context, id int64, req document_template.EditRequest) error
+}
+
+type createdResponse struct {
+	resp.Response
+	Template *document_template.Model `json:"template"`
+}
+
+const maxTemplateSize = 50 * 1024 * 1024
+
+// unknownPlaceholderMessage keeps the placeholder names the service appends
+// after the sentinel and drops the op prefixes in front of it.
+func unknownPlaceholderMessage(err error) string {
+	msg := err.Error()
+	sentinel := doctemplates.ErrUnknownPlaceholder.Error()
+	if i := strings.Index(msg, sentinel); i >= 0 {
+		msg = msg[i:]
+	}
+	return "Template uses unknown placeholders" + strings.TrimPrefix(msg, sentinel)
+}
+
+// List returns the templates, optionally of one ?kind=, with download URLs.
+// ?active=true hides deactivated ones.
+func List(log *slog.Logger, svc TemplateLister, minioRepo helpers.MinioURLGenerator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.document-templates.List"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		var kind *string
+		if v := r.URL.Query().Get("kind"); v != "" {
+			kind = &v
+		}
+		activeOnly := false
+		if v := r.URL.Query().Get("active"); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'active' parameter"))
+				return
+			}
+			activeOnly = b
+		}
+
+		templates, err := svc.Templates(r.Context(), kind, activeOnly)
+		if err != nil {
+			log.Error("failed to retrieve document templates", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve document templates"))
+			return
+		}
+
+		sources := make([]file.Model, len(templates))
+		for i, t := range templates {
+			sources[i] = t.Source
+		}
+		// Files whose URL fails are skipped by the helper, so match by ID.
+		files := helpers.TransformFilesWithURLs(r.Context(), sources, minioRepo, log)
+		for i := range files {
+			for j := range templates {
+				if templates[j].FileID == files[i].ID {
+					templates[j].File = &files[i]
+				}
+			}
+		}
+
+		log.Info("successfully retrieved document templates", slog.Int("count", len(templates)))
+		render.JSON(w, r, templates)
+	}
+}
+
+// Placeholders lists the placeholders a template may use.
+func Placeholders() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, document_template.Placeholders())
+	}
+}
+
+// Create uploads a DOCX template. Multipart form: file, document_kind, name
+// and an optional description.
+func Create(log *slog.Logger, svc TemplateCreator, minioRepo helpers.MinioURLGenerator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.document-templates.Create"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		userID, err := auth.GetUserID(r.Context())
+		if err != nil {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxTemplateSize)
+		if err := r.ParseMultipartForm(maxTemplateSize); err != nil {
+			log.Error("failed to parse multipart form or file is too large", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request or file is too large"))
+			return
+		}
+
+		kind := strings.TrimSpace(r.FormValue("document_kind"))
+		name := strings.TrimSpace(r.FormValue("name"))
+		if kind == "" || name == "" {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Form fields 'document_kind' and 'name' are required"))
+			return
+		}
+		if len(name) > 255 {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Form field 'name' is too long"))
+			return
+		}
+		var description *string
+		if v := strings.TrimSpace(r.FormValue("description")); v != "" {
+			description = &v
+		}
+
+		f, fh, err := r.FormFile("file")
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Form field 'file' is required"))
+			return
+		}
+		data, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			log.Error("failed to read uploaded file", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to read uploaded file"))
+			return
+		}
+
+		tpl, err := svc.CreateTemplate(r.Context(), kind, name, description, fh.Filename, data, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, doctemplates.ErrUnknownKind):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Unknown document kind"))
+			case errors.Is(err, doctemplates.ErrNotDOCX):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Template must be a DOCX document"))
+			case errors.Is(err, doctemplates.ErrUnknownPlaceholder):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest(unknownPlaceholderMessage(err)))
+			case errors.Is(err, storage.ErrDuplicate):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("A template with this name already exists for the kind"))
+			default:
+				log.Error("failed to create document template", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to create document template"))
+			}
+			return
+		}
+		if files := helpers.TransformFilesWithURLs(r.Context(), []file.Model{tpl.Source}, minioRepo, log); len(files) > 0 {
+			tpl.File = &files[0]
+		}
+
+		log.Info("document template created", slog.Int64("id", tpl.ID))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, createdResponse{Response: resp.Created(), Template: tpl})
+	}
+}
+
+// Edit renames, re-describes or (de)activates a template. The file itself
+// is replaced by uploading a new template.
+func Edit(log *slog.Logger, svc TemplateEditor) http.HandlerFunc {
+	validate := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.document-templates.Edit"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'id' parameter"))
+			return
+		}
+
+		var req document_template.EditRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+		if err := validate.Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		if err := svc.EditTemplate(r.Context(), id, req); err != nil {
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Template not found"))
+			case errors.Is(err, storage.ErrDuplicate):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("A template with this name already exists for the kind"))
+			default:
+				log.Error("failed to update document template", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to update document template"))
+			}
+			return
+		}
+
+		log.Info("document template updated", slog.Int64("id", id))
+		render.JSON(w, r, resp.OK())
+	}
+}
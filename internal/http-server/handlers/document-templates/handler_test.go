@@ -0,0 +1,145 @@
+package documenttemplates
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	document_template "srmt-admin/internal/lib/model/document-template"
+	"srmt-admin/internal/lib/model/file"
+	doctemplates "srmt-admin/internal/lib/service/document-templates"
+	"srmt-admin/internal/storage"
+	"srmt-admin/internal/token"
+)
+
+type fakeService struct {
+	templates []document_template.Model
+	createErr error
+	kind      string
+	fileName  string
+}
+
+func (f *fakeService) Templates(_ context.Context, _ *string, _ bool) ([]document_template.Model, error) {
+	return f.templates, nil
+}
+
+func (f *fakeService) CreateTemplate(_ context.Context, kind, name string, _ *string, fileName string, _ []byte, _ int64) (*document_template.Model, error) {
+	f.kind, f.fileName = kind, fileName
+	if f.createErr != nil {
+		return nil, f.createErr
+	}
+	return &document_template.Model{ID: 1, DocumentKind: kind, Name: name, FileID: 9,
+		Source: file.Model{ID: 9, ObjectKey: "document-templates/t.docx"}}, nil
+}
+
+type urlMinio struct{}
+
+func (urlMinio) GetPresignedURL(_ context.Context, objectName string, _ time.Duration) (*url.URL, error) {
+	if strings.Contains(objectName, "broken") {
+		return nil, fmt.Errorf("presign failed")
+	}
+	return url.Parse("https://minio.local/" + objectName)
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func newCreateRequest(t *testing.T, fields map[string]string, withFile bool) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		if err := mw.WriteField(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if withFile {
+		fw, err := mw.CreateFormFile("file", "letter.docx")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = fw.Write([]byte("docx"))
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/document-templates", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req.WithContext(mwauth.ContextWithClaims(req.Context(), &token.Claims{UserID: 1, Roles: []string{"rais"}}))
+}
+
+func TestCreate(t *testing.T) {
+	fields := map[string]string{"document_kind": "letter", "name": "Письмо"}
+
+	tests := []struct {
+		name       string
+		fields     map[string]string
+		withFile   bool
+		err        error
+		wantStatus int
+		wantMsg    string
+	}{
+		{name: "success", fields: fields, withFile: true, wantStatus: http.StatusCreated},
+		{name: "no file", fields: fields, wantStatus: http.StatusBadRequest},
+		{name: "no name", fields: map[string]string{"document_kind": "letter"}, withFile: true, wantStatus: http.StatusBadRequest},
+		{name: "not docx", fields: fields, withFile: true,
+			err: fmt.Errorf("op: %w", doctemplates.ErrNotDOCX), wantStatus: http.StatusBadRequest},
+		{name: "unknown placeholder", fields: fields, withFile: true,
+			err:        fmt.Errorf("service.document-templates.CreateTemplate: %w: numbr, dat", doctemplates.ErrUnknownPlaceholder),
+			wantStatus: http.StatusBadRequest, wantMsg: "Template uses unknown placeholders: numbr, dat"},
+		{name: "duplicate", fields: fields, withFile: true,
+			err: fmt.Errorf("op: %w", storage.ErrDuplicate), wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeService{createErr: tt.err}
+			rr := httptest.NewRecorder()
+			Create(testLogger(), svc, urlMinio{}).ServeHTTP(rr, newCreateRequest(t, tt.fields, tt.withFile))
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if tt.wantMsg != "" && !strings.Contains(rr.Body.String(), tt.wantMsg) {
+				t.Errorf("body = %s, want %q", rr.Body.String(), tt.wantMsg)
+			}
+			if tt.wantStatus == http.StatusCreated && (svc.kind != "letter" || svc.fileName != "letter.docx") {
+				t.Errorf("service called with kind %q, file %q", svc.kind, svc.fileName)
+			}
+		})
+	}
+}
+
+func TestList_MatchesFilesByID(t *testing.T) {
+	svc := &fakeService{templates: []document_template.Model{
+		{ID: 1, FileID: 10, Source: file.Model{ID: 10, ObjectKey: "broken.docx"}},
+		{ID: 2, FileID: 20, Source: file.Model{ID: 20, ObjectKey: "ok.docx"}},
+	}}
+	rr := httptest.NewRecorder()
+	List(testLogger(), svc, urlMinio{}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/document-templates?kind=letter", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var got []document_template.Model
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got[0].File != nil || got[1].File == nil || got[1].File.ID != 20 {
+		t.Errorf("files = %+v, %+v", got[0].File, got[1].File)
+	}
+}
@@ -0,0 +1,211 @@
+package documents
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	docstatuses "srmt-admin/internal/http-server/handlers/document-statuses"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
+	"srmt-admin/internal/lib/service/auth"
+	docworkflow "srmt-admin/internal/lib/service/document-workflow"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type addRequest struct {
+	Name                 string                      `json:"name" validate:"required"`
+	Number               *string                     `json:"number,omitempty"`
+	DocumentDate         time.Time                   `json:"document_date" validate:"required"`
+	Description          *string                     `json:"description,omitempty"`
+	TypeID               int                         `json:"type_id" validate:"required,min=1"`
+	StatusID             *int                        `json:"status_id,omitempty"`
+	ResponsibleContactID *int64                      `json:"responsible_contact_id,omitempty"`
+	OrganizationID       *int64                      `json:"organization_id,omitempty"`
+	ExecutorContactID    *int64                      `json:"executor_contact_id,omitempty"`
+	DueDate              *time.Time                  `json:"due_date,omitempty"`
+	ParentDocumentID     *int64                      `json:"parent_document_id,omitempty"`
+	FileIDs              []int64                     `json:"file_ids,omitempty"`
+	LinkedDocuments      []dto.LinkedDocumentRequest `json:"linked_documents,omitempty"`
+	ReservationID        *int64                      `json:"reservation_id,omitempty"`
+}
+
+type addResponse struct {
+	resp.Response
+	ID     int64   `json:"id"`
+	Number *string `json:"number,omitempty"`
+}
+
+type initialStatusAuthorizer interface {
+	AuthorizeInitial(ctx context.Context, docType string, statusID int, actor docworkflow.Actor) error
+}
+
+type documentAdder interface {
+	kindChecker
+	AddDocument(ctx context.Context, kind document_kind.Model, req dto.AddDocumentRequest, createdByID int64) (int64, *string, error)
+	LinkDocumentFiles(ctx context.Context, kind document_kind.Model, docID int64, fileIDs []int64) error
+	LinkDocuments(ctx context.Context, kind document_kind.Model, docID int64, links []dto.LinkedDocumentRequest, userID int64) error
+}
+
+// Add creates a document of the route's kind. The fields the kind lists as
+// required must be set, inactive kinds accept no new documents, and an
+// explicit status_id must be one of the creation statuses of the kind's
+// status graph. Kinds with a numbering scheme get their number allocated on
+// registration, or from the reservation named by reservation_id.
+func Add(log *slog.Logger, adder documentAdder, workflow initialStatusAuthorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.documents.add"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		kind, ok := requestKind(w, r, log)
+		if !ok {
+			return
+		}
+		log = log.With(slog.String("kind", kind.Code))
+
+		userID, err := auth.GetUserID(r.Context())
+		if err != nil {
+			log.Error("failed to get user id from context", sl.Err(err))
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		if !kind.IsActive {
+			log.Warn("document kind is inactive")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Document kind is inactive"))
+			return
+		}
+
+		var req addRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			log.Error("validation failed", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		if missing := missingRequiredFields(kind, req); len(missing) > 0 {
+			log.Warn("required fields missing", slog.Any("fields", missing))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Missing required fields: "+strings.Join(missing, ", ")))
+			return
+		}
+
+		if writeInvalidLinkType(w, r, log, adder, req.LinkedDocuments) {
+			return
+		}
+
+		if req.StatusID != nil {
+			actor, _ := docstatuses.ActorFromContext(r.Context())
+			if err := workflow.AuthorizeInitial(r.Context(), kind.Code, *req.StatusID, actor); err != nil {
+				if docstatuses.WriteTransitionError(w, r, err) {
+					log.Warn("creation status refused", sl.Err(err))
+					return
+				}
+				log.Error("failed to check creation status", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to add document"))
+				return
+			}
+		}
+
+		storageReq := dto.AddDocumentRequest{
+			Name:                 req.Name,
+			Number:               req.Number,
+			DocumentDate:         req.DocumentDate,
+			Description:          req.Description,
+			TypeID:               req.TypeID,
+			StatusID:             req.StatusID,
+			ResponsibleContactID: req.ResponsibleContactID,
+			OrganizationID:       req.OrganizationID,
+			ExecutorContactID:    req.ExecutorContactID,
+			DueDate:              req.DueDate,
+			ParentDocumentID:     req.ParentDocumentID,
+			FileIDs:              req.FileIDs,
+			LinkedDocuments:      req.LinkedDocuments,
+			ReservationID:        req.ReservationID,
+		}
+
+		id, number, err := adder.AddDocument(r.Context(), kind, storageReq, userID)
+		if err != nil {
+			if errors.Is(err, storage.ErrForeignKeyViolation) {
+				log.Warn("foreign key violation")
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid reference ID (type, status, contact, or organization)"))
+				return
+			}
+			if errors.Is(err, storage.ErrDuplicate) {
+				log.Warn("document number already used")
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Document number is already used"))
+				return
+			}
+			if errors.Is(err, storage.ErrNumberAssigned) {
+				log.Warn("number typed for an automatically numbered kind")
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Number is assigned automatically for this document kind"))
+				return
+			}
+			if errors.Is(err, storage.ErrNotFound) {
+				log.Warn("reservation not found")
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'reservation_id'"))
+				return
+			}
+			if errors.Is(err, storage.ErrInvalidStatus) {
+				log.Warn("reservation is not available", sl.Err(err))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Reserved number is already used, cancelled or of another kind or organization"))
+				return
+			}
+			log.Error("failed to add document", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to add document"))
+			return
+		}
+
+		if len(req.FileIDs) > 0 {
+			if err := adder.LinkDocumentFiles(r.Context(), kind, id, req.FileIDs); err != nil {
+				log.Error("failed to link files", sl.Err(err))
+			}
+		}
+
+		if len(req.LinkedDocuments) > 0 {
+			if err := adder.LinkDocuments(r.Context(), kind, id, req.LinkedDocuments, userID); err != nil {
+				log.Error("failed to link documents", sl.Err(err))
+			}
+		}
+
+		log.Info("document added successfully",
+			slog.Int64("id", id),
+			slog.Int("files", len(req.FileIDs)),
+		)
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, addResponse{
+			Response: resp.Created(),
+			ID:       id,
+			Number:   number,
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package documents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	mwdockind "srmt-admin/internal/http-server/middleware/document-kind"
+	"srmt-admin/internal/lib/dto"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
+	"srmt-admin/internal/storage"
+	"srmt-admin/internal/token"
+)
+
+// knownKinds answers DocumentKindExists for the handler mocks.
+type knownKinds struct{}
+
+func (knownKinds) DocumentKindExists(_ context.Context, code string) (bool, error) {
+	return code == "decree" || code == "report" || code == "letter" || code == "instruction", nil
+}
+
+type mockDocumentAdder struct {
+	knownKinds
+	addFunc           func(ctx context.Context, req dto.AddDocumentRequest, createdByID int64) (int64, error)
+	linkFilesFunc     func(ctx context.Context, docID int64, fileIDs []int64) error
+	linkDocumentsFunc func(ctx context.Context, docID int64, links []dto.LinkedDocumentRequest, userID int64) error
+}
+
+func (m *mockDocumentAdder) AddDocument(ctx context.Context, _ document_kind.Model, req dto.AddDocumentRequest, createdByID int64) (int64, *string, error) {
+	if m.addFunc != nil {
+		id, err := m.addFunc(ctx, req, createdByID)
+		return id, req.Number, err
+	}
+	return 1, req.Number, nil
+}
+
+func (m *mockDocumentAdder) LinkDocumentFiles(ctx context.Context, _ document_kind.Model, docID int64, fileIDs []int64) error {
+	if m.linkFilesFunc != nil {
+		return m.linkFilesFunc(ctx, docID, fileIDs)
+	}
+	return nil
+}
+
+func (m *mockDocumentAdder) LinkDocuments(ctx context.Context, _ document_kind.Model, docID int64, links []dto.LinkedDocumentRequest, userID int64) error {
+	if m.linkDocumentsFunc != nil {
+		return m.linkDocumentsFunc(ctx, docID, links, userID)
+	}
+	return nil
+}
+
+var decreeKind = document_kind.Model{Code: "decree", Name: "Приказ", TableName: "decrees", IsActive: true}
+
+// testContextWithKind resolves the route's kind the way the document-kind
+// middleware does.
+func testContextWithKind(ctx context.Context, kind document_kind.Model) context.Context {
+	return mwdockind.ContextWithKind(ctx, kind)
+}
+
+func testContextWithClaims(ctx context.Context, userID int64) context.Context {
+	claims := &token.Claims{
+		UserID: userID,
+		Name:   "Test User",
+		Roles:  []string{"admin"},
+	}
+	return mwauth.ContextWithClaims(testContextWithKind(ctx, decreeKind), claims)
+}
+
+func TestAdd(t *testing.T) {
+	docDate := time.Date(2026, 4, 8, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		body           interface{}
+		userID         int64
+		mockResponse   int64
+		mockError      error
+		wantStatusCode int
+	}{
+		{
+			name: "successful creation with file_ids",
+			body: addRequest{
+				Name:         "Test document",
+				DocumentDate: docDate,
+				TypeID:       1,
+				FileIDs:      []int64{42, 43},
+			},
+			userID:         1,
+			mockResponse:   10,
+			wantStatusCode: http.StatusCreated,
+		},
+		{
+			name: "successful creation without files",
+			body: addRequest{
+				Name:         "Test document",
+				DocumentDate: docDate,
+				TypeID:       1,
+			},
+			userID:         1,
+			mockResponse:   11,
+			wantStatusCode: http.StatusCreated,
+		},
+		{
+			name: "validation error - missing name",
+			body: addRequest{
+				DocumentDate: docDate,
+				TypeID:       1,
+			},
+			userID:         1,
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "foreign key violation",
+			body: addRequest{
+				Name:         "Test",
+				DocumentDate: docDate,
+				TypeID:       999,
+			},
+			userID:         1,
+			mockError:      storage.ErrForeignKeyViolation,
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "number already used",
+			body: addRequest{
+				Name:         "Test",
+				DocumentDate: docDate,
+				TypeID:       1,
+			},
+			userID:         1,
+			mockError:      storage.ErrDuplicate,
+			wantStatusCode: http.StatusConflict,
+		},
+		{
+			name: "number typed for a numbered kind",
+			body: addRequest{
+				Name:         "Test",
+				DocumentDate: docDate,
+				TypeID:       1,
+			},
+			userID:         1,
+			mockError:      storage.ErrNumberAssigned,
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "reservation already used",
+			body: addRequest{
+				Name:         "Test",
+				DocumentDate: docDate,
+				TypeID:       1,
+			},
+			userID:         1,
+			mockError:      storage.ErrInvalidStatus,
+			wantStatusCode: http.StatusConflict,
+		},
+		{
+			name: "unknown linked document type",
+			body: addRequest{
+				Name:            "Test",
+				DocumentDate:    docDate,
+				TypeID:          1,
+				LinkedDocuments: []dto.LinkedDocumentRequest{{LinkedDocumentType: "memo", LinkedDocumentID: 1}},
+			},
+			userID:         1,
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "internal server error",
+			body: addRequest{
+				Name:         "Test",
+				DocumentDate: docDate,
+				TypeID:       1,
+			},
+			userID:         1,
+			mockError:      errors.New("db error"),
+			wantStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name:           "invalid JSON",
+			body:           "not json",
+			userID:         1,
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockDocumentAdder{
+				addFunc: func(ctx context.Context, req dto.AddDocumentRequest, createdByID int64) (int64, error) {
+					if tt.mockError != nil {
+						return 0, tt.mockError
+					}
+					return tt.mockResponse, nil
+				},
+			}
+
+			var bodyReader io.Reader
+			if str, ok := tt.body.(string); ok {
+				bodyReader = bytes.NewBufferString(str)
+			} else {
+				bodyBytes, _ := json.Marshal(tt.body)
+				bodyReader = bytes.NewBuffer(bodyBytes)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/decrees", bodyReader)
+			req.Header.Set("Content-Type", "application/json")
+			ctx := testContextWithClaims(req.Context(), tt.userID)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			handler := Add(logger, mock, &mockWorkflow{})
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d, body: %s", rr.Code, tt.wantStatusCode, rr.Body.String())
+			}
+
+			if tt.wantStatusCode == http.StatusCreated {
+				var resp addResponse
+				if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+				if resp.ID != tt.mockResponse {
+					t.Errorf("got ID %d, want %d", resp.ID, tt.mockResponse)
+				}
+			}
+		})
+	}
+}
+
+func TestAdd_NoAuth(t *testing.T) {
+	mock := &mockDocumentAdder{}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	body := addRequest{Name: "Test", DocumentDate: time.Now(), TypeID: 1}
+	bodyBytes, _ := json.Marshal(body)
+
+	req := httptest.NewRequest(http.MethodPost, "/decrees", bytes.NewBuffer(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(testContextWithKind(req.Context(), decreeKind))
+
+	rr := httptest.NewRecorder()
+	handler := Add(logger, mock, &mockWorkflow{})
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAdd_MultipartRejected(t *testing.T) {
+	mock := &mockDocumentAdder{}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/decrees", bytes.NewBufferString("--boundary\r\n"))
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=boundary")
+	ctx := testContextWithClaims(req.Context(), 1)
+	req = req.WithContext(ctx)
+
+	rr := httptest.NewRecorder()
+	handler := Add(logger, mock, &mockWorkflow{})
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("multipart should be rejected: got status %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestAdd_KindRules(t *testing.T) {
+	number := "12-п"
+	tests := []struct {
+		name           string
+		kind           document_kind.Model
+		body           addRequest
+		wantStatusCode int
+	}{
+		{
+			name:           "required field missing",
+			kind:           document_kind.Model{Code: "letter", IsActive: true, RequiredFields: []string{document_kind.FieldNumber, document_kind.FieldOrganizationID}},
+			body:           addRequest{Name: "Письмо", DocumentDate: time.Now(), TypeID: 1, Number: &number},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "required fields set",
+			kind:           document_kind.Model{Code: "letter", IsActive: true, RequiredFields: []string{document_kind.FieldNumber}},
+			body:           addRequest{Name: "Письмо", DocumentDate: time.Now(), TypeID: 1, Number: &number},
+			wantStatusCode: http.StatusCreated,
+		},
+		{
+			name:           "inactive kind",
+			kind:           document_kind.Model{Code: "letter", IsActive: false},
+			body:           addRequest{Name: "Письмо", DocumentDate: time.Now(), TypeID: 1},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodyBytes, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(http.MethodPost, "/documents/letter", bytes.NewBuffer(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			ctx := testContextWithClaims(req.Context(), 1)
+			req = req.WithContext(testContextWithKind(ctx, tt.kind))
+
+			rr := httptest.NewRecorder()
+			Add(slog.New(slog.NewTextHandler(io.Discard, nil)), &mockDocumentAdder{}, &mockWorkflow{}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d, body: %s", rr.Code, tt.wantStatusCode, rr.Body.String())
+			}
+		})
+	}
+}
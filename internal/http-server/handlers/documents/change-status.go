@@ -1,4 +1,4 @@
-package letters
+package documents
 
 import (
 	"context"
@@ -7,10 +7,11 @@ import (
 	"net/http"
 	"strconv"
 
+	docstatuses "srmt-admin/internal/http-server/handlers/document-statuses"
 	resp "srmt-admin/internal/lib/api/response"
 	"srmt-admin/internal/lib/dto"
 	"srmt-admin/internal/lib/logger/sl"
-	"srmt-admin/internal/lib/service/auth"
+	docworkflow "srmt-admin/internal/lib/service/document-workflow"
 	"srmt-admin/internal/storage"
 
 	"github.com/go-chi/chi/v5"
@@ -19,24 +20,26 @@ import (
 	"github.com/go-playground/validator/v10"
 )
 
-type changeStatusRequest struct {
-	StatusID int     `json:"status_id" validate:"required,min=1"`
-	Comment  *string `json:"comment,omitempty"`
+type statusChanger interface {
+	ChangeStatus(ctx context.Context, docType string, docID int64, toStatusID int, comment *string, actor docworkflow.Actor) error
 }
 
-type letterStatusChanger interface {
-	EditLetter(ctx context.Context, id int64, req dto.EditLetterRequest, updatedByID int64) error
-	AddLetterStatusHistoryComment(ctx context.Context, letterID int64, comment string) error
-}
-
-func ChangeStatus(log *slog.Logger, changer letterStatusChanger) http.HandlerFunc {
+// ChangeStatus moves the document along an allowed edge of its kind's status
+// graph. Refused transitions return 409 with the allowed next statuses.
+func ChangeStatus(log *slog.Logger, changer statusChanger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.letters.change-status"
+		const op = "handlers.documents.change-status"
 		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
 
-		userID, err := auth.GetUserID(r.Context())
-		if err != nil {
-			log.Error("failed to get user id from context", sl.Err(err))
+		kind, ok := requestKind(w, r, log)
+		if !ok {
+			return
+		}
+		log = log.With(slog.String("kind", kind.Code))
+
+		actor, ok := docstatuses.ActorFromContext(r.Context())
+		if !ok {
+			log.Error("failed to get user from context")
 			render.Status(r, http.StatusUnauthorized)
 			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
 			return
@@ -51,7 +54,7 @@ func ChangeStatus(log *slog.Logger, changer letterStatusChanger) http.HandlerFun
 			return
 		}
 
-		var req changeStatusRequest
+		var req dto.ChangeStatusRequest
 		if err := render.DecodeJSON(r.Body, &req); err != nil {
 			log.Error("failed to decode request", sl.Err(err))
 			render.Status(r, http.StatusBadRequest)
@@ -68,37 +71,31 @@ func ChangeStatus(log *slog.Logger, changer letterStatusChanger) http.HandlerFun
 			return
 		}
 
-		editReq := dto.EditLetterRequest{
-			StatusID: &req.StatusID,
-		}
-
-		err = changer.EditLetter(r.Context(), id, editReq, userID)
+		err = changer.ChangeStatus(r.Context(), kind.Code, id, req.StatusID, req.Comment, actor)
 		if err != nil {
 			if errors.Is(err, storage.ErrNotFound) {
-				log.Warn("letter not found", slog.Int64("id", id))
+				log.Warn("document not found", slog.Int64("id", id))
 				render.Status(r, http.StatusNotFound)
-				render.JSON(w, r, resp.NotFound("Letter not found"))
+				render.JSON(w, r, resp.NotFound("Document not found"))
+				return
+			}
+			if docstatuses.WriteTransitionError(w, r, err) {
+				log.Warn("status transition refused", sl.Err(err), slog.Int64("id", id))
 				return
 			}
-			if errors.Is(err, storage.ErrForeignKeyViolation) {
-				log.Warn("invalid status_id", slog.Int("status_id", req.StatusID))
-				render.Status(r, http.StatusBadRequest)
-				render.JSON(w, r, resp.BadRequest("Invalid status_id"))
+			if errors.Is(err, storage.ErrInvalidStatus) {
+				log.Warn("status changed concurrently", slog.Int64("id", id))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Status was changed by another user, reload and retry"))
 				return
 			}
-			log.Error("failed to change letter status", sl.Err(err))
+			log.Error("failed to change document status", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
 			render.JSON(w, r, resp.InternalServerError("Failed to change status"))
 			return
 		}
 
-		if req.Comment != nil && *req.Comment != "" {
-			if err := changer.AddLetterStatusHistoryComment(r.Context(), id, *req.Comment); err != nil {
-				log.Warn("failed to add status change comment", sl.Err(err))
-			}
-		}
-
-		log.Info("letter status changed successfully", slog.Int64("id", id), slog.Int("new_status_id", req.StatusID))
+		log.Info("document status changed successfully", slog.Int64("id", id), slog.Int("new_status_id", req.StatusID))
 		render.JSON(w, r, resp.OK())
 	}
 }
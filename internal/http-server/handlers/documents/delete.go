@@ -1,4 +1,4 @@
-package reports
+package documents
 
 import (
 	"context"
@@ -9,6 +9,7 @@ import (
 
 	resp "srmt-admin/internal/lib/api/response"
 	"srmt-admin/internal/lib/logger/sl"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
 	"srmt-admin/internal/storage"
 
 	"github.com/go-chi/chi/v5"
@@ -16,15 +17,22 @@ import (
 	"github.com/go-chi/render"
 )
 
-type reportDeleter interface {
-	DeleteReport(ctx context.Context, id int64) error
+type documentDeleter interface {
+	DeleteDocument(ctx context.Context, kind document_kind.Model, id int64) error
 }
 
-func Delete(log *slog.Logger, deleter reportDeleter) http.HandlerFunc {
+// Delete removes a document of the route's kind.
+func Delete(log *slog.Logger, deleter documentDeleter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.reports.delete"
+		const op = "handlers.documents.delete"
 		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
 
+		kind, ok := requestKind(w, r, log)
+		if !ok {
+			return
+		}
+		log = log.With(slog.String("kind", kind.Code))
+
 		idStr := chi.URLParam(r, "id")
 		id, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
@@ -34,27 +42,27 @@ func Delete(log *slog.Logger, deleter reportDeleter) http.HandlerFunc {
 			return
 		}
 
-		err = deleter.DeleteReport(r.Context(), id)
+		err = deleter.DeleteDocument(r.Context(), kind, id)
 		if err != nil {
 			if errors.Is(err, storage.ErrNotFound) {
-				log.Warn("report not found", slog.Int64("id", id))
+				log.Warn("document not found", slog.Int64("id", id))
 				render.Status(r, http.StatusNotFound)
-				render.JSON(w, r, resp.NotFound("Report not found"))
+				render.JSON(w, r, resp.NotFound("Document not found"))
 				return
 			}
 			if errors.Is(err, storage.ErrForeignKeyViolation) {
-				log.Warn("cannot delete report - referenced by other records", slog.Int64("id", id))
+				log.Warn("cannot delete document - referenced by other records", slog.Int64("id", id))
 				render.Status(r, http.StatusBadRequest)
-				render.JSON(w, r, resp.BadRequest("Cannot delete: report is referenced by other records"))
+				render.JSON(w, r, resp.BadRequest("Cannot delete: document is referenced by other records"))
 				return
 			}
-			log.Error("failed to delete report", sl.Err(err))
+			log.Error("failed to delete document", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, resp.InternalServerError("Failed to delete report"))
+			render.JSON(w, r, resp.InternalServerError("Failed to delete document"))
 			return
 		}
 
-		log.Info("report deleted successfully", slog.Int64("id", id))
+		log.Info("document deleted successfully", slog.Int64("id", id))
 		render.Status(r, http.StatusNoContent)
 	}
 }
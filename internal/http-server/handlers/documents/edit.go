@@ -0,0 +1,219 @@
+package documents
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	docstatuses "srmt-admin/internal/http-server/handlers/document-statuses"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
+	"srmt-admin/internal/lib/service/auth"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type editRequest struct {
+	Name                 *string                     `json:"name,omitempty"`
+	Number               *string                     `json:"number,omitempty"`
+	DocumentDate         *time.Time                  `json:"document_date,omitempty"`
+	Description          *string                     `json:"description,omitempty"`
+	TypeID               *int                        `json:"type_id,omitempty"`
+	StatusID             *int                        `json:"status_id,omitempty"`
+	ResponsibleContactID *int64                      `json:"responsible_contact_id,omitempty"`
+	OrganizationID       *int64                      `json:"organization_id,omitempty"`
+	ExecutorContactID    *int64                      `json:"executor_contact_id,omitempty"`
+	DueDate              *time.Time                  `json:"due_date,omitempty"`
+	ParentDocumentID     *int64                      `json:"parent_document_id,omitempty"`
+	FileIDs              []int64                     `json:"file_ids,omitempty"`
+	LinkedDocuments      []dto.LinkedDocumentRequest `json:"linked_documents,omitempty"`
+}
+
+type documentEditor interface {
+	kindChecker
+	EditDocument(ctx context.Context, kind document_kind.Model, id int64, req dto.EditDocumentRequest, updatedByID int64) error
+	UnlinkDocumentFiles(ctx context.Context, kind document_kind.Model, docID int64) error
+	LinkDocumentFiles(ctx context.Context, kind document_kind.Model, docID int64, fileIDs []int64) error
+	UnlinkDocuments(ctx context.Context, kind document_kind.Model, docID int64) error
+	LinkDocuments(ctx context.Context, kind document_kind.Model, docID int64, links []dto.LinkedDocumentRequest, userID int64) error
+	DocumentAttachmentLock(ctx context.Context, kind document_kind.Model, docID int64) (bool, []int64, error)
+}
+
+// Edit updates the document's fields. A status_id different from the current
+// status goes through the status graph first, like PATCH .../{id}/status;
+// when it is refused nothing else is changed. The attachments of a signed
+// document are locked: file_ids may only repeat the files already attached.
+func Edit(log *slog.Logger, editor documentEditor, changer statusChanger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.documents.edit"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		kind, ok := requestKind(w, r, log)
+		if !ok {
+			return
+		}
+		log = log.With(slog.String("kind", kind.Code))
+
+		userID, err := auth.GetUserID(r.Context())
+		if err != nil {
+			log.Error("failed to get user id from context", sl.Err(err))
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			log.Warn("invalid 'id' parameter", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'id' parameter"))
+			return
+		}
+
+		var req editRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+
+		if writeInvalidLinkType(w, r, log, editor, req.LinkedDocuments) {
+			return
+		}
+
+		if req.FileIDs != nil {
+			locked, attached, err := editor.DocumentAttachmentLock(r.Context(), kind, id)
+			if err != nil {
+				log.Error("failed to check attachment lock", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to update document"))
+				return
+			}
+			if locked {
+				if !sameFiles(attached, req.FileIDs) {
+					log.Warn("attachments of a signed document are locked", slog.Int64("id", id))
+					render.Status(r, http.StatusConflict)
+					render.JSON(w, r, resp.Conflict("Attachments of a signed document cannot be changed"))
+					return
+				}
+				req.FileIDs = nil
+			}
+		}
+
+		if req.StatusID != nil {
+			actor, _ := docstatuses.ActorFromContext(r.Context())
+			if err := changer.ChangeStatus(r.Context(), kind.Code, id, *req.StatusID, nil, actor); err != nil {
+				if errors.Is(err, storage.ErrNotFound) {
+					log.Warn("document not found", slog.Int64("id", id))
+					render.Status(r, http.StatusNotFound)
+					render.JSON(w, r, resp.NotFound("Document not found"))
+					return
+				}
+				if docstatuses.WriteTransitionError(w, r, err) {
+					log.Warn("status transition refused", sl.Err(err), slog.Int64("id", id))
+					return
+				}
+				if errors.Is(err, storage.ErrInvalidStatus) {
+					render.Status(r, http.StatusConflict)
+					render.JSON(w, r, resp.Conflict("Status was changed by another user, reload and retry"))
+					return
+				}
+				log.Error("failed to change document status", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to change status"))
+				return
+			}
+		}
+
+		storageReq := dto.EditDocumentRequest{
+			Name:                 req.Name,
+			Number:               req.Number,
+			DocumentDate:         req.DocumentDate,
+			Description:          req.Description,
+			TypeID:               req.TypeID,
+			ResponsibleContactID: req.ResponsibleContactID,
+			OrganizationID:       req.OrganizationID,
+			ExecutorContactID:    req.ExecutorContactID,
+			DueDate:              req.DueDate,
+			ParentDocumentID:     req.ParentDocumentID,
+			FileIDs:              req.FileIDs,
+			LinkedDocuments:      req.LinkedDocuments,
+		}
+
+		err = editor.EditDocument(r.Context(), kind, id, storageReq, userID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				log.Warn("document not found", slog.Int64("id", id))
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Document not found"))
+				return
+			}
+			if errors.Is(err, storage.ErrForeignKeyViolation) {
+				log.Warn("foreign key violation")
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid reference ID"))
+				return
+			}
+			if errors.Is(err, storage.ErrDuplicate) {
+				log.Warn("document number already used", slog.Int64("id", id))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Document number is already used"))
+				return
+			}
+			if errors.Is(err, storage.ErrNumberAssigned) {
+				log.Warn("registration number cannot be changed", slog.Int64("id", id))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Registration number was issued by the numbering scheme and cannot be changed"))
+				return
+			}
+			log.Error("failed to update document", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to update document"))
+			return
+		}
+
+		if req.FileIDs != nil {
+			if err := editor.UnlinkDocumentFiles(r.Context(), kind, id); err != nil {
+				log.Error("failed to unlink files", sl.Err(err))
+			}
+			if len(req.FileIDs) > 0 {
+				if err := editor.LinkDocumentFiles(r.Context(), kind, id, req.FileIDs); err != nil {
+					log.Error("failed to link files", sl.Err(err))
+				}
+			}
+		}
+
+		if req.LinkedDocuments != nil {
+			if err := editor.UnlinkDocuments(r.Context(), kind, id); err != nil {
+				log.Error("failed to unlink documents", sl.Err(err))
+			}
+			if len(req.LinkedDocuments) > 0 {
+				if err := editor.LinkDocuments(r.Context(), kind, id, req.LinkedDocuments, userID); err != nil {
+					log.Error("failed to link documents", sl.Err(err))
+				}
+			}
+		}
+
+		log.Info("document updated successfully", slog.Int64("id", id))
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// sameFiles reports whether two lists hold the same file IDs, in any order.
+func sameFiles(a, b []int64) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
@@ -1,4 +1,4 @@
-package decrees
+package documents
 
 import (
 	"bytes"
@@ -12,60 +12,67 @@ import (
 	"testing"
 
 	"srmt-admin/internal/lib/dto"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
 	"srmt-admin/internal/storage"
 
 	"github.com/go-chi/chi/v5"
 )
 
-type mockDecreeEditor struct {
-	editFunc           func(ctx context.Context, id int64, req dto.EditDecreeRequest, updatedByID int64) error
-	unlinkFilesFunc    func(ctx context.Context, decreeID int64) error
-	linkFilesFunc      func(ctx context.Context, decreeID int64, fileIDs []int64) error
-	unlinkDocsFunc     func(ctx context.Context, decreeID int64) error
-	linkDocsFunc       func(ctx context.Context, decreeID int64, links []dto.LinkedDocumentRequest, userID int64) error
-	unlinkFilesCalled  bool
-	linkFilesCalled    bool
-	linkedFileIDs      []int64
+type mockDocumentEditor struct {
+	knownKinds
+	editFunc          func(ctx context.Context, id int64, req dto.EditDocumentRequest, updatedByID int64) error
+	unlinkFilesFunc   func(ctx context.Context, docID int64) error
+	linkFilesFunc     func(ctx context.Context, docID int64, fileIDs []int64) error
+	unlinkDocsFunc    func(ctx context.Context, docID int64) error
+	linkDocsFunc      func(ctx context.Context, docID int64, links []dto.LinkedDocumentRequest, userID int64) error
+	unlinkFilesCalled bool
+	linkFilesCalled   bool
+	linkedFileIDs     []int64
+	lockedFileIDs     []int64 // attachments of a signed document, locked when set
 }
 
-func (m *mockDecreeEditor) EditDecree(ctx context.Context, id int64, req dto.EditDecreeRequest, updatedByID int64) error {
+func (m *mockDocumentEditor) EditDocument(ctx context.Context, _ document_kind.Model, id int64, req dto.EditDocumentRequest, updatedByID int64) error {
 	if m.editFunc != nil {
 		return m.editFunc(ctx, id, req, updatedByID)
 	}
 	return nil
 }
 
-func (m *mockDecreeEditor) UnlinkDecreeFiles(ctx context.Context, decreeID int64) error {
+func (m *mockDocumentEditor) UnlinkDocumentFiles(ctx context.Context, _ document_kind.Model, docID int64) error {
 	m.unlinkFilesCalled = true
 	if m.unlinkFilesFunc != nil {
-		return m.unlinkFilesFunc(ctx, decreeID)
+		return m.unlinkFilesFunc(ctx, docID)
 	}
 	return nil
 }
 
-func (m *mockDecreeEditor) LinkDecreeFiles(ctx context.Context, decreeID int64, fileIDs []int64) error {
+func (m *mockDocumentEditor) LinkDocumentFiles(ctx context.Context, _ document_kind.Model, docID int64, fileIDs []int64) error {
 	m.linkFilesCalled = true
 	m.linkedFileIDs = fileIDs
 	if m.linkFilesFunc != nil {
-		return m.linkFilesFunc(ctx, decreeID, fileIDs)
+		return m.linkFilesFunc(ctx, docID, fileIDs)
 	}
 	return nil
 }
 
-func (m *mockDecreeEditor) UnlinkDecreeDocuments(ctx context.Context, decreeID int64) error {
+func (m *mockDocumentEditor) UnlinkDocuments(ctx context.Context, _ document_kind.Model, docID int64) error {
 	if m.unlinkDocsFunc != nil {
-		return m.unlinkDocsFunc(ctx, decreeID)
+		return m.unlinkDocsFunc(ctx, docID)
 	}
 	return nil
 }
 
-func (m *mockDecreeEditor) LinkDecreeDocuments(ctx context.Context, decreeID int64, links []dto.LinkedDocumentRequest, userID int64) error {
+func (m *mockDocumentEditor) LinkDocuments(ctx context.Context, _ document_kind.Model, docID int64, links []dto.LinkedDocumentRequest, userID int64) error {
 	if m.linkDocsFunc != nil {
-		return m.linkDocsFunc(ctx, decreeID, links, userID)
+		return m.linkDocsFunc(ctx, docID, links, userID)
 	}
 	return nil
 }
 
+func (m *mockDocumentEditor) DocumentAttachmentLock(_ context.Context, _ document_kind.Model, _ int64) (bool, []int64, error) {
+	return m.lockedFileIDs != nil, m.lockedFileIDs, nil
+}
+
 func newEditRequest(t *testing.T, id string, body interface{}) *http.Request {
 	t.Helper()
 	var bodyReader io.Reader
@@ -92,6 +99,7 @@ func TestEdit(t *testing.T) {
 		id                string
 		body              interface{}
 		mockError         error
+		lockedFileIDs     []int64
 		wantStatusCode    int
 		wantUnlinkFiles   bool
 		wantLinkFiles     bool
@@ -104,12 +112,12 @@ func TestEdit(t *testing.T) {
 			wantStatusCode: http.StatusOK,
 		},
 		{
-			name:            "file_ids present replaces files",
-			id:              "1",
-			body:            map[string]interface{}{"file_ids": []int64{10, 20}},
-			wantStatusCode:  http.StatusOK,
-			wantUnlinkFiles: true,
-			wantLinkFiles:   true,
+			name:              "file_ids present replaces files",
+			id:                "1",
+			body:              map[string]interface{}{"file_ids": []int64{10, 20}},
+			wantStatusCode:    http.StatusOK,
+			wantUnlinkFiles:   true,
+			wantLinkFiles:     true,
 			wantLinkedFileIDs: []int64{10, 20},
 		},
 		{
@@ -121,13 +129,27 @@ func TestEdit(t *testing.T) {
 			wantLinkFiles:   false,
 		},
 		{
-			name:           "file_ids omitted does not touch files",
-			id:             "1",
-			body:           map[string]interface{}{"name": "No file changes"},
-			wantStatusCode: http.StatusOK,
+			name:            "file_ids omitted does not touch files",
+			id:              "1",
+			body:            map[string]interface{}{"name": "No file changes"},
+			wantStatusCode:  http.StatusOK,
 			wantUnlinkFiles: false,
 			wantLinkFiles:   false,
 		},
+		{
+			name:           "signed document refuses other files",
+			id:             "1",
+			body:           map[string]interface{}{"name": "Updated", "file_ids": []int64{10, 30}},
+			lockedFileIDs:  []int64{10, 20},
+			wantStatusCode: http.StatusConflict,
+		},
+		{
+			name:           "signed document accepts the same files",
+			id:             "1",
+			body:           map[string]interface{}{"file_ids": []int64{20, 10}},
+			lockedFileIDs:  []int64{10, 20},
+			wantStatusCode: http.StatusOK,
+		},
 		{
 			name:           "invalid id parameter",
 			id:             "abc",
@@ -135,7 +157,7 @@ func TestEdit(t *testing.T) {
 			wantStatusCode: http.StatusBadRequest,
 		},
 		{
-			name:           "decree not found",
+			name:           "document not found",
 			id:             "999",
 			body:           map[string]interface{}{"name": "Test"},
 			mockError:      storage.ErrNotFound,
@@ -148,6 +170,13 @@ func TestEdit(t *testing.T) {
 			mockError:      storage.ErrForeignKeyViolation,
 			wantStatusCode: http.StatusBadRequest,
 		},
+		{
+			name:           "number already used",
+			id:             "1",
+			body:           map[string]interface{}{"number": "12-п"},
+			mockError:      storage.ErrDuplicate,
+			wantStatusCode: http.StatusConflict,
+		},
 		{
 			name:           "internal server error",
 			id:             "1",
@@ -165,8 +194,9 @@ func TestEdit(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mock := &mockDecreeEditor{
-				editFunc: func(ctx context.Context, id int64, req dto.EditDecreeRequest, updatedByID int64) error {
+			mock := &mockDocumentEditor{
+				lockedFileIDs: tt.lockedFileIDs,
+				editFunc: func(ctx context.Context, id int64, req dto.EditDocumentRequest, updatedByID int64) error {
 					if tt.mockError != nil {
 						return tt.mockError
 					}
@@ -178,7 +208,7 @@ func TestEdit(t *testing.T) {
 			rr := httptest.NewRecorder()
 			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
-			handler := Edit(logger, mock)
+			handler := Edit(logger, mock, &mockWorkflow{})
 			handler.ServeHTTP(rr, req)
 
 			if rr.Code != tt.wantStatusCode {
@@ -201,7 +231,7 @@ func TestEdit(t *testing.T) {
 }
 
 func TestEdit_NoAuth(t *testing.T) {
-	mock := &mockDecreeEditor{}
+	mock := &mockDocumentEditor{}
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
 	body, _ := json.Marshal(map[string]interface{}{"name": "Test"})
@@ -210,10 +240,11 @@ func TestEdit_NoAuth(t *testing.T) {
 
 	rctx := chi.NewRouteContext()
 	rctx.URLParams.Add("id", "1")
-	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	ctx := testContextWithKind(req.Context(), decreeKind)
+	req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
 
 	rr := httptest.NewRecorder()
-	handler := Edit(logger, mock)
+	handler := Edit(logger, mock, &mockWorkflow{})
 	handler.ServeHTTP(rr, req)
 
 	if rr.Code != http.StatusUnauthorized {
@@ -222,7 +253,7 @@ func TestEdit_NoAuth(t *testing.T) {
 }
 
 func TestEdit_MultipartRejected(t *testing.T) {
-	mock := &mockDecreeEditor{}
+	mock := &mockDocumentEditor{}
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
 	req := httptest.NewRequest(http.MethodPatch, "/decrees/1", bytes.NewBufferString("--boundary\r\n"))
@@ -234,7 +265,7 @@ func TestEdit_MultipartRejected(t *testing.T) {
 	req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
 
 	rr := httptest.NewRecorder()
-	handler := Edit(logger, mock)
+	handler := Edit(logger, mock, &mockWorkflow{})
 	handler.ServeHTTP(rr, req)
 
 	if rr.Code != http.StatusBadRequest {
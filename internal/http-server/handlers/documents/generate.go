@@ -0,0 +1,106 @@
+package documents
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/helpers"
+	"srmt-admin/internal/lib/logger/sl"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
+	document_template "srmt-admin/internal/lib/model/document-template"
+	"srmt-admin/internal/lib/model/file"
+	doctemplates "srmt-admin/internal/lib/service/document-templates"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type documentGenerator interface {
+	Generate(ctx context.Context, kind document_kind.Model, docID int64, req document_template.GenerateRequest) (*document_template.Generated, error)
+}
+
+type generateResponse struct {
+	resp.Response
+	*document_template.Generated
+}
+
+// Generate fills a template of the route's kind from a document and stores
+// the result as DOCX or PDF, attached to the document unless attach=false.
+func Generate(log *slog.Logger, generator documentGenerator, minioRepo helpers.MinioURLGenerator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.documents.generate"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		kind, ok := requestKind(w, r, log)
+		if !ok {
+			return
+		}
+		log = log.With(slog.String("kind", kind.Code))
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Warn("invalid 'id' parameter", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'id' parameter"))
+			return
+		}
+
+		var req document_template.GenerateRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			log.Error("validation failed", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		res, err := generator.Generate(r.Context(), kind, id, req)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Document or template not found"))
+			case errors.Is(err, doctemplates.ErrTemplateKindMismatch):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Template belongs to another document kind"))
+			case errors.Is(err, doctemplates.ErrTemplateInactive):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Template is inactive"))
+			case errors.Is(err, storage.ErrAttachmentsLocked):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Attachments of a signed document cannot be changed, generate with attach=false"))
+			case errors.Is(err, doctemplates.ErrNotDOCX):
+				log.Error("stored template is not a DOCX document", sl.Err(err))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Template file is not a valid DOCX document"))
+			default:
+				log.Error("failed to generate document", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to generate document"))
+			}
+			return
+		}
+
+		if files := helpers.TransformFilesWithURLs(r.Context(), []file.Model{res.Stored}, minioRepo, log); len(files) > 0 {
+			res.File = &files[0]
+		}
+
+		log.Info("document generated", slog.Int64("id", id), slog.Int64("file_id", res.FileID))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, generateResponse{Response: resp.Created(), Generated: res})
+	}
+}
@@ -0,0 +1,104 @@
+package documents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	document_kind "srmt-admin/internal/lib/model/document-kind"
+	document_template "srmt-admin/internal/lib/model/document-template"
+	"srmt-admin/internal/lib/model/file"
+	doctemplates "srmt-admin/internal/lib/service/document-templates"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type mockGenerator struct {
+	err  error
+	kind document_kind.Model
+	req  document_template.GenerateRequest
+}
+
+func (m *mockGenerator) Generate(_ context.Context, kind document_kind.Model, docID int64, req document_template.GenerateRequest) (*document_template.Generated, error) {
+	m.kind, m.req = kind, req
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &document_template.Generated{
+		FileID:   42,
+		FileName: "Приказ 12.docx",
+		Stored:   file.Model{ID: 42, FileName: "Приказ 12.docx", ObjectKey: "generated-documents/x.docx"},
+		Attached: true,
+	}, nil
+}
+
+type urlMinio struct{}
+
+func (urlMinio) GetPresignedURL(_ context.Context, objectName string, _ time.Duration) (*url.URL, error) {
+	return url.Parse("https://minio.local/" + objectName)
+}
+
+func newGenerateRequest(t *testing.T, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/decrees/5/generate", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "5")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	return req.WithContext(testContextWithClaims(req.Context(), 1))
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"success", `{"template_id":3,"format":"pdf"}`, nil, http.StatusCreated},
+		{"missing template", `{"format":"pdf"}`, nil, http.StatusBadRequest},
+		{"bad format", `{"template_id":3,"format":"odt"}`, nil, http.StatusBadRequest},
+		{"not found", `{"template_id":3}`, fmt.Errorf("op: %w", storage.ErrNotFound), http.StatusNotFound},
+		{"other kind", `{"template_id":3}`, fmt.Errorf("op: %w", doctemplates.ErrTemplateKindMismatch), http.StatusBadRequest},
+		{"inactive", `{"template_id":3}`, fmt.Errorf("op: %w", doctemplates.ErrTemplateInactive), http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := &mockGenerator{err: tt.err}
+			rr := httptest.NewRecorder()
+			Generate(slog.New(slog.NewTextHandler(io.Discard, nil)), gen, urlMinio{}).ServeHTTP(rr, newGenerateRequest(t, tt.body))
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+			if gen.kind.Code != decreeKind.Code || gen.req.TemplateID != 3 {
+				t.Errorf("generator called with kind %q, req %+v", gen.kind.Code, gen.req)
+			}
+			var got struct {
+				FileID int64 `json:"file_id"`
+				File   struct {
+					URL string `json:"url"`
+				} `json:"file"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.FileID != 42 || got.File.URL != "https://minio.local/generated-documents/x.docx" {
+				t.Errorf("response = %s", rr.Body.String())
+			}
+		})
+	}
+}
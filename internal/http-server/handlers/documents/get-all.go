@@ -1,4 +1,4 @@
-package reports
+package documents
 
 import (
 	"context"
@@ -11,22 +11,30 @@ import (
 	"srmt-admin/internal/lib/dto"
 	"srmt-admin/internal/lib/helpers"
 	"srmt-admin/internal/lib/logger/sl"
-	"srmt-admin/internal/lib/model/report"
+	"srmt-admin/internal/lib/model/document"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 )
 
-type reportGetter interface {
-	GetAllReports(ctx context.Context, filters dto.GetAllReportsFilters) ([]*report.ResponseModel, error)
+type documentLister interface {
+	GetAllDocuments(ctx context.Context, kind document_kind.Model, filters dto.GetAllDocumentsFilters) ([]*document.ResponseModel, error)
 }
 
-func GetAll(log *slog.Logger, getter reportGetter, minioRepo helpers.MinioURLGenerator) http.HandlerFunc {
+// GetAll lists the documents of the route's kind.
+func GetAll(log *slog.Logger, getter documentLister, minioRepo helpers.MinioURLGenerator) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.reports.get-all"
+		const op = "handlers.documents.get-all"
 		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
 
-		filters := dto.GetAllReportsFilters{}
+		kind, ok := requestKind(w, r, log)
+		if !ok {
+			return
+		}
+		log = log.With(slog.String("kind", kind.Code))
+
+		filters := dto.GetAllDocumentsFilters{}
 		q := r.URL.Query()
 
 		// Filter by type_id
@@ -147,29 +155,30 @@ func GetAll(log *slog.Logger, getter reportGetter, minioRepo helpers.MinioURLGen
 			filters.NumberSearch = &numberSearch
 		}
 
-		documents, err := getter.GetAllReports(r.Context(), filters)
+		documents, err := getter.GetAllDocuments(r.Context(), kind, filters)
 		if err != nil {
-			log.Error("failed to get all reports", sl.Err(err))
+			log.Error("failed to get documents", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, resp.InternalServerError("Failed to retrieve reports"))
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve documents"))
 			return
 		}
 
 		// Transform documents to include presigned URLs
-		documentsWithURLs := make([]*report.ResponseWithURLs, 0, len(documents))
+		documentsWithURLs := make([]*document.ResponseWithURLs, 0, len(documents))
 		for _, doc := range documents {
-			docWithURLs := transformReportToResponse(r.Context(), doc, minioRepo, log)
+			docWithURLs := transformToResponse(r.Context(), doc, minioRepo, log)
 			documentsWithURLs = append(documentsWithURLs, docWithURLs)
 		}
 
-		log.Info("successfully retrieved reports", slog.Int("count", len(documentsWithURLs)))
+		log.Info("successfully retrieved documents", slog.Int("count", len(documentsWithURLs)))
 		render.JSON(w, r, documentsWithURLs)
 	}
 }
 
-func transformReportToResponse(ctx context.Context, doc *report.ResponseModel, minioRepo helpers.MinioURLGenerator, log *slog.Logger) *report.ResponseWithURLs {
-	return &report.ResponseWithURLs{
+func transformToResponse(ctx context.Context, doc *document.ResponseModel, minioRepo helpers.MinioURLGenerator, log *slog.Logger) *document.ResponseWithURLs {
+	return &document.ResponseWithURLs{
 		ID:                 doc.ID,
+		Kind:               doc.Kind,
 		Name:               doc.Name,
 		Number:             doc.Number,
 		DocumentDate:       doc.DocumentDate,
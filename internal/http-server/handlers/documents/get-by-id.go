@@ -1,4 +1,4 @@
-package decrees
+package documents
 
 import (
 	"context"
@@ -10,7 +10,8 @@ import (
 	resp "srmt-admin/internal/lib/api/response"
 	"srmt-admin/internal/lib/helpers"
 	"srmt-admin/internal/lib/logger/sl"
-	"srmt-admin/internal/lib/model/decree"
+	"srmt-admin/internal/lib/model/document"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
 	"srmt-admin/internal/storage"
 
 	"github.com/go-chi/chi/v5"
@@ -18,15 +19,22 @@ import (
 	"github.com/go-chi/render"
 )
 
-type decreeByIDGetter interface {
-	GetDecreeByID(ctx context.Context, id int64) (*decree.ResponseModel, error)
+type documentByIDGetter interface {
+	GetDocumentByID(ctx context.Context, kind document_kind.Model, id int64) (*document.ResponseModel, error)
 }
 
-func GetByID(log *slog.Logger, getter decreeByIDGetter, minioRepo helpers.MinioURLGenerator) http.HandlerFunc {
+// GetByID returns one document of the route's kind with presigned file URLs.
+func GetByID(log *slog.Logger, getter documentByIDGetter, minioRepo helpers.MinioURLGenerator) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.decrees.get-by-id"
+		const op = "handlers.documents.get-by-id"
 		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
 
+		kind, ok := requestKind(w, r, log)
+		if !ok {
+			return
+		}
+		log = log.With(slog.String("kind", kind.Code))
+
 		idStr := chi.URLParam(r, "id")
 		id, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
@@ -36,23 +44,23 @@ func GetByID(log *slog.Logger, getter decreeByIDGetter, minioRepo helpers.MinioU
 			return
 		}
 
-		doc, err := getter.GetDecreeByID(r.Context(), id)
+		doc, err := getter.GetDocumentByID(r.Context(), kind, id)
 		if err != nil {
 			if errors.Is(err, storage.ErrNotFound) {
-				log.Warn("decree not found", slog.Int64("id", id))
+				log.Warn("document not found", slog.Int64("id", id))
 				render.Status(r, http.StatusNotFound)
-				render.JSON(w, r, resp.NotFound("Decree not found"))
+				render.JSON(w, r, resp.NotFound("Document not found"))
 				return
 			}
-			log.Error("failed to get decree", sl.Err(err))
+			log.Error("failed to get document", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, resp.InternalServerError("Failed to retrieve decree"))
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve document"))
 			return
 		}
 
-		docWithURLs := transformDecreeToResponse(r.Context(), doc, minioRepo, log)
+		docWithURLs := transformToResponse(r.Context(), doc, minioRepo, log)
 
-		log.Info("successfully retrieved decree", slog.Int64("id", id))
+		log.Info("successfully retrieved document", slog.Int64("id", id))
 		render.JSON(w, r, docWithURLs)
 	}
 }
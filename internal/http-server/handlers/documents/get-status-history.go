@@ -1,4 +1,4 @@
-package letters
+package documents
 
 import (
 	"context"
@@ -8,22 +8,30 @@ import (
 
 	resp "srmt-admin/internal/lib/api/response"
 	"srmt-admin/internal/lib/logger/sl"
-	"srmt-admin/internal/lib/model/letter"
+	"srmt-admin/internal/lib/model/document"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 )
 
-type letterStatusHistoryGetter interface {
-	GetLetterStatusHistory(ctx context.Context, letterID int64) ([]letter.StatusHistory, error)
+type statusHistoryGetter interface {
+	GetDocumentStatusHistory(ctx context.Context, kind document_kind.Model, docID int64) ([]document.StatusHistory, error)
 }
 
-func GetStatusHistory(log *slog.Logger, getter letterStatusHistoryGetter) http.HandlerFunc {
+// GetStatusHistory returns the status changes of a document of the route's kind.
+func GetStatusHistory(log *slog.Logger, getter statusHistoryGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.letters.get-status-history"
+		const op = "handlers.documents.get-status-history"
 		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
 
+		kind, ok := requestKind(w, r, log)
+		if !ok {
+			return
+		}
+		log = log.With(slog.String("kind", kind.Code))
+
 		idStr := chi.URLParam(r, "id")
 		id, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
@@ -33,7 +41,7 @@ func GetStatusHistory(log *slog.Logger, getter letterStatusHistoryGetter) http.H
 			return
 		}
 
-		history, err := getter.GetLetterStatusHistory(r.Context(), id)
+		history, err := getter.GetDocumentStatusHistory(r.Context(), kind, id)
 		if err != nil {
 			log.Error("failed to get status history", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
@@ -41,7 +49,7 @@ func GetStatusHistory(log *slog.Logger, getter letterStatusHistoryGetter) http.H
 			return
 		}
 
-		log.Info("successfully retrieved letter status history", slog.Int64("id", id), slog.Int("count", len(history)))
+		log.Info("successfully retrieved document status history", slog.Int64("id", id), slog.Int("count", len(history)))
 		render.JSON(w, r, history)
 	}
 }
@@ -0,0 +1,44 @@
+package documents
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/document"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type typeGetter interface {
+	GetDocumentTypes(ctx context.Context, kind document_kind.Model) ([]document.Type, error)
+}
+
+// GetTypes lists the document types of the route's kind.
+func GetTypes(log *slog.Logger, getter typeGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.documents.get-types"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		kind, ok := requestKind(w, r, log)
+		if !ok {
+			return
+		}
+		log = log.With(slog.String("kind", kind.Code))
+
+		types, err := getter.GetDocumentTypes(r.Context(), kind)
+		if err != nil {
+			log.Error("failed to get document types", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve document types"))
+			return
+		}
+
+		log.Info("successfully retrieved document types", slog.Int("count", len(types)))
+		render.JSON(w, r, types)
+	}
+}
@@ -0,0 +1,89 @@
+package documents
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	mwdockind "srmt-admin/internal/http-server/middleware/document-kind"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
+
+	"github.com/go-chi/render"
+)
+
+type kindChecker interface {
+	DocumentKindExists(ctx context.Context, code string) (bool, error)
+}
+
+// requestKind returns the document kind resolved by the route middleware.
+func requestKind(w http.ResponseWriter, r *http.Request, log *slog.Logger) (document_kind.Model, bool) {
+	kind, ok := mwdockind.KindFromContext(r.Context())
+	if !ok {
+		log.Error("document kind is not resolved for the route")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.InternalServerError("Document kind is not resolved"))
+	}
+	return kind, ok
+}
+
+// invalidLinkType returns the first link type that is neither a registered
+// kind nor legal_document, or "" when all are valid.
+func invalidLinkType(ctx context.Context, checker kindChecker, links []dto.LinkedDocumentRequest) (string, error) {
+	for _, link := range links {
+		if link.LinkedDocumentType == document_kind.LegalDocumentLinkType {
+			continue
+		}
+		ok, err := checker.DocumentKindExists(ctx, link.LinkedDocumentType)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return link.LinkedDocumentType, nil
+		}
+	}
+	return "", nil
+}
+
+// writeInvalidLinkType checks the link types of a request and answers 400 or
+// 500 when they cannot be accepted. It reports whether a response was written.
+func writeInvalidLinkType(w http.ResponseWriter, r *http.Request, log *slog.Logger, checker kindChecker, links []dto.LinkedDocumentRequest) bool {
+	bad, err := invalidLinkType(r.Context(), checker, links)
+	if err != nil {
+		log.Error("failed to check linked document types", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.InternalServerError("Failed to check linked documents"))
+		return true
+	}
+	if bad != "" {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.BadRequest(fmt.Sprintf("Invalid linked_document_type %q", bad)))
+		return true
+	}
+	return false
+}
+
+// missingRequiredFields lists the fields the kind requires that req leaves empty.
+func missingRequiredFields(kind document_kind.Model, req addRequest) []string {
+	set := map[string]bool{
+		document_kind.FieldNumber:               req.Number != nil && strings.TrimSpace(*req.Number) != "",
+		document_kind.FieldDescription:          req.Description != nil && strings.TrimSpace(*req.Description) != "",
+		document_kind.FieldResponsibleContactID: req.ResponsibleContactID != nil,
+		document_kind.FieldOrganizationID:       req.OrganizationID != nil,
+		document_kind.FieldExecutorContactID:    req.ExecutorContactID != nil,
+		document_kind.FieldDueDate:              req.DueDate != nil,
+		document_kind.FieldParentDocumentID:     req.ParentDocumentID != nil,
+		document_kind.FieldFileIDs:              len(req.FileIDs) > 0,
+	}
+	missing := make([]string, 0)
+	for _, field := range kind.RequiredFields {
+		if !set[field] {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,98 @@
+package documents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type kindsGetter interface {
+	GetDocumentKinds(ctx context.Context) ([]document_kind.Model, error)
+}
+
+type kindEditor interface {
+	EditDocumentKind(ctx context.Context, code string, req document_kind.EditRequest) error
+}
+
+// GetKinds lists the document kind catalogue in display order.
+func GetKinds(log *slog.Logger, getter kindsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.documents.get-kinds"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		kinds, err := getter.GetDocumentKinds(r.Context())
+		if err != nil {
+			log.Error("failed to get document kinds", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve document kinds"))
+			return
+		}
+
+		log.Info("successfully retrieved document kinds", slog.Int("count", len(kinds)))
+		render.JSON(w, r, kinds)
+	}
+}
+
+// EditKind changes the catalogue entry of a kind: its name, required fields,
+// number uniqueness, activity and display order.
+func EditKind(log *slog.Logger, editor kindEditor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.documents.edit-kind"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		code := chi.URLParam(r, "code")
+
+		var req document_kind.EditRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			log.Error("validation failed", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		for _, field := range req.RequiredFields {
+			if !document_kind.IsRequirableField(field) {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest(fmt.Sprintf("Field %q cannot be required", field)))
+				return
+			}
+		}
+
+		if err := editor.EditDocumentKind(r.Context(), code, req); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				log.Warn("document kind not found", slog.String("code", code))
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Unknown document kind"))
+				return
+			}
+			log.Error("failed to update document kind", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to update document kind"))
+			return
+		}
+
+		log.Info("document kind updated", slog.String("code", code))
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,111 @@
+package documents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"srmt-admin/internal/lib/dto"
+	document_status "srmt-admin/internal/lib/model/document-status"
+	docworkflow "srmt-admin/internal/lib/service/document-workflow"
+)
+
+// mockWorkflow stands in for the document status graph service.
+type mockWorkflow struct {
+	err          error
+	changeCalled bool
+}
+
+func (m *mockWorkflow) AuthorizeInitial(_ context.Context, _ string, _ int, _ docworkflow.Actor) error {
+	return m.err
+}
+
+func (m *mockWorkflow) ChangeStatus(_ context.Context, _ string, _ int64, _ int, _ *string, _ docworkflow.Actor) error {
+	m.changeCalled = true
+	return m.err
+}
+
+func refusal() error {
+	from := 9
+	return &docworkflow.TransitionError{
+		Code:         docworkflow.ReasonNotAllowed,
+		FromStatusID: &from,
+		ToStatusID:   1,
+		Allowed: []document_status.Transition{{
+			ToStatus: document_status.ShortModel{ID: 5, Code: "in_execution", Name: "На исполнении"},
+		}},
+	}
+}
+
+func TestEdit_StatusTransitionRefused(t *testing.T) {
+	editCalled := false
+	mock := &mockDocumentEditor{
+		editFunc: func(context.Context, int64, dto.EditDocumentRequest, int64) error {
+			editCalled = true
+			return nil
+		},
+	}
+	workflow := &mockWorkflow{err: refusal()}
+
+	req := newEditRequest(t, "1", map[string]any{"name": "Новое", "status_id": 1})
+	rr := httptest.NewRecorder()
+	Edit(slog.New(slog.NewTextHandler(io.Discard, nil)), mock, workflow).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("got status %d, want 409, body: %s", rr.Code, rr.Body.String())
+	}
+	if editCalled {
+		t.Fatal("fields must not be edited when the status change is refused")
+	}
+
+	var body struct {
+		Code    string           `json:"code"`
+		Details []map[string]any `json:"details"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != docworkflow.ReasonNotAllowed || len(body.Details) != 1 || body.Details[0]["code"] != "in_execution" {
+		t.Fatalf("unexpected structured error: %s", rr.Body.String())
+	}
+}
+
+func TestEdit_WithoutStatusSkipsWorkflow(t *testing.T) {
+	workflow := &mockWorkflow{err: refusal()}
+	req := newEditRequest(t, "1", map[string]any{"name": "Новое"})
+	rr := httptest.NewRecorder()
+	Edit(slog.New(slog.NewTextHandler(io.Discard, nil)), &mockDocumentEditor{}, workflow).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK || workflow.changeCalled {
+		t.Fatalf("got status %d, workflow called=%v", rr.Code, workflow.changeCalled)
+	}
+}
+
+func TestAdd_CreationStatusRefused(t *testing.T) {
+	added := false
+	mock := &mockDocumentAdder{
+		addFunc: func(context.Context, dto.AddDocumentRequest, int64) (int64, error) {
+			added = true
+			return 1, nil
+		},
+	}
+	statusID := 9
+	body, _ := json.Marshal(addRequest{Name: "Приказ", DocumentDate: time.Now(), TypeID: 1, StatusID: &statusID})
+
+	req := httptest.NewRequest(http.MethodPost, "/decrees", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(testContextWithClaims(req.Context(), 1))
+
+	rr := httptest.NewRecorder()
+	Add(slog.New(slog.NewTextHandler(io.Discard, nil)), mock, &mockWorkflow{err: refusal()}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusConflict || added {
+		t.Fatalf("got status %d, added=%v", rr.Code, added)
+	}
+}
@@ -0,0 +1,411 @@
+// Package dutyrosterhandler exposes the HTTP layer for the station shift
+// roster: rotation patterns, the monthly roster, shift swaps and the
+// "who is on duty now" lookup. Each handler depends on a narrow service
+// interface (one method per endpoint) so tests can mock the exact surface
+// they need.
+package dutyrosterhandler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	rostermodel "srmt-admin/internal/lib/model/duty-roster"
+	"srmt-admin/internal/lib/service/auth"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+// --- Local interfaces — handler depends only on what it calls ---
+
+type PatternLister interface {
+	ListPatterns(ctx context.Context, orgID *int64) ([]rostermodel.Pattern, error)
+}
+
+type PatternGetter interface {
+	GetPattern(ctx context.Context, id int64) (*rostermodel.Pattern, error)
+}
+
+type PatternCreator interface {
+	CreatePattern(ctx context.Context, req rostermodel.PatternRequest, createdByUserID int64) (*rostermodel.Pattern, error)
+}
+
+// PatternUpdater, PatternDeleter and RosterGenerator need GetPattern to
+// authorize against the pattern's own organization (IDOR defense).
+type PatternUpdater interface {
+	GetPattern(ctx context.Context, id int64) (*rostermodel.Pattern, error)
+	UpdatePattern(ctx context.Context, id int64, req rostermodel.PatternRequest) (*rostermodel.Pattern, error)
+}
+
+type PatternDeleter interface {
+	GetPattern(ctx context.Context, id int64) (*rostermodel.Pattern, error)
+	DeletePattern(ctx context.Context, id int64) error
+}
+
+type RosterGenerator interface {
+	GetPattern(ctx context.Context, id int64) (*rostermodel.Pattern, error)
+	Generate(ctx context.Context, patternID int64, year, month int, createdByUserID int64) (*rostermodel.GenerateResult, error)
+}
+
+// validate is shared between all handlers — validator instances are
+// thread-safe and cache reflection results.
+var validate = validator.New()
+
+// --- GET /duty-roster/patterns?organization_id=N ---
+
+func ListPatterns(log *slog.Logger, svc PatternLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.duty-roster.ListPatterns"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		orgID, err := parseOptionalID(r.URL.Query().Get("organization_id"))
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("invalid organization_id"))
+			return
+		}
+		if orgID != nil {
+			if !authorizeOrg(w, r, log, *orgID) {
+				return
+			}
+		} else if !isPrivilegedCaller(r.Context()) {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("organization_id is required"))
+			return
+		}
+
+		patterns, err := svc.ListPatterns(r.Context(), orgID)
+		if err != nil {
+			log.Error("failed to list shift patterns", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to list shift patterns"))
+			return
+		}
+		render.JSON(w, r, patterns)
+	}
+}
+
+// --- GET /duty-roster/patterns/{id} ---
+
+func GetPattern(log *slog.Logger, svc PatternGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.duty-roster.GetPattern"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		p, ok := loadPattern(w, r, log, svc)
+		if !ok {
+			return
+		}
+		render.JSON(w, r, p)
+	}
+}
+
+// --- POST /duty-roster/patterns ---
+
+func CreatePattern(log *slog.Logger, svc PatternCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.duty-roster.CreatePattern"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		userID, ok := requireUserID(w, r, log)
+		if !ok {
+			return
+		}
+
+		var req rostermodel.PatternRequest
+		if !decodeAndValidate(w, r, log, &req) {
+			return
+		}
+		if !authorizeOrg(w, r, log, req.OrganizationID) {
+			return
+		}
+
+		p, err := svc.CreatePattern(r.Context(), req, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrInvalidShiftPattern):
+				log.Warn("invalid shift pattern", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Shift codes must be unique and every cycle day must use a defined code"))
+			case errors.Is(err, storage.ErrShiftPatternExists):
+				log.Warn("shift pattern name taken", sl.Err(err))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Shift pattern with this name already exists"))
+			case errors.Is(err, storage.ErrForeignKeyViolation):
+				log.Warn("referenced record not found", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Organization or employee does not exist"))
+			case errors.Is(err, storage.ErrCheckConstraintViolation):
+				log.Warn("check constraint violation", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("invalid value (CHECK constraint)"))
+			default:
+				log.Error("failed to create shift pattern", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to create shift pattern"))
+			}
+			return
+		}
+
+		log.Info("shift pattern created", slog.Int64("id", p.ID))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, p)
+	}
+}
+
+// --- PUT /duty-roster/patterns/{id} ---
+
+func UpdatePattern(log *slog.Logger, svc PatternUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.duty-roster.UpdatePattern"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		existing, ok := loadPattern(w, r, log, svc)
+		if !ok {
+			return
+		}
+
+		var req rostermodel.PatternRequest
+		// The pattern stays in its organization; fill it in so the
+		// required tag does not force clients to repeat it.
+		req.OrganizationID = existing.OrganizationID
+		if !decodeAndValidate(w, r, log, &req) {
+			return
+		}
+		req.OrganizationID = existing.OrganizationID
+
+		p, err := svc.UpdatePattern(r.Context(), existing.ID, req)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrShiftPatternNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Shift pattern not found"))
+			case errors.Is(err, storage.ErrInvalidShiftPattern):
+				log.Warn("invalid shift pattern", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Shift codes must be unique and every cycle day must use a defined code"))
+			case errors.Is(err, storage.ErrShiftPatternExists):
+				log.Warn("shift pattern name taken", sl.Err(err))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Shift pattern with this name already exists"))
+			case errors.Is(err, storage.ErrForeignKeyViolation):
+				log.Warn("referenced record not found", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Organization or employee does not exist"))
+			case errors.Is(err, storage.ErrCheckConstraintViolation):
+				log.Warn("check constraint violation", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("invalid value (CHECK constraint)"))
+			default:
+				log.Error("failed to update shift pattern", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to update shift pattern"))
+			}
+			return
+		}
+
+		log.Info("shift pattern updated", slog.Int64("id", p.ID))
+		render.JSON(w, r, p)
+	}
+}
+
+// --- DELETE /duty-roster/patterns/{id} ---
+
+func DeletePattern(log *slog.Logger, svc PatternDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.duty-roster.DeletePattern"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		existing, ok := loadPattern(w, r, log, svc)
+		if !ok {
+			return
+		}
+
+		if err := svc.DeletePattern(r.Context(), existing.ID); err != nil {
+			switch {
+			case errors.Is(err, storage.ErrShiftPatternNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Shift pattern not found"))
+			default:
+				log.Error("failed to delete shift pattern", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to delete shift pattern"))
+			}
+			return
+		}
+
+		log.Info("shift pattern deleted", slog.Int64("id", existing.ID))
+		render.Status(r, http.StatusNoContent)
+		render.JSON(w, r, resp.Delete())
+	}
+}
+
+// --- POST /duty-roster/patterns/{id}/generate ---
+
+func Generate(log *slog.Logger, svc RosterGenerator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.duty-roster.Generate"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		userID, ok := requireUserID(w, r, log)
+		if !ok {
+			return
+		}
+		p, ok := loadPattern(w, r, log, svc)
+		if !ok {
+			return
+		}
+
+		var req rostermodel.GenerateRequest
+		if !decodeAndValidate(w, r, log, &req) {
+			return
+		}
+
+		res, err := svc.Generate(r.Context(), p.ID, req.Year, req.Month, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrShiftPatternNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Shift pattern not found"))
+			case errors.Is(err, storage.ErrInvalidShiftPattern):
+				log.Warn("invalid shift pattern", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Shift codes must be unique and every cycle day must use a defined code"))
+			case errors.Is(err, storage.ErrShiftOverlap):
+				log.Warn("shift overlap", sl.Err(err))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Employee already has a duty shift at this time"))
+			case errors.Is(err, storage.ErrForeignKeyViolation):
+				log.Warn("referenced record not found", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Organization or employee does not exist"))
+			default:
+				log.Error("failed to generate roster", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to generate roster"))
+			}
+			return
+		}
+
+		log.Info("roster generated",
+			slog.Int64("pattern_id", p.ID),
+			slog.Int("year", req.Year), slog.Int("month", req.Month),
+			slog.Int("created", res.Created), slog.Int("removed", res.Removed))
+		render.JSON(w, r, res)
+	}
+}
+
+// --- helpers (package-local) ---
+
+// loadPattern reads {id}, loads the pattern and authorizes the caller
+// against its organization. It writes the error response itself and
+// reports whether the handler may continue.
+func loadPattern(w http.ResponseWriter, r *http.Request, log *slog.Logger, svc PatternGetter) (*rostermodel.Pattern, bool) {
+	id, err := parseIDParam(r)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.BadRequest("Invalid id"))
+		return nil, false
+	}
+	p, err := svc.GetPattern(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrShiftPatternNotFound):
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.NotFound("Shift pattern not found"))
+		default:
+			log.Error("failed to load shift pattern", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to load shift pattern"))
+		}
+		return nil, false
+	}
+	if !authorizeOrg(w, r, log, p.OrganizationID) {
+		return nil, false
+	}
+	return p, true
+}
+
+func requireUserID(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	userID, err := auth.GetUserID(r.Context())
+	if err != nil {
+		log.Error("failed to get user id from context", sl.Err(err))
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+		return 0, false
+	}
+	return userID, true
+}
+
+func decodeAndValidate(w http.ResponseWriter, r *http.Request, log *slog.Logger, req any) bool {
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		log.Error("failed to decode request", sl.Err(err))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.BadRequest("Invalid request format"))
+		return false
+	}
+	if err := validate.Struct(req); err != nil {
+		var vErrs validator.ValidationErrors
+		errors.As(err, &vErrs)
+		log.Warn("validation failed", sl.Err(err))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.ValidationErrors(vErrs))
+		return false
+	}
+	return true
+}
+
+func authorizeOrg(w http.ResponseWriter, r *http.Request, log *slog.Logger, orgID int64) bool {
+	if err := auth.CheckOrgAccess(r.Context(), orgID); err != nil {
+		log.Warn("org access denied", sl.Err(err), slog.Int64("organization_id", orgID))
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, resp.Forbidden("Access denied"))
+		return false
+	}
+	return true
+}
+
+// isPrivilegedCaller reports whether the caller is sc or rais (full
+// cross-org access) and may therefore list without an organization filter.
+func isPrivilegedCaller(ctx context.Context) bool {
+	claims, ok := mwauth.ClaimsFromContext(ctx)
+	if !ok || claims == nil {
+		return false
+	}
+	for _, role := range claims.Roles {
+		if role == "sc" || role == "rais" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIDParam reads {id} from the chi URL pattern. We do not accept 0 or
+// negative — those can't match a real serial primary key.
+func parseIDParam(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid id")
+	}
+	return id, nil
+}
+
+func parseOptionalID(s string) (*int64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || v <= 0 {
+		return nil, errors.New("invalid id")
+	}
+	return &v, nil
+}
@@ -0,0 +1,227 @@
+package dutyrosterhandler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	rostermodel "srmt-admin/internal/lib/model/duty-roster"
+	"srmt-admin/internal/storage"
+	"srmt-admin/internal/token"
+)
+
+// --- mocks ---
+
+type mockOnDuty struct {
+	gotOrg int64
+	gotAt  time.Time
+}
+
+func (m *mockOnDuty) OnDuty(_ context.Context, orgID int64, at time.Time) (*rostermodel.OnDuty, error) {
+	m.gotOrg, m.gotAt = orgID, at
+	return &rostermodel.OnDuty{OrganizationID: orgID, At: at, Officers: []rostermodel.Shift{
+		{ID: 1, OrganizationID: orgID, EmployeeID: 100, EmployeeName: "Каримов А."},
+	}}, nil
+}
+
+type mockDecider struct {
+	getOut  *rostermodel.Swap
+	err     error
+	called  bool
+	approve bool
+}
+
+func (m *mockDecider) GetSwap(context.Context, int64) (*rostermodel.Swap, error) {
+	return m.getOut, nil
+}
+
+func (m *mockDecider) DecideSwap(_ context.Context, _ int64, approve bool, _ int64) (*rostermodel.Swap, error) {
+	m.called, m.approve = true, approve
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &rostermodel.Swap{ID: m.getOut.ID, Status: rostermodel.SwapApproved}, nil
+}
+
+type mockPatternCreator struct {
+	err error
+}
+
+func (m *mockPatternCreator) CreatePattern(_ context.Context, req rostermodel.PatternRequest, _ int64) (*rostermodel.Pattern, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &rostermodel.Pattern{ID: 1, OrganizationID: req.OrganizationID, Name: req.Name}, nil
+}
+
+func quietLog() *slog.Logger { return slog.New(slog.NewTextHandler(io.Discard, nil)) }
+
+func requestAs(claims *token.Claims, method, url, body string) *http.Request {
+	var r *http.Request
+	if body != "" {
+		r = httptest.NewRequest(method, url, strings.NewReader(body))
+	} else {
+		r = httptest.NewRequest(method, url, nil)
+	}
+	return r.WithContext(mwauth.ContextWithClaims(r.Context(), claims))
+}
+
+func withID(req *http.Request, id string) *http.Request {
+	rc := chi.NewRouteContext()
+	rc.URLParams.Add("id", id)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rc))
+}
+
+func scClaims(userID int64) *token.Claims {
+	return &token.Claims{UserID: userID, Roles: []string{"sc"}}
+}
+
+// stationClaims is a station-level user confined to its own organization.
+func stationClaims(userID, orgID int64) *token.Claims {
+	return &token.Claims{UserID: userID, Roles: []string{"reservoir_flood"}, OrganizationIDs: []int64{orgID}}
+}
+
+// --- GET /duty-roster/on-duty ---
+
+func TestGetOnDuty_ParsesAtAndReturnsOfficers(t *testing.T) {
+	svc := &mockOnDuty{}
+	req := requestAs(stationClaims(1, 103), http.MethodGet, "/duty-roster/on-duty?organization_id=103&at=2026-03-01T21:30:00%2B05:00", "")
+	rec := httptest.NewRecorder()
+
+	GetOnDuty(quietLog(), svc)(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: want 200, got %d (body %s)", rec.Code, rec.Body.String())
+	}
+	if svc.gotOrg != 103 || !svc.gotAt.Equal(time.Date(2026, 3, 1, 16, 30, 0, 0, time.UTC)) {
+		t.Errorf("service got org %d at %v", svc.gotOrg, svc.gotAt)
+	}
+	var got rostermodel.OnDuty
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got.Officers) != 1 || got.Officers[0].EmployeeID != 100 {
+		t.Errorf("officers = %+v", got.Officers)
+	}
+}
+
+func TestGetOnDuty_RequiresOrganization(t *testing.T) {
+	req := requestAs(scClaims(1), http.MethodGet, "/duty-roster/on-duty", "")
+	rec := httptest.NewRecorder()
+
+	GetOnDuty(quietLog(), &mockOnDuty{})(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status: want 400, got %d", rec.Code)
+	}
+}
+
+func TestGetOnDuty_ForeignStationForbidden(t *testing.T) {
+	svc := &mockOnDuty{}
+	req := requestAs(stationClaims(1, 103), http.MethodGet, "/duty-roster/on-duty?organization_id=104", "")
+	rec := httptest.NewRecorder()
+
+	GetOnDuty(quietLog(), svc)(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status: want 403, got %d", rec.Code)
+	}
+	if svc.gotOrg != 0 {
+		t.Error("service was called for a foreign organization")
+	}
+}
+
+// --- POST /duty-roster/swaps/{id}/approve ---
+
+func TestApproveSwap_ForeignOrganizationForbidden(t *testing.T) {
+	svc := &mockDecider{getOut: &rostermodel.Swap{ID: 9, OrganizationID: 104}}
+	req := withID(requestAs(stationClaims(1, 103), http.MethodPost, "/duty-roster/swaps/9/approve", ""), "9")
+	rec := httptest.NewRecorder()
+
+	ApproveSwap(quietLog(), svc)(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status: want 403, got %d", rec.Code)
+	}
+	if svc.called {
+		t.Error("swap of a foreign organization was decided")
+	}
+}
+
+func TestApproveSwap_MapsDomainErrors(t *testing.T) {
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{storage.ErrSwapSelfDecision, http.StatusForbidden},
+		{storage.ErrShiftOverlap, http.StatusConflict},
+		{storage.ErrInvalidStatus, http.StatusConflict},
+		{storage.ErrShiftAlreadyEnded, http.StatusBadRequest},
+		{storage.ErrSwapNotFound, http.StatusNotFound},
+	}
+	for _, c := range cases {
+		svc := &mockDecider{
+			getOut: &rostermodel.Swap{ID: 9, OrganizationID: 103},
+			err:    fmt.Errorf("service.dutyroster.DecideSwap: %w", c.err),
+		}
+		req := withID(requestAs(scClaims(1), http.MethodPost, "/duty-roster/swaps/9/approve", ""), "9")
+		rec := httptest.NewRecorder()
+
+		ApproveSwap(quietLog(), svc)(rec, req)
+
+		if rec.Code != c.want {
+			t.Errorf("%v: status %d, want %d", c.err, rec.Code, c.want)
+		}
+		if !svc.approve {
+			t.Errorf("%v: approve handler passed approve=false", c.err)
+		}
+	}
+}
+
+// --- POST /duty-roster/patterns ---
+
+func TestCreatePattern_ValidatesShiftDefinitions(t *testing.T) {
+	body := `{
+		"organization_id": 103,
+		"name": "12h day/night",
+		"shifts": [{"code": "D", "start": "8am", "hours": 12}],
+		"cycle": ["D", "-"],
+		"anchor_date": "2026-01-01"
+	}`
+	req := requestAs(scClaims(1), http.MethodPost, "/duty-roster/patterns", body)
+	rec := httptest.NewRecorder()
+
+	CreatePattern(quietLog(), &mockPatternCreator{})(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status: want 400 for a non HH:MM start, got %d", rec.Code)
+	}
+}
+
+func TestCreatePattern_DuplicateNameConflict(t *testing.T) {
+	body := `{
+		"organization_id": 103,
+		"name": "12h day/night",
+		"shifts": [{"code": "D", "start": "08:00", "hours": 12}, {"code": "N", "start": "20:00", "hours": 12}],
+		"cycle": ["D", "N", "-", "-"],
+		"anchor_date": "2026-01-01"
+	}`
+	req := requestAs(scClaims(1), http.MethodPost, "/duty-roster/patterns", body)
+	rec := httptest.NewRecorder()
+
+	CreatePattern(quietLog(), &mockPatternCreator{err: storage.ErrShiftPatternExists})(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("status: want 409, got %d (body %s)", rec.Code, rec.Body.String())
+	}
+}
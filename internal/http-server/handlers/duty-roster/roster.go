@@ -0,0 +1,229 @@
+package dutyrosterhandler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	rostermodel "srmt-admin/internal/lib/model/duty-roster"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type RosterGetter interface {
+	Roster(ctx context.Context, f rostermodel.RosterFilter) ([]rostermodel.Shift, error)
+}
+
+type ShiftCreator interface {
+	CreateShift(ctx context.Context, req rostermodel.CreateShiftRequest, createdByUserID int64) (*rostermodel.Shift, error)
+}
+
+// ShiftDeleter needs GetShift to authorize against the shift's organization.
+type ShiftDeleter interface {
+	GetShift(ctx context.Context, id int64) (*rostermodel.Shift, error)
+	DeleteShift(ctx context.Context, id int64) error
+}
+
+type OnDutyGetter interface {
+	OnDuty(ctx context.Context, orgID int64, at time.Time) (*rostermodel.OnDuty, error)
+}
+
+// --- GET /duty-roster/shifts?organization_id=N&month=YYYY-MM[&employee_id=M] ---
+
+// GetRoster returns one organization's shifts starting in the month. The
+// month is read in loc so a night shift starting at 20:00 on the last day
+// belongs to that month, not the next one. Omitting month means the
+// current one.
+func GetRoster(log *slog.Logger, svc RosterGetter, loc *time.Location) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.duty-roster.GetRoster"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		q := r.URL.Query()
+		orgID, err := parseOptionalID(q.Get("organization_id"))
+		if err != nil || orgID == nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("organization_id is required"))
+			return
+		}
+		employeeID, err := parseOptionalID(q.Get("employee_id"))
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("invalid employee_id"))
+			return
+		}
+
+		from := time.Now().In(loc)
+		from = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, loc)
+		if s := q.Get("month"); s != "" {
+			from, err = time.ParseInLocation("2006-01", s, loc)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("invalid month, expected YYYY-MM"))
+				return
+			}
+		}
+
+		if !authorizeOrg(w, r, log, *orgID) {
+			return
+		}
+
+		shifts, err := svc.Roster(r.Context(), rostermodel.RosterFilter{
+			OrganizationID: *orgID,
+			EmployeeID:     employeeID,
+			From:           from,
+			To:             from.AddDate(0, 1, 0),
+		})
+		if err != nil {
+			log.Error("failed to load roster", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to load roster"))
+			return
+		}
+		render.JSON(w, r, shifts)
+	}
+}
+
+// --- POST /duty-roster/shifts ---
+
+func CreateShift(log *slog.Logger, svc ShiftCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.duty-roster.CreateShift"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		userID, ok := requireUserID(w, r, log)
+		if !ok {
+			return
+		}
+
+		var req rostermodel.CreateShiftRequest
+		if !decodeAndValidate(w, r, log, &req) {
+			return
+		}
+		if !authorizeOrg(w, r, log, req.OrganizationID) {
+			return
+		}
+
+		shift, err := svc.CreateShift(r.Context(), req, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrShiftOverlap):
+				log.Warn("shift overlap", sl.Err(err))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Employee already has a duty shift at this time"))
+			case errors.Is(err, storage.ErrForeignKeyViolation):
+				log.Warn("referenced record not found", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Organization or employee does not exist"))
+			case errors.Is(err, storage.ErrCheckConstraintViolation):
+				log.Warn("check constraint violation", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("invalid value (CHECK constraint)"))
+			default:
+				log.Error("failed to create shift", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to create shift"))
+			}
+			return
+		}
+
+		log.Info("manual shift created", slog.Int64("id", shift.ID))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, shift)
+	}
+}
+
+// --- DELETE /duty-roster/shifts/{id} ---
+
+func DeleteShift(log *slog.Logger, svc ShiftDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.duty-roster.DeleteShift"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		id, err := parseIDParam(r)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid id"))
+			return
+		}
+		shift, err := svc.GetShift(r.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrShiftNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Shift not found"))
+			default:
+				log.Error("failed to load shift", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to load shift"))
+			}
+			return
+		}
+		if !authorizeOrg(w, r, log, shift.OrganizationID) {
+			return
+		}
+
+		if err := svc.DeleteShift(r.Context(), id); err != nil {
+			switch {
+			case errors.Is(err, storage.ErrShiftNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Shift not found"))
+			default:
+				log.Error("failed to delete shift", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to delete shift"))
+			}
+			return
+		}
+
+		log.Info("shift deleted", slog.Int64("id", id))
+		render.Status(r, http.StatusNoContent)
+		render.JSON(w, r, resp.Delete())
+	}
+}
+
+// --- GET /duty-roster/on-duty?organization_id=N[&at=RFC3339] ---
+
+// GetOnDuty answers "who is on duty at station N". at defaults to now.
+func GetOnDuty(log *slog.Logger, svc OnDutyGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.duty-roster.GetOnDuty"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		q := r.URL.Query()
+		orgID, err := parseOptionalID(q.Get("organization_id"))
+		if err != nil || orgID == nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("organization_id is required"))
+			return
+		}
+		at := time.Now()
+		if s := q.Get("at"); s != "" {
+			at, err = time.Parse(time.RFC3339, s)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("invalid at, expected RFC3339"))
+				return
+			}
+		}
+
+		if !authorizeOrg(w, r, log, *orgID) {
+			return
+		}
+
+		onDuty, err := svc.OnDuty(r.Context(), *orgID, at)
+		if err != nil {
+			log.Error("failed to resolve duty officers", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to resolve duty officers"))
+			return
+		}
+		render.JSON(w, r, onDuty)
+	}
+}
@@ -0,0 +1,233 @@
+package dutyrosterhandler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	rostermodel "srmt-admin/internal/lib/model/duty-roster"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// SwapRequester needs GetShift to authorize against the shift's organization.
+type SwapRequester interface {
+	GetShift(ctx context.Context, id int64) (*rostermodel.Shift, error)
+	RequestSwap(ctx context.Context, shiftID int64, req rostermodel.SwapRequest, requestedByUserID int64) (*rostermodel.Swap, error)
+}
+
+type SwapLister interface {
+	ListSwaps(ctx context.Context, f rostermodel.SwapFilter) ([]rostermodel.Swap, error)
+}
+
+// SwapDecider needs GetSwap to authorize against the swap's organization.
+type SwapDecider interface {
+	GetSwap(ctx context.Context, id int64) (*rostermodel.Swap, error)
+	DecideSwap(ctx context.Context, id int64, approve bool, decidedByUserID int64) (*rostermodel.Swap, error)
+}
+
+// --- POST /duty-roster/shifts/{id}/swaps ---
+
+func RequestSwap(log *slog.Logger, svc SwapRequester) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.duty-roster.RequestSwap"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		userID, ok := requireUserID(w, r, log)
+		if !ok {
+			return
+		}
+		shiftID, err := parseIDParam(r)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid id"))
+			return
+		}
+
+		var req rostermodel.SwapRequest
+		if !decodeAndValidate(w, r, log, &req) {
+			return
+		}
+
+		shift, err := svc.GetShift(r.Context(), shiftID)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrShiftNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Shift not found"))
+			default:
+				log.Error("failed to load shift", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to load shift"))
+			}
+			return
+		}
+		if !authorizeOrg(w, r, log, shift.OrganizationID) {
+			return
+		}
+
+		swap, err := svc.RequestSwap(r.Context(), shiftID, req, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrShiftNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Shift not found"))
+			case errors.Is(err, storage.ErrShiftAlreadyEnded):
+				log.Warn("shift already ended", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Shift has already ended"))
+			case errors.Is(err, storage.ErrShiftOverlap):
+				log.Warn("shift overlap", sl.Err(err))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Employee already has a duty shift at this time"))
+			case errors.Is(err, storage.ErrSwapPending):
+				log.Warn("swap already pending", sl.Err(err))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Shift already has a pending swap request"))
+			case errors.Is(err, storage.ErrForeignKeyViolation):
+				log.Warn("replacement not found", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Replacement employee does not exist"))
+			default:
+				log.Error("failed to request swap", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to request swap"))
+			}
+			return
+		}
+
+		log.Info("shift swap requested", slog.Int64("id", swap.ID), slog.Int64("shift_id", shiftID))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, swap)
+	}
+}
+
+// --- GET /duty-roster/swaps?organization_id=N&status=pending ---
+
+func ListSwaps(log *slog.Logger, svc SwapLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.duty-roster.ListSwaps"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		q := r.URL.Query()
+		var f rostermodel.SwapFilter
+		orgID, err := parseOptionalID(q.Get("organization_id"))
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("invalid organization_id"))
+			return
+		}
+		f.OrganizationID = orgID
+		if s := q.Get("status"); s != "" {
+			switch s {
+			case rostermodel.SwapPending, rostermodel.SwapApproved, rostermodel.SwapRejected:
+				f.Status = &s
+			default:
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("invalid status"))
+				return
+			}
+		}
+
+		if orgID != nil {
+			if !authorizeOrg(w, r, log, *orgID) {
+				return
+			}
+		} else if !isPrivilegedCaller(r.Context()) {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("organization_id is required"))
+			return
+		}
+
+		swaps, err := svc.ListSwaps(r.Context(), f)
+		if err != nil {
+			log.Error("failed to list swaps", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to list swaps"))
+			return
+		}
+		render.JSON(w, r, swaps)
+	}
+}
+
+// --- POST /duty-roster/swaps/{id}/approve, POST /duty-roster/swaps/{id}/reject ---
+
+func ApproveSwap(log *slog.Logger, svc SwapDecider) http.HandlerFunc {
+	return decideSwap(log, svc, true, "handlers.duty-roster.ApproveSwap")
+}
+
+func RejectSwap(log *slog.Logger, svc SwapDecider) http.HandlerFunc {
+	return decideSwap(log, svc, false, "handlers.duty-roster.RejectSwap")
+}
+
+func decideSwap(log *slog.Logger, svc SwapDecider, approve bool, op string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		userID, ok := requireUserID(w, r, log)
+		if !ok {
+			return
+		}
+		id, err := parseIDParam(r)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid id"))
+			return
+		}
+
+		existing, err := svc.GetSwap(r.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrSwapNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Swap request not found"))
+			default:
+				log.Error("failed to load swap", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to load swap"))
+			}
+			return
+		}
+		if !authorizeOrg(w, r, log, existing.OrganizationID) {
+			return
+		}
+
+		swap, err := svc.DecideSwap(r.Context(), id, approve, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrSwapNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Swap request not found"))
+			case errors.Is(err, storage.ErrSwapSelfDecision):
+				log.Warn("requester tried to decide own swap", sl.Err(err))
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, resp.Forbidden("Swap request cannot be decided by its requester"))
+			case errors.Is(err, storage.ErrShiftAlreadyEnded):
+				log.Warn("shift already ended", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Shift has already ended"))
+			case errors.Is(err, storage.ErrShiftOverlap):
+				log.Warn("shift overlap", sl.Err(err))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Employee already has a duty shift at this time"))
+			case errors.Is(err, storage.ErrInvalidStatus):
+				log.Warn("swap no longer pending", sl.Err(err))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Swap request has already been decided or the shift was reassigned"))
+			default:
+				log.Error("failed to decide swap", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to decide swap"))
+			}
+			return
+		}
+
+		log.Info("shift swap decided", slog.Int64("id", id), slog.String("status", swap.Status))
+		render.JSON(w, r, swap)
+	}
+}
@@ -0,0 +1,414 @@
+package executioncontrol
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/helpers"
+	"srmt-admin/internal/lib/logger/sl"
+	execution_control "srmt-admin/internal/lib/model/execution-control"
+	execcontrol "srmt-admin/internal/lib/service/execution-control"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type ControlLister interface {
+	List(ctx context.Context, f execution_control.Filter) ([]execution_control.Control, error)
+}
+
+type MyControlsGetter interface {
+	Mine(ctx context.Context, actor execcontrol.Actor) ([]execution_control.Control, error)
+}
+
+type ControlGetter interface {
+	Get(ctx context.Context, id int64, actor execcontrol.Actor) (*execution_control.Control, error)
+}
+
+type ReportSubmitter interface {
+	SubmitReport(ctx context.Context, id int64, req execution_control.SubmitReportRequest, actor execcontrol.Actor) (int64, error)
+}
+
+type Reviewer interface {
+	Review(ctx context.Context, id int64, req execution_control.ReviewRequest, actor execcontrol.Actor) error
+}
+
+type DueGetter interface {
+	Due(ctx context.Context, date string, days int) ([]execution_control.Control, error)
+}
+
+type DashboardGetter interface {
+	Dashboard(ctx context.Context, groupBy, date string, soonDays int) (*execution_control.Dashboard, error)
+}
+
+type reportResponse struct {
+	execution_control.Report
+	Files []dto.FileResponse `json:"files"`
+}
+
+type controlResponse struct {
+	*execution_control.Control
+	Reports []reportResponse `json:"reports"`
+}
+
+type createdResponse struct {
+	resp.Response
+	ID int64 `json:"id"`
+}
+
+func actorFromContext(ctx context.Context) (execcontrol.Actor, bool) {
+	claims, ok := mwauth.ClaimsFromContext(ctx)
+	if !ok || claims == nil {
+		return execcontrol.Actor{}, false
+	}
+	return execcontrol.Actor{UserID: claims.UserID, ContactID: claims.ContactID, Roles: claims.Roles}, true
+}
+
+func parseOptionalInt(r *http.Request, name string) (*int64, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return nil, true
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	return &n, true
+}
+
+// List returns control items. Filters: status, document_type,
+// executor_contact_id, department_id and open=true to hide accepted items.
+func List(log *slog.Logger, svc ControlLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.execution-control.List"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		q := r.URL.Query()
+		var f execution_control.Filter
+		if v := q.Get("status"); v != "" {
+			f.Status = &v
+		}
+		if v := q.Get("document_type"); v != "" {
+			f.DocumentType = &v
+		}
+		var ok bool
+		if f.ExecutorContactID, ok = parseOptionalInt(r, "executor_contact_id"); !ok {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'executor_contact_id' parameter"))
+			return
+		}
+		if f.DepartmentID, ok = parseOptionalInt(r, "department_id"); !ok {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'department_id' parameter"))
+			return
+		}
+		f.OpenOnly = q.Get("open") == "true"
+
+		items, err := svc.List(r.Context(), f)
+		if err != nil {
+			log.Error("failed to retrieve control items", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve control items"))
+			return
+		}
+		render.JSON(w, r, items)
+	}
+}
+
+// Mine returns the caller's open items.
+func Mine(log *slog.Logger, svc MyControlsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.execution-control.Mine"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		actor, ok := actorFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		items, err := svc.Mine(r.Context(), actor)
+		if err != nil {
+			log.Error("failed to retrieve control items", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve control items"))
+			return
+		}
+		render.JSON(w, r, items)
+	}
+}
+
+// Get returns a control item with its reports and their files.
+func Get(log *slog.Logger, svc ControlGetter, minioRepo helpers.MinioURLGenerator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.execution-control.Get"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		actor, ok := actorFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'id' parameter"))
+			return
+		}
+
+		c, err := svc.Get(r.Context(), id, actor)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Control item not found"))
+			case errors.Is(err, execcontrol.ErrForbidden):
+				log.Warn("execution control access denied", sl.Err(err))
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, resp.Forbidden(err.Error()))
+			default:
+				log.Error("failed to retrieve control item", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to retrieve control item"))
+			}
+			return
+		}
+
+		out := controlResponse{Control: c, Reports: make([]reportResponse, 0, len(c.Reports))}
+		for _, rep := range c.Reports {
+			out.Reports = append(out.Reports, reportResponse{
+				Report: rep,
+				Files:  helpers.TransformFilesWithURLs(r.Context(), rep.Files, minioRepo, log),
+			})
+		}
+		render.JSON(w, r, out)
+	}
+}
+
+// SubmitReport files the caller's progress or completion report.
+func SubmitReport(log *slog.Logger, svc ReportSubmitter) http.HandlerFunc {
+	validate := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.execution-control.SubmitReport"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		actor, ok := actorFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'id' parameter"))
+			return
+		}
+
+		var req execution_control.SubmitReportRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+		if err := validate.Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		reportID, err := svc.SubmitReport(r.Context(), id, req, actor)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Control item not found"))
+			case errors.Is(err, execcontrol.ErrNotExecutor):
+				log.Warn("execution control access denied", sl.Err(err))
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, resp.Forbidden(err.Error()))
+			case errors.Is(err, execcontrol.ErrInvalidState),
+				errors.Is(err, storage.ErrInvalidStatus):
+				log.Warn("execution control status conflict", sl.Err(err))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Operation not allowed in the item's current status"))
+			case errors.Is(err, storage.ErrForeignKeyViolation):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid file ID"))
+			default:
+				log.Error("failed to submit report", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to submit report"))
+			}
+			return
+		}
+
+		log.Info("execution report submitted",
+			slog.Int64("control_id", id),
+			slog.Int64("report_id", reportID),
+			slog.String("kind", req.Kind))
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, createdResponse{Response: resp.Created(), ID: reportID})
+	}
+}
+
+// Review accepts or returns a submitted completion.
+func Review(log *slog.Logger, svc Reviewer) http.HandlerFunc {
+	validate := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.execution-control.Review"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		actor, ok := actorFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'id' parameter"))
+			return
+		}
+
+		var req execution_control.ReviewRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+		if err := validate.Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		if err := svc.Review(r.Context(), id, req, actor); err != nil {
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Control item not found"))
+			case errors.Is(err, execcontrol.ErrNotController):
+				log.Warn("execution control access denied", sl.Err(err))
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, resp.Forbidden(err.Error()))
+			case errors.Is(err, execcontrol.ErrInvalidState),
+				errors.Is(err, storage.ErrInvalidStatus):
+				log.Warn("execution control status conflict", sl.Err(err))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Operation not allowed in the item's current status"))
+			case errors.Is(err, execcontrol.ErrCommentRequired),
+				errors.Is(err, execcontrol.ErrDueDateInPast),
+				errors.Is(err, execcontrol.ErrInvalidDate):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest(err.Error()))
+			default:
+				log.Error("failed to review execution", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to review execution"))
+			}
+			return
+		}
+
+		log.Info("execution reviewed", slog.Int64("control_id", id), slog.String("decision", req.Decision))
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// Due lists open items due within ?days (default 3) of ?date (default
+// today), overdue ones included.
+func Due(log *slog.Logger, svc DueGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.execution-control.Due"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		days := execcontrol.DefaultSoonDays
+		if v := r.URL.Query().Get("days"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 || n > 366 {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'days' parameter"))
+				return
+			}
+			days = n
+		}
+
+		items, err := svc.Due(r.Context(), r.URL.Query().Get("date"), days)
+		if err != nil {
+			switch {
+			case errors.Is(err, execcontrol.ErrInvalidDate):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest(err.Error()))
+			default:
+				log.Error("failed to retrieve due items", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to retrieve due items"))
+			}
+			return
+		}
+		render.JSON(w, r, items)
+	}
+}
+
+// Dashboard returns the "on control" counts per executor or department:
+// ?group_by=executor|department (default executor), ?date, ?soon_days.
+func Dashboard(log *slog.Logger, svc DashboardGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.execution-control.Dashboard"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		q := r.URL.Query()
+		groupBy := q.Get("group_by")
+		if groupBy == "" {
+			groupBy = execution_control.GroupByExecutor
+		}
+		soonDays := execcontrol.DefaultSoonDays
+		if v := q.Get("soon_days"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 || n > 366 {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'soon_days' parameter"))
+				return
+			}
+			soonDays = n
+		}
+
+		d, err := svc.Dashboard(r.Context(), groupBy, q.Get("date"), soonDays)
+		if err != nil {
+			switch {
+			case errors.Is(err, execcontrol.ErrInvalidGroupBy),
+				errors.Is(err, execcontrol.ErrInvalidDate):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest(err.Error()))
+			default:
+				log.Error("failed to build dashboard", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to build dashboard"))
+			}
+			return
+		}
+		render.JSON(w, r, d)
+	}
+}
@@ -0,0 +1,71 @@
+package executioncontrol
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	execution_control "srmt-admin/internal/lib/model/execution-control"
+	execcontrol "srmt-admin/internal/lib/service/execution-control"
+	"srmt-admin/internal/token"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type mockSubmitter struct {
+	err   error
+	actor execcontrol.Actor
+}
+
+func (m *mockSubmitter) SubmitReport(_ context.Context, _ int64, _ execution_control.SubmitReportRequest, actor execcontrol.Actor) (int64, error) {
+	m.actor = actor
+	return 5, m.err
+}
+
+func newReportRequest(t *testing.T, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/my-execution/3/reports", bytes.NewReader([]byte(body)))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "3")
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = mwauth.ContextWithClaims(ctx, &token.Claims{UserID: 4, ContactID: 10, Roles: []string{"hrm_employee"}})
+	return req.WithContext(ctx)
+}
+
+func TestSubmitReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		wantCode int
+	}{
+		{name: "completion", body: `{"kind":"completion","text":"Исполнено","file_ids":[7]}`, wantCode: http.StatusCreated},
+		{name: "unknown kind", body: `{"kind":"done","text":"x"}`, wantCode: http.StatusBadRequest},
+		{name: "missing text", body: `{"kind":"progress"}`, wantCode: http.StatusBadRequest},
+		{name: "not the executor", body: `{"kind":"progress","text":"x"}`,
+			err: fmt.Errorf("op: %w", execcontrol.ErrNotExecutor), wantCode: http.StatusForbidden},
+		{name: "already submitted", body: `{"kind":"completion","text":"x"}`,
+			err: fmt.Errorf("op: %w", execcontrol.ErrInvalidState), wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockSubmitter{err: tt.err}
+			rr := httptest.NewRecorder()
+			SubmitReport(slog.New(slog.NewTextHandler(io.Discard, nil)), svc).ServeHTTP(rr, newReportRequest(t, tt.body))
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d, body: %s", rr.Code, tt.wantCode, rr.Body.String())
+			}
+			if tt.wantCode == http.StatusCreated && (svc.actor.UserID != 4 || svc.actor.ContactID != 10) {
+				t.Fatalf("actor not taken from claims: %+v", svc.actor)
+			}
+		})
+	}
+}
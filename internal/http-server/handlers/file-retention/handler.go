@@ -0,0 +1,225 @@
+package fileretention
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/file"
+	"srmt-admin/internal/lib/service/auth"
+	filelifecycle "srmt-admin/internal/lib/service/file-lifecycle"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type PolicyLister interface {
+	Policies(ctx context.Context) ([]file.RetentionPolicy, error)
+}
+
+type PolicySetter interface {
+	SetPolicy(ctx context.Context, categoryID int64, req file.RetentionPolicyRequest, userID int64) error
+}
+
+type PolicyDeleter interface {
+	DeletePolicy(ctx context.Context, categoryID int64) error
+}
+
+type CleanupRunner interface {
+	Cleanup(ctx context.Context, dryRun bool, userID *int64) (*file.CleanupRun, error)
+}
+
+type RunLister interface {
+	Runs(ctx context.Context, limit int) ([]file.CleanupRun, error)
+}
+
+const (
+	defaultRunsLimit = 20
+	maxRunsLimit     = 200
+)
+
+func parseCategoryID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "categoryID"), 10, 64)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.BadRequest("Invalid 'categoryID' parameter"))
+		return 0, false
+	}
+	return id, true
+}
+
+// ListPolicies returns the retention policies. Categories without a policy
+// keep their files forever.
+func ListPolicies(log *slog.Logger, svc PolicyLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.file-retention.ListPolicies"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		policies, err := svc.Policies(r.Context())
+		if err != nil {
+			log.Error("failed to retrieve retention policies", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve retention policies"))
+			return
+		}
+		render.JSON(w, r, policies)
+	}
+}
+
+// SetPolicy creates or replaces the retention policy of a category.
+func SetPolicy(log *slog.Logger, svc PolicySetter) http.HandlerFunc {
+	validate := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.file-retention.SetPolicy"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		userID, err := auth.GetUserID(r.Context())
+		if err != nil {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+		categoryID, ok := parseCategoryID(w, r)
+		if !ok {
+			return
+		}
+
+		var req file.RetentionPolicyRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+		if err := validate.Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		if err := svc.SetPolicy(r.Context(), categoryID, req, userID); err != nil {
+			switch {
+			case errors.Is(err, filelifecycle.ErrEmptyPolicy):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Set 'retention_days' or 'purge_orphans'"))
+			case errors.Is(err, storage.ErrForeignKeyViolation):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Category not found"))
+			default:
+				log.Error("failed to set retention policy", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to set retention policy"))
+			}
+			return
+		}
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// DeletePolicy removes the retention policy of a category; its files are kept
+// from then on.
+func DeletePolicy(log *slog.Logger, svc PolicyDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.file-retention.DeletePolicy"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		categoryID, ok := parseCategoryID(w, r)
+		if !ok {
+			return
+		}
+		if err := svc.DeletePolicy(r.Context(), categoryID); err != nil {
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Retention policy not found"))
+			default:
+				log.Error("failed to delete retention policy", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to delete retention policy"))
+			}
+			return
+		}
+
+		log.Info("retention policy deleted", slog.Int64("category_id", categoryID))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, resp.Delete())
+	}
+}
+
+// Cleanup runs the storage cleanup now and returns its report. With
+// ?dry_run=true nothing is removed.
+func Cleanup(log *slog.Logger, svc CleanupRunner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.file-retention.Cleanup"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		userID, err := auth.GetUserID(r.Context())
+		if err != nil {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		var dryRun bool
+		if v := r.URL.Query().Get("dry_run"); v != "" {
+			dryRun, err = strconv.ParseBool(v)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'dry_run' parameter"))
+				return
+			}
+		}
+
+		run, err := svc.Cleanup(r.Context(), dryRun, &userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, filelifecycle.ErrCleanupRunning):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("A cleanup is already running"))
+			default:
+				log.Error("failed to run file cleanup", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to run file cleanup"))
+			}
+			return
+		}
+		render.JSON(w, r, run)
+	}
+}
+
+// Runs returns the latest cleanup reports, newest first (?limit, default 20).
+func Runs(log *slog.Logger, svc RunLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.file-retention.Runs"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		limit := defaultRunsLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > maxRunsLimit {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'limit' parameter"))
+				return
+			}
+			limit = n
+		}
+
+		runs, err := svc.Runs(r.Context(), limit)
+		if err != nil {
+			log.Error("failed to retrieve cleanup runs", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve cleanup runs"))
+			return
+		}
+		render.JSON(w, r, runs)
+	}
+}
@@ -0,0 +1,108 @@
+package fileretention
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	"srmt-admin/internal/lib/model/file"
+	filelifecycle "srmt-admin/internal/lib/service/file-lifecycle"
+	"srmt-admin/internal/token"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type mockSetter struct {
+	err error
+}
+
+func (m *mockSetter) SetPolicy(_ context.Context, _ int64, _ file.RetentionPolicyRequest, _ int64) error {
+	return m.err
+}
+
+func TestSetPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		wantCode int
+	}{
+		{name: "set", body: `{"retention_days":365}`, wantCode: http.StatusOK},
+		{name: "invalid days", body: `{"retention_days":0}`, wantCode: http.StatusBadRequest},
+		{name: "empty policy", body: `{}`,
+			err: fmt.Errorf("op: %w", filelifecycle.ErrEmptyPolicy), wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			r.Put("/files/retention-policies/{categoryID}", SetPolicy(slog.New(slog.NewTextHandler(io.Discard, nil)), &mockSetter{err: tt.err}))
+
+			req := httptest.NewRequest(http.MethodPut, "/files/retention-policies/3", bytes.NewReader([]byte(tt.body)))
+			req = req.WithContext(mwauth.ContextWithClaims(req.Context(), &token.Claims{UserID: 1}))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d, body: %s", rr.Code, tt.wantCode, rr.Body.String())
+			}
+		})
+	}
+}
+
+type mockRunner struct {
+	dryRun bool
+	err    error
+}
+
+func (m *mockRunner) Cleanup(_ context.Context, dryRun bool, _ *int64) (*file.CleanupRun, error) {
+	m.dryRun = dryRun
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &file.CleanupRun{ID: 7, DryRun: dryRun, ReclaimedBytes: 1024}, nil
+}
+
+func TestCleanup(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		err        error
+		wantCode   int
+		wantDryRun bool
+	}{
+		{name: "run", wantCode: http.StatusOK},
+		{name: "dry run", query: "?dry_run=true", wantCode: http.StatusOK, wantDryRun: true},
+		{name: "invalid flag", query: "?dry_run=maybe", wantCode: http.StatusBadRequest},
+		{name: "already running", err: fmt.Errorf("op: %w", filelifecycle.ErrCleanupRunning), wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &mockRunner{err: tt.err}
+			req := httptest.NewRequest(http.MethodPost, "/files/cleanup"+tt.query, nil)
+			req = req.WithContext(mwauth.ContextWithClaims(req.Context(), &token.Claims{UserID: 1}))
+			rr := httptest.NewRecorder()
+
+			Cleanup(slog.New(slog.NewTextHandler(io.Discard, nil)), runner).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d, body: %s", rr.Code, tt.wantCode, rr.Body.String())
+			}
+			if tt.wantCode == http.StatusOK {
+				if runner.dryRun != tt.wantDryRun {
+					t.Errorf("dry run = %v, want %v", runner.dryRun, tt.wantDryRun)
+				}
+				if !bytes.Contains(rr.Body.Bytes(), []byte(`"reclaimed_bytes":1024`)) {
+					t.Errorf("report missing from response: %s", rr.Body.String())
+				}
+			}
+		})
+	}
+}
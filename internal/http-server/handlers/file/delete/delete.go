@@ -12,20 +12,16 @@ import (
 	"github.com/go-chi/render"
 	resp "srmt-admin/internal/lib/api/response"
 	"srmt-admin/internal/lib/logger/sl"
-	"srmt-admin/internal/lib/model/file"
 	"srmt-admin/internal/storage"
 )
 
-type MetaDataDeleter interface {
-	GetFileByID(ctx context.Context, id int64) (file.Model, error)
-	DeleteFile(ctx context.Context, id int64) error
+// FileRemover удаляет запись файла и, если её больше никто не использует,
+// объект в хранилище.
+type FileRemover interface {
+	Delete(ctx context.Context, id int64) error
 }
 
-type FileDeleter interface {
-	DeleteFile(ctx context.Context, objectName string) error
-}
-
-func New(log *slog.Logger, dataDeleter MetaDataDeleter, fileDeleter FileDeleter) http.HandlerFunc {
+func New(log *slog.Logger, remover FileRemover) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.file.delete.New"
 		log := log.With(
@@ -42,40 +38,30 @@ func New(log *slog.Logger, dataDeleter MetaDataDeleter, fileDeleter FileDeleter)
 			return
 		}
 
-		fileMeta, err := dataDeleter.GetFileByID(r.Context(), fileID)
-		if err != nil {
+		if err := remover.Delete(r.Context(), fileID); err != nil {
 			if errors.Is(err, storage.ErrNotFound) {
 				log.Warn("file not found in db, nothing to delete", slog.Int64("file_id", fileID))
 				render.Status(r, http.StatusNoContent)
 				return
 			}
-			log.Error("failed to get file metadata before deletion", sl.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, resp.InternalServerError("Failed to retrieve file data for deletion"))
-			return
-		}
-
-		if err := dataDeleter.DeleteFile(r.Context(), fileID); err != nil {
-			if errors.Is(err, storage.ErrNotFound) {
-				log.Warn("file already deleted from db", slog.Int64("file_id", fileID))
-				render.Status(r, http.StatusNoContent)
+			if errors.Is(err, storage.ErrAttachmentsLocked) {
+				log.Warn("file is attached to a signed document", slog.Int64("file_id", fileID))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("File is attached to a signed document and cannot be deleted"))
+				return
+			}
+			if errors.Is(err, storage.ErrForeignKeyViolation) {
+				log.Warn("file is still referenced", slog.Int64("file_id", fileID))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("File is used by a document template and cannot be deleted"))
 				return
 			}
-			log.Error("failed to delete file record from db", sl.Err(err))
+			log.Error("failed to delete file", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, resp.InternalServerError("Failed to delete file from database"))
+			render.JSON(w, r, resp.InternalServerError("Failed to delete file"))
 			return
 		}
-		log.Info("file record deleted from database", slog.Int64("file_id", fileID))
-
-		if err := fileDeleter.DeleteFile(r.Context(), fileMeta.ObjectKey); err != nil {
-			log.Error("CRITICAL: failed to delete file from minio storage after deleting from db",
-				sl.Err(err),
-				slog.String("object_key", fileMeta.ObjectKey),
-			)
-		} else {
-			log.Info("file deleted from minio storage", slog.String("object_key", fileMeta.ObjectKey))
-		}
+		log.Info("file deleted", slog.Int64("file_id", fileID))
 
 		render.Status(r, http.StatusNoContent)
 	}
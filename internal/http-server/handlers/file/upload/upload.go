@@ -3,6 +3,7 @@ package upload
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -12,7 +13,7 @@ import (
 	resp "srmt-admin/internal/lib/api/response"
 	"srmt-admin/internal/lib/logger/sl"
 	"srmt-admin/internal/lib/model/category"
-	"srmt-admin/internal/lib/model/file"
+	filelifecycle "srmt-admin/internal/lib/service/file-lifecycle"
 	"strconv"
 	"time"
 
@@ -21,28 +22,28 @@ import (
 	"github.com/google/uuid"
 )
 
-type FileUploader interface {
-	UploadFile(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) error
-	DeleteFile(ctx context.Context, objectName string) error
+// FileStore сканирует, дедуплицирует и сохраняет загруженные файлы.
+type FileStore interface {
+	Store(ctx context.Context, u filelifecycle.Upload) (*filelifecycle.Stored, error)
+	Delete(ctx context.Context, id int64) error
 }
 
-// FileMetaSaver определяет интерфейс для сохранения метаданных файла в БД.
-type FileMetaSaver interface {
-	AddFile(ctx context.Context, fileData file.Model) (int64, error)
+// CategoryGetter определяет интерфейс для получения категории файла.
+type CategoryGetter interface {
 	GetCategoryByID(ctx context.Context, id int64) (category.Model, error)
 }
 
-
 const defaultCategoryID = 1 // "other" category fallback
 
 type uploadedFile struct {
-	ID       int64  `json:"id"`
-	FileName string `json:"file_name"`
+	ID           int64  `json:"id"`
+	FileName     string `json:"file_name"`
+	Deduplicated bool   `json:"deduplicated,omitempty"`
 }
 
 // New создает новый HTTP-хендлер для загрузки файлов.
 // Поддерживает один файл (поле "file") или несколько (поле "files").
-func New(log *slog.Logger, uploader FileUploader, saver FileMetaSaver, parserURL, apiKey string) http.HandlerFunc {
+func New(log *slog.Logger, store FileStore, saver CategoryGetter, parserURL, apiKey string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.file.upload.New"
 		log := log.With(
@@ -127,13 +128,12 @@ func New(log *slog.Logger, uploader FileUploader, saver FileMetaSaver, parserURL
 		// 5. Загружаем каждый файл.
 		datePrefix := fileDate.Format("2006/01/02")
 		var uploaded []uploadedFile
-		var uploadedObjectKeys []string
 
 		for _, fh := range fileHeaders {
 			f, err := fh.Open()
 			if err != nil {
 				log.Error("failed to open file", sl.Err(err), slog.String("filename", fh.Filename))
-				compensateUploads(r.Context(), log, uploader, saver, uploaded, uploadedObjectKeys)
+				compensateUploads(r.Context(), log, store, uploaded)
 				render.Status(r, http.StatusInternalServerError)
 				render.JSON(w, r, resp.InternalServerError("Failed to open uploaded file"))
 				return
@@ -146,38 +146,41 @@ func New(log *slog.Logger, uploader FileUploader, saver FileMetaSaver, parserURL
 				filepath.Ext(fh.Filename),
 			)
 
-			err = uploader.UploadFile(r.Context(), objectKey, f, fh.Size, fh.Header.Get("Content-Type"))
+			stored, err := store.Store(r.Context(), filelifecycle.Upload{
+				FileName:    fh.Filename,
+				ContentType: fh.Header.Get("Content-Type"),
+				Size:        fh.Size,
+				Body:        f,
+				CategoryID:  cat.ID,
+				TargetDate:  fileDate,
+				ObjectKey:   objectKey,
+			})
 			f.Close()
 			if err != nil {
-				log.Error("failed to upload file to storage", sl.Err(err), slog.String("filename", fh.Filename))
-				compensateUploads(r.Context(), log, uploader, saver, uploaded, uploadedObjectKeys)
-				render.Status(r, http.StatusInternalServerError)
-				render.JSON(w, r, resp.InternalServerError("Could not upload file to storage"))
+				compensateUploads(r.Context(), log, store, uploaded)
+				var infected *filelifecycle.InfectedError
+				switch {
+				case errors.As(err, &infected):
+					log.Warn("infected file rejected", slog.String("filename", fh.Filename), slog.String("signature", infected.Signature))
+					render.Status(r, http.StatusBadRequest)
+					render.JSON(w, r, resp.BadRequest(fmt.Sprintf("File '%s' was rejected by the malware scan: %s", fh.Filename, infected.Signature)))
+				case errors.Is(err, filelifecycle.ErrScanUnavailable):
+					log.Error("failed to scan file", sl.Err(err), slog.String("filename", fh.Filename))
+					render.Status(r, http.StatusBadGateway)
+					render.JSON(w, r, resp.BadGateway("File could not be scanned for malware, try again later"))
+				default:
+					log.Error("failed to store file", sl.Err(err), slog.String("filename", fh.Filename))
+					render.Status(r, http.StatusInternalServerError)
+					render.JSON(w, r, resp.InternalServerError("Could not store file"))
+				}
 				return
 			}
-			uploadedObjectKeys = append(uploadedObjectKeys, objectKey)
 
-			fileModel := file.Model{
-				FileName:   fh.Filename,
-				ObjectKey:  objectKey,
-				CategoryID: cat.ID,
-				MimeType:   fh.Header.Get("Content-Type"),
-				SizeBytes:  fh.Size,
-				CreatedAt:  time.Now(),
-				TargetDate: fileDate,
-			}
-
-			fileID, err := saver.AddFile(r.Context(), fileModel)
-			if err != nil {
-				log.Error("failed to save file metadata", sl.Err(err), slog.String("filename", fh.Filename))
-				compensateUploads(r.Context(), log, uploader, saver, uploaded, uploadedObjectKeys)
-				render.Status(r, http.StatusInternalServerError)
-				render.JSON(w, r, resp.InternalServerError("Could not save file metadata"))
-				return
-			}
-
-			uploaded = append(uploaded, uploadedFile{ID: fileID, FileName: fh.Filename})
-			log.Info("file uploaded", slog.Int64("id", fileID), slog.String("object_key", objectKey))
+			uploaded = append(uploaded, uploadedFile{ID: stored.ID, FileName: fh.Filename, Deduplicated: stored.Deduplicated})
+			log.Info("file uploaded",
+				slog.Int64("id", stored.ID),
+				slog.String("object_key", stored.ObjectKey),
+				slog.Bool("deduplicated", stored.Deduplicated))
 		}
 
 		// 6. Production parser — только для одного файла через "file".
@@ -224,17 +227,13 @@ func extractIDs(files []uploadedFile) []int64 {
 	return ids
 }
 
-// compensateUploads удаляет уже загруженные файлы при ошибке.
-func compensateUploads(ctx context.Context, log *slog.Logger, uploader FileUploader, saver FileMetaSaver, uploaded []uploadedFile, objectKeys []string) {
-	for _, key := range objectKeys {
-		if err := uploader.DeleteFile(ctx, key); err != nil {
-			log.Error("compensation: failed to delete file from storage", sl.Err(err), slog.String("object_key", key))
+// compensateUploads удаляет уже сохранённые файлы запроса при ошибке.
+func compensateUploads(ctx context.Context, log *slog.Logger, store FileStore, uploaded []uploadedFile) {
+	for _, f := range uploaded {
+		if err := store.Delete(ctx, f.ID); err != nil {
+			log.Error("compensation: failed to delete uploaded file", sl.Err(err), slog.Int64("file_id", f.ID))
 		}
 	}
-	// Метаданные в БД удалятся каскадно или останутся осиротевшими — логируем
-	if len(uploaded) > 0 {
-		log.Warn("compensation: orphaned file metadata may remain in DB", slog.Int("count", len(uploaded)))
-	}
 }
 
 // sendToParser отправляет файл в prime-parser (для категории production).
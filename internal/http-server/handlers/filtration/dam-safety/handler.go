@@ -0,0 +1,396 @@
+// Package damsafety exposes the dam-safety analytics endpoints under
+// /filtration: instrument trends, the alert list with acknowledgement, and
+// the dashboard. Each handler depends on a narrow interface so tests can
+// mock exactly the surface they call.
+package damsafety
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/filtration"
+	"srmt-admin/internal/lib/service/auth"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type TrendGetter interface {
+	InstrumentOrgID(ctx context.Context, kind string, id int64) (int64, error)
+	Trend(ctx context.Context, kind string, id int64, from, to string, window int) (*filtration.InstrumentTrend, error)
+}
+
+type AlertsGetter interface {
+	GetFiltrationAlerts(ctx context.Context, f filtration.AlertFilter) ([]filtration.Alert, error)
+}
+
+type AlertAcknowledger interface {
+	GetFiltrationAlertOrgID(ctx context.Context, id int64) (int64, error)
+	AcknowledgeFiltrationAlert(ctx context.Context, id int64, userID int64) error
+}
+
+type DashboardGetter interface {
+	Dashboard(ctx context.Context, date string, orgIDs []int64) (*filtration.Dashboard, error)
+}
+
+// --- GET /filtration/analytics/trend ---
+
+// GetTrend: ?instrument_type=location|piezometer&instrument_id=N&from=&to=[&window=N].
+// Authorization is checked against the instrument's own organization.
+func GetTrend(log *slog.Logger, svc TrendGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.filtration.dam-safety.GetTrend"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		q := r.URL.Query()
+
+		kind := q.Get("instrument_type")
+		if kind != filtration.InstrumentLocation && kind != filtration.InstrumentPiezometer {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("'instrument_type' must be 'location' or 'piezometer'"))
+			return
+		}
+
+		id, err := strconv.ParseInt(q.Get("instrument_id"), 10, 64)
+		if err != nil || id <= 0 {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'instrument_id' parameter"))
+			return
+		}
+
+		from, to, ok := parseRange(w, r)
+		if !ok {
+			return
+		}
+
+		window := 0
+		if s := q.Get("window"); s != "" {
+			window, err = strconv.Atoi(s)
+			if err != nil || window < 1 || window > 365 {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'window' parameter (expected 1..365)"))
+				return
+			}
+		}
+
+		orgID, err := svc.InstrumentOrgID(r.Context(), kind, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Instrument not found"))
+				return
+			}
+			log.Error("failed to resolve instrument organization", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve trend"))
+			return
+		}
+		if err := auth.CheckOrgAccess(r.Context(), orgID); err != nil {
+			log.Warn("access denied to organization", slog.Int64("org_id", orgID))
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, resp.Forbidden("Access denied"))
+			return
+		}
+
+		trend, err := svc.Trend(r.Context(), kind, id, from, to, window)
+		if err != nil {
+			log.Error("failed to build trend", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve trend"))
+			return
+		}
+
+		render.JSON(w, r, trend)
+	}
+}
+
+// --- GET /filtration/alerts ---
+
+// GetAlerts: optional ?organization_id, ?from, ?to, ?status=open|all (default open).
+// Non-privileged callers only ever see their own organizations.
+func GetAlerts(log *slog.Logger, getter AlertsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.filtration.dam-safety.GetAlerts"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		q := r.URL.Query()
+		f := filtration.AlertFilter{OnlyOpen: true}
+
+		switch q.Get("status") {
+		case "", "open":
+		case "all":
+			f.OnlyOpen = false
+		default:
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("'status' must be 'open' or 'all'"))
+			return
+		}
+
+		for _, p := range []struct {
+			name string
+			dst  **string
+		}{{"from", &f.From}, {"to", &f.To}} {
+			if s := q.Get(p.name); s != "" {
+				if _, err := time.Parse("2006-01-02", s); err != nil {
+					render.Status(r, http.StatusBadRequest)
+					render.JSON(w, r, resp.BadRequest("Invalid '"+p.name+"' format (expected YYYY-MM-DD)"))
+					return
+				}
+				v := s
+				*p.dst = &v
+			}
+		}
+
+		if s := q.Get("organization_id"); s != "" {
+			orgID, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'organization_id' parameter"))
+				return
+			}
+			if err := auth.CheckOrgAccess(r.Context(), orgID); err != nil {
+				log.Warn("access denied to organization", slog.Int64("org_id", orgID))
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, resp.Forbidden("Access denied"))
+				return
+			}
+			f.OrganizationIDs = []int64{orgID}
+		} else {
+			f.OrganizationIDs = callerOrgScope(r.Context())
+		}
+
+		alerts, err := getter.GetFiltrationAlerts(r.Context(), f)
+		if err != nil {
+			log.Error("failed to get alerts", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve alerts"))
+			return
+		}
+
+		render.JSON(w, r, alerts)
+	}
+}
+
+// --- POST /filtration/alerts/{id}/acknowledge ---
+
+func Acknowledge(log *slog.Logger, repo AlertAcknowledger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.filtration.dam-safety.Acknowledge"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		userID, err := auth.GetUserID(r.Context())
+		if err != nil {
+			log.Error("failed to get user id from context", sl.Err(err))
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil || id <= 0 {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'id' parameter"))
+			return
+		}
+
+		orgID, err := repo.GetFiltrationAlertOrgID(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Alert not found"))
+				return
+			}
+			log.Error("failed to get alert organization", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to acknowledge alert"))
+			return
+		}
+		if err := auth.CheckOrgAccess(r.Context(), orgID); err != nil {
+			log.Warn("access denied to organization", slog.Int64("org_id", orgID))
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, resp.Forbidden("Access denied"))
+			return
+		}
+
+		if err := repo.AcknowledgeFiltrationAlert(r.Context(), id, userID); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Alert not found"))
+				return
+			}
+			log.Error("failed to acknowledge alert", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to acknowledge alert"))
+			return
+		}
+
+		log.Info("alert acknowledged", slog.Int64("id", id), slog.Int64("user_id", userID))
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// --- GET /filtration/dam-safety/dashboard ---
+
+// GetDashboard: ?date=YYYY-MM-DD (defaults to today in loc).
+func GetDashboard(log *slog.Logger, svc DashboardGetter, loc *time.Location) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.filtration.dam-safety.GetDashboard"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		date := r.URL.Query().Get("date")
+		if date == "" {
+			date = time.Now().In(loc).Format("2006-01-02")
+		} else if _, err := time.Parse("2006-01-02", date); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'date' format (expected YYYY-MM-DD)"))
+			return
+		}
+
+		dashboard, err := svc.Dashboard(r.Context(), date, callerOrgScope(r.Context()))
+		if err != nil {
+			log.Error("failed to build dashboard", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve dashboard"))
+			return
+		}
+
+		render.JSON(w, r, dashboard)
+	}
+}
+
+// --- GET /filtration/missing ---
+
+// maxMissingRangeDays bounds the ?from..?to span of GetMissing.
+const maxMissingRangeDays = 366
+
+type MissingGetter interface {
+	Missing(ctx context.Context, orgIDs []int64, from, to string) ([]filtration.MissingReading, error)
+}
+
+// GetMissing: optional ?organization_id, ?from, ?to. Defaults to the 30 days
+// ending yesterday. Lists schedule periods that ended without a reading.
+func GetMissing(log *slog.Logger, svc MissingGetter, loc *time.Location) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.filtration.dam-safety.GetMissing"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		q := r.URL.Query()
+
+		toDate := time.Now().In(loc).AddDate(0, 0, -1)
+		if s := q.Get("to"); s != "" {
+			d, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'to' format (expected YYYY-MM-DD)"))
+				return
+			}
+			toDate = d
+		}
+		fromDate := toDate.AddDate(0, 0, -29)
+		if s := q.Get("from"); s != "" {
+			d, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'from' format (expected YYYY-MM-DD)"))
+				return
+			}
+			fromDate = d
+		}
+		from, to := fromDate.Format("2006-01-02"), toDate.Format("2006-01-02")
+		if to < from {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("'to' must not be before 'from'"))
+			return
+		}
+		if toDate.Sub(fromDate) > maxMissingRangeDays*24*time.Hour {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Range must not exceed 366 days"))
+			return
+		}
+
+		var orgIDs []int64
+		if s := q.Get("organization_id"); s != "" {
+			orgID, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'organization_id' parameter"))
+				return
+			}
+			if err := auth.CheckOrgAccess(r.Context(), orgID); err != nil {
+				log.Warn("access denied to organization", slog.Int64("org_id", orgID))
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, resp.Forbidden("Access denied"))
+				return
+			}
+			orgIDs = []int64{orgID}
+		} else {
+			orgIDs = callerOrgScope(r.Context())
+		}
+
+		missing, err := svc.Missing(r.Context(), orgIDs, from, to)
+		if err != nil {
+			log.Error("failed to detect missing readings", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve missing readings"))
+			return
+		}
+
+		render.JSON(w, r, missing)
+	}
+}
+
+// --- helpers ---
+
+// callerOrgScope returns nil ("all organizations") for sc/rais and the
+// caller's own organization list otherwise. An empty non-nil slice means the
+// caller has no organization and sees nothing.
+func callerOrgScope(ctx context.Context) []int64 {
+	claims, ok := mwauth.ClaimsFromContext(ctx)
+	if !ok || claims == nil {
+		return []int64{}
+	}
+	for _, role := range claims.Roles {
+		if role == "sc" || role == "rais" {
+			return nil
+		}
+	}
+	if claims.OrganizationIDs == nil {
+		return []int64{}
+	}
+	return claims.OrganizationIDs
+}
+
+// parseRange reads the required ?from and ?to dates. Writes the 400 response
+// itself and returns ok=false on invalid input.
+func parseRange(w http.ResponseWriter, r *http.Request) (from, to string, ok bool) {
+	from = r.URL.Query().Get("from")
+	to = r.URL.Query().Get("to")
+
+	fromDate, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.BadRequest("Missing or invalid 'from' parameter (format: YYYY-MM-DD)"))
+		return "", "", false
+	}
+	toDate, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.BadRequest("Missing or invalid 'to' parameter (format: YYYY-MM-DD)"))
+		return "", "", false
+	}
+	if toDate.Before(fromDate) {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.BadRequest("'to' must not be before 'from'"))
+		return "", "", false
+	}
+	return from, to, true
+}
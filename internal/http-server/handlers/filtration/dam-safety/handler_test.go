@@ -0,0 +1,215 @@
+package damsafety
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	"srmt-admin/internal/lib/model/filtration"
+	"srmt-admin/internal/storage"
+	"srmt-admin/internal/token"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type mockTokenVerifier struct {
+	claims *token.Claims
+}
+
+func (m *mockTokenVerifier) Verify(_ string) (*token.Claims, error) {
+	return m.claims, nil
+}
+
+func withAuth(handler http.Handler, claims *token.Claims) http.Handler {
+	return mwauth.Authenticator(&mockTokenVerifier{claims: claims})(handler)
+}
+
+func discardLogger() *slog.Logger { return slog.New(slog.NewTextHandler(io.Discard, nil)) }
+
+type mockTrend struct {
+	orgID  int64
+	orgErr error
+	called bool
+}
+
+func (m *mockTrend) InstrumentOrgID(_ context.Context, _ string, _ int64) (int64, error) {
+	return m.orgID, m.orgErr
+}
+
+func (m *mockTrend) Trend(_ context.Context, kind string, id int64, _, _ string, window int) (*filtration.InstrumentTrend, error) {
+	m.called = true
+	return &filtration.InstrumentTrend{InstrumentType: kind, InstrumentID: id, Window: window}, nil
+}
+
+type mockAlerts struct {
+	got filtration.AlertFilter
+}
+
+func (m *mockAlerts) GetFiltrationAlerts(_ context.Context, f filtration.AlertFilter) ([]filtration.Alert, error) {
+	m.got = f
+	return []filtration.Alert{}, nil
+}
+
+type mockAck struct {
+	orgID int64
+	acked bool
+}
+
+func (m *mockAck) GetFiltrationAlertOrgID(_ context.Context, _ int64) (int64, error) {
+	if m.orgID == 0 {
+		return 0, storage.ErrNotFound
+	}
+	return m.orgID, nil
+}
+
+func (m *mockAck) AcknowledgeFiltrationAlert(_ context.Context, _ int64, _ int64) error {
+	m.acked = true
+	return nil
+}
+
+func doRequest(h http.Handler, method, url string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestGetTrend(t *testing.T) {
+	sc := &token.Claims{UserID: 1, Roles: []string{"sc"}}
+	reservoir := &token.Claims{UserID: 2, Roles: []string{"reservoir"}, OrganizationIDs: []int64{5}}
+
+	tests := []struct {
+		name       string
+		url        string
+		claims     *token.Claims
+		mock       *mockTrend
+		wantStatus int
+		wantCalled bool
+	}{
+		{"ok", "/t?instrument_type=location&instrument_id=1&from=2026-01-01&to=2026-01-31", sc, &mockTrend{orgID: 7}, http.StatusOK, true},
+		{"bad type", "/t?instrument_type=foo&instrument_id=1&from=2026-01-01&to=2026-01-31", sc, &mockTrend{orgID: 7}, http.StatusBadRequest, false},
+		{"bad id", "/t?instrument_type=location&instrument_id=x&from=2026-01-01&to=2026-01-31", sc, &mockTrend{orgID: 7}, http.StatusBadRequest, false},
+		{"missing from", "/t?instrument_type=location&instrument_id=1&to=2026-01-31", sc, &mockTrend{orgID: 7}, http.StatusBadRequest, false},
+		{"reversed range", "/t?instrument_type=location&instrument_id=1&from=2026-02-01&to=2026-01-31", sc, &mockTrend{orgID: 7}, http.StatusBadRequest, false},
+		{"bad window", "/t?instrument_type=piezometer&instrument_id=1&from=2026-01-01&to=2026-01-31&window=0", sc, &mockTrend{orgID: 7}, http.StatusBadRequest, false},
+		{"not found", "/t?instrument_type=location&instrument_id=1&from=2026-01-01&to=2026-01-31", sc, &mockTrend{orgErr: storage.ErrNotFound}, http.StatusNotFound, false},
+		{"foreign org", "/t?instrument_type=location&instrument_id=1&from=2026-01-01&to=2026-01-31", reservoir, &mockTrend{orgID: 7}, http.StatusForbidden, false},
+		{"own org", "/t?instrument_type=location&instrument_id=1&from=2026-01-01&to=2026-01-31", reservoir, &mockTrend{orgID: 5}, http.StatusOK, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := doRequest(withAuth(GetTrend(discardLogger(), tt.mock), tt.claims), http.MethodGet, tt.url)
+			if rr.Code != tt.wantStatus {
+				t.Errorf("want status %d, got %d (%s)", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+			if tt.mock.called != tt.wantCalled {
+				t.Errorf("want Trend called=%v, got %v", tt.wantCalled, tt.mock.called)
+			}
+		})
+	}
+}
+
+func TestGetAlerts_ScopesNonPrivilegedCaller(t *testing.T) {
+	reservoir := &token.Claims{UserID: 2, Roles: []string{"reservoir"}, OrganizationIDs: []int64{5}}
+	mock := &mockAlerts{}
+
+	rr := doRequest(withAuth(GetAlerts(discardLogger(), mock), reservoir), http.MethodGet, "/alerts?status=all")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("want 200, got %d", rr.Code)
+	}
+	if len(mock.got.OrganizationIDs) != 1 || mock.got.OrganizationIDs[0] != 5 {
+		t.Errorf("want org scope [5], got %v", mock.got.OrganizationIDs)
+	}
+	if mock.got.OnlyOpen {
+		t.Error("status=all must not restrict to open alerts")
+	}
+
+	rr = doRequest(withAuth(GetAlerts(discardLogger(), mock), reservoir), http.MethodGet, "/alerts?organization_id=7")
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("want 403 for foreign org, got %d", rr.Code)
+	}
+}
+
+func TestAcknowledge(t *testing.T) {
+	reservoir := &token.Claims{UserID: 2, Roles: []string{"reservoir"}, OrganizationIDs: []int64{5}}
+
+	tests := []struct {
+		name       string
+		orgID      int64
+		wantStatus int
+		wantAcked  bool
+	}{
+		{"own org", 5, http.StatusOK, true},
+		{"foreign org", 7, http.StatusForbidden, false},
+		{"not found", 0, http.StatusNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockAck{orgID: tt.orgID}
+			router := chi.NewRouter()
+			router.Post("/alerts/{id}/acknowledge", Acknowledge(discardLogger(), mock))
+
+			rr := doRequest(withAuth(router, reservoir), http.MethodPost, "/alerts/3/acknowledge")
+			if rr.Code != tt.wantStatus {
+				t.Errorf("want status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if mock.acked != tt.wantAcked {
+				t.Errorf("want acked=%v, got %v", tt.wantAcked, mock.acked)
+			}
+		})
+	}
+}
+
+type mockMissing struct {
+	orgIDs   []int64
+	from, to string
+	called   bool
+}
+
+func (m *mockMissing) Missing(_ context.Context, orgIDs []int64, from, to string) ([]filtration.MissingReading, error) {
+	m.called = true
+	m.orgIDs, m.from, m.to = orgIDs, from, to
+	return []filtration.MissingReading{}, nil
+}
+
+func TestGetMissing(t *testing.T) {
+	reservoir := &token.Claims{UserID: 2, Roles: []string{"reservoir"}, OrganizationIDs: []int64{5}}
+
+	mock := &mockMissing{}
+	rr := doRequest(withAuth(GetMissing(discardLogger(), mock, time.UTC), reservoir), http.MethodGet, "/missing?from=2026-03-01&to=2026-03-31")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("want 200, got %d (%s)", rr.Code, rr.Body.String())
+	}
+	if len(mock.orgIDs) != 1 || mock.orgIDs[0] != 5 || mock.from != "2026-03-01" || mock.to != "2026-03-31" {
+		t.Errorf("unexpected call: orgs=%v from=%s to=%s", mock.orgIDs, mock.from, mock.to)
+	}
+
+	mock = &mockMissing{}
+	rr = doRequest(withAuth(GetMissing(discardLogger(), mock, time.UTC), reservoir), http.MethodGet, "/missing")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("want 200 with defaults, got %d", rr.Code)
+	}
+	if want := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02"); mock.to != want {
+		t.Errorf("want default to=%s, got %s", want, mock.to)
+	}
+
+	for name, url := range map[string]string{
+		"foreign org":    "/missing?organization_id=7",
+		"reversed range": "/missing?from=2026-03-10&to=2026-03-01",
+		"too long":       "/missing?from=2024-01-01&to=2026-03-01",
+	} {
+		mock = &mockMissing{}
+		rr = doRequest(withAuth(GetMissing(discardLogger(), mock, time.UTC), reservoir), http.MethodGet, url)
+		if rr.Code == http.StatusOK || mock.called {
+			t.Errorf("%s: want rejection, got %d (called=%v)", name, rr.Code, mock.called)
+		}
+	}
+}
@@ -21,6 +21,12 @@ type AllMeasurementsUpserter interface {
 	UpsertAllMeasurements(ctx context.Context, req filtration.UpsertAllMeasurementsRequest) error
 }
 
+// AlertEvaluator re-runs dam-safety checks for the saved dates. Optional:
+// a nil evaluator skips evaluation.
+type AlertEvaluator interface {
+	Evaluate(ctx context.Context, orgID int64, date string) ([]filtration.Alert, error)
+}
+
 type UpsertRequest struct {
 	OrganizationID int64  `json:"organization_id" validate:"required"`
 	Date           string `json:"date" validate:"required"`
@@ -42,7 +48,7 @@ type UpsertRequest struct {
 	ClearPiezoComparisonDate  bool `json:"clear_piezo_comparison_date,omitempty"`
 }
 
-func Upsert(log *slog.Logger, upserter AllMeasurementsUpserter) http.HandlerFunc {
+func Upsert(log *slog.Logger, upserter AllMeasurementsUpserter, evaluator AlertEvaluator) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.filtration.measurements.Upsert"
 		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
@@ -156,6 +162,21 @@ func Upsert(log *slog.Logger, upserter AllMeasurementsUpserter) http.HandlerFunc
 			slog.Int64("user_id", userID),
 		)
 
+		// Alert evaluation is best-effort: the measurements are already
+		// committed, so a failure here is logged and the daily sweep retries.
+		if evaluator != nil {
+			seen := make(map[string]struct{}, 3)
+			for _, date := range []string{req.Date, historicalFilterDate, historicalPiezoDate} {
+				if _, ok := seen[date]; ok || date == "" {
+					continue
+				}
+				seen[date] = struct{}{}
+				if _, err := evaluator.Evaluate(r.Context(), req.OrganizationID, date); err != nil {
+					log.Error("failed to evaluate dam-safety alerts", sl.Err(err), slog.String("date", date))
+				}
+			}
+		}
+
 		render.Status(r, http.StatusOK)
 		render.JSON(w, r, resp.OK())
 	}
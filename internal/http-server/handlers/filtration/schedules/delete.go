@@ -0,0 +1,74 @@
+package schedules
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/service/auth"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type ScheduleDeleter interface {
+	GetFiltrationScheduleOrgID(ctx context.Context, id int64) (int64, error)
+	DeleteFiltrationSchedule(ctx context.Context, id int64) error
+}
+
+func Delete(log *slog.Logger, deleter ScheduleDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.filtration.schedules.Delete"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Warn("invalid 'id' parameter", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'id' parameter"))
+			return
+		}
+
+		orgID, err := deleter.GetFiltrationScheduleOrgID(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Schedule not found"))
+				return
+			}
+			log.Error("failed to get schedule org", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to check access"))
+			return
+		}
+
+		if err := auth.CheckOrgAccess(r.Context(), orgID); err != nil {
+			log.Warn("access denied to organization", slog.Int64("org_id", orgID))
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, resp.Forbidden("Access denied"))
+			return
+		}
+
+		if err := deleter.DeleteFiltrationSchedule(r.Context(), id); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Schedule not found"))
+				return
+			}
+			log.Error("failed to delete schedule", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to delete schedule"))
+			return
+		}
+
+		log.Info("schedule deleted", slog.Int64("id", id))
+		render.Status(r, http.StatusNoContent)
+		render.JSON(w, r, resp.Delete())
+	}
+}
@@ -0,0 +1,61 @@
+package schedules
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/filtration"
+	"srmt-admin/internal/lib/service/auth"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type ScheduleGetter interface {
+	GetFiltrationSchedules(ctx context.Context, orgID int64) ([]filtration.Schedule, error)
+}
+
+func Get(log *slog.Logger, getter ScheduleGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.filtration.schedules.Get"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		orgIDStr := r.URL.Query().Get("organization_id")
+		if orgIDStr == "" {
+			log.Warn("missing required 'organization_id' parameter")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("organization_id is required"))
+			return
+		}
+
+		orgID, err := strconv.ParseInt(orgIDStr, 10, 64)
+		if err != nil {
+			log.Warn("invalid organization_id", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid organization_id"))
+			return
+		}
+
+		if err := auth.CheckOrgAccess(r.Context(), orgID); err != nil {
+			log.Warn("access denied to organization", slog.Int64("org_id", orgID))
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, resp.Forbidden("Access denied"))
+			return
+		}
+
+		schedules, err := getter.GetFiltrationSchedules(r.Context(), orgID)
+		if err != nil {
+			log.Error("failed to get schedules", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve schedules"))
+			return
+		}
+
+		log.Info("schedules retrieved", slog.Int64("organization_id", orgID), slog.Int("count", len(schedules)))
+		render.JSON(w, r, schedules)
+	}
+}
@@ -0,0 +1,107 @@
+package schedules
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/filtration"
+	"srmt-admin/internal/lib/service/auth"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type ScheduleUpserter interface {
+	UpsertFiltrationSchedule(ctx context.Context, req filtration.UpsertScheduleRequest, userID int64) (int64, error)
+}
+
+// InstrumentOrgGetter resolves the organization of an instrument so an
+// override cannot be attached to another organization's location or piezometer.
+type InstrumentOrgGetter interface {
+	GetFiltrationLocationOrgID(ctx context.Context, id int64) (int64, error)
+	GetPiezometerOrgID(ctx context.Context, id int64) (int64, error)
+}
+
+// Upsert sets the organization default schedule (no instrument in the body)
+// or the override for one location or piezometer.
+func Upsert(log *slog.Logger, upserter ScheduleUpserter, orgGetter InstrumentOrgGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.filtration.schedules.Upsert"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		userID, err := auth.GetUserID(r.Context())
+		if err != nil {
+			log.Error("failed to get user id from context", sl.Err(err))
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		var req filtration.UpsertScheduleRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to parse request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("failed to parse request"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var validationErrors validator.ValidationErrors
+			errors.As(err, &validationErrors)
+			log.Error("failed to validate request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(validationErrors))
+			return
+		}
+
+		if err := auth.CheckOrgAccess(r.Context(), req.OrganizationID); err != nil {
+			log.Warn("access denied to organization", slog.Int64("org_id", req.OrganizationID))
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, resp.Forbidden("Access denied"))
+			return
+		}
+
+		var instrumentOrgID int64
+		switch {
+		case req.LocationID != nil:
+			instrumentOrgID, err = orgGetter.GetFiltrationLocationOrgID(r.Context(), *req.LocationID)
+		case req.PiezometerID != nil:
+			instrumentOrgID, err = orgGetter.GetPiezometerOrgID(r.Context(), *req.PiezometerID)
+		default:
+			instrumentOrgID = req.OrganizationID
+		}
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Instrument not found"))
+				return
+			}
+			log.Error("failed to get instrument org", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to save schedule"))
+			return
+		}
+		if instrumentOrgID != req.OrganizationID {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Instrument does not belong to the organization"))
+			return
+		}
+
+		id, err := upserter.UpsertFiltrationSchedule(r.Context(), req, userID)
+		if err != nil {
+			log.Error("failed to upsert schedule", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to save schedule"))
+			return
+		}
+
+		log.Info("schedule upserted", slog.Int64("id", id), slog.Int64("organization_id", req.OrganizationID))
+		render.JSON(w, r, map[string]int64{"id": id})
+	}
+}
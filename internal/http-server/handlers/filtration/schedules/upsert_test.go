@@ -0,0 +1,92 @@
+package schedules
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	"srmt-admin/internal/lib/model/filtration"
+	"srmt-admin/internal/storage"
+	"srmt-admin/internal/token"
+)
+
+type mockTokenVerifier struct {
+	claims *token.Claims
+}
+
+func (m *mockTokenVerifier) Verify(_ string) (*token.Claims, error) {
+	return m.claims, nil
+}
+
+type mockUpserter struct {
+	called bool
+	got    filtration.UpsertScheduleRequest
+}
+
+func (m *mockUpserter) UpsertFiltrationSchedule(_ context.Context, req filtration.UpsertScheduleRequest, _ int64) (int64, error) {
+	m.called = true
+	m.got = req
+	return 1, nil
+}
+
+type mockOrgGetter struct {
+	orgs map[int64]int64
+}
+
+func (m *mockOrgGetter) GetFiltrationLocationOrgID(_ context.Context, id int64) (int64, error) {
+	if org, ok := m.orgs[id]; ok {
+		return org, nil
+	}
+	return 0, storage.ErrNotFound
+}
+
+func (m *mockOrgGetter) GetPiezometerOrgID(ctx context.Context, id int64) (int64, error) {
+	return m.GetFiltrationLocationOrgID(ctx, id)
+}
+
+func TestUpsert(t *testing.T) {
+	reservoir := &token.Claims{UserID: 2, Roles: []string{"reservoir"}, OrganizationIDs: []int64{5}}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCalled bool
+	}{
+		{"org default", `{"organization_id":5,"frequency":"daily"}`, http.StatusOK, true},
+		{"location override", `{"organization_id":5,"location_id":10,"frequency":"weekly"}`, http.StatusOK, true},
+		{"piezometer override", `{"organization_id":5,"piezometer_id":10,"frequency":"monthly"}`, http.StatusOK, true},
+		{"bad frequency", `{"organization_id":5,"frequency":"hourly"}`, http.StatusBadRequest, false},
+		{"both instruments", `{"organization_id":5,"location_id":10,"piezometer_id":10,"frequency":"daily"}`, http.StatusBadRequest, false},
+		{"foreign org", `{"organization_id":7,"frequency":"daily"}`, http.StatusForbidden, false},
+		{"foreign instrument", `{"organization_id":5,"location_id":20,"frequency":"daily"}`, http.StatusBadRequest, false},
+		{"unknown instrument", `{"organization_id":5,"location_id":30,"frequency":"daily"}`, http.StatusNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upserter := &mockUpserter{}
+			orgs := &mockOrgGetter{orgs: map[int64]int64{10: 5, 20: 7}}
+			log := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := mwauth.Authenticator(&mockTokenVerifier{claims: reservoir})(Upsert(log, upserter, orgs))
+
+			req := httptest.NewRequest(http.MethodPost, "/schedules", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer test-token")
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("want status %d, got %d (%s)", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+			if upserter.called != tt.wantCalled {
+				t.Errorf("want upsert called=%v, got %v", tt.wantCalled, upserter.called)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package fulltextsearch
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/search"
+	fts "srmt-admin/internal/lib/service/full-text-search"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Searcher interface {
+	Search(ctx context.Context, q search.Query, actor fts.Actor) (*search.Result, error)
+}
+
+type Reindexer interface {
+	Reindex(ctx context.Context) (int, error)
+}
+
+type reindexResponse struct {
+	resp.Response
+	Entries int `json:"entries"`
+}
+
+func parseOptionalInt(r *http.Request, name string) (*int, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return nil, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, false
+	}
+	return &n, true
+}
+
+// Search runs a full-text query: ?q (web search syntax: "phrase", OR,
+// -word), kind (repeatable or comma separated), type_id, status_id, year,
+// organization_id, limit and offset. Registry documents outside the caller's
+// organizations are left out.
+func Search(log *slog.Logger, svc Searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.full-text-search.Search"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		claims, ok := mwauth.ClaimsFromContext(r.Context())
+		if !ok || claims == nil {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		query := r.URL.Query()
+		q := search.Query{Text: query.Get("q")}
+		for _, v := range query["kind"] {
+			for _, kind := range strings.Split(v, ",") {
+				if kind = strings.TrimSpace(kind); kind != "" {
+					q.Kinds = append(q.Kinds, kind)
+				}
+			}
+		}
+
+		for _, p := range []struct {
+			name string
+			dst  **int
+		}{
+			{"type_id", &q.TypeID},
+			{"status_id", &q.StatusID},
+			{"year", &q.Year},
+		} {
+			if *p.dst, ok = parseOptionalInt(r, p.name); !ok {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid '"+p.name+"' parameter"))
+				return
+			}
+		}
+		if v := query.Get("organization_id"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'organization_id' parameter"))
+				return
+			}
+			q.OrganizationID = &id
+		}
+		if v := query.Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'limit' parameter"))
+				return
+			}
+			q.Limit = n
+		}
+		if v := query.Get("offset"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'offset' parameter"))
+				return
+			}
+			q.Offset = n
+		}
+
+		result, err := svc.Search(r.Context(), q, fts.Actor{Roles: claims.Roles, OrganizationIDs: claims.OrganizationIDs})
+		if err != nil {
+			if errors.Is(err, fts.ErrQueryTooShort) || errors.Is(err, fts.ErrQueryTooLong) {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest(errors.Unwrap(err).Error()))
+				return
+			}
+			log.Error("failed to search documents", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to search documents"))
+			return
+		}
+
+		render.JSON(w, r, result)
+	}
+}
+
+// Reindex rebuilds the search index from the source tables and requeues
+// attachments whose text extraction failed.
+func Reindex(log *slog.Logger, svc Reindexer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.full-text-search.Reindex"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		n, err := svc.Reindex(r.Context())
+		if err != nil {
+			log.Error("failed to rebuild search index", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to rebuild search index"))
+			return
+		}
+
+		log.Info("search index rebuilt", slog.Int("entries", n))
+		render.JSON(w, r, reindexResponse{Response: resp.OK(), Entries: n})
+	}
+}
@@ -0,0 +1,69 @@
+package fulltextsearch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	"srmt-admin/internal/lib/model/search"
+	fts "srmt-admin/internal/lib/service/full-text-search"
+	"srmt-admin/internal/token"
+)
+
+type mockSearcher struct {
+	query search.Query
+	actor fts.Actor
+	err   error
+}
+
+func (m *mockSearcher) Search(_ context.Context, q search.Query, actor fts.Actor) (*search.Result, error) {
+	m.query, m.actor = q, actor
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &search.Result{Hits: []search.Hit{}}, nil
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		err      error
+		wantCode int
+	}{
+		{name: "ok", url: "/search?q=приказ&kind=decree,letter&kind=report&year=2025&limit=10", wantCode: http.StatusOK},
+		{name: "bad year", url: "/search?q=приказ&year=abc", wantCode: http.StatusBadRequest},
+		{name: "bad offset", url: "/search?q=приказ&offset=-1", wantCode: http.StatusBadRequest},
+		{name: "short query", url: "/search?q=a", err: fmt.Errorf("op: %w", fts.ErrQueryTooShort), wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockSearcher{err: tt.err}
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			req = req.WithContext(mwauth.ContextWithClaims(req.Context(),
+				&token.Claims{UserID: 1, Roles: []string{"ges"}, OrganizationIDs: []int64{3}}))
+			rr := httptest.NewRecorder()
+
+			Search(slog.New(slog.NewTextHandler(io.Discard, nil)), svc).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d, body: %s", rr.Code, tt.wantCode, rr.Body.String())
+			}
+			if tt.name != "ok" {
+				return
+			}
+			if len(svc.query.Kinds) != 3 || svc.query.Year == nil || *svc.query.Year != 2025 || svc.query.Limit != 10 {
+				t.Fatalf("query not parsed: %+v", svc.query)
+			}
+			if len(svc.actor.OrganizationIDs) != 1 || svc.actor.OrganizationIDs[0] != 3 {
+				t.Fatalf("actor not taken from claims: %+v", svc.actor)
+			}
+		})
+	}
+}
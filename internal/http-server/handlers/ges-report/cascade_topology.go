@@ -0,0 +1,196 @@
+package gesreport
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	model "srmt-admin/internal/lib/model/ges-report"
+	"srmt-admin/internal/lib/service/auth"
+	cascaderouting "srmt-admin/internal/lib/service/cascade-routing"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+// maxRoutingRangeDays caps the routing view so a single request stays cheap.
+const maxRoutingRangeDays = 366
+
+type CascadeTopologyGetter interface {
+	Topology(ctx context.Context, cascadeOrgID int64) (*model.CascadeTopology, error)
+}
+
+type CascadeTopologyReplacer interface {
+	ReplaceTopology(ctx context.Context, req model.ReplaceTopologyRequest) error
+}
+
+type CascadeRoutingGetter interface {
+	Routing(ctx context.Context, cascadeOrgID int64, from, to time.Time) (*model.CascadeRouting, error)
+}
+
+// GetCascadeTopology returns the upstream→downstream links of a cascade.
+// Cascade users may only read their own cascade.
+func GetCascadeTopology(log *slog.Logger, svc CascadeTopologyGetter, checker auth.CascadeChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.ges-report.GetCascadeTopology"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		cascadeID, err := parseIntParam(r, "cascade_id")
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("cascade_id is required"))
+			return
+		}
+
+		if err := auth.CheckCascadeStationAccess(r.Context(), cascadeID, checker); err != nil {
+			log.Warn("cascade access denied for topology get", sl.Err(err))
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, resp.Forbidden("access denied"))
+			return
+		}
+
+		topology, err := svc.Topology(r.Context(), cascadeID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("cascade not found"))
+				return
+			}
+			log.Error("failed to get cascade topology", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("failed to get cascade topology"))
+			return
+		}
+
+		render.JSON(w, r, topology)
+	}
+}
+
+// ReplaceCascadeTopology stores the whole topology of a cascade. The links
+// must form a loop-free chain of the cascade's own stations.
+func ReplaceCascadeTopology(log *slog.Logger, svc CascadeTopologyReplacer) http.HandlerFunc {
+	validate := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.ges-report.ReplaceCascadeTopology"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		var req model.ReplaceTopologyRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("invalid request format"))
+			return
+		}
+
+		if err := validate.Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			log.Error("validation failed", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		if err := svc.ReplaceTopology(r.Context(), req); err != nil {
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("cascade not found"))
+			case errors.Is(err, cascaderouting.ErrInvalidTopology):
+				log.Warn("invalid cascade topology", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest(err.Error()))
+			default:
+				log.Error("failed to replace cascade topology", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("failed to save cascade topology"))
+			}
+			return
+		}
+
+		log.Info("cascade topology replaced",
+			slog.Int64("cascade_id", req.CascadeOrgID),
+			slog.Int("links", len(req.Links)))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// GetCascadeRouting returns the daily inflow of each station next to the
+// lagged outflow of its upstream stations for [from, to]. Defaults to the 30
+// days ending yesterday.
+func GetCascadeRouting(log *slog.Logger, svc CascadeRoutingGetter, checker auth.CascadeChecker, loc *time.Location) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.ges-report.GetCascadeRouting"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		cascadeID, err := parseIntParam(r, "cascade_id")
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("cascade_id is required"))
+			return
+		}
+
+		q := r.URL.Query()
+		now := time.Now().In(loc)
+		toDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+		if s := q.Get("to"); s != "" {
+			d, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("invalid 'to' format, expected YYYY-MM-DD"))
+				return
+			}
+			toDate = d
+		}
+		fromDate := toDate.AddDate(0, 0, -29)
+		if s := q.Get("from"); s != "" {
+			d, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("invalid 'from' format, expected YYYY-MM-DD"))
+				return
+			}
+			fromDate = d
+		}
+		if toDate.Before(fromDate) {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("'to' must not be before 'from'"))
+			return
+		}
+		if toDate.Sub(fromDate) > maxRoutingRangeDays*24*time.Hour {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("range must not exceed 366 days"))
+			return
+		}
+
+		if err := auth.CheckCascadeStationAccess(r.Context(), cascadeID, checker); err != nil {
+			log.Warn("cascade access denied for routing view", sl.Err(err))
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, resp.Forbidden("access denied"))
+			return
+		}
+
+		routing, err := svc.Routing(r.Context(), cascadeID, fromDate, toDate)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("cascade not found"))
+				return
+			}
+			log.Error("failed to build cascade routing", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("failed to build cascade routing"))
+			return
+		}
+
+		render.JSON(w, r, routing)
+	}
+}
@@ -0,0 +1,128 @@
+package gesreport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	model "srmt-admin/internal/lib/model/ges-report"
+	cascaderouting "srmt-admin/internal/lib/service/cascade-routing"
+	"srmt-admin/internal/token"
+)
+
+type fakeCascadeRoutingSvc struct {
+	replaceErr error
+	from, to   time.Time
+	called     bool
+}
+
+func (f *fakeCascadeRoutingSvc) Topology(_ context.Context, id int64) (*model.CascadeTopology, error) {
+	f.called = true
+	return &model.CascadeTopology{CascadeOrgID: id, Links: []model.TopologyLink{}}, nil
+}
+
+func (f *fakeCascadeRoutingSvc) ReplaceTopology(_ context.Context, _ model.ReplaceTopologyRequest) error {
+	f.called = true
+	return f.replaceErr
+}
+
+func (f *fakeCascadeRoutingSvc) Routing(_ context.Context, id int64, from, to time.Time) (*model.CascadeRouting, error) {
+	f.called = true
+	f.from, f.to = from, to
+	return &model.CascadeRouting{CascadeOrgID: id, Days: []model.RoutingDay{}}, nil
+}
+
+type noParentChecker struct{}
+
+func (noParentChecker) GetOrganizationParentID(_ context.Context, _ int64) (*int64, error) {
+	return nil, nil
+}
+
+func setupCascadeTopologyRouter(svc *fakeCascadeRoutingSvc, claims *token.Claims) http.Handler {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	r := chi.NewRouter()
+	r.Use(mwauth.Authenticator(&mockTokenVerifier{claims: claims}))
+	r.Get("/cascade-topology", GetCascadeTopology(logger, svc, noParentChecker{}))
+	r.Put("/cascade-topology", ReplaceCascadeTopology(logger, svc))
+	r.Get("/cascade-routing", GetCascadeRouting(logger, svc, noParentChecker{}, time.UTC))
+	return r
+}
+
+func doCascadeTopology(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer faketoken")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestGetCascadeTopology_CascadeForeignForbidden(t *testing.T) {
+	svc := &fakeCascadeRoutingSvc{}
+	h := setupCascadeTopologyRouter(svc, &token.Claims{UserID: 1, OrganizationIDs: []int64{10}, Roles: []string{"cascade"}})
+
+	rr := doCascadeTopology(h, http.MethodGet, "/cascade-topology?cascade_id=20", "")
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if svc.called {
+		t.Fatal("service must not be called on denied access")
+	}
+
+	rr = doCascadeTopology(h, http.MethodGet, "/cascade-topology?cascade_id=10", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 for own cascade, got %d", rr.Code)
+	}
+}
+
+func TestReplaceCascadeTopology_InvalidTopology(t *testing.T) {
+	svc := &fakeCascadeRoutingSvc{replaceErr: fmt.Errorf("op: %w: loop", cascaderouting.ErrInvalidTopology)}
+	h := setupCascadeTopologyRouter(svc, &token.Claims{UserID: 1, Roles: []string{"sc"}})
+
+	body := `{"cascade_id": 10, "links": [{"station_id": 11, "lag_hours": 6, "tolerance_pct": 10}]}`
+	rr := doCascadeTopology(h, http.MethodPut, "/cascade-topology", body)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestReplaceCascadeTopology_ValidationFails(t *testing.T) {
+	svc := &fakeCascadeRoutingSvc{}
+	h := setupCascadeTopologyRouter(svc, &token.Claims{UserID: 1, Roles: []string{"sc"}})
+
+	body := `{"cascade_id": 10, "links": [{"station_id": 11, "lag_hours": -1, "tolerance_pct": 10}]}`
+	rr := doCascadeTopology(h, http.MethodPut, "/cascade-topology", body)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+	if svc.called {
+		t.Fatal("service must not be called on invalid body")
+	}
+}
+
+func TestGetCascadeRouting_Range(t *testing.T) {
+	svc := &fakeCascadeRoutingSvc{}
+	h := setupCascadeTopologyRouter(svc, &token.Claims{UserID: 1, Roles: []string{"sc"}})
+
+	rr := doCascadeTopology(h, http.MethodGet, "/cascade-routing?cascade_id=10&from=2026-05-01&to=2026-05-10", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if svc.from.Format("2006-01-02") != "2026-05-01" || svc.to.Format("2006-01-02") != "2026-05-10" {
+		t.Fatalf("unexpected range %s..%s", svc.from, svc.to)
+	}
+
+	rr = doCascadeTopology(h, http.MethodGet, "/cascade-routing?cascade_id=10&from=2024-01-01&to=2026-01-01", "")
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for too long range, got %d", rr.Code)
+	}
+}
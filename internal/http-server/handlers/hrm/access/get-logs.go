@@ -48,6 +48,7 @@ func GetLogs(log *slog.Logger, svc LogGetter) http.HandlerFunc {
 		if v := q.Get("date_to"); v != "" {
 			filters.DateTo = &v
 		}
+		filters.AlertsOnly = q.Get("alerts_only") == "true"
 
 		logs, err := svc.GetLogs(r.Context(), filters)
 		if err != nil {
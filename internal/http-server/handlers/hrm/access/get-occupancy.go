@@ -1,36 +1,35 @@
-package decrees
+package access
 
 import (
 	"context"
 	"log/slog"
 	"net/http"
-
 	resp "srmt-admin/internal/lib/api/response"
 	"srmt-admin/internal/lib/logger/sl"
-	decree_type "srmt-admin/internal/lib/model/decree-type"
+	"srmt-admin/internal/lib/model/hrm/access"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 )
 
-type decreeTypeGetter interface {
-	GetAllDecreeTypes(ctx context.Context) ([]decree_type.Model, error)
+type OccupancyGetter interface {
+	GetOccupancy(ctx context.Context) ([]*access.ZoneOccupancy, error)
 }
 
-func GetTypes(log *slog.Logger, getter decreeTypeGetter) http.HandlerFunc {
+// GetOccupancy returns who is inside each zone now against its capacity
+func GetOccupancy(log *slog.Logger, svc OccupancyGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.decrees.get-types"
+		const op = "handlers.hrm.access.GetOccupancy"
 		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
 
-		types, err := getter.GetAllDecreeTypes(r.Context())
+		zones, err := svc.GetOccupancy(r.Context())
 		if err != nil {
-			log.Error("failed to get decree types", sl.Err(err))
+			log.Error("failed to get zone occupancy", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, resp.InternalServerError("Failed to retrieve decree types"))
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve zone occupancy"))
 			return
 		}
 
-		log.Info("successfully retrieved decree types", slog.Int("count", len(types)))
-		render.JSON(w, r, types)
+		render.JSON(w, r, zones)
 	}
 }
@@ -0,0 +1,79 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/hrm/access"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type EventIngester interface {
+	Ingest(ctx context.Context, events []dto.AccessEventInput) (*access.IngestResult, error)
+}
+
+// IngestEvent stores one event posted by a turnstile or door controller
+func IngestEvent(log *slog.Logger, svc EventIngester) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.access.IngestEvent"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		var req dto.AccessEventInput
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request body"))
+			return
+		}
+
+		ingest(w, r, log, svc, []dto.AccessEventInput{req}, req)
+	}
+}
+
+// IngestEvents stores a batch of events posted by a controller, e.g. the
+// ones it buffered while offline
+func IngestEvents(log *slog.Logger, svc EventIngester) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.access.IngestEvents"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		var req dto.IngestAccessEventsRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request body"))
+			return
+		}
+
+		ingest(w, r, log, svc, req.Events, req)
+	}
+}
+
+func ingest(w http.ResponseWriter, r *http.Request, log *slog.Logger, svc EventIngester, events []dto.AccessEventInput, req any) {
+	if err := validator.New().Struct(req); err != nil {
+		var vErrs validator.ValidationErrors
+		errors.As(err, &vErrs)
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.ValidationErrors(vErrs))
+		return
+	}
+
+	result, err := svc.Ingest(r.Context(), events)
+	if err != nil {
+		log.Error("failed to ingest access events", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.InternalServerError("Failed to ingest access events"))
+		return
+	}
+
+	log.Info("access events ingested",
+		slog.Int("accepted", result.Accepted),
+		slog.Int("duplicates", result.Duplicates),
+		slog.Int("alerts", result.Alerts))
+	render.JSON(w, r, result)
+}
@@ -0,0 +1,50 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/storage"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type ScheduleDeleter interface {
+	DeleteSchedule(ctx context.Context, organizationID int64) error
+}
+
+// DeleteSchedule returns an organization to the default week schedule
+func DeleteSchedule(log *slog.Logger, svc ScheduleDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.calendar.DeleteSchedule"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		orgID, err := strconv.ParseInt(chi.URLParam(r, "organizationId"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid organization ID"))
+			return
+		}
+
+		if err := svc.DeleteSchedule(r.Context(), orgID); err != nil {
+			if errors.Is(err, storage.ErrWorkScheduleNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Work schedule not found"))
+				return
+			}
+			log.Error("failed to delete work schedule", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to delete work schedule"))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, resp.OK())
+	}
+}
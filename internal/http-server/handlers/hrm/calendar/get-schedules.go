@@ -0,0 +1,34 @@
+package calendar
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/hrm/calendar"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type SchedulesGetter interface {
+	GetSchedules(ctx context.Context) ([]*calendar.Schedule, error)
+}
+
+func GetSchedules(log *slog.Logger, svc SchedulesGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.calendar.GetSchedules"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		schedules, err := svc.GetSchedules(r.Context())
+		if err != nil {
+			log.Error("failed to get work schedules", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to get work schedules"))
+			return
+		}
+
+		render.JSON(w, r, schedules)
+	}
+}
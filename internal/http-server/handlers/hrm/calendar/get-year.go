@@ -0,0 +1,56 @@
+package calendar
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/hrm/calendar"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type YearGetter interface {
+	GetYear(ctx context.Context, year int, organizationID *int64) (*calendar.Year, error)
+}
+
+// GetYear returns the production calendar of a year, under the week
+// schedule of ?organization_id= or the default one
+func GetYear(log *slog.Logger, svc YearGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.calendar.GetYear"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		year, err := strconv.Atoi(chi.URLParam(r, "year"))
+		if err != nil || year < 2000 || year > 2100 {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid year"))
+			return
+		}
+
+		var orgID *int64
+		if v := r.URL.Query().Get("organization_id"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'organization_id' parameter"))
+				return
+			}
+			orgID = &id
+		}
+
+		result, err := svc.GetYear(r.Context(), year, orgID)
+		if err != nil {
+			log.Error("failed to get calendar", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to get calendar"))
+			return
+		}
+
+		render.JSON(w, r, result)
+	}
+}
@@ -0,0 +1,82 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/storage"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type Publisher interface {
+	Publish(ctx context.Context, year int, req dto.PublishCalendarRequest, publishedBy int64) error
+}
+
+// Publish replaces the holidays and transferred and shortened days of a year
+func Publish(log *slog.Logger, svc Publisher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.calendar.Publish"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		claims, ok := mwauth.ClaimsFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("unauthorized"))
+			return
+		}
+
+		year, err := strconv.Atoi(chi.URLParam(r, "year"))
+		if err != nil || year < 2000 || year > 2100 {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid year"))
+			return
+		}
+
+		var req dto.PublishCalendarRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		if err := svc.Publish(r.Context(), year, req, claims.ContactID); err != nil {
+			if errors.Is(err, storage.ErrDateOutsideYear) {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Every date must fall within the year"))
+				return
+			}
+			if errors.Is(err, storage.ErrCalendarDayDuplicate) || errors.Is(err, storage.ErrDuplicate) {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("A day is listed more than once"))
+				return
+			}
+			log.Error("failed to publish calendar", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to publish calendar"))
+			return
+		}
+
+		log.Info("calendar published", slog.Int("year", year))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, resp.OK())
+	}
+}
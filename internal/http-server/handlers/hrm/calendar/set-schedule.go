@@ -0,0 +1,68 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/storage"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type ScheduleSetter interface {
+	SetSchedule(ctx context.Context, organizationID int64, req dto.SetWorkScheduleRequest) error
+}
+
+func SetSchedule(log *slog.Logger, svc ScheduleSetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.calendar.SetSchedule"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		orgID, err := strconv.ParseInt(chi.URLParam(r, "organizationId"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid organization ID"))
+			return
+		}
+
+		var req dto.SetWorkScheduleRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		if err := svc.SetSchedule(r.Context(), orgID, req); err != nil {
+			if errors.Is(err, storage.ErrForeignKeyViolation) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Organization not found"))
+				return
+			}
+			log.Error("failed to set work schedule", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to set work schedule"))
+			return
+		}
+
+		log.Info("work schedule set", slog.Int64("organization_id", orgID), slog.Int("week_days", req.WeekDays))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, resp.OK())
+	}
+}
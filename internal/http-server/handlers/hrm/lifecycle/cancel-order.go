@@ -0,0 +1,53 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/storage"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type OrderCanceller interface {
+	Cancel(ctx context.Context, id int64) error
+}
+
+func CancelOrder(log *slog.Logger, svc OrderCanceller) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.lifecycle.CancelOrder"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid ID"))
+			return
+		}
+
+		if err := svc.Cancel(r.Context(), id); err != nil {
+			if errors.Is(err, storage.ErrHROrderNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Order not found"))
+				return
+			}
+			if errors.Is(err, storage.ErrInvalidStatus) {
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Only a scheduled order can be cancelled"))
+				return
+			}
+			log.Error("failed to cancel order", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to cancel order"))
+			return
+		}
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,77 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/hrm/lifecycle"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type Dismisser interface {
+	Dismiss(ctx context.Context, req dto.DismissalRequest, createdBy int64) (*lifecycle.Order, error)
+}
+
+func Dismiss(log *slog.Logger, svc Dismisser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.lifecycle.Dismiss"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		claims, ok := mwauth.ClaimsFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("unauthorized"))
+			return
+		}
+
+		var req dto.DismissalRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request body"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		order, err := svc.Dismiss(r.Context(), req, claims.ContactID)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrPersonnelRecordNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Personnel record not found"))
+			case errors.Is(err, storage.ErrEmployeeDismissed):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Employee is already dismissed"))
+			case errors.Is(err, storage.ErrHROrderScheduled):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Employee already has a scheduled order"))
+			case errors.Is(err, storage.ErrInvalidDateRange):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Last day is before the hire date"))
+			default:
+				log.Error("failed to dismiss", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to create dismissal order"))
+			}
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, order)
+	}
+}
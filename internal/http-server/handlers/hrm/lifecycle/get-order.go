@@ -0,0 +1,50 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/hrm/lifecycle"
+	"srmt-admin/internal/storage"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type OrderGetter interface {
+	GetOrder(ctx context.Context, id int64) (*lifecycle.Order, error)
+}
+
+func GetOrder(log *slog.Logger, svc OrderGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.lifecycle.GetOrder"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid ID"))
+			return
+		}
+
+		order, err := svc.GetOrder(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, storage.ErrHROrderNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Order not found"))
+				return
+			}
+			log.Error("failed to get order", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve order"))
+			return
+		}
+
+		render.JSON(w, r, order)
+	}
+}
@@ -0,0 +1,54 @@
+package lifecycle
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/hrm/lifecycle"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type OrderAllGetter interface {
+	GetOrders(ctx context.Context, filters dto.HROrderFilters) ([]*lifecycle.Order, error)
+}
+
+func GetOrders(log *slog.Logger, svc OrderAllGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.lifecycle.GetOrders"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		q := r.URL.Query()
+		var filters dto.HROrderFilters
+
+		if v := q.Get("employee_id"); v != "" {
+			val, _ := strconv.ParseInt(v, 10, 64)
+			filters.EmployeeID = &val
+		}
+		if v := q.Get("kind"); v != "" {
+			filters.Kind = &v
+		}
+		if v := q.Get("status"); v != "" {
+			filters.Status = &v
+		}
+		if v := q.Get("year"); v != "" {
+			val, _ := strconv.Atoi(v)
+			filters.Year = &val
+		}
+
+		orders, err := svc.GetOrders(r.Context(), filters)
+		if err != nil {
+			log.Error("failed to get orders", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve orders"))
+			return
+		}
+
+		render.JSON(w, r, orders)
+	}
+}
@@ -0,0 +1,83 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/hrm/lifecycle"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type Transferrer interface {
+	Transfer(ctx context.Context, req dto.TransferRequest, createdBy int64) (*lifecycle.Order, error)
+}
+
+func Transfer(log *slog.Logger, svc Transferrer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.lifecycle.Transfer"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		claims, ok := mwauth.ClaimsFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("unauthorized"))
+			return
+		}
+
+		var req dto.TransferRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request body"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		order, err := svc.Transfer(r.Context(), req, claims.ContactID)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrPersonnelRecordNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Personnel record not found"))
+			case errors.Is(err, storage.ErrEmployeeDismissed):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Employee is dismissed"))
+			case errors.Is(err, storage.ErrHROrderScheduled):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Employee already has a scheduled order"))
+			case errors.Is(err, storage.ErrNothingToTransfer):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Transfer changes neither department, position nor salary"))
+			case errors.Is(err, storage.ErrInvalidDateRange):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Effective date is before the hire date"))
+			case errors.Is(err, storage.ErrForeignKeyViolation), errors.Is(err, storage.ErrNotFound):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid department or position"))
+			default:
+				log.Error("failed to transfer", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to create transfer order"))
+			}
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, order)
+	}
+}
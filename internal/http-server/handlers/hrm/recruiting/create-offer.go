@@ -1,19 +1,58 @@
 package recruiting
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/storage"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
 )
 
-func CreateOffer(log *slog.Logger) http.HandlerFunc {
+type OfferCreator interface {
+	CreateOffer(ctx context.Context, req dto.CreateJobOfferRequest) (int64, error)
+}
+
+func CreateOffer(log *slog.Logger, svc OfferCreator) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.hrm.recruiting.CreateOffer"
-		_ = log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		var req dto.CreateJobOfferRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request body"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		id, err := svc.CreateOffer(r.Context(), req)
+		if err != nil {
+			if errors.Is(err, storage.ErrCandidateNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Candidate not found"))
+				return
+			}
+			log.Error("failed to create offer", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to create offer"))
+			return
+		}
 
-		render.Status(r, http.StatusNotImplemented)
-		render.JSON(w, r, map[string]string{"error": "Offers not implemented yet"})
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, map[string]int64{"id": id})
 	}
 }
@@ -0,0 +1,51 @@
+package recruiting
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	recruiting "srmt-admin/internal/lib/model/hrm/recruiting"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type OfferAllGetter interface {
+	GetAllOffers(ctx context.Context, filters dto.JobOfferFilters) ([]*recruiting.JobOffer, error)
+}
+
+func GetOffers(log *slog.Logger, svc OfferAllGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.recruiting.GetOffers"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		q := r.URL.Query()
+		var filters dto.JobOfferFilters
+
+		if v := q.Get("candidate_id"); v != "" {
+			val, _ := strconv.ParseInt(v, 10, 64)
+			filters.CandidateID = &val
+		}
+		if v := q.Get("vacancy_id"); v != "" {
+			val, _ := strconv.ParseInt(v, 10, 64)
+			filters.VacancyID = &val
+		}
+		if v := q.Get("status"); v != "" {
+			filters.Status = &v
+		}
+
+		offers, err := svc.GetAllOffers(r.Context(), filters)
+		if err != nil {
+			log.Error("failed to get offers", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve offers"))
+			return
+		}
+
+		render.JSON(w, r, offers)
+	}
+}
@@ -1,18 +1,47 @@
 package recruiting
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	recruiting "srmt-admin/internal/lib/model/hrm/recruiting"
+	"strconv"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 )
 
-func GetOnboardings(log *slog.Logger) http.HandlerFunc {
+type OnboardingAllGetter interface {
+	GetAllOnboardings(ctx context.Context, filters dto.OnboardingFilters) ([]*recruiting.Onboarding, error)
+}
+
+func GetOnboardings(log *slog.Logger, svc OnboardingAllGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.hrm.recruiting.GetOnboardings"
-		_ = log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		q := r.URL.Query()
+		var filters dto.OnboardingFilters
+
+		if v := q.Get("employee_id"); v != "" {
+			val, _ := strconv.ParseInt(v, 10, 64)
+			filters.EmployeeID = &val
+		}
+		if v := q.Get("status"); v != "" {
+			filters.Status = &v
+		}
+
+		onboardings, err := svc.GetAllOnboardings(r.Context(), filters)
+		if err != nil {
+			log.Error("failed to get onboardings", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve onboardings"))
+			return
+		}
 
-		render.JSON(w, r, []struct{}{})
+		render.JSON(w, r, onboardings)
 	}
 }
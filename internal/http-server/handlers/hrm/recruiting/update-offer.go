@@ -1,19 +1,70 @@
 package recruiting
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/storage"
+	"strconv"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
 )
 
-func UpdateOffer(log *slog.Logger) http.HandlerFunc {
+type OfferUpdater interface {
+	UpdateOffer(ctx context.Context, id int64, req dto.UpdateJobOfferRequest) error
+}
+
+func UpdateOffer(log *slog.Logger, svc OfferUpdater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.hrm.recruiting.UpdateOffer"
-		_ = log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid ID"))
+			return
+		}
+
+		var req dto.UpdateJobOfferRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request body"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		if err := svc.UpdateOffer(r.Context(), id, req); err != nil {
+			if errors.Is(err, storage.ErrOfferNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Offer not found"))
+				return
+			}
+			if errors.Is(err, storage.ErrInvalidStatus) {
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Offer cannot be changed in its current status"))
+				return
+			}
+			log.Error("failed to update offer", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to update offer"))
+			return
+		}
 
-		render.Status(r, http.StatusNotImplemented)
-		render.JSON(w, r, map[string]string{"error": "Offers not implemented yet"})
+		render.JSON(w, r, resp.OK())
 	}
 }
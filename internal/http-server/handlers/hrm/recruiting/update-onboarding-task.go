@@ -0,0 +1,70 @@
+package recruiting
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/storage"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type OnboardingTaskUpdater interface {
+	UpdateOnboardingTask(ctx context.Context, id int64, req dto.UpdateOnboardingTaskRequest) error
+}
+
+func UpdateOnboardingTask(log *slog.Logger, svc OnboardingTaskUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.recruiting.UpdateOnboardingTask"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid ID"))
+			return
+		}
+
+		var req dto.UpdateOnboardingTaskRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request body"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		if err := svc.UpdateOnboardingTask(r.Context(), id, req); err != nil {
+			if errors.Is(err, storage.ErrOnboardingTaskNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Onboarding task not found"))
+				return
+			}
+			if errors.Is(err, storage.ErrForeignKeyViolation) {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid assignee ID"))
+				return
+			}
+			log.Error("failed to update onboarding task", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to update onboarding task"))
+			return
+		}
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,66 @@
+package salary
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type RateCreator interface {
+	CreateRate(ctx context.Context, req dto.CreatePayrollRateRequest, createdBy int64) (int64, error)
+}
+
+func CreateRate(log *slog.Logger, svc RateCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.salary.CreateRate"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		claims, ok := mwauth.ClaimsFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("unauthorized"))
+			return
+		}
+
+		var req dto.CreatePayrollRateRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request body"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		id, err := svc.CreateRate(r.Context(), req, claims.ContactID)
+		if err != nil {
+			if errors.Is(err, storage.ErrPayrollRateExists) {
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("A rate with this code is already set for this date"))
+				return
+			}
+			log.Error("failed to create payroll rate", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to create payroll rate"))
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, map[string]int64{"id": id})
+	}
+}
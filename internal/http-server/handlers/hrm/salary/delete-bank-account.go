@@ -0,0 +1,48 @@
+package salary
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/storage"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type BankAccountDeleter interface {
+	DeleteBankAccount(ctx context.Context, employeeID int64) error
+}
+
+func DeleteBankAccount(log *slog.Logger, svc BankAccountDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.salary.DeleteBankAccount"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		employeeID, err := strconv.ParseInt(chi.URLParam(r, "employeeId"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid employee ID"))
+			return
+		}
+
+		if err := svc.DeleteBankAccount(r.Context(), employeeID); err != nil {
+			if errors.Is(err, storage.ErrBankAccountNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Bank account not found"))
+				return
+			}
+			log.Error("failed to delete bank account", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to delete bank account"))
+			return
+		}
+
+		render.JSON(w, r, resp.Delete())
+	}
+}
@@ -1,19 +1,68 @@
 package salary
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"strconv"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
+	"github.com/xuri/excelize/v2"
 )
 
-func Export(log *slog.Logger) http.HandlerFunc {
+type RegisterExporter interface {
+	PaymentRegister(ctx context.Context, year, month int, departmentID *int64) (*excelize.File, error)
+}
+
+// Export serves the bank payment register of the approved salaries of
+// ?period_year and ?period_month, optionally of one ?department_id
+func Export(log *slog.Logger, svc RegisterExporter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.hrm.salary.Export"
-		_ = log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		q := r.URL.Query()
+		year, err := strconv.Atoi(q.Get("period_year"))
+		if err != nil || year < 2020 {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid or missing period_year"))
+			return
+		}
+		month, err := strconv.Atoi(q.Get("period_month"))
+		if err != nil || month < 1 || month > 12 {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid or missing period_month"))
+			return
+		}
+		var departmentID *int64
+		if v := q.Get("department_id"); v != "" {
+			val, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid department_id"))
+				return
+			}
+			departmentID = &val
+		}
+
+		f, err := svc.PaymentRegister(r.Context(), year, month, departmentID)
+		if err != nil {
+			log.Error("failed to build payment register", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to export payment register"))
+			return
+		}
+		defer f.Close()
+
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="payment_register_%d_%02d.xlsx"`, year, month))
 
-		render.Status(r, http.StatusNotImplemented)
-		render.JSON(w, r, map[string]string{"error": "Export not implemented yet"})
+		if err := f.Write(w); err != nil {
+			log.Error("failed to write excel to response", sl.Err(err))
+		}
 	}
 }
@@ -0,0 +1,34 @@
+package salary
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/hrm/salary"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type BankAccountsGetter interface {
+	GetBankAccounts(ctx context.Context) ([]*salary.BankAccount, error)
+}
+
+func GetBankAccounts(log *slog.Logger, svc BankAccountsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.salary.GetBankAccounts"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		accounts, err := svc.GetBankAccounts(r.Context())
+		if err != nil {
+			log.Error("failed to get bank accounts", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve bank accounts"))
+			return
+		}
+
+		render.JSON(w, r, accounts)
+	}
+}
@@ -0,0 +1,46 @@
+package salary
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/hrm/salary"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type RatesGetter interface {
+	GetRates(ctx context.Context, onDate string) ([]*salary.PayrollRate, error)
+}
+
+// GetRates lists the payroll rates with their history, or only those in
+// effect on ?date (YYYY-MM-DD)
+func GetRates(log *slog.Logger, svc RatesGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.salary.GetRates"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		date := r.URL.Query().Get("date")
+		if date != "" {
+			if _, err := time.Parse("2006-01-02", date); err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid date, expected YYYY-MM-DD"))
+				return
+			}
+		}
+
+		rates, err := svc.GetRates(r.Context(), date)
+		if err != nil {
+			log.Error("failed to get payroll rates", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve payroll rates"))
+			return
+		}
+
+		render.JSON(w, r, rates)
+	}
+}
@@ -0,0 +1,87 @@
+package salary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	salary "srmt-admin/internal/lib/model/hrm/salary"
+	salarysvc "srmt-admin/internal/lib/service/hrm/salary"
+	"srmt-admin/internal/storage"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type PayslipGetter interface {
+	GetByID(ctx context.Context, id int64) (*salary.Salary, error)
+	Payslip(ctx context.Context, sal *salary.Salary, format string) ([]byte, error)
+}
+
+// GetPayslip serves the payslip of a calculated salary as PDF, or as XLSX
+// with ?format=xlsx
+func GetPayslip(log *slog.Logger, svc PayslipGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.salary.GetPayslip"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid ID"))
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = salarysvc.FormatPDF
+		}
+		if format != salarysvc.FormatPDF && format != salarysvc.FormatXLSX {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid format, expected pdf or xlsx"))
+			return
+		}
+
+		sal, err := svc.GetByID(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, storage.ErrSalaryNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Salary record not found"))
+				return
+			}
+			log.Error("failed to get salary", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to get payslip"))
+			return
+		}
+		if sal.Status == "draft" || sal.Status == "cancelled" {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Salary record is not calculated"))
+			return
+		}
+
+		data, err := svc.Payslip(r.Context(), sal, format)
+		if err != nil {
+			log.Error("failed to render payslip", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to render payslip"))
+			return
+		}
+
+		contentType := "application/pdf"
+		if format == salarysvc.FormatXLSX {
+			contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition",
+			fmt.Sprintf(`attachment; filename="payslip_%d_%d_%02d.%s"`, sal.EmployeeID, sal.PeriodYear, sal.PeriodMonth, format))
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(data)
+	}
+}
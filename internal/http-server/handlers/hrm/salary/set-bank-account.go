@@ -0,0 +1,65 @@
+package salary
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/storage"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type BankAccountSetter interface {
+	SetBankAccount(ctx context.Context, employeeID int64, req dto.SetBankAccountRequest) error
+}
+
+func SetBankAccount(log *slog.Logger, svc BankAccountSetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.salary.SetBankAccount"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		employeeID, err := strconv.ParseInt(chi.URLParam(r, "employeeId"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid employee ID"))
+			return
+		}
+
+		var req dto.SetBankAccountRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request body"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		if err := svc.SetBankAccount(r.Context(), employeeID, req); err != nil {
+			if errors.Is(err, storage.ErrForeignKeyViolation) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Employee not found"))
+				return
+			}
+			log.Error("failed to set bank account", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to set bank account"))
+			return
+		}
+
+		render.JSON(w, r, resp.OK())
+	}
+}
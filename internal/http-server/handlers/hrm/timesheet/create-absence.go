@@ -0,0 +1,78 @@
+package timesheet
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type AbsenceCreator interface {
+	CreateAbsence(ctx context.Context, req dto.CreateAbsenceRequest, createdBy int64) (int64, error)
+}
+
+type CreateAbsenceResponse struct {
+	resp.Response
+	ID int64 `json:"id"`
+}
+
+func CreateAbsence(log *slog.Logger, svc AbsenceCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.timesheet.CreateAbsence"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		claims, ok := mwauth.ClaimsFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("unauthorized"))
+			return
+		}
+
+		var req dto.CreateAbsenceRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		id, err := svc.CreateAbsence(r.Context(), req, claims.ContactID)
+		if err != nil {
+			if errors.Is(err, storage.ErrInvalidDateRange) {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("End date must not be before start date"))
+				return
+			}
+			if errors.Is(err, storage.ErrForeignKeyViolation) {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Employee not found"))
+				return
+			}
+			log.Error("failed to create absence", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to create absence"))
+			return
+		}
+
+		log.Info("absence created", slog.Int64("id", id))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, CreateAbsenceResponse{Response: resp.OK(), ID: id})
+	}
+}
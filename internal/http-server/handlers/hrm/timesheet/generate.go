@@ -0,0 +1,55 @@
+package timesheet
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	ts "srmt-admin/internal/lib/model/hrm/timesheet"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type TimesheetGenerator interface {
+	Generate(ctx context.Context, req dto.GenerateTimesheetRequest) (*ts.GenerateResult, error)
+}
+
+// Generate fills a month's timesheets from vacations, absences, holidays and
+// access-control events. Manual entries are kept.
+func Generate(log *slog.Logger, svc TimesheetGenerator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.timesheet.Generate"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		var req dto.GenerateTimesheetRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		result, err := svc.Generate(r.Context(), req)
+		if err != nil {
+			log.Error("failed to generate timesheet", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to generate timesheet"))
+			return
+		}
+
+		render.JSON(w, r, result)
+	}
+}
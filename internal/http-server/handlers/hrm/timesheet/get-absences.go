@@ -0,0 +1,53 @@
+package timesheet
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	ts "srmt-admin/internal/lib/model/hrm/timesheet"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type AbsenceGetter interface {
+	GetAbsences(ctx context.Context, filters dto.AbsenceFilters) ([]*ts.Absence, error)
+}
+
+func GetAbsences(log *slog.Logger, svc AbsenceGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.timesheet.GetAbsences"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		var filters dto.AbsenceFilters
+		q := r.URL.Query()
+
+		if v := q.Get("employee_id"); v != "" {
+			val, _ := strconv.ParseInt(v, 10, 64)
+			filters.EmployeeID = &val
+		}
+		if v := q.Get("type"); v != "" {
+			filters.Type = &v
+		}
+		if v := q.Get("from"); v != "" {
+			filters.From = &v
+		}
+		if v := q.Get("to"); v != "" {
+			filters.To = &v
+		}
+
+		absences, err := svc.GetAbsences(r.Context(), filters)
+		if err != nil {
+			log.Error("failed to get absences", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve absences"))
+			return
+		}
+
+		render.JSON(w, r, absences)
+	}
+}
@@ -0,0 +1,58 @@
+package training
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type CertificationTypeCreator interface {
+	CreateCertificationType(ctx context.Context, req dto.CreateCertificationTypeRequest) (int64, error)
+}
+
+func CreateCertificationType(log *slog.Logger, svc CertificationTypeCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.training.CreateCertificationType"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		var req dto.CreateCertificationTypeRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request body"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		id, err := svc.CreateCertificationType(r.Context(), req)
+		if err != nil {
+			if errors.Is(err, storage.ErrDuplicate) {
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Certification type with this name already exists"))
+				return
+			}
+			log.Error("failed to create certification type", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to create certification type"))
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, map[string]int64{"id": id})
+	}
+}
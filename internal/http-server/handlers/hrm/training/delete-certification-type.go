@@ -0,0 +1,53 @@
+package training
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/storage"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type CertificationTypeDeleter interface {
+	DeleteCertificationType(ctx context.Context, id int64) error
+}
+
+func DeleteCertificationType(log *slog.Logger, svc CertificationTypeDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.training.DeleteCertificationType"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid ID"))
+			return
+		}
+
+		if err := svc.DeleteCertificationType(r.Context(), id); err != nil {
+			if errors.Is(err, storage.ErrCertificationTypeNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Certification type not found"))
+				return
+			}
+			if errors.Is(err, storage.ErrForeignKeyViolation) {
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Certification type is required by positions"))
+				return
+			}
+			log.Error("failed to delete certification type", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to delete certification type"))
+			return
+		}
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -1,36 +1,34 @@
-package letters
+package training
 
 import (
 	"context"
 	"log/slog"
 	"net/http"
-
 	resp "srmt-admin/internal/lib/api/response"
 	"srmt-admin/internal/lib/logger/sl"
-	letter_type "srmt-admin/internal/lib/model/letter-type"
+	"srmt-admin/internal/lib/model/hrm/training"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 )
 
-type letterTypeGetter interface {
-	GetAllLetterTypes(ctx context.Context) ([]letter_type.Model, error)
+type CertificationTypesGetter interface {
+	GetAllCertificationTypes(ctx context.Context) ([]*training.CertificationType, error)
 }
 
-func GetTypes(log *slog.Logger, getter letterTypeGetter) http.HandlerFunc {
+func GetCertificationTypes(log *slog.Logger, svc CertificationTypesGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.letters.get-types"
+		const op = "handlers.hrm.training.GetCertificationTypes"
 		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
 
-		types, err := getter.GetAllLetterTypes(r.Context())
+		types, err := svc.GetAllCertificationTypes(r.Context())
 		if err != nil {
-			log.Error("failed to get letter types", sl.Err(err))
+			log.Error("failed to get certification types", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, resp.InternalServerError("Failed to retrieve letter types"))
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve certification types"))
 			return
 		}
 
-		log.Info("successfully retrieved letter types", slog.Int("count", len(types)))
 		render.JSON(w, r, types)
 	}
 }
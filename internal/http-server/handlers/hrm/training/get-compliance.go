@@ -0,0 +1,51 @@
+package training
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/hrm/training"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type ComplianceGetter interface {
+	GetCompliance(ctx context.Context, filters dto.ComplianceFilters) (*training.ComplianceMatrix, error)
+}
+
+func GetCompliance(log *slog.Logger, svc ComplianceGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.training.GetCompliance"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		matrix, err := svc.GetCompliance(r.Context(), complianceFilters(r))
+		if err != nil {
+			log.Error("failed to get compliance matrix", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve compliance matrix"))
+			return
+		}
+
+		render.JSON(w, r, matrix)
+	}
+}
+
+func complianceFilters(r *http.Request) dto.ComplianceFilters {
+	q := r.URL.Query()
+	var filters dto.ComplianceFilters
+
+	if v := q.Get("organization_id"); v != "" {
+		val, _ := strconv.ParseInt(v, 10, 64)
+		filters.OrganizationID = &val
+	}
+	if v := q.Get("department_id"); v != "" {
+		val, _ := strconv.ParseInt(v, 10, 64)
+		filters.DepartmentID = &val
+	}
+	return filters
+}
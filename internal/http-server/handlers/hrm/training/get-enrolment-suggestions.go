@@ -0,0 +1,35 @@
+package training
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/hrm/training"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type EnrolmentSuggestionsGetter interface {
+	GetEnrolmentSuggestions(ctx context.Context, filters dto.ComplianceFilters) ([]*training.EnrolmentSuggestion, error)
+}
+
+func GetEnrolmentSuggestions(log *slog.Logger, svc EnrolmentSuggestionsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.training.GetEnrolmentSuggestions"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		suggestions, err := svc.GetEnrolmentSuggestions(r.Context(), complianceFilters(r))
+		if err != nil {
+			log.Error("failed to get enrolment suggestions", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve enrolment suggestions"))
+			return
+		}
+
+		render.JSON(w, r, suggestions)
+	}
+}
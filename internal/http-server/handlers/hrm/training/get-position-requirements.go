@@ -0,0 +1,41 @@
+package training
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/hrm/training"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type PositionRequirementsGetter interface {
+	GetPositionRequirements(ctx context.Context, positionID *int64) ([]*training.PositionRequirement, error)
+}
+
+func GetPositionRequirements(log *slog.Logger, svc PositionRequirementsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.training.GetPositionRequirements"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		var positionID *int64
+		if v := r.URL.Query().Get("position_id"); v != "" {
+			val, _ := strconv.ParseInt(v, 10, 64)
+			positionID = &val
+		}
+
+		reqs, err := svc.GetPositionRequirements(r.Context(), positionID)
+		if err != nil {
+			log.Error("failed to get position requirements", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve position requirements"))
+			return
+		}
+
+		render.JSON(w, r, reqs)
+	}
+}
@@ -0,0 +1,70 @@
+package training
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/storage"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type CertificationTypeUpdater interface {
+	UpdateCertificationType(ctx context.Context, id int64, req dto.UpdateCertificationTypeRequest) error
+}
+
+func UpdateCertificationType(log *slog.Logger, svc CertificationTypeUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.training.UpdateCertificationType"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid ID"))
+			return
+		}
+
+		var req dto.UpdateCertificationTypeRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request body"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		if err := svc.UpdateCertificationType(r.Context(), id, req); err != nil {
+			if errors.Is(err, storage.ErrCertificationTypeNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Certification type not found"))
+				return
+			}
+			if errors.Is(err, storage.ErrDuplicate) {
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Certification type with this name already exists"))
+				return
+			}
+			log.Error("failed to update certification type", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to update certification type"))
+			return
+		}
+
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -45,6 +45,11 @@ func UpdateTraining(log *slog.Logger, svc TrainingUpdater) http.HandlerFunc {
 				render.JSON(w, r, resp.NotFound("Training not found"))
 				return
 			}
+			if errors.Is(err, storage.ErrForeignKeyViolation) {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid reference ID"))
+				return
+			}
 			log.Error("failed to update training", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
 			render.JSON(w, r, resp.InternalServerError("Failed to update training"))
@@ -0,0 +1,63 @@
+package vacation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	vacationmodel "srmt-admin/internal/lib/model/hrm/vacation"
+	"srmt-admin/internal/storage"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type YearCloser interface {
+	CloseYear(ctx context.Context, year int, closedBy *int64) (*vacationmodel.ClosingResult, error)
+}
+
+// CloseYear carries the unused days of a past year over to the next one
+func CloseYear(log *slog.Logger, svc YearCloser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.vacation.CloseYear"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		claims, ok := mwauth.ClaimsFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("unauthorized"))
+			return
+		}
+
+		year, err := strconv.Atoi(chi.URLParam(r, "year"))
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid year"))
+			return
+		}
+
+		res, err := svc.CloseYear(r.Context(), year, &claims.ContactID)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrAccrualPeriodNotOver):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("The year is not over yet"))
+			case errors.Is(err, storage.ErrVacationYearClosed):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("The vacation year is already closed"))
+			default:
+				log.Error("failed to close vacation year", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to close vacation year"))
+			}
+			return
+		}
+
+		render.JSON(w, r, res)
+	}
+}
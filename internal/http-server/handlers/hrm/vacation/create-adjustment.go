@@ -0,0 +1,79 @@
+package vacation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/storage"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type AdjustmentCreator interface {
+	AddAdjustment(ctx context.Context, employeeID int64, req dto.CreateLedgerAdjustmentRequest, createdBy int64) (int64, error)
+}
+
+func CreateAdjustment(log *slog.Logger, svc AdjustmentCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.vacation.CreateAdjustment"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		claims, ok := mwauth.ClaimsFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("unauthorized"))
+			return
+		}
+
+		employeeID, err := strconv.ParseInt(chi.URLParam(r, "employeeId"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid employee ID"))
+			return
+		}
+
+		var req dto.CreateLedgerAdjustmentRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request body"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		id, err := svc.AddAdjustment(r.Context(), employeeID, req, claims.ContactID)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrVacationYearClosed):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("The vacation year is closed"))
+			case errors.Is(err, storage.ErrForeignKeyViolation):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Employee not found"))
+			default:
+				log.Error("failed to add vacation adjustment", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to add adjustment"))
+			}
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, map[string]int64{"id": id})
+	}
+}
@@ -0,0 +1,62 @@
+package vacation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type EntitlementRuleCreator interface {
+	CreateEntitlementRule(ctx context.Context, req dto.CreateEntitlementRuleRequest) (int64, error)
+}
+
+func CreateEntitlementRule(log *slog.Logger, svc EntitlementRuleCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.vacation.CreateEntitlementRule"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		var req dto.CreateEntitlementRuleRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request body"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		id, err := svc.CreateEntitlementRule(r.Context(), req)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrEntitlementRuleExists):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("A rule for this position and seniority already exists"))
+			case errors.Is(err, storage.ErrForeignKeyViolation):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Position not found"))
+			default:
+				log.Error("failed to create entitlement rule", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to create entitlement rule"))
+			}
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, map[string]int64{"id": id})
+	}
+}
@@ -0,0 +1,48 @@
+package vacation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/storage"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type EntitlementRuleDeleter interface {
+	DeleteEntitlementRule(ctx context.Context, id int64) error
+}
+
+func DeleteEntitlementRule(log *slog.Logger, svc EntitlementRuleDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.vacation.DeleteEntitlementRule"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid ID"))
+			return
+		}
+
+		if err := svc.DeleteEntitlementRule(r.Context(), id); err != nil {
+			if errors.Is(err, storage.ErrEntitlementRuleNotFound) {
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Entitlement rule not found"))
+				return
+			}
+			log.Error("failed to delete entitlement rule", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to delete entitlement rule"))
+			return
+		}
+
+		render.JSON(w, r, resp.Delete())
+	}
+}
@@ -0,0 +1,34 @@
+package vacation
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	vacationmodel "srmt-admin/internal/lib/model/hrm/vacation"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type EntitlementRulesGetter interface {
+	GetEntitlementRules(ctx context.Context) ([]*vacationmodel.EntitlementRule, error)
+}
+
+func GetEntitlementRules(log *slog.Logger, svc EntitlementRulesGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.vacation.GetEntitlementRules"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		rules, err := svc.GetEntitlementRules(r.Context())
+		if err != nil {
+			log.Error("failed to get entitlement rules", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve entitlement rules"))
+			return
+		}
+
+		render.JSON(w, r, rules)
+	}
+}
@@ -0,0 +1,53 @@
+package vacation
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	vacationmodel "srmt-admin/internal/lib/model/hrm/vacation"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type LedgerGetter interface {
+	GetLedger(ctx context.Context, employeeID int64, year int) (*vacationmodel.Ledger, error)
+}
+
+// GetLedger lists the accruals, carry-overs and vacations behind an
+// employee's balance for ?year (the current one by default)
+func GetLedger(log *slog.Logger, svc LedgerGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.vacation.GetLedger"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		employeeID, err := strconv.ParseInt(chi.URLParam(r, "employeeId"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid employee ID"))
+			return
+		}
+
+		year := time.Now().Year()
+		if v := r.URL.Query().Get("year"); v != "" {
+			if y, err := strconv.Atoi(v); err == nil {
+				year = y
+			}
+		}
+
+		ledger, err := svc.GetLedger(r.Context(), employeeID, year)
+		if err != nil {
+			log.Error("failed to get vacation ledger", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to get vacation ledger"))
+			return
+		}
+
+		render.JSON(w, r, ledger)
+	}
+}
@@ -0,0 +1,34 @@
+package vacation
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	vacationmodel "srmt-admin/internal/lib/model/hrm/vacation"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type PolicyGetter interface {
+	GetPolicy(ctx context.Context) (*vacationmodel.Policy, error)
+}
+
+func GetPolicy(log *slog.Logger, svc PolicyGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.vacation.GetPolicy"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		policy, err := svc.GetPolicy(r.Context())
+		if err != nil {
+			log.Error("failed to get leave policy", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to get leave policy"))
+			return
+		}
+
+		render.JSON(w, r, policy)
+	}
+}
@@ -0,0 +1,64 @@
+package vacation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/logger/sl"
+	vacationmodel "srmt-admin/internal/lib/model/hrm/vacation"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type MonthAccruer interface {
+	AccrueMonth(ctx context.Context, year, month int) (*vacationmodel.AccrualResult, error)
+}
+
+// RunAccrual accrues a past month again, e.g. after a late change of a
+// personnel record
+func RunAccrual(log *slog.Logger, svc MonthAccruer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.hrm.vacation.RunAccrual"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		var req dto.RunAccrualRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request body"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		res, err := svc.AccrueMonth(r.Context(), req.Year, req.Month)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrAccrualPeriodNotOver):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("The month is not over yet"))
+			case errors.Is(err, storage.ErrVacationYearClosed):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("The vacation year is closed"))
+			default:
+				log.Error("failed to accrue vacation days", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to accrue vacation days"))
+			}
+			return
+		}
+
+		render.JSON(w, r, res)
+	}
+}
@@ -1,60 +0,0 @@
-package instructions
-
-import (
-	"context"
-	"errors"
-	"log/slog"
-	"net/http"
-	"strconv"
-
-	resp "srmt-admin/internal/lib/api/response"
-	"srmt-admin/internal/lib/logger/sl"
-	"srmt-admin/internal/storage"
-
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/render"
-)
-
-type instructionDeleter interface {
-	DeleteInstruction(ctx context.Context, id int64) error
-}
-
-func Delete(log *slog.Logger, deleter instructionDeleter) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.instructions.delete"
-		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
-
-		idStr := chi.URLParam(r, "id")
-		id, err := strconv.ParseInt(idStr, 10, 64)
-		if err != nil {
-			log.Warn("invalid 'id' parameter", sl.Err(err))
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, resp.BadRequest("Invalid 'id' parameter"))
-			return
-		}
-
-		err = deleter.DeleteInstruction(r.Context(), id)
-		if err != nil {
-			if errors.Is(err, storage.ErrNotFound) {
-				log.Warn("instruction not found", slog.Int64("id", id))
-				render.Status(r, http.StatusNotFound)
-				render.JSON(w, r, resp.NotFound("Instruction not found"))
-				return
-			}
-			if errors.Is(err, storage.ErrForeignKeyViolation) {
-				log.Warn("cannot delete instruction - referenced by other records", slog.Int64("id", id))
-				render.Status(r, http.StatusBadRequest)
-				render.JSON(w, r, resp.BadRequest("Cannot delete: instruction is referenced by other records"))
-				return
-			}
-			log.Error("failed to delete instruction", sl.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, resp.InternalServerError("Failed to delete instruction"))
-			return
-		}
-
-		log.Info("instruction deleted successfully", slog.Int64("id", id))
-		render.Status(r, http.StatusNoContent)
-	}
-}
@@ -1,36 +0,0 @@
-package instructions
-
-import (
-	"context"
-	"log/slog"
-	"net/http"
-
-	resp "srmt-admin/internal/lib/api/response"
-	"srmt-admin/internal/lib/logger/sl"
-	instruction_type "srmt-admin/internal/lib/model/instruction-type"
-
-	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/render"
-)
-
-type instructionTypeGetter interface {
-	GetAllInstructionTypes(ctx context.Context) ([]instruction_type.Model, error)
-}
-
-func GetTypes(log *slog.Logger, getter instructionTypeGetter) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.instructions.get-types"
-		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
-
-		types, err := getter.GetAllInstructionTypes(r.Context())
-		if err != nil {
-			log.Error("failed to get instruction types", sl.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, resp.InternalServerError("Failed to retrieve instruction types"))
-			return
-		}
-
-		log.Info("successfully retrieved instruction types", slog.Int("count", len(types)))
-		render.JSON(w, r, types)
-	}
-}
@@ -0,0 +1,54 @@
+package leavebalance
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/hrm/vacation"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type LedgerGetter interface {
+	GetLedger(ctx context.Context, employeeID int64, year int) (*vacation.Ledger, error)
+}
+
+// GetLedger explains the caller's balance: every accrual, carry-over and
+// vacation of ?year with the running balance after each
+func GetLedger(log *slog.Logger, svc LedgerGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.my.leave-balance.GetLedger"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		claims, ok := mwauth.ClaimsFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("unauthorized"))
+			return
+		}
+
+		year := time.Now().Year()
+		if v := r.URL.Query().Get("year"); v != "" {
+			if parsed, err := strconv.Atoi(v); err == nil {
+				year = parsed
+			}
+		}
+
+		ledger, err := svc.GetLedger(r.Context(), claims.ContactID, year)
+		if err != nil {
+			log.Error("failed to get leave ledger", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to get leave ledger"))
+			return
+		}
+
+		render.JSON(w, r, ledger)
+	}
+}
@@ -0,0 +1,111 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	model "srmt-admin/internal/lib/model/notification"
+	"srmt-admin/internal/lib/service/notification"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type PreferencesGetter interface {
+	Preferences(ctx context.Context, contactID int64) (*model.PreferencesResponse, error)
+}
+
+type PreferencesSetter interface {
+	PreferencesGetter
+	SetPreferences(ctx context.Context, contactID int64, prefs []model.PreferenceInput) error
+}
+
+// GetPreferences returns the channels the caller receives every event type
+// on, and the channels that can be delivered to.
+func GetPreferences(log *slog.Logger, svc PreferencesGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.my.notifications.GetPreferences"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		claims, ok := mwauth.ClaimsFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("unauthorized"))
+			return
+		}
+
+		prefs, err := svc.Preferences(r.Context(), claims.ContactID)
+		if err != nil {
+			log.Error("failed to get notification preferences", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to get notification preferences"))
+			return
+		}
+
+		render.JSON(w, r, prefs)
+	}
+}
+
+// SetPreferences changes the channels of the listed event types. An empty
+// channel list turns the event off; a null one restores its defaults.
+func SetPreferences(log *slog.Logger, svc PreferencesSetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.my.notifications.SetPreferences"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		claims, ok := mwauth.ClaimsFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("unauthorized"))
+			return
+		}
+
+		var req model.SetPreferencesRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			log.Warn("validation failed", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		if err := svc.SetPreferences(r.Context(), claims.ContactID, req.Preferences); err != nil {
+			if errors.Is(err, notification.ErrUnknownEvent) {
+				log.Warn("unknown event type", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Unknown event type"))
+				return
+			}
+			log.Error("failed to set notification preferences", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to set notification preferences"))
+			return
+		}
+
+		prefs, err := svc.Preferences(r.Context(), claims.ContactID)
+		if err != nil {
+			log.Error("failed to get notification preferences", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to get notification preferences"))
+			return
+		}
+
+		log.Info("notification preferences updated",
+			slog.Int64("contact_id", claims.ContactID),
+			slog.Int("events", len(req.Preferences)))
+		render.JSON(w, r, prefs)
+	}
+}
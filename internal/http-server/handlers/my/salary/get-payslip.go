@@ -1,19 +1,101 @@
 package salary
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	salary "srmt-admin/internal/lib/model/hrm/salary"
+	salarysvc "srmt-admin/internal/lib/service/hrm/salary"
+	"srmt-admin/internal/storage"
+	"strconv"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 )
 
-type stubResponse struct {
-	Error string `json:"error"`
+type PayslipGetter interface {
+	GetByID(ctx context.Context, id int64) (*salary.Salary, error)
+	Payslip(ctx context.Context, sal *salary.Salary, format string) ([]byte, error)
 }
 
-func GetPayslip(log *slog.Logger) http.HandlerFunc {
+// GetPayslip serves the caller's payslip of an approved or paid salary as
+// PDF, or as XLSX with ?format=xlsx
+func GetPayslip(log *slog.Logger, svc PayslipGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		render.Status(r, http.StatusNotImplemented)
-		render.JSON(w, r, stubResponse{Error: "not implemented yet"})
+		const op = "handlers.my.salary.GetPayslip"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		claims, ok := mwauth.ClaimsFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("unauthorized"))
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid ID"))
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = salarysvc.FormatPDF
+		}
+		if format != salarysvc.FormatPDF && format != salarysvc.FormatXLSX {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid format, expected pdf or xlsx"))
+			return
+		}
+
+		sal, err := svc.GetByID(r.Context(), id)
+		if err != nil && !errors.Is(err, storage.ErrSalaryNotFound) {
+			log.Error("failed to get salary", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to get payslip"))
+			return
+		}
+		// Another employee's salary is as good as missing
+		if sal == nil || sal.EmployeeID != claims.ContactID {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.NotFound("Payslip not found"))
+			return
+		}
+		if sal.Status != "approved" && sal.Status != "paid" {
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, resp.Conflict("Payslip is available once the salary is approved"))
+			return
+		}
+
+		data, err := svc.Payslip(r.Context(), sal, format)
+		if err != nil {
+			log.Error("failed to render payslip", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to render payslip"))
+			return
+		}
+
+		writePayslip(w, sal, format, data)
+	}
+}
+
+// writePayslip writes a rendered payslip as an attachment
+func writePayslip(w http.ResponseWriter, sal *salary.Salary, format string, data []byte) {
+	contentType := "application/pdf"
+	if format == salarysvc.FormatXLSX {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
 	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="payslip_%d_%02d.%s"`, sal.PeriodYear, sal.PeriodMonth, format))
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
 }
@@ -0,0 +1,77 @@
+package salary
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	salary "srmt-admin/internal/lib/model/hrm/salary"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type mockPayslipGetter struct {
+	salaries map[int64]*salary.Salary
+	format   string
+}
+
+func (m *mockPayslipGetter) GetByID(_ context.Context, id int64) (*salary.Salary, error) {
+	if s, ok := m.salaries[id]; ok {
+		return s, nil
+	}
+	return nil, storage.ErrSalaryNotFound
+}
+
+func (m *mockPayslipGetter) Payslip(_ context.Context, _ *salary.Salary, format string) ([]byte, error) {
+	m.format = format
+	return []byte("payslip"), nil
+}
+
+func TestGetPayslip(t *testing.T) {
+	mock := &mockPayslipGetter{salaries: map[int64]*salary.Salary{
+		1: {ID: 1, EmployeeID: 42, PeriodYear: 2026, PeriodMonth: 3, Status: "paid"},
+		2: {ID: 2, EmployeeID: 7, PeriodYear: 2026, PeriodMonth: 3, Status: "paid"},
+		3: {ID: 3, EmployeeID: 42, PeriodYear: 2026, PeriodMonth: 4, Status: "calculated"},
+	}}
+
+	tests := []struct {
+		name       string
+		id         string
+		query      string
+		wantStatus int
+		wantFormat string
+	}{
+		{name: "own paid salary as pdf", id: "1", wantStatus: http.StatusOK, wantFormat: "pdf"},
+		{name: "own paid salary as xlsx", id: "1", query: "?format=xlsx", wantStatus: http.StatusOK, wantFormat: "xlsx"},
+		{name: "unknown format", id: "1", query: "?format=doc", wantStatus: http.StatusBadRequest},
+		{name: "another employee's salary", id: "2", wantStatus: http.StatusNotFound},
+		{name: "missing salary", id: "9", wantStatus: http.StatusNotFound},
+		{name: "salary not approved yet", id: "3", wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.format = ""
+
+			req := httptest.NewRequest(http.MethodGet, "/my-salary/payslip/"+tt.id+tt.query, nil)
+			rc := chi.NewRouteContext()
+			rc.URLParams.Add("id", tt.id)
+			ctx := context.WithValue(contextWithClaims(req.Context(), 42), chi.RouteCtxKey, rc)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+			GetPayslip(slog.New(slog.NewTextHandler(io.Discard, nil)), mock).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if mock.format != tt.wantFormat {
+				t.Errorf("rendered as %q, want %q", mock.format, tt.wantFormat)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package telegram
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	model "srmt-admin/internal/lib/model/telegram-bot"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type LinkGetter interface {
+	Link(ctx context.Context, contactID int64) (*model.Link, error)
+}
+
+type LinkCodeCreator interface {
+	CreateLinkCode(ctx context.Context, userID int64) (*model.LinkCode, error)
+}
+
+type Unlinker interface {
+	Unlink(ctx context.Context, contactID int64) error
+}
+
+// GetLink returns whether the caller has linked a Telegram chat.
+func GetLink(log *slog.Logger, svc LinkGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.my.telegram.GetLink"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		claims, ok := mwauth.ClaimsFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("unauthorized"))
+			return
+		}
+
+		link, err := svc.Link(r.Context(), claims.ContactID)
+		if err != nil {
+			log.Error("failed to get telegram link", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to get Telegram link"))
+			return
+		}
+
+		render.JSON(w, r, link)
+	}
+}
+
+// CreateLinkCode issues a one-time code the caller sends to the bot to link
+// the chat. A new code invalidates the previous one.
+func CreateLinkCode(log *slog.Logger, svc LinkCodeCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.my.telegram.CreateLinkCode"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		claims, ok := mwauth.ClaimsFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("unauthorized"))
+			return
+		}
+
+		code, err := svc.CreateLinkCode(r.Context(), claims.UserID)
+		if err != nil {
+			log.Error("failed to create link code", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to create link code"))
+			return
+		}
+
+		log.Info("telegram link code issued", slog.Int64("user_id", claims.UserID))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, code)
+	}
+}
+
+// Unlink removes the caller's Telegram chat.
+func Unlink(log *slog.Logger, svc Unlinker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.my.telegram.Unlink"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		claims, ok := mwauth.ClaimsFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("unauthorized"))
+			return
+		}
+
+		if err := svc.Unlink(r.Context(), claims.ContactID); err != nil {
+			log.Error("failed to unlink telegram", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to unlink Telegram"))
+			return
+		}
+
+		log.Info("telegram unlinked", slog.Int64("contact_id", claims.ContactID))
+		render.Status(r, http.StatusNoContent)
+		render.JSON(w, r, resp.Delete())
+	}
+}
@@ -0,0 +1,109 @@
+package notificationdeliveries
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	model "srmt-admin/internal/lib/model/notification"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type DeliveryLister interface {
+	Deliveries(ctx context.Context, f model.DeliveryFilter) ([]model.Delivery, error)
+}
+
+type CatalogueGetter interface {
+	Catalogue() []model.EventKind
+}
+
+const (
+	defaultLimit = 100
+	maxLimit     = 500
+)
+
+var (
+	channels = []string{model.ChannelInApp, model.ChannelEmail, model.ChannelTelegram}
+	statuses = []string{model.StatusPending, model.StatusSent, model.StatusFailed, model.StatusSkipped}
+)
+
+// List returns the delivery log, newest first, filtered by contact_id,
+// event_type, channel and status.
+func List(log *slog.Logger, svc DeliveryLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.notification-deliveries.List"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		q := r.URL.Query()
+		f := model.DeliveryFilter{Limit: defaultLimit}
+
+		if v := q.Get("contact_id"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'contact_id' parameter"))
+				return
+			}
+			f.ContactID = &id
+		}
+		if v := q.Get("event_type"); v != "" {
+			f.EventType = &v
+		}
+		if v := q.Get("channel"); v != "" {
+			if !slices.Contains(channels, v) {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'channel' parameter"))
+				return
+			}
+			f.Channel = &v
+		}
+		if v := q.Get("status"); v != "" {
+			if !slices.Contains(statuses, v) {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'status' parameter"))
+				return
+			}
+			f.Status = &v
+		}
+		if v := q.Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > maxLimit {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'limit' parameter"))
+				return
+			}
+			f.Limit = n
+		}
+		if v := q.Get("offset"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'offset' parameter"))
+				return
+			}
+			f.Offset = n
+		}
+
+		deliveries, err := svc.Deliveries(r.Context(), f)
+		if err != nil {
+			log.Error("failed to get notification deliveries", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve notification deliveries"))
+			return
+		}
+		render.JSON(w, r, deliveries)
+	}
+}
+
+// Events returns the catalogue of notification event types.
+func Events(svc CatalogueGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, svc.Catalogue())
+	}
+}
@@ -0,0 +1,55 @@
+package notificationdeliveries
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	model "srmt-admin/internal/lib/model/notification"
+)
+
+type mockLister struct {
+	filter model.DeliveryFilter
+}
+
+func (m *mockLister) Deliveries(_ context.Context, f model.DeliveryFilter) ([]model.Delivery, error) {
+	m.filter = f
+	return nil, nil
+}
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+	}{
+		{name: "no filters", query: "", wantCode: http.StatusOK},
+		{name: "filters", query: "?contact_id=5&channel=email&status=failed&limit=10&offset=20", wantCode: http.StatusOK},
+		{name: "bad channel", query: "?channel=sms", wantCode: http.StatusBadRequest},
+		{name: "bad status", query: "?status=lost", wantCode: http.StatusBadRequest},
+		{name: "bad limit", query: "?limit=1000", wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockLister{}
+			h := List(slog.New(slog.NewTextHandler(io.Discard, nil)), svc)
+
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/notifications/deliveries"+tt.query, nil))
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d, body: %s", rr.Code, tt.wantCode, rr.Body.String())
+			}
+			if tt.name == "filters" {
+				f := svc.filter
+				if f.ContactID == nil || *f.ContactID != 5 || *f.Channel != "email" || *f.Status != "failed" || f.Limit != 10 || f.Offset != 20 {
+					t.Errorf("filter = %+v", f)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,454 @@
+package registrationnumbering
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	registration_number "srmt-admin/internal/lib/model/registration-number"
+	"srmt-admin/internal/lib/service/auth"
+	regnumbering "srmt-admin/internal/lib/service/registration-numbering"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/xuri/excelize/v2"
+)
+
+type SchemeLister interface {
+	Schemes(ctx context.Context, kind *string) ([]registration_number.Scheme, error)
+}
+
+type SchemeCreator interface {
+	CreateScheme(ctx context.Context, req registration_number.AddSchemeRequest, userID int64) (int64, error)
+}
+
+type SchemeEditor interface {
+	EditScheme(ctx context.Context, id int64, req registration_number.EditSchemeRequest) error
+}
+
+type NumberPreviewer interface {
+	Preview(ctx context.Context, id int64, date *time.Time) (string, error)
+}
+
+type NumberReserver interface {
+	Reserve(ctx context.Context, req registration_number.ReserveRequest, userID int64) (int64, string, error)
+}
+
+type NumberCanceller interface {
+	Cancel(ctx context.Context, id int64, reason *string, userID int64) error
+}
+
+type JournalGetter interface {
+	Journal(ctx context.Context, f registration_number.JournalFilter) ([]registration_number.Entry, error)
+}
+
+type JournalExporter interface {
+	ExportJournal(ctx context.Context, f registration_number.JournalFilter) (*excelize.File, error)
+}
+
+type createdResponse struct {
+	resp.Response
+	ID int64 `json:"id"`
+}
+
+type numberResponse struct {
+	resp.Response
+	ID     int64  `json:"id,omitempty"`
+	Number string `json:"number"`
+}
+
+const dateLayout = "2006-01-02"
+
+// schemeErrorMessage returns the pattern rule the scheme breaks.
+func schemeErrorMessage(err error) string {
+	for _, e := range []error{
+		registration_number.ErrPatternNoSeq,
+		registration_number.ErrPatternNoYear,
+		registration_number.ErrPatternOrgCode,
+		registration_number.ErrPatternNoOrgCode,
+		registration_number.ErrPatternUnknownPart,
+		registration_number.ErrInvalidResetPeriod,
+	} {
+		if errors.Is(err, e) {
+			return "Invalid numbering scheme: " + e.Error()
+		}
+	}
+	return "Invalid numbering scheme"
+}
+
+func writeValidationError(w http.ResponseWriter, r *http.Request, err error) {
+	var vErrs validator.ValidationErrors
+	errors.As(err, &vErrs)
+	render.Status(r, http.StatusBadRequest)
+	render.JSON(w, r, resp.ValidationErrors(vErrs))
+}
+
+func parseJournalFilter(r *http.Request) (registration_number.JournalFilter, string) {
+	q := r.URL.Query()
+	var f registration_number.JournalFilter
+	if v := q.Get("document_kind"); v != "" {
+		f.DocumentKind = &v
+	}
+	if v := q.Get("status"); v != "" {
+		f.Status = &v
+	}
+	if v := q.Get("organization_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return f, "Invalid 'organization_id' parameter"
+		}
+		f.OrganizationID = &id
+	}
+	if v := q.Get("year"); v != "" {
+		year, err := strconv.Atoi(v)
+		if err != nil || year < 1900 || year > 9999 {
+			return f, "Invalid 'year' parameter"
+		}
+		f.Year = &year
+	}
+	return f, ""
+}
+
+// ListSchemes returns the numbering schemes, optionally ?document_kind.
+func ListSchemes(log *slog.Logger, svc SchemeLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.registration-numbering.ListSchemes"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		var kind *string
+		if v := r.URL.Query().Get("document_kind"); v != "" {
+			kind = &v
+		}
+
+		schemes, err := svc.Schemes(r.Context(), kind)
+		if err != nil {
+			log.Error("failed to retrieve numbering schemes", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve numbering schemes"))
+			return
+		}
+		render.JSON(w, r, schemes)
+	}
+}
+
+// CreateScheme adds a numbering scheme for a kind, optionally per organization.
+func CreateScheme(log *slog.Logger, svc SchemeCreator) http.HandlerFunc {
+	validate := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.registration-numbering.CreateScheme"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		userID, err := auth.GetUserID(r.Context())
+		if err != nil {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		var req registration_number.AddSchemeRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+		if err := validate.Struct(req); err != nil {
+			writeValidationError(w, r, err)
+			return
+		}
+
+		id, err := svc.CreateScheme(r.Context(), req, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, regnumbering.ErrUnknownKind):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Unknown document kind"))
+			case errors.Is(err, regnumbering.ErrInvalidScheme):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest(schemeErrorMessage(err)))
+			case errors.Is(err, storage.ErrDuplicate):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("A scheme for this kind and organization, or with this org code, already exists"))
+			case errors.Is(err, storage.ErrForeignKeyViolation):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'organization_id'"))
+			default:
+				log.Error("failed to create numbering scheme", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to create numbering scheme"))
+			}
+			return
+		}
+
+		log.Info("numbering scheme created", slog.Int64("id", id))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, createdResponse{Response: resp.Created(), ID: id})
+	}
+}
+
+// EditScheme updates a numbering scheme. Issued numbers are not renumbered.
+func EditScheme(log *slog.Logger, svc SchemeEditor) http.HandlerFunc {
+	validate := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.registration-numbering.EditScheme"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'id' parameter"))
+			return
+		}
+
+		var req registration_number.EditSchemeRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+		if err := validate.Struct(req); err != nil {
+			writeValidationError(w, r, err)
+			return
+		}
+
+		if err := svc.EditScheme(r.Context(), id, req); err != nil {
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Numbering scheme not found"))
+			case errors.Is(err, regnumbering.ErrInvalidScheme):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest(schemeErrorMessage(err)))
+			case errors.Is(err, regnumbering.ErrSchemeInUse):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Numbering of a scheme that has issued numbers cannot be changed"))
+			case errors.Is(err, storage.ErrDuplicate):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("A scheme for this kind and organization, or with this org code, already exists"))
+			default:
+				log.Error("failed to update numbering scheme", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to update numbering scheme"))
+			}
+			return
+		}
+
+		log.Info("numbering scheme updated", slog.Int64("id", id))
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// PreviewNext returns the number the scheme would issue next on ?date
+// (default today) without allocating it.
+func PreviewNext(log *slog.Logger, svc NumberPreviewer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.registration-numbering.PreviewNext"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'id' parameter"))
+			return
+		}
+
+		var date *time.Time
+		if v := r.URL.Query().Get("date"); v != "" {
+			d, err := time.Parse(dateLayout, v)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'date' parameter, expected YYYY-MM-DD"))
+				return
+			}
+			date = &d
+		}
+
+		number, err := svc.Preview(r.Context(), id, date)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Numbering scheme not found"))
+			default:
+				log.Error("failed to preview registration number", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to preview registration number"))
+			}
+			return
+		}
+		render.JSON(w, r, numberResponse{Response: resp.OK(), Number: number})
+	}
+}
+
+// Reserve holds the next number for a document to be registered later; the
+// returned id is passed as reservation_id when the document is created.
+func Reserve(log *slog.Logger, svc NumberReserver) http.HandlerFunc {
+	validate := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.registration-numbering.Reserve"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		userID, err := auth.GetUserID(r.Context())
+		if err != nil {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		var req registration_number.ReserveRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+		if err := validate.Struct(req); err != nil {
+			writeValidationError(w, r, err)
+			return
+		}
+
+		id, number, err := svc.Reserve(r.Context(), req, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, regnumbering.ErrNoScheme):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("No active numbering scheme for the document kind"))
+			case errors.Is(err, regnumbering.ErrReservationNotAllowed):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Numbering scheme does not allow reservations"))
+			default:
+				log.Error("failed to reserve registration number", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to reserve registration number"))
+			}
+			return
+		}
+
+		log.Info("registration number reserved", slog.Int64("id", id), slog.String("number", number))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, numberResponse{Response: resp.Created(), ID: id, Number: number})
+	}
+}
+
+// Cancel withdraws a reserved number; it stays in the journal and is never
+// reissued.
+func Cancel(log *slog.Logger, svc NumberCanceller) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.registration-numbering.Cancel"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		userID, err := auth.GetUserID(r.Context())
+		if err != nil {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'id' parameter"))
+			return
+		}
+
+		var req registration_number.CancelRequest
+		if r.ContentLength != 0 {
+			if err := render.DecodeJSON(r.Body, &req); err != nil {
+				log.Error("failed to decode request", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid request format"))
+				return
+			}
+		}
+
+		if err := svc.Cancel(r.Context(), id, req.Reason, userID); err != nil {
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Registration number not found"))
+			case errors.Is(err, storage.ErrInvalidStatus):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Only reserved numbers can be cancelled"))
+			default:
+				log.Error("failed to cancel registration number", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to cancel registration number"))
+			}
+			return
+		}
+
+		log.Info("registration number cancelled", slog.Int64("id", id))
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// Journal lists the registration journal. Filters: document_kind,
+// organization_id, year and status.
+func Journal(log *slog.Logger, svc JournalGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.registration-numbering.Journal"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		f, msg := parseJournalFilter(r)
+		if msg != "" {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest(msg))
+			return
+		}
+
+		entries, err := svc.Journal(r.Context(), f)
+		if err != nil {
+			log.Error("failed to retrieve registration journal", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve registration journal"))
+			return
+		}
+		render.JSON(w, r, entries)
+	}
+}
+
+// ExportJournal returns the journal selected by the Journal filters as xlsx.
+func ExportJournal(log *slog.Logger, svc JournalExporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.registration-numbering.ExportJournal"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		f, msg := parseJournalFilter(r)
+		if msg != "" {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest(msg))
+			return
+		}
+
+		file, err := svc.ExportJournal(r.Context(), f)
+		if err != nil {
+			log.Error("failed to export registration journal", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to export registration journal"))
+			return
+		}
+		defer file.Close()
+
+		filename := "registration_journal.xlsx"
+		if f.Year != nil {
+			filename = fmt.Sprintf("registration_journal_%d.xlsx", *f.Year)
+		}
+
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+		if err := file.Write(w); err != nil {
+			log.Error("failed to write excel to response", sl.Err(err))
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package registrationnumbering
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	registration_number "srmt-admin/internal/lib/model/registration-number"
+	regnumbering "srmt-admin/internal/lib/service/registration-numbering"
+	"srmt-admin/internal/token"
+)
+
+type mockReserver struct {
+	err error
+}
+
+func (m *mockReserver) Reserve(_ context.Context, _ registration_number.ReserveRequest, _ int64) (int64, string, error) {
+	if m.err != nil {
+		return 0, "", m.err
+	}
+	return 3, "12-26", nil
+}
+
+func TestReserve(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		wantCode int
+	}{
+		{name: "reserved", body: `{"document_kind":"decree"}`, wantCode: http.StatusCreated},
+		{name: "missing kind", body: `{}`, wantCode: http.StatusBadRequest},
+		{name: "no scheme", body: `{"document_kind":"memo"}`,
+			err: fmt.Errorf("op: %w", regnumbering.ErrNoScheme), wantCode: http.StatusBadRequest},
+		{name: "reservations disabled", body: `{"document_kind":"decree"}`,
+			err: fmt.Errorf("op: %w", regnumbering.ErrReservationNotAllowed), wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/registration-numbers/reserve", bytes.NewReader([]byte(tt.body)))
+			req = req.WithContext(mwauth.ContextWithClaims(req.Context(), &token.Claims{UserID: 1}))
+			rr := httptest.NewRecorder()
+
+			Reserve(slog.New(slog.NewTextHandler(io.Discard, nil)), &mockReserver{err: tt.err}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d, body: %s", rr.Code, tt.wantCode, rr.Body.String())
+			}
+			if tt.wantCode == http.StatusCreated && !bytes.Contains(rr.Body.Bytes(), []byte(`"number":"12-26"`)) {
+				t.Fatalf("number missing from response: %s", rr.Body.String())
+			}
+		})
+	}
+}
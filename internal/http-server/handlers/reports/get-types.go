@@ -1,36 +0,0 @@
-package reports
-
-import (
-	"context"
-	"log/slog"
-	"net/http"
-
-	resp "srmt-admin/internal/lib/api/response"
-	"srmt-admin/internal/lib/logger/sl"
-	report_type "srmt-admin/internal/lib/model/report-type"
-
-	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/render"
-)
-
-type reportTypeGetter interface {
-	GetAllReportTypes(ctx context.Context) ([]report_type.Model, error)
-}
-
-func GetTypes(log *slog.Logger, getter reportTypeGetter) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.reports.get-types"
-		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
-
-		types, err := getter.GetAllReportTypes(r.Context())
-		if err != nil {
-			log.Error("failed to get report types", sl.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, resp.InternalServerError("Failed to retrieve report types"))
-			return
-		}
-
-		log.Info("successfully retrieved report types", slog.Int("count", len(types)))
-		render.JSON(w, r, types)
-	}
-}
@@ -11,6 +11,7 @@ import (
 	"srmt-admin/internal/lib/logger/sl"
 	model "srmt-admin/internal/lib/model/reservoir-flood"
 	"srmt-admin/internal/lib/service/auth"
+	"srmt-admin/internal/storage"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
@@ -84,6 +85,14 @@ func UpsertHourly(log *slog.Logger, repo HourlyUpserter) http.HandlerFunc {
 				})
 				return
 			}
+			if v := items[i].DutyEmployeeID.Value; v != nil && *v <= 0 {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, map[string]any{
+					"error":      "invalid duty_employee_id",
+					"item_index": i,
+				})
+				return
+			}
 		}
 
 		// Org-bound access check on EVERY item.
@@ -106,6 +115,12 @@ func UpsertHourly(log *slog.Logger, repo HourlyUpserter) http.HandlerFunc {
 		}
 
 		if err := repo.UpsertReservoirFloodHourly(r.Context(), items, userID); err != nil {
+			if errors.Is(err, storage.ErrForeignKeyViolation) {
+				log.Warn("duty employee or organization not found", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("duty employee or organization does not exist"))
+				return
+			}
 			log.Error("failed to upsert hourly", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
 			render.JSON(w, r, resp.InternalServerError("failed to save hourly data"))
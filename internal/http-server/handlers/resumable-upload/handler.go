@@ -0,0 +1,345 @@
+package resumableupload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/file"
+	"srmt-admin/internal/lib/service/auth"
+	filelifecycle "srmt-admin/internal/lib/service/file-lifecycle"
+	uploadsession "srmt-admin/internal/lib/service/upload-session"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+type SessionCreator interface {
+	Create(ctx context.Context, req file.CreateUploadRequest, userID int64) (*file.UploadSession, error)
+}
+
+type SessionGetter interface {
+	Session(ctx context.Context, id string, userID int64) (*file.UploadSession, error)
+}
+
+type ChunkWriter interface {
+	WriteChunk(ctx context.Context, id string, userID int64, offset int64, body io.Reader, size int64) (*file.UploadSession, error)
+}
+
+type SessionFinisher interface {
+	Finish(ctx context.Context, id string, userID int64) (*filelifecycle.Stored, error)
+}
+
+type SessionAborter interface {
+	Abort(ctx context.Context, id string, userID int64) error
+}
+
+// Headers of the protocol, after tus
+const (
+	headerOffset = "Upload-Offset"
+	headerLength = "Upload-Length"
+)
+
+// chunkTimeout replaces the server timeouts for a chunk request: a large
+// chunk over a slow link takes far longer than an API call.
+const chunkTimeout = 15 * time.Minute
+
+type sessionResponse struct {
+	resp.Response
+	*file.UploadSession
+	MinChunkSize int64 `json:"min_chunk_size"`
+	MaxChunkSize int64 `json:"max_chunk_size"`
+}
+
+type finishResponse struct {
+	resp.Response
+	ID           int64 `json:"id"`
+	Deduplicated bool  `json:"deduplicated,omitempty"`
+}
+
+func sessionBody(s *file.UploadSession, status resp.Response) sessionResponse {
+	return sessionResponse{
+		Response:      status,
+		UploadSession: s,
+		MinChunkSize:  uploadsession.MinChunkSize,
+		MaxChunkSize:  uploadsession.MaxChunkSize,
+	}
+}
+
+func setProgressHeaders(w http.ResponseWriter, s *file.UploadSession) {
+	w.Header().Set(headerOffset, strconv.FormatInt(s.Offset, 10))
+	w.Header().Set(headerLength, strconv.FormatInt(s.Size, 10))
+	w.Header().Set("Cache-Control", "no-store")
+}
+
+// request reads the user and the upload ID of a session request.
+func request(w http.ResponseWriter, r *http.Request) (int64, string, bool) {
+	userID, err := auth.GetUserID(r.Context())
+	if err != nil {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+		return 0, "", false
+	}
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, resp.NotFound("Upload not found"))
+		return 0, "", false
+	}
+	return userID, id, true
+}
+
+// Create starts a resumable upload of a file of known size. The response
+// carries the upload ID and the chunk size limits; Location is the upload URL.
+func Create(log *slog.Logger, svc SessionCreator) http.HandlerFunc {
+	validate := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.resumable-upload.Create"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		userID, err := auth.GetUserID(r.Context())
+		if err != nil {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		var req file.CreateUploadRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+		if err := validate.Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		sess, err := svc.Create(r.Context(), req, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, uploadsession.ErrTooLarge):
+				render.Status(r, http.StatusRequestEntityTooLarge)
+				render.JSON(w, r, resp.BadRequest("File exceeds the maximum upload size"))
+			case errors.Is(err, uploadsession.ErrUnknownCategory):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Category not found"))
+			default:
+				log.Error("failed to start upload", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to start upload"))
+			}
+			return
+		}
+
+		setProgressHeaders(w, sess)
+		w.Header().Set("Location", r.URL.Path+"/"+sess.ID)
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, sessionBody(sess, resp.Created()))
+	}
+}
+
+// Status returns the progress of an upload; the Upload-Offset header is
+// where the next chunk must start. Serves GET and HEAD.
+func Status(log *slog.Logger, svc SessionGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.resumable-upload.Status"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		userID, id, ok := request(w, r)
+		if !ok {
+			return
+		}
+		sess, err := svc.Session(r.Context(), id, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Upload not found"))
+			default:
+				log.Error("failed to retrieve upload", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to retrieve upload"))
+			}
+			return
+		}
+
+		setProgressHeaders(w, sess)
+		render.JSON(w, r, sessionBody(sess, resp.OK()))
+	}
+}
+
+// WriteChunk stores the request body as the next chunk of an upload. The
+// Upload-Offset header (or ?offset) must equal the upload offset and
+// Content-Length must be set. A chunk at the wrong offset gets 409 with the
+// offset to resume from.
+func WriteChunk(log *slog.Logger, svc ChunkWriter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.resumable-upload.WriteChunk"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		userID, id, ok := request(w, r)
+		if !ok {
+			return
+		}
+
+		offsetStr := r.Header.Get(headerOffset)
+		if offsetStr == "" {
+			offsetStr = r.URL.Query().Get("offset")
+		}
+		offset, err := strconv.ParseInt(offsetStr, 10, 64)
+		if err != nil || offset < 0 {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Header 'Upload-Offset' is required"))
+			return
+		}
+		if r.ContentLength < 0 {
+			render.Status(r, http.StatusLengthRequired)
+			render.JSON(w, r, resp.BadRequest("Header 'Content-Length' is required"))
+			return
+		}
+		if r.ContentLength > uploadsession.MaxChunkSize {
+			render.Status(r, http.StatusRequestEntityTooLarge)
+			render.JSON(w, r, resp.BadRequest(fmt.Sprintf("Chunk exceeds %d bytes", uploadsession.MaxChunkSize)))
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		deadline := time.Now().Add(chunkTimeout)
+		if err := rc.SetReadDeadline(deadline); err != nil {
+			log.Debug("cannot extend read deadline", sl.Err(err))
+		}
+		if err := rc.SetWriteDeadline(deadline); err != nil {
+			log.Debug("cannot extend write deadline", sl.Err(err))
+		}
+
+		body := http.MaxBytesReader(w, r.Body, r.ContentLength)
+		sess, err := svc.WriteChunk(r.Context(), id, userID, offset, body, r.ContentLength)
+		if err != nil {
+			var offsetErr *uploadsession.OffsetError
+			switch {
+			case errors.As(err, &offsetErr):
+				w.Header().Set(headerOffset, strconv.FormatInt(offsetErr.Offset, 10))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict(fmt.Sprintf("Chunk must start at offset %d", offsetErr.Offset)))
+			case errors.Is(err, storage.ErrNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Upload not found"))
+			case errors.Is(err, uploadsession.ErrChunkTooSmall):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest(fmt.Sprintf("Only the last chunk may be smaller than %d bytes", uploadsession.MinChunkSize)))
+			case errors.Is(err, uploadsession.ErrChunkPastEnd):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Chunk goes past the declared file size"))
+			case errors.Is(err, uploadsession.ErrTooManyParts):
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Too many chunks, send larger ones"))
+			case errors.Is(err, uploadsession.ErrUploadFinished):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Upload is already finished"))
+			default:
+				log.Error("failed to store chunk", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to store chunk"))
+			}
+			return
+		}
+
+		setProgressHeaders(w, sess)
+		render.JSON(w, r, sessionBody(sess, resp.OK()))
+	}
+}
+
+// Finish assembles a fully sent upload into a file. Repeating it returns the
+// same file.
+func Finish(log *slog.Logger, svc SessionFinisher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.resumable-upload.Finish"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		userID, id, ok := request(w, r)
+		if !ok {
+			return
+		}
+
+		// Assembling and scanning a large file outlasts the server timeout
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(chunkTimeout)); err != nil {
+			log.Debug("cannot extend write deadline", sl.Err(err))
+		}
+
+		stored, err := svc.Finish(r.Context(), id, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Upload not found"))
+			case errors.Is(err, uploadsession.ErrUploadIncomplete):
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Upload is not complete"))
+			case errors.Is(err, filelifecycle.ErrInfected):
+				var infected *filelifecycle.InfectedError
+				errors.As(err, &infected)
+				log.Warn("infected upload rejected", slog.String("signature", infected.Signature))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("File was rejected by the malware scan: "+infected.Signature))
+			case errors.Is(err, filelifecycle.ErrScanUnavailable):
+				log.Error("failed to scan upload", sl.Err(err))
+				render.Status(r, http.StatusBadGateway)
+				render.JSON(w, r, resp.BadGateway("File could not be scanned for malware, finish the upload again later"))
+			default:
+				log.Error("failed to finish upload", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to finish upload"))
+			}
+			return
+		}
+
+		log.Info("upload finished", slog.String("id", id), slog.Int64("file_id", stored.ID))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, finishResponse{Response: resp.Created(), ID: stored.ID, Deduplicated: stored.Deduplicated})
+	}
+}
+
+// Abort cancels an upload and discards the chunks sent.
+func Abort(log *slog.Logger, svc SessionAborter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.resumable-upload.Abort"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		userID, id, ok := request(w, r)
+		if !ok {
+			return
+		}
+		if err := svc.Abort(r.Context(), id, userID); err != nil {
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Upload not found"))
+			default:
+				log.Error("failed to abort upload", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to abort upload"))
+			}
+			return
+		}
+
+		log.Info("upload aborted", slog.String("id", id))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, resp.Delete())
+	}
+}
@@ -0,0 +1,109 @@
+package resumableupload
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	"srmt-admin/internal/lib/model/file"
+	uploadsession "srmt-admin/internal/lib/service/upload-session"
+	"srmt-admin/internal/token"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const sessionID = "1b4e28ba-2fa1-11d2-883f-0016d3cca427"
+
+type mockWriter struct {
+	offset int64
+}
+
+func (m *mockWriter) WriteChunk(_ context.Context, _ string, _ int64, offset int64, body io.Reader, size int64) (*file.UploadSession, error) {
+	if offset != m.offset {
+		return nil, fmt.Errorf("op: %w", &uploadsession.OffsetError{Offset: m.offset})
+	}
+	n, _ := io.Copy(io.Discard, body)
+	m.offset += n
+	return &file.UploadSession{ID: sessionID, Size: 100, Offset: m.offset}, nil
+}
+
+func TestWriteChunk(t *testing.T) {
+	tests := []struct {
+		name       string
+		offset     string
+		wantCode   int
+		wantOffset string
+	}{
+		{name: "accepted", offset: "40", wantCode: http.StatusOK, wantOffset: "50"},
+		{name: "wrong offset", offset: "0", wantCode: http.StatusConflict, wantOffset: "40"},
+		{name: "missing offset", wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			r.Put("/uploads/{id}", WriteChunk(slog.New(slog.NewTextHandler(io.Discard, nil)), &mockWriter{offset: 40}))
+
+			req := httptest.NewRequest(http.MethodPut, "/uploads/"+sessionID, bytes.NewReader(make([]byte, 10)))
+			if tt.offset != "" {
+				req.Header.Set("Upload-Offset", tt.offset)
+			}
+			req = req.WithContext(mwauth.ContextWithClaims(req.Context(), &token.Claims{UserID: 1}))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d, body: %s", rr.Code, tt.wantCode, rr.Body.String())
+			}
+			if got := rr.Header().Get("Upload-Offset"); got != tt.wantOffset {
+				t.Errorf("Upload-Offset = %q, want %q", got, tt.wantOffset)
+			}
+		})
+	}
+}
+
+type mockCreator struct{}
+
+func (mockCreator) Create(_ context.Context, req file.CreateUploadRequest, _ int64) (*file.UploadSession, error) {
+	return &file.UploadSession{ID: sessionID, FileName: req.FileName, Size: req.Size}, nil
+}
+
+func TestCreate(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "created", body: `{"file_name":"video.mp4","size":104857600}`, wantCode: http.StatusCreated},
+		{name: "no size", body: `{"file_name":"video.mp4"}`, wantCode: http.StatusBadRequest},
+		{name: "bad date", body: `{"file_name":"a","size":1,"date":"18.10.2026"}`, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/uploads", bytes.NewReader([]byte(tt.body)))
+			req = req.WithContext(mwauth.ContextWithClaims(req.Context(), &token.Claims{UserID: 1}))
+			rr := httptest.NewRecorder()
+
+			Create(slog.New(slog.NewTextHandler(io.Discard, nil)), mockCreator{}).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d, body: %s", rr.Code, tt.wantCode, rr.Body.String())
+			}
+			if tt.wantCode == http.StatusCreated {
+				if loc := rr.Header().Get("Location"); loc != "/uploads/"+sessionID {
+					t.Errorf("Location = %q", loc)
+				}
+				if !bytes.Contains(rr.Body.Bytes(), []byte(`"min_chunk_size":5242880`)) {
+					t.Errorf("chunk limits missing: %s", rr.Body.String())
+				}
+			}
+		})
+	}
+}
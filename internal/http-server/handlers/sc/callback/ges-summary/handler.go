@@ -2,24 +2,19 @@ package gessummary
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	resp "srmt-admin/internal/lib/api/response"
 	"srmt-admin/internal/lib/logger/sl"
 	gesproduction "srmt-admin/internal/lib/model/ges-production"
-	"time"
+	screport "srmt-admin/internal/lib/model/sc-report"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 )
 
-type Request struct {
-	Date                    string  `json:"date"`
-	TotalEnergyProduction   float64 `json:"total_energy_production"`
-	MonthlyEnergyProduction float64 `json:"monthly_energy_production"`
-	YearlyEnergyProduction  float64 `json:"yearly_energy_production"`
-}
-
 type Saver interface {
 	UpsertGesProduction(ctx context.Context, data gesproduction.Model) error
 }
@@ -32,19 +27,28 @@ func New(log *slog.Logger, saver Saver) http.HandlerFunc {
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		var req Request
-		if err := render.DecodeJSON(r.Body, &req); err != nil {
-			log.Error("failed to decode request body", sl.Err(err))
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			log.Error("failed to read request body", sl.Err(err))
 			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, resp.BadRequest("invalid request body"))
+			render.JSON(w, r, resp.BadRequest("could not read request body"))
 			return
 		}
 
-		// Validate date format
-		if _, err := time.Parse("2006-01-02", req.Date); err != nil {
-			log.Error("invalid date format", sl.Err(err), slog.String("date", req.Date))
+		var req screport.GesSummaryReport
+		if err := screport.Decode(body, &req); err != nil {
+			var fieldErr *screport.FieldError
+			if errors.As(err, &fieldErr) {
+				log.Warn("rejected malformed ges summary", slog.String("field", fieldErr.Field), slog.String("reason", fieldErr.Message))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequestStructured("invalid_payload", fieldErr.Error(), []resp.Detail{
+					{"field": fieldErr.Field, "message": fieldErr.Message},
+				}))
+				return
+			}
+			log.Error("failed to decode request body", sl.Err(err))
 			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, resp.BadRequest("invalid date format, expected YYYY-MM-DD"))
+			render.JSON(w, r, resp.BadRequest("invalid request body"))
 			return
 		}
 
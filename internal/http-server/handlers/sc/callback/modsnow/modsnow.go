@@ -2,6 +2,7 @@ package modsnow
 
 import (
 	"context"
+	"errors"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"io"
@@ -9,15 +10,17 @@ import (
 	"net/http"
 	resp "srmt-admin/internal/lib/api/response"
 	"srmt-admin/internal/lib/logger/sl"
+	screport "srmt-admin/internal/lib/model/sc-report"
 )
 
 type Saver interface {
 	SaveSnowData(ctx context.Context, jsonData string) error
+	UpsertModsnowReport(ctx context.Context, report screport.ModsnowReport) error
 }
 
 func New(log *slog.Logger, saver Saver) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.sc.callback.New"
+		const op = "handlers.sc.callback.modsnow.New"
 
 		log = log.With(
 			slog.String("op", op),
@@ -33,25 +36,41 @@ func New(log *slog.Logger, saver Saver) http.HandlerFunc {
 		}
 		defer r.Body.Close()
 
-		if len(rawJSON) == 0 {
-			log.Warn("received empty request body")
+		var report screport.ModsnowReport
+		if err := screport.Decode(rawJSON, &report); err != nil {
+			var fieldErr *screport.FieldError
+			if errors.As(err, &fieldErr) {
+				log.Warn("rejected malformed modsnow payload", slog.String("field", fieldErr.Field), slog.String("reason", fieldErr.Message))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequestStructured("invalid_payload", fieldErr.Error(), []resp.Detail{
+					{"field": fieldErr.Field, "message": fieldErr.Message},
+				}))
+				return
+			}
+			log.Error("failed to decode modsnow payload", sl.Err(err))
 			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, resp.BadRequest("empty request body is not allowed"))
+			render.JSON(w, r, resp.BadRequest("invalid request body"))
 			return
 		}
 
-		jsonData := string(rawJSON)
+		log.Info("received processed modsnow data, saving to storage", slog.String("date", report.Date))
 
-		log.Info("received processed stock data, saving to storage")
+		// The raw payload is still kept for /api/v3/modsnow consumers.
+		if err := saver.SaveSnowData(r.Context(), string(rawJSON)); err != nil {
+			log.Error("failed to save modsnow data", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("failed to save data"))
+			return
+		}
 
-		if err := saver.SaveSnowData(r.Context(), jsonData); err != nil {
-			log.Error("failed to save stock data", sl.Err(err))
+		if err := saver.UpsertModsnowReport(r.Context(), report); err != nil {
+			log.Error("failed to save modsnow report", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
 			render.JSON(w, r, resp.InternalServerError("failed to save data"))
 			return
 		}
 
-		log.Info("stock data saved successfully")
+		log.Info("modsnow data saved successfully", slog.String("date", report.Date))
 
 		render.Status(r, http.StatusOK)
 	}
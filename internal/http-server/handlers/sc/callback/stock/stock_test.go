@@ -0,0 +1,73 @@
+package stock
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	screport "srmt-admin/internal/lib/model/sc-report"
+)
+
+type mockSaver struct {
+	raw    string
+	report *screport.StockReport
+}
+
+func (m *mockSaver) SaveStockData(_ context.Context, jsonData string) error {
+	m.raw = jsonData
+	return nil
+}
+
+func (m *mockSaver) UpsertStockReport(_ context.Context, report screport.StockReport) error {
+	m.report = &report
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantSaved  bool
+		wantError  string
+	}{
+		{"valid", `{"date":"2026-03-01","rows":[{"name":"Чирчик","values":{"q":120.5}}]}`, http.StatusOK, true, ""},
+		{"empty", ``, http.StatusBadRequest, false, "body: empty request body is not allowed"},
+		{"wrong type", `{"date":"2026-03-01","rows":[{"name":"Чирчик","values":{"q":"n/a"}}]}`, http.StatusBadRequest, false, "rows.0.values.q"},
+		{"bad date", `{"date":"2026/03/01","rows":[{"name":"Чирчик","values":{"q":1}}]}`, http.StatusBadRequest, false, "date:"},
+		{"other shape", `[{"post":"Чирчик","q":120.5}]`, http.StatusBadRequest, false, "body: expected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saver := &mockSaver{}
+			req := httptest.NewRequest(http.MethodPost, "/sc/stock", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			New(log, saver).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("want status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if (saver.report != nil) != tt.wantSaved || (saver.raw != "") != tt.wantSaved {
+				t.Errorf("want saved=%v, got report=%v raw=%q", tt.wantSaved, saver.report, saver.raw)
+			}
+			if tt.wantError != "" {
+				var body struct {
+					Error string `json:"error"`
+				}
+				_ = json.Unmarshal(rr.Body.Bytes(), &body)
+				if !strings.Contains(body.Error, tt.wantError) {
+					t.Errorf("want error containing %q, got %q", tt.wantError, body.Error)
+				}
+			}
+		})
+	}
+}
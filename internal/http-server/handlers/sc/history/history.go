@@ -0,0 +1,144 @@
+// Package history serves the stored situation-center callback reports for a
+// date range, each compared with the report before it.
+package history
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	gesproduction "srmt-admin/internal/lib/model/ges-production"
+	screport "srmt-admin/internal/lib/model/sc-report"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// maxRangeDays bounds the ?from..?to span of a history query.
+const maxRangeDays = 366
+
+type StockGetter interface {
+	GetStockReports(ctx context.Context, from, to string) ([]screport.StockReport, error)
+	GetStockReportBefore(ctx context.Context, date string) (*screport.StockReport, error)
+}
+
+type ModsnowGetter interface {
+	GetModsnowReports(ctx context.Context, from, to string) ([]screport.ModsnowReport, error)
+	GetModsnowReportBefore(ctx context.Context, date string) (*screport.ModsnowReport, error)
+}
+
+type GesSummaryGetter interface {
+	GetGesProductionRange(ctx context.Context, from, to string) ([]gesproduction.Model, error)
+	GetGesProductionBefore(ctx context.Context, date string) (*gesproduction.Model, error)
+}
+
+// Stock: GET ?from=YYYY-MM-DD&to=YYYY-MM-DD
+func Stock(log *slog.Logger, getter StockGetter) http.HandlerFunc {
+	return serve(log, "handlers.sc.history.Stock", getter.GetStockReportBefore, getter.GetStockReports)
+}
+
+// Modsnow: GET ?from=YYYY-MM-DD&to=YYYY-MM-DD
+func Modsnow(log *slog.Logger, getter ModsnowGetter) http.HandlerFunc {
+	return serve(log, "handlers.sc.history.Modsnow", getter.GetModsnowReportBefore, getter.GetModsnowReports)
+}
+
+// GesSummary: GET ?from=YYYY-MM-DD&to=YYYY-MM-DD
+func GesSummary(log *slog.Logger, getter GesSummaryGetter) http.HandlerFunc {
+	before := func(ctx context.Context, date string) (*screport.GesSummaryReport, error) {
+		m, err := getter.GetGesProductionBefore(ctx, date)
+		if err != nil || m == nil {
+			return nil, err
+		}
+		report := gesReport(*m)
+		return &report, nil
+	}
+	inRange := func(ctx context.Context, from, to string) ([]screport.GesSummaryReport, error) {
+		models, err := getter.GetGesProductionRange(ctx, from, to)
+		if err != nil {
+			return nil, err
+		}
+		reports := make([]screport.GesSummaryReport, 0, len(models))
+		for _, m := range models {
+			reports = append(reports, gesReport(m))
+		}
+		return reports, nil
+	}
+	return serve(log, "handlers.sc.history.GesSummary", before, inRange)
+}
+
+func gesReport(m gesproduction.Model) screport.GesSummaryReport {
+	return screport.GesSummaryReport{
+		Date:                    m.Date,
+		TotalEnergyProduction:   m.TotalEnergyProduction,
+		MonthlyEnergyProduction: m.MonthlyEnergyProduction,
+		YearlyEnergyProduction:  m.YearlyEnergyProduction,
+	}
+}
+
+func serve[T screport.Report](
+	log *slog.Logger,
+	op string,
+	before func(ctx context.Context, date string) (*T, error),
+	inRange func(ctx context.Context, from, to string) ([]T, error),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		from, to, ok := parseRange(w, r)
+		if !ok {
+			return
+		}
+
+		prev, err := before(r.Context(), from)
+		if err != nil {
+			log.Error("failed to get previous report", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve history"))
+			return
+		}
+
+		reports, err := inRange(r.Context(), from, to)
+		if err != nil {
+			log.Error("failed to get reports", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve history"))
+			return
+		}
+
+		render.JSON(w, r, screport.BuildHistory(prev, reports))
+	}
+}
+
+// parseRange reads the required ?from and ?to dates. Writes the 400 response
+// itself and returns ok=false on invalid input.
+func parseRange(w http.ResponseWriter, r *http.Request) (from, to string, ok bool) {
+	from = r.URL.Query().Get("from")
+	to = r.URL.Query().Get("to")
+
+	fromDate, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.BadRequest("Missing or invalid 'from' parameter (format: YYYY-MM-DD)"))
+		return "", "", false
+	}
+	toDate, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.BadRequest("Missing or invalid 'to' parameter (format: YYYY-MM-DD)"))
+		return "", "", false
+	}
+	if toDate.Before(fromDate) {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.BadRequest("'to' must not be before 'from'"))
+		return "", "", false
+	}
+	if toDate.Sub(fromDate) > maxRangeDays*24*time.Hour {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.BadRequest("Range must not exceed 366 days"))
+		return "", "", false
+	}
+	return from, to, true
+}
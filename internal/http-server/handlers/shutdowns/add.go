@@ -3,6 +3,7 @@ package shutdowns
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
@@ -13,6 +14,7 @@ import (
 	"srmt-admin/internal/lib/logger/sl"
 	"srmt-admin/internal/lib/service/auth"
 	"srmt-admin/internal/lib/service/dayrotation/cutoffs"
+	"srmt-admin/internal/lib/service/notification"
 	"srmt-admin/internal/storage"
 	"time"
 )
@@ -39,9 +41,17 @@ type ShutdownAdder interface {
 	AddShutdown(ctx context.Context, req dto.AddShutdownRequest, loc *time.Location) (int64, error)
 	LinkShutdownFiles(ctx context.Context, shutdownID int64, fileIDs []int64) error
 	GetOrganizationParentID(ctx context.Context, orgID int64) (*int64, error)
+	GetOrganizationName(ctx context.Context, orgID int64) (string, error)
 }
 
-func Add(log *slog.Logger, adder ShutdownAdder, loc *time.Location) http.HandlerFunc {
+type ShutdownNotifier interface {
+	Notify(ctx context.Context, e notification.Event) error
+}
+
+// NotifyRoles are the roles told about registered shutdowns
+var NotifyRoles = []string{"sc"}
+
+func Add(log *slog.Logger, adder ShutdownAdder, notifier ShutdownNotifier, loc *time.Location) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.shutdown.Add"
 		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
@@ -152,6 +162,8 @@ func Add(log *slog.Logger, adder ShutdownAdder, loc *time.Location) http.Handler
 			}
 		}
 
+		notifyShutdown(r.Context(), log, adder, notifier, req)
+
 		log.Info("shutdown added successfully",
 			slog.Int64("id", id),
 			slog.Int64("user_id", userID),
@@ -166,3 +178,28 @@ func Add(log *slog.Logger, adder ShutdownAdder, loc *time.Location) http.Handler
 		})
 	}
 }
+
+// notifyShutdown tells the situation center about a new shutdown. Failures
+// are logged; the shutdown stands.
+func notifyShutdown(ctx context.Context, log *slog.Logger, adder ShutdownAdder, notifier ShutdownNotifier, req addRequest) {
+	name, err := adder.GetOrganizationName(ctx, req.OrganizationID)
+	if err != nil {
+		log.Warn("failed to get organization name", sl.Err(err))
+		name = fmt.Sprintf("Организация ID %d", req.OrganizationID)
+	}
+
+	msg := fmt.Sprintf("%s, с %s", name, req.StartTime.Format("02.01.2006 15:04"))
+	if req.Reason != nil && *req.Reason != "" {
+		msg += ": " + *req.Reason
+	}
+
+	err = notifier.Notify(ctx, notification.Event{
+		Type:    notification.ShutdownCreated,
+		Roles:   NotifyRoles,
+		Message: msg,
+		Link:    "/shutdowns",
+	})
+	if err != nil {
+		log.Error("failed to notify about shutdown", sl.Err(err))
+	}
+}
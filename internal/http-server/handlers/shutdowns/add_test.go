@@ -11,6 +11,7 @@ import (
 	"net/http/httptest"
 	mwauth "srmt-admin/internal/http-server/middleware/auth"
 	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/service/notification"
 	"srmt-admin/internal/storage"
 	"srmt-admin/internal/token"
 	"testing"
@@ -23,6 +24,7 @@ type mockShutdownAdder struct {
 	linkFunc   func(ctx context.Context, shutdownID int64, fileIDs []int64) error
 	parentFunc func(ctx context.Context, orgID int64) (*int64, error)
 	addCalls   int
+	events     []notification.Event
 }
 
 func (m *mockShutdownAdder) LinkShutdownFiles(ctx context.Context, shutdownID int64, fileIDs []int64) error {
@@ -40,6 +42,15 @@ func (m *mockShutdownAdder) AddShutdown(ctx context.Context, req dto.AddShutdown
 	return 1, nil
 }
 
+func (m *mockShutdownAdder) GetOrganizationName(_ context.Context, _ int64) (string, error) {
+	return "Чарвакская ГЭС", nil
+}
+
+func (m *mockShutdownAdder) Notify(_ context.Context, e notification.Event) error {
+	m.events = append(m.events, e)
+	return nil
+}
+
 // GetOrganizationParentID is required by the new CascadeChecker interface.
 func (m *mockShutdownAdder) GetOrganizationParentID(ctx context.Context, orgID int64) (*int64, error) {
 	if m.parentFunc != nil {
@@ -252,7 +263,7 @@ func TestAdd(t *testing.T) {
 			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
 			// Call handler
-			handler := Add(logger, mock, time.UTC)
+			handler := Add(logger, mock, mock, time.UTC)
 			handler.ServeHTTP(rr, req)
 
 			// Check status code
@@ -279,6 +290,11 @@ func TestAdd(t *testing.T) {
 				if resp.ID != tt.mockResponse {
 					t.Errorf("response ID = %v, want %v", resp.ID, tt.mockResponse)
 				}
+				if len(mock.events) != 1 || mock.events[0].Type != notification.ShutdownCreated {
+					t.Errorf("events = %+v, want one %s", mock.events, notification.ShutdownCreated)
+				}
+			} else if len(mock.events) != 0 {
+				t.Errorf("failed request must not notify: %+v", mock.events)
 			}
 		})
 	}
@@ -300,7 +316,7 @@ func TestAdd_NoUserID(t *testing.T) {
 
 	rr := httptest.NewRecorder()
 
-	handler := Add(logger, mock, time.UTC)
+	handler := Add(logger, mock, mock, time.UTC)
 	handler.ServeHTTP(rr, req)
 
 	if rr.Code != http.StatusUnauthorized {
@@ -333,7 +349,7 @@ func runAddWithCtx(t *testing.T, mock *mockShutdownAdder, ctx context.Context, b
 
 	rr := httptest.NewRecorder()
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-	handler := Add(logger, mock, time.UTC)
+	handler := Add(logger, mock, mock, time.UTC)
 	handler.ServeHTTP(rr, req)
 	return rr
 }
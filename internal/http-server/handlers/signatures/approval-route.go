@@ -0,0 +1,212 @@
+package signatures
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/signature"
+	"srmt-admin/internal/lib/service/auth"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type approvalRouteCreator interface {
+	CreateApprovalRoute(ctx context.Context, docType string, docID int64, req signature.CreateRouteRequest, userID int64) (int64, error)
+}
+
+type approvalRouteGetter interface {
+	GetApprovalRoute(ctx context.Context, docType string, docID int64) (*signature.ApprovalRoute, error)
+}
+
+type approvalRouteCanceller interface {
+	CancelApprovalRoute(ctx context.Context, docType string, docID int64) error
+}
+
+type createRouteResponse struct {
+	resp.Response
+	ID int64 `json:"id"`
+}
+
+// CreateRoute puts a document on an approval route. Stages are signed in
+// order; the signers listed in one stage sign in parallel. The signers of
+// the first stage are notified.
+func CreateRoute(log *slog.Logger, creator approvalRouteCreator, notifier signingNotifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.signatures.create-route"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		docType, ok := documentType(w, r, log)
+		if !ok {
+			return
+		}
+		log = log.With(slog.String("document_type", docType))
+
+		userID, err := auth.GetUserID(r.Context())
+		if err != nil {
+			log.Error("failed to get user id from context", sl.Err(err))
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		docID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Warn("invalid 'id' parameter", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'id' parameter"))
+			return
+		}
+
+		var req signature.CreateRouteRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			log.Error("validation failed", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		id, err := creator.CreateApprovalRoute(r.Context(), docType, docID, req, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				log.Warn("document not found", slog.Int64("id", docID))
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Document not found"))
+			case errors.Is(err, storage.ErrDuplicate):
+				log.Warn("document already has an active route or a stage lists a signer twice", slog.Int64("id", docID))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Document already has an active approval route, or a stage lists the same signer twice"))
+			case errors.Is(err, storage.ErrInvalidStatus):
+				log.Warn("document cannot be routed in its status", slog.Int64("id", docID))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Document cannot be put on an approval route in its current status"))
+			case errors.Is(err, storage.ErrForeignKeyViolation):
+				log.Warn("unknown signer", slog.Int64("id", docID))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid signer user ID"))
+			default:
+				log.Error("failed to create approval route", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to create approval route"))
+			}
+			return
+		}
+
+		log.Info("approval route created",
+			slog.Int64("route_id", id),
+			slog.Int64("document_id", docID),
+			slog.Int("stages", len(req.Stages)),
+		)
+
+		requestSignatures(r.Context(), log, notifier, docID, stageSigners(req))
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, createRouteResponse{
+			Response: resp.Created(),
+			ID:       id,
+		})
+	}
+}
+
+// GetRoute returns the latest approval route of a document with its steps.
+func GetRoute(log *slog.Logger, getter approvalRouteGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.signatures.get-route"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		docType, ok := documentType(w, r, log)
+		if !ok {
+			return
+		}
+		log = log.With(slog.String("document_type", docType))
+
+		docID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Warn("invalid 'id' parameter", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'id' parameter"))
+			return
+		}
+
+		route, err := getter.GetApprovalRoute(r.Context(), docType, docID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				log.Warn("approval route not found", slog.Int64("id", docID))
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Approval route not found"))
+				return
+			}
+			log.Error("failed to get approval route", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve approval route"))
+			return
+		}
+
+		render.JSON(w, r, route)
+	}
+}
+
+// CancelRoute cancels the document's active approval route.
+func CancelRoute(log *slog.Logger, canceller approvalRouteCanceller) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.signatures.cancel-route"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		docType, ok := documentType(w, r, log)
+		if !ok {
+			return
+		}
+		log = log.With(slog.String("document_type", docType))
+
+		docID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Warn("invalid 'id' parameter", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'id' parameter"))
+			return
+		}
+
+		if err := canceller.CancelApprovalRoute(r.Context(), docType, docID); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				log.Warn("no active approval route", slog.Int64("id", docID))
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("No active approval route"))
+				return
+			}
+			log.Error("failed to cancel approval route", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to cancel approval route"))
+			return
+		}
+
+		log.Info("approval route cancelled", slog.Int64("document_id", docID))
+		render.Status(r, http.StatusNoContent)
+	}
+}
@@ -0,0 +1,242 @@
+package signatures
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/signature"
+	"srmt-admin/internal/lib/service/auth"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type delegationCreator interface {
+	CreateApprovalDelegation(ctx context.Context, userID, delegateUserID int64, dateFrom, dateTo time.Time, createdBy int64) (int64, error)
+}
+
+type delegationGetter interface {
+	GetApprovalDelegations(ctx context.Context, userID int64) ([]signature.Delegation, error)
+}
+
+type delegationDeleter interface {
+	GetApprovalDelegationByID(ctx context.Context, id int64) (*signature.Delegation, error)
+	DeleteApprovalDelegation(ctx context.Context, id int64) error
+}
+
+// CreateDelegation lets a delegate act on the pending approval steps of a
+// signer for a date range. Without user_id the caller delegates their own
+// signature; only admin and rais may delegate someone else's.
+func CreateDelegation(log *slog.Logger, creator delegationCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.signatures.create-delegation"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		callerID, err := auth.GetUserID(r.Context())
+		if err != nil {
+			log.Error("failed to get user id from context", sl.Err(err))
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		var req signature.CreateDelegationRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			var vErrs validator.ValidationErrors
+			errors.As(err, &vErrs)
+			log.Error("validation failed", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationErrors(vErrs))
+			return
+		}
+
+		userID := callerID
+		if req.UserID != nil {
+			userID = *req.UserID
+		}
+		if userID != callerID && !canManageDelegations(r.Context()) {
+			log.Warn("attempt to delegate another user's signature", slog.Int64("user_id", userID))
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, resp.Forbidden("Only your own signature can be delegated"))
+			return
+		}
+		if userID == req.DelegateUserID {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("A user cannot delegate to themselves"))
+			return
+		}
+
+		// Formats are checked by the validator above.
+		dateFrom, _ := time.Parse("2006-01-02", req.DateFrom)
+		dateTo, _ := time.Parse("2006-01-02", req.DateTo)
+		if dateTo.Before(dateFrom) {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("'date_to' must not be before 'date_from'"))
+			return
+		}
+
+		id, err := creator.CreateApprovalDelegation(r.Context(), userID, req.DelegateUserID, dateFrom, dateTo, callerID)
+		if err != nil {
+			if errors.Is(err, storage.ErrForeignKeyViolation) {
+				log.Warn("unknown user in delegation")
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid user ID"))
+				return
+			}
+			log.Error("failed to create delegation", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to create delegation"))
+			return
+		}
+
+		log.Info("signature delegated",
+			slog.Int64("id", id),
+			slog.Int64("user_id", userID),
+			slog.Int64("delegate_user_id", req.DelegateUserID),
+		)
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, createRouteResponse{
+			Response: resp.Created(),
+			ID:       id,
+		})
+	}
+}
+
+// GetDelegations lists unexpired delegations given by or to a user: the
+// caller by default; admin and rais may pass another user with ?user_id=, or
+// everyone with ?user_id=0.
+func GetDelegations(log *slog.Logger, getter delegationGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.signatures.get-delegations"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		callerID, err := auth.GetUserID(r.Context())
+		if err != nil {
+			log.Error("failed to get user id from context", sl.Err(err))
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		userID := callerID
+		if v := r.URL.Query().Get("user_id"); v != "" {
+			userID, err = strconv.ParseInt(v, 10, 64)
+			if err != nil || userID < 0 {
+				log.Warn("invalid 'user_id' parameter", slog.String("user_id", v))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.BadRequest("Invalid 'user_id' parameter"))
+				return
+			}
+		}
+		if userID != callerID && !canManageDelegations(r.Context()) {
+			log.Warn("attempt to list another user's delegations", slog.Int64("user_id", userID))
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, resp.Forbidden("Only your own delegations can be viewed"))
+			return
+		}
+
+		delegations, err := getter.GetApprovalDelegations(r.Context(), userID)
+		if err != nil {
+			log.Error("failed to get delegations", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to retrieve delegations"))
+			return
+		}
+
+		render.JSON(w, r, delegations)
+	}
+}
+
+// DeleteDelegation revokes a delegation. Only the signer who gave it, admin
+// or rais may revoke it.
+func DeleteDelegation(log *slog.Logger, deleter delegationDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.signatures.delete-delegation"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		callerID, err := auth.GetUserID(r.Context())
+		if err != nil {
+			log.Error("failed to get user id from context", sl.Err(err))
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Warn("invalid 'id' parameter", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'id' parameter"))
+			return
+		}
+
+		d, err := deleter.GetApprovalDelegationByID(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				log.Warn("delegation not found", slog.Int64("id", id))
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Delegation not found"))
+				return
+			}
+			log.Error("failed to get delegation", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to delete delegation"))
+			return
+		}
+		if d.UserID != callerID && !canManageDelegations(r.Context()) {
+			log.Warn("attempt to revoke another user's delegation", slog.Int64("id", id))
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, resp.Forbidden("Only your own delegations can be revoked"))
+			return
+		}
+
+		if err := deleter.DeleteApprovalDelegation(r.Context(), id); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				log.Warn("delegation not found", slog.Int64("id", id))
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Delegation not found"))
+				return
+			}
+			log.Error("failed to delete delegation", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to delete delegation"))
+			return
+		}
+
+		log.Info("delegation deleted", slog.Int64("id", id))
+		render.Status(r, http.StatusNoContent)
+	}
+}
+
+// canManageDelegations reports whether the caller may manage the delegations
+// of other users.
+func canManageDelegations(ctx context.Context) bool {
+	claims, ok := mwauth.ClaimsFromContext(ctx)
+	if !ok || claims == nil {
+		return false
+	}
+	for _, role := range claims.Roles {
+		if role == "admin" || role == "rais" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package signatures
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	"srmt-admin/internal/lib/model/signature"
+	"srmt-admin/internal/storage"
+	"srmt-admin/internal/token"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type mockDelegations struct {
+	created   bool
+	listedFor int64
+	deleted   bool
+	owner     int64
+}
+
+func (m *mockDelegations) CreateApprovalDelegation(_ context.Context, _, _ int64, _, _ time.Time, _ int64) (int64, error) {
+	m.created = true
+	return 1, nil
+}
+
+func (m *mockDelegations) GetApprovalDelegations(_ context.Context, userID int64) ([]signature.Delegation, error) {
+	m.listedFor = userID
+	return []signature.Delegation{}, nil
+}
+
+func (m *mockDelegations) GetApprovalDelegationByID(_ context.Context, id int64) (*signature.Delegation, error) {
+	if m.owner == 0 {
+		return nil, storage.ErrNotFound
+	}
+	return &signature.Delegation{ID: id, UserID: m.owner, DelegateUserID: 99}, nil
+}
+
+func (m *mockDelegations) DeleteApprovalDelegation(_ context.Context, _ int64) error {
+	m.deleted = true
+	return nil
+}
+
+func newDelegationRequest(method, target, body string, userID int64, roles ...string) *http.Request {
+	req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "5")
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = mwauth.ContextWithClaims(ctx, &token.Claims{UserID: userID, Name: "Test User", Roles: roles})
+	return req.WithContext(ctx)
+}
+
+func TestCreateDelegation_OthersOnlyForPrivileged(t *testing.T) {
+	body := `{"user_id": 43, "delegate_user_id": 44, "date_from": "2026-11-01", "date_to": "2026-11-10"}`
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	repo := &mockDelegations{}
+	rr := httptest.NewRecorder()
+	CreateDelegation(log, repo).ServeHTTP(rr, newDelegationRequest(http.MethodPost, "/approval-delegations", body, 42, "chancellery"))
+	if rr.Code != http.StatusForbidden || repo.created {
+		t.Fatalf("got status %d, want 403 without creating, body: %s", rr.Code, rr.Body.String())
+	}
+
+	repo = &mockDelegations{}
+	rr = httptest.NewRecorder()
+	CreateDelegation(log, repo).ServeHTTP(rr, newDelegationRequest(http.MethodPost, "/approval-delegations", body, 42, "rais"))
+	if rr.Code != http.StatusCreated || !repo.created {
+		t.Fatalf("got status %d, want 201, body: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestGetDelegations_OthersOnlyForPrivileged(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, target := range []string{"/approval-delegations?user_id=0", "/approval-delegations?user_id=43"} {
+		repo := &mockDelegations{listedFor: -1}
+		rr := httptest.NewRecorder()
+		GetDelegations(log, repo).ServeHTTP(rr, newDelegationRequest(http.MethodGet, target, "", 42, "chancellery"))
+		if rr.Code != http.StatusForbidden || repo.listedFor != -1 {
+			t.Fatalf("%s: got status %d, want 403 without listing", target, rr.Code)
+		}
+	}
+
+	repo := &mockDelegations{}
+	rr := httptest.NewRecorder()
+	GetDelegations(log, repo).ServeHTTP(rr, newDelegationRequest(http.MethodGet, "/approval-delegations", "", 42, "chancellery"))
+	if rr.Code != http.StatusOK || repo.listedFor != 42 {
+		t.Fatalf("got status %d listing for %d, want 200 for 42", rr.Code, repo.listedFor)
+	}
+
+	repo = &mockDelegations{listedFor: -1}
+	rr = httptest.NewRecorder()
+	GetDelegations(log, repo).ServeHTTP(rr, newDelegationRequest(http.MethodGet, "/approval-delegations?user_id=0", "", 42, "admin"))
+	if rr.Code != http.StatusOK || repo.listedFor != 0 {
+		t.Fatalf("got status %d listing for %d, want 200 for everyone", rr.Code, repo.listedFor)
+	}
+}
+
+func TestDeleteDelegation_OwnerOrPrivileged(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	repo := &mockDelegations{owner: 43}
+	rr := httptest.NewRecorder()
+	DeleteDelegation(log, repo).ServeHTTP(rr, newDelegationRequest(http.MethodDelete, "/approval-delegations/5", "", 42, "chancellery"))
+	if rr.Code != http.StatusForbidden || repo.deleted {
+		t.Fatalf("got status %d, want 403 without deleting", rr.Code)
+	}
+
+	repo = &mockDelegations{owner: 42}
+	rr = httptest.NewRecorder()
+	DeleteDelegation(log, repo).ServeHTTP(rr, newDelegationRequest(http.MethodDelete, "/approval-delegations/5", "", 42, "chancellery"))
+	if !repo.deleted {
+		t.Fatalf("got status %d, the owner must be able to revoke", rr.Code)
+	}
+
+	repo = &mockDelegations{owner: 43}
+	rr = httptest.NewRecorder()
+	DeleteDelegation(log, repo).ServeHTTP(rr, newDelegationRequest(http.MethodDelete, "/approval-delegations/5", "", 42, "admin"))
+	if !repo.deleted {
+		t.Fatalf("got status %d, admin must be able to revoke", rr.Code)
+	}
+}
@@ -8,22 +8,33 @@ import (
 	resp "srmt-admin/internal/lib/api/response"
 	"srmt-admin/internal/lib/logger/sl"
 	"srmt-admin/internal/lib/model/signature"
+	"srmt-admin/internal/lib/service/auth"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 )
 
 type pendingDocumentsGetter interface {
-	GetPendingSignatureDocuments(ctx context.Context) ([]signature.PendingDocument, error)
+	GetPendingSignatureDocuments(ctx context.Context, userID int64) ([]signature.PendingDocument, error)
 }
 
-// GetPending returns all documents waiting for signature across all document types
+// GetPending returns the documents waiting for the caller's signature across
+// all document types. Documents on an approval route are listed only while
+// the caller (or a signer who delegated to them) has a step in the current stage.
 func GetPending(log *slog.Logger, getter pendingDocumentsGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.signatures.get-pending"
 		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
 
-		documents, err := getter.GetPendingSignatureDocuments(r.Context())
+		userID, err := auth.GetUserID(r.Context())
+		if err != nil {
+			log.Error("failed to get user id from context", sl.Err(err))
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("Not authenticated"))
+			return
+		}
+
+		documents, err := getter.GetPendingSignatureDocuments(r.Context(), userID)
 		if err != nil {
 			log.Error("failed to get pending signature documents", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
@@ -20,22 +20,19 @@ type documentSignaturesGetter interface {
 }
 
 // GetSignatures returns all signatures for a specific document
-func GetSignatures(log *slog.Logger, getter documentSignaturesGetter, docType string) http.HandlerFunc {
+func GetSignatures(log *slog.Logger, getter documentSignaturesGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.signatures.get-signatures"
 		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
-			slog.String("document_type", docType),
 		)
 
-		// Validate document type
-		if !signature.IsValidDocumentType(docType) {
-			log.Warn("invalid document type")
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, resp.BadRequest("Invalid document type"))
+		docType, ok := documentType(w, r, log)
+		if !ok {
 			return
 		}
+		log = log.With(slog.String("document_type", docType))
 
 		// Parse document ID
 		idStr := chi.URLParam(r, "id")
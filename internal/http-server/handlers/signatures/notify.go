@@ -0,0 +1,76 @@
+package signatures
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	mwdockind "srmt-admin/internal/http-server/middleware/document-kind"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/signature"
+	"srmt-admin/internal/lib/service/notification"
+)
+
+type signingNotifier interface {
+	Notify(ctx context.Context, e notification.Event) error
+}
+
+// requestSignatures tells the signers that a document awaits their
+// signature. A failure is logged; the route itself stands.
+func requestSignatures(ctx context.Context, log *slog.Logger, notifier signingNotifier, docID int64, signerIDs []int64) {
+	if len(signerIDs) == 0 {
+		return
+	}
+
+	kind, _ := mwdockind.KindFromContext(ctx)
+	label := kind.Name
+	if label == "" {
+		label = "Документ"
+	}
+
+	err := notifier.Notify(ctx, notification.Event{
+		Type:    notification.SignatureRequested,
+		Users:   signerIDs,
+		Message: fmt.Sprintf("%s (ID %d) ожидает вашей подписи", label, docID),
+		Link:    fmt.Sprintf("/documents/%s/%d", kind.Code, docID),
+	})
+	if err != nil {
+		log.Error("failed to notify signers", sl.Err(err), slog.Int64("document_id", docID))
+	}
+}
+
+// nextSigners returns the signers whose turn came when userID signed a step
+// of the route: the pending signers of the current stage if the route moved
+// past the stage of that step, none otherwise.
+func nextSigners(route *signature.ApprovalRoute, userID int64) []int64 {
+	signedStage := 0
+	for _, step := range route.Steps {
+		if step.Status == signature.StepSigned && step.ActedBy != nil && step.ActedBy.ID == userID && step.Stage > signedStage {
+			signedStage = step.Stage
+		}
+	}
+	if signedStage == 0 || route.CurrentStage <= signedStage {
+		return nil
+	}
+
+	var signers []int64
+	for _, step := range route.Steps {
+		if step.Stage == route.CurrentStage && step.Status == signature.StepPending {
+			signers = append(signers, step.Signer.ID)
+		}
+	}
+	return signers
+}
+
+// stageSigners returns the user IDs of the signers listed in the first stage
+// of a route request.
+func stageSigners(req signature.CreateRouteRequest) []int64 {
+	if len(req.Stages) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(req.Stages[0].Signers))
+	for _, s := range req.Stages[0].Signers {
+		ids = append(ids, s.UserID)
+	}
+	return ids
+}
@@ -10,7 +10,6 @@ import (
 	resp "srmt-admin/internal/lib/api/response"
 	"srmt-admin/internal/lib/dto"
 	"srmt-admin/internal/lib/logger/sl"
-	"srmt-admin/internal/lib/model/signature"
 	"srmt-admin/internal/lib/service/auth"
 	"srmt-admin/internal/storage"
 
@@ -20,27 +19,29 @@ import (
 )
 
 type signatureRejecter interface {
+	GetStatusIDByCode(ctx context.Context, code string) (int, error)
+	HasActiveApprovalRoute(ctx context.Context, docType string, docID int64) (bool, error)
 	RejectSignature(ctx context.Context, docType string, docID int64, reason *string, userID int64) error
 	GetSignatureRejectedStatusInfo(ctx context.Context) (*dto.StatusInfo, error)
 }
 
-// Reject rejects a document signature with optional reason
-func Reject(log *slog.Logger, rejecter signatureRejecter, docType string) http.HandlerFunc {
+// Reject rejects a document signature with optional reason. The reason is
+// passed to the status graph as the transition comment, so an edge requiring
+// a comment makes it mandatory. On an approval route only a signer of the
+// current stage may reject, and the rejection ends the route.
+func Reject(log *slog.Logger, rejecter signatureRejecter, workflow statusAuthorizer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.signatures.reject"
 		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
-			slog.String("document_type", docType),
 		)
 
-		// Validate document type
-		if !signature.IsValidDocumentType(docType) {
-			log.Warn("invalid document type")
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, resp.BadRequest("Invalid document type"))
+		docType, ok := documentType(w, r, log)
+		if !ok {
 			return
 		}
+		log = log.With(slog.String("document_type", docType))
 
 		// Get user ID from context
 		userID, err := auth.GetUserID(r.Context())
@@ -70,6 +71,10 @@ func Reject(log *slog.Logger, rejecter signatureRejecter, docType string) http.H
 			return
 		}
 
+		if !authorizeSignature(w, r, log, rejecter, workflow, docType, docID, "signature_rejected", req.Reason) {
+			return
+		}
+
 		// Reject the signature
 		err = rejecter.RejectSignature(r.Context(), docType, docID, req.Reason, userID)
 		if err != nil {
@@ -79,6 +84,12 @@ func Reject(log *slog.Logger, rejecter signatureRejecter, docType string) http.H
 				render.JSON(w, r, resp.NotFound("Document not found"))
 				return
 			}
+			if errors.Is(err, storage.ErrNotYourTurn) {
+				log.Warn("user has no pending approval step", slog.Int64("id", docID), slog.Int64("user_id", userID))
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, resp.Forbidden("It is not your turn to sign this document"))
+				return
+			}
 			// Check for "not in pending_signature status" error
 			if errors.Is(err, storage.ErrInvalidStatus) ||
 				(err != nil && containsStatusError(err.Error())) {
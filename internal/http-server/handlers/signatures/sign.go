@@ -12,6 +12,7 @@ import (
 	"srmt-admin/internal/lib/logger/sl"
 	"srmt-admin/internal/lib/model/signature"
 	"srmt-admin/internal/lib/service/auth"
+	docworkflow "srmt-admin/internal/lib/service/document-workflow"
 	"srmt-admin/internal/storage"
 
 	"github.com/go-chi/chi/v5"
@@ -20,27 +21,43 @@ import (
 )
 
 type documentSigner interface {
-	SignDocument(ctx context.Context, docType string, docID int64, req dto.SignDocumentRequest, userID int64) error
+	GetStatusIDByCode(ctx context.Context, code string) (int, error)
+	HasActiveApprovalRoute(ctx context.Context, docType string, docID int64) (bool, error)
+	SignDocument(ctx context.Context, docType string, docID int64, req dto.SignDocumentRequest, userID int64) (bool, error)
 	GetSignedStatusInfo(ctx context.Context) (*dto.StatusInfo, error)
+	GetApprovalRoute(ctx context.Context, docType string, docID int64) (*signature.ApprovalRoute, error)
 }
 
-// Sign signs a document with optional resolution, executor assignment, and due date
-func Sign(log *slog.Logger, signer documentSigner, docType string) http.HandlerFunc {
+type documentSealer interface {
+	Seal(ctx context.Context, docType string, docID int64, resolution *string, userID int64) (*signature.Seal, error)
+}
+
+type statusAuthorizer interface {
+	Authorize(ctx context.Context, docType string, docID int64, toStatusID int, comment *string, actor docworkflow.Actor) (int, error)
+}
+
+// Sign signs a document with optional resolution, executor assignment, and due date.
+// The pending_signature → signed edge of the status graph is checked first, so
+// role restrictions configured on it apply to signing too. Documents on an
+// approval route skip that check: the route names who signs and when, and
+// the document is reported as awaiting signatures until its last stage is done.
+// The signature is sealed with the document's key fields and the hashes of its
+// attachments as the signer sees them; a document changed in between is
+// refused with 409. When a signature completes a stage of the route, the
+// signers of the next stage are notified.
+func Sign(log *slog.Logger, signer documentSigner, sealer documentSealer, workflow statusAuthorizer, notifier signingNotifier) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.signatures.sign"
 		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
-			slog.String("document_type", docType),
 		)
 
-		// Validate document type
-		if !signature.IsValidDocumentType(docType) {
-			log.Warn("invalid document type")
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, resp.BadRequest("Invalid document type"))
+		docType, ok := documentType(w, r, log)
+		if !ok {
 			return
 		}
+		log = log.With(slog.String("document_type", docType))
 
 		// Get user ID from context
 		userID, err := auth.GetUserID(r.Context())
@@ -70,8 +87,26 @@ func Sign(log *slog.Logger, signer documentSigner, docType string) http.HandlerF
 			return
 		}
 
+		if !authorizeSignature(w, r, log, signer, workflow, docType, docID, "signed", req.ResolutionText) {
+			return
+		}
+
+		req.Seal, err = sealer.Seal(r.Context(), docType, docID, req.ResolutionText, userID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				log.Warn("document not found", slog.Int64("id", docID))
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Document not found"))
+				return
+			}
+			log.Error("failed to seal document", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to sign document"))
+			return
+		}
+
 		// Sign the document
-		err = signer.SignDocument(r.Context(), docType, docID, req, userID)
+		completed, err := signer.SignDocument(r.Context(), docType, docID, req, userID)
 		if err != nil {
 			if errors.Is(err, storage.ErrNotFound) {
 				log.Warn("document not found", slog.Int64("id", docID))
@@ -79,6 +114,18 @@ func Sign(log *slog.Logger, signer documentSigner, docType string) http.HandlerF
 				render.JSON(w, r, resp.NotFound("Document not found"))
 				return
 			}
+			if errors.Is(err, storage.ErrNotYourTurn) {
+				log.Warn("user has no pending approval step", slog.Int64("id", docID), slog.Int64("user_id", userID))
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, resp.Forbidden("It is not your turn to sign this document"))
+				return
+			}
+			if errors.Is(err, storage.ErrContentChanged) {
+				log.Warn("document changed while signing", slog.Int64("id", docID))
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Conflict("Document changed while it was being signed, review it and sign again"))
+				return
+			}
 			// Check for "not in pending_signature status" error
 			if errors.Is(err, storage.ErrInvalidStatus) ||
 				(err != nil && containsStatusError(err.Error())) {
@@ -93,6 +140,20 @@ func Sign(log *slog.Logger, signer documentSigner, docType string) http.HandlerF
 			return
 		}
 
+		if !completed {
+			log.Info("approval step signed, route continues",
+				slog.Int64("document_id", docID),
+				slog.Int64("signed_by", userID),
+			)
+			if route, err := signer.GetApprovalRoute(r.Context(), docType, docID); err != nil {
+				log.Error("failed to get approval route", sl.Err(err))
+			} else {
+				requestSignatures(r.Context(), log, notifier, docID, nextSigners(route, userID))
+			}
+			render.JSON(w, r, dto.SignatureResponse{Status: "OK", AwaitingSignatures: true})
+			return
+		}
+
 		// Get signed status info for response
 		signedStatus, err := signer.GetSignedStatusInfo(r.Context())
 		if err != nil {
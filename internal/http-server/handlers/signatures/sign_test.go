@@ -0,0 +1,216 @@
+package signatures
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	mwdockind "srmt-admin/internal/http-server/middleware/document-kind"
+	"srmt-admin/internal/lib/dto"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
+	"srmt-admin/internal/lib/model/signature"
+	"srmt-admin/internal/lib/model/user"
+	docworkflow "srmt-admin/internal/lib/service/document-workflow"
+	"srmt-admin/internal/lib/service/notification"
+	"srmt-admin/internal/storage"
+	"srmt-admin/internal/token"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type mockSigner struct {
+	routed    bool
+	completed bool
+	signErr   error
+	signedBy  int64
+	seal      *signature.Seal
+	route     *signature.ApprovalRoute
+}
+
+func (m *mockSigner) GetStatusIDByCode(_ context.Context, _ string) (int, error) { return 9, nil }
+
+func (m *mockSigner) HasActiveApprovalRoute(_ context.Context, _ string, _ int64) (bool, error) {
+	return m.routed, nil
+}
+
+func (m *mockSigner) SignDocument(_ context.Context, _ string, _ int64, req dto.SignDocumentRequest, userID int64) (bool, error) {
+	m.signedBy = userID
+	m.seal = req.Seal
+	return m.completed, m.signErr
+}
+
+func (m *mockSigner) GetSignedStatusInfo(_ context.Context) (*dto.StatusInfo, error) {
+	return &dto.StatusInfo{ID: 9, Code: "signed", Name: "Подписан"}, nil
+}
+
+func (m *mockSigner) GetApprovalRoute(_ context.Context, _ string, _ int64) (*signature.ApprovalRoute, error) {
+	if m.route == nil {
+		return nil, storage.ErrNotFound
+	}
+	return m.route, nil
+}
+
+type mockNotifier struct {
+	events []notification.Event
+}
+
+func (m *mockNotifier) Notify(_ context.Context, e notification.Event) error {
+	m.events = append(m.events, e)
+	return nil
+}
+
+type mockSealer struct {
+	resolution *string
+}
+
+func (m *mockSealer) Seal(_ context.Context, docType string, docID int64, resolution *string, userID int64) (*signature.Seal, error) {
+	m.resolution = resolution
+	return &signature.Seal{Digest: "d", Manifest: signature.Manifest{DocumentType: docType, DocumentID: docID, SignedBy: userID}}, nil
+}
+
+type mockAuthorizer struct {
+	called bool
+	err    error
+}
+
+func (m *mockAuthorizer) Authorize(_ context.Context, _ string, _ int64, _ int, _ *string, _ docworkflow.Actor) (int, error) {
+	m.called = true
+	return 8, m.err
+}
+
+func newSignRequest(t *testing.T, userID int64) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/decrees/7/sign", bytes.NewReader([]byte(`{}`)))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "7")
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = mwauth.ContextWithClaims(ctx, &token.Claims{UserID: userID, Name: "Test User", Roles: []string{"chancellery"}})
+	ctx = mwdockind.ContextWithKind(ctx, document_kind.Model{Code: "decree", TableName: "decrees", IsActive: true})
+	return req.WithContext(ctx)
+}
+
+func TestSign_RouteContinues(t *testing.T) {
+	// 42 signed the only step of stage 1; stage 2 is now up
+	signer := &mockSigner{routed: true, completed: false, route: &signature.ApprovalRoute{
+		Status:       signature.RouteActive,
+		CurrentStage: 2,
+		Steps: []signature.ApprovalStep{
+			{Stage: 1, Signer: user.ShortInfo{ID: 42}, Status: signature.StepSigned, ActedBy: &user.ShortInfo{ID: 42}},
+			{Stage: 2, Signer: user.ShortInfo{ID: 77}, Status: signature.StepPending},
+			{Stage: 3, Signer: user.ShortInfo{ID: 78}, Status: signature.StepPending},
+		},
+	}}
+	workflow := &mockAuthorizer{err: fmt.Errorf("must not be consulted")}
+	notifier := &mockNotifier{}
+
+	rr := httptest.NewRecorder()
+	Sign(slog.New(slog.NewTextHandler(io.Discard, nil)), signer, &mockSealer{}, workflow, notifier).ServeHTTP(rr, newSignRequest(t, 42))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200, body: %s", rr.Code, rr.Body.String())
+	}
+	if workflow.called {
+		t.Fatal("status graph must not be checked for a routed document")
+	}
+	if signer.signedBy != 42 {
+		t.Fatalf("signed by %d, want 42", signer.signedBy)
+	}
+
+	var body dto.SignatureResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if !body.AwaitingSignatures || body.NewStatus != nil {
+		t.Fatalf("expected awaiting signatures without new status, got %s", rr.Body.String())
+	}
+	if len(notifier.events) != 1 || len(notifier.events[0].Users) != 1 || notifier.events[0].Users[0] != 77 {
+		t.Fatalf("next stage signers must be notified, got %+v", notifier.events)
+	}
+}
+
+func TestSign_RouteCompleted(t *testing.T) {
+	signer := &mockSigner{routed: true, completed: true}
+
+	rr := httptest.NewRecorder()
+	Sign(slog.New(slog.NewTextHandler(io.Discard, nil)), signer, &mockSealer{}, &mockAuthorizer{}, &mockNotifier{}).ServeHTTP(rr, newSignRequest(t, 42))
+
+	var body dto.SignatureResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if rr.Code != http.StatusOK || body.AwaitingSignatures || body.NewStatus == nil || body.NewStatus.Code != "signed" {
+		t.Fatalf("expected signed status, got %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestSign_NotYourTurn(t *testing.T) {
+	signer := &mockSigner{routed: true, signErr: fmt.Errorf("storage.repo.SignDocument: %w", storage.ErrNotYourTurn)}
+
+	rr := httptest.NewRecorder()
+	Sign(slog.New(slog.NewTextHandler(io.Discard, nil)), signer, &mockSealer{}, &mockAuthorizer{}, &mockNotifier{}).ServeHTTP(rr, newSignRequest(t, 42))
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("got status %d, want 403, body: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestSign_UnroutedChecksStatusGraph(t *testing.T) {
+	signer := &mockSigner{completed: true}
+	from := 8
+	workflow := &mockAuthorizer{err: &docworkflow.TransitionError{
+		Code:         docworkflow.ReasonRoleForbidden,
+		FromStatusID: &from,
+		ToStatusID:   9,
+	}}
+
+	rr := httptest.NewRecorder()
+	Sign(slog.New(slog.NewTextHandler(io.Discard, nil)), signer, &mockSealer{}, workflow, &mockNotifier{}).ServeHTTP(rr, newSignRequest(t, 42))
+
+	if !workflow.called {
+		t.Fatal("status graph must be checked for a document without a route")
+	}
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("got status %d, want 409, body: %s", rr.Code, rr.Body.String())
+	}
+	if signer.signedBy != 0 {
+		t.Fatal("document must not be signed when the transition is refused")
+	}
+}
+
+func TestSign_SealsDocument(t *testing.T) {
+	signer := &mockSigner{completed: true}
+	sealer := &mockSealer{}
+
+	req := newSignRequest(t, 42)
+	req.Body = io.NopCloser(bytes.NewReader([]byte(`{"resolution_text":"К исполнению"}`)))
+	rr := httptest.NewRecorder()
+	Sign(slog.New(slog.NewTextHandler(io.Discard, nil)), signer, sealer, &mockAuthorizer{}, &mockNotifier{}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200, body: %s", rr.Code, rr.Body.String())
+	}
+	if sealer.resolution == nil || *sealer.resolution != "К исполнению" {
+		t.Fatalf("sealed with resolution %v", sealer.resolution)
+	}
+	if signer.seal == nil || signer.seal.Manifest.DocumentID != 7 || signer.seal.Manifest.SignedBy != 42 {
+		t.Fatalf("signed with seal %+v", signer.seal)
+	}
+}
+
+func TestSign_ContentChanged(t *testing.T) {
+	signer := &mockSigner{signErr: fmt.Errorf("storage.repo.SignDocument: %w", storage.ErrContentChanged)}
+
+	rr := httptest.NewRecorder()
+	Sign(slog.New(slog.NewTextHandler(io.Discard, nil)), signer, &mockSealer{}, &mockAuthorizer{}, &mockNotifier{}).ServeHTTP(rr, newSignRequest(t, 42))
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("got status %d, want 409, body: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,69 @@
+package signatures
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/model/signature"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type documentVerifier interface {
+	Verify(ctx context.Context, docType string, docID int64) (*signature.Verification, error)
+}
+
+// Verify recomputes the hashes of a document and its attachments and reports,
+// per sealed signature, whether the document changed after it was signed.
+func Verify(log *slog.Logger, verifier documentVerifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.signatures.verify"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		docType, ok := documentType(w, r, log)
+		if !ok {
+			return
+		}
+		log = log.With(slog.String("document_type", docType))
+
+		docID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Warn("invalid 'id' parameter", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid 'id' parameter"))
+			return
+		}
+
+		v, err := verifier.Verify(r.Context(), docType, docID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				log.Warn("document not found", slog.Int64("id", docID))
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, resp.NotFound("Document not found"))
+				return
+			}
+			log.Error("failed to verify document", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.InternalServerError("Failed to verify document"))
+			return
+		}
+
+		log.Info("document verified",
+			slog.Int64("document_id", docID),
+			slog.Bool("sealed", v.Sealed),
+			slog.Bool("intact", v.Intact),
+		)
+		render.JSON(w, r, v)
+	}
+}
@@ -0,0 +1,87 @@
+package signatures
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	docstatuses "srmt-admin/internal/http-server/handlers/document-statuses"
+	mwdockind "srmt-admin/internal/http-server/middleware/document-kind"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/render"
+)
+
+type statusIDResolver interface {
+	GetStatusIDByCode(ctx context.Context, code string) (int, error)
+	HasActiveApprovalRoute(ctx context.Context, docType string, docID int64) (bool, error)
+}
+
+// documentType returns the code of the document kind the route serves.
+func documentType(w http.ResponseWriter, r *http.Request, log *slog.Logger) (string, bool) {
+	kind, ok := mwdockind.KindFromContext(r.Context())
+	if !ok {
+		log.Error("document kind is not resolved for the route")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.InternalServerError("Document kind is not resolved"))
+		return "", false
+	}
+	return kind.Code, true
+}
+
+// authorizeSignature runs the status graph check for documents that are not
+// on an active approval route. A route decides who may sign, and its
+// intermediate signers need not hold the roles of the final signed edge.
+func authorizeSignature(w http.ResponseWriter, r *http.Request, log *slog.Logger,
+	statuses statusIDResolver, workflow statusAuthorizer, docType string, docID int64, toCode string, comment *string,
+) bool {
+	routed, err := statuses.HasActiveApprovalRoute(r.Context(), docType, docID)
+	if err != nil {
+		log.Error("failed to check approval route", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.InternalServerError("Failed to check approval route"))
+		return false
+	}
+	if routed {
+		return true
+	}
+	return authorizeSignatureTransition(w, r, log, statuses, workflow, docType, docID, toCode, comment)
+}
+
+// authorizeSignatureTransition checks the document's move to the status with
+// the given code against the status graph and writes the error response when
+// it is refused. Returns true when the caller may proceed.
+func authorizeSignatureTransition(w http.ResponseWriter, r *http.Request, log *slog.Logger,
+	statuses statusIDResolver, workflow statusAuthorizer, docType string, docID int64, toCode string, comment *string,
+) bool {
+	actor, _ := docstatuses.ActorFromContext(r.Context())
+
+	toStatusID, err := statuses.GetStatusIDByCode(r.Context(), toCode)
+	if err != nil {
+		log.Error("failed to resolve status", sl.Err(err), slog.String("status_code", toCode))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.InternalServerError("Failed to resolve status"))
+		return false
+	}
+
+	if _, err := workflow.Authorize(r.Context(), docType, docID, toStatusID, comment, actor); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("document not found", slog.Int64("id", docID))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.NotFound("Document not found"))
+			return false
+		}
+		if docstatuses.WriteTransitionError(w, r, err) {
+			log.Warn("status transition refused", sl.Err(err), slog.Int64("id", docID))
+			return false
+		}
+		log.Error("failed to check status transition", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.InternalServerError("Failed to check status transition"))
+		return false
+	}
+	return true
+}
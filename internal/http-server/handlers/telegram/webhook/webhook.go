@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	"srmt-admin/internal/lib/service/telegram"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type UpdateHandler interface {
+	HandleUpdate(ctx context.Context, u telegram.Update)
+}
+
+// New receives updates pushed by Telegram in webhook mode. Requests must
+// carry the configured secret; with no secret configured every request is
+// rejected.
+func New(log *slog.Logger, handler UpdateHandler, secret string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.telegram.webhook.New"
+		log := log.With(slog.String("op", op), slog.String("request_id", middleware.GetReqID(r.Context())))
+
+		got := r.Header.Get(telegram.SecretTokenHeader)
+		if secret == "" || subtle.ConstantTimeCompare([]byte(got), []byte(secret)) != 1 {
+			log.Warn("telegram webhook with invalid secret")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Unauthorized("unauthorized"))
+			return
+		}
+
+		var u telegram.Update
+		if err := render.DecodeJSON(r.Body, &u); err != nil {
+			log.Error("failed to decode update", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.BadRequest("Invalid request format"))
+			return
+		}
+
+		// Replies go out through the Bot API; Telegram only needs a 200
+		handler.HandleUpdate(r.Context(), u)
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"srmt-admin/internal/lib/service/telegram"
+)
+
+type mockHandler struct {
+	updates []telegram.Update
+}
+
+func (m *mockHandler) HandleUpdate(_ context.Context, u telegram.Update) {
+	m.updates = append(m.updates, u)
+}
+
+func TestNew(t *testing.T) {
+	body := `{"update_id":7,"message":{"message_id":1,"chat":{"id":5,"type":"private"},"text":"/help"}}`
+
+	tests := []struct {
+		name     string
+		secret   string
+		header   string
+		wantCode int
+	}{
+		{name: "valid", secret: "s3cret", header: "s3cret", wantCode: http.StatusOK},
+		{name: "wrong secret", secret: "s3cret", header: "guess", wantCode: http.StatusUnauthorized},
+		{name: "no secret configured", secret: "", header: "", wantCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &mockHandler{}
+			req := httptest.NewRequest(http.MethodPost, "/telegram/webhook", strings.NewReader(body))
+			req.Header.Set(telegram.SecretTokenHeader, tt.header)
+			rr := httptest.NewRecorder()
+
+			New(slog.New(slog.NewTextHandler(io.Discard, nil)), h, tt.secret).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d", rr.Code, tt.wantCode)
+			}
+			wantUpdates := 0
+			if tt.wantCode == http.StatusOK {
+				wantUpdates = 1
+			}
+			if len(h.updates) != wantUpdates {
+				t.Errorf("handled %d updates, want %d", len(h.updates), wantUpdates)
+			}
+		})
+	}
+}
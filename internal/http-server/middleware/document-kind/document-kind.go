@@ -0,0 +1,67 @@
+package documentkind
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "srmt-admin/internal/lib/api/response"
+	"srmt-admin/internal/lib/logger/sl"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
+	"srmt-admin/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type contextKey string
+
+const kindKey = contextKey("document_kind")
+
+type KindGetter interface {
+	GetDocumentKind(ctx context.Context, code string) (*document_kind.Model, error)
+}
+
+// FromURL resolves the document kind named by the {kind} URL parameter
+// against the catalogue. Unknown kinds get 404.
+func FromURL(log *slog.Logger, getter KindGetter) func(http.Handler) http.Handler {
+	return resolve(log, getter, func(r *http.Request) string { return chi.URLParam(r, "kind") })
+}
+
+// Fixed binds a route to one kind. The legacy /decrees, /reports, /letters
+// and /instructions routes use it to serve the generic document endpoints.
+func Fixed(log *slog.Logger, getter KindGetter, code string) func(http.Handler) http.Handler {
+	return resolve(log, getter, func(*http.Request) string { return code })
+}
+
+func resolve(log *slog.Logger, getter KindGetter, code func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			kind, err := getter.GetDocumentKind(r.Context(), code(r))
+			if err != nil {
+				if errors.Is(err, storage.ErrUnknownDocumentKind) {
+					render.Status(r, http.StatusNotFound)
+					render.JSON(w, r, resp.NotFound("Unknown document kind"))
+					return
+				}
+				log.Error("failed to resolve document kind", slog.String("kind", code(r)), sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.InternalServerError("Failed to resolve document kind"))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(ContextWithKind(r.Context(), *kind)))
+		})
+	}
+}
+
+// ContextWithKind stores kind in ctx.
+func ContextWithKind(ctx context.Context, kind document_kind.Model) context.Context {
+	return context.WithValue(ctx, kindKey, kind)
+}
+
+// KindFromContext returns the kind resolved for the request.
+func KindFromContext(ctx context.Context) (document_kind.Model, bool) {
+	kind, ok := ctx.Value(kindKey).(document_kind.Model)
+	return kind, ok
+}
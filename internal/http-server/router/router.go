@@ -22,7 +22,7 @@ import (
 	"srmt-admin/internal/http-server/handlers/dashboard/production"
 	productionstats "srmt-admin/internal/http-server/handlers/dashboard/production-stats"
 	"srmt-admin/internal/http-server/handlers/data/analytics"
-	"srmt-admin/internal/http-server/handlers/decrees"
+	runoffForecast "srmt-admin/internal/http-server/handlers/data/runoff-forecast"
 	departmentAdd "srmt-admin/internal/http-server/handlers/department/add"
 	departmentDelete "srmt-admin/internal/http-server/handlers/department/delete"
 	departmentEdit "srmt-admin/internal/http-server/handlers/department/edit"
@@ -36,11 +36,18 @@ import (
 	dischargeGetCurrent "srmt-admin/internal/http-server/handlers/discharge/get-current"
 	dischargeGetFlat "srmt-admin/internal/http-server/handlers/discharge/get-flat"
 	docstatuses "srmt-admin/internal/http-server/handlers/document-statuses"
+	"srmt-admin/internal/http-server/handlers/documents"
+	executioncontrol "srmt-admin/internal/http-server/handlers/execution-control"
+	registrationnumbering "srmt-admin/internal/http-server/handlers/registration-numbering"
+	fulltextsearch "srmt-admin/internal/http-server/handlers/full-text-search"
+	documenttemplates "srmt-admin/internal/http-server/handlers/document-templates"
 	filtrationLocations "srmt-admin/internal/http-server/handlers/filtration/locations"
 	filtrationMeasurements "srmt-admin/internal/http-server/handlers/filtration/measurements"
 	piezometerCounts "srmt-admin/internal/http-server/handlers/filtration/piezometer-counts"
 	filtrationPiezometers "srmt-admin/internal/http-server/handlers/filtration/piezometers"
 	filtrationComparison "srmt-admin/internal/http-server/handlers/filtration/comparison"
+	filtrationDamSafety "srmt-admin/internal/http-server/handlers/filtration/dam-safety"
+	filtrationSchedules "srmt-admin/internal/http-server/handlers/filtration/schedules"
 	filtrationSummary "srmt-admin/internal/http-server/handlers/filtration/summary"
 	manualComparison "srmt-admin/internal/http-server/handlers/manual-comparison"
 	eventAdd "srmt-admin/internal/http-server/handlers/events/add"
@@ -59,6 +66,9 @@ import (
 	catAdd "srmt-admin/internal/http-server/handlers/file/category/add"
 	catGet "srmt-admin/internal/http-server/handlers/file/category/list"
 	fileDelete "srmt-admin/internal/http-server/handlers/file/delete"
+	fileretention "srmt-admin/internal/http-server/handlers/file-retention"
+	notificationdeliveries "srmt-admin/internal/http-server/handlers/notification-deliveries"
+	resumableupload "srmt-admin/internal/http-server/handlers/resumable-upload"
 	"srmt-admin/internal/http-server/handlers/file/download"
 	getbycategory "srmt-admin/internal/http-server/handlers/file/get-by-category"
 	"srmt-admin/internal/http-server/handlers/file/latest"
@@ -73,9 +83,11 @@ import (
 	gesVisits "srmt-admin/internal/http-server/handlers/ges/visits"
 	hrmAccessHandler "srmt-admin/internal/http-server/handlers/hrm/access"
 	hrmAnalyticsHandler "srmt-admin/internal/http-server/handlers/hrm/analytics"
+	hrmCalendarHandler "srmt-admin/internal/http-server/handlers/hrm/calendar"
 	hrmCompetencyHandler "srmt-admin/internal/http-server/handlers/hrm/competency"
 	hrmDashboardHandler "srmt-admin/internal/http-server/handlers/hrm/dashboard"
 	hrmDocumentHandler "srmt-admin/internal/http-server/handlers/hrm/document"
+	hrmLifecycleHandler "srmt-admin/internal/http-server/handlers/hrm/lifecycle"
 	hrmOrgStructureHandler "srmt-admin/internal/http-server/handlers/hrm/orgstructure"
 	hrmPerformanceHandler "srmt-admin/internal/http-server/handlers/hrm/performance"
 	hrmPersonnelHandler "srmt-admin/internal/http-server/handlers/hrm/personnel"
@@ -85,13 +97,12 @@ import (
 	hrmTrainingHandler "srmt-admin/internal/http-server/handlers/hrm/training"
 	hrmVacationHandler "srmt-admin/internal/http-server/handlers/hrm/vacation"
 	dutyviolationshandler "srmt-admin/internal/http-server/handlers/duty-violations"
+	dutyrosterhandler "srmt-admin/internal/http-server/handlers/duty-roster"
 	incidentsHandler "srmt-admin/internal/http-server/handlers/incidents-handler"
 	setIndicator "srmt-admin/internal/http-server/handlers/indicators/set"
-	"srmt-admin/internal/http-server/handlers/instructions"
 	investActiveProjects "srmt-admin/internal/http-server/handlers/invest-active-projects"
 	"srmt-admin/internal/http-server/handlers/investments"
 	legaldocuments "srmt-admin/internal/http-server/handlers/legal-documents"
-	"srmt-admin/internal/http-server/handlers/letters"
 	levelVolumeGet "srmt-admin/internal/http-server/handlers/level-volume/get"
 	lexparser "srmt-admin/internal/http-server/handlers/lex-parser"
 	myCompetencies "srmt-admin/internal/http-server/handlers/my/competencies"
@@ -101,6 +112,7 @@ import (
 	myProfile "srmt-admin/internal/http-server/handlers/my/profile"
 	mySalary "srmt-admin/internal/http-server/handlers/my/salary"
 	myTasks "srmt-admin/internal/http-server/handlers/my/tasks"
+	myTelegram "srmt-admin/internal/http-server/handlers/my/telegram"
 	myTraining "srmt-admin/internal/http-server/handlers/my/training"
 	myVacations "srmt-admin/internal/http-server/handlers/my/vacations"
 	"srmt-admin/internal/http-server/handlers/news"
@@ -122,7 +134,6 @@ import (
 	receptionEdit "srmt-admin/internal/http-server/handlers/reception/edit"
 	receptionGetAll "srmt-admin/internal/http-server/handlers/reception/get-all"
 	receptionGetById "srmt-admin/internal/http-server/handlers/reception/get-by-id"
-	"srmt-admin/internal/http-server/handlers/reports"
 	reservoirdevicesummary "srmt-admin/internal/http-server/handlers/reservoir-device-summary"
 	reservoirfloodhandler "srmt-admin/internal/http-server/handlers/reservoir-flood"
 	reservoirsummary "srmt-admin/internal/http-server/handlers/reservoir-summary"
@@ -135,6 +146,7 @@ import (
 	gessummary "srmt-admin/internal/http-server/handlers/sc/callback/ges-summary"
 	callbackModsnow "srmt-admin/internal/http-server/handlers/sc/callback/modsnow"
 	callbackStock "srmt-admin/internal/http-server/handlers/sc/callback/stock"
+	scHistory "srmt-admin/internal/http-server/handlers/sc/history"
 	"srmt-admin/internal/http-server/handlers/sc/dc"
 	filterExport "srmt-admin/internal/http-server/handlers/filter/export"
 	scExport "srmt-admin/internal/http-server/handlers/sc/export"
@@ -147,6 +159,7 @@ import (
 	snowCoverGet "srmt-admin/internal/http-server/handlers/snow-cover/get"
 	solarhandler "srmt-admin/internal/http-server/handlers/solar"
 	"srmt-admin/internal/http-server/handlers/telegram/gidro/test"
+	telegramwebhook "srmt-admin/internal/http-server/handlers/telegram/webhook"
 	usersAdd "srmt-admin/internal/http-server/handlers/users/add"
 	assignRole "srmt-admin/internal/http-server/handlers/users/assign-role"
 	usersDelete "srmt-admin/internal/http-server/handlers/users/delete"
@@ -162,10 +175,25 @@ import (
 	mwapikey "srmt-admin/internal/http-server/middleware/api-key"
 	asutpauth "srmt-admin/internal/http-server/middleware/asutp-auth"
 	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	mwdockind "srmt-admin/internal/http-server/middleware/document-kind"
 	"srmt-admin/internal/http-server/middleware/devonly"
 	"srmt-admin/internal/lib/service/alarm"
+	"srmt-admin/internal/lib/service/damsafety"
+	runoffsvc "srmt-admin/internal/lib/service/runoff"
+	cascaderouting "srmt-admin/internal/lib/service/cascade-routing"
+	docworkflow "srmt-admin/internal/lib/service/document-workflow"
+	execcontrol "srmt-admin/internal/lib/service/execution-control"
+	regnumbering "srmt-admin/internal/lib/service/registration-numbering"
+	fts "srmt-admin/internal/lib/service/full-text-search"
+	doctemplates "srmt-admin/internal/lib/service/document-templates"
+	docintegrity "srmt-admin/internal/lib/service/document-integrity"
+	filelifecycle "srmt-admin/internal/lib/service/file-lifecycle"
+	uploadsession "srmt-admin/internal/lib/service/upload-session"
+	"srmt-admin/internal/lib/service/notification"
+	telegrambot "srmt-admin/internal/lib/service/telegram-bot"
 	dischargesvc "srmt-admin/internal/lib/service/discharge"
 	dutyviolationssvc "srmt-admin/internal/lib/service/dutyviolations"
+	dutyrostersvc "srmt-admin/internal/lib/service/dutyroster"
 	dischargeExcelGen "srmt-admin/internal/lib/service/excel/discharge"
 	excelgen "srmt-admin/internal/lib/service/excel/reservoir-summary"
 	reservoirHourlyExcelGen "srmt-admin/internal/lib/service/excel/reservoir-summary-hourly"
@@ -179,9 +207,11 @@ import (
 	"srmt-admin/internal/lib/service/excel/templates"
 	gesreportsvc "srmt-admin/internal/lib/service/ges-report"
 	hrmanalytics "srmt-admin/internal/lib/service/hrm/analytics"
+	hrmcalendar "srmt-admin/internal/lib/service/hrm/calendar"
 	hrmcompetency "srmt-admin/internal/lib/service/hrm/competency"
 	hrmdashboard "srmt-admin/internal/lib/service/hrm/dashboard"
 	hrmdocument "srmt-admin/internal/lib/service/hrm/document"
+	hrmlifecycle "srmt-admin/internal/lib/service/hrm/lifecycle"
 	hrmorgstructure "srmt-admin/internal/lib/service/hrm/orgstructure"
 	hrmperformance "srmt-admin/internal/lib/service/hrm/performance"
 	hrmpersonnel "srmt-admin/internal/lib/service/hrm/personnel"
@@ -227,8 +257,10 @@ type AppDependencies struct {
 	HRMVacationService         *hrmvacation.Service
 	HRMDashboardService        *hrmdashboard.Service
 	HRMTimesheetService        *hrmtimesheet.Service
+	HRMCalendarService         *hrmcalendar.Service
 	HRMSalaryService           *hrmsalary.Service
 	HRMRecruitingService       *hrmrecruiting.Service
+	HRMLifecycleService        *hrmlifecycle.Service
 	HRMTrainingService         *hrmtraining.Service
 	HRMDocumentService         *hrmdocument.Service
 	HRMAccessService           *hrmaccess.Service
@@ -240,7 +272,21 @@ type AppDependencies struct {
 	GESReportService           *gesreportsvc.Service
 	DischargeService           *dischargesvc.Service
 	DutyViolationsService      *dutyviolationssvc.Service
+	DutyRosterService          *dutyrostersvc.Service
 	SelService                 *selsvc.Service
+	DamSafetyService           *damsafety.Service
+	RunoffService              *runoffsvc.Service
+	CascadeRoutingService      *cascaderouting.Service
+	DocWorkflowService         *docworkflow.Service
+	ExecControlService         *execcontrol.Service
+	RegNumberingService        *regnumbering.Service
+	FullTextSearchService      *fts.Service
+	DocTemplateService         *doctemplates.Service
+	DocIntegrityService        *docintegrity.Service
+	FileLifecycleService       *filelifecycle.Service
+	UploadSessionService       *uploadsession.Service
+	NotificationService        *notification.Service
+	TelegramBot                *telegrambot.Service
 }
 
 func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
@@ -254,6 +300,11 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 	router.Post("/auth/refresh", refresh.New(deps.Log, deps.PgRepo, deps.Token))
 	router.Post("/auth/sign-out", signOut.New(deps.Log))
 
+	// Telegram bot updates in webhook mode; authenticated by the webhook secret
+	if deps.TelegramBot != nil {
+		router.Post("/telegram/webhook", telegramwebhook.New(deps.Log, deps.TelegramBot, deps.Config.Telegram.WebhookSecret))
+	}
+
 	// Debug routes — disabled in production (returns 404)
 	router.Route("/debug", func(r chi.Router) {
 		r.Use(devonly.Guard(deps.Config.Env))
@@ -296,6 +347,7 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 		r.Get("/modsnow/dynamics", modsnowImg.Get(deps.Log, deps.MinioRepo, "modsnow-dynamics"))
 
 		r.Get("/analytics", analytics.New(deps.Log, deps.PgRepo))
+		r.Get("/analytics/runoff-forecast", runoffForecast.New(deps.Log, deps.RunoffService, loc))
 		r.Get("/currency", currency.Get(deps.Log, deps.HTTPClient))
 
 		r.Route("/weather", func(r chi.Router) {
@@ -317,6 +369,16 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 		r.Post("/sc/ges/summary", gessummary.New(deps.Log, deps.PgRepo))
 	})
 
+	// Turnstile and door controllers
+	if key := deps.Config.AccessControl.APIKey; key != "" {
+		router.Group(func(r chi.Router) {
+			r.Use(mwapikey.RequireAPIKey(key))
+
+			r.Post("/access-control/events", hrmAccessHandler.IngestEvent(deps.Log, deps.HRMAccessService))
+			r.Post("/access-control/events/batch", hrmAccessHandler.IngestEvents(deps.Log, deps.HRMAccessService))
+		})
+	}
+
 	// Token required routes
 	router.Group(func(r chi.Router) {
 		r.Use(mwauth.Authenticator(deps.Token))
@@ -329,6 +391,7 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 			r.Patch("/", myProfile.Update(deps.Log, deps.PgRepo))
 		})
 		r.Get("/my-leave-balance", myLeaveBalance.Get(deps.Log, deps.PgRepo))
+		r.Get("/my-leave-balance/ledger", myLeaveBalance.GetLedger(deps.Log, deps.HRMVacationService))
 		r.Route("/my-vacations", func(r chi.Router) {
 			r.Get("/", myVacations.GetAll(deps.Log, deps.PgRepo))
 			r.Post("/", myVacations.Create(deps.Log, deps.HRMVacationService))
@@ -338,7 +401,16 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 			r.Get("/", myNotifications.GetAll(deps.Log, deps.PgRepo))
 			r.Patch("/{id}/read", myNotifications.MarkRead(deps.Log, deps.HRMDashboardService))
 			r.Post("/read-all", myNotifications.MarkReadAll(deps.Log, deps.HRMDashboardService))
+			r.Get("/preferences", myNotifications.GetPreferences(deps.Log, deps.NotificationService))
+			r.Put("/preferences", myNotifications.SetPreferences(deps.Log, deps.NotificationService))
 		})
+		if deps.TelegramBot != nil {
+			r.Route("/my-telegram", func(r chi.Router) {
+				r.Get("/", myTelegram.GetLink(deps.Log, deps.TelegramBot))
+				r.Post("/link-code", myTelegram.CreateLinkCode(deps.Log, deps.TelegramBot))
+				r.Delete("/", myTelegram.Unlink(deps.Log, deps.TelegramBot))
+			})
+		}
 		r.Get("/my-tasks", myTasks.GetAll(deps.Log, deps.PgRepo))
 		r.Route("/my-documents", func(r chi.Router) {
 			r.Get("/", myDocuments.GetAll(deps.Log, deps.PgRepo))
@@ -346,7 +418,12 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 		})
 		r.Route("/my-salary", func(r chi.Router) {
 			r.Get("/", mySalary.Get(deps.Log, deps.HRMSalaryService))
-			r.Get("/payslip/{id}", mySalary.GetPayslip(deps.Log))
+			r.Get("/payslip/{id}", mySalary.GetPayslip(deps.Log, deps.HRMSalaryService))
+		})
+		r.Route("/my-execution", func(r chi.Router) {
+			r.Get("/", executioncontrol.Mine(deps.Log, deps.ExecControlService))
+			r.Get("/{id}", executioncontrol.Get(deps.Log, deps.ExecControlService, deps.MinioRepo))
+			r.Post("/{id}/reports", executioncontrol.SubmitReport(deps.Log, deps.ExecControlService))
 		})
 		r.Get("/my-training", myTraining.Get(deps.Log, deps.HRMTrainingService))
 		r.Get("/my-competencies", myCompetencies.Get(deps.Log, deps.HRMCompetencyService))
@@ -388,12 +465,18 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 		// Snow cover (modsnow)
 		r.Get("/snow-cover", snowCoverGet.Get(deps.Log, deps.PgRepo))
 
+		// Situation-center callback history (stored reports with diff vs previous)
+		r.Get("/sc/stock/history", scHistory.Stock(deps.Log, deps.MongoRepo))
+		r.Get("/sc/modsnow/history", scHistory.Modsnow(deps.Log, deps.MongoRepo))
+		r.Get("/sc/ges/summary/history", scHistory.GesSummary(deps.Log, deps.PgRepo))
+
 		// Open routes (available to all authenticated users)
 		r.Get("/shutdowns", shutdowns.Get(deps.Log, deps.PgRepo, deps.MinioRepo, loc))
 		r.Get("/legal-documents", legaldocuments.GetAll(deps.Log, deps.PgRepo, deps.MinioRepo))
 		r.Get("/legal-documents/types", legaldocuments.GetTypes(deps.Log, deps.PgRepo))
 		r.Get("/legal-documents/{id}", legaldocuments.GetByID(deps.Log, deps.PgRepo, deps.MinioRepo))
 		r.Get("/lex-search", lexparser.Search(deps.Log, deps.HTTPClient, deps.Config.LexParser.BaseURL))
+		r.Get("/search", fulltextsearch.Search(deps.Log, deps.FullTextSearchService))
 
 		// GES (individual HPP view)
 		r.Get("/ges/{id}", gesGet.New(deps.Log, deps.PgRepo))
@@ -437,6 +520,10 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 			r.Post("/users/{userID}/roles", assignRole.New(deps.Log, deps.PgRepo))
 			r.Delete("/users/{userID}/roles/{roleID}", revokeRole.New(deps.Log, deps.PgRepo))
 			r.Put("/users/{userID}/organizations", usersOrganizations.New(deps.Log, deps.PgRepo))
+
+			// Notification delivery log
+			r.Get("/notifications/events", notificationdeliveries.Events(deps.NotificationService))
+			r.Get("/notifications/deliveries", notificationdeliveries.List(deps.Log, deps.NotificationService))
 		})
 
 		// SC endpoints
@@ -450,7 +537,7 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 			r.Post("/upload/stock", stock.Upload(deps.Log, deps.HTTPClient, deps.Config.Upload.Stock))
 			r.Post("/upload/modsnow", table.Upload(deps.Log, deps.HTTPClient, deps.Config.Upload.Modsnow))
 			r.Post("/upload/archive", modsnowImg.Upload(deps.Log, deps.HTTPClient, deps.Config.Upload.Archive))
-			r.Post("/upload/files", upload.New(deps.Log, deps.MinioRepo, deps.PgRepo, deps.Config.PrimeParser.URL, deps.Config.ApiKey))
+			r.Post("/upload/files", upload.New(deps.Log, deps.FileLifecycleService, deps.PgRepo, deps.Config.PrimeParser.URL, deps.Config.ApiKey))
 
 			// Reservoirs
 			r.Post("/reservoirs", resAdd.New(deps.Log, deps.PgRepo))
@@ -460,7 +547,14 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 			r.Post("/files/categories", catAdd.New(deps.Log, deps.PgRepo))
 
 			// Delete
-			r.Delete("/files/{fileID}", fileDelete.New(deps.Log, deps.PgRepo, deps.MinioRepo))
+			r.Delete("/files/{fileID}", fileDelete.New(deps.Log, deps.FileLifecycleService))
+
+			// Storage lifecycle: retention policies and cleanup
+			r.Get("/files/retention-policies", fileretention.ListPolicies(deps.Log, deps.FileLifecycleService))
+			r.Put("/files/retention-policies/{categoryID}", fileretention.SetPolicy(deps.Log, deps.FileLifecycleService))
+			r.Delete("/files/retention-policies/{categoryID}", fileretention.DeletePolicy(deps.Log, deps.FileLifecycleService))
+			r.Post("/files/cleanup", fileretention.Cleanup(deps.Log, deps.FileLifecycleService))
+			r.Get("/files/cleanup-runs", fileretention.Runs(deps.Log, deps.FileLifecycleService))
 		})
 
 		r.Group(func(r chi.Router) {
@@ -470,11 +564,19 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 			r.Get("/files/{fileID}/download", download.New(deps.Log, deps.PgRepo, deps.MinioRepo))
 			r.Get("/files", getbycategory.New(deps.Log, deps.PgRepo, deps.MinioRepo))
 
+			// Resumable uploads of large files (archives, incident video)
+			r.Post("/uploads", resumableupload.Create(deps.Log, deps.UploadSessionService))
+			r.Get("/uploads/{id}", resumableupload.Status(deps.Log, deps.UploadSessionService))
+			r.Head("/uploads/{id}", resumableupload.Status(deps.Log, deps.UploadSessionService))
+			r.Put("/uploads/{id}", resumableupload.WriteChunk(deps.Log, deps.UploadSessionService))
+			r.Post("/uploads/{id}/finish", resumableupload.Finish(deps.Log, deps.UploadSessionService))
+			r.Delete("/uploads/{id}", resumableupload.Abort(deps.Log, deps.UploadSessionService))
+
 			// Discharges (Сбросы)
 			r.Get("/discharges", dischargeGet.New(deps.Log, deps.PgRepo, deps.MinioRepo, loc))
 			r.Get("/discharges/current", dischargeGetCurrent.New(deps.Log, deps.PgRepo, deps.MinioRepo))
 			r.Get("/discharges/flat", dischargeGetFlat.New(deps.Log, deps.PgRepo, deps.MinioRepo, loc))
-			r.Post("/discharges", dischargeAdd.New(deps.Log, deps.PgRepo, deps.DischargeService, deps.PgRepo, loc))
+			r.Post("/discharges", dischargeAdd.New(deps.Log, deps.PgRepo, deps.DischargeService, deps.DischargeService, deps.PgRepo, loc))
 			r.Patch("/discharges/{id}", dischargePatch.New(deps.Log, deps.PgRepo, deps.PgRepo))
 			r.Delete("/discharges/{id}", dischargeDelete.New(deps.Log, deps.PgRepo, deps.PgRepo))
 			r.Get("/discharges/export", dischargeExport.New(
@@ -607,6 +709,33 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 			})
 		})
 
+		// Duty roster (графики дежурств): rotation patterns, monthly roster
+		// generation, swaps and the on-duty lookup.
+		r.Route("/duty-roster", func(r chi.Router) {
+			// Tier 1: stations read their roster and request swaps.
+			r.Group(func(r chi.Router) {
+				r.Use(mwauth.RequireAnyRole("sc", "rais", "reservoir", "reservoir_flood", "cascade"))
+				r.Get("/on-duty", dutyrosterhandler.GetOnDuty(deps.Log, deps.DutyRosterService))
+				r.Get("/shifts", dutyrosterhandler.GetRoster(deps.Log, deps.DutyRosterService, loc))
+				r.Get("/patterns", dutyrosterhandler.ListPatterns(deps.Log, deps.DutyRosterService))
+				r.Get("/patterns/{id}", dutyrosterhandler.GetPattern(deps.Log, deps.DutyRosterService))
+				r.Post("/shifts/{id}/swaps", dutyrosterhandler.RequestSwap(deps.Log, deps.DutyRosterService))
+				r.Get("/swaps", dutyrosterhandler.ListSwaps(deps.Log, deps.DutyRosterService))
+			})
+			// Tier 2: patterns, generation, manual shifts and swap decisions — sc/rais only.
+			r.Group(func(r chi.Router) {
+				r.Use(mwauth.RequireAnyRole("sc", "rais"))
+				r.Post("/patterns", dutyrosterhandler.CreatePattern(deps.Log, deps.DutyRosterService))
+				r.Put("/patterns/{id}", dutyrosterhandler.UpdatePattern(deps.Log, deps.DutyRosterService))
+				r.Delete("/patterns/{id}", dutyrosterhandler.DeletePattern(deps.Log, deps.DutyRosterService))
+				r.Post("/patterns/{id}/generate", dutyrosterhandler.Generate(deps.Log, deps.DutyRosterService))
+				r.Post("/shifts", dutyrosterhandler.CreateShift(deps.Log, deps.DutyRosterService))
+				r.Delete("/shifts/{id}", dutyrosterhandler.DeleteShift(deps.Log, deps.DutyRosterService))
+				r.Post("/swaps/{id}/approve", dutyrosterhandler.ApproveSwap(deps.Log, deps.DutyRosterService))
+				r.Post("/swaps/{id}/reject", dutyrosterhandler.RejectSwap(deps.Log, deps.DutyRosterService))
+			})
+		})
+
 		// Solar (daily generation + per-org config + monthly plans).
 		r.Route("/solar", func(r chi.Router) {
 			// Tier 1: read + write daily data + read config + read plans.
@@ -630,7 +759,7 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 		// (own org or its direct children). sc/rais keep full access.
 		r.Group(func(r chi.Router) {
 			r.Use(mwauth.RequireAnyRole("sc", "rais", "cascade"))
-			r.Post("/shutdowns", shutdowns.Add(deps.Log, deps.PgRepo, loc))
+			r.Post("/shutdowns", shutdowns.Add(deps.Log, deps.PgRepo, deps.NotificationService, loc))
 			r.Patch("/shutdowns/{id}", shutdowns.Edit(deps.Log, deps.PgRepo))
 			r.Delete("/shutdowns/{id}", shutdowns.Delete(deps.Log, deps.PgRepo))
 			r.Patch("/shutdowns/{id}/viewed", shutdowns.MarkViewed(deps.Log, deps.PgRepo))
@@ -671,6 +800,8 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 				r.Put("/frozen-defaults", gesreporthandler.UpsertFrozenDefault(deps.Log, deps.PgRepo))
 				r.Delete("/frozen-defaults", gesreporthandler.DeleteFrozenDefault(deps.Log, deps.PgRepo))
 				r.Get("/frozen-defaults", gesreporthandler.ListFrozenDefaults(deps.Log, deps.PgRepo))
+				r.Get("/cascade-topology", gesreporthandler.GetCascadeTopology(deps.Log, deps.CascadeRoutingService, deps.PgRepo))
+				r.Get("/cascade-routing", gesreporthandler.GetCascadeRouting(deps.Log, deps.CascadeRoutingService, deps.PgRepo, loc))
 			})
 
 			// Tier 2: sc/rais only — config write, plans write, export
@@ -683,6 +814,7 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 				r.Post("/plans", gesreporthandler.BulkUpsertPlan(deps.Log, deps.PgRepo))
 				r.Post("/cascade-config", gesreporthandler.UpsertCascadeConfig(deps.Log, deps.PgRepo))
 				r.Delete("/cascade-config", gesreporthandler.DeleteCascadeConfig(deps.Log, deps.PgRepo))
+				r.Put("/cascade-topology", gesreporthandler.ReplaceCascadeTopology(deps.Log, deps.CascadeRoutingService))
 			})
 		})
 
@@ -707,7 +839,7 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 			r.Post("/piezometer-counts", piezometerCounts.Upsert(deps.Log, deps.PgRepo))
 
 			// Measurements
-			r.Post("/measurements", filtrationMeasurements.Upsert(deps.Log, deps.PgRepo))
+			r.Post("/measurements", filtrationMeasurements.Upsert(deps.Log, deps.PgRepo, deps.DamSafetyService))
 			r.Get("/measurements", filtrationMeasurements.Get(deps.Log, deps.PgRepo, deps.PgRepo, deps.PgRepo))
 
 			// Summary
@@ -716,6 +848,18 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 			// Comparison
 			r.Get("/comparison/similar-dates", filtrationComparison.GetSimilarDates(deps.Log, deps.PgRepo))
 			r.Get("/comparison/data", filtrationComparison.GetData(deps.Log, deps.PgRepo))
+
+			// Dam-safety analytics (тренды, регрессия по уровню водохранилища, оповещения)
+			r.Get("/analytics/trend", filtrationDamSafety.GetTrend(deps.Log, deps.DamSafetyService))
+			r.Get("/alerts", filtrationDamSafety.GetAlerts(deps.Log, deps.PgRepo))
+			r.Post("/alerts/{id}/acknowledge", filtrationDamSafety.Acknowledge(deps.Log, deps.PgRepo))
+			r.Get("/dam-safety/dashboard", filtrationDamSafety.GetDashboard(deps.Log, deps.DamSafetyService, loc))
+
+			// Measurement schedules and missed readings
+			r.Get("/schedules", filtrationSchedules.Get(deps.Log, deps.PgRepo))
+			r.Post("/schedules", filtrationSchedules.Upsert(deps.Log, deps.PgRepo, deps.PgRepo))
+			r.Delete("/schedules/{id}", filtrationSchedules.Delete(deps.Log, deps.PgRepo))
+			r.Get("/missing", filtrationDamSafety.GetMissing(deps.Log, deps.DamSafetyService, loc))
 		})
 
 		// Manual Comparison (ручное сравнение фильтрации — без привязки к исторической дате)
@@ -781,70 +925,103 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 
 			// Document Statuses (shared reference)
 			r.Get("/document-statuses", docstatuses.GetAll(deps.Log, deps.PgRepo))
+			r.Get("/document-statuses/transitions", docstatuses.GetTransitions(deps.Log, deps.DocWorkflowService))
+			r.Group(func(r chi.Router) {
+				r.Use(mwauth.RequireAnyRole("rais"))
+				r.Put("/document-statuses/transitions/{type}", docstatuses.ReplaceTransitions(deps.Log, deps.DocWorkflowService))
+			})
+
+			// Document kinds catalogue (виды документов)
+			r.Get("/document-kinds", documents.GetKinds(deps.Log, deps.PgRepo))
+			r.Group(func(r chi.Router) {
+				r.Use(mwauth.RequireAnyRole("rais"))
+				r.Patch("/document-kinds/{code}", documents.EditKind(deps.Log, deps.PgRepo))
+			})
+
+			// Registration numbering (схемы нумерации и журнал регистрации)
+			r.Get("/numbering-schemes", registrationnumbering.ListSchemes(deps.Log, deps.RegNumberingService))
+			r.Get("/numbering-schemes/{id}/next", registrationnumbering.PreviewNext(deps.Log, deps.RegNumberingService))
+			r.Group(func(r chi.Router) {
+				r.Use(mwauth.RequireAnyRole("rais"))
+				r.Post("/numbering-schemes", registrationnumbering.CreateScheme(deps.Log, deps.RegNumberingService))
+				r.Patch("/numbering-schemes/{id}", registrationnumbering.EditScheme(deps.Log, deps.RegNumberingService))
+			})
+
+			// Full-text search index rebuild
+			r.Group(func(r chi.Router) {
+				r.Use(mwauth.RequireAnyRole("rais"))
+				r.Post("/search/reindex", fulltextsearch.Reindex(deps.Log, deps.FullTextSearchService))
+			})
+			r.Get("/registration-numbers", registrationnumbering.Journal(deps.Log, deps.RegNumberingService))
+			r.Get("/registration-numbers/export", registrationnumbering.ExportJournal(deps.Log, deps.RegNumberingService))
+			r.Post("/registration-numbers/reserve", registrationnumbering.Reserve(deps.Log, deps.RegNumberingService))
+			r.Post("/registration-numbers/{id}/cancel", registrationnumbering.Cancel(deps.Log, deps.RegNumberingService))
+
+			// Document templates (шаблоны DOCX по видам документов)
+			r.Get("/document-templates", documenttemplates.List(deps.Log, deps.DocTemplateService, deps.MinioRepo))
+			r.Get("/document-templates/placeholders", documenttemplates.Placeholders())
+			r.Group(func(r chi.Router) {
+				r.Use(mwauth.RequireAnyRole("rais"))
+				r.Post("/document-templates", documenttemplates.Create(deps.Log, deps.DocTemplateService, deps.MinioRepo))
+				r.Patch("/document-templates/{id}", documenttemplates.Edit(deps.Log, deps.DocTemplateService))
+			})
+
+			// Registry documents of one kind. The same routes serve
+			// /documents/{kind} and the legacy per-kind paths below.
+			documentRoutes := func(r chi.Router) {
+				r.Get("/", documents.GetAll(deps.Log, deps.PgRepo, deps.MinioRepo))
+				r.Get("/types", documents.GetTypes(deps.Log, deps.PgRepo))
+				r.Get("/{id}", documents.GetByID(deps.Log, deps.PgRepo, deps.MinioRepo))
+				r.Get("/{id}/history", documents.GetStatusHistory(deps.Log, deps.PgRepo))
+				r.Post("/", documents.Add(deps.Log, deps.PgRepo, deps.DocWorkflowService))
+				r.Patch("/{id}", documents.Edit(deps.Log, deps.PgRepo, deps.DocWorkflowService))
+				r.Patch("/{id}/status", documents.ChangeStatus(deps.Log, deps.DocWorkflowService))
+				r.Get("/{id}/transitions", docstatuses.GetAllowedNext(deps.Log, deps.DocWorkflowService))
+				r.Delete("/{id}", documents.Delete(deps.Log, deps.PgRepo))
+				r.Post("/{id}/generate", documents.Generate(deps.Log, deps.DocTemplateService, deps.MinioRepo))
+
+				// Document Signatures (Подписание документов)
+				r.Post("/{id}/sign", signatures.Sign(deps.Log, deps.PgRepo, deps.DocIntegrityService, deps.DocWorkflowService, deps.NotificationService))
+				r.Post("/{id}/reject-signature", signatures.Reject(deps.Log, deps.PgRepo, deps.DocWorkflowService))
+				r.Get("/{id}/signatures", signatures.GetSignatures(deps.Log, deps.PgRepo))
+				r.Get("/{id}/signatures/verify", signatures.Verify(deps.Log, deps.DocIntegrityService))
+				r.Post("/{id}/approval-route", signatures.CreateRoute(deps.Log, deps.PgRepo, deps.NotificationService))
+				r.Get("/{id}/approval-route", signatures.GetRoute(deps.Log, deps.PgRepo))
+				r.Delete("/{id}/approval-route", signatures.CancelRoute(deps.Log, deps.PgRepo))
+			}
 
-			// Decrees (Приказы)
-			r.Get("/decrees", decrees.GetAll(deps.Log, deps.PgRepo, deps.MinioRepo))
-			r.Get("/decrees/types", decrees.GetTypes(deps.Log, deps.PgRepo))
-			r.Get("/decrees/{id}", decrees.GetByID(deps.Log, deps.PgRepo, deps.MinioRepo))
-			r.Get("/decrees/{id}/history", decrees.GetStatusHistory(deps.Log, deps.PgRepo))
-			r.Post("/decrees", decrees.Add(deps.Log, deps.PgRepo))
-			r.Patch("/decrees/{id}", decrees.Edit(deps.Log, deps.PgRepo))
-			r.Patch("/decrees/{id}/status", decrees.ChangeStatus(deps.Log, deps.PgRepo))
-			r.Delete("/decrees/{id}", decrees.Delete(deps.Log, deps.PgRepo))
-
-			// Reports (Рапорты)
-			r.Get("/reports", reports.GetAll(deps.Log, deps.PgRepo, deps.MinioRepo))
-			r.Get("/reports/types", reports.GetTypes(deps.Log, deps.PgRepo))
-			r.Get("/reports/{id}", reports.GetByID(deps.Log, deps.PgRepo, deps.MinioRepo))
-			r.Get("/reports/{id}/history", reports.GetStatusHistory(deps.Log, deps.PgRepo))
-			r.Post("/reports", reports.Add(deps.Log, deps.PgRepo))
-			r.Patch("/reports/{id}", reports.Edit(deps.Log, deps.PgRepo))
-			r.Patch("/reports/{id}/status", reports.ChangeStatus(deps.Log, deps.PgRepo))
-			r.Delete("/reports/{id}", reports.Delete(deps.Log, deps.PgRepo))
-
-			// Letters (Письма)
-			r.Get("/letters", letters.GetAll(deps.Log, deps.PgRepo, deps.MinioRepo))
-			r.Get("/letters/types", letters.GetTypes(deps.Log, deps.PgRepo))
-			r.Get("/letters/{id}", letters.GetByID(deps.Log, deps.PgRepo, deps.MinioRepo))
-			r.Get("/letters/{id}/history", letters.GetStatusHistory(deps.Log, deps.PgRepo))
-			r.Post("/letters", letters.Add(deps.Log, deps.PgRepo))
-			r.Patch("/letters/{id}", letters.Edit(deps.Log, deps.PgRepo))
-			r.Patch("/letters/{id}/status", letters.ChangeStatus(deps.Log, deps.PgRepo))
-			r.Delete("/letters/{id}", letters.Delete(deps.Log, deps.PgRepo))
-
-			// Instructions (Инструкции)
-			r.Get("/instructions", instructions.GetAll(deps.Log, deps.PgRepo, deps.MinioRepo))
-			r.Get("/instructions/types", instructions.GetTypes(deps.Log, deps.PgRepo))
-			r.Get("/instructions/{id}", instructions.GetByID(deps.Log, deps.PgRepo, deps.MinioRepo))
-			r.Get("/instructions/{id}/history", instructions.GetStatusHistory(deps.Log, deps.PgRepo))
-			r.Post("/instructions", instructions.Add(deps.Log, deps.PgRepo))
-			r.Patch("/instructions/{id}", instructions.Edit(deps.Log, deps.PgRepo))
-			r.Patch("/instructions/{id}/status", instructions.ChangeStatus(deps.Log, deps.PgRepo))
-			r.Delete("/instructions/{id}", instructions.Delete(deps.Log, deps.PgRepo))
-
-			// Document Signatures (Подписание документов)
 			// Unified list of documents pending signature
 			r.Get("/documents/pending-signature", signatures.GetPending(deps.Log, deps.PgRepo))
 
-			// Decrees signatures
-			r.Post("/decrees/{id}/sign", signatures.Sign(deps.Log, deps.PgRepo, "decree"))
-			r.Post("/decrees/{id}/reject-signature", signatures.Reject(deps.Log, deps.PgRepo, "decree"))
-			r.Get("/decrees/{id}/signatures", signatures.GetSignatures(deps.Log, deps.PgRepo, "decree"))
-
-			// Reports signatures
-			r.Post("/reports/{id}/sign", signatures.Sign(deps.Log, deps.PgRepo, "report"))
-			r.Post("/reports/{id}/reject-signature", signatures.Reject(deps.Log, deps.PgRepo, "report"))
-			r.Get("/reports/{id}/signatures", signatures.GetSignatures(deps.Log, deps.PgRepo, "report"))
-
-			// Letters signatures
-			r.Post("/letters/{id}/sign", signatures.Sign(deps.Log, deps.PgRepo, "letter"))
-			r.Post("/letters/{id}/reject-signature", signatures.Reject(deps.Log, deps.PgRepo, "letter"))
-			r.Get("/letters/{id}/signatures", signatures.GetSignatures(deps.Log, deps.PgRepo, "letter"))
-
-			// Instructions signatures
-			r.Post("/instructions/{id}/sign", signatures.Sign(deps.Log, deps.PgRepo, "instruction"))
-			r.Post("/instructions/{id}/reject-signature", signatures.Reject(deps.Log, deps.PgRepo, "instruction"))
-			r.Get("/instructions/{id}/signatures", signatures.GetSignatures(deps.Log, deps.PgRepo, "instruction"))
+			r.Route("/documents/{kind}", func(r chi.Router) {
+				r.Use(mwdockind.FromURL(deps.Log, deps.PgRepo))
+				documentRoutes(r)
+			})
+
+			// Decrees (Приказы), Reports (Рапорты), Letters (Письма), Instructions (Инструкции)
+			for path, kind := range map[string]string{
+				"/decrees":      "decree",
+				"/reports":      "report",
+				"/letters":      "letter",
+				"/instructions": "instruction",
+			} {
+				r.Route(path, func(r chi.Router) {
+					r.Use(mwdockind.Fixed(deps.Log, deps.PgRepo, kind))
+					documentRoutes(r)
+				})
+			}
+
+			// Signature delegations while a signer is away
+			r.Get("/approval-delegations", signatures.GetDelegations(deps.Log, deps.PgRepo))
+			r.Post("/approval-delegations", signatures.CreateDelegation(deps.Log, deps.PgRepo))
+			r.Delete("/approval-delegations/{id}", signatures.DeleteDelegation(deps.Log, deps.PgRepo))
+
+			// Execution control of signed resolutions (Контроль исполнения)
+			r.Get("/execution-control", executioncontrol.List(deps.Log, deps.ExecControlService))
+			r.Get("/execution-control/due", executioncontrol.Due(deps.Log, deps.ExecControlService))
+			r.Get("/execution-control/dashboard", executioncontrol.Dashboard(deps.Log, deps.ExecControlService))
+			r.Get("/execution-control/{id}", executioncontrol.Get(deps.Log, deps.ExecControlService, deps.MinioRepo))
+			r.Post("/execution-control/{id}/review", executioncontrol.Review(deps.Log, deps.ExecControlService))
 		})
 
 		r.Group(func(r chi.Router) {
@@ -896,6 +1073,18 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 				r.Get("/vacations/balances", hrmVacationHandler.GetBalances(deps.Log, deps.HRMVacationService))
 				r.Get("/vacations/pending", hrmVacationHandler.GetPending(deps.Log, deps.HRMVacationService))
 				r.Get("/vacations/balance/{id}", hrmVacationHandler.GetBalance(deps.Log, deps.HRMVacationService))
+				r.Get("/vacations/ledger/{employeeId}", hrmVacationHandler.GetLedger(deps.Log, deps.HRMVacationService))
+				r.Get("/vacations/entitlements", hrmVacationHandler.GetEntitlementRules(deps.Log, deps.HRMVacationService))
+				r.Get("/vacations/policy", hrmVacationHandler.GetPolicy(deps.Log, deps.HRMVacationService))
+				r.Group(func(r chi.Router) {
+					r.Use(mwauth.RequireAnyRole("hrm_admin"))
+					r.Post("/vacations/ledger/{employeeId}/adjustments", hrmVacationHandler.CreateAdjustment(deps.Log, deps.HRMVacationService))
+					r.Post("/vacations/entitlements", hrmVacationHandler.CreateEntitlementRule(deps.Log, deps.HRMVacationService))
+					r.Delete("/vacations/entitlements/{id}", hrmVacationHandler.DeleteEntitlementRule(deps.Log, deps.HRMVacationService))
+					r.Put("/vacations/policy", hrmVacationHandler.UpdatePolicy(deps.Log, deps.HRMVacationService))
+					r.Post("/vacations/accruals", hrmVacationHandler.RunAccrual(deps.Log, deps.HRMVacationService))
+					r.Post("/vacations/closings/{year}", hrmVacationHandler.CloseYear(deps.Log, deps.HRMVacationService))
+				})
 				r.Get("/vacations", hrmVacationHandler.GetAll(deps.Log, deps.HRMVacationService))
 				r.Post("/vacations", hrmVacationHandler.Create(deps.Log, deps.HRMVacationService))
 				r.Get("/vacations/{id}", hrmVacationHandler.GetByID(deps.Log, deps.HRMVacationService))
@@ -912,6 +1101,7 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 				r.Post("/timesheet/corrections/{id}/approve", hrmTimesheetHandler.ApproveCorrection(deps.Log, deps.HRMTimesheetService))
 				r.Post("/timesheet/corrections/{id}/reject", hrmTimesheetHandler.RejectCorrection(deps.Log, deps.HRMTimesheetService))
 				r.Get("/timesheet/export", hrmTimesheetHandler.Export(deps.Log))
+				r.Post("/timesheet/generate", hrmTimesheetHandler.Generate(deps.Log, deps.HRMTimesheetService))
 				r.Patch("/timesheet/{id}", hrmTimesheetHandler.UpdateEntry(deps.Log, deps.HRMTimesheetService))
 
 				// Salaries — register specific routes BEFORE {id} routes
@@ -920,7 +1110,16 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 				r.Get("/salaries/structures", hrmSalaryHandler.GetAllStructures(deps.Log, deps.HRMSalaryService))
 				r.Get("/salaries/bonuses", hrmSalaryHandler.GetAllBonuses(deps.Log, deps.HRMSalaryService))
 				r.Get("/salaries/deductions", hrmSalaryHandler.GetAllDeductions(deps.Log, deps.HRMSalaryService))
-				r.Get("/salaries/export", hrmSalaryHandler.Export(deps.Log))
+				r.Get("/salaries/export", hrmSalaryHandler.Export(deps.Log, deps.HRMSalaryService))
+				r.Get("/salaries/rates", hrmSalaryHandler.GetRates(deps.Log, deps.HRMSalaryService))
+				r.Get("/salaries/bank-accounts", hrmSalaryHandler.GetBankAccounts(deps.Log, deps.HRMSalaryService))
+				r.Group(func(r chi.Router) {
+					r.Use(mwauth.RequireAnyRole("hrm_admin"))
+					r.Post("/salaries/rates", hrmSalaryHandler.CreateRate(deps.Log, deps.HRMSalaryService))
+					r.Delete("/salaries/rates/{id}", hrmSalaryHandler.DeleteRate(deps.Log, deps.HRMSalaryService))
+					r.Put("/salaries/bank-accounts/{employeeId}", hrmSalaryHandler.SetBankAccount(deps.Log, deps.HRMSalaryService))
+					r.Delete("/salaries/bank-accounts/{employeeId}", hrmSalaryHandler.DeleteBankAccount(deps.Log, deps.HRMSalaryService))
+				})
 				r.Get("/salaries", hrmSalaryHandler.GetAll(deps.Log, deps.HRMSalaryService))
 				r.Post("/salaries", hrmSalaryHandler.Create(deps.Log, deps.HRMSalaryService))
 				r.Get("/salaries/{id}", hrmSalaryHandler.GetByID(deps.Log, deps.HRMSalaryService))
@@ -931,11 +1130,24 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 				r.Post("/salaries/{id}/pay", hrmSalaryHandler.MarkPaid(deps.Log, deps.HRMSalaryService))
 				r.Get("/salaries/{id}/deductions", hrmSalaryHandler.GetDeductions(deps.Log, deps.HRMSalaryService))
 				r.Get("/salaries/{id}/bonuses", hrmSalaryHandler.GetBonuses(deps.Log, deps.HRMSalaryService))
+				r.Get("/salaries/{id}/payslip", hrmSalaryHandler.GetPayslip(deps.Log, deps.HRMSalaryService))
 
 				// Holidays
-				r.Get("/holidays", hrmTimesheetHandler.GetHolidays(deps.Log, deps.HRMTimesheetService))
-				r.Post("/holidays", hrmTimesheetHandler.CreateHoliday(deps.Log, deps.HRMTimesheetService))
-				r.Delete("/holidays/{id}", hrmTimesheetHandler.DeleteHoliday(deps.Log, deps.HRMTimesheetService))
+				r.Get("/holidays", hrmTimesheetHandler.GetHolidays(deps.Log, deps.HRMCalendarService))
+				r.Post("/holidays", hrmTimesheetHandler.CreateHoliday(deps.Log, deps.HRMCalendarService))
+				r.Delete("/holidays/{id}", hrmTimesheetHandler.DeleteHoliday(deps.Log, deps.HRMCalendarService))
+
+				// Production calendar — register specific routes BEFORE {year} routes
+				r.Get("/calendar/schedules", hrmCalendarHandler.GetSchedules(deps.Log, deps.HRMCalendarService))
+				r.Put("/calendar/schedules/{organizationId}", hrmCalendarHandler.SetSchedule(deps.Log, deps.HRMCalendarService))
+				r.Delete("/calendar/schedules/{organizationId}", hrmCalendarHandler.DeleteSchedule(deps.Log, deps.HRMCalendarService))
+				r.Get("/calendar/{year}", hrmCalendarHandler.GetYear(deps.Log, deps.HRMCalendarService))
+				r.Put("/calendar/{year}", hrmCalendarHandler.Publish(deps.Log, deps.HRMCalendarService))
+
+				// Absences (sick leave, business trips)
+				r.Get("/absences", hrmTimesheetHandler.GetAbsences(deps.Log, deps.HRMTimesheetService))
+				r.Post("/absences", hrmTimesheetHandler.CreateAbsence(deps.Log, deps.HRMTimesheetService))
+				r.Delete("/absences/{id}", hrmTimesheetHandler.DeleteAbsence(deps.Log, deps.HRMTimesheetService))
 
 				// Recruiting
 				r.Route("/recruiting", func(r chi.Router) {
@@ -962,14 +1174,32 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 					r.Post("/interviews", hrmRecruitingHandler.CreateInterview(deps.Log, deps.HRMRecruitingService))
 					r.Patch("/interviews/{id}", hrmRecruitingHandler.UpdateInterview(deps.Log, deps.HRMRecruitingService))
 
+					// Offers
+					r.Get("/offers", hrmRecruitingHandler.GetOffers(deps.Log, deps.HRMRecruitingService))
+					r.Post("/offers", hrmRecruitingHandler.CreateOffer(deps.Log, deps.HRMRecruitingService))
+					r.Patch("/offers/{id}", hrmRecruitingHandler.UpdateOffer(deps.Log, deps.HRMRecruitingService))
+
+					// Onboardings (created by the hire order)
+					r.Get("/onboardings", hrmRecruitingHandler.GetOnboardings(deps.Log, deps.HRMRecruitingService))
+					r.Patch("/onboarding-tasks/{id}", hrmRecruitingHandler.UpdateOnboardingTask(deps.Log, deps.HRMRecruitingService))
+
 					// Stubs (501)
-					r.Post("/offers", hrmRecruitingHandler.CreateOffer(deps.Log))
-					r.Patch("/offers/{id}", hrmRecruitingHandler.UpdateOffer(deps.Log))
-					r.Get("/onboardings", hrmRecruitingHandler.GetOnboardings(deps.Log))
-					r.Post("/onboardings", hrmRecruitingHandler.CreateOnboarding(deps.Log))
 					r.Get("/stats", hrmRecruitingHandler.GetStats(deps.Log))
 				})
 
+				// Employee lifecycle: hire, transfer and dismissal orders
+				r.Route("/lifecycle", func(r chi.Router) {
+					r.Get("/orders", hrmLifecycleHandler.GetOrders(deps.Log, deps.HRMLifecycleService))
+					r.Get("/orders/{id}", hrmLifecycleHandler.GetOrder(deps.Log, deps.HRMLifecycleService))
+					r.Group(func(r chi.Router) {
+						r.Use(mwauth.RequireAnyRole("hrm_admin"))
+						r.Post("/hire", hrmLifecycleHandler.Hire(deps.Log, deps.HRMLifecycleService))
+						r.Post("/transfers", hrmLifecycleHandler.Transfer(deps.Log, deps.HRMLifecycleService))
+						r.Post("/dismissals", hrmLifecycleHandler.Dismiss(deps.Log, deps.HRMLifecycleService))
+						r.Post("/orders/{id}/cancel", hrmLifecycleHandler.CancelOrder(deps.Log, deps.HRMLifecycleService))
+					})
+				})
+
 				// Training
 				r.Route("/training", func(r chi.Router) {
 					// Trainings CRUD
@@ -987,6 +1217,20 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 					// Employee trainings & certificates
 					r.Get("/employees/{id}/trainings", hrmTrainingHandler.GetEmployeeTrainings(deps.Log, deps.HRMTrainingService))
 					r.Get("/employees/{id}/certificates", hrmTrainingHandler.GetEmployeeCertificates(deps.Log, deps.HRMTrainingService))
+					r.Post("/certificates", hrmTrainingHandler.CreateCertificate(deps.Log, deps.HRMTrainingService))
+
+					// Certification compliance
+					r.Get("/certification-types", hrmTrainingHandler.GetCertificationTypes(deps.Log, deps.HRMTrainingService))
+					r.Get("/position-requirements", hrmTrainingHandler.GetPositionRequirements(deps.Log, deps.HRMTrainingService))
+					r.Get("/compliance", hrmTrainingHandler.GetCompliance(deps.Log, deps.HRMTrainingService))
+					r.Get("/compliance/suggestions", hrmTrainingHandler.GetEnrolmentSuggestions(deps.Log, deps.HRMTrainingService))
+					r.Group(func(r chi.Router) {
+						r.Use(mwauth.RequireAnyRole("hrm_admin"))
+						r.Post("/certification-types", hrmTrainingHandler.CreateCertificationType(deps.Log, deps.HRMTrainingService))
+						r.Patch("/certification-types/{id}", hrmTrainingHandler.UpdateCertificationType(deps.Log, deps.HRMTrainingService))
+						r.Delete("/certification-types/{id}", hrmTrainingHandler.DeleteCertificationType(deps.Log, deps.HRMTrainingService))
+						r.Put("/positions/{id}/certifications", hrmTrainingHandler.SetPositionCertifications(deps.Log, deps.HRMTrainingService))
+					})
 
 					// Development Plans
 					r.Get("/development-plans", hrmTrainingHandler.GetDevelopmentPlans(deps.Log, deps.HRMTrainingService))
@@ -1019,6 +1263,7 @@ func SetupRoutes(router *chi.Mux, deps *AppDependencies) {
 					r.Post("/zones", hrmAccessHandler.CreateZone(deps.Log, deps.HRMAccessService))
 					r.Patch("/zones/{id}", hrmAccessHandler.UpdateZone(deps.Log, deps.HRMAccessService))
 					r.Get("/logs", hrmAccessHandler.GetLogs(deps.Log, deps.HRMAccessService))
+					r.Get("/occupancy", hrmAccessHandler.GetOccupancy(deps.Log, deps.HRMAccessService))
 					r.Get("/requests", hrmAccessHandler.GetRequests(deps.Log, deps.HRMAccessService))
 					r.Post("/requests", hrmAccessHandler.CreateRequest(deps.Log, deps.HRMAccessService))
 					r.Post("/requests/{id}/approve", hrmAccessHandler.ApproveRequest(deps.Log, deps.HRMAccessService))
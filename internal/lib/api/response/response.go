@@ -126,3 +126,15 @@ func Conflict(msg string) Response {
 		Error:  msg,
 	}
 }
+
+// ConflictStructured returns a 409 with a machine-readable code and details,
+// for requests that are well-formed but clash with the resource's current
+// state (e.g. a status transition the workflow does not allow).
+func ConflictStructured(code, msg string, details []Detail) Response {
+	return Response{
+		Status:  http.StatusConflict,
+		Error:   msg,
+		Code:    code,
+		Details: details,
+	}
+}
@@ -2,8 +2,8 @@ package dto
 
 import "time"
 
-// GetAllDecreesFilters - Filters for querying decrees
-type GetAllDecreesFilters struct {
+// GetAllDocumentsFilters - Filters for querying documents of one kind
+type GetAllDocumentsFilters struct {
 	TypeID               *int       `json:"type_id,omitempty"`
 	StatusID             *int       `json:"status_id,omitempty"`
 	OrganizationID       *int64     `json:"organization_id,omitempty"`
@@ -17,8 +17,8 @@ type GetAllDecreesFilters struct {
 	NumberSearch         *string    `json:"number_search,omitempty"`
 }
 
-// AddDecreeRequest is the DTO for creating a decree
-type AddDecreeRequest struct {
+// AddDocumentRequest is the DTO for creating a document
+type AddDocumentRequest struct {
 	Name                 string                  `json:"name"`
 	Number               *string                 `json:"number,omitempty"`
 	DocumentDate         time.Time               `json:"document_date"`
@@ -32,11 +32,12 @@ type AddDecreeRequest struct {
 	ParentDocumentID     *int64                  `json:"parent_document_id,omitempty"`
 	FileIDs              []int64                 `json:"file_ids,omitempty"`
 	LinkedDocuments      []LinkedDocumentRequest `json:"linked_documents,omitempty"`
+	ReservationID        *int64                  `json:"reservation_id,omitempty"`
 }
 
-// EditDecreeRequest is the DTO for updating a decree
+// EditDocumentRequest is the DTO for updating a document
 // All fields are pointers (optional) - only provided fields will be updated
-type EditDecreeRequest struct {
+type EditDocumentRequest struct {
 	Name                 *string                 `json:"name,omitempty"`
 	Number               *string                 `json:"number,omitempty"`
 	DocumentDate         *time.Time              `json:"document_date,omitempty"`
@@ -59,9 +60,10 @@ type ChangeStatusRequest struct {
 	Comment  *string `json:"comment,omitempty"`
 }
 
-// LinkedDocumentRequest is the DTO for linking documents
+// LinkedDocumentRequest is the DTO for linking documents. The type is a
+// registered document kind or legal_document.
 type LinkedDocumentRequest struct {
-	LinkedDocumentType string  `json:"linked_document_type" validate:"required,oneof=decree report letter instruction legal_document"`
+	LinkedDocumentType string  `json:"linked_document_type" validate:"required"`
 	LinkedDocumentID   int64   `json:"linked_document_id" validate:"required,min=1"`
 	LinkDescription    *string `json:"link_description,omitempty"`
 }
@@ -1,6 +1,9 @@
 package dto
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 // --- Access Cards ---
 
@@ -60,6 +63,26 @@ type AccessLogFilters struct {
 	Status     *string
 	DateFrom   *string
 	DateTo     *string
+	AlertsOnly bool
+}
+
+// AccessEventInput — one controller event for POST /access-control/events.
+// The zone is ZoneID, or else the zone whose readers include ReaderID.
+type AccessEventInput struct {
+	ControllerID string    `json:"controller_id" validate:"required,max=100"`
+	EventID      *string   `json:"event_id,omitempty" validate:"omitempty,max=100"`
+	CardNumber   string    `json:"card_number" validate:"required,max=100"`
+	ZoneID       *int64    `json:"zone_id,omitempty"`
+	ReaderID     *int      `json:"reader_id,omitempty"`
+	Direction    string    `json:"direction" validate:"required,oneof=entry exit"`
+	Timestamp    time.Time `json:"timestamp" validate:"required"`
+	Status       string    `json:"status" validate:"required,oneof=granted denied error forced"`
+	DenialReason *string   `json:"denial_reason,omitempty"`
+}
+
+// IngestAccessEventsRequest — POST /access-control/events/batch
+type IngestAccessEventsRequest struct {
+	Events []AccessEventInput `json:"events" validate:"required,min=1,max=1000,dive"`
 }
 
 // --- Access Requests ---
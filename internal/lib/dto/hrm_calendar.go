@@ -0,0 +1,22 @@
+package dto
+
+// CalendarExceptionInput is one transferred or shortened day of a published year
+type CalendarExceptionInput struct {
+	Date     string  `json:"date" validate:"required,datetime=2006-01-02"`
+	Type     string  `json:"type" validate:"required,oneof=working day_off short"`
+	WeekDays *int    `json:"week_days,omitempty" validate:"omitempty,oneof=5 6"`
+	Note     *string `json:"note,omitempty"`
+}
+
+// PublishCalendarRequest — PUT /hrm/calendar/{year}
+// Replaces the holidays and exceptions of the year.
+type PublishCalendarRequest struct {
+	Holidays   []CreateHolidayRequest   `json:"holidays" validate:"dive"`
+	Exceptions []CalendarExceptionInput `json:"exceptions" validate:"dive"`
+}
+
+// SetWorkScheduleRequest — PUT /hrm/calendar/schedules/{organizationId}
+type SetWorkScheduleRequest struct {
+	WeekDays int     `json:"week_days" validate:"required,oneof=5 6"`
+	DayHours float64 `json:"day_hours" validate:"required,gt=0,lte=12"`
+}
@@ -0,0 +1,58 @@
+package dto
+
+// --- Lifecycle Orders ---
+
+// HireRequest — POST /hrm/lifecycle/hire. The hire date defaults to the
+// start date of the offer, the order date to today.
+type HireRequest struct {
+	OfferID         int64            `json:"offer_id" validate:"required"`
+	TabNumber       string           `json:"tab_number" validate:"required,max=50"`
+	ContractType    string           `json:"contract_type" validate:"required,oneof=permanent temporary contract"`
+	ContractEndDate *string          `json:"contract_end_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	HireDate        *string          `json:"hire_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	OrderDate       *string          `json:"order_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	MentorID        *int64           `json:"mentor_id,omitempty"`
+	Login           *string          `json:"login,omitempty" validate:"omitempty,min=1"`
+	Password        *string          `json:"password,omitempty" validate:"required_with=Login,omitempty,min=8"`
+	Reason          *string          `json:"reason,omitempty"`
+	Signatures      []SignatureInput `json:"signatures,omitempty" validate:"dive"`
+}
+
+// SalaryChangeInput — the salary structure from the transfer on
+type SalaryChangeInput struct {
+	BaseSalary          float64 `json:"base_salary" validate:"required,gt=0"`
+	RegionalAllowance   float64 `json:"regional_allowance" validate:"min=0"`
+	SeniorityAllowance  float64 `json:"seniority_allowance" validate:"min=0"`
+	QualificationAllow  float64 `json:"qualification_allowance" validate:"min=0"`
+	HazardAllowance     float64 `json:"hazard_allowance" validate:"min=0"`
+	NightShiftAllowance float64 `json:"night_shift_allowance" validate:"min=0"`
+}
+
+// TransferRequest — POST /hrm/lifecycle/transfers
+type TransferRequest struct {
+	EmployeeID     int64              `json:"employee_id" validate:"required"`
+	ToDepartmentID *int64             `json:"to_department_id,omitempty"`
+	ToPositionID   *int64             `json:"to_position_id,omitempty"`
+	Salary         *SalaryChangeInput `json:"salary,omitempty"`
+	EffectiveDate  string             `json:"effective_date" validate:"required,datetime=2006-01-02"`
+	OrderDate      *string            `json:"order_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Reason         *string            `json:"reason,omitempty"`
+	Signatures     []SignatureInput   `json:"signatures,omitempty" validate:"dive"`
+}
+
+// DismissalRequest — POST /hrm/lifecycle/dismissals. LastDay is the last
+// day of work; the dismissal takes effect the day after.
+type DismissalRequest struct {
+	EmployeeID int64            `json:"employee_id" validate:"required"`
+	LastDay    string           `json:"last_day" validate:"required,datetime=2006-01-02"`
+	OrderDate  *string          `json:"order_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Reason     string           `json:"reason" validate:"required"`
+	Signatures []SignatureInput `json:"signatures,omitempty" validate:"dive"`
+}
+
+type HROrderFilters struct {
+	EmployeeID *int64
+	Kind       *string
+	Status     *string
+	Year       *int
+}
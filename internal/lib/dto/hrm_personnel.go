@@ -8,6 +8,7 @@ type CreatePersonnelRecordRequest struct {
 	PositionID      int64   `json:"position_id" validate:"required"`
 	ContractType    string  `json:"contract_type" validate:"required,oneof=permanent temporary contract"`
 	ContractEndDate *string `json:"contract_end_date,omitempty"`
+	DismissalDate   *string `json:"dismissal_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
 	Status          string  `json:"status" validate:"required,oneof=active on_leave dismissed"`
 }
 
@@ -18,6 +19,7 @@ type EditPersonnelRecordRequest struct {
 	PositionID      *int64  `json:"position_id,omitempty"`
 	ContractType    *string `json:"contract_type,omitempty" validate:"omitempty,oneof=permanent temporary contract"`
 	ContractEndDate *string `json:"contract_end_date,omitempty"`
+	DismissalDate   *string `json:"dismissal_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
 	Status          *string `json:"status,omitempty" validate:"omitempty,oneof=active on_leave dismissed"`
 }
 
@@ -141,3 +141,38 @@ type InterviewFilters struct {
 	Status      *string
 	Type        *string
 }
+
+// --- Offers ---
+
+type CreateJobOfferRequest struct {
+	CandidateID   int64    `json:"candidate_id" validate:"required"`
+	SalaryOffered *float64 `json:"salary_offered,omitempty" validate:"omitempty,gt=0"`
+	StartDate     *string  `json:"start_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Notes         *string  `json:"notes,omitempty"`
+}
+
+type UpdateJobOfferRequest struct {
+	SalaryOffered *float64 `json:"salary_offered,omitempty" validate:"omitempty,gt=0"`
+	StartDate     *string  `json:"start_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Status        *string  `json:"status,omitempty" validate:"omitempty,oneof=draft sent accepted rejected expired withdrawn"`
+	Notes         *string  `json:"notes,omitempty"`
+}
+
+type JobOfferFilters struct {
+	CandidateID *int64
+	VacancyID   *int64
+	Status      *string
+}
+
+// --- Onboardings ---
+
+type OnboardingFilters struct {
+	EmployeeID *int64
+	Status     *string
+}
+
+type UpdateOnboardingTaskRequest struct {
+	Status     *string `json:"status,omitempty" validate:"omitempty,oneof=pending in_progress completed skipped"`
+	AssignedTo *int64  `json:"assigned_to,omitempty"`
+	DueDate    *string `json:"due_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+}
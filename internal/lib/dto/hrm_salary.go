@@ -19,6 +19,8 @@ type CalculateSalaryRequest struct {
 	WorkDays      int              `json:"work_days" validate:"required,min=1"`
 	ActualDays    int              `json:"actual_days" validate:"required,min=0"`
 	OvertimeHours float64          `json:"overtime_hours" validate:"min=0"`
+	NightHours    float64          `json:"night_hours" validate:"min=0"`
+	SickDays      int              `json:"sick_days" validate:"min=0"`
 	Bonuses       []BonusInput     `json:"bonuses,omitempty"`
 	Deductions    []DeductionInput `json:"deductions,omitempty"`
 }
@@ -52,3 +54,18 @@ type SalaryFilters struct {
 	DepartmentID *int64
 	Status       *string
 }
+
+// CreatePayrollRateRequest — POST /hrm/salaries/rates
+type CreatePayrollRateRequest struct {
+	Code          string  `json:"code" validate:"required,oneof=ndfl social pension health trade_union overtime night sick_pay"`
+	Rate          float64 `json:"rate" validate:"min=0,max=100"`
+	EffectiveFrom string  `json:"effective_from" validate:"required,datetime=2006-01-02"`
+	Note          *string `json:"note,omitempty"`
+}
+
+// SetBankAccountRequest — PUT /hrm/salaries/bank-accounts/:employeeId
+type SetBankAccountRequest struct {
+	BankName      string `json:"bank_name" validate:"required,max=255"`
+	BankCode      string `json:"bank_code" validate:"required,len=5,numeric"`
+	AccountNumber string `json:"account_number" validate:"required,len=20,numeric"`
+}
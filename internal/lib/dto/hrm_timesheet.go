@@ -21,6 +21,7 @@ type UpdateTimesheetEntryRequest struct {
 	CheckOut    *string  `json:"check_out,omitempty"`
 	HoursWorked *float64 `json:"hours_worked,omitempty"`
 	Overtime    *float64 `json:"overtime,omitempty"`
+	NightHours  *float64 `json:"night_hours,omitempty" validate:"omitempty,min=0,max=24"`
 	Note        *string  `json:"note,omitempty"`
 }
 
@@ -46,3 +47,28 @@ type CreateTimesheetCorrectionRequest struct {
 type RejectCorrectionRequest struct {
 	Reason string `json:"reason" validate:"required"`
 }
+
+// GenerateTimesheetRequest — POST /hrm/timesheet/generate
+type GenerateTimesheetRequest struct {
+	Year         int    `json:"year" validate:"required,min=2000"`
+	Month        int    `json:"month" validate:"required,min=1,max=12"`
+	DepartmentID *int64 `json:"department_id,omitempty"`
+	EmployeeID   *int64 `json:"employee_id,omitempty"`
+}
+
+// AbsenceFilters — query parameters for GET /hrm/absences
+type AbsenceFilters struct {
+	EmployeeID *int64
+	Type       *string
+	From       *string
+	To         *string
+}
+
+// CreateAbsenceRequest — POST /hrm/absences
+type CreateAbsenceRequest struct {
+	EmployeeID int64   `json:"employee_id" validate:"required"`
+	Type       string  `json:"type" validate:"required,oneof=sick_leave business_trip"`
+	StartDate  string  `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate    string  `json:"end_date" validate:"required,datetime=2006-01-02"`
+	Note       *string `json:"note,omitempty"`
+}
@@ -5,34 +5,36 @@ import "encoding/json"
 // --- Trainings ---
 
 type CreateTrainingRequest struct {
-	Title           string          `json:"title" validate:"required"`
-	Description     *string         `json:"description,omitempty"`
-	Type            string          `json:"type" validate:"required,oneof=internal external online workshop conference certification mentoring"`
-	Provider        *string         `json:"provider,omitempty"`
-	Trainer         *string         `json:"trainer,omitempty"`
-	StartDate       string          `json:"start_date" validate:"required"`
-	EndDate         string          `json:"end_date" validate:"required"`
-	Location        *string         `json:"location,omitempty"`
-	MaxParticipants *int            `json:"max_participants,omitempty"`
-	Cost            *float64        `json:"cost,omitempty"`
-	Mandatory       *bool           `json:"mandatory,omitempty"`
-	DepartmentIDs   json.RawMessage `json:"department_ids,omitempty"`
+	Title               string          `json:"title" validate:"required"`
+	Description         *string         `json:"description,omitempty"`
+	Type                string          `json:"type" validate:"required,oneof=internal external online workshop conference certification mentoring"`
+	Provider            *string         `json:"provider,omitempty"`
+	Trainer             *string         `json:"trainer,omitempty"`
+	StartDate           string          `json:"start_date" validate:"required"`
+	EndDate             string          `json:"end_date" validate:"required"`
+	Location            *string         `json:"location,omitempty"`
+	MaxParticipants     *int            `json:"max_participants,omitempty"`
+	Cost                *float64        `json:"cost,omitempty"`
+	Mandatory           *bool           `json:"mandatory,omitempty"`
+	DepartmentIDs       json.RawMessage `json:"department_ids,omitempty"`
+	CertificationTypeID *int64          `json:"certification_type_id,omitempty"`
 }
 
 type UpdateTrainingRequest struct {
-	Title           *string          `json:"title,omitempty"`
-	Description     *string          `json:"description,omitempty"`
-	Type            *string          `json:"type,omitempty" validate:"omitempty,oneof=internal external online workshop conference certification mentoring"`
-	Status          *string          `json:"status,omitempty" validate:"omitempty,oneof=planned registration_open in_progress completed cancelled"`
-	Provider        *string          `json:"provider,omitempty"`
-	Trainer         *string          `json:"trainer,omitempty"`
-	StartDate       *string          `json:"start_date,omitempty"`
-	EndDate         *string          `json:"end_date,omitempty"`
-	Location        *string          `json:"location,omitempty"`
-	MaxParticipants *int             `json:"max_participants,omitempty"`
-	Cost            *float64         `json:"cost,omitempty"`
-	Mandatory       *bool            `json:"mandatory,omitempty"`
-	DepartmentIDs   *json.RawMessage `json:"department_ids,omitempty"`
+	Title               *string          `json:"title,omitempty"`
+	Description         *string          `json:"description,omitempty"`
+	Type                *string          `json:"type,omitempty" validate:"omitempty,oneof=internal external online workshop conference certification mentoring"`
+	Status              *string          `json:"status,omitempty" validate:"omitempty,oneof=planned registration_open in_progress completed cancelled"`
+	Provider            *string          `json:"provider,omitempty"`
+	Trainer             *string          `json:"trainer,omitempty"`
+	StartDate           *string          `json:"start_date,omitempty"`
+	EndDate             *string          `json:"end_date,omitempty"`
+	Location            *string          `json:"location,omitempty"`
+	MaxParticipants     *int             `json:"max_participants,omitempty"`
+	Cost                *float64         `json:"cost,omitempty"`
+	Mandatory           *bool            `json:"mandatory,omitempty"`
+	DepartmentIDs       *json.RawMessage `json:"department_ids,omitempty"`
+	CertificationTypeID *int64           `json:"certification_type_id,omitempty"`
 }
 
 type TrainingFilters struct {
@@ -52,6 +54,49 @@ type CompleteParticipantRequest struct {
 	Notes *string `json:"notes,omitempty"`
 }
 
+// --- Certificates ---
+
+// CreateCertificateRequest — POST /hrm/training/certificates, for
+// certificates issued elsewhere. The title defaults to the name of the
+// certification type and the expiry date follows from its validity.
+type CreateCertificateRequest struct {
+	EmployeeID          int64   `json:"employee_id" validate:"required"`
+	CertificationTypeID *int64  `json:"certification_type_id,omitempty"`
+	TrainingID          *int64  `json:"training_id,omitempty"`
+	Title               string  `json:"title" validate:"required_without=CertificationTypeID,max=255"`
+	Issuer              *string `json:"issuer,omitempty"`
+	IssueDate           string  `json:"issue_date" validate:"required,datetime=2006-01-02"`
+	ExpiryDate          *string `json:"expiry_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	CertificateURL      *string `json:"certificate_url,omitempty"`
+}
+
+// --- Certification Compliance ---
+
+type CreateCertificationTypeRequest struct {
+	Name           string  `json:"name" validate:"required,max=255"`
+	Description    *string `json:"description,omitempty"`
+	ValidityMonths *int    `json:"validity_months,omitempty" validate:"omitempty,min=1"`
+	AlertDays      *int    `json:"alert_days,omitempty" validate:"omitempty,min=0"`
+}
+
+type UpdateCertificationTypeRequest struct {
+	Name           *string `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	Description    *string `json:"description,omitempty"`
+	ValidityMonths *int    `json:"validity_months,omitempty" validate:"omitempty,min=1"`
+	AlertDays      *int    `json:"alert_days,omitempty" validate:"omitempty,min=0"`
+}
+
+// SetPositionCertificationsRequest — PUT /hrm/training/positions/{id}/certifications
+// replaces the certifications the position requires
+type SetPositionCertificationsRequest struct {
+	CertificationTypeIDs []int64 `json:"certification_type_ids" validate:"required,dive,gt=0"`
+}
+
+type ComplianceFilters struct {
+	OrganizationID *int64
+	DepartmentID   *int64
+}
+
 // --- Development Plans ---
 
 type CreateDevelopmentPlanRequest struct {
@@ -34,3 +34,30 @@ type VacationCalendarFilters struct {
 	StartDate    *string
 	EndDate      *string
 }
+
+// CreateEntitlementRuleRequest — POST /hrm/vacations/entitlements
+type CreateEntitlementRuleRequest struct {
+	PositionID        *int64  `json:"position_id,omitempty"`
+	MinSeniorityYears int     `json:"min_seniority_years" validate:"min=0,max=60"`
+	AnnualDays        int     `json:"annual_days" validate:"min=0,max=366"`
+	Note              *string `json:"note,omitempty"`
+}
+
+// UpdateLeavePolicyRequest — PUT /hrm/vacations/policy
+type UpdateLeavePolicyRequest struct {
+	HazardDays   int `json:"hazard_days" validate:"min=0,max=366"`
+	CarryOverCap int `json:"carry_over_cap" validate:"min=0,max=366"`
+}
+
+// RunAccrualRequest — POST /hrm/vacations/accruals
+type RunAccrualRequest struct {
+	Year  int `json:"year" validate:"required,min=2000,max=2100"`
+	Month int `json:"month" validate:"required,min=1,max=12"`
+}
+
+// CreateLedgerAdjustmentRequest — POST /hrm/vacations/ledger/:employeeId/adjustments
+type CreateLedgerAdjustmentRequest struct {
+	Date string  `json:"date" validate:"required,datetime=2006-01-02"`
+	Days float64 `json:"days" validate:"required,min=-366,max=366"`
+	Note string  `json:"note" validate:"required"`
+}
@@ -1,10 +1,15 @@
 package dto
 
+import "srmt-admin/internal/lib/model/signature"
+
 // SignDocumentRequest is the request body for signing a document
 type SignDocumentRequest struct {
 	ResolutionText     *string `json:"resolution_text,omitempty"`
 	AssignedExecutorID *int64  `json:"assigned_executor_id,omitempty"`
 	AssignedDueDate    *string `json:"assigned_due_date,omitempty"` // YYYY-MM-DD format
+
+	// Seal is computed by the server before signing, never read from the body
+	Seal *signature.Seal `json:"-"`
 }
 
 // RejectSignatureRequest is the request body for rejecting a document signature
@@ -16,6 +21,9 @@ type RejectSignatureRequest struct {
 type SignatureResponse struct {
 	Status    string      `json:"status"`
 	NewStatus *StatusInfo `json:"new_status,omitempty"`
+	// AwaitingSignatures is set when the signature closed a step of an
+	// approval route that still has stages to go.
+	AwaitingSignatures bool `json:"awaiting_signatures,omitempty"`
 }
 
 // StatusInfo represents brief status information
@@ -0,0 +1,74 @@
+package document_kind
+
+import (
+	"slices"
+	"time"
+)
+
+// Fields a kind can require on creation, on top of name, document_date and
+// type_id, which every document has.
+const (
+	FieldNumber               = "number"
+	FieldDescription          = "description"
+	FieldResponsibleContactID = "responsible_contact_id"
+	FieldOrganizationID       = "organization_id"
+	FieldExecutorContactID    = "executor_contact_id"
+	FieldDueDate              = "due_date"
+	FieldParentDocumentID     = "parent_document_id"
+	FieldFileIDs              = "file_ids"
+)
+
+// LegalDocumentLinkType is the link type of legal documents. They can be
+// linked to registry documents but are not a kind of the registry.
+const LegalDocumentLinkType = "legal_document"
+
+// RequirableFields returns the fields a kind may list in RequiredFields.
+func RequirableFields() []string {
+	return []string{
+		FieldNumber, FieldDescription, FieldResponsibleContactID, FieldOrganizationID,
+		FieldExecutorContactID, FieldDueDate, FieldParentDocumentID, FieldFileIDs,
+	}
+}
+
+// IsRequirableField checks if field may be listed in RequiredFields
+func IsRequirableField(field string) bool {
+	return slices.Contains(RequirableFields(), field)
+}
+
+// Model is a registered document kind. Documents of the kind are stored in
+// TableName; its types, file links, document links and status history live
+// in the companion tables named after Code.
+type Model struct {
+	Code           string     `json:"code"`
+	Name           string     `json:"name"`
+	TableName      string     `json:"-"`
+	RequiredFields []string   `json:"required_fields"`
+	UniqueNumber   bool       `json:"unique_number"`
+	IsActive       bool       `json:"is_active"`
+	DisplayOrder   int        `json:"display_order"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+}
+
+// Requires reports whether field must be set when a document is created
+func (m Model) Requires(field string) bool {
+	return slices.Contains(m.RequiredFields, field)
+}
+
+func (m Model) TypeTable() string          { return m.Code + "_type" }
+func (m Model) FileLinksTable() string     { return m.Code + "_file_links" }
+func (m Model) DocumentLinksTable() string { return m.Code + "_document_links" }
+func (m Model) HistoryTable() string       { return m.Code + "_status_history" }
+
+// ForeignKey is the column referencing the document in the companion tables
+func (m Model) ForeignKey() string { return m.Code + "_id" }
+
+// EditRequest updates the catalogue entry of a kind. Omitted fields are left
+// unchanged; required_fields replaces the whole list when present.
+type EditRequest struct {
+	Name           *string  `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	RequiredFields []string `json:"required_fields,omitempty"`
+	UniqueNumber   *bool    `json:"unique_number,omitempty"`
+	IsActive       *bool    `json:"is_active,omitempty"`
+	DisplayOrder   *int     `json:"display_order,omitempty"`
+}
@@ -0,0 +1,28 @@
+package document_status
+
+// Transition is an allowed edge of a document type's status graph.
+// FromStatus is nil for the statuses a document may be created in.
+// An empty AllowedRoles list places no role restriction on the edge.
+type Transition struct {
+	ID                 int         `json:"id"`
+	DocumentType       string      `json:"document_type"`
+	FromStatus         *ShortModel `json:"from_status,omitempty"`
+	ToStatus           ShortModel  `json:"to_status"`
+	AllowedRoles       []string    `json:"allowed_roles"`
+	RequireComment     bool        `json:"require_comment"`
+	RequireAttachments bool        `json:"require_attachments"`
+}
+
+// TransitionInput is one edge of ReplaceTransitionsRequest.
+type TransitionInput struct {
+	FromStatusID       *int     `json:"from_status_id,omitempty" validate:"omitempty,min=1"`
+	ToStatusID         int      `json:"to_status_id" validate:"required,min=1"`
+	AllowedRoles       []string `json:"allowed_roles"`
+	RequireComment     bool     `json:"require_comment"`
+	RequireAttachments bool     `json:"require_attachments"`
+}
+
+// ReplaceTransitionsRequest replaces the whole graph of one document type.
+type ReplaceTransitionsRequest struct {
+	Transitions []TransitionInput `json:"transitions" validate:"required,min=1,dive"`
+}
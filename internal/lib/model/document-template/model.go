@@ -0,0 +1,120 @@
+package document_template
+
+import (
+	"time"
+
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/file"
+	"srmt-admin/internal/lib/model/user"
+)
+
+// Placeholders filled from the document. A template writes them as
+// {{name}}; Word may split one across runs, the filler handles that.
+const (
+	FieldName               = "name"
+	FieldNumber             = "number"
+	FieldDocumentDate       = "document_date"
+	FieldType               = "type"
+	FieldStatus             = "status"
+	FieldDescription        = "description"
+	FieldOrganization       = "organization"
+	FieldResponsibleContact = "responsible_contact"
+	FieldExecutor           = "executor"
+	FieldDueDate            = "due_date"
+	FieldResolution         = "resolution"
+	FieldSignedBy           = "signed_by"
+	FieldSignedAt           = "signed_at"
+	FieldToday              = "today"
+)
+
+// Placeholder describes a supported placeholder.
+type Placeholder struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Placeholders lists the supported placeholders.
+func Placeholders() []Placeholder {
+	return []Placeholder{
+		{FieldName, "Наименование документа"},
+		{FieldNumber, "Регистрационный номер"},
+		{FieldDocumentDate, "Дата документа (ДД.ММ.ГГГГ)"},
+		{FieldType, "Тип документа"},
+		{FieldStatus, "Статус документа"},
+		{FieldDescription, "Содержание"},
+		{FieldOrganization, "Организация"},
+		{FieldResponsibleContact, "Ответственный"},
+		{FieldExecutor, "Исполнитель"},
+		{FieldDueDate, "Срок исполнения (ДД.ММ.ГГГГ)"},
+		{FieldResolution, "Текст последней резолюции"},
+		{FieldSignedBy, "Подписавший последнюю резолюцию"},
+		{FieldSignedAt, "Дата подписания (ДД.ММ.ГГГГ)"},
+		{FieldToday, "Дата формирования (ДД.ММ.ГГГГ)"},
+	}
+}
+
+// IsPlaceholder reports whether name is a supported placeholder.
+func IsPlaceholder(name string) bool {
+	for _, p := range Placeholders() {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Model is a DOCX template of a document kind.
+type Model struct {
+	ID           int64             `json:"id"`
+	DocumentKind string            `json:"document_kind"`
+	Name         string            `json:"name"`
+	Description  *string           `json:"description,omitempty"`
+	FileID       int64             `json:"file_id"`
+	File         *dto.FileResponse `json:"file,omitempty"`
+	Source       file.Model        `json:"-"` // template file metadata, for download and File
+	Placeholders []string          `json:"placeholders"`
+	IsActive     bool              `json:"is_active"`
+	CreatedBy    *user.ShortInfo   `json:"created_by,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    *time.Time        `json:"updated_at,omitempty"`
+}
+
+// AddRequest registers an uploaded template file.
+type AddRequest struct {
+	DocumentKind string
+	Name         string
+	Description  *string
+	FileID       int64
+	Placeholders []string
+}
+
+// EditRequest updates a template. Omitted fields are left unchanged.
+type EditRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	Description *string `json:"description,omitempty"`
+	IsActive    *bool   `json:"is_active,omitempty"`
+}
+
+// Output formats of a generated document
+const (
+	FormatDOCX = "docx"
+	FormatPDF  = "pdf"
+)
+
+// GenerateRequest fills a template from a document. Attach defaults to true.
+type GenerateRequest struct {
+	TemplateID int64  `json:"template_id" validate:"required,min=1"`
+	Format     string `json:"format,omitempty" validate:"omitempty,oneof=docx pdf"`
+	Attach     *bool  `json:"attach,omitempty"`
+}
+
+// Generated is the file produced from a template.
+type Generated struct {
+	FileID   int64             `json:"file_id"`
+	FileName string            `json:"file_name"`
+	File     *dto.FileResponse `json:"file,omitempty"`
+	Stored   file.Model        `json:"-"` // metadata of the stored file, for File
+	Attached bool              `json:"attached"`
+	// Unfilled lists placeholders the document had no value for.
+	Unfilled []string `json:"unfilled,omitempty"`
+}
@@ -1,4 +1,4 @@
-package letter
+package document
 
 import (
 	"time"
@@ -6,10 +6,16 @@ import (
 	"srmt-admin/internal/lib/dto"
 	document_status "srmt-admin/internal/lib/model/document-status"
 	"srmt-admin/internal/lib/model/file"
-	letter_type "srmt-admin/internal/lib/model/letter-type"
 	"srmt-admin/internal/lib/model/user"
 )
 
+// Type represents a document type of one kind
+type Type struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
 // ContactShortInfo represents minimal contact information
 type ContactShortInfo struct {
 	ID   int64  `json:"id"`
@@ -22,8 +28,9 @@ type OrganizationShortInfo struct {
 	Name string `json:"name"`
 }
 
-// ParentLetterInfo represents minimal parent letter information
-type ParentLetterInfo struct {
+// ParentInfo represents minimal information about the parent document,
+// which is always of the same kind
+type ParentInfo struct {
 	ID           int64      `json:"id"`
 	Name         string     `json:"name"`
 	Number       *string    `json:"number,omitempty"`
@@ -40,40 +47,21 @@ type DocumentLink struct {
 	LinkDescription *string `json:"link_description,omitempty"`
 }
 
-// Model is the internal DB representation of a letter
-type Model struct {
-	ID                   int64      `json:"id"`
-	Name                 string     `json:"name"`
-	Number               *string    `json:"number,omitempty"`
-	DocumentDate         time.Time  `json:"document_date"`
-	Description          *string    `json:"description,omitempty"`
-	TypeID               int        `json:"type_id"`
-	StatusID             int        `json:"status_id"`
-	ResponsibleContactID *int64     `json:"responsible_contact_id,omitempty"`
-	OrganizationID       *int64     `json:"organization_id,omitempty"`
-	ExecutorContactID    *int64     `json:"executor_contact_id,omitempty"`
-	DueDate              *time.Time `json:"due_date,omitempty"`
-	ParentDocumentID     *int64     `json:"parent_document_id,omitempty"`
-	CreatedAt            time.Time  `json:"created_at"`
-	CreatedByUserID      *int64     `json:"created_by_user_id,omitempty"`
-	UpdatedAt            *time.Time `json:"updated_at,omitempty"`
-	UpdatedByUserID      *int64     `json:"updated_by_user_id,omitempty"`
-}
-
 // ResponseModel includes joined data and files
 type ResponseModel struct {
 	ID                 int64                      `json:"id"`
+	Kind               string                     `json:"kind"`
 	Name               string                     `json:"name"`
 	Number             *string                    `json:"number,omitempty"`
 	DocumentDate       time.Time                  `json:"document_date"`
 	Description        *string                    `json:"description,omitempty"`
-	Type               letter_type.Model          `json:"type"`
+	Type               Type                       `json:"type"`
 	Status             document_status.ShortModel `json:"status"`
 	ResponsibleContact *ContactShortInfo          `json:"responsible_contact,omitempty"`
 	Organization       *OrganizationShortInfo     `json:"organization,omitempty"`
 	ExecutorContact    *ContactShortInfo          `json:"executor_contact,omitempty"`
 	DueDate            *time.Time                 `json:"due_date,omitempty"`
-	ParentDocument     *ParentLetterInfo          `json:"parent_document,omitempty"`
+	ParentDocument     *ParentInfo                `json:"parent_document,omitempty"`
 	CreatedAt          time.Time                  `json:"created_at"`
 	CreatedBy          *user.ShortInfo            `json:"created_by,omitempty"`
 	UpdatedAt          *time.Time                 `json:"updated_at,omitempty"`
@@ -85,17 +73,18 @@ type ResponseModel struct {
 // ResponseWithURLs is the API response with presigned file URLs
 type ResponseWithURLs struct {
 	ID                 int64                      `json:"id"`
+	Kind               string                     `json:"kind"`
 	Name               string                     `json:"name"`
 	Number             *string                    `json:"number,omitempty"`
 	DocumentDate       time.Time                  `json:"document_date"`
 	Description        *string                    `json:"description,omitempty"`
-	Type               letter_type.Model          `json:"type"`
+	Type               Type                       `json:"type"`
 	Status             document_status.ShortModel `json:"status"`
 	ResponsibleContact *ContactShortInfo          `json:"responsible_contact,omitempty"`
 	Organization       *OrganizationShortInfo     `json:"organization,omitempty"`
 	ExecutorContact    *ContactShortInfo          `json:"executor_contact,omitempty"`
 	DueDate            *time.Time                 `json:"due_date,omitempty"`
-	ParentDocument     *ParentLetterInfo          `json:"parent_document,omitempty"`
+	ParentDocument     *ParentInfo                `json:"parent_document,omitempty"`
 	CreatedAt          time.Time                  `json:"created_at"`
 	CreatedBy          *user.ShortInfo            `json:"created_by,omitempty"`
 	UpdatedAt          *time.Time                 `json:"updated_at,omitempty"`
@@ -113,10 +102,3 @@ type StatusHistory struct {
 	ChangedBy *user.ShortInfo             `json:"changed_by,omitempty"`
 	Comment   *string                     `json:"comment,omitempty"`
 }
-
-// ShortInfo represents minimal letter information for references
-type ShortInfo struct {
-	ID     int64   `json:"id"`
-	Name   string  `json:"name"`
-	Number *string `json:"number,omitempty"`
-}
@@ -0,0 +1,163 @@
+// Package dutyroster defines the data model for the station shift roster
+// (график дежурств): rotation patterns, the generated monthly roster,
+// shift swaps and the "who is on duty now" view.
+package dutyroster
+
+import (
+	"time"
+)
+
+// OffDay marks a day off in a pattern cycle.
+const OffDay = "-"
+
+// Shift sources — how a duty_shifts row came to be.
+const (
+	SourceGenerated = "generated"
+	SourceManual    = "manual"
+	SourceSwap      = "swap"
+)
+
+// Swap statuses.
+const (
+	SwapPending  = "pending"
+	SwapApproved = "approved"
+	SwapRejected = "rejected"
+)
+
+// ShiftDef is one shift of a pattern, e.g. {"D", "08:00", 12}. Start is
+// local wall-clock time in the server location; a shift whose start plus
+// length passes midnight simply ends the next day.
+type ShiftDef struct {
+	Code  string `json:"code" validate:"required,max=10,ne=-"`
+	Start string `json:"start" validate:"required,datetime=15:04"`
+	Hours int    `json:"hours" validate:"required,gt=0,lte=24"`
+}
+
+// Member places an employee on a pattern. OffsetDays shifts the employee's
+// position in the cycle so crews of the same pattern do not coincide.
+type Member struct {
+	EmployeeID   int64  `json:"employee_id" validate:"required,gt=0"`
+	EmployeeName string `json:"employee_name,omitempty"`
+	OffsetDays   int    `json:"offset_days" validate:"gte=0"`
+}
+
+// Pattern is a per-organization rotation. Cycle lists one shift code per
+// day ("-" = off), counted from AnchorDate.
+type Pattern struct {
+	ID               int64      `json:"id"`
+	OrganizationID   int64      `json:"organization_id"`
+	OrganizationName string     `json:"organization_name,omitempty"`
+	Name             string     `json:"name"`
+	Shifts           []ShiftDef `json:"shifts"`
+	Cycle            []string   `json:"cycle"`
+	AnchorDate       string     `json:"anchor_date"`
+	Members          []Member   `json:"members"`
+	CreatedByUserID  *int64     `json:"created_by_user_id,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// PatternRequest is the POST/PUT body for a pattern. Members is a full
+// replacement of the current member list. OrganizationID is ignored on
+// PUT — a pattern does not move between organizations.
+type PatternRequest struct {
+	OrganizationID int64      `json:"organization_id" validate:"required,gt=0"`
+	Name           string     `json:"name" validate:"required,min=1,max=200"`
+	Shifts         []ShiftDef `json:"shifts" validate:"required,min=1,max=10,dive"`
+	Cycle          []string   `json:"cycle" validate:"required,min=1,max=62,dive,required,max=10"`
+	AnchorDate     string     `json:"anchor_date" validate:"required,datetime=2006-01-02"`
+	Members        []Member   `json:"members" validate:"omitempty,dive"`
+}
+
+// Shift is one rostered duty.
+type Shift struct {
+	ID                 int64     `json:"id"`
+	OrganizationID     int64     `json:"organization_id"`
+	OrganizationName   string    `json:"organization_name,omitempty"`
+	PatternID          *int64    `json:"pattern_id,omitempty"`
+	EmployeeID         int64     `json:"employee_id"`
+	EmployeeName       string    `json:"employee_name"`
+	RosteredEmployeeID *int64    `json:"rostered_employee_id,omitempty"`
+	ShiftCode          string    `json:"shift_code"`
+	StartsAt           time.Time `json:"starts_at"`
+	EndsAt             time.Time `json:"ends_at"`
+	Source             string    `json:"source"`
+	CreatedByUserID    *int64    `json:"created_by_user_id,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// CreateShiftRequest adds a one-off shift outside any pattern.
+type CreateShiftRequest struct {
+	OrganizationID int64     `json:"organization_id" validate:"required,gt=0"`
+	EmployeeID     int64     `json:"employee_id" validate:"required,gt=0"`
+	ShiftCode      string    `json:"shift_code" validate:"required,max=10"`
+	StartsAt       time.Time `json:"starts_at" validate:"required"`
+	EndsAt         time.Time `json:"ends_at" validate:"required,gtfield=StartsAt"`
+}
+
+// GenerateRequest selects the month to (re)generate from a pattern.
+type GenerateRequest struct {
+	Year  int `json:"year" validate:"required,gte=2000,lte=2100"`
+	Month int `json:"month" validate:"required,gte=1,lte=12"`
+}
+
+// GenerateResult reports what a generation run did. Kept counts the
+// pattern's shifts in the month that were left alone because they were
+// swapped or have a swap request open.
+type GenerateResult struct {
+	PatternID int64 `json:"pattern_id"`
+	Year      int   `json:"year"`
+	Month     int   `json:"month"`
+	Created   int   `json:"created"`
+	Removed   int   `json:"removed"`
+	Kept      int   `json:"kept"`
+}
+
+// Swap is a request to hand a shift over to another employee.
+type Swap struct {
+	ID                   int64      `json:"id"`
+	ShiftID              int64      `json:"shift_id"`
+	OrganizationID       int64      `json:"organization_id"`
+	ShiftCode            string     `json:"shift_code"`
+	StartsAt             time.Time  `json:"starts_at"`
+	EndsAt               time.Time  `json:"ends_at"`
+	OriginalEmployeeID   int64      `json:"original_employee_id"`
+	OriginalEmployeeName string     `json:"original_employee_name"`
+	ReplacementID        int64      `json:"replacement_id"`
+	ReplacementName      string     `json:"replacement_name"`
+	Reason               *string    `json:"reason,omitempty"`
+	Status               string     `json:"status"`
+	RequestedByUserID    *int64     `json:"requested_by_user_id,omitempty"`
+	DecidedByUserID      *int64     `json:"decided_by_user_id,omitempty"`
+	DecidedAt            *time.Time `json:"decided_at,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+}
+
+// SwapRequest is the POST body for requesting a swap.
+type SwapRequest struct {
+	ReplacementID int64   `json:"replacement_id" validate:"required,gt=0"`
+	Reason        *string `json:"reason" validate:"omitempty,max=1000"`
+}
+
+// RosterFilter narrows the roster listing. From/To is a half-open window
+// on starts_at.
+type RosterFilter struct {
+	OrganizationID int64
+	EmployeeID     *int64
+	From           time.Time
+	To             time.Time
+}
+
+// SwapFilter narrows the swap listing. Nil fields are not filtered on.
+type SwapFilter struct {
+	OrganizationID *int64
+	Status         *string
+}
+
+// OnDuty answers "who is on duty at station X at time T".
+type OnDuty struct {
+	OrganizationID int64     `json:"organization_id"`
+	At             time.Time `json:"at"`
+	Officers       []Shift   `json:"officers"`
+}
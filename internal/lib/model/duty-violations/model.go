@@ -20,6 +20,9 @@ type DutyViolation struct {
 	// EndTime is optional: a record may be created the moment a violation
 	// is noticed, with end_time filled in later via PATCH. Nil → "ongoing".
 	EndTime         *time.Time   `json:"end_time,omitempty"`
+	// DutyOfficerID links the record to the employee (contact) on duty;
+	// nil for records that only carry a free-text name.
+	DutyOfficerID   *int64       `json:"duty_officer_id,omitempty"`
 	DutyOfficerName string       `json:"duty_officer_name"`
 	Reason          string       `json:"reason"`
 	Files           []file.Model `json:"files"`
@@ -39,7 +42,11 @@ type CreateRequest struct {
 	// strictly after StartTime; omitempty short-circuits the gtfield
 	// check when the pointer is nil.
 	EndTime         *time.Time `json:"end_time" validate:"omitempty,gtfield=StartTime"`
-	DutyOfficerName string     `json:"duty_officer_name" validate:"required,min=1,max=200"`
+	// DutyOfficerID picks the employee on duty and supplies the name.
+	// Without it DutyOfficerName is required, and the service links the
+	// record to the rostered officer when the name matches.
+	DutyOfficerID   *int64     `json:"duty_officer_id" validate:"omitempty,gt=0"`
+	DutyOfficerName string     `json:"duty_officer_name" validate:"required_without=DutyOfficerID,max=200"`
 	Reason          string     `json:"reason" validate:"required,min=1,max=2000"`
 	FileIDs         []int64    `json:"file_ids" validate:"omitempty,dive,gt=0"`
 }
@@ -53,7 +60,11 @@ type UpdateRequest struct {
 	// EndTime: same semantics as CreateRequest — optional, validated only
 	// when present. To clear a previously-set end_time, send null.
 	EndTime         *time.Time `json:"end_time" validate:"omitempty,gtfield=StartTime"`
-	DutyOfficerName string     `json:"duty_officer_name" validate:"required,min=1,max=200"`
+	// DutyOfficerID picks the employee on duty and supplies the name.
+	// Without it DutyOfficerName is required, and the service links the
+	// record to the rostered officer when the name matches.
+	DutyOfficerID   *int64     `json:"duty_officer_id" validate:"omitempty,gt=0"`
+	DutyOfficerName string     `json:"duty_officer_name" validate:"required_without=DutyOfficerID,max=200"`
 	Reason          string     `json:"reason" validate:"required,min=1,max=2000"`
 	FileIDs         []int64    `json:"file_ids" validate:"omitempty,dive,gt=0"`
 }
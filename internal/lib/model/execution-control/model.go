@@ -0,0 +1,120 @@
+package execution_control
+
+import (
+	"time"
+
+	"srmt-admin/internal/lib/model/file"
+	"srmt-admin/internal/lib/model/user"
+)
+
+// Control item statuses
+const (
+	StatusOnControl = "on_control" // assigned, executor working on it
+	StatusSubmitted = "submitted"  // completion reported, awaiting the controller
+	StatusReturned  = "returned"   // completion returned for rework
+	StatusAccepted  = "accepted"   // work accepted, item closed
+)
+
+// Report kinds
+const (
+	ReportProgress   = "progress"
+	ReportCompletion = "completion"
+)
+
+// Controller decisions on a completion report
+const (
+	DecisionAccepted = "accepted"
+	DecisionReturned = "returned"
+)
+
+// Dashboard groupings
+const (
+	GroupByExecutor   = "executor"
+	GroupByDepartment = "department"
+)
+
+type Executor struct {
+	ID             int64   `json:"id"`
+	Name           string  `json:"name"`
+	DepartmentID   *int64  `json:"department_id,omitempty"`
+	DepartmentName *string `json:"department_name,omitempty"`
+}
+
+// Control is the execution control of one signed document. DaysLeft and
+// Overdue are computed against the request date; they are nil/false for
+// items without a due date and for accepted items.
+type Control struct {
+	ID                  int64           `json:"id"`
+	DocumentType        string          `json:"document_type"`
+	DocumentID          int64           `json:"document_id"`
+	DocumentName        string          `json:"document_name"`
+	DocumentNumber      *string         `json:"document_number,omitempty"`
+	Executor            Executor        `json:"executor"`
+	Controller          *user.ShortInfo `json:"controller,omitempty"`
+	ControllerContactID *int64          `json:"-"`
+	ResolutionText      *string         `json:"resolution_text,omitempty"`
+	DueDate             *time.Time      `json:"due_date,omitempty"`
+	Status              string          `json:"status"`
+	AcceptedAt          *time.Time      `json:"accepted_at,omitempty"`
+	CreatedAt           time.Time       `json:"created_at"`
+	DaysLeft            *int            `json:"days_left,omitempty"`
+	Overdue             bool            `json:"overdue"`
+	Reports             []Report        `json:"reports,omitempty"`
+}
+
+type Report struct {
+	ID              int64           `json:"id"`
+	Kind            string          `json:"kind"`
+	Text            string          `json:"text"`
+	SubmittedBy     *user.ShortInfo `json:"submitted_by,omitempty"`
+	SubmittedAt     time.Time       `json:"submitted_at"`
+	Decision        *string         `json:"decision,omitempty"`
+	DecisionComment *string         `json:"decision_comment,omitempty"`
+	DecidedBy       *user.ShortInfo `json:"decided_by,omitempty"`
+	DecidedAt       *time.Time      `json:"decided_at,omitempty"`
+	Files           []file.Model    `json:"-"`
+}
+
+type Filter struct {
+	Status            *string
+	DocumentType      *string
+	ExecutorContactID *int64
+	DepartmentID      *int64
+	// OpenOnly excludes accepted items.
+	OpenOnly bool
+	// DueBefore keeps items due on or before the date (YYYY-MM-DD).
+	DueBefore *string
+}
+
+type SubmitReportRequest struct {
+	Kind    string  `json:"kind" validate:"required,oneof=progress completion"`
+	Text    string  `json:"text" validate:"required"`
+	FileIDs []int64 `json:"file_ids,omitempty" validate:"omitempty,dive,min=1"`
+}
+
+// ReviewRequest accepts or returns a completion. Returning requires a
+// comment and may move the due date.
+type ReviewRequest struct {
+	Decision   string  `json:"decision" validate:"required,oneof=accepted returned"`
+	Comment    *string `json:"comment,omitempty"`
+	NewDueDate *string `json:"new_due_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// DashboardRow counts the open items of one executor or department.
+type DashboardRow struct {
+	ID        *int64 `json:"id"`
+	Name      string `json:"name"`
+	OnControl int    `json:"on_control"`
+	Submitted int    `json:"submitted"`
+	Returned  int    `json:"returned"`
+	DueSoon   int    `json:"due_soon"`
+	Overdue   int    `json:"overdue"`
+}
+
+type Dashboard struct {
+	GroupBy  string         `json:"group_by"`
+	Date     string         `json:"date"`
+	SoonDays int            `json:"soon_days"`
+	Rows     []DashboardRow `json:"rows"`
+	Total    DashboardRow   `json:"total"`
+}
@@ -0,0 +1,64 @@
+package file
+
+import "time"
+
+// RetentionPolicy says how long files of a category are kept. Files older
+// than RetentionDays are purged; with PurgeOrphans, files nothing refers to
+// are purged once OrphanGraceHours have passed since upload.
+type RetentionPolicy struct {
+	CategoryID       int64      `json:"category_id"`
+	CategoryName     string     `json:"category_name"`
+	RetentionDays    *int       `json:"retention_days"`
+	PurgeOrphans     bool       `json:"purge_orphans"`
+	OrphanGraceHours int        `json:"orphan_grace_hours"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+}
+
+// RetentionPolicyRequest sets the policy of a category, replacing it whole.
+type RetentionPolicyRequest struct {
+	RetentionDays    *int `json:"retention_days" validate:"omitempty,min=1"`
+	PurgeOrphans     bool `json:"purge_orphans"`
+	OrphanGraceHours *int `json:"orphan_grace_hours,omitempty" validate:"omitempty,min=1"`
+}
+
+// Reasons a file is purged
+const (
+	PurgeExpired = "expired" // older than the retention period of its category
+	PurgeOrphan  = "orphan"  // nothing refers to it
+)
+
+// PurgeCandidate is a file a cleanup run removes.
+type PurgeCandidate struct {
+	Model
+	Reason string
+}
+
+// CleanupCategory is the part of a cleanup run in one category.
+type CleanupCategory struct {
+	CategoryID     int64  `json:"category_id"`
+	CategoryName   string `json:"category_name"`
+	ExpiredFiles   int    `json:"expired_files"`
+	OrphanFiles    int    `json:"orphan_files"`
+	HeldFiles      int    `json:"held_files"`
+	ReclaimedBytes int64  `json:"reclaimed_bytes"`
+}
+
+// CleanupRun is the report of a storage cleanup. A dry run lists what would
+// be removed without removing it. Held files were due but are still needed,
+// e.g. attachments of a signed document; reclaimed bytes count only objects
+// actually removed from storage, not rows that shared a deduplicated object.
+type CleanupRun struct {
+	ID             int64             `json:"id"`
+	DryRun         bool              `json:"dry_run"`
+	TriggeredBy    *int64            `json:"triggered_by,omitempty"` // nil for the scheduled run
+	StartedAt      time.Time         `json:"started_at"`
+	FinishedAt     time.Time         `json:"finished_at"`
+	ExpiredFiles   int               `json:"expired_files"`
+	OrphanFiles    int               `json:"orphan_files"`
+	OrphanObjects  int               `json:"orphan_objects"` // objects in storage without a files row
+	HeldFiles      int               `json:"held_files"`
+	ReclaimedBytes int64             `json:"reclaimed_bytes"`
+	Categories     []CleanupCategory `json:"categories"`
+	Errors         []string          `json:"errors"`
+}
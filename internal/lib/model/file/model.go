@@ -11,4 +11,7 @@ type Model struct {
 	SizeBytes  int64     `json:"size_bytes"`
 	CreatedAt  time.Time `json:"created_at"`
 	TargetDate time.Time `json:"target_date"`
+	// ContentSHA256 is the hex SHA-256 of the content, empty for files
+	// stored before uploads were deduplicated.
+	ContentSHA256 string `json:"-"`
 }
@@ -0,0 +1,45 @@
+package file
+
+import "time"
+
+// UploadPart is a chunk of a resumable upload stored as a multipart part.
+type UploadPart struct {
+	Number int    `json:"number"`
+	ETag   string `json:"etag"`
+	Size   int64  `json:"size"`
+}
+
+// UploadSession is a resumable upload. Offset is how many bytes have been
+// accepted; the next chunk must start there.
+type UploadSession struct {
+	ID          string       `json:"id"`
+	ObjectKey   string       `json:"-"`
+	MultipartID string       `json:"-"`
+	FileName    string       `json:"file_name"`
+	ContentType string       `json:"content_type"`
+	Size        int64        `json:"size"`
+	Offset      int64        `json:"offset"`
+	Parts       []UploadPart `json:"-"`
+	CategoryID  int64        `json:"category_id"`
+	TargetDate  time.Time    `json:"target_date"`
+	CreatedBy   int64        `json:"-"`
+	AssembledAt *time.Time   `json:"-"`
+	FileID      *int64       `json:"file_id,omitempty"` // set once the upload is finished
+	CreatedAt   time.Time    `json:"created_at"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+}
+
+// Complete reports whether every byte has been received.
+func (s *UploadSession) Complete() bool {
+	return s.Offset == s.Size
+}
+
+// CreateUploadRequest starts a resumable upload. TargetDate is YYYY-MM-DD and
+// defaults to today, CategoryID to the "other" category.
+type CreateUploadRequest struct {
+	FileName    string  `json:"file_name" validate:"required,max=255"`
+	ContentType string  `json:"content_type" validate:"omitempty,max=255"`
+	Size        int64   `json:"size" validate:"required,min=1"`
+	CategoryID  *int64  `json:"category_id,omitempty"`
+	TargetDate  *string `json:"date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+}
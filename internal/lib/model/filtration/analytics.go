@@ -0,0 +1,112 @@
+package filtration
+
+import (
+	"time"
+
+	"srmt-admin/internal/lib/stats"
+)
+
+// --- Dam-safety analytics ---
+
+// Instrument types accepted by the analytics endpoints and stored in
+// filtration_alerts.instrument_type.
+const (
+	InstrumentLocation   = "location"
+	InstrumentPiezometer = "piezometer"
+)
+
+// Alert reasons. A single reading may raise both.
+const (
+	AlertReasonNormExceeded = "norm_exceeded"
+	AlertReasonOutOfBand    = "out_of_band"
+)
+
+// TrendPoint is one reading in an instrument time series. Value is the
+// flow rate (locations) or piezometric level (piezometers); ReservoirLevel
+// is the upstream level for the same date from reservoir_data.
+type TrendPoint struct {
+	Date           string   `json:"date"`
+	Value          *float64 `json:"value"`
+	ReservoirLevel *float64 `json:"reservoir_level"`
+	MovingAvg      *float64 `json:"moving_avg"`
+	Expected       *float64 `json:"expected"`
+	BandLow        *float64 `json:"band_low"`
+	BandHigh       *float64 `json:"band_high"`
+	Flags          []string `json:"flags"`
+}
+
+// Regression is the least-squares fit value = Intercept + Slope * level.
+// ResidualStd is the standard deviation of residuals and defines the width
+// of the expected band. Nil on InstrumentTrend when there are too few
+// paired readings to fit.
+type Regression = stats.Regression
+
+// InstrumentTrend is the response of GET /filtration/analytics/trend.
+type InstrumentTrend struct {
+	OrganizationID int64        `json:"organization_id"`
+	InstrumentType string       `json:"instrument_type"`
+	InstrumentID   int64        `json:"instrument_id"`
+	InstrumentName string       `json:"instrument_name"`
+	Norm           *float64     `json:"norm"`
+	Window         int          `json:"window"`
+	Regression     *Regression  `json:"regression"`
+	Points         []TrendPoint `json:"points"`
+}
+
+// SeriesRow is a raw (date, value, reservoir level) triple as read from the
+// measurement tables joined with reservoir_data.
+type SeriesRow struct {
+	Date           string
+	Value          *float64
+	ReservoirLevel *float64
+}
+
+// Alert is a flagged reading persisted in filtration_alerts.
+type Alert struct {
+	ID                   int64      `json:"id"`
+	OrganizationID       int64      `json:"organization_id"`
+	OrganizationName     string     `json:"organization_name,omitempty"`
+	InstrumentType       string     `json:"instrument_type"`
+	InstrumentID         int64      `json:"instrument_id"`
+	InstrumentName       string     `json:"instrument_name"`
+	Date                 string     `json:"date"`
+	Reason               string     `json:"reason"`
+	Value                float64    `json:"value"`
+	ReservoirLevel       *float64   `json:"reservoir_level"`
+	Norm                 *float64   `json:"norm"`
+	Expected             *float64   `json:"expected"`
+	BandLow              *float64   `json:"band_low"`
+	BandHigh             *float64   `json:"band_high"`
+	CreatedAt            time.Time  `json:"created_at"`
+	AcknowledgedAt       *time.Time `json:"acknowledged_at"`
+	AcknowledgedByUserID *int64     `json:"acknowledged_by_user_id"`
+}
+
+// AlertFilter holds the optional query-string filters for GET /filtration/alerts.
+// OrganizationIDs nil means "all organizations".
+type AlertFilter struct {
+	OrganizationIDs []int64
+	From            *string
+	To              *string
+	OnlyOpen        bool
+}
+
+// DashboardOrg is one organization row of the dam-safety dashboard.
+type DashboardOrg struct {
+	OrganizationID   int64    `json:"organization_id"`
+	OrganizationName string   `json:"organization_name"`
+	ReservoirLevel   *float64 `json:"reservoir_level"`
+	Instruments      int      `json:"instruments"`
+	Measured         int      `json:"measured"`
+	OpenAlerts       int      `json:"open_alerts"`
+	NormExceeded     int      `json:"norm_exceeded"`
+	OutOfBand        int      `json:"out_of_band"`
+	Alerts           []Alert  `json:"alerts"`
+}
+
+// Dashboard is the response of GET /filtration/dam-safety/dashboard.
+type Dashboard struct {
+	Date          string         `json:"date"`
+	TotalOpen     int            `json:"total_open"`
+	Organizations []DashboardOrg `json:"organizations"`
+}
@@ -104,15 +104,20 @@ type UpsertPiezometerCountsRequest struct {
 
 // --- Aggregated response ---
 
+// Measured is true when the station sent a reading row for the date, even
+// one without a value, so clients can tell "not measured" from a reading
+// left blank. It is set by the daily summary only.
 type LocationReading struct {
 	Location
 	FlowRate *float64 `json:"flow_rate"`
+	Measured bool     `json:"measured"`
 }
 
 type PiezoReading struct {
 	Piezometer
-	Level   *float64 `json:"level"`
-	Anomaly bool     `json:"anomaly"`
+	Level    *float64 `json:"level"`
+	Anomaly  bool     `json:"anomaly"`
+	Measured bool     `json:"measured"`
 }
 
 type OrgFiltrationSummary struct {
@@ -0,0 +1,52 @@
+package filtration
+
+import "time"
+
+// --- Measurement schedule ---
+
+// Schedule frequencies. A reading is due once per day, per ISO week
+// (Mon–Sun) or per calendar month.
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+// Schedule is one row of filtration_measurement_schedules. A row with both
+// LocationID and PiezometerID nil is the organization default; a row with
+// one of them set overrides the default for that instrument.
+type Schedule struct {
+	ID             int64     `json:"id"`
+	OrganizationID int64     `json:"organization_id"`
+	LocationID     *int64    `json:"location_id"`
+	PiezometerID   *int64    `json:"piezometer_id"`
+	Frequency      string    `json:"frequency"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type UpsertScheduleRequest struct {
+	OrganizationID int64  `json:"organization_id" validate:"required"`
+	LocationID     *int64 `json:"location_id" validate:"omitempty,gt=0,excluded_with=PiezometerID"`
+	PiezometerID   *int64 `json:"piezometer_id" validate:"omitempty,gt=0"`
+	Frequency      string `json:"frequency" validate:"required,oneof=daily weekly monthly"`
+}
+
+// ReadDate marks that an instrument has a non-null reading on Date.
+type ReadDate struct {
+	InstrumentType string
+	InstrumentID   int64
+	Date           string
+}
+
+// MissingReading is a schedule period that ended without any reading.
+type MissingReading struct {
+	OrganizationID   int64  `json:"organization_id"`
+	OrganizationName string `json:"organization_name"`
+	InstrumentType   string `json:"instrument_type"`
+	InstrumentID     int64  `json:"instrument_id"`
+	InstrumentName   string `json:"instrument_name"`
+	Frequency        string `json:"frequency"`
+	PeriodStart      string `json:"period_start"`
+	PeriodEnd        string `json:"period_end"`
+}
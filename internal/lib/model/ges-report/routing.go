@@ -0,0 +1,69 @@
+package gesreport
+
+// --- Cascade topology ---
+
+// TopologyLink is one station of a cascade with the station its outflow
+// feeds. DownstreamOrgID is nil for the last station of the chain.
+type TopologyLink struct {
+	StationOrgID    int64   `json:"station_id" validate:"required"`
+	StationName     string  `json:"station_name,omitempty"`
+	DownstreamOrgID *int64  `json:"downstream_id,omitempty" validate:"omitempty,gt=0"`
+	LagHours        float64 `json:"lag_hours" validate:"gte=0,lte=720"`
+	TolerancePct    float64 `json:"tolerance_pct" validate:"gt=0,lte=100"`
+}
+
+type CascadeTopology struct {
+	CascadeOrgID int64          `json:"cascade_id"`
+	Links        []TopologyLink `json:"links"`
+}
+
+// ReplaceTopologyRequest replaces the whole topology of a cascade.
+type ReplaceTopologyRequest struct {
+	CascadeOrgID int64          `json:"cascade_id" validate:"required"`
+	Links        []TopologyLink `json:"links" validate:"dive"`
+}
+
+// --- Routing view ---
+
+// Routing flags.
+const (
+	RoutingGain = "gain" // inflow exceeds routed upstream outflow beyond tolerance
+	RoutingLoss = "loss" // inflow falls short of routed upstream outflow beyond tolerance
+)
+
+// FlowRow is the daily inflow/outflow of one station.
+type FlowRow struct {
+	OrganizationID int64
+	Date           string
+	InflowM3s      *float64
+	OutflowM3s     *float64
+}
+
+// RoutingStation is one station on one date: its own inflow next to the
+// upstream outflow shifted by the travel lag. UpstreamOutflowM3s is nil for
+// headwater stations and when any upstream value is missing.
+type RoutingStation struct {
+	OrganizationID     int64    `json:"organization_id"`
+	OrganizationName   string   `json:"organization_name"`
+	UpstreamIDs        []int64  `json:"upstream_ids"`
+	InflowM3s          *float64 `json:"inflow_m3s"`
+	OutflowM3s         *float64 `json:"outflow_m3s"`
+	UpstreamOutflowM3s *float64 `json:"upstream_outflow_m3s"`
+	ImbalanceM3s       *float64 `json:"imbalance_m3s"`
+	ImbalancePct       *float64 `json:"imbalance_pct"`
+	TolerancePct       float64  `json:"tolerance_pct"`
+	Flag               *string  `json:"flag"`
+}
+
+type RoutingDay struct {
+	Date     string           `json:"date"`
+	Stations []RoutingStation `json:"stations"`
+}
+
+type CascadeRouting struct {
+	CascadeOrgID int64          `json:"cascade_id"`
+	CascadeName  string         `json:"cascade_name"`
+	Topology     []TopologyLink `json:"topology"`
+	Days         []RoutingDay   `json:"days"`
+	Flagged      int            `json:"flagged"`
+}
@@ -34,9 +34,11 @@ type AccessZone struct {
 	UpdatedAt        time.Time       `json:"updated_at"`
 }
 
+// AccessLog is one controller event. EmployeeID is empty for an unknown
+// card; Alert is set on events security should look at.
 type AccessLog struct {
 	ID           int64     `json:"id"`
-	EmployeeID   int64     `json:"employee_id"`
+	EmployeeID   *int64    `json:"employee_id,omitempty"`
 	EmployeeName string    `json:"employee_name"`
 	CardNumber   string    `json:"card_number"`
 	ZoneID       *int64    `json:"zone_id,omitempty"`
@@ -46,6 +48,81 @@ type AccessLog struct {
 	Timestamp    time.Time `json:"timestamp"`
 	Status       string    `json:"status"`
 	DenialReason *string   `json:"denial_reason,omitempty"`
+	ControllerID *string   `json:"controller_id,omitempty"`
+	Alert        *string   `json:"alert,omitempty"`
+}
+
+// Alerts raised on ingested events
+const (
+	AlertCardBlocked       = "card_blocked"
+	AlertCardLost          = "card_lost"
+	AlertCardExpired       = "card_expired"
+	AlertCardDeactivated   = "card_deactivated"
+	AlertUnknownCard       = "unknown_card"
+	AlertAntiPassback      = "anti_passback"
+	AlertOccupancyExceeded = "occupancy_exceeded"
+)
+
+// EventRecord is a controller event matched to its card, employee and zone,
+// ready to be stored. Alert carries a card problem found while matching.
+type EventRecord struct {
+	ControllerID string
+	EventID      *string
+	CardNumber   string
+	CardID       *int64
+	EmployeeID   *int64
+	ZoneID       *int64
+	ReaderID     *int
+	Direction    string
+	Timestamp    time.Time
+	Status       string
+	DenialReason *string
+	Alert        *string
+	// PresenceSince is the oldest entry still counted as being inside at
+	// Timestamp
+	PresenceSince time.Time
+}
+
+// RecordedEvent is the outcome of storing an event
+type RecordedEvent struct {
+	ID        int64
+	Duplicate bool
+	Alert     *string
+}
+
+// IngestedEvent reports one event of an ingestion request, by its index
+type IngestedEvent struct {
+	Index      int     `json:"index"`
+	ID         int64   `json:"id,omitempty"`
+	EmployeeID *int64  `json:"employee_id,omitempty"`
+	ZoneID     *int64  `json:"zone_id,omitempty"`
+	Duplicate  bool    `json:"duplicate,omitempty"`
+	Alert      *string `json:"alert,omitempty"`
+}
+
+// IngestResult reports an ingestion request
+type IngestResult struct {
+	Accepted   int             `json:"accepted"`
+	Duplicates int             `json:"duplicates"`
+	Alerts     int             `json:"alerts"`
+	Events     []IngestedEvent `json:"events"`
+}
+
+// ZoneOccupancy is who is inside a zone now
+type ZoneOccupancy struct {
+	ZoneID       int64          `json:"zone_id"`
+	ZoneName     string         `json:"zone_name"`
+	MaxOccupancy int            `json:"max_occupancy"`
+	Occupancy    int            `json:"occupancy"`
+	OverCapacity bool           `json:"over_capacity"`
+	People       []ZoneOccupant `json:"people"`
+}
+
+// ZoneOccupant is an employee inside a zone
+type ZoneOccupant struct {
+	EmployeeID   int64     `json:"employee_id"`
+	EmployeeName string    `json:"employee_name"`
+	EnteredAt    time.Time `json:"entered_at"`
 }
 
 type AccessRequest struct {
@@ -0,0 +1,67 @@
+package calendar
+
+import "time"
+
+// Exception types
+const (
+	ExceptionWorking = "working"
+	ExceptionDayOff  = "day_off"
+	ExceptionShort   = "short"
+)
+
+// Default week schedule of an organization without one of its own
+const (
+	DefaultWeekDays = 6
+	DefaultDayHours = 8.0
+)
+
+// Day is one day of the production calendar for a week schedule
+type Day struct {
+	Date        string  `json:"date"`
+	Working     bool    `json:"working"`
+	Hours       float64 `json:"hours"`
+	Holiday     bool    `json:"holiday"`
+	HolidayName string  `json:"holiday_name,omitempty"`
+	Short       bool    `json:"short,omitempty"`
+	Transferred bool    `json:"transferred,omitempty"`
+	Note        *string `json:"note,omitempty"`
+}
+
+// Exception moves a day between working and non-working or shortens it.
+// WeekDays limits it to one week schedule; nil applies it to both.
+type Exception struct {
+	ID       int64   `json:"id"`
+	Date     string  `json:"date"`
+	Type     string  `json:"type"`
+	WeekDays *int    `json:"week_days,omitempty"`
+	Note     *string `json:"note,omitempty"`
+}
+
+// Schedule is an organization's working week
+type Schedule struct {
+	OrganizationID   *int64     `json:"organization_id,omitempty"`
+	OrganizationName string     `json:"organization_name,omitempty"`
+	WeekDays         int        `json:"week_days"`
+	DayHours         float64    `json:"day_hours"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+}
+
+// MonthSummary is the working time of a calendar month
+type MonthSummary struct {
+	Month     int     `json:"month"`
+	WorkDays  int     `json:"work_days"`
+	DaysOff   int     `json:"days_off"`
+	ShortDays int     `json:"short_days"`
+	WorkHours float64 `json:"work_hours"`
+}
+
+// Year is the production calendar of a year for a week schedule
+type Year struct {
+	Year        int            `json:"year"`
+	Published   bool           `json:"published"`
+	PublishedAt *time.Time     `json:"published_at,omitempty"`
+	Schedule    Schedule       `json:"schedule"`
+	Months      []MonthSummary `json:"months"`
+	Exceptions  []*Exception   `json:"exceptions"`
+	Days        []Day          `json:"days"`
+}
@@ -0,0 +1,96 @@
+package lifecycle
+
+import (
+	"srmt-admin/internal/lib/model/hrm/salary"
+	"time"
+)
+
+// Order kinds
+const (
+	KindHire      = "hire"
+	KindTransfer  = "transfer"
+	KindDismissal = "dismissal"
+)
+
+// Order statuses. A hire is applied when it is made; transfers and
+// dismissals wait for their effective date.
+const (
+	StatusScheduled = "scheduled"
+	StatusApplied   = "applied"
+	StatusCancelled = "cancelled"
+)
+
+// SalaryChange is the salary structure of an employee from a transfer on
+type SalaryChange struct {
+	BaseSalary          float64 `json:"base_salary"`
+	RegionalAllowance   float64 `json:"regional_allowance"`
+	SeniorityAllowance  float64 `json:"seniority_allowance"`
+	QualificationAllow  float64 `json:"qualification_allowance"`
+	HazardAllowance     float64 `json:"hazard_allowance"`
+	NightShiftAllowance float64 `json:"night_shift_allowance"`
+}
+
+// Order is an HR order hiring, transferring or dismissing an employee. For
+// a dismissal EffectiveDate is the last day of work.
+type Order struct {
+	ID            int64   `json:"id"`
+	Kind          string  `json:"kind"`
+	Number        string  `json:"number"`
+	OrderDate     string  `json:"order_date"`
+	EffectiveDate string  `json:"effective_date"`
+	Status        string  `json:"status"`
+	EmployeeID    int64   `json:"employee_id"`
+	EmployeeName  string  `json:"employee_name"`
+	RecordID      *int64  `json:"record_id,omitempty"`
+	DocumentID    *int64  `json:"document_id,omitempty"`
+	Reason        *string `json:"reason,omitempty"`
+
+	// Hire
+	OfferID      *int64 `json:"offer_id,omitempty"`
+	OnboardingID *int64 `json:"onboarding_id,omitempty"`
+	UserID       *int64 `json:"user_id,omitempty"`
+
+	// Transfer
+	FromDepartmentID *int64        `json:"from_department_id,omitempty"`
+	ToDepartmentID   *int64        `json:"to_department_id,omitempty"`
+	FromPositionID   *int64        `json:"from_position_id,omitempty"`
+	ToPositionID     *int64        `json:"to_position_id,omitempty"`
+	Salary           *SalaryChange `json:"salary,omitempty"`
+	TransferID       *int64        `json:"transfer_id,omitempty"`
+
+	// Dismissal
+	Settlement *salary.Settlement `json:"settlement,omitempty"`
+
+	CreatedBy *int64     `json:"created_by,omitempty"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// ChecklistTask is an item of the onboarding checklist of a new employee
+type ChecklistTask struct {
+	Title      string
+	AssignedTo *int64
+	DueDate    string
+}
+
+// Hire is what a hire order sets up for the candidate of an accepted offer:
+// the employee's contact and personnel record, the salary structure of the
+// offered salary, a user account when a login is given, and the onboarding
+// checklist. The account gets the hrm_employee role only; anything more is
+// granted through user management.
+type Hire struct {
+	OfferID         int64
+	HireDate        string
+	TabNumber       string
+	ContractType    string
+	ContractEndDate *string
+	BaseSalary      *float64
+	Login           *string
+	PasswordHash    []byte
+	MentorID        *int64
+	Checklist       []ChecklistTask
+	OrderDate       string
+	Reason          *string
+	CreatedBy       int64
+}
@@ -14,6 +14,7 @@ type Record struct {
 	PositionName    string    `json:"position_name"`
 	ContractType    string    `json:"contract_type"`
 	ContractEndDate *string   `json:"contract_end_date,omitempty"`
+	DismissalDate   *string   `json:"dismissal_date,omitempty"`
 	Status          string    `json:"status"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
@@ -95,3 +95,47 @@ type Interview struct {
 	CreatedAt       time.Time       `json:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at"`
 }
+
+type JobOffer struct {
+	ID            int64      `json:"id"`
+	CandidateID   int64      `json:"candidate_id"`
+	CandidateName string     `json:"candidate_name"`
+	VacancyID     int64      `json:"vacancy_id"`
+	VacancyTitle  string     `json:"vacancy_title"`
+	DepartmentID  int64      `json:"department_id"`
+	PositionID    int64      `json:"position_id"`
+	SalaryOffered *float64   `json:"salary_offered,omitempty"`
+	StartDate     *string    `json:"start_date,omitempty"`
+	Status        string     `json:"status"`
+	Notes         *string    `json:"notes,omitempty"`
+	DecidedAt     *time.Time `json:"decided_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+type Onboarding struct {
+	ID           int64            `json:"id"`
+	CandidateID  int64            `json:"candidate_id"`
+	VacancyID    int64            `json:"vacancy_id"`
+	EmployeeID   *int64           `json:"employee_id,omitempty"`
+	EmployeeName *string          `json:"employee_name,omitempty"`
+	OfferID      *int64           `json:"offer_id,omitempty"`
+	StartDate    *string          `json:"start_date,omitempty"`
+	Status       string           `json:"status"`
+	MentorID     *int64           `json:"mentor_id,omitempty"`
+	Notes        *string          `json:"notes,omitempty"`
+	Tasks        []OnboardingTask `json:"tasks"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+type OnboardingTask struct {
+	ID           int64      `json:"id"`
+	OnboardingID int64      `json:"onboarding_id"`
+	Title        string     `json:"title"`
+	Description  *string    `json:"description,omitempty"`
+	AssignedTo   *int64     `json:"assigned_to,omitempty"`
+	DueDate      *string    `json:"due_date,omitempty"`
+	Status       string     `json:"status"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+}
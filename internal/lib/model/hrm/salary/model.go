@@ -17,6 +17,8 @@ type Salary struct {
 	HazardAllowance     float64   `json:"hazard_allowance"`
 	NightShiftAllowance float64   `json:"night_shift_allowance"`
 	OvertimeAmount      float64   `json:"overtime_amount"`
+	NightAmount         float64   `json:"night_amount"`
+	SickAmount          float64   `json:"sick_amount"`
 	BonusAmount         float64   `json:"bonus_amount"`
 	GrossSalary         float64   `json:"gross_salary"`
 	NDFL                float64   `json:"ndfl"`
@@ -29,6 +31,9 @@ type Salary struct {
 	WorkDays            int       `json:"work_days"`
 	ActualDays          int       `json:"actual_days"`
 	OvertimeHours       float64   `json:"overtime_hours"`
+	NightHours          float64   `json:"night_hours"`
+	SickDays            int       `json:"sick_days"`
+	FromTimesheet       bool      `json:"from_timesheet"`
 	Status              string    `json:"status"`
 	CalculatedAt        *string   `json:"calculated_at,omitempty"`
 	ApprovedBy          *int64    `json:"approved_by,omitempty"`
@@ -71,18 +76,103 @@ type Deduction struct {
 	CreatedAt     time.Time `json:"created_at"`
 }
 
+// TaxRates are the tax and contribution shares of the gross salary, and the
+// pay rates for overtime, night hours and sick days
 type TaxRates struct {
 	NDFL       float64
 	Social     float64
 	Pension    float64
 	Health     float64
 	TradeUnion float64
+	Overtime   float64 // multiplier of the hourly rate
+	Night      float64 // surcharge on the hourly rate
+	SickPay    float64 // share of the daily rate
 }
 
+// DefaultTaxRates apply where no payroll rate is in effect
 var DefaultTaxRates = TaxRates{
 	NDFL:       0.12,
 	Social:     0.005,
 	Pension:    0.03,
 	Health:     0.005,
 	TradeUnion: 0.01,
+	Overtime:   1.5,
+	Night:      0.5,
+	SickPay:    0.6,
+}
+
+// Payroll rate codes
+const (
+	RateNDFL       = "ndfl"
+	RateSocial     = "social"
+	RatePension    = "pension"
+	RateHealth     = "health"
+	RateTradeUnion = "trade_union"
+	RateOvertime   = "overtime"
+	RateNight      = "night"
+	RateSickPay    = "sick_pay"
+)
+
+// PayrollRate is a rate in effect from a date until the next rate of the
+// same code
+type PayrollRate struct {
+	ID            int64     `json:"id"`
+	Code          string    `json:"code"`
+	Rate          float64   `json:"rate"`
+	EffectiveFrom string    `json:"effective_from"`
+	Note          *string   `json:"note,omitempty"`
+	CreatedBy     *int64    `json:"created_by,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Apply returns the rates with the given payroll rates put in place of
+// their codes
+func (t TaxRates) Apply(rates []*PayrollRate) TaxRates {
+	for _, r := range rates {
+		switch r.Code {
+		case RateNDFL:
+			t.NDFL = r.Rate
+		case RateSocial:
+			t.Social = r.Rate
+		case RatePension:
+			t.Pension = r.Rate
+		case RateHealth:
+			t.Health = r.Rate
+		case RateTradeUnion:
+			t.TradeUnion = r.Rate
+		case RateOvertime:
+			t.Overtime = r.Rate
+		case RateNight:
+			t.Night = r.Rate
+		case RateSickPay:
+			t.SickPay = r.Rate
+		}
+	}
+	return t
+}
+
+// BankAccount is the account an employee's salary is paid to
+type BankAccount struct {
+	EmployeeID    int64     `json:"employee_id"`
+	EmployeeName  string    `json:"employee_name,omitempty"`
+	BankName      string    `json:"bank_name"`
+	BankCode      string    `json:"bank_code"`
+	AccountNumber string    `json:"account_number"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Settlement is what is paid to an employee on dismissal: the salary of the
+// last month, with compensation for the vacation days not taken at the
+// average daily earnings
+type Settlement struct {
+	EmployeeID           int64   `json:"employee_id"`
+	LastDay              string  `json:"last_day"`
+	SalaryID             *int64  `json:"salary_id,omitempty"`
+	PeriodYear           int     `json:"period_year"`
+	PeriodMonth          int     `json:"period_month"`
+	UnusedVacationDays   float64 `json:"unused_vacation_days"`
+	AverageDailyEarnings float64 `json:"average_daily_earnings"`
+	VacationCompensation float64 `json:"vacation_compensation"`
+	GrossSalary          float64 `json:"gross_salary"`
+	NetSalary            float64 `json:"net_salary"`
 }
@@ -12,14 +12,24 @@ type Day struct {
 	CheckOut    *string  `json:"check_out,omitempty"`
 	HoursWorked *float64 `json:"hours_worked,omitempty"`
 	Overtime    *float64 `json:"overtime,omitempty"`
+	NightHours  *float64 `json:"night_hours,omitempty"`
 	IsWeekend   bool     `json:"is_weekend"`
 	IsHoliday   bool     `json:"is_holiday"`
 	Note        *string  `json:"note,omitempty"`
+	Source      string   `json:"source,omitempty"`
 }
 
+// Entry sources. Auto entries are rewritten by the generator whenever one
+// of its sources changes; manual entries always take precedence over them.
+const (
+	SourceManual = "manual"
+	SourceAuto   = "auto"
+)
+
 // Summary is the monthly aggregation for an employee's timesheet
 type Summary struct {
 	TotalWorkDays    int     `json:"total_work_days"`
+	NormHours        float64 `json:"norm_hours"`
 	PresentDays      int     `json:"present_days"`
 	AbsentDays       int     `json:"absent_days"`
 	VacationDays     int     `json:"vacation_days"`
@@ -28,6 +38,7 @@ type Summary struct {
 	RemoteDays       int     `json:"remote_days"`
 	TotalHours       float64 `json:"total_hours"`
 	OvertimeHours    float64 `json:"overtime_hours"`
+	NightHours       float64 `json:"night_hours"`
 	LateArrivals     int     `json:"late_arrivals"`
 	EarlyDepartures  int     `json:"early_departures"`
 }
@@ -81,3 +92,42 @@ type EmployeeInfo struct {
 	Position   string
 	TabNumber  string
 }
+
+// Absence is a sick leave or business trip period recorded by HR
+type Absence struct {
+	ID           int64     `json:"id"`
+	EmployeeID   int64     `json:"employee_id"`
+	EmployeeName string    `json:"employee_name"`
+	Type         string    `json:"type"`
+	StartDate    string    `json:"start_date"`
+	EndDate      string    `json:"end_date"`
+	Note         *string   `json:"note,omitempty"`
+	CreatedBy    *int64    `json:"created_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Period is an approved vacation or an absence as seen by the generator.
+// Kind is the vacation type or the absence type.
+type Period struct {
+	EmployeeID int64
+	Kind       string
+	StartDate  string
+	EndDate    string
+}
+
+// Presence is an employee's first granted entry and last granted exit on a
+// local calendar day, taken from the access-control logs.
+type Presence struct {
+	EmployeeID int64
+	Date       string
+	FirstIn    *time.Time
+	LastOut    *time.Time
+}
+
+// GenerateResult reports what a timesheet generation run did
+type GenerateResult struct {
+	Employees int `json:"employees"`
+	Written   int `json:"written"`
+	Unchanged int `json:"unchanged"`
+	Manual    int `json:"manual"`
+}
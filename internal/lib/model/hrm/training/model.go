@@ -21,6 +21,7 @@ type Training struct {
 	Cost                *float64        `json:"cost,omitempty"`
 	Mandatory           bool            `json:"mandatory"`
 	DepartmentIDs       json.RawMessage `json:"department_ids"`
+	CertificationTypeID *int64          `json:"certification_type_id,omitempty"`
 	CreatedBy           *int64          `json:"created_by,omitempty"`
 	CreatedAt           time.Time       `json:"created_at"`
 	UpdatedAt           time.Time       `json:"updated_at"`
@@ -40,17 +41,19 @@ type Participant struct {
 }
 
 type Certificate struct {
-	ID             int64     `json:"id"`
-	EmployeeID     int64     `json:"employee_id"`
-	EmployeeName   string    `json:"employee_name"`
-	TrainingID     *int64    `json:"training_id,omitempty"`
-	TrainingTitle  *string   `json:"training_title,omitempty"`
-	Title          string    `json:"title"`
-	Issuer         *string   `json:"issuer,omitempty"`
-	IssueDate      string    `json:"issue_date"`
-	ExpiryDate     *string   `json:"expiry_date,omitempty"`
-	CertificateURL *string   `json:"certificate_url,omitempty"`
-	CreatedAt      time.Time `json:"created_at"`
+	ID                    int64     `json:"id"`
+	EmployeeID            int64     `json:"employee_id"`
+	EmployeeName          string    `json:"employee_name"`
+	TrainingID            *int64    `json:"training_id,omitempty"`
+	TrainingTitle         *string   `json:"training_title,omitempty"`
+	CertificationTypeID   *int64    `json:"certification_type_id,omitempty"`
+	CertificationTypeName *string   `json:"certification_type_name,omitempty"`
+	Title                 string    `json:"title"`
+	Issuer                *string   `json:"issuer,omitempty"`
+	IssueDate             string    `json:"issue_date"`
+	ExpiryDate            *string   `json:"expiry_date,omitempty"`
+	CertificateURL        *string   `json:"certificate_url,omitempty"`
+	CreatedAt             time.Time `json:"created_at"`
 }
 
 type DevelopmentPlan struct {
@@ -77,3 +80,140 @@ type DevelopmentGoal struct {
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 }
+
+// CertificationType is a certification positions may require, e.g. an
+// electrical safety group or a work-at-height permit. A certificate of it is
+// valid for ValidityMonths (for good when nil); holders are alerted
+// AlertDays ahead of expiry.
+type CertificationType struct {
+	ID             int64     `json:"id"`
+	Name           string    `json:"name"`
+	Description    *string   `json:"description,omitempty"`
+	ValidityMonths *int      `json:"validity_months,omitempty"`
+	AlertDays      int       `json:"alert_days"`
+	PositionCount  int       `json:"position_count"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// PositionRequirement is a certification type a position requires
+type PositionRequirement struct {
+	PositionID            int64  `json:"position_id"`
+	PositionName          string `json:"position_name"`
+	CertificationTypeID   int64  `json:"certification_type_id"`
+	CertificationTypeName string `json:"certification_type_name"`
+}
+
+// Compliance statuses of a required certification. An expiring certificate
+// is still valid; expired and missing ones make an employee non-compliant.
+const (
+	ComplianceValid    = "valid"
+	ComplianceExpiring = "expiring"
+	ComplianceExpired  = "expired"
+	ComplianceMissing  = "missing"
+)
+
+// RequiredCertification is a certification an employee's position requires,
+// with the employee's latest certificate of it if any
+type RequiredCertification struct {
+	EmployeeID            int64
+	EmployeeName          string
+	DepartmentID          int64
+	DepartmentName        string
+	PositionID            int64
+	PositionName          string
+	CertificationTypeID   int64
+	CertificationTypeName string
+	AlertDays             int
+	CertificateID         *int64
+	IssueDate             *string
+	ExpiryDate            *string
+}
+
+// ComplianceMatrix is the certification compliance of the employees of an
+// organization on a date: a row per employee whose position requires any
+// certification, a column per certification type required
+type ComplianceMatrix struct {
+	Date           string                `json:"date"`
+	Certifications []ComplianceColumn    `json:"certifications"`
+	Employees      []*EmployeeCompliance `json:"employees"`
+	Summary        ComplianceSummary     `json:"summary"`
+}
+
+type ComplianceColumn struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type EmployeeCompliance struct {
+	EmployeeID     int64                 `json:"employee_id"`
+	EmployeeName   string                `json:"employee_name"`
+	DepartmentID   int64                 `json:"department_id"`
+	DepartmentName string                `json:"department_name"`
+	PositionID     int64                 `json:"position_id"`
+	PositionName   string                `json:"position_name"`
+	Compliant      bool                  `json:"compliant"`
+	Certifications []CertificationStatus `json:"certifications"`
+}
+
+// CertificationStatus is a cell of the compliance matrix. DaysLeft is
+// negative once the certificate has expired.
+type CertificationStatus struct {
+	CertificationTypeID int64   `json:"certification_type_id"`
+	Status              string  `json:"status"`
+	CertificateID       *int64  `json:"certificate_id,omitempty"`
+	ExpiryDate          *string `json:"expiry_date,omitempty"`
+	DaysLeft            *int    `json:"days_left,omitempty"`
+}
+
+type ComplianceSummary struct {
+	Employees int `json:"employees"`
+	Compliant int `json:"compliant"`
+	Required  int `json:"required"`
+	Valid     int `json:"valid"`
+	Expiring  int `json:"expiring"`
+	Expired   int `json:"expired"`
+	Missing   int `json:"missing"`
+}
+
+// CertificationTraining is an upcoming training granting a certification,
+// with the employees already enrolled. SeatsLeft is nil when the number of
+// participants is not limited.
+type CertificationTraining struct {
+	ID                  int64
+	CertificationTypeID int64
+	Title               string
+	StartDate           string
+	SeatsLeft           *int
+	Enrolled            []int64
+}
+
+// EnrolmentSuggestion proposes enrolling an employee whose required
+// certification is missing, expired or expiring into the nearest training
+// granting it
+type EnrolmentSuggestion struct {
+	EmployeeID            int64   `json:"employee_id"`
+	EmployeeName          string  `json:"employee_name"`
+	DepartmentName        string  `json:"department_name"`
+	PositionName          string  `json:"position_name"`
+	CertificationTypeID   int64   `json:"certification_type_id"`
+	CertificationTypeName string  `json:"certification_type_name"`
+	Status                string  `json:"status"`
+	ExpiryDate            *string `json:"expiry_date,omitempty"`
+	TrainingID            int64   `json:"training_id"`
+	TrainingTitle         string  `json:"training_title"`
+	TrainingStartDate     string  `json:"training_start_date"`
+}
+
+// ExpiringCertificate is the current certificate of a required
+// certification that expires within the alert period of its type, with the
+// last alert sent about it
+type ExpiringCertificate struct {
+	ID                    int64
+	EmployeeID            int64
+	EmployeeName          string
+	CertificationTypeID   int64
+	CertificationTypeName string
+	ExpiryDate            string
+	Alert                 *string
+}
@@ -0,0 +1,94 @@
+package vacation
+
+import "time"
+
+// Ledger entry kinds. Opening, accrual, hazard and adjustment rows make up
+// the days earned in a year, carry_over those brought from the year before;
+// carried_forward and expiry empty a closed year. Usage and pending rows are
+// not stored: they come from the vacations themselves.
+const (
+	LedgerOpening        = "opening"
+	LedgerAccrual        = "accrual"
+	LedgerHazard         = "hazard"
+	LedgerAdjustment     = "adjustment"
+	LedgerCarryOver      = "carry_over"
+	LedgerCarriedForward = "carried_forward"
+	LedgerExpiry         = "expiry"
+	LedgerUsage          = "usage"
+	LedgerPending        = "pending"
+)
+
+// LedgerEntry is one movement of an employee's vacation days. Balance is the
+// running balance of the year after the entry.
+type LedgerEntry struct {
+	ID           *int64  `json:"id,omitempty"`
+	EmployeeID   int64   `json:"employee_id"`
+	Year         int     `json:"year"`
+	Date         string  `json:"date"`
+	Kind         string  `json:"kind"`
+	Days         float64 `json:"days"`
+	Balance      float64 `json:"balance"`
+	VacationID   *int64  `json:"vacation_id,omitempty"`
+	VacationType *string `json:"vacation_type,omitempty"`
+	Note         *string `json:"note,omitempty"`
+	CreatedBy    *int64  `json:"created_by,omitempty"`
+}
+
+// Ledger explains an employee's balance for a year
+type Ledger struct {
+	EmployeeID  int64          `json:"employee_id"`
+	Year        int            `json:"year"`
+	Earned      float64        `json:"earned"`
+	CarriedOver float64        `json:"carried_over"`
+	Used        float64        `json:"used"`
+	Pending     float64        `json:"pending"`
+	Closed      float64        `json:"closed"`
+	Remaining   float64        `json:"remaining"`
+	YearClosed  bool           `json:"year_closed"`
+	Entries     []*LedgerEntry `json:"entries"`
+}
+
+// EntitlementRule sets the yearly vacation days of a position, or of all
+// positions when PositionID is nil, from MinSeniorityYears of service on
+type EntitlementRule struct {
+	ID                int64     `json:"id"`
+	PositionID        *int64    `json:"position_id,omitempty"`
+	PositionName      *string   `json:"position_name,omitempty"`
+	MinSeniorityYears int       `json:"min_seniority_years"`
+	AnnualDays        int       `json:"annual_days"`
+	Note              *string   `json:"note,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// Policy holds the accrual settings shared by all employees
+type Policy struct {
+	HazardDays   int       `json:"hazard_days"`
+	CarryOverCap int       `json:"carry_over_cap"`
+	UpdatedBy    *int64    `json:"updated_by,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// AccrualEmployee is what accrual needs to know of an employee for a month
+type AccrualEmployee struct {
+	EmployeeID    int64
+	PositionID    int64
+	HireDate      string
+	DismissalDate *string
+	Hazard        bool
+}
+
+// AccrualResult reports a month's accrual
+type AccrualResult struct {
+	Year      int     `json:"year"`
+	Month     int     `json:"month"`
+	Employees int     `json:"employees"`
+	Days      float64 `json:"days"`
+}
+
+// ClosingResult reports a year-end closing
+type ClosingResult struct {
+	Year        int     `json:"year"`
+	Employees   int     `json:"employees"`
+	CarriedOver float64 `json:"carried_over"`
+	Expired     float64 `json:"expired"`
+}
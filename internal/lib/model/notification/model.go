@@ -0,0 +1,84 @@
+package notification
+
+import "time"
+
+// Delivery channels
+const (
+	ChannelInApp    = "in_app"
+	ChannelEmail    = "email"
+	ChannelTelegram = "telegram"
+)
+
+// Delivery statuses
+const (
+	StatusPending = "pending"
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+	// StatusSkipped marks deliveries that could not be attempted, e.g. to a
+	// contact without an email address or a linked Telegram chat.
+	StatusSkipped = "skipped"
+)
+
+// EventKind describes one event type of the catalogue.
+type EventKind struct {
+	Type            string   `json:"type"`
+	Name            string   `json:"name"`
+	Severity        string   `json:"severity"`
+	DefaultChannels []string `json:"default_channels"`
+}
+
+// Preference is the channels a contact receives an event type on. Channels
+// is empty when the contact turned the event off.
+type Preference struct {
+	EventType string   `json:"event_type"`
+	Name      string   `json:"name"`
+	Channels  []string `json:"channels"`
+	Custom    bool     `json:"custom"`
+}
+
+// PreferencesResponse lists the contact's preferences for every event type
+// together with the channels that can be delivered to.
+type PreferencesResponse struct {
+	Channels    []string     `json:"channels"`
+	Preferences []Preference `json:"preferences"`
+}
+
+type PreferenceInput struct {
+	EventType string `json:"event_type" validate:"required"`
+	// Channels replaces the channels of the event; nil resets the event to
+	// its default channels.
+	Channels []string `json:"channels" validate:"omitempty,dive,oneof=in_app email telegram"`
+}
+
+type SetPreferencesRequest struct {
+	Preferences []PreferenceInput `json:"preferences" validate:"required,min=1,dive"`
+}
+
+// Delivery is one notification to one contact on one channel.
+type Delivery struct {
+	ID             int64      `json:"id"`
+	ContactID      int64      `json:"contact_id"`
+	ContactName    *string    `json:"contact_name,omitempty"`
+	EventType      string     `json:"event_type"`
+	Channel        string     `json:"channel"`
+	Severity       string     `json:"severity"`
+	Title          string     `json:"title"`
+	Message        string     `json:"message"`
+	Link           *string    `json:"link,omitempty"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastError      *string    `json:"last_error,omitempty"`
+	NotificationID *int64     `json:"notification_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	SentAt         *time.Time `json:"sent_at,omitempty"`
+}
+
+type DeliveryFilter struct {
+	ContactID *int64
+	EventType *string
+	Channel   *string
+	Status    *string
+	Limit     int
+	Offset    int
+}
@@ -0,0 +1,195 @@
+package registration_number
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"srmt-admin/internal/lib/model/user"
+)
+
+// Counter reset periods
+const (
+	ResetYearly = "yearly"
+	ResetNever  = "never"
+)
+
+// Journal entry statuses
+const (
+	StatusReserved  = "reserved"  // handed out, no document yet
+	StatusIssued    = "issued"    // assigned to a registered document
+	StatusCancelled = "cancelled" // withdrawn; the number is never reused
+)
+
+var (
+	ErrPatternNoSeq       = errors.New("pattern must contain {seq} or {seq:N}")
+	ErrPatternNoYear      = errors.New("a yearly scheme must contain {yy} or {yyyy} so numbers stay unique across years")
+	ErrPatternOrgCode     = errors.New("pattern uses {org_code} but the scheme has no org_code")
+	ErrPatternNoOrgCode   = errors.New("an organization's scheme must contain {org_code} so its numbers stay unique within the kind")
+	ErrPatternUnknownPart = errors.New("unknown placeholder in pattern")
+	ErrInvalidResetPeriod = errors.New("reset_period must be yearly or never")
+)
+
+var placeholderRe = regexp.MustCompile(`\{([a-z_]+)(?::(\d+))?\}`)
+
+// Scheme is the numbering scheme of a document kind. Pattern placeholders:
+// {seq} or {seq:N} (zero padded to N digits), {yy}, {yyyy}, {mm}, {dd} of
+// the registration date, and {org_code}.
+type Scheme struct {
+	ID               int64      `json:"id"`
+	DocumentKind     string     `json:"document_kind"`
+	OrganizationID   *int64     `json:"organization_id,omitempty"`
+	OrganizationName *string    `json:"organization_name,omitempty"`
+	OrgCode          *string    `json:"org_code,omitempty"`
+	Pattern          string     `json:"pattern"`
+	ResetPeriod      string     `json:"reset_period"`
+	AllowReservation bool       `json:"allow_reservation"`
+	IsActive         bool       `json:"is_active"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+}
+
+// Period returns the counter period a number registered on date falls in.
+func (s Scheme) Period(date time.Time) int {
+	if s.ResetPeriod == ResetYearly {
+		return date.Year()
+	}
+	return 0
+}
+
+// Format renders the number with sequence value seq registered on date.
+func (s Scheme) Format(seq int, date time.Time) string {
+	return placeholderRe.ReplaceAllStringFunc(s.Pattern, func(m string) string {
+		parts := placeholderRe.FindStringSubmatch(m)
+		switch parts[1] {
+		case "seq":
+			if parts[2] != "" {
+				width, _ := strconv.Atoi(parts[2])
+				return fmt.Sprintf("%0*d", width, seq)
+			}
+			return strconv.Itoa(seq)
+		case "yy":
+			return fmt.Sprintf("%02d", date.Year()%100)
+		case "yyyy":
+			return strconv.Itoa(date.Year())
+		case "mm":
+			return fmt.Sprintf("%02d", int(date.Month()))
+		case "dd":
+			return fmt.Sprintf("%02d", date.Day())
+		case "org_code":
+			if s.OrgCode != nil {
+				return *s.OrgCode
+			}
+		}
+		return m
+	})
+}
+
+// Validate checks the pattern against the reset period and org code. Numbers
+// are unique per kind while counters are kept per scheme, so every pattern
+// must tell the schemes of a kind apart: an organization's scheme by its org
+// code, a yearly scheme's periods by the year.
+func (s Scheme) Validate() error {
+	if s.ResetPeriod != ResetYearly && s.ResetPeriod != ResetNever {
+		return ErrInvalidResetPeriod
+	}
+
+	var hasSeq, hasYear, hasOrgCode bool
+	for _, parts := range placeholderRe.FindAllStringSubmatch(s.Pattern, -1) {
+		switch parts[1] {
+		case "seq":
+			hasSeq = true
+		case "yy", "yyyy":
+			hasYear = true
+		case "org_code":
+			hasOrgCode = true
+		case "mm", "dd":
+		default:
+			return fmt.Errorf("%w: {%s}", ErrPatternUnknownPart, parts[1])
+		}
+		if parts[2] != "" && parts[1] != "seq" {
+			return fmt.Errorf("%w: {%s:%s}", ErrPatternUnknownPart, parts[1], parts[2])
+		}
+	}
+	if !hasSeq {
+		return ErrPatternNoSeq
+	}
+	if s.ResetPeriod == ResetYearly && !hasYear {
+		return ErrPatternNoYear
+	}
+	if hasOrgCode && (s.OrgCode == nil || strings.TrimSpace(*s.OrgCode) == "") {
+		return ErrPatternOrgCode
+	}
+	if s.OrganizationID != nil && !hasOrgCode {
+		return ErrPatternNoOrgCode
+	}
+	return nil
+}
+
+// AddSchemeRequest creates a scheme. OrganizationID nil makes it the
+// default scheme of the kind.
+type AddSchemeRequest struct {
+	DocumentKind     string  `json:"document_kind" validate:"required"`
+	OrganizationID   *int64  `json:"organization_id,omitempty"`
+	OrgCode          *string `json:"org_code,omitempty" validate:"omitempty,max=30"`
+	Pattern          string  `json:"pattern" validate:"required,max=100"`
+	ResetPeriod      string  `json:"reset_period" validate:"required,oneof=yearly never"`
+	AllowReservation bool    `json:"allow_reservation"`
+}
+
+// EditSchemeRequest updates a scheme. Omitted fields are left unchanged. The
+// numbering (org code, pattern and reset period) of a scheme that has
+// numbers in the journal cannot be changed.
+type EditSchemeRequest struct {
+	OrgCode          *string `json:"org_code,omitempty" validate:"omitempty,max=30"`
+	Pattern          *string `json:"pattern,omitempty" validate:"omitempty,max=100"`
+	ResetPeriod      *string `json:"reset_period,omitempty" validate:"omitempty,oneof=yearly never"`
+	AllowReservation *bool   `json:"allow_reservation,omitempty"`
+	IsActive         *bool   `json:"is_active,omitempty"`
+}
+
+// Entry is one line of the registration journal.
+type Entry struct {
+	ID               int64           `json:"id"`
+	SchemeID         int64           `json:"scheme_id"`
+	DocumentKind     string          `json:"document_kind"`
+	OrganizationID   *int64          `json:"organization_id,omitempty"`
+	OrganizationName *string         `json:"organization_name,omitempty"`
+	Period           int             `json:"period"`
+	Seq              int             `json:"seq"`
+	Number           string          `json:"number"`
+	RegisteredOn     time.Time       `json:"registered_on"`
+	Status           string          `json:"status"`
+	DocumentID       *int64          `json:"document_id,omitempty"`
+	DocumentName     *string         `json:"document_name,omitempty"`
+	ReservedBy       *user.ShortInfo `json:"reserved_by,omitempty"`
+	IssuedAt         *time.Time      `json:"issued_at,omitempty"`
+	CancelledAt      *time.Time      `json:"cancelled_at,omitempty"`
+	CancelledBy      *user.ShortInfo `json:"cancelled_by,omitempty"`
+	CancelReason     *string         `json:"cancel_reason,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+}
+
+// JournalFilter selects journal entries. Year matches the registration date.
+type JournalFilter struct {
+	DocumentKind   *string
+	OrganizationID *int64
+	Year           *int
+	Status         *string
+}
+
+// ReserveRequest reserves the next number of the scheme that applies to the
+// kind and organization.
+type ReserveRequest struct {
+	DocumentKind   string     `json:"document_kind" validate:"required"`
+	OrganizationID *int64     `json:"organization_id,omitempty"`
+	RegisteredOn   *time.Time `json:"registered_on,omitempty"`
+}
+
+// CancelRequest withdraws a reserved number.
+type CancelRequest struct {
+	Reason *string `json:"reason,omitempty"`
+}
@@ -1,8 +0,0 @@
-package report_type
-
-// Model represents a report type
-type Model struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-}
@@ -45,6 +45,12 @@ type HourlyRecord struct {
 	GESFlowM3s       *float64  `json:"ges_flow_m3s"`
 	IdleDischargeM3s *float64  `json:"idle_discharge_m3s"`
 	DutyName         *string   `json:"duty_name"`
+	// DutyEmployeeID links the record to the officer on duty: the employee
+	// picked explicitly, otherwise — when DutyName is empty too — whoever
+	// the roster had on shift at RecordedAt. DutyEmployeeName is that
+	// employee's name.
+	DutyEmployeeID   *int64    `json:"duty_employee_id"`
+	DutyEmployeeName *string   `json:"duty_employee_name"`
 	CapacityMwt      *float64  `json:"capacity_mwt"`
 	WeatherCondition *string   `json:"weather_condition"`
 	TemperatureC     *float64  `json:"temperature_c"`
@@ -69,6 +75,7 @@ type UpsertHourlyRequest struct {
 	GESFlowM3s       optional.Optional[float64] `json:"ges_flow_m3s"        validate:"omitempty"`
 	IdleDischargeM3s optional.Optional[float64] `json:"idle_discharge_m3s"  validate:"omitempty"`
 	DutyName         optional.Optional[string]  `json:"duty_name"`
+	DutyEmployeeID   optional.Optional[int64]   `json:"duty_employee_id"`
 	CapacityMwt      optional.Optional[float64] `json:"capacity_mwt"        validate:"omitempty"`
 	WeatherCondition optional.Optional[string]  `json:"weather_condition"`
 	TemperatureC     optional.Optional[float64] `json:"temperature_c"       validate:"omitempty"`
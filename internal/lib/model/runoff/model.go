@@ -0,0 +1,60 @@
+package runoff
+
+import "srmt-admin/internal/lib/stats"
+
+// Forecast methods. Regression is used when enough past seasons have both a
+// snow cover reading and the season inflow; otherwise the range falls back to
+// the historical distribution of season inflow (climatology).
+const (
+	MethodRegression  = "regression"
+	MethodClimatology = "climatology"
+)
+
+// SeasonSample is one past year: snow cover near the same day of year as the
+// forecast date and the inflow volume of that year's season.
+type SeasonSample struct {
+	Year     int      `json:"year"`
+	SnowDate *string  `json:"snow_date"`
+	Cover    *float64 `json:"cover"`
+	Inflow   float64  `json:"inflow"` // mln m³
+}
+
+// YearValue is a season inflow total for one year and the number of days
+// with data that went into it.
+type YearValue struct {
+	Year  int
+	Value float64
+	Days  int
+}
+
+// CoverReading is the modsnow cover of a basin on a date.
+type CoverReading struct {
+	Date  string
+	Cover float64
+}
+
+// Regression is the fit inflow = Intercept + Slope * cover over past seasons.
+type Regression = stats.Regression
+
+// Range is the expected season inflow, mln m³.
+type Range struct {
+	Low      float64 `json:"low"`
+	Expected float64 `json:"expected"`
+	High     float64 `json:"high"`
+}
+
+type Forecast struct {
+	ReservoirID    int            `json:"reservoir_id"`
+	Reservoir      string         `json:"reservoir"`
+	OrganizationID *int64         `json:"organization_id"`
+	AsOf           string         `json:"as_of"`
+	SeasonYear     int            `json:"season_year"`
+	SeasonFrom     string         `json:"season_from"`
+	SeasonTo       string         `json:"season_to"`
+	SnowDate       *string        `json:"snow_date"`
+	Cover          *float64       `json:"cover"`
+	Method         string         `json:"method"`
+	Regression     *Regression    `json:"regression,omitempty"`
+	Range          *Range         `json:"range"`
+	Samples        []SeasonSample `json:"samples"`
+}
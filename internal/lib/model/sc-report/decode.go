@@ -0,0 +1,42 @@
+package screport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// FieldError points the sender at the offending part of the payload.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string { return e.Field + ": " + e.Message }
+
+// Decode unmarshals body into report and validates it. Every failure is a
+// *FieldError naming the field (or "body" for syntax errors).
+func Decode(body []byte, report Report) error {
+	if len(body) == 0 {
+		return &FieldError{Field: "body", Message: "empty request body is not allowed"}
+	}
+
+	if err := json.Unmarshal(body, report); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			return &FieldError{Field: "body", Message: fmt.Sprintf("invalid JSON at offset %d: %s", syntaxErr.Offset, syntaxErr.Error())}
+		case errors.As(err, &typeErr):
+			field := typeErr.Field
+			if field == "" {
+				field = "body"
+			}
+			return &FieldError{Field: field, Message: fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value)}
+		default:
+			return &FieldError{Field: "body", Message: err.Error()}
+		}
+	}
+
+	return report.Validate()
+}
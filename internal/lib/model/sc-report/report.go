@@ -0,0 +1,266 @@
+// Package screport holds the typed payloads of the situation-center
+// callbacks (stock, modsnow, GES summary). Each payload is validated on
+// ingest, stored under its report date and compared with the previous
+// report when history is queried.
+package screport
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// Report is implemented by every callback payload.
+type Report interface {
+	ReportDate() string
+	// Values flattens the report into comparable numeric values keyed by a
+	// stable path, e.g. "Чирчик/q" for a stock row value.
+	Values() map[string]*float64
+	Validate() error
+}
+
+// --- Stock (сток) ---
+
+type StockReport struct {
+	Date string     `json:"date" bson:"report_date"`
+	Rows []StockRow `json:"rows" bson:"rows"`
+}
+
+// StockRow is one gauging point with its named values, e.g. {"q": 120.5}.
+// A null value means the parser found the cell empty.
+type StockRow struct {
+	Name   string              `json:"name" bson:"name"`
+	Values map[string]*float64 `json:"values" bson:"values"`
+}
+
+func (s StockReport) ReportDate() string { return s.Date }
+
+func (s StockReport) Values() map[string]*float64 {
+	out := make(map[string]*float64)
+	for _, row := range s.Rows {
+		for k, v := range row.Values {
+			out[row.Name+"/"+k] = v
+		}
+	}
+	return out
+}
+
+func (s StockReport) Validate() error {
+	if err := validateDate("date", s.Date, true); err != nil {
+		return err
+	}
+	if len(s.Rows) == 0 {
+		return &FieldError{Field: "rows", Message: "must contain at least one row"}
+	}
+	seen := make(map[string]struct{}, len(s.Rows))
+	for i, row := range s.Rows {
+		field := fmt.Sprintf("rows[%d]", i)
+		if row.Name == "" {
+			return &FieldError{Field: field + ".name", Message: "is required"}
+		}
+		if _, dup := seen[row.Name]; dup {
+			return &FieldError{Field: field + ".name", Message: fmt.Sprintf("duplicate row %q", row.Name)}
+		}
+		seen[row.Name] = struct{}{}
+		if len(row.Values) == 0 {
+			return &FieldError{Field: field + ".values", Message: "must contain at least one value"}
+		}
+		for k := range row.Values {
+			if k == "" {
+				return &FieldError{Field: field + ".values", Message: "value keys must not be empty"}
+			}
+		}
+	}
+	return nil
+}
+
+// --- Modsnow (снежный покров) ---
+
+type ModsnowReport struct {
+	Date         string             `json:"date" bson:"report_date"`
+	ResourceDate string             `json:"resource_date,omitempty" bson:"resource_date,omitempty"`
+	Catchments   []ModsnowCatchment `json:"catchments" bson:"catchments"`
+}
+
+type ModsnowCatchment struct {
+	Name   string        `json:"name" bson:"name"`
+	ScaPct *float64      `json:"sca_pct" bson:"sca_pct"`
+	Zones  []ModsnowZone `json:"zones,omitempty" bson:"zones,omitempty"`
+}
+
+type ModsnowZone struct {
+	MinElev int      `json:"min_elev" bson:"min_elev"`
+	MaxElev int      `json:"max_elev" bson:"max_elev"`
+	ScaPct  *float64 `json:"sca_pct" bson:"sca_pct"`
+}
+
+func (m ModsnowReport) ReportDate() string { return m.Date }
+
+func (m ModsnowReport) Values() map[string]*float64 {
+	out := make(map[string]*float64)
+	for _, c := range m.Catchments {
+		out[c.Name] = c.ScaPct
+		for _, z := range c.Zones {
+			out[fmt.Sprintf("%s/%d-%d", c.Name, z.MinElev, z.MaxElev)] = z.ScaPct
+		}
+	}
+	return out
+}
+
+func (m ModsnowReport) Validate() error {
+	if err := validateDate("date", m.Date, true); err != nil {
+		return err
+	}
+	if err := validateDate("resource_date", m.ResourceDate, false); err != nil {
+		return err
+	}
+	if len(m.Catchments) == 0 {
+		return &FieldError{Field: "catchments", Message: "must contain at least one catchment"}
+	}
+	seen := make(map[string]struct{}, len(m.Catchments))
+	for i, c := range m.Catchments {
+		field := fmt.Sprintf("catchments[%d]", i)
+		if c.Name == "" {
+			return &FieldError{Field: field + ".name", Message: "is required"}
+		}
+		if _, dup := seen[c.Name]; dup {
+			return &FieldError{Field: field + ".name", Message: fmt.Sprintf("duplicate catchment %q", c.Name)}
+		}
+		seen[c.Name] = struct{}{}
+		if err := validatePercent(field+".sca_pct", c.ScaPct); err != nil {
+			return err
+		}
+		for j, z := range c.Zones {
+			zf := fmt.Sprintf("%s.zones[%d]", field, j)
+			if z.MinElev >= z.MaxElev {
+				return &FieldError{Field: zf, Message: fmt.Sprintf("min_elev (%d) must be below max_elev (%d)", z.MinElev, z.MaxElev)}
+			}
+			if err := validatePercent(zf+".sca_pct", z.ScaPct); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// --- GES summary (выработка ГЭС) ---
+
+type GesSummaryReport struct {
+	Date                    string  `json:"date"`
+	TotalEnergyProduction   float64 `json:"total_energy_production"`
+	MonthlyEnergyProduction float64 `json:"monthly_energy_production"`
+	YearlyEnergyProduction  float64 `json:"yearly_energy_production"`
+}
+
+func (g GesSummaryReport) ReportDate() string { return g.Date }
+
+func (g GesSummaryReport) Values() map[string]*float64 {
+	total, monthly, yearly := g.TotalEnergyProduction, g.MonthlyEnergyProduction, g.YearlyEnergyProduction
+	return map[string]*float64{
+		"total_energy_production":   &total,
+		"monthly_energy_production": &monthly,
+		"yearly_energy_production":  &yearly,
+	}
+}
+
+func (g GesSummaryReport) Validate() error {
+	if err := validateDate("date", g.Date, true); err != nil {
+		return err
+	}
+	for _, f := range []struct {
+		name  string
+		value float64
+	}{
+		{"total_energy_production", g.TotalEnergyProduction},
+		{"monthly_energy_production", g.MonthlyEnergyProduction},
+		{"yearly_energy_production", g.YearlyEnergyProduction},
+	} {
+		if f.value < 0 {
+			return &FieldError{Field: f.name, Message: "must not be negative"}
+		}
+	}
+	return nil
+}
+
+// --- History ---
+
+// Change is one value of a report compared with the previous report. Delta
+// is nil when either side is missing.
+type Change struct {
+	Key      string   `json:"key"`
+	Value    *float64 `json:"value"`
+	Previous *float64 `json:"previous"`
+	Delta    *float64 `json:"delta"`
+}
+
+type HistoryEntry[T Report] struct {
+	Report       T        `json:"report"`
+	PreviousDate *string  `json:"previous_date"`
+	Changes      []Change `json:"changes"`
+}
+
+// BuildHistory pairs each report (ascending by date) with the one before it.
+// prev is the last report before the queried range, or nil.
+func BuildHistory[T Report](prev *T, reports []T) []HistoryEntry[T] {
+	out := make([]HistoryEntry[T], 0, len(reports))
+	for i := range reports {
+		entry := HistoryEntry[T]{Report: reports[i], Changes: []Change{}}
+		if prev != nil {
+			date := (*prev).ReportDate()
+			entry.PreviousDate = &date
+			entry.Changes = Diff(*prev, reports[i])
+		}
+		out = append(out, entry)
+		prev = &reports[i]
+	}
+	return out
+}
+
+// Diff compares every value present in either report, sorted by key.
+func Diff(prev, cur Report) []Change {
+	pv, cv := prev.Values(), cur.Values()
+
+	keys := make([]string, 0, len(cv))
+	for k := range cv {
+		keys = append(keys, k)
+	}
+	for k := range pv {
+		if _, ok := cv[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := make([]Change, 0, len(keys))
+	for _, k := range keys {
+		c := Change{Key: k, Value: cv[k], Previous: pv[k]}
+		if c.Value != nil && c.Previous != nil {
+			d := *c.Value - *c.Previous
+			c.Delta = &d
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+func validateDate(field, value string, required bool) error {
+	if value == "" {
+		if required {
+			return &FieldError{Field: field, Message: "is required (YYYY-MM-DD)"}
+		}
+		return nil
+	}
+	if _, err := time.Parse(dateLayout, value); err != nil {
+		return &FieldError{Field: field, Message: fmt.Sprintf("%q is not a valid date (YYYY-MM-DD)", value)}
+	}
+	return nil
+}
+
+func validatePercent(field string, v *float64) error {
+	if v != nil && (*v < 0 || *v > 100) {
+		return &FieldError{Field: field, Message: fmt.Sprintf("%v is outside 0..100", *v)}
+	}
+	return nil
+}
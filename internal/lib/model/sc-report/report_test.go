@@ -0,0 +1,79 @@
+package screport
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecode_PreciseErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		report    Report
+		wantField string
+	}{
+		{"empty", ``, &StockReport{}, "body"},
+		{"syntax", `{"date":`, &StockReport{}, "body"},
+		{"wrong type", `{"date":"2026-03-01","rows":[{"name":"A","values":{"q":"x"}}]}`, &StockReport{}, "rows.0.values.q"},
+		{"missing date", `{"rows":[{"name":"A","values":{"q":1}}]}`, &StockReport{}, "date"},
+		{"bad date", `{"date":"01.03.2026","rows":[{"name":"A","values":{"q":1}}]}`, &StockReport{}, "date"},
+		{"no rows", `{"date":"2026-03-01","rows":[]}`, &StockReport{}, "rows"},
+		{"duplicate row", `{"date":"2026-03-01","rows":[{"name":"A","values":{"q":1}},{"name":"A","values":{"q":2}}]}`, &StockReport{}, "rows[1].name"},
+		{"pct range", `{"date":"2026-03-01","catchments":[{"name":"Chirchik","sca_pct":140}]}`, &ModsnowReport{}, "catchments[0].sca_pct"},
+		{"zone order", `{"date":"2026-03-01","catchments":[{"name":"Chirchik","sca_pct":40,"zones":[{"min_elev":2000,"max_elev":1000,"sca_pct":1}]}]}`, &ModsnowReport{}, "catchments[0].zones[0]"},
+		{"negative ges", `{"date":"2026-03-01","total_energy_production":-1}`, &GesSummaryReport{}, "total_energy_production"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Decode([]byte(tt.body), tt.report)
+			var fe *FieldError
+			if !errors.As(err, &fe) {
+				t.Fatalf("want *FieldError, got %v", err)
+			}
+			if !strings.HasPrefix(fe.Field, tt.wantField) {
+				t.Errorf("want field %q, got %q (%s)", tt.wantField, fe.Field, fe.Message)
+			}
+		})
+	}
+}
+
+func TestDecode_Valid(t *testing.T) {
+	var m ModsnowReport
+	body := `{"date":"2026-03-01","resource_date":"2026-02-28","catchments":[{"name":"Chirchik","sca_pct":40.5,"zones":[{"min_elev":1000,"max_elev":2000,"sca_pct":20}]}]}`
+	if err := Decode([]byte(body), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Catchments[0].Zones[0].MaxElev != 2000 {
+		t.Errorf("zones not decoded: %+v", m)
+	}
+}
+
+func TestBuildHistory(t *testing.T) {
+	v := func(f float64) *float64 { return &f }
+	prev := StockReport{Date: "2026-02-28", Rows: []StockRow{{Name: "A", Values: map[string]*float64{"q": v(10)}}}}
+	reports := []StockReport{
+		{Date: "2026-03-01", Rows: []StockRow{{Name: "A", Values: map[string]*float64{"q": v(12)}}}},
+		{Date: "2026-03-02", Rows: []StockRow{{Name: "A", Values: map[string]*float64{"q": nil}}, {Name: "B", Values: map[string]*float64{"q": v(1)}}}},
+	}
+
+	h := BuildHistory(&prev, reports)
+	if len(h) != 2 {
+		t.Fatalf("want 2 entries, got %d", len(h))
+	}
+	if *h[0].PreviousDate != "2026-02-28" || *h[0].Changes[0].Delta != 2 {
+		t.Errorf("first entry: %+v", h[0])
+	}
+	if *h[1].PreviousDate != "2026-03-01" || len(h[1].Changes) != 2 {
+		t.Fatalf("second entry: %+v", h[1])
+	}
+	if h[1].Changes[0].Key != "A/q" || h[1].Changes[0].Delta != nil {
+		t.Errorf("missing value must have nil delta: %+v", h[1].Changes[0])
+	}
+
+	first := BuildHistory[StockReport](nil, reports[:1])
+	if first[0].PreviousDate != nil || len(first[0].Changes) != 0 {
+		t.Errorf("no previous report must yield no changes: %+v", first[0])
+	}
+}
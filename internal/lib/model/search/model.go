@@ -0,0 +1,90 @@
+package search
+
+import "time"
+
+// EntityLegalDocument is the entity type of legal documents. Registry
+// documents use their kind code.
+const EntityLegalDocument = "legal_document"
+
+// Attachment text extraction statuses
+const (
+	FileTextPending     = "pending"
+	FileTextExtracted   = "extracted"
+	FileTextUnsupported = "unsupported" // format has no extractor or file too large
+	FileTextFailed      = "failed"      // extraction kept failing; not retried
+)
+
+// Query is a full-text search request. Text uses web search syntax:
+// quoted phrases, OR and -exclusion.
+type Query struct {
+	Text           string
+	Kinds          []string
+	TypeID         *int
+	StatusID       *int
+	Year           *int
+	OrganizationID *int64
+	Limit          int
+	Offset         int
+}
+
+// Scope limits the entries the caller may see. Legal documents are visible
+// to everyone, as /legal-documents is; registry documents only with
+// Registry, and then within OrganizationIDs unless All.
+type Scope struct {
+	Registry        bool
+	All             bool
+	OrganizationIDs []int64
+}
+
+// Hit is one search result. TitleHighlight and Snippet mark matches with
+// <mark></mark>.
+type Hit struct {
+	EntityType       string     `json:"entity_type"`
+	EntityID         int64      `json:"entity_id"`
+	Title            string     `json:"title"`
+	TitleHighlight   string     `json:"title_highlight"`
+	Snippet          string     `json:"snippet,omitempty"`
+	Number           *string    `json:"number,omitempty"`
+	DocumentDate     *time.Time `json:"document_date,omitempty"`
+	TypeID           *int       `json:"type_id,omitempty"`
+	TypeName         *string    `json:"type_name,omitempty"`
+	StatusID         *int       `json:"status_id,omitempty"`
+	StatusName       *string    `json:"status_name,omitempty"`
+	OrganizationID   *int64     `json:"organization_id,omitempty"`
+	OrganizationName *string    `json:"organization_name,omitempty"`
+	Rank             float64    `json:"rank"`
+	Link             string     `json:"link"`
+}
+
+// FacetValue is a facet bucket. Types are keyed "<kind>:<type_id>" since
+// every kind has its own types.
+type FacetValue struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// Facets count the matches by kind, type, status and year. They ignore the
+// kind/type/status/year filters so the other buckets stay selectable.
+type Facets struct {
+	Kinds    []FacetValue `json:"kinds"`
+	Types    []FacetValue `json:"types"`
+	Statuses []FacetValue `json:"statuses"`
+	Years    []FacetValue `json:"years"`
+}
+
+type Result struct {
+	Total  int    `json:"total"`
+	Hits   []Hit  `json:"hits"`
+	Facets Facets `json:"facets"`
+}
+
+// PendingFile is an attachment waiting for text extraction.
+type PendingFile struct {
+	FileID    int64
+	FileName  string
+	ObjectKey string
+	MimeType  *string
+	SizeBytes *int64
+	Attempts  int
+}
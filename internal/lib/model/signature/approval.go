@@ -0,0 +1,83 @@
+package signature
+
+import (
+	"time"
+
+	"srmt-admin/internal/lib/model/user"
+)
+
+// Approval route statuses
+const (
+	RouteActive    = "active"
+	RouteCompleted = "completed"
+	RouteRejected  = "rejected"
+	RouteCancelled = "cancelled"
+)
+
+// Approval step statuses
+const (
+	StepPending  = "pending"
+	StepSigned   = "signed"
+	StepRejected = "rejected"
+)
+
+// ApprovalRoute is the ordered list of signers of a document. Steps with the
+// same Stage are signed in parallel.
+type ApprovalRoute struct {
+	ID           int64           `json:"id"`
+	DocumentType string          `json:"document_type"`
+	DocumentID   int64           `json:"document_id"`
+	Status       string          `json:"status"`
+	CurrentStage int             `json:"current_stage"`
+	CreatedBy    *user.ShortInfo `json:"created_by,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	CompletedAt  *time.Time      `json:"completed_at,omitempty"`
+	Steps        []ApprovalStep  `json:"steps"`
+}
+
+type ApprovalStep struct {
+	ID      int64           `json:"id"`
+	Stage   int             `json:"stage"`
+	Signer  user.ShortInfo  `json:"signer"`
+	DueDate *time.Time      `json:"due_date,omitempty"`
+	Status  string          `json:"status"`
+	ActedBy *user.ShortInfo `json:"acted_by,omitempty"`
+	ActedAt *time.Time      `json:"acted_at,omitempty"`
+	Comment *string         `json:"comment,omitempty"`
+}
+
+// CreateRouteRequest lists the stages in signing order.
+type CreateRouteRequest struct {
+	Stages []RouteStageInput `json:"stages" validate:"required,min=1,dive"`
+}
+
+type RouteStageInput struct {
+	Signers []RouteSignerInput `json:"signers" validate:"required,min=1,dive"`
+}
+
+type RouteSignerInput struct {
+	UserID  int64   `json:"user_id" validate:"required,min=1"`
+	DueDate *string `json:"due_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// Delegation lets DelegateUserID act on UserID's pending steps between
+// DateFrom and DateTo inclusive.
+type Delegation struct {
+	ID             int64     `json:"id"`
+	UserID         int64     `json:"user_id"`
+	UserName       *string   `json:"user_name,omitempty"`
+	DelegateUserID int64     `json:"delegate_user_id"`
+	DelegateName   *string   `json:"delegate_name,omitempty"`
+	DateFrom       string    `json:"date_from"`
+	DateTo         string    `json:"date_to"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// CreateDelegationRequest delegates the signing rights of UserID, or of the
+// caller when UserID is omitted.
+type CreateDelegationRequest struct {
+	UserID         *int64 `json:"user_id,omitempty" validate:"omitempty,min=1"`
+	DelegateUserID int64  `json:"delegate_user_id" validate:"required,min=1"`
+	DateFrom       string `json:"date_from" validate:"required,datetime=2006-01-02"`
+	DateTo         string `json:"date_to" validate:"required,datetime=2006-01-02"`
+}
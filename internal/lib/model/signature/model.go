@@ -41,6 +41,10 @@ type PendingDocument struct {
 	ResponsibleID   *int64    `json:"responsible_id,omitempty"`
 	CreatedAt       time.Time `json:"created_at"`
 	CreatedBy       *string   `json:"created_by,omitempty"`
+	// Set when the document has an active approval route: the stage awaiting
+	// the user and the earliest due date of their pending steps in it.
+	ApprovalStage *int       `json:"approval_stage,omitempty"`
+	StepDueDate   *time.Time `json:"step_due_date,omitempty"`
 }
 
 // SignatureAction constants
@@ -49,25 +53,11 @@ const (
 	ActionRejected = "rejected"
 )
 
-// DocumentType constants
+// Codes of the built-in document kinds. Further kinds are registered in the
+// document_kinds catalogue.
 const (
 	DocTypeDecree      = "decree"
 	DocTypeReport      = "report"
 	DocTypeLetter      = "letter"
 	DocTypeInstruction = "instruction"
 )
-
-// ValidDocumentTypes returns list of valid document types
-func ValidDocumentTypes() []string {
-	return []string{DocTypeDecree, DocTypeReport, DocTypeLetter, DocTypeInstruction}
-}
-
-// IsValidDocumentType checks if document type is valid
-func IsValidDocumentType(docType string) bool {
-	for _, t := range ValidDocumentTypes() {
-		if t == docType {
-			return true
-		}
-	}
-	return false
-}
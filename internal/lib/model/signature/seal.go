@@ -0,0 +1,153 @@
+package signature
+
+import (
+	"slices"
+	"time"
+
+	"srmt-admin/internal/lib/model/file"
+	"srmt-admin/internal/lib/model/user"
+)
+
+// DigestAlgorithm is the hash of attachments and of the manifest.
+const DigestAlgorithm = "sha256"
+
+// ContentFields are the key fields of a document covered by a seal. The
+// executor and due date are left out: the signature itself assigns them.
+type ContentFields struct {
+	Name                 string  `json:"name"`
+	Number               *string `json:"number"`
+	DocumentDate         string  `json:"document_date"` // YYYY-MM-DD
+	Description          *string `json:"description"`
+	TypeID               int     `json:"type_id"`
+	OrganizationID       *int64  `json:"organization_id"`
+	ResponsibleContactID *int64  `json:"responsible_contact_id"`
+	ParentDocumentID     *int64  `json:"parent_document_id"`
+}
+
+// Content is the current state of a document a seal is made from.
+type Content struct {
+	Fields ContentFields
+	Files  []file.Model // ordered by ID
+}
+
+// ManifestFile is an attachment as it was when the document was signed.
+type ManifestFile struct {
+	FileID    int64  `json:"file_id"`
+	FileName  string `json:"file_name"`
+	SizeBytes int64  `json:"size_bytes"`
+	SHA256    string `json:"sha256"`
+}
+
+// Manifest is what a signature covers. Its canonical form is its JSON
+// encoding; the seal digest is the SHA-256 of that.
+type Manifest struct {
+	DocumentType string         `json:"document_type"`
+	DocumentID   int64          `json:"document_id"`
+	Fields       ContentFields  `json:"fields"`
+	Files        []ManifestFile `json:"files"` // ordered by file ID
+	SignedBy     int64          `json:"signed_by"`
+	Resolution   *string        `json:"resolution"`
+}
+
+// Covers reports whether the manifest was made from content: same key
+// fields and the same attachments. Attachment bytes are not compared, the
+// objects behind a file ID are never rewritten.
+func (m Manifest) Covers(c Content) bool {
+	if !fieldsEqual(m.Fields, c.Fields) || len(m.Files) != len(c.Files) {
+		return false
+	}
+	for i, f := range c.Files {
+		if m.Files[i].FileID != f.ID {
+			return false
+		}
+	}
+	return true
+}
+
+func fieldsEqual(a, b ContentFields) bool {
+	return a.Name == b.Name && equalPtr(a.Number, b.Number) && a.DocumentDate == b.DocumentDate &&
+		equalPtr(a.Description, b.Description) && a.TypeID == b.TypeID &&
+		equalPtr(a.OrganizationID, b.OrganizationID) && equalPtr(a.ResponsibleContactID, b.ResponsibleContactID) &&
+		equalPtr(a.ParentDocumentID, b.ParentDocumentID)
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Seal binds a signature to the document content it was given on. Value and
+// Certificate are whatever the provider returned for the digest; the
+// built-in sha256 provider returns neither.
+type Seal struct {
+	Algorithm   string   `json:"algorithm"`
+	Digest      string   `json:"digest"` // hex
+	Manifest    Manifest `json:"manifest"`
+	Provider    string   `json:"provider"`
+	Value       *string  `json:"value,omitempty"`
+	Certificate *string  `json:"certificate,omitempty"`
+}
+
+// SealedSignature is a signing of a document with its seal, nil for
+// signatures made before sealing was introduced.
+type SealedSignature struct {
+	ID             int64
+	SignedBy       *user.ShortInfo
+	SignedAt       time.Time
+	ResolutionText *string
+	Seal           *Seal
+}
+
+// Seal check results
+const (
+	SealValid        = "valid"        // digest matches the manifest and the provider accepts it
+	SealInvalid      = "invalid"      // the stored seal was altered
+	SealUnverifiable = "unverifiable" // the provider that made it is not configured
+)
+
+// SignatureCheck compares one signature's manifest with the document now.
+type SignatureCheck struct {
+	SignatureID   int64           `json:"signature_id"`
+	SignedBy      *user.ShortInfo `json:"signed_by,omitempty"`
+	SignedAt      time.Time       `json:"signed_at"`
+	Provider      string          `json:"provider"`
+	Digest        string          `json:"digest"`
+	CurrentDigest string          `json:"current_digest"`
+	SealStatus    string          `json:"seal_status"`
+	// Intact is true when the document is unchanged since this signature.
+	Intact        bool           `json:"intact"`
+	ChangedFields []string       `json:"changed_fields"`
+	AddedFiles    []ManifestFile `json:"added_files"`
+	RemovedFiles  []ManifestFile `json:"removed_files"`
+	ModifiedFiles []ManifestFile `json:"modified_files"` // current state of files whose content differs
+}
+
+// Verification is the result of re-hashing a document against its seals.
+type Verification struct {
+	DocumentType string `json:"document_type"`
+	DocumentID   int64  `json:"document_id"`
+	// Sealed is false when no signature of the document carries a seal.
+	Sealed bool `json:"sealed"`
+	// Intact is true when the latest seal is valid and the document has
+	// not changed since it.
+	Intact     bool             `json:"intact"`
+	VerifiedAt time.Time        `json:"verified_at"`
+	Signatures []SignatureCheck `json:"signatures"`
+	// Unsealed counts signatures made before sealing was introduced.
+	Unsealed int `json:"unsealed"`
+}
+
+// SortFiles orders manifest files by file ID, the canonical order.
+func SortFiles(files []ManifestFile) {
+	slices.SortFunc(files, func(a, b ManifestFile) int {
+		switch {
+		case a.FileID < b.FileID:
+			return -1
+		case a.FileID > b.FileID:
+			return 1
+		}
+		return 0
+	})
+}
@@ -0,0 +1,18 @@
+package telegrambot
+
+import "time"
+
+// LinkCode is a one-time code the user sends to the bot to link a chat.
+type LinkCode struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// BotURL opens the bot with the code filled in; set when the bot
+	// username is configured
+	BotURL *string `json:"bot_url,omitempty"`
+}
+
+// Link is the state of the caller's Telegram link.
+type Link struct {
+	Linked   bool       `json:"linked"`
+	LinkedAt *time.Time `json:"linked_at,omitempty"`
+}
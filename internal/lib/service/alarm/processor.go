@@ -7,8 +7,12 @@ import (
 
 	"srmt-admin/internal/lib/dto"
 	"srmt-admin/internal/lib/model/asutp"
+	"srmt-admin/internal/lib/service/notification"
 )
 
+// ShutdownNotifyRoles are the roles told about shutdowns created from alarms
+var ShutdownNotifyRoles = []string{"sc"}
+
 // ShutdownManager provides methods to manage shutdowns
 type ShutdownManager interface {
 	AddShutdown(ctx context.Context, req dto.AddShutdownRequest, loc *time.Location) (int64, error)
@@ -25,18 +29,25 @@ type StateTracker interface {
 	ClearActiveShutdown(ctx context.Context, stationID int64, deviceID string) error
 }
 
+// Notifier tells the situation center about automatically created shutdowns
+type Notifier interface {
+	Notify(ctx context.Context, e notification.Event) error
+}
+
 // Processor handles automatic shutdown creation based on ASUTP alarms
 type Processor struct {
 	shutdownRepo ShutdownManager
 	stateTracker StateTracker
+	notifier     Notifier
 	log          *slog.Logger
 }
 
-// NewProcessor creates a new alarm processor
-func NewProcessor(shutdownRepo ShutdownManager, stateTracker StateTracker, log *slog.Logger) *Processor {
+// NewProcessor creates a new alarm processor; notifier may be nil
+func NewProcessor(shutdownRepo ShutdownManager, stateTracker StateTracker, notifier Notifier, log *slog.Logger) *Processor {
 	return &Processor{
 		shutdownRepo: shutdownRepo,
 		stateTracker: stateTracker,
+		notifier:     notifier,
 		log:          log,
 	}
 }
@@ -120,7 +131,23 @@ func (p *Processor) createShutdown(ctx context.Context, stationDBID int64, env *
 
 	// loc=nil disables backdate rotation. ASUTP-driven shutdowns use time.Now()
 	// for start_time, so backdate rotation is never applicable.
-	return p.shutdownRepo.AddShutdown(ctx, req, nil)
+	id, err := p.shutdownRepo.AddShutdown(ctx, req, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if p.notifier != nil {
+		err := p.notifier.Notify(ctx, notification.Event{
+			Type:    notification.ShutdownAutoCreated,
+			Roles:   ShutdownNotifyRoles,
+			Message: reason,
+			Link:    "/shutdowns",
+		})
+		if err != nil {
+			p.log.Warn("failed to notify about shutdown", "shutdown_id", id, "error", err)
+		}
+	}
+	return id, nil
 }
 
 // closeShutdown sets the end time on an existing shutdown
@@ -93,7 +93,7 @@ func TestProcessor_ProcessEnvelope_CreateShutdown(t *testing.T) {
 	shutdownMgr := &mockShutdownManager{}
 	stateTracker := newMockStateTracker()
 
-	processor := NewProcessor(shutdownMgr, stateTracker, log)
+	processor := NewProcessor(shutdownMgr, stateTracker, nil, log)
 
 	timestamp := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
 	env := &asutp.Envelope{
@@ -152,7 +152,7 @@ func TestProcessor_ProcessEnvelope_NoDuplicateShutdown(t *testing.T) {
 	// Simulate existing active shutdown
 	stateTracker.activeShutdowns[stateTracker.makeKey(32, "gen1")] = 100
 
-	processor := NewProcessor(shutdownMgr, stateTracker, log)
+	processor := NewProcessor(shutdownMgr, stateTracker, nil, log)
 
 	env := &asutp.Envelope{
 		ID:        "test-2",
@@ -184,7 +184,7 @@ func TestProcessor_ProcessEnvelope_CloseShutdown(t *testing.T) {
 	// Simulate existing active shutdown
 	stateTracker.activeShutdowns[stateTracker.makeKey(32, "gen1")] = 100
 
-	processor := NewProcessor(shutdownMgr, stateTracker, log)
+	processor := NewProcessor(shutdownMgr, stateTracker, nil, log)
 
 	endTime := time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)
 	env := &asutp.Envelope{
@@ -232,7 +232,7 @@ func TestProcessor_ProcessEnvelope_NoAction(t *testing.T) {
 	shutdownMgr := &mockShutdownManager{}
 	stateTracker := newMockStateTracker()
 
-	processor := NewProcessor(shutdownMgr, stateTracker, log)
+	processor := NewProcessor(shutdownMgr, stateTracker, nil, log)
 
 	// No alarms, no active shutdown - should do nothing
 	env := &asutp.Envelope{
@@ -268,7 +268,7 @@ func TestProcessor_ProcessEnvelope_RedisErrorOnGet(t *testing.T) {
 		return 0, errors.New("redis connection error")
 	}
 
-	processor := NewProcessor(shutdownMgr, stateTracker, log)
+	processor := NewProcessor(shutdownMgr, stateTracker, nil, log)
 
 	env := &asutp.Envelope{
 		ID:        "test-5",
@@ -301,7 +301,7 @@ func TestProcessor_ProcessEnvelope_ShutdownCreationError(t *testing.T) {
 	}
 	stateTracker := newMockStateTracker()
 
-	processor := NewProcessor(shutdownMgr, stateTracker, log)
+	processor := NewProcessor(shutdownMgr, stateTracker, nil, log)
 
 	env := &asutp.Envelope{
 		ID:        "test-6",
@@ -0,0 +1,278 @@
+// Package cascaderouting links the stations of a cascade upstream to
+// downstream and compares each station's inflow with the outflow of the
+// stations above it, shifted by the water travel time. A discrepancy beyond
+// the configured tolerance usually means a typo in ges_daily_data.
+package cascaderouting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+
+	model "srmt-admin/internal/lib/model/ges-report"
+)
+
+const dateLayout = "2006-01-02"
+
+// ErrInvalidTopology is returned by ReplaceTopology when the links do not
+// form a valid chain of the cascade's stations.
+var ErrInvalidTopology = errors.New("invalid cascade topology")
+
+type Repository interface {
+	GetCascadeConfigByOrgID(ctx context.Context, orgID int64) (*model.CascadeConfig, error)
+	GetCascadeStationIDs(ctx context.Context, cascadeOrgID int64) ([]int64, error)
+	GetCascadeTopology(ctx context.Context, cascadeOrgID int64) ([]model.TopologyLink, error)
+	ReplaceCascadeTopology(ctx context.Context, req model.ReplaceTopologyRequest) error
+	GetGESFlowSeries(ctx context.Context, orgIDs []int64, from, to string) ([]model.FlowRow, error)
+}
+
+type Service struct {
+	repo Repository
+	log  *slog.Logger
+}
+
+func NewService(repo Repository, log *slog.Logger) *Service {
+	return &Service{
+		repo: repo,
+		log:  log.With(slog.String("service", "cascade-routing")),
+	}
+}
+
+// Topology returns the cascade's links ordered upstream first. Returns
+// storage.ErrNotFound when the organization is not a cascade.
+func (s *Service) Topology(ctx context.Context, cascadeOrgID int64) (*model.CascadeTopology, error) {
+	const op = "service.cascade-routing.Topology"
+
+	if _, err := s.repo.GetCascadeConfigByOrgID(ctx, cascadeOrgID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	links, err := s.repo.GetCascadeTopology(ctx, cascadeOrgID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &model.CascadeTopology{CascadeOrgID: cascadeOrgID, Links: orderUpstreamFirst(links)}, nil
+}
+
+// ReplaceTopology validates req against the cascade's stations and stores it.
+// Every station must be a direct child of the cascade and appear once, a
+// downstream station must itself be listed, and the links must not loop.
+func (s *Service) ReplaceTopology(ctx context.Context, req model.ReplaceTopologyRequest) error {
+	const op = "service.cascade-routing.ReplaceTopology"
+
+	if _, err := s.repo.GetCascadeConfigByOrgID(ctx, req.CascadeOrgID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	stationIDs, err := s.repo.GetCascadeStationIDs(ctx, req.CascadeOrgID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := validateLinks(req.Links, stationIDs); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.repo.ReplaceCascadeTopology(ctx, req); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func validateLinks(links []model.TopologyLink, stationIDs []int64) error {
+	children := make(map[int64]bool, len(stationIDs))
+	for _, id := range stationIDs {
+		children[id] = true
+	}
+
+	next := make(map[int64]*int64, len(links))
+	for _, l := range links {
+		if !children[l.StationOrgID] {
+			return fmt.Errorf("%w: station %d does not belong to the cascade", ErrInvalidTopology, l.StationOrgID)
+		}
+		if _, dup := next[l.StationOrgID]; dup {
+			return fmt.Errorf("%w: station %d is listed twice", ErrInvalidTopology, l.StationOrgID)
+		}
+		next[l.StationOrgID] = l.DownstreamOrgID
+	}
+
+	for _, l := range links {
+		if l.DownstreamOrgID == nil {
+			continue
+		}
+		if _, ok := next[*l.DownstreamOrgID]; !ok {
+			return fmt.Errorf("%w: downstream %d of station %d is not listed", ErrInvalidTopology, *l.DownstreamOrgID, l.StationOrgID)
+		}
+	}
+
+	// Each station has at most one downstream, so walking the chain from any
+	// station must reach the end within len(links) steps.
+	for _, l := range links {
+		cur := l.DownstreamOrgID
+		for steps := 0; cur != nil; steps++ {
+			if *cur == l.StationOrgID || steps > len(links) {
+				return fmt.Errorf("%w: station %d is part of a loop", ErrInvalidTopology, l.StationOrgID)
+			}
+			cur = next[*cur]
+		}
+	}
+	return nil
+}
+
+// orderUpstreamFirst sorts links so every station comes before its
+// downstream. Stations at the same depth keep their stored order.
+func orderUpstreamFirst(links []model.TopologyLink) []model.TopologyLink {
+	next := make(map[int64]*int64, len(links))
+	for _, l := range links {
+		next[l.StationOrgID] = l.DownstreamOrgID
+	}
+	// distance to the mouth of the chain; bounded in case of stored loops.
+	depth := func(id int64) int {
+		d := 0
+		for cur := next[id]; cur != nil && d <= len(links); cur = next[*cur] {
+			d++
+		}
+		return d
+	}
+	ordered := append([]model.TopologyLink(nil), links...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return depth(ordered[i].StationOrgID) > depth(ordered[j].StationOrgID)
+	})
+	return ordered
+}
+
+// Routing builds the day-by-day comparison for [from, to]. For each station
+// the upstream outflow is the sum of its upstream stations' total outflow,
+// each shifted by that station's travel lag. Fractional-day lags interpolate
+// linearly between the two neighbouring days. Stations with no upstream, or
+// with any upstream value missing, are shown without a comparison.
+func (s *Service) Routing(ctx context.Context, cascadeOrgID int64, from, to time.Time) (*model.CascadeRouting, error) {
+	const op = "service.cascade-routing.Routing"
+
+	cfg, err := s.repo.GetCascadeConfigByOrgID(ctx, cascadeOrgID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	links, err := s.repo.GetCascadeTopology(ctx, cascadeOrgID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	links = orderUpstreamFirst(links)
+
+	result := &model.CascadeRouting{
+		CascadeOrgID: cascadeOrgID,
+		CascadeName:  cfg.OrganizationName,
+		Topology:     links,
+		Days:         []model.RoutingDay{},
+	}
+	if len(links) == 0 {
+		return result, nil
+	}
+
+	upstream := make(map[int64][]model.TopologyLink)
+	orgIDs := make([]int64, 0, len(links))
+	maxLag := 0.0
+	for _, l := range links {
+		orgIDs = append(orgIDs, l.StationOrgID)
+		if l.DownstreamOrgID != nil {
+			upstream[*l.DownstreamOrgID] = append(upstream[*l.DownstreamOrgID], l)
+			maxLag = math.Max(maxLag, l.LagHours)
+		}
+	}
+
+	// Outflows are needed from before from, as far back as the longest lag
+	// reaches (plus the day used for interpolation).
+	loadFrom := from.AddDate(0, 0, -(int(maxLag/24) + 1))
+	rows, err := s.repo.GetGESFlowSeries(ctx, orgIDs, loadFrom.Format(dateLayout), to.Format(dateLayout))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	flows := make(map[int64]map[string]model.FlowRow, len(links))
+	for _, row := range rows {
+		if flows[row.OrganizationID] == nil {
+			flows[row.OrganizationID] = make(map[string]model.FlowRow)
+		}
+		flows[row.OrganizationID][row.Date] = row
+	}
+
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format(dateLayout)
+		day := model.RoutingDay{Date: date, Stations: make([]model.RoutingStation, 0, len(links))}
+
+		for _, l := range links {
+			row := flows[l.StationOrgID][date]
+			st := model.RoutingStation{
+				OrganizationID:   l.StationOrgID,
+				OrganizationName: l.StationName,
+				UpstreamIDs:      []int64{},
+				InflowM3s:        row.InflowM3s,
+				OutflowM3s:       row.OutflowM3s,
+				TolerancePct:     l.TolerancePct,
+			}
+			ups := upstream[l.StationOrgID]
+			for _, u := range ups {
+				st.UpstreamIDs = append(st.UpstreamIDs, u.StationOrgID)
+			}
+			if len(ups) > 0 {
+				st.UpstreamOutflowM3s = routedOutflow(ups, flows, d)
+			}
+			compare(&st)
+			if st.Flag != nil {
+				result.Flagged++
+			}
+			day.Stations = append(day.Stations, st)
+		}
+		result.Days = append(result.Days, day)
+	}
+
+	return result, nil
+}
+
+// routedOutflow sums the outflow of the upstream stations as it arrives on
+// day d. A lag of k+f days (0 <= f < 1) blends the outflow of d-k and d-k-1.
+func routedOutflow(ups []model.TopologyLink, flows map[int64]map[string]model.FlowRow, d time.Time) *float64 {
+	total := 0.0
+	for _, u := range ups {
+		days := u.LagHours / 24
+		k := int(math.Floor(days))
+		f := days - float64(k)
+
+		near := flows[u.StationOrgID][d.AddDate(0, 0, -k).Format(dateLayout)].OutflowM3s
+		if near == nil {
+			return nil
+		}
+		v := *near
+		if f > 0 {
+			far := flows[u.StationOrgID][d.AddDate(0, 0, -k-1).Format(dateLayout)].OutflowM3s
+			if far == nil {
+				return nil
+			}
+			v = (1-f)*v + f*(*far)
+		}
+		total += v
+	}
+	return &total
+}
+
+// compare fills the imbalance and flag of st. The imbalance is inflow minus
+// routed upstream outflow; a positive value is an unexplained gain.
+func compare(st *model.RoutingStation) {
+	if st.InflowM3s == nil || st.UpstreamOutflowM3s == nil {
+		return
+	}
+	diff := *st.InflowM3s - *st.UpstreamOutflowM3s
+	st.ImbalanceM3s = &diff
+	if *st.UpstreamOutflowM3s == 0 {
+		return
+	}
+	pct := diff / *st.UpstreamOutflowM3s * 100
+	st.ImbalancePct = &pct
+	if math.Abs(pct) <= st.TolerancePct {
+		return
+	}
+	flag := model.RoutingLoss
+	if diff > 0 {
+		flag = model.RoutingGain
+	}
+	st.Flag = &flag
+}
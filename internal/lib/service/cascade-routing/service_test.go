@@ -0,0 +1,187 @@
+package cascaderouting
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"testing"
+	"time"
+
+	model "srmt-admin/internal/lib/model/ges-report"
+)
+
+type fakeRepo struct {
+	stations []int64
+	links    []model.TopologyLink
+	rows     []model.FlowRow
+	saved    *model.ReplaceTopologyRequest
+}
+
+func (f *fakeRepo) GetCascadeConfigByOrgID(_ context.Context, orgID int64) (*model.CascadeConfig, error) {
+	return &model.CascadeConfig{OrganizationID: orgID, OrganizationName: "Чирчикский каскад"}, nil
+}
+
+func (f *fakeRepo) GetCascadeStationIDs(_ context.Context, _ int64) ([]int64, error) {
+	return f.stations, nil
+}
+
+func (f *fakeRepo) GetCascadeTopology(_ context.Context, _ int64) ([]model.TopologyLink, error) {
+	return f.links, nil
+}
+
+func (f *fakeRepo) ReplaceCascadeTopology(_ context.Context, req model.ReplaceTopologyRequest) error {
+	f.saved = &req
+	return nil
+}
+
+func (f *fakeRepo) GetGESFlowSeries(_ context.Context, _ []int64, _, _ string) ([]model.FlowRow, error) {
+	return f.rows, nil
+}
+
+func discardLogger() *slog.Logger { return slog.New(slog.NewTextHandler(io.Discard, nil)) }
+
+func ptr[T any](v T) *T { return &v }
+
+func flow(orgID int64, date string, in, out float64) model.FlowRow {
+	return model.FlowRow{OrganizationID: orgID, Date: date, InflowM3s: &in, OutflowM3s: &out}
+}
+
+func TestReplaceTopology_Validation(t *testing.T) {
+	tests := []struct {
+		name  string
+		links []model.TopologyLink
+		ok    bool
+	}{
+		{"chain", []model.TopologyLink{
+			{StationOrgID: 1, DownstreamOrgID: ptr(int64(2)), TolerancePct: 10},
+			{StationOrgID: 2, TolerancePct: 10},
+		}, true},
+		{"foreign station", []model.TopologyLink{{StationOrgID: 9, TolerancePct: 10}}, false},
+		{"duplicate", []model.TopologyLink{{StationOrgID: 1, TolerancePct: 10}, {StationOrgID: 1, TolerancePct: 10}}, false},
+		{"unlisted downstream", []model.TopologyLink{{StationOrgID: 1, DownstreamOrgID: ptr(int64(3)), TolerancePct: 10}}, false},
+		{"loop", []model.TopologyLink{
+			{StationOrgID: 1, DownstreamOrgID: ptr(int64(2)), TolerancePct: 10},
+			{StationOrgID: 2, DownstreamOrgID: ptr(int64(3)), TolerancePct: 10},
+			{StationOrgID: 3, DownstreamOrgID: ptr(int64(1)), TolerancePct: 10},
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepo{stations: []int64{1, 2, 3}}
+			err := NewService(repo, discardLogger()).ReplaceTopology(context.Background(),
+				model.ReplaceTopologyRequest{CascadeOrgID: 100, Links: tt.links})
+			if tt.ok {
+				if err != nil || repo.saved == nil {
+					t.Fatalf("expected save, got err=%v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidTopology) || repo.saved != nil {
+				t.Fatalf("expected ErrInvalidTopology without save, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTopology_OrdersUpstreamFirst(t *testing.T) {
+	repo := &fakeRepo{links: []model.TopologyLink{
+		{StationOrgID: 3},
+		{StationOrgID: 2, DownstreamOrgID: ptr(int64(3))},
+		{StationOrgID: 1, DownstreamOrgID: ptr(int64(2))},
+	}}
+	topo, err := NewService(repo, discardLogger()).Topology(context.Background(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []int64{1, 2, 3} {
+		if topo.Links[i].StationOrgID != want {
+			t.Fatalf("position %d: got station %d, want %d", i, topo.Links[i].StationOrgID, want)
+		}
+	}
+}
+
+func TestRouting_LagAndFlags(t *testing.T) {
+	// 1 -> 2 with a 12 h lag; 2's inflow on day d should be the average of
+	// 1's outflow on d and d-1.
+	repo := &fakeRepo{
+		links: []model.TopologyLink{
+			{StationOrgID: 1, StationName: "Чарвак", DownstreamOrgID: ptr(int64(2)), LagHours: 12, TolerancePct: 10},
+			{StationOrgID: 2, StationName: "Ходжикент", TolerancePct: 10},
+		},
+		rows: []model.FlowRow{
+			flow(1, "2026-05-01", 0, 100),
+			flow(1, "2026-05-02", 0, 200),
+			flow(1, "2026-05-03", 0, 200),
+			flow(2, "2026-05-02", 155, 150), // routed 150: within tolerance
+			flow(2, "2026-05-03", 260, 250), // routed 200: +30% gain
+		},
+	}
+	from := time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+
+	res, err := NewService(repo, discardLogger()).Routing(context.Background(), 100, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Days) != 3 {
+		t.Fatalf("expected 3 days, got %d", len(res.Days))
+	}
+	if res.Flagged != 1 {
+		t.Fatalf("expected 1 flag, got %d", res.Flagged)
+	}
+
+	head := res.Days[0].Stations[0]
+	if head.UpstreamOutflowM3s != nil || head.Flag != nil {
+		t.Fatalf("headwater station must have no comparison: %+v", head)
+	}
+
+	d1 := res.Days[0].Stations[1]
+	if d1.UpstreamOutflowM3s == nil || math.Abs(*d1.UpstreamOutflowM3s-150) > 1e-9 {
+		t.Fatalf("day 1 routed outflow: got %v, want 150", d1.UpstreamOutflowM3s)
+	}
+	if d1.Flag != nil {
+		t.Fatalf("day 1 must not be flagged, got %s", *d1.Flag)
+	}
+
+	d2 := res.Days[1].Stations[1]
+	if d2.Flag == nil || *d2.Flag != model.RoutingGain {
+		t.Fatalf("day 2 must be flagged as gain: %+v", d2)
+	}
+	if math.Abs(*d2.ImbalancePct-30) > 1e-9 {
+		t.Fatalf("day 2 imbalance pct: got %v, want 30", *d2.ImbalancePct)
+	}
+
+	// Day 3 has no inflow for station 2: shown, but not compared.
+	d3 := res.Days[2].Stations[1]
+	if d3.InflowM3s != nil || d3.ImbalanceM3s != nil || d3.Flag != nil {
+		t.Fatalf("day 3 must have no comparison: %+v", d3)
+	}
+}
+
+func TestRouting_MissingUpstreamSkipsComparison(t *testing.T) {
+	repo := &fakeRepo{
+		links: []model.TopologyLink{
+			{StationOrgID: 1, DownstreamOrgID: ptr(int64(3)), LagHours: 24, TolerancePct: 10},
+			{StationOrgID: 2, DownstreamOrgID: ptr(int64(3)), TolerancePct: 10},
+			{StationOrgID: 3, TolerancePct: 10},
+		},
+		rows: []model.FlowRow{
+			flow(2, "2026-05-02", 0, 50),
+			flow(3, "2026-05-02", 10, 10),
+		},
+	}
+	day := time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC)
+	res, err := NewService(repo, discardLogger()).Routing(context.Background(), 100, day, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := res.Days[0].Stations[2]
+	if st.OrganizationID != 3 || len(st.UpstreamIDs) != 2 {
+		t.Fatalf("unexpected station: %+v", st)
+	}
+	if st.UpstreamOutflowM3s != nil || st.Flag != nil {
+		t.Fatalf("missing upstream outflow must skip comparison: %+v", st)
+	}
+}
@@ -0,0 +1,244 @@
+package damsafety
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"srmt-admin/internal/lib/model/filtration"
+	"srmt-admin/internal/lib/service/notification"
+)
+
+const dateLayout = "2006-01-02"
+
+// periodOf returns the schedule period containing d: the day itself, its ISO
+// week (Mon–Sun) or its calendar month.
+func periodOf(freq string, d time.Time) (start, end time.Time) {
+	switch freq {
+	case filtration.FrequencyWeekly:
+		offset := (int(d.Weekday()) + 6) % 7 // Monday = 0
+		start = d.AddDate(0, 0, -offset)
+		return start, start.AddDate(0, 0, 6)
+	case filtration.FrequencyMonthly:
+		start = time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, d.Location())
+		return start, start.AddDate(0, 1, -1)
+	default:
+		return d, d
+	}
+}
+
+// periodsEnding lists the periods of freq whose last day falls in [from, to].
+// Only finished periods can be missed, so a week or month still in progress
+// at to is not reported.
+func periodsEnding(freq string, from, to time.Time) [][2]time.Time {
+	var result [][2]time.Time
+	start, end := periodOf(freq, from)
+	for !end.After(to) {
+		if !end.Before(from) {
+			result = append(result, [2]time.Time{start, end})
+		}
+		start = end.AddDate(0, 0, 1)
+		_, end = periodOf(freq, start)
+	}
+	return result
+}
+
+// effectiveFrequencies resolves the schedule of every instrument: its own
+// override, otherwise the organization default. Instruments without either
+// are not tracked and are absent from the map.
+func effectiveFrequencies(schedules []filtration.Schedule) (orgDefault string, byInstrument map[string]string) {
+	byInstrument = make(map[string]string)
+	for _, s := range schedules {
+		switch {
+		case s.LocationID != nil:
+			byInstrument[instrumentKey(filtration.InstrumentLocation, *s.LocationID)] = s.Frequency
+		case s.PiezometerID != nil:
+			byInstrument[instrumentKey(filtration.InstrumentPiezometer, *s.PiezometerID)] = s.Frequency
+		default:
+			orgDefault = s.Frequency
+		}
+	}
+	return orgDefault, byInstrument
+}
+
+func instrumentKey(kind string, id int64) string {
+	return fmt.Sprintf("%s:%d", kind, id)
+}
+
+// Missing returns, for every organization in orgIDs (all filtration
+// organizations when nil), the schedule periods ending in [from, to] in which
+// an instrument got no reading. Periods that ended before the instrument was
+// created are skipped.
+func (s *Service) Missing(ctx context.Context, orgIDs []int64, from, to string) ([]filtration.MissingReading, error) {
+	const op = "service.damsafety.Missing"
+
+	fromDate, err := time.ParseInLocation(dateLayout, from, s.loc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid from: %w", op, err)
+	}
+	toDate, err := time.ParseInLocation(dateLayout, to, s.loc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid to: %w", op, err)
+	}
+
+	if orgIDs == nil {
+		if orgIDs, err = s.repo.GetFiltrationOrgIDs(ctx); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	// The longest period (a month) can start before from; read dates are
+	// loaded from there so a reading early in that period still counts.
+	readFrom, _ := periodOf(filtration.FrequencyMonthly, fromDate)
+	if weekStart, _ := periodOf(filtration.FrequencyWeekly, fromDate); weekStart.Before(readFrom) {
+		readFrom = weekStart
+	}
+
+	result := make([]filtration.MissingReading, 0)
+	for _, orgID := range orgIDs {
+		schedules, err := s.repo.GetFiltrationSchedules(ctx, orgID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: schedules org=%d: %w", op, orgID, err)
+		}
+		if len(schedules) == 0 {
+			continue
+		}
+		orgDefault, overrides := effectiveFrequencies(schedules)
+
+		summary, err := s.repo.GetOrgFiltrationSummary(ctx, orgID, to)
+		if err != nil {
+			return nil, fmt.Errorf("%s: summary org=%d: %w", op, orgID, err)
+		}
+
+		reads, err := s.repo.GetFiltrationReadDates(ctx, orgID, readFrom.Format(dateLayout), to)
+		if err != nil {
+			return nil, fmt.Errorf("%s: read dates org=%d: %w", op, orgID, err)
+		}
+		readDays := make(map[string][]time.Time)
+		for _, rd := range reads {
+			d, err := time.ParseInLocation(dateLayout, rd.Date, s.loc)
+			if err != nil {
+				continue
+			}
+			key := instrumentKey(rd.InstrumentType, rd.InstrumentID)
+			readDays[key] = append(readDays[key], d)
+		}
+
+		check := func(kind string, id int64, name string, created time.Time) {
+			key := instrumentKey(kind, id)
+			freq, ok := overrides[key]
+			if !ok {
+				freq = orgDefault
+			}
+			if freq == "" {
+				return
+			}
+			createdDay := time.Date(created.In(s.loc).Year(), created.In(s.loc).Month(), created.In(s.loc).Day(), 0, 0, 0, 0, s.loc)
+			for _, p := range periodsEnding(freq, fromDate, toDate) {
+				if p[1].Before(createdDay) || hasReadIn(readDays[key], p[0], p[1]) {
+					continue
+				}
+				result = append(result, filtration.MissingReading{
+					OrganizationID:   orgID,
+					OrganizationName: summary.OrganizationName,
+					InstrumentType:   kind,
+					InstrumentID:     id,
+					InstrumentName:   name,
+					Frequency:        freq,
+					PeriodStart:      p[0].Format(dateLayout),
+					PeriodEnd:        p[1].Format(dateLayout),
+				})
+			}
+		}
+
+		for _, l := range summary.Locations {
+			check(filtration.InstrumentLocation, l.ID, l.Name, l.CreatedAt)
+		}
+		for _, p := range summary.Piezometers {
+			check(filtration.InstrumentPiezometer, p.ID, p.Name, p.CreatedAt)
+		}
+	}
+
+	return result, nil
+}
+
+func hasReadIn(days []time.Time, start, end time.Time) bool {
+	for _, d := range days {
+		if !d.Before(start) && !d.After(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemindMissing notifies the reservoir staff of each organization about the
+// periods that ended on date without a reading. Daily periods end every day,
+// weekly ones on Sunday and monthly ones on the last day of the month, so a
+// single call per day covers all schedules. Each recipient gets one
+// notification per organization.
+func (s *Service) RemindMissing(ctx context.Context, date string) {
+	if s.notifier == nil {
+		return
+	}
+
+	missing, err := s.Missing(ctx, nil, date, date)
+	if err != nil {
+		s.log.Error("failed to detect missing readings", slog.String("date", date), slog.String("error", err.Error()))
+		return
+	}
+
+	byOrg := make(map[int64][]filtration.MissingReading)
+	var orgOrder []int64
+	for _, m := range missing {
+		if _, ok := byOrg[m.OrganizationID]; !ok {
+			orgOrder = append(orgOrder, m.OrganizationID)
+		}
+		byOrg[m.OrganizationID] = append(byOrg[m.OrganizationID], m)
+	}
+
+	var sent, failed int
+	for _, orgID := range orgOrder {
+		items := byOrg[orgID]
+
+		recipients, err := s.repo.GetFiltrationReminderRecipients(ctx, orgID)
+		if err != nil {
+			s.log.Error("failed to get reminder recipients",
+				slog.Int64("organization_id", orgID),
+				slog.String("error", err.Error()))
+			failed++
+			continue
+		}
+		if len(recipients) == 0 {
+			s.log.Warn("no recipients for missing-reading reminder", slog.Int64("organization_id", orgID))
+			continue
+		}
+
+		names := make([]string, 0, len(items))
+		for _, m := range items {
+			names = append(names, m.InstrumentName)
+		}
+		err = s.notifier.Notify(ctx, notification.Event{
+			Type:     notification.FiltrationReadingsMissing,
+			Contacts: recipients,
+			Message: fmt.Sprintf("%s: нет замеров за период, закончившийся %s — %s",
+				items[0].OrganizationName, date, strings.Join(names, ", ")),
+			Link: "/filtration",
+		})
+		if err != nil {
+			s.log.Error("failed to create reminder",
+				slog.Int64("organization_id", orgID),
+				slog.String("error", err.Error()))
+			failed++
+			continue
+		}
+		sent += len(recipients)
+	}
+
+	s.log.Info("missing-reading reminders completed",
+		slog.String("date", date),
+		slog.Int("missing", len(missing)),
+		slog.Int("sent", sent),
+		slog.Int("failed", failed))
+}
@@ -0,0 +1,129 @@
+package damsafety
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"srmt-admin/internal/lib/model/filtration"
+)
+
+func day(s string) time.Time {
+	d, _ := time.Parse(dateLayout, s)
+	return d
+}
+
+func TestPeriodsEnding(t *testing.T) {
+	tests := []struct {
+		freq     string
+		from, to string
+		want     []string // period ends
+	}{
+		{filtration.FrequencyDaily, "2026-03-01", "2026-03-03", []string{"2026-03-01", "2026-03-02", "2026-03-03"}},
+		// 2026-03-01 is a Sunday; the week of 03-02 is unfinished on 03-07.
+		{filtration.FrequencyWeekly, "2026-03-01", "2026-03-07", []string{"2026-03-01"}},
+		{filtration.FrequencyWeekly, "2026-03-02", "2026-03-15", []string{"2026-03-08", "2026-03-15"}},
+		{filtration.FrequencyMonthly, "2026-02-15", "2026-03-30", []string{"2026-02-28"}},
+		{filtration.FrequencyMonthly, "2026-03-31", "2026-03-31", []string{"2026-03-31"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.freq+" "+tt.from+".."+tt.to, func(t *testing.T) {
+			got := periodsEnding(tt.freq, day(tt.from), day(tt.to))
+			if len(got) != len(tt.want) {
+				t.Fatalf("want %d periods, got %d (%v)", len(tt.want), len(got), got)
+			}
+			for i, p := range got {
+				if end := p[1].Format(dateLayout); end != tt.want[i] {
+					t.Errorf("period %d: want end %s, got %s", i, tt.want[i], end)
+				}
+			}
+		})
+	}
+}
+
+func TestMissing_UsesOverridesAndIgnoresUntracked(t *testing.T) {
+	loc1, piezo9 := int64(1), int64(9)
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &fakeRepo{
+		summary: &filtration.OrgFiltrationSummary{
+			OrganizationID:   7,
+			OrganizationName: "Чарвак",
+			Locations: []filtration.LocationReading{
+				{Location: filtration.Location{ID: 1, Name: "L1", CreatedAt: created}},
+				{Location: filtration.Location{ID: 2, Name: "L2", CreatedAt: created}},
+				{Location: filtration.Location{ID: 3, Name: "L3 new", CreatedAt: time.Date(2026, 3, 3, 10, 0, 0, 0, time.UTC)}},
+			},
+			Piezometers: []filtration.PiezoReading{
+				{Piezometer: filtration.Piezometer{ID: 9, Name: "P9", CreatedAt: created}},
+			},
+		},
+		schedules: []filtration.Schedule{
+			{OrganizationID: 7, Frequency: filtration.FrequencyDaily},
+			{OrganizationID: 7, LocationID: &loc1, Frequency: filtration.FrequencyWeekly},
+			{OrganizationID: 7, PiezometerID: &piezo9, Frequency: filtration.FrequencyMonthly},
+		},
+		reads: []filtration.ReadDate{
+			{InstrumentType: filtration.InstrumentLocation, InstrumentID: 2, Date: "2026-03-02"},
+			{InstrumentType: filtration.InstrumentLocation, InstrumentID: 1, Date: "2026-03-03"},
+		},
+	}
+	svc := NewService(repo, repo, time.UTC, discardLogger())
+
+	got, err := svc.Missing(context.Background(), []int64{7}, "2026-03-02", "2026-03-04")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// L1 is weekly (week not finished), P9 monthly (month not finished).
+	// L2 daily: read on 03-02, missed 03-03 and 03-04. L3 created on 03-03.
+	want := map[string]bool{"L2 2026-03-03": true, "L2 2026-03-04": true, "L3 new 2026-03-03": true, "L3 new 2026-03-04": true}
+	if len(got) != len(want) {
+		t.Fatalf("want %d missing, got %d: %+v", len(want), len(got), got)
+	}
+	for _, m := range got {
+		if !want[m.InstrumentName+" "+m.PeriodEnd] {
+			t.Errorf("unexpected missing reading %s %s", m.InstrumentName, m.PeriodEnd)
+		}
+	}
+}
+
+func TestMissing_NoScheduleMeansNotTracked(t *testing.T) {
+	repo := &fakeRepo{
+		summary: &filtration.OrgFiltrationSummary{
+			Locations: []filtration.LocationReading{{Location: filtration.Location{ID: 1, Name: "L1"}}},
+		},
+		orgIDs: []int64{7},
+	}
+	svc := NewService(repo, repo, time.UTC, discardLogger())
+
+	got, err := svc.Missing(context.Background(), nil, "2026-03-01", "2026-03-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("want nothing without a schedule, got %+v", got)
+	}
+}
+
+func TestRemindMissing_OneNotificationPerRecipient(t *testing.T) {
+	repo := &fakeRepo{
+		summary: &filtration.OrgFiltrationSummary{
+			OrganizationName: "Чарвак",
+			Locations: []filtration.LocationReading{
+				{Location: filtration.Location{ID: 1, Name: "L1"}},
+				{Location: filtration.Location{ID: 2, Name: "L2"}},
+			},
+		},
+		orgIDs:    []int64{7},
+		schedules: []filtration.Schedule{{OrganizationID: 7, Frequency: filtration.FrequencyDaily}},
+		contacts:  []int64{100, 200},
+	}
+	svc := NewService(repo, repo, time.UTC, discardLogger())
+
+	svc.RemindMissing(context.Background(), "2026-03-04")
+
+	if len(repo.notified) != 2 {
+		t.Fatalf("want one notification per recipient, got %v", repo.notified)
+	}
+}
@@ -0,0 +1,430 @@
+// Package damsafety provides dam-safety analytics over filtration and
+// piezometer measurements: per-instrument time series with moving averages,
+// regression against reservoir level, and automatic flagging of readings
+// that exceed the instrument norm or leave the statistically expected band.
+// Flags are persisted as filtration_alerts and summarised in a dashboard.
+package damsafety
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"srmt-admin/internal/lib/model/filtration"
+	"srmt-admin/internal/lib/service/notification"
+	"srmt-admin/internal/lib/stats"
+)
+
+const (
+	// DefaultWindow is the moving-average window (in readings) used when
+	// the caller does not pass one.
+	DefaultWindow = 7
+
+	// lookbackDays is how much history feeds the level regression.
+	lookbackDays = 3 * 365
+	// minSamples is the minimum number of (level, value) pairs needed
+	// before a regression band is trusted.
+	minSamples = 10
+	// bandSigma is the half-width of the expected band in residual σ.
+	bandSigma = 2.0
+)
+
+type Repository interface {
+	GetFiltrationLocationByID(ctx context.Context, id int64) (*filtration.Location, error)
+	GetPiezometerByID(ctx context.Context, id int64) (*filtration.Piezometer, error)
+	GetFiltrationSeries(ctx context.Context, locationID int64, from, to string) ([]filtration.SeriesRow, error)
+	GetPiezometerSeries(ctx context.Context, piezometerID int64, from, to string) ([]filtration.SeriesRow, error)
+	GetOrgFiltrationSummary(ctx context.Context, orgID int64, date string) (*filtration.OrgFiltrationSummary, error)
+	GetReservoirLevelVolume(ctx context.Context, orgID int64, date string) (*float64, *float64, error)
+	GetFiltrationOrgIDs(ctx context.Context) ([]int64, error)
+	ReplaceFiltrationAlerts(ctx context.Context, orgID int64, date string, alerts []filtration.Alert) error
+	GetFiltrationAlerts(ctx context.Context, f filtration.AlertFilter) ([]filtration.Alert, error)
+	GetFiltrationSchedules(ctx context.Context, orgID int64) ([]filtration.Schedule, error)
+	GetFiltrationReadDates(ctx context.Context, orgID int64, from, to string) ([]filtration.ReadDate, error)
+	GetFiltrationReminderRecipients(ctx context.Context, orgID int64) ([]int64, error)
+}
+
+// Notifier delivers missing-reading reminders
+type Notifier interface {
+	Notify(ctx context.Context, e notification.Event) error
+}
+
+type Service struct {
+	repo     Repository
+	notifier Notifier
+	loc      *time.Location
+	log      *slog.Logger
+	runHour  int
+}
+
+func NewService(repo Repository, notifier Notifier, loc *time.Location, log *slog.Logger) *Service {
+	return &Service{
+		repo:     repo,
+		notifier: notifier,
+		loc:      loc,
+		log:      log.With(slog.String("service", "damsafety")),
+		runHour:  6, // after day rotation, once reservoir_data for yesterday is in
+	}
+}
+
+// instrument is the common view of a location or piezometer.
+type instrument struct {
+	kind  string
+	id    int64
+	orgID int64
+	name  string
+	norm  *float64
+}
+
+func (s *Service) getInstrument(ctx context.Context, kind string, id int64) (*instrument, error) {
+	switch kind {
+	case filtration.InstrumentLocation:
+		l, err := s.repo.GetFiltrationLocationByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &instrument{kind: kind, id: l.ID, orgID: l.OrganizationID, name: l.Name, norm: l.Norm}, nil
+	case filtration.InstrumentPiezometer:
+		p, err := s.repo.GetPiezometerByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &instrument{kind: kind, id: p.ID, orgID: p.OrganizationID, name: p.Name, norm: p.Norm}, nil
+	default:
+		return nil, fmt.Errorf("unknown instrument type %q", kind)
+	}
+}
+
+func (s *Service) series(ctx context.Context, kind string, id int64, from, to string) ([]filtration.SeriesRow, error) {
+	if kind == filtration.InstrumentLocation {
+		return s.repo.GetFiltrationSeries(ctx, id, from, to)
+	}
+	return s.repo.GetPiezometerSeries(ctx, id, from, to)
+}
+
+// InstrumentOrgID returns the organization owning an instrument so handlers
+// can authorize before reading its series.
+func (s *Service) InstrumentOrgID(ctx context.Context, kind string, id int64) (int64, error) {
+	const op = "service.damsafety.InstrumentOrgID"
+
+	inst, err := s.getInstrument(ctx, kind, id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return inst.orgID, nil
+}
+
+// Trend returns the [from, to] series of one instrument with a trailing
+// moving average and, when enough history is available, the regression of the
+// reading against reservoir level with its expected band and per-point flags.
+// The regression is fitted on the displayed range plus lookbackDays before it.
+func (s *Service) Trend(ctx context.Context, kind string, id int64, from, to string, window int) (*filtration.InstrumentTrend, error) {
+	const op = "service.damsafety.Trend"
+
+	if window < 1 {
+		window = DefaultWindow
+	}
+
+	inst, err := s.getInstrument(ctx, kind, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	fromDate, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid from: %w", op, err)
+	}
+	historyFrom := fromDate.AddDate(0, 0, -lookbackDays).Format("2006-01-02")
+
+	rows, err := s.series(ctx, kind, id, historyFrom, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	xs, ys := pairs(rows)
+	reg := stats.FitLinear(xs, ys, minSamples)
+
+	values := make([]*float64, len(rows))
+	for i := range rows {
+		values[i] = rows[i].Value
+	}
+	avgs := movingAverage(values, window)
+
+	points := make([]filtration.TrendPoint, 0, len(rows))
+	for i, row := range rows {
+		if row.Date < from {
+			continue
+		}
+		p := filtration.TrendPoint{
+			Date:           row.Date,
+			Value:          row.Value,
+			ReservoirLevel: row.ReservoirLevel,
+			MovingAvg:      avgs[i],
+			Flags:          []string{},
+		}
+		if reg != nil && row.ReservoirLevel != nil {
+			expected, low, high := expectedBand(reg, *row.ReservoirLevel, bandSigma)
+			p.Expected, p.BandLow, p.BandHigh = &expected, &low, &high
+		}
+		if row.Value != nil {
+			p.Flags = flagsFor(*row.Value, inst.norm, reg, row.ReservoirLevel, bandSigma)
+		}
+		points = append(points, p)
+	}
+
+	return &filtration.InstrumentTrend{
+		OrganizationID: inst.orgID,
+		InstrumentType: kind,
+		InstrumentID:   id,
+		InstrumentName: inst.name,
+		Norm:           inst.norm,
+		Window:         window,
+		Regression:     reg,
+		Points:         points,
+	}, nil
+}
+
+// Evaluate flags the readings of one organization on one date and rebuilds
+// that date's open alerts. The expected band for each instrument is fitted on
+// the lookbackDays before date, so the evaluated reading never pulls its own
+// band towards itself. Returns the alerts raised.
+func (s *Service) Evaluate(ctx context.Context, orgID int64, date string) ([]filtration.Alert, error) {
+	const op = "service.damsafety.Evaluate"
+
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid date: %w", op, err)
+	}
+	historyFrom := day.AddDate(0, 0, -lookbackDays).Format("2006-01-02")
+	historyTo := day.AddDate(0, 0, -1).Format("2006-01-02")
+
+	summary, err := s.repo.GetOrgFiltrationSummary(ctx, orgID, date)
+	if err != nil {
+		return nil, fmt.Errorf("%s: summary: %w", op, err)
+	}
+
+	level, _, err := s.repo.GetReservoirLevelVolume(ctx, orgID, date)
+	if err != nil {
+		return nil, fmt.Errorf("%s: reservoir level: %w", op, err)
+	}
+
+	type reading struct {
+		inst  instrument
+		value float64
+	}
+	var readings []reading
+	for _, l := range summary.Locations {
+		if l.FlowRate != nil {
+			readings = append(readings, reading{
+				inst:  instrument{kind: filtration.InstrumentLocation, id: l.ID, orgID: orgID, name: l.Name, norm: l.Norm},
+				value: *l.FlowRate,
+			})
+		}
+	}
+	for _, p := range summary.Piezometers {
+		if p.Level != nil {
+			readings = append(readings, reading{
+				inst:  instrument{kind: filtration.InstrumentPiezometer, id: p.ID, orgID: orgID, name: p.Name, norm: p.Norm},
+				value: *p.Level,
+			})
+		}
+	}
+
+	alerts := make([]filtration.Alert, 0)
+	for _, rd := range readings {
+		var reg *filtration.Regression
+		if level != nil {
+			rows, err := s.series(ctx, rd.inst.kind, rd.inst.id, historyFrom, historyTo)
+			if err != nil {
+				return nil, fmt.Errorf("%s: history %s/%d: %w", op, rd.inst.kind, rd.inst.id, err)
+			}
+			xs, ys := pairs(rows)
+			reg = stats.FitLinear(xs, ys, minSamples)
+		}
+
+		flags := flagsFor(rd.value, rd.inst.norm, reg, level, bandSigma)
+		if len(flags) == 0 {
+			continue
+		}
+
+		base := filtration.Alert{
+			OrganizationID:   orgID,
+			OrganizationName: summary.OrganizationName,
+			InstrumentType:   rd.inst.kind,
+			InstrumentID:     rd.inst.id,
+			InstrumentName:   rd.inst.name,
+			Date:             date,
+			Value:            rd.value,
+			ReservoirLevel:   level,
+			Norm:             rd.inst.norm,
+		}
+		if reg != nil && level != nil {
+			expected, low, high := expectedBand(reg, *level, bandSigma)
+			base.Expected, base.BandLow, base.BandHigh = &expected, &low, &high
+		}
+		for _, reason := range flags {
+			a := base
+			a.Reason = reason
+			alerts = append(alerts, a)
+		}
+	}
+
+	if err := s.repo.ReplaceFiltrationAlerts(ctx, orgID, date, alerts); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(alerts) > 0 {
+		s.log.Warn("dam-safety alerts raised",
+			slog.Int64("organization_id", orgID),
+			slog.String("date", date),
+			slog.Int("alerts", len(alerts)))
+	}
+
+	return alerts, nil
+}
+
+// Dashboard summarises, for every organization with filtration instruments
+// (restricted to orgIDs when non-nil), how many instruments were read on date
+// and which alerts are still unacknowledged.
+func (s *Service) Dashboard(ctx context.Context, date string, orgIDs []int64) (*filtration.Dashboard, error) {
+	const op = "service.damsafety.Dashboard"
+
+	allOrgIDs, err := s.repo.GetFiltrationOrgIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if orgIDs != nil {
+		allowed := make(map[int64]struct{}, len(orgIDs))
+		for _, id := range orgIDs {
+			allowed[id] = struct{}{}
+		}
+		filtered := make([]int64, 0, len(allOrgIDs))
+		for _, id := range allOrgIDs {
+			if _, ok := allowed[id]; ok {
+				filtered = append(filtered, id)
+			}
+		}
+		allOrgIDs = filtered
+	}
+
+	open, err := s.repo.GetFiltrationAlerts(ctx, filtration.AlertFilter{
+		OrganizationIDs: allOrgIDs,
+		OnlyOpen:        true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: alerts: %w", op, err)
+	}
+	openByOrg := make(map[int64][]filtration.Alert)
+	for _, a := range open {
+		openByOrg[a.OrganizationID] = append(openByOrg[a.OrganizationID], a)
+	}
+
+	result := &filtration.Dashboard{
+		Date:          date,
+		Organizations: make([]filtration.DashboardOrg, 0, len(allOrgIDs)),
+	}
+	for _, orgID := range allOrgIDs {
+		summary, err := s.repo.GetOrgFiltrationSummary(ctx, orgID, date)
+		if err != nil {
+			return nil, fmt.Errorf("%s: summary org=%d: %w", op, orgID, err)
+		}
+		level, _, err := s.repo.GetReservoirLevelVolume(ctx, orgID, date)
+		if err != nil {
+			return nil, fmt.Errorf("%s: level org=%d: %w", op, orgID, err)
+		}
+
+		row := filtration.DashboardOrg{
+			OrganizationID:   orgID,
+			OrganizationName: summary.OrganizationName,
+			ReservoirLevel:   level,
+			Instruments:      len(summary.Locations) + len(summary.Piezometers),
+			Alerts:           openByOrg[orgID],
+		}
+		for _, l := range summary.Locations {
+			if l.FlowRate != nil {
+				row.Measured++
+			}
+		}
+		for _, p := range summary.Piezometers {
+			if p.Level != nil {
+				row.Measured++
+			}
+		}
+		if row.Alerts == nil {
+			row.Alerts = []filtration.Alert{}
+		}
+		for _, a := range row.Alerts {
+			switch a.Reason {
+			case filtration.AlertReasonNormExceeded:
+				row.NormExceeded++
+			case filtration.AlertReasonOutOfBand:
+				row.OutOfBand++
+			}
+		}
+		row.OpenAlerts = len(row.Alerts)
+		result.TotalOpen += row.OpenAlerts
+		result.Organizations = append(result.Organizations, row)
+	}
+
+	return result, nil
+}
+
+// Sweep re-evaluates every filtration organization for date. Readings saved
+// before the day's reservoir level was entered get their band check here.
+func (s *Service) Sweep(ctx context.Context, date string) {
+	orgIDs, err := s.repo.GetFiltrationOrgIDs(ctx)
+	if err != nil {
+		s.log.Error("failed to list filtration organizations", slog.String("error", err.Error()))
+		return
+	}
+
+	var raised, failed int
+	for _, orgID := range orgIDs {
+		alerts, err := s.Evaluate(ctx, orgID, date)
+		if err != nil {
+			s.log.Error("dam-safety evaluation failed",
+				slog.Int64("organization_id", orgID),
+				slog.String("date", date),
+				slog.String("error", err.Error()))
+			failed++
+			continue
+		}
+		raised += len(alerts)
+	}
+
+	s.log.Info("dam-safety sweep completed",
+		slog.String("date", date),
+		slog.Int("organizations", len(orgIDs)),
+		slog.Int("alerts", raised),
+		slog.Int("failed", failed))
+}
+
+// StartScheduler sweeps the previous day once a day at runHour and reminds
+// station staff about readings missed that day. Blocks until ctx is cancelled.
+func (s *Service) StartScheduler(ctx context.Context) {
+	for {
+		now := time.Now().In(s.loc)
+		next := time.Date(now.Year(), now.Month(), now.Day(), s.runHour, 0, 0, 0, s.loc)
+		if !next.After(now) {
+			next = next.Add(24 * time.Hour)
+		}
+		wait := next.Sub(now)
+
+		s.log.Info("next dam-safety sweep scheduled",
+			slog.String("run_at", next.Format(time.RFC3339)),
+			slog.Duration("in", wait))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.log.Info("dam-safety scheduler stopped")
+			return
+		case <-timer.C:
+			yesterday := next.AddDate(0, 0, -1).Format("2006-01-02")
+			s.Sweep(ctx, yesterday)
+			s.RemindMissing(ctx, yesterday)
+		}
+	}
+}
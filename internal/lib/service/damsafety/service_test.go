@@ -0,0 +1,317 @@
+package damsafety
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"testing"
+	"time"
+
+	"srmt-admin/internal/lib/model/filtration"
+	"srmt-admin/internal/lib/service/notification"
+)
+
+// ---------- mocks ----------
+
+type fakeRepo struct {
+	summary   *filtration.OrgFiltrationSummary
+	level     *float64
+	series    map[string][]filtration.SeriesRow // key: kind/id
+	orgIDs    []int64
+	open      []filtration.Alert
+	replaced  []filtration.Alert
+	gotFrom   string
+	gotTo     string
+	replaceOK bool
+	schedules []filtration.Schedule
+	reads     []filtration.ReadDate
+	contacts  []int64
+	notified  []int64
+}
+
+func (f *fakeRepo) GetFiltrationLocationByID(_ context.Context, id int64) (*filtration.Location, error) {
+	for _, l := range f.summary.Locations {
+		if l.ID == id {
+			loc := l.Location
+			return &loc, nil
+		}
+	}
+	return nil, fmt.Errorf("location %d not found", id)
+}
+
+func (f *fakeRepo) GetPiezometerByID(_ context.Context, id int64) (*filtration.Piezometer, error) {
+	for _, p := range f.summary.Piezometers {
+		if p.ID == id {
+			pz := p.Piezometer
+			return &pz, nil
+		}
+	}
+	return nil, fmt.Errorf("piezometer %d not found", id)
+}
+
+func (f *fakeRepo) GetFiltrationSeries(_ context.Context, id int64, from, to string) ([]filtration.SeriesRow, error) {
+	f.gotFrom, f.gotTo = from, to
+	return f.series[fmt.Sprintf("location/%d", id)], nil
+}
+
+func (f *fakeRepo) GetPiezometerSeries(_ context.Context, id int64, from, to string) ([]filtration.SeriesRow, error) {
+	f.gotFrom, f.gotTo = from, to
+	return f.series[fmt.Sprintf("piezometer/%d", id)], nil
+}
+
+func (f *fakeRepo) GetOrgFiltrationSummary(_ context.Context, _ int64, _ string) (*filtration.OrgFiltrationSummary, error) {
+	return f.summary, nil
+}
+
+func (f *fakeRepo) GetReservoirLevelVolume(_ context.Context, _ int64, _ string) (*float64, *float64, error) {
+	return f.level, nil, nil
+}
+
+func (f *fakeRepo) GetFiltrationOrgIDs(_ context.Context) ([]int64, error) {
+	return f.orgIDs, nil
+}
+
+func (f *fakeRepo) ReplaceFiltrationAlerts(_ context.Context, _ int64, _ string, alerts []filtration.Alert) error {
+	f.replaced = alerts
+	f.replaceOK = true
+	return nil
+}
+
+func (f *fakeRepo) GetFiltrationAlerts(_ context.Context, _ filtration.AlertFilter) ([]filtration.Alert, error) {
+	return f.open, nil
+}
+
+func (f *fakeRepo) GetFiltrationSchedules(_ context.Context, _ int64) ([]filtration.Schedule, error) {
+	return f.schedules, nil
+}
+
+func (f *fakeRepo) GetFiltrationReadDates(_ context.Context, _ int64, _, _ string) ([]filtration.ReadDate, error) {
+	return f.reads, nil
+}
+
+func (f *fakeRepo) GetFiltrationReminderRecipients(_ context.Context, _ int64) ([]int64, error) {
+	return f.contacts, nil
+}
+
+func (f *fakeRepo) Notify(_ context.Context, e notification.Event) error {
+	f.notified = append(f.notified, e.Contacts...)
+	return nil
+}
+
+func discardLogger() *slog.Logger { return slog.New(slog.NewTextHandler(io.Discard, nil)) }
+
+func ptr(v float64) *float64 { return &v }
+
+// linearHistory builds n readings where value = 2*level + 1 with a small
+// alternating residual so the fitted σ is non-zero.
+func linearHistory(n int) []filtration.SeriesRow {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := make([]filtration.SeriesRow, 0, n)
+	for i := 0; i < n; i++ {
+		level := 100 + float64(i)
+		noise := 0.1
+		if i%2 == 0 {
+			noise = -0.1
+		}
+		rows = append(rows, filtration.SeriesRow{
+			Date:           start.AddDate(0, 0, i).Format("2006-01-02"),
+			Value:          ptr(2*level + 1 + noise),
+			ReservoirLevel: ptr(level),
+		})
+	}
+	return rows
+}
+
+// ---------- stats ----------
+
+func TestMovingAverage_SkipsGaps(t *testing.T) {
+	got := movingAverage([]*float64{ptr(1), nil, ptr(3), ptr(5), ptr(7)}, 2)
+
+	want := []*float64{ptr(1), nil, ptr(2), ptr(4), ptr(6)}
+	for i := range want {
+		switch {
+		case want[i] == nil && got[i] != nil:
+			t.Errorf("[%d] want nil, got %v", i, *got[i])
+		case want[i] != nil && (got[i] == nil || *got[i] != *want[i]):
+			t.Errorf("[%d] want %v, got %v", i, *want[i], got[i])
+		}
+	}
+}
+
+func TestFlagsFor(t *testing.T) {
+	reg := &filtration.Regression{Slope: 2, Intercept: 1, ResidualStd: 0.5}
+
+	tests := []struct {
+		name  string
+		value float64
+		norm  *float64
+		level *float64
+		want  []string
+	}{
+		{"within norm and band", 201, ptr(300), ptr(100), []string{}},
+		{"above norm only", 201, ptr(200), ptr(100), []string{filtration.AlertReasonNormExceeded}},
+		{"out of band only", 205, ptr(300), ptr(100), []string{filtration.AlertReasonOutOfBand}},
+		{"below band", 195, nil, ptr(100), []string{filtration.AlertReasonOutOfBand}},
+		{"no level skips band", 500, nil, nil, []string{}},
+		{"both", 205, ptr(200), ptr(100), []string{filtration.AlertReasonNormExceeded, filtration.AlertReasonOutOfBand}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := flagsFor(tt.value, tt.norm, reg, tt.level, bandSigma)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// ---------- service ----------
+
+func TestEvaluate_RaisesNormAndBandAlerts(t *testing.T) {
+	repo := &fakeRepo{
+		summary: &filtration.OrgFiltrationSummary{
+			OrganizationID:   7,
+			OrganizationName: "Чарвак",
+			Locations: []filtration.LocationReading{
+				{Location: filtration.Location{ID: 1, Name: "L1", Norm: ptr(150)}, FlowRate: ptr(160)},
+				{Location: filtration.Location{ID: 2, Name: "L2"}, FlowRate: ptr(2*130 + 1)},
+				{Location: filtration.Location{ID: 3, Name: "L3 unread", Norm: ptr(1)}},
+			},
+			Piezometers: []filtration.PiezoReading{
+				{Piezometer: filtration.Piezometer{ID: 9, Name: "P9"}, Level: ptr(400)},
+			},
+		},
+		level: ptr(130),
+		series: map[string][]filtration.SeriesRow{
+			"location/2":   linearHistory(30),
+			"piezometer/9": linearHistory(30),
+		},
+	}
+	svc := NewService(repo, repo, time.UTC, discardLogger())
+
+	alerts, err := svc.Evaluate(context.Background(), 7, "2026-03-10")
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	if !repo.replaceOK {
+		t.Fatal("want ReplaceFiltrationAlerts to be called")
+	}
+	if repo.gotTo != "2026-03-09" {
+		t.Errorf("history must end the day before date, got to=%s", repo.gotTo)
+	}
+
+	got := map[string]bool{}
+	for _, a := range alerts {
+		got[fmt.Sprintf("%s/%d/%s", a.InstrumentType, a.InstrumentID, a.Reason)] = true
+	}
+	want := map[string]bool{
+		"location/1/norm_exceeded": true,
+		"piezometer/9/out_of_band": true,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	for k := range want {
+		if !got[k] {
+			t.Errorf("missing alert %s (got %v)", k, got)
+		}
+	}
+}
+
+func TestEvaluate_NoLevelSkipsBand(t *testing.T) {
+	repo := &fakeRepo{
+		summary: &filtration.OrgFiltrationSummary{
+			Piezometers: []filtration.PiezoReading{
+				{Piezometer: filtration.Piezometer{ID: 9, Name: "P9"}, Level: ptr(400)},
+			},
+		},
+		series: map[string][]filtration.SeriesRow{"piezometer/9": linearHistory(30)},
+	}
+	svc := NewService(repo, repo, time.UTC, discardLogger())
+
+	alerts, err := svc.Evaluate(context.Background(), 7, "2026-03-10")
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	if len(alerts) != 0 {
+		t.Errorf("want no alerts without reservoir level, got %+v", alerts)
+	}
+	if !repo.replaceOK || len(repo.replaced) != 0 {
+		t.Errorf("want open alerts cleared for the date, got %+v", repo.replaced)
+	}
+}
+
+func TestTrend_OnlyReturnsRequestedRange(t *testing.T) {
+	history := linearHistory(30) // 2025-01-01 .. 2025-01-30
+	repo := &fakeRepo{
+		summary: &filtration.OrgFiltrationSummary{
+			Locations: []filtration.LocationReading{
+				{Location: filtration.Location{ID: 2, OrganizationID: 7, Name: "L2"}},
+			},
+		},
+		series: map[string][]filtration.SeriesRow{"location/2": history},
+	}
+	svc := NewService(repo, repo, time.UTC, discardLogger())
+
+	trend, err := svc.Trend(context.Background(), filtration.InstrumentLocation, 2, "2025-01-21", "2025-01-30", 3)
+	if err != nil {
+		t.Fatalf("Trend: %v", err)
+	}
+	if len(trend.Points) != 10 {
+		t.Fatalf("want 10 points, got %d", len(trend.Points))
+	}
+	if trend.Regression == nil || trend.Regression.Samples != 30 {
+		t.Errorf("want regression over full history, got %+v", trend.Regression)
+	}
+	first := trend.Points[0]
+	if first.MovingAvg == nil || first.Expected == nil {
+		t.Fatalf("want moving average and expected value, got %+v", first)
+	}
+	// Moving average at the first displayed point still uses the two
+	// readings before it.
+	wantAvg := (*history[18].Value + *history[19].Value + *history[20].Value) / 3
+	if math.Abs(*first.MovingAvg-wantAvg) > 1e-9 {
+		t.Errorf("want moving avg %.3f, got %.3f", wantAvg, *first.MovingAvg)
+	}
+	if trend.OrganizationID != 7 {
+		t.Errorf("want org 7, got %d", trend.OrganizationID)
+	}
+}
+
+func TestDashboard_CountsPerOrg(t *testing.T) {
+	repo := &fakeRepo{
+		summary: &filtration.OrgFiltrationSummary{
+			OrganizationName: "Чарвак",
+			Locations: []filtration.LocationReading{
+				{Location: filtration.Location{ID: 1}, FlowRate: ptr(1)},
+				{Location: filtration.Location{ID: 2}},
+			},
+			Piezometers: []filtration.PiezoReading{
+				{Piezometer: filtration.Piezometer{ID: 9}, Level: ptr(1)},
+			},
+		},
+		orgIDs: []int64{7, 8},
+		open: []filtration.Alert{
+			{OrganizationID: 7, Reason: filtration.AlertReasonNormExceeded},
+			{OrganizationID: 7, Reason: filtration.AlertReasonOutOfBand},
+		},
+	}
+	svc := NewService(repo, repo, time.UTC, discardLogger())
+
+	d, err := svc.Dashboard(context.Background(), "2026-03-10", []int64{7})
+	if err != nil {
+		t.Fatalf("Dashboard: %v", err)
+	}
+	if len(d.Organizations) != 1 {
+		t.Fatalf("want 1 org after access filter, got %d", len(d.Organizations))
+	}
+	o := d.Organizations[0]
+	if o.Instruments != 3 || o.Measured != 2 {
+		t.Errorf("want 3 instruments / 2 measured, got %d / %d", o.Instruments, o.Measured)
+	}
+	if o.OpenAlerts != 2 || o.NormExceeded != 1 || o.OutOfBand != 1 || d.TotalOpen != 2 {
+		t.Errorf("unexpected alert counts: %+v total=%d", o, d.TotalOpen)
+	}
+}
@@ -0,0 +1,67 @@
+package damsafety
+
+import "srmt-admin/internal/lib/model/filtration"
+
+// movingAverage returns the trailing mean over the last `window` non-nil
+// values for every position. Gaps (nil values) keep a nil average and do not
+// count towards the window — the average is over readings, not calendar days.
+func movingAverage(values []*float64, window int) []*float64 {
+	out := make([]*float64, len(values))
+	if window < 1 {
+		return out
+	}
+
+	buf := make([]float64, 0, window)
+	var sum float64
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		if len(buf) == window {
+			sum -= buf[0]
+			buf = buf[1:]
+		}
+		buf = append(buf, *v)
+		sum += *v
+
+		avg := sum / float64(len(buf))
+		out[i] = &avg
+	}
+	return out
+}
+
+// expectedBand returns the predicted value for level and the
+// [expected - k·σ, expected + k·σ] band around it.
+func expectedBand(reg *filtration.Regression, level, k float64) (expected, low, high float64) {
+	expected = reg.Intercept + reg.Slope*level
+	half := k * reg.ResidualStd
+	return expected, expected - half, expected + half
+}
+
+// pairs extracts the (reservoir level, value) pairs usable for regression.
+func pairs(rows []filtration.SeriesRow) (xs, ys []float64) {
+	for _, row := range rows {
+		if row.Value == nil || row.ReservoirLevel == nil {
+			continue
+		}
+		xs = append(xs, *row.ReservoirLevel)
+		ys = append(ys, *row.Value)
+	}
+	return xs, ys
+}
+
+// flagsFor reports the alert reasons raised by one reading. norm, reg and
+// level may each be nil; the corresponding check is skipped.
+func flagsFor(value float64, norm *float64, reg *filtration.Regression, level *float64, k float64) []string {
+	flags := make([]string, 0, 2)
+	if norm != nil && value > *norm {
+		flags = append(flags, filtration.AlertReasonNormExceeded)
+	}
+	if reg != nil && level != nil {
+		_, low, high := expectedBand(reg, *level, k)
+		if value < low || value > high {
+			flags = append(flags, filtration.AlertReasonOutOfBand)
+		}
+	}
+	return flags
+}
@@ -4,24 +4,35 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"srmt-admin/internal/lib/service/notification"
 	"srmt-admin/internal/storage"
 	"time"
 )
 
+// ApproverRoles are the roles asked to approve new discharges.
+var ApproverRoles = []string{"sc"}
+
 // Repository defines data-access methods for ongoing discharge checks.
 type Repository interface {
 	CheckOngoingDischarge(ctx context.Context, orgID int64) (id int64, exists bool, err error)
 	CloseDischarge(ctx context.Context, id int64, endTime time.Time) error
+	GetOrganizationName(ctx context.Context, orgID int64) (string, error)
+}
+
+// Notifier delivers approval requests.
+type Notifier interface {
+	Notify(ctx context.Context, e notification.Event) error
 }
 
 // Service handles discharge business logic.
 type Service struct {
-	repo Repository
+	repo     Repository
+	notifier Notifier
 }
 
 // NewService creates a new discharge Service.
-func NewService(repo Repository) *Service {
-	return &Service{repo: repo}
+func NewService(repo Repository, notifier Notifier) *Service {
+	return &Service{repo: repo, notifier: notifier}
 }
 
 // EnsureNoOngoingDischarge checks if an ongoing idle discharge exists for the organization.
@@ -52,3 +63,29 @@ func (s *Service) EnsureNoOngoingDischarge(ctx context.Context, orgID int64, for
 
 	return nil
 }
+
+// RequestApproval tells the approvers that a new discharge of the
+// organization awaits their approval.
+func (s *Service) RequestApproval(ctx context.Context, dischargeID, orgID int64, flowRate float64) error {
+	const op = "service.discharge.RequestApproval"
+
+	if s.notifier == nil {
+		return nil
+	}
+
+	name, err := s.repo.GetOrganizationName(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = s.notifier.Notify(ctx, notification.Event{
+		Type:    notification.DischargeAwaitingApproval,
+		Roles:   ApproverRoles,
+		Message: fmt.Sprintf("%s: холостой сброс %.1f м³/с (№%d) ожидает подтверждения", name, flowRate, dischargeID),
+		Link:    "/discharges",
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -22,12 +22,16 @@ func (m *mockRepository) CloseDischarge(ctx context.Context, id int64, endTime t
 	return m.closeFunc(ctx, id, endTime)
 }
 
+func (m *mockRepository) GetOrganizationName(_ context.Context, _ int64) (string, error) {
+	return "Чарвакская ГЭС", nil
+}
+
 func TestEnsureNoOngoingDischarge_NoOngoing(t *testing.T) {
 	svc := NewService(&mockRepository{
 		checkOngoingFunc: func(_ context.Context, _ int64) (int64, bool, error) {
 			return 0, false, nil
 		},
-	})
+	}, nil)
 
 	err := svc.EnsureNoOngoingDischarge(context.Background(), 1, false, time.Now())
 	if err != nil {
@@ -40,7 +44,7 @@ func TestEnsureNoOngoingDischarge_ExistsNoForce(t *testing.T) {
 		checkOngoingFunc: func(_ context.Context, _ int64) (int64, bool, error) {
 			return 42, true, nil
 		},
-	})
+	}, nil)
 
 	err := svc.EnsureNoOngoingDischarge(context.Background(), 1, false, time.Now())
 	if !errors.Is(err, storage.ErrOngoingDischargeExists) {
@@ -58,7 +62,7 @@ func TestEnsureNoOngoingDischarge_ExistsForce(t *testing.T) {
 			closedID = id
 			return nil
 		},
-	})
+	}, nil)
 
 	err := svc.EnsureNoOngoingDischarge(context.Background(), 1, true, time.Now())
 	if err != nil {
@@ -75,7 +79,7 @@ func TestEnsureNoOngoingDischarge_CheckError(t *testing.T) {
 		checkOngoingFunc: func(_ context.Context, _ int64) (int64, bool, error) {
 			return 0, false, repoErr
 		},
-	})
+	}, nil)
 
 	err := svc.EnsureNoOngoingDischarge(context.Background(), 1, false, time.Now())
 	if !errors.Is(err, repoErr) {
@@ -92,7 +96,7 @@ func TestEnsureNoOngoingDischarge_CloseError(t *testing.T) {
 		closeFunc: func(_ context.Context, _ int64, _ time.Time) error {
 			return closeErr
 		},
-	})
+	}, nil)
 
 	err := svc.EnsureNoOngoingDischarge(context.Background(), 1, true, time.Now())
 	if !errors.Is(err, closeErr) {
@@ -111,7 +115,7 @@ func TestEnsureNoOngoingDischarge_ForceCloseCheckConstraint(t *testing.T) {
 		closeFunc: func(_ context.Context, _ int64, _ time.Time) error {
 			return storage.ErrCheckConstraintViolation
 		},
-	})
+	}, nil)
 
 	err := svc.EnsureNoOngoingDischarge(context.Background(), 1, true, time.Now())
 	if !errors.Is(err, storage.ErrDischargeEndBeforeStart) {
@@ -0,0 +1,343 @@
+// Package docintegrity seals registry documents on signing and verifies them
+// later. A seal is the SHA-256 of a manifest of the document's key fields and
+// the SHA-256 of every attachment, signed by a Provider.
+package docintegrity
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	document_kind "srmt-admin/internal/lib/model/document-kind"
+	"srmt-admin/internal/lib/model/signature"
+)
+
+type Repository interface {
+	GetDocumentKind(ctx context.Context, code string) (*document_kind.Model, error)
+	GetDocumentContent(ctx context.Context, kind document_kind.Model, id int64) (*signature.Content, error)
+	GetSignatureSeals(ctx context.Context, docType string, docID int64) ([]signature.SealedSignature, error)
+}
+
+type FileStorage interface {
+	GetObject(ctx context.Context, objectName string) (io.ReadCloser, error)
+}
+
+type Service struct {
+	repo      Repository
+	storage   FileStorage
+	signer    Provider
+	providers map[string]Provider
+	log       *slog.Logger
+}
+
+// NewService seals with the hmac-sha256 provider when sealKey is set and with
+// the bare digest otherwise. The digest provider stays registered, so seals
+// made before the key was configured still verify; hmac seals without the
+// key are unverifiable.
+func NewService(repo Repository, storage FileStorage, sealKey string, log *slog.Logger) *Service {
+	s := &Service{
+		repo:      repo,
+		storage:   storage,
+		providers: make(map[string]Provider),
+		log:       log.With(slog.String("service", "document-integrity")),
+	}
+	s.UseProvider(digestProvider{})
+	if sealKey != "" {
+		s.UseProvider(hmacProvider{key: []byte(sealKey)})
+	}
+	return s
+}
+
+// UseProvider registers p and seals new signatures with it.
+func (s *Service) UseProvider(p Provider) {
+	s.providers[p.Name()] = p
+	s.signer = p
+}
+
+// Seal builds the manifest of a document as it is now for a signature by
+// userID with resolution, and has the signing provider sign its digest.
+func (s *Service) Seal(ctx context.Context, docType string, docID int64, resolution *string, userID int64) (*signature.Seal, error) {
+	const op = "service.document-integrity.Seal"
+
+	kind, err := s.repo.GetDocumentKind(ctx, docType)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	content, err := s.repo.GetDocumentContent(ctx, *kind, docID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	files, err := s.hashFiles(ctx, *content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	manifest := signature.Manifest{
+		DocumentType: docType,
+		DocumentID:   docID,
+		Fields:       content.Fields,
+		Files:        files,
+		SignedBy:     userID,
+		Resolution:   resolution,
+	}
+	digest, err := manifestDigest(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sig, err := s.signer.Sign(ctx, digest, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: provider %s: %w", op, s.signer.Name(), err)
+	}
+
+	return &signature.Seal{
+		Algorithm:   signature.DigestAlgorithm,
+		Digest:      hex.EncodeToString(digest),
+		Manifest:    manifest,
+		Provider:    s.signer.Name(),
+		Value:       sig.Value,
+		Certificate: sig.Certificate,
+	}, nil
+}
+
+// Verify re-hashes a document and compares it with every sealed signature.
+func (s *Service) Verify(ctx context.Context, docType string, docID int64) (*signature.Verification, error) {
+	const op = "service.document-integrity.Verify"
+
+	kind, err := s.repo.GetDocumentKind(ctx, docType)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	content, err := s.repo.GetDocumentContent(ctx, *kind, docID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	sigs, err := s.repo.GetSignatureSeals(ctx, docType, docID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	v := &signature.Verification{
+		DocumentType: docType,
+		DocumentID:   docID,
+		VerifiedAt:   time.Now(),
+		Signatures:   make([]signature.SignatureCheck, 0, len(sigs)),
+	}
+
+	var files []signature.ManifestFile
+	for _, sig := range sigs {
+		if sig.Seal == nil {
+			v.Unsealed++
+			continue
+		}
+		if files == nil {
+			// Hash the attachments once, and only for a sealed document
+			if files, err = s.hashFiles(ctx, *content); err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+		check, err := s.check(ctx, docType, docID, sig, content.Fields, files)
+		if err != nil {
+			return nil, fmt.Errorf("%s: signature %d: %w", op, sig.ID, err)
+		}
+		v.Signatures = append(v.Signatures, *check)
+	}
+
+	// Signatures come newest first; the latest seal decides
+	if len(v.Signatures) > 0 {
+		latest := v.Signatures[0]
+		v.Sealed = true
+		v.Intact = latest.Intact && latest.SealStatus == signature.SealValid
+	}
+
+	if !v.Intact && v.Sealed {
+		s.log.Warn("signed document changed after signing",
+			slog.String("document_type", docType), slog.Int64("document_id", docID))
+	}
+
+	return v, nil
+}
+
+// check compares one seal with the current fields and attachments.
+func (s *Service) check(ctx context.Context, docType string, docID int64, sig signature.SealedSignature, fields signature.ContentFields, files []signature.ManifestFile) (*signature.SignatureCheck, error) {
+	seal := sig.Seal
+	c := &signature.SignatureCheck{
+		SignatureID:   sig.ID,
+		SignedBy:      sig.SignedBy,
+		SignedAt:      sig.SignedAt,
+		Provider:      seal.Provider,
+		Digest:        seal.Digest,
+		ChangedFields: make([]string, 0),
+		AddedFiles:    make([]signature.ManifestFile, 0),
+		RemovedFiles:  make([]signature.ManifestFile, 0),
+		ModifiedFiles: make([]signature.ManifestFile, 0),
+	}
+
+	status, err := s.sealStatus(ctx, *seal)
+	if err != nil {
+		return nil, err
+	}
+	c.SealStatus = status
+
+	// The current manifest takes the signer and resolution from the
+	// signature row, so an edit of the row shows as well
+	current := signature.Manifest{
+		DocumentType: docType,
+		DocumentID:   docID,
+		Fields:       fields,
+		Files:        files,
+		Resolution:   sig.ResolutionText,
+	}
+	if sig.SignedBy != nil {
+		current.SignedBy = sig.SignedBy.ID
+	}
+	digest, err := manifestDigest(current)
+	if err != nil {
+		return nil, err
+	}
+	c.CurrentDigest = hex.EncodeToString(digest)
+	c.Intact = c.CurrentDigest == seal.Digest
+
+	changed, err := changedFields(seal.Manifest.Fields, fields)
+	if err != nil {
+		return nil, err
+	}
+	c.ChangedFields = append(c.ChangedFields, changed...)
+	if !equalText(seal.Manifest.Resolution, current.Resolution) {
+		c.ChangedFields = append(c.ChangedFields, "resolution")
+	}
+	if seal.Manifest.SignedBy != current.SignedBy {
+		c.ChangedFields = append(c.ChangedFields, "signed_by")
+	}
+
+	signed := make(map[int64]signature.ManifestFile, len(seal.Manifest.Files))
+	for _, f := range seal.Manifest.Files {
+		signed[f.FileID] = f
+	}
+	for _, f := range files {
+		was, ok := signed[f.FileID]
+		switch {
+		case !ok:
+			c.AddedFiles = append(c.AddedFiles, f)
+		case was.SHA256 != f.SHA256 || was.SizeBytes != f.SizeBytes:
+			c.ModifiedFiles = append(c.ModifiedFiles, f)
+		}
+		delete(signed, f.FileID)
+	}
+	for _, f := range seal.Manifest.Files {
+		if _, gone := signed[f.FileID]; gone {
+			c.RemovedFiles = append(c.RemovedFiles, f)
+		}
+	}
+
+	return c, nil
+}
+
+// sealStatus checks that the stored digest is the digest of the stored
+// manifest and that the provider that made the seal accepts it.
+func (s *Service) sealStatus(ctx context.Context, seal signature.Seal) (string, error) {
+	digest, err := manifestDigest(seal.Manifest)
+	if err != nil {
+		return "", err
+	}
+	if hex.EncodeToString(digest) != seal.Digest {
+		return signature.SealInvalid, nil
+	}
+
+	p := s.providers[seal.Provider]
+	if p == nil {
+		return signature.SealUnverifiable, nil
+	}
+	err = p.Verify(ctx, digest, ProviderSignature{Value: seal.Value, Certificate: seal.Certificate})
+	if errors.Is(err, ErrSealMismatch) {
+		return signature.SealInvalid, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("provider %s: %w", p.Name(), err)
+	}
+	return signature.SealValid, nil
+}
+
+// hashFiles computes the SHA-256 of every attachment, in file ID order.
+func (s *Service) hashFiles(ctx context.Context, content signature.Content) ([]signature.ManifestFile, error) {
+	files := make([]signature.ManifestFile, 0, len(content.Files))
+	for _, f := range content.Files {
+		obj, err := s.storage.GetObject(ctx, f.ObjectKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file %d: %w", f.ID, err)
+		}
+		h := sha256.New()
+		size, err := io.Copy(h, obj)
+		obj.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %d: %w", f.ID, err)
+		}
+		files = append(files, signature.ManifestFile{
+			FileID:    f.ID,
+			FileName:  f.FileName,
+			SizeBytes: size,
+			SHA256:    hex.EncodeToString(h.Sum(nil)),
+		})
+	}
+	signature.SortFiles(files)
+	return files, nil
+}
+
+func manifestDigest(m signature.Manifest) ([]byte, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	return sum[:], nil
+}
+
+// changedFields lists the JSON names of the key fields that differ.
+func changedFields(signed, current signature.ContentFields) ([]string, error) {
+	a, err := fieldMap(signed)
+	if err != nil {
+		return nil, err
+	}
+	b, err := fieldMap(current)
+	if err != nil {
+		return nil, err
+	}
+	changed := make([]string, 0)
+	for _, name := range fieldOrder {
+		if string(a[name]) != string(b[name]) {
+			changed = append(changed, name)
+		}
+	}
+	return changed, nil
+}
+
+// fieldOrder is the order changed fields are reported in.
+var fieldOrder = []string{
+	"name", "number", "document_date", "description", "type_id",
+	"organization_id", "responsible_contact_id", "parent_document_id",
+}
+
+func fieldMap(f signature.ContentFields) (map[string]json.RawMessage, error) {
+	b, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func equalText(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
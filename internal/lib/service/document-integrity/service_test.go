@@ -0,0 +1,191 @@
+package docintegrity
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	document_kind "srmt-admin/internal/lib/model/document-kind"
+	"srmt-admin/internal/lib/model/file"
+	"srmt-admin/internal/lib/model/signature"
+	"srmt-admin/internal/lib/model/user"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+type fakeRepo struct {
+	content signature.Content
+	sigs    []signature.SealedSignature
+}
+
+func (f *fakeRepo) GetDocumentKind(_ context.Context, code string) (*document_kind.Model, error) {
+	return &document_kind.Model{Code: code, TableName: code + "s"}, nil
+}
+
+func (f *fakeRepo) GetDocumentContent(_ context.Context, _ document_kind.Model, _ int64) (*signature.Content, error) {
+	c := f.content
+	c.Files = slices.Clone(f.content.Files)
+	return &c, nil
+}
+
+func (f *fakeRepo) GetSignatureSeals(_ context.Context, _ string, _ int64) ([]signature.SealedSignature, error) {
+	return f.sigs, nil
+}
+
+type fakeStorage map[string]string
+
+func (f fakeStorage) GetObject(_ context.Context, objectName string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(f[objectName])), nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func newFixture() (*fakeRepo, fakeStorage) {
+	repo := &fakeRepo{content: signature.Content{
+		Fields: signature.ContentFields{Name: "Приказ", Number: ptr("12"), DocumentDate: "2026-10-01", TypeID: 1},
+		Files: []file.Model{
+			{ID: 3, FileName: "order.pdf", ObjectKey: "a/order.pdf"},
+			{ID: 7, FileName: "annex.pdf", ObjectKey: "a/annex.pdf"},
+		},
+	}}
+	return repo, fakeStorage{"a/order.pdf": "order v1", "a/annex.pdf": "annex v1", "a/new.pdf": "new"}
+}
+
+// sign seals the document as it is now and records the signature.
+func sign(t *testing.T, svc *Service, repo *fakeRepo, userID int64, resolution *string) {
+	t.Helper()
+
+	seal, err := svc.Seal(context.Background(), "decree", 5, resolution, userID)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	repo.sigs = append([]signature.SealedSignature{{
+		ID: int64(len(repo.sigs) + 1), SignedBy: &user.ShortInfo{ID: userID}, SignedAt: time.Now(),
+		ResolutionText: resolution, Seal: seal,
+	}}, repo.sigs...)
+}
+
+func TestSeal(t *testing.T) {
+	repo, files := newFixture()
+	seal, err := NewService(repo, files, "", testLogger()).Seal(context.Background(), "decree", 5, ptr("Согласен"), 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if seal.Provider != "sha256" || seal.Value != nil || len(seal.Digest) != 64 {
+		t.Errorf("seal = %+v", seal)
+	}
+	m := seal.Manifest
+	if m.SignedBy != 9 || *m.Resolution != "Согласен" || len(m.Files) != 2 || m.Files[0].FileID != 3 {
+		t.Errorf("manifest = %+v", m)
+	}
+	if m.Files[0].SizeBytes != int64(len("order v1")) {
+		t.Errorf("size = %d", m.Files[0].SizeBytes)
+	}
+	if !m.Covers(repo.content) {
+		t.Error("manifest does not cover the content it was made from")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         string
+		verifyKey   string
+		change      func(repo *fakeRepo, files fakeStorage)
+		wantIntact  bool
+		wantStatus  string
+		wantFields  []string
+		wantAdded   int
+		wantRemoved int
+		wantChanged int
+	}{
+		{name: "unchanged", wantIntact: true, wantStatus: signature.SealValid},
+		{name: "unchanged with key", key: "k", verifyKey: "k", wantIntact: true, wantStatus: signature.SealValid},
+		{name: "field changed",
+			change: func(repo *fakeRepo, _ fakeStorage) {
+				repo.content.Fields.Number = ptr("13")
+			},
+			wantStatus: signature.SealValid, wantFields: []string{"number"}},
+		{name: "file replaced",
+			change: func(repo *fakeRepo, _ fakeStorage) {
+				repo.content.Files[1] = file.Model{ID: 8, FileName: "new.pdf", ObjectKey: "a/new.pdf"}
+			},
+			wantStatus: signature.SealValid, wantAdded: 1, wantRemoved: 1},
+		{name: "file content changed",
+			change: func(_ *fakeRepo, files fakeStorage) {
+				files["a/order.pdf"] = "order v2"
+			},
+			wantStatus: signature.SealValid, wantChanged: 1},
+		{name: "resolution edited",
+			change: func(repo *fakeRepo, _ fakeStorage) {
+				repo.sigs[0].ResolutionText = ptr("Отказать")
+			},
+			wantStatus: signature.SealValid, wantFields: []string{"resolution"}},
+		{name: "manifest tampered",
+			change: func(repo *fakeRepo, _ fakeStorage) {
+				repo.sigs[0].Seal.Manifest.Fields.Name = "Другой"
+			},
+			wantIntact: true, wantStatus: signature.SealInvalid, wantFields: []string{"name"}},
+		{name: "wrong key", key: "k", verifyKey: "other", wantIntact: true, wantStatus: signature.SealInvalid},
+		{name: "key not configured", key: "k", wantIntact: true, wantStatus: signature.SealUnverifiable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, files := newFixture()
+			sign(t, NewService(repo, files, tt.key, testLogger()), repo, 9, ptr("Согласен"))
+			if tt.change != nil {
+				tt.change(repo, files)
+			}
+
+			v, err := NewService(repo, files, tt.verifyKey, testLogger()).Verify(context.Background(), "decree", 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !v.Sealed || len(v.Signatures) != 1 {
+				t.Fatalf("verification = %+v", v)
+			}
+			c := v.Signatures[0]
+			if c.Intact != tt.wantIntact || c.SealStatus != tt.wantStatus {
+				t.Errorf("intact = %v, status = %s; want %v, %s", c.Intact, c.SealStatus, tt.wantIntact, tt.wantStatus)
+			}
+			if v.Intact != (tt.wantIntact && tt.wantStatus == signature.SealValid) {
+				t.Errorf("document intact = %v", v.Intact)
+			}
+			if !slices.Equal(c.ChangedFields, append([]string{}, tt.wantFields...)) {
+				t.Errorf("changed fields = %v, want %v", c.ChangedFields, tt.wantFields)
+			}
+			if len(c.AddedFiles) != tt.wantAdded || len(c.RemovedFiles) != tt.wantRemoved || len(c.ModifiedFiles) != tt.wantChanged {
+				t.Errorf("files added %v, removed %v, modified %v", c.AddedFiles, c.RemovedFiles, c.ModifiedFiles)
+			}
+		})
+	}
+}
+
+func TestVerify_LatestSealDecides(t *testing.T) {
+	repo, files := newFixture()
+	svc := NewService(repo, files, "", testLogger())
+	repo.sigs = []signature.SealedSignature{{ID: 100, SignedAt: time.Now()}} // signed before sealing
+
+	sign(t, svc, repo, 9, nil)
+	repo.content.Fields.Name = "Исправленный приказ" // returned to draft and revised
+	sign(t, svc, repo, 9, nil)
+
+	v, err := svc.Verify(context.Background(), "decree", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Intact || v.Unsealed != 1 || len(v.Signatures) != 2 {
+		t.Fatalf("verification = %+v", v)
+	}
+	if v.Signatures[1].Intact {
+		t.Error("the earlier signature should report the revision")
+	}
+}
@@ -0,0 +1,208 @@
+package doctemplates
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var ErrNotDOCX = errors.New("file is not a DOCX document")
+
+var (
+	// textRe matches the text element of a run; <w:tab/> and <w:tbl> do not
+	// match since the name must end right after "w:t".
+	textRe        = regexp.MustCompile(`(<w:t(?:\s[^>]*)?>)([^<]*)(</w:t>)`)
+	placeholderRe = regexp.MustCompile(`\{\{\s*([a-z_]+)\s*\}\}`)
+)
+
+const preserveTextTag = `<w:t xml:space="preserve">`
+
+// isTemplatePart reports whether a DOCX entry holds text with placeholders:
+// the body, headers and footers.
+func isTemplatePart(name string) bool {
+	if name == "word/document.xml" {
+		return true
+	}
+	return strings.HasSuffix(name, ".xml") &&
+		(strings.HasPrefix(name, "word/header") || strings.HasPrefix(name, "word/footer"))
+}
+
+func openDOCX(data []byte) (*zip.Reader, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrNotDOCX
+	}
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			return zr, nil
+		}
+	}
+	return nil, ErrNotDOCX
+}
+
+func readEntry(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", f.Name, err)
+	}
+	return string(b), nil
+}
+
+// partText returns the text runs of an XML part and their concatenation.
+// Word splits typed text across runs freely, so placeholders are searched
+// in the concatenation.
+func partText(part string) (locs [][]int, full string) {
+	locs = textRe.FindAllStringSubmatchIndex(part, -1)
+	var b strings.Builder
+	for _, l := range locs {
+		b.WriteString(part[l[4]:l[5]])
+	}
+	return locs, b.String()
+}
+
+// ScanPlaceholders returns the distinct placeholder names used in a DOCX.
+func ScanPlaceholders(data []byte) ([]string, error) {
+	zr, err := openDOCX(data)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0)
+	for _, f := range zr.File {
+		if !isTemplatePart(f.Name) {
+			continue
+		}
+		part, err := readEntry(f)
+		if err != nil {
+			return nil, err
+		}
+		_, full := partText(part)
+		for _, m := range placeholderRe.FindAllStringSubmatch(full, -1) {
+			if !slices.Contains(names, m[1]) {
+				names = append(names, m[1])
+			}
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// Fill replaces the placeholders of a DOCX with values. A placeholder split
+// across runs is written into the run where it starts, keeping that run's
+// formatting; placeholders without a value are left as they are.
+func Fill(data []byte, values map[string]string) ([]byte, error) {
+	zr, err := openDOCX(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	for _, f := range zr.File {
+		if !isTemplatePart(f.Name) {
+			if err := zw.Copy(f); err != nil {
+				return nil, fmt.Errorf("failed to copy %s: %w", f.Name, err)
+			}
+			continue
+		}
+
+		part, err := readEntry(f)
+		if err != nil {
+			return nil, err
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.Name, Method: zip.Deflate, Modified: f.Modified})
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", f.Name, err)
+		}
+		if _, err := io.WriteString(w, fillPart(part, values)); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", f.Name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish docx: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+func fillPart(part string, values map[string]string) string {
+	locs, full := partText(part)
+	matches := placeholderRe.FindAllStringSubmatchIndex(full, -1)
+	if len(matches) == 0 {
+		return part
+	}
+
+	var b strings.Builder
+	last, offset := 0, 0
+	for _, l := range locs {
+		segStart, segEnd := offset, offset+l[5]-l[4]
+		offset = segEnd
+
+		text, changed := rebuildRun(full, segStart, segEnd, matches, values)
+		b.WriteString(part[last:l[0]])
+		if changed {
+			b.WriteString(preserveTextTag)
+		} else {
+			b.WriteString(part[l[2]:l[3]])
+		}
+		b.WriteString(text)
+		b.WriteString(part[l[6]:l[7]])
+		last = l[1]
+	}
+	b.WriteString(part[last:])
+	return b.String()
+}
+
+// rebuildRun returns the new text of the run covering full[segStart:segEnd].
+func rebuildRun(full string, segStart, segEnd int, matches [][]int, values map[string]string) (string, bool) {
+	var b strings.Builder
+	pos, changed := segStart, false
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if end <= segStart || start >= segEnd {
+			continue
+		}
+		value, ok := values[full[m[2]:m[3]]]
+		if !ok {
+			continue
+		}
+		changed = true
+		if start > pos {
+			b.WriteString(full[pos:start])
+		}
+		if start >= segStart {
+			b.WriteString(runText(value))
+		}
+		pos = min(end, segEnd)
+	}
+	if !changed {
+		return full[segStart:segEnd], false
+	}
+	if pos < segEnd {
+		b.WriteString(full[pos:segEnd])
+	}
+	return b.String(), true
+}
+
+// runText escapes a value for a text element; line breaks become <w:br/>.
+func runText(value string) string {
+	lines := strings.Split(strings.ReplaceAll(value, "\r\n", "\n"), "\n")
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString(`</w:t><w:br/>` + preserveTextTag)
+		}
+		xml.EscapeText(&b, []byte(line))
+	}
+	return b.String()
+}
@@ -0,0 +1,407 @@
+// Package doctemplates keeps the DOCX templates of the document kinds and
+// fills them from registry documents. A generated file is stored like any
+// upload and, by default, attached to the document it was made from.
+package doctemplates
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"srmt-admin/internal/lib/model/category"
+	"srmt-admin/internal/lib/model/document"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
+	document_template "srmt-admin/internal/lib/model/document-template"
+	"srmt-admin/internal/lib/model/file"
+	"srmt-admin/internal/lib/model/signature"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownKind          = errors.New("unknown document kind")
+	ErrUnknownPlaceholder   = errors.New("template uses unknown placeholders")
+	ErrTemplateInactive     = errors.New("template is inactive")
+	ErrTemplateKindMismatch = errors.New("template belongs to another document kind")
+)
+
+const (
+	templateCategory  = "document-templates"
+	generatedCategory = "generated-documents"
+
+	mimeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	mimePDF  = "application/pdf"
+
+	dateLayout = "02.01.2006"
+
+	convertTimeout = 2 * time.Minute
+)
+
+type Repository interface {
+	DocumentKindExists(ctx context.Context, code string) (bool, error)
+	GetDocumentTemplates(ctx context.Context, kind *string, activeOnly bool) ([]document_template.Model, error)
+	GetDocumentTemplate(ctx context.Context, id int64) (*document_template.Model, error)
+	AddDocumentTemplate(ctx context.Context, req document_template.AddRequest, userID int64) (int64, error)
+	EditDocumentTemplate(ctx context.Context, id int64, req document_template.EditRequest) error
+	GetDocumentByID(ctx context.Context, kind document_kind.Model, id int64) (*document.ResponseModel, error)
+	GetDocumentSignatures(ctx context.Context, docType string, docID int64) ([]signature.Signature, error)
+	GetCategoryByName(ctx context.Context, categoryName string) (category.Model, error)
+	AddFile(ctx context.Context, fileData file.Model) (int64, error)
+	DeleteFile(ctx context.Context, id int64) error
+	LinkDocumentFiles(ctx context.Context, kind document_kind.Model, docID int64, fileIDs []int64) error
+}
+
+type FileStorage interface {
+	UploadFile(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) error
+	GetObject(ctx context.Context, objectName string) (io.ReadCloser, error)
+	DeleteFile(ctx context.Context, objectName string) error
+}
+
+// Converter turns a DOCX into a PDF.
+type Converter func(ctx context.Context, docx []byte) ([]byte, error)
+
+type Service struct {
+	repo    Repository
+	storage FileStorage
+	convert Converter
+	loc     *time.Location
+	log     *slog.Logger
+}
+
+func NewService(repo Repository, storage FileStorage, loc *time.Location, log *slog.Logger) *Service {
+	return &Service{
+		repo:    repo,
+		storage: storage,
+		convert: ConvertToPDF,
+		loc:     loc,
+		log:     log.With(slog.String("service", "document-templates")),
+	}
+}
+
+// Templates lists templates, optionally of one kind and only the active ones.
+func (s *Service) Templates(ctx context.Context, kind *string, activeOnly bool) ([]document_template.Model, error) {
+	const op = "service.document-templates.Templates"
+
+	templates, err := s.repo.GetDocumentTemplates(ctx, kind, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return templates, nil
+}
+
+// CreateTemplate stores an uploaded DOCX as a template of kind. Every
+// placeholder in it must be a supported one, so a typo is caught on upload
+// rather than in a generated document.
+func (s *Service) CreateTemplate(ctx context.Context, kind, name string, description *string, fileName string, data []byte, userID int64) (*document_template.Model, error) {
+	const op = "service.document-templates.CreateTemplate"
+
+	ok, err := s.repo.DocumentKindExists(ctx, kind)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, ErrUnknownKind)
+	}
+
+	if !strings.EqualFold(filepath.Ext(fileName), ".docx") {
+		return nil, fmt.Errorf("%s: %w", op, ErrNotDOCX)
+	}
+	placeholders, err := ScanPlaceholders(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	var unknown []string
+	for _, p := range placeholders {
+		if !document_template.IsPlaceholder(p) {
+			unknown = append(unknown, p)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("%s: %w: %s", op, ErrUnknownPlaceholder, strings.Join(unknown, ", "))
+	}
+
+	stored, err := s.storeFile(ctx, templateCategory, fileName, mimeDOCX, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := s.repo.AddDocumentTemplate(ctx, document_template.AddRequest{
+		DocumentKind: kind,
+		Name:         name,
+		Description:  description,
+		FileID:       stored.ID,
+		Placeholders: placeholders,
+	}, userID)
+	if err != nil {
+		s.discardFile(ctx, stored.ID, stored.ObjectKey)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.log.Info("document template created", slog.Int64("id", id), slog.String("document_kind", kind))
+
+	tpl, err := s.repo.GetDocumentTemplate(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return tpl, nil
+}
+
+// EditTemplate updates a template's name, description or activity.
+func (s *Service) EditTemplate(ctx context.Context, id int64, req document_template.EditRequest) error {
+	const op = "service.document-templates.EditTemplate"
+
+	if err := s.repo.EditDocumentTemplate(ctx, id, req); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Generate fills a template of kind from the document docID and stores the
+// result as a new file, attached to the document unless req.Attach is false.
+func (s *Service) Generate(ctx context.Context, kind document_kind.Model, docID int64, req document_template.GenerateRequest) (*document_template.Generated, error) {
+	const op = "service.document-templates.Generate"
+
+	tpl, err := s.repo.GetDocumentTemplate(ctx, req.TemplateID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if tpl.DocumentKind != kind.Code {
+		return nil, fmt.Errorf("%s: %w", op, ErrTemplateKindMismatch)
+	}
+	if !tpl.IsActive {
+		return nil, fmt.Errorf("%s: %w", op, ErrTemplateInactive)
+	}
+
+	doc, err := s.repo.GetDocumentByID(ctx, kind, docID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	signatures, err := s.repo.GetDocumentSignatures(ctx, kind.Code, docID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get signatures: %w", op, err)
+	}
+
+	source, err := s.readObject(ctx, tpl.Source.ObjectKey)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	values := s.values(doc, latestSignature(signatures))
+	data, err := Fill(source, values)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	format, mimeType := document_template.FormatDOCX, mimeDOCX
+	if req.Format == document_template.FormatPDF {
+		if data, err = s.convert(ctx, data); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		format, mimeType = document_template.FormatPDF, mimePDF
+	}
+
+	fileName := generatedFileName(tpl.Name, doc, format)
+	stored, err := s.storeFile(ctx, generatedCategory, fileName, mimeType, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	attach := req.Attach == nil || *req.Attach
+	if attach {
+		if err := s.repo.LinkDocumentFiles(ctx, kind, docID, []int64{stored.ID}); err != nil {
+			s.discardFile(ctx, stored.ID, stored.ObjectKey)
+			return nil, fmt.Errorf("%s: failed to attach file: %w", op, err)
+		}
+	}
+
+	unfilled := make([]string, 0)
+	for _, p := range tpl.Placeholders {
+		if values[p] == "" {
+			unfilled = append(unfilled, p)
+		}
+	}
+
+	s.log.Info("document generated from template",
+		slog.Int64("template_id", tpl.ID),
+		slog.String("document_kind", kind.Code),
+		slog.Int64("document_id", docID),
+		slog.Int64("file_id", stored.ID),
+	)
+
+	return &document_template.Generated{
+		FileID:   stored.ID,
+		FileName: fileName,
+		Stored:   stored,
+		Attached: attach,
+		Unfilled: unfilled,
+	}, nil
+}
+
+// values maps every supported placeholder to its text; a missing field is "".
+func (s *Service) values(doc *document.ResponseModel, sig *signature.Signature) map[string]string {
+	date := func(t time.Time) string { return t.In(s.loc).Format(dateLayout) }
+
+	v := map[string]string{
+		document_template.FieldName:         doc.Name,
+		document_template.FieldDocumentDate: date(doc.DocumentDate),
+		document_template.FieldType:         doc.Type.Name,
+		document_template.FieldStatus:       doc.Status.Name,
+		document_template.FieldToday:        date(time.Now()),
+	}
+	if doc.Number != nil {
+		v[document_template.FieldNumber] = *doc.Number
+	}
+	if doc.Description != nil {
+		v[document_template.FieldDescription] = *doc.Description
+	}
+	if doc.Organization != nil {
+		v[document_template.FieldOrganization] = doc.Organization.Name
+	}
+	if doc.ResponsibleContact != nil {
+		v[document_template.FieldResponsibleContact] = doc.ResponsibleContact.Name
+	}
+	if doc.ExecutorContact != nil {
+		v[document_template.FieldExecutor] = doc.ExecutorContact.Name
+	}
+	if doc.DueDate != nil {
+		v[document_template.FieldDueDate] = date(*doc.DueDate)
+	}
+	if sig != nil {
+		if sig.ResolutionText != nil {
+			v[document_template.FieldResolution] = *sig.ResolutionText
+		}
+		if sig.SignedBy != nil && sig.SignedBy.Name != nil {
+			v[document_template.FieldSignedBy] = *sig.SignedBy.Name
+		}
+		v[document_template.FieldSignedAt] = date(sig.SignedAt)
+	}
+	for _, p := range document_template.Placeholders() {
+		if _, ok := v[p.Name]; !ok {
+			v[p.Name] = ""
+		}
+	}
+	return v
+}
+
+// latestSignature returns the newest signing; signatures come newest first.
+func latestSignature(signatures []signature.Signature) *signature.Signature {
+	for i := range signatures {
+		if signatures[i].Action == signature.ActionSigned {
+			return &signatures[i]
+		}
+	}
+	return nil
+}
+
+func generatedFileName(templateName string, doc *document.ResponseModel, format string) string {
+	name := templateName
+	if doc.Number != nil && *doc.Number != "" {
+		name += " " + *doc.Number
+	} else {
+		name += fmt.Sprintf(" %d", doc.ID)
+	}
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	return name + "." + format
+}
+
+func (s *Service) readObject(ctx context.Context, objectKey string) ([]byte, error) {
+	rc, err := s.storage.GetObject(ctx, objectKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open template file: %w", err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template file: %w", err)
+	}
+	return data, nil
+}
+
+// storeFile uploads data under the category's prefix and records it.
+func (s *Service) storeFile(ctx context.Context, categoryName, fileName, mimeType string, data []byte) (file.Model, error) {
+	cat, err := s.repo.GetCategoryByName(ctx, categoryName)
+	if err != nil {
+		return file.Model{}, fmt.Errorf("failed to get file category: %w", err)
+	}
+
+	now := time.Now()
+	stored := file.Model{
+		FileName: fileName,
+		ObjectKey: fmt.Sprintf("%s/%s/%s%s",
+			cat.DisplayName,
+			now.Format("2006/01/02"),
+			uuid.New().String(),
+			filepath.Ext(fileName),
+		),
+		CategoryID: cat.ID,
+		MimeType:   mimeType,
+		SizeBytes:  int64(len(data)),
+		CreatedAt:  now,
+		TargetDate: now,
+	}
+	if err := s.storage.UploadFile(ctx, stored.ObjectKey, bytes.NewReader(data), stored.SizeBytes, mimeType); err != nil {
+		return file.Model{}, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	stored.ID, err = s.repo.AddFile(ctx, stored)
+	if err != nil {
+		s.discardFile(ctx, 0, stored.ObjectKey)
+		return file.Model{}, fmt.Errorf("failed to save file metadata: %w", err)
+	}
+	return stored, nil
+}
+
+// discardFile removes a file stored by a failed operation.
+func (s *Service) discardFile(ctx context.Context, fileID int64, objectKey string) {
+	if err := s.storage.DeleteFile(ctx, objectKey); err != nil {
+		s.log.Error("failed to delete orphaned object", slog.String("object_key", objectKey), slog.String("error", err.Error()))
+	}
+	if fileID == 0 {
+		return
+	}
+	if err := s.repo.DeleteFile(ctx, fileID); err != nil {
+		s.log.Error("failed to delete orphaned file record", slog.Int64("file_id", fileID), slog.String("error", err.Error()))
+	}
+}
+
+// ConvertToPDF converts a DOCX with headless LibreOffice, as the report
+// exports do.
+func ConvertToPDF(ctx context.Context, docx []byte) ([]byte, error) {
+	tempDir, err := os.MkdirTemp("", "doc-template-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	docxPath := filepath.Join(tempDir, "document.docx")
+	if err := os.WriteFile(docxPath, docx, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write docx: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, convertTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "soffice", "--headless", "--convert-to", "pdf", "--outdir", tempDir, docxPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to convert docx to pdf: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	pdf, err := os.ReadFile(filepath.Join(tempDir, "document.pdf"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pdf: %w", err)
+	}
+	return pdf, nil
+}
@@ -0,0 +1,362 @@
+package doctemplates
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"srmt-admin/internal/lib/model/category"
+	"srmt-admin/internal/lib/model/document"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
+	document_template "srmt-admin/internal/lib/model/document-template"
+	"srmt-admin/internal/lib/model/file"
+	"srmt-admin/internal/lib/model/signature"
+	"srmt-admin/internal/lib/model/user"
+	"srmt-admin/internal/storage"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+// buildDOCX makes a minimal DOCX whose body holds the given runs' texts.
+func buildDOCX(t *testing.T, runs ...string) []byte {
+	t.Helper()
+
+	var body strings.Builder
+	body.WriteString(`<?xml version="1.0" encoding="UTF-8"?><w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p>`)
+	for _, r := range runs {
+		body.WriteString(`<w:r><w:rPr><w:b/></w:rPr><w:t>` + r + `</w:t></w:r>`)
+	}
+	body.WriteString(`</w:p></w:body></w:document>`)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"[Content_Types].xml": `<?xml version="1.0"?><Types/>`,
+		"word/document.xml":   body.String(),
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func documentXML(t *testing.T, docx []byte) string {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(docx), int64(len(docx)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			s, err := readEntry(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		}
+	}
+	t.Fatal("word/document.xml missing")
+	return ""
+}
+
+type fakeRepo struct {
+	templates []document_template.Model
+	doc       *document.ResponseModel
+	sigs      []signature.Signature
+	added     *document_template.AddRequest
+	files     []file.Model
+	linked    []int64
+	deleted   []int64
+	linkErr   error
+}
+
+func (f *fakeRepo) DocumentKindExists(_ context.Context, code string) (bool, error) {
+	return code == "letter", nil
+}
+
+func (f *fakeRepo) GetDocumentTemplates(_ context.Context, _ *string, _ bool) ([]document_template.Model, error) {
+	return f.templates, nil
+}
+
+func (f *fakeRepo) GetDocumentTemplate(_ context.Context, id int64) (*document_template.Model, error) {
+	for _, t := range f.templates {
+		if t.ID == id {
+			return &t, nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+func (f *fakeRepo) AddDocumentTemplate(_ context.Context, req document_template.AddRequest, _ int64) (int64, error) {
+	f.added = &req
+	id := int64(len(f.templates) + 1)
+	f.templates = append(f.templates, document_template.Model{
+		ID: id, DocumentKind: req.DocumentKind, Name: req.Name, FileID: req.FileID,
+		Placeholders: req.Placeholders, IsActive: true,
+	})
+	return id, nil
+}
+
+func (f *fakeRepo) EditDocumentTemplate(_ context.Context, _ int64, _ document_template.EditRequest) error {
+	return nil
+}
+
+func (f *fakeRepo) GetDocumentByID(_ context.Context, _ document_kind.Model, id int64) (*document.ResponseModel, error) {
+	if f.doc == nil || f.doc.ID != id {
+		return nil, storage.ErrNotFound
+	}
+	return f.doc, nil
+}
+
+func (f *fakeRepo) GetDocumentSignatures(_ context.Context, _ string, _ int64) ([]signature.Signature, error) {
+	return f.sigs, nil
+}
+
+func (f *fakeRepo) GetCategoryByName(_ context.Context, name string) (category.Model, error) {
+	return category.Model{ID: 7, Name: name, DisplayName: name}, nil
+}
+
+func (f *fakeRepo) AddFile(_ context.Context, m file.Model) (int64, error) {
+	f.files = append(f.files, m)
+	return int64(100 + len(f.files)), nil
+}
+
+func (f *fakeRepo) DeleteFile(_ context.Context, id int64) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func (f *fakeRepo) LinkDocumentFiles(_ context.Context, _ document_kind.Model, _ int64, fileIDs []int64) error {
+	if f.linkErr != nil {
+		return f.linkErr
+	}
+	f.linked = append(f.linked, fileIDs...)
+	return nil
+}
+
+type fakeStorage struct {
+	objects map[string][]byte
+}
+
+func (f *fakeStorage) UploadFile(_ context.Context, objectName string, reader io.Reader, _ int64, _ string) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	f.objects[objectName] = data
+	return nil
+}
+
+func (f *fakeStorage) GetObject(_ context.Context, objectName string) (io.ReadCloser, error) {
+	data, ok := f.objects[objectName]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (f *fakeStorage) DeleteFile(_ context.Context, objectName string) error {
+	delete(f.objects, objectName)
+	return nil
+}
+
+func newTestService(repo *fakeRepo, st *fakeStorage) *Service {
+	return NewService(repo, st, time.UTC, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestScanPlaceholders_SplitRuns(t *testing.T) {
+	docx := buildDOCX(t, "Исх. № {{", "num", "ber}} от {{ document_date }}", "{{number}}")
+
+	got, err := ScanPlaceholders(docx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(got, ",") != "document_date,number" {
+		t.Errorf("placeholders = %v", got)
+	}
+}
+
+func TestScanPlaceholders_NotDOCX(t *testing.T) {
+	if _, err := ScanPlaceholders([]byte("plain text")); !errors.Is(err, ErrNotDOCX) {
+		t.Errorf("err = %v, want ErrNotDOCX", err)
+	}
+}
+
+func TestFill_SplitRunsAndEscaping(t *testing.T) {
+	docx := buildDOCX(t, "Исх. № {{", "num", "ber}}, ", "{{description}}", " {{unknown}}")
+
+	out, err := Fill(docx, map[string]string{
+		"number":      "01-12/345",
+		"description": "A & B\nсрочно",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	xml := documentXML(t, out)
+	for _, want := range []string{
+		`<w:t xml:space="preserve">Исх. № 01-12/345</w:t>`,
+		`<w:t xml:space="preserve"></w:t>`,
+		`<w:t xml:space="preserve">, </w:t>`,
+		`<w:t xml:space="preserve">A &amp; B</w:t><w:br/><w:t xml:space="preserve">срочно</w:t>`,
+		`<w:t> {{unknown}}</w:t>`,
+	} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("document.xml lacks %q:\n%s", want, xml)
+		}
+	}
+}
+
+func TestCreateTemplate_UnknownPlaceholder(t *testing.T) {
+	repo := &fakeRepo{}
+	st := &fakeStorage{objects: map[string][]byte{}}
+	svc := newTestService(repo, st)
+
+	_, err := svc.CreateTemplate(context.Background(), "letter", "Письмо", nil, "letter.docx",
+		buildDOCX(t, "{{number}} {{numbr}}"), 1)
+	if !errors.Is(err, ErrUnknownPlaceholder) || !strings.Contains(err.Error(), "numbr") {
+		t.Fatalf("err = %v, want ErrUnknownPlaceholder naming numbr", err)
+	}
+	if len(st.objects) != 0 || len(repo.files) != 0 {
+		t.Error("rejected template must not be stored")
+	}
+}
+
+func TestCreateTemplate_Stores(t *testing.T) {
+	repo := &fakeRepo{}
+	st := &fakeStorage{objects: map[string][]byte{}}
+	svc := newTestService(repo, st)
+
+	tpl, err := svc.CreateTemplate(context.Background(), "letter", "Письмо", nil, "letter.DOCX",
+		buildDOCX(t, "{{number}} от {{document_date}}"), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tpl.FileID != 101 || strings.Join(repo.added.Placeholders, ",") != "document_date,number" {
+		t.Errorf("template = %+v, added = %+v", tpl, repo.added)
+	}
+	if len(st.objects) != 1 || !strings.HasPrefix(repo.files[0].ObjectKey, templateCategory+"/") {
+		t.Errorf("stored files = %+v", repo.files)
+	}
+
+	if _, err := svc.CreateTemplate(context.Background(), "decree", "X", nil, "x.docx", buildDOCX(t, "x"), 1); !errors.Is(err, ErrUnknownKind) {
+		t.Errorf("err = %v, want ErrUnknownKind", err)
+	}
+	if _, err := svc.CreateTemplate(context.Background(), "letter", "X", nil, "x.doc", buildDOCX(t, "x"), 1); !errors.Is(err, ErrNotDOCX) {
+		t.Errorf("err = %v, want ErrNotDOCX", err)
+	}
+}
+
+func generateFixture(t *testing.T) (*fakeRepo, *fakeStorage) {
+	t.Helper()
+
+	repo := &fakeRepo{
+		templates: []document_template.Model{{
+			ID: 1, DocumentKind: "letter", Name: "Сопроводительное письмо", IsActive: true,
+			Placeholders: []string{"number", "document_date", "executor", "resolution"},
+			Source:       file.Model{ObjectKey: "document-templates/tpl.docx"},
+		}},
+		doc: &document.ResponseModel{
+			ID: 5, Name: "Письмо", Number: ptr("01-12/345"),
+			DocumentDate: time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC),
+		},
+		sigs: []signature.Signature{
+			{Action: signature.ActionRejected, SignedAt: time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)},
+			{Action: signature.ActionSigned, ResolutionText: ptr("К исполнению"),
+				SignedBy: &user.ShortInfo{ID: 2, Name: ptr("Иванов И.И.")}, SignedAt: time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	st := &fakeStorage{objects: map[string][]byte{
+		"document-templates/tpl.docx": buildDOCX(t, "№ {{number}} от {{document_date}}; {{executor}}; {{resolution}}"),
+	}}
+	return repo, st
+}
+
+func TestGenerate_FillsAndAttaches(t *testing.T) {
+	repo, st := generateFixture(t)
+	svc := newTestService(repo, st)
+
+	res, err := svc.Generate(context.Background(), document_kind.Model{Code: "letter"}, 5,
+		document_template.GenerateRequest{TemplateID: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.FileName != "Сопроводительное письмо 01-12_345.docx" || !res.Attached {
+		t.Errorf("result = %+v", res)
+	}
+	if len(repo.linked) != 1 || repo.linked[0] != res.FileID {
+		t.Errorf("linked = %v", repo.linked)
+	}
+	if strings.Join(res.Unfilled, ",") != "executor" {
+		t.Errorf("unfilled = %v", res.Unfilled)
+	}
+
+	out := st.objects[repo.files[0].ObjectKey]
+	if got := documentXML(t, out); !strings.Contains(got, "№ 01-12/345 от 04.03.2026; ; К исполнению") {
+		t.Errorf("generated document.xml = %s", got)
+	}
+}
+
+func TestGenerate_PDFWithoutAttach(t *testing.T) {
+	repo, st := generateFixture(t)
+	svc := newTestService(repo, st)
+	svc.convert = func(_ context.Context, _ []byte) ([]byte, error) { return []byte("%PDF-1.7"), nil }
+
+	res, err := svc.Generate(context.Background(), document_kind.Model{Code: "letter"}, 5,
+		document_template.GenerateRequest{TemplateID: 1, Format: document_template.FormatPDF, Attach: ptr(false)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Attached || len(repo.linked) != 0 {
+		t.Errorf("file must not be attached: %+v", res)
+	}
+	if !strings.HasSuffix(res.FileName, ".pdf") || repo.files[0].MimeType != mimePDF {
+		t.Errorf("result = %+v, file = %+v", res, repo.files[0])
+	}
+}
+
+func TestGenerate_Rejects(t *testing.T) {
+	repo, st := generateFixture(t)
+	svc := newTestService(repo, st)
+	ctx := context.Background()
+
+	if _, err := svc.Generate(ctx, document_kind.Model{Code: "decree"}, 5,
+		document_template.GenerateRequest{TemplateID: 1}); !errors.Is(err, ErrTemplateKindMismatch) {
+		t.Errorf("err = %v, want ErrTemplateKindMismatch", err)
+	}
+
+	repo.templates[0].IsActive = false
+	if _, err := svc.Generate(ctx, document_kind.Model{Code: "letter"}, 5,
+		document_template.GenerateRequest{TemplateID: 1}); !errors.Is(err, ErrTemplateInactive) {
+		t.Errorf("err = %v, want ErrTemplateInactive", err)
+	}
+}
+
+func TestGenerate_AttachFailureDiscardsFile(t *testing.T) {
+	repo, st := generateFixture(t)
+	repo.linkErr = errors.New("db down")
+	svc := newTestService(repo, st)
+
+	if _, err := svc.Generate(context.Background(), document_kind.Model{Code: "letter"}, 5,
+		document_template.GenerateRequest{TemplateID: 1}); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(st.objects) != 1 || len(repo.deleted) != 1 {
+		t.Errorf("generated file must be discarded: objects=%d deleted=%v", len(st.objects), repo.deleted)
+	}
+}
@@ -0,0 +1,337 @@
+// Package docworkflow enforces the status graph of every registered
+// document kind. Every status change — the change-status endpoints, edits
+// carrying a status_id, document creation and signing — is checked against
+// the configured edges, the roles allowed to take them and the comment or
+// attachments they require.
+package docworkflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	document_status "srmt-admin/internal/lib/model/document-status"
+)
+
+// Reason codes carried by TransitionError.
+const (
+	ReasonNotAllowed          = "transition_not_allowed"
+	ReasonRoleForbidden       = "transition_role_forbidden"
+	ReasonCommentRequired     = "transition_comment_required"
+	ReasonAttachmentsRequired = "transition_attachments_required"
+)
+
+var (
+	ErrTransitionNotAllowed = errors.New("status transition not allowed")
+	ErrInvalidGraph         = errors.New("invalid status transition graph")
+	ErrInvalidDocumentType  = errors.New("invalid document type")
+)
+
+// TransitionError explains why a status change was refused. Allowed lists the
+// edges out of the current status that the caller may take.
+type TransitionError struct {
+	Code         string
+	FromStatusID *int
+	ToStatusID   int
+	Allowed      []document_status.Transition
+}
+
+func (e *TransitionError) Error() string {
+	from := "creation"
+	if e.FromStatusID != nil {
+		from = fmt.Sprintf("status %d", *e.FromStatusID)
+	}
+	switch e.Code {
+	case ReasonRoleForbidden:
+		return fmt.Sprintf("role is not allowed to move document from %s to status %d", from, e.ToStatusID)
+	case ReasonCommentRequired:
+		return fmt.Sprintf("comment is required to move document from %s to status %d", from, e.ToStatusID)
+	case ReasonAttachmentsRequired:
+		return fmt.Sprintf("attachments are required to move document from %s to status %d", from, e.ToStatusID)
+	default:
+		return fmt.Sprintf("transition from %s to status %d is not allowed", from, e.ToStatusID)
+	}
+}
+
+func (e *TransitionError) Unwrap() error { return ErrTransitionNotAllowed }
+
+// Actor is the user taking a transition.
+type Actor struct {
+	UserID int64
+	Roles  []string
+}
+
+type Repository interface {
+	DocumentKindExists(ctx context.Context, code string) (bool, error)
+	GetAllDocumentStatuses(ctx context.Context) ([]document_status.Model, error)
+	GetDocumentTransitions(ctx context.Context, docType string) ([]document_status.Transition, error)
+	ReplaceDocumentTransitions(ctx context.Context, docType string, edges []document_status.TransitionInput) error
+	GetDocumentStatusID(ctx context.Context, docType string, docID int64) (int, error)
+	CountDocumentFiles(ctx context.Context, docType string, docID int64) (int, error)
+	ChangeDocumentStatus(ctx context.Context, docType string, docID int64, fromStatusID, toStatusID int, userID int64, comment *string) error
+}
+
+type Service struct {
+	repo Repository
+	log  *slog.Logger
+}
+
+func NewService(repo Repository, log *slog.Logger) *Service {
+	return &Service{
+		repo: repo,
+		log:  log.With(slog.String("service", "document-workflow")),
+	}
+}
+
+// Transitions returns the graph of docType, or of all types when empty.
+func (s *Service) Transitions(ctx context.Context, docType string) ([]document_status.Transition, error) {
+	const op = "service.document-workflow.Transitions"
+
+	if docType != "" {
+		if err := s.checkKind(ctx, docType); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	edges, err := s.repo.GetDocumentTransitions(ctx, docType)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return edges, nil
+}
+
+// ReplaceTransitions validates and stores the whole graph of docType. The
+// graph needs at least one creation edge, must not leave a terminal status
+// and must not list an edge twice.
+func (s *Service) ReplaceTransitions(ctx context.Context, docType string, edges []document_status.TransitionInput) error {
+	const op = "service.document-workflow.ReplaceTransitions"
+
+	if err := s.checkKind(ctx, docType); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	statuses, err := s.repo.GetAllDocumentStatuses(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := validateGraph(edges, statuses); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.repo.ReplaceDocumentTransitions(ctx, docType, edges); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	s.log.Info("status graph replaced", slog.String("document_type", docType), slog.Int("edges", len(edges)))
+	return nil
+}
+
+func validateGraph(edges []document_status.TransitionInput, statuses []document_status.Model) error {
+	byID := make(map[int]document_status.Model, len(statuses))
+	for _, st := range statuses {
+		byID[st.ID] = st
+	}
+
+	type edgeKey struct{ from, to int }
+	seen := make(map[edgeKey]bool, len(edges))
+	hasInitial := false
+	for _, e := range edges {
+		if _, ok := byID[e.ToStatusID]; !ok {
+			return fmt.Errorf("%w: unknown status %d", ErrInvalidGraph, e.ToStatusID)
+		}
+		from := 0
+		if e.FromStatusID == nil {
+			if e.RequireAttachments {
+				return fmt.Errorf("%w: creation transitions cannot require attachments", ErrInvalidGraph)
+			}
+			hasInitial = true
+		} else {
+			st, ok := byID[*e.FromStatusID]
+			if !ok {
+				return fmt.Errorf("%w: unknown status %d", ErrInvalidGraph, *e.FromStatusID)
+			}
+			if st.IsTerminal {
+				return fmt.Errorf("%w: terminal status %q cannot have outgoing transitions", ErrInvalidGraph, st.Code)
+			}
+			if st.ID == e.ToStatusID {
+				return fmt.Errorf("%w: status %q cannot transition to itself", ErrInvalidGraph, st.Code)
+			}
+			from = st.ID
+		}
+		key := edgeKey{from, e.ToStatusID}
+		if seen[key] {
+			return fmt.Errorf("%w: duplicate transition to status %d", ErrInvalidGraph, e.ToStatusID)
+		}
+		seen[key] = true
+	}
+	if !hasInitial {
+		return fmt.Errorf("%w: at least one creation status (from_status_id null) is required", ErrInvalidGraph)
+	}
+	return nil
+}
+
+// AllowedNext returns the edges out of the document's current status that
+// actor may take.
+func (s *Service) AllowedNext(ctx context.Context, docType string, docID int64, actor Actor) ([]document_status.Transition, error) {
+	const op = "service.document-workflow.AllowedNext"
+
+	current, edges, err := s.load(ctx, docType, docID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return allowedFrom(edges, &current, actor.Roles), nil
+}
+
+// Authorize checks that actor may move the document to toStatusID with the
+// given comment and returns the current status. It does not change anything;
+// callers that write the status themselves (signing) use it before their own
+// conditional update.
+func (s *Service) Authorize(ctx context.Context, docType string, docID int64, toStatusID int, comment *string, actor Actor) (int, error) {
+	const op = "service.document-workflow.Authorize"
+
+	current, edges, err := s.load(ctx, docType, docID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	attachments := func() (int, error) { return s.repo.CountDocumentFiles(ctx, docType, docID) }
+	if err := check(edges, &current, toStatusID, comment, actor.Roles, attachments); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return current, nil
+}
+
+// AuthorizeInitial checks that a document of docType may be created in
+// statusID by actor.
+func (s *Service) AuthorizeInitial(ctx context.Context, docType string, statusID int, actor Actor) error {
+	const op = "service.document-workflow.AuthorizeInitial"
+
+	if err := s.checkKind(ctx, docType); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	edges, err := s.repo.GetDocumentTransitions(ctx, docType)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	// A new document has no files linked yet; the handler links them after
+	// the insert, so creation edges cannot require attachments.
+	noFiles := func() (int, error) { return 0, nil }
+	if err := check(edges, nil, statusID, nil, actor.Roles, noFiles); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// ChangeStatus authorizes and applies a status change. Requesting the
+// current status is a no-op, so edit forms that resend status_id keep
+// working. The write is conditional on the status the check was made
+// against; a concurrent change surfaces as storage.ErrInvalidStatus.
+func (s *Service) ChangeStatus(ctx context.Context, docType string, docID int64, toStatusID int, comment *string, actor Actor) error {
+	const op = "service.document-workflow.ChangeStatus"
+
+	current, edges, err := s.load(ctx, docType, docID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if current == toStatusID {
+		return nil
+	}
+	attachments := func() (int, error) { return s.repo.CountDocumentFiles(ctx, docType, docID) }
+	if err := check(edges, &current, toStatusID, comment, actor.Roles, attachments); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.repo.ChangeDocumentStatus(ctx, docType, docID, current, toStatusID, actor.UserID, comment); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (s *Service) load(ctx context.Context, docType string, docID int64) (int, []document_status.Transition, error) {
+	if err := s.checkKind(ctx, docType); err != nil {
+		return 0, nil, err
+	}
+	current, err := s.repo.GetDocumentStatusID(ctx, docType, docID)
+	if err != nil {
+		return 0, nil, err
+	}
+	edges, err := s.repo.GetDocumentTransitions(ctx, docType)
+	if err != nil {
+		return 0, nil, err
+	}
+	return current, edges, nil
+}
+
+// checkKind returns ErrInvalidDocumentType unless docType is a registered
+// document kind.
+func (s *Service) checkKind(ctx context.Context, docType string) error {
+	ok, err := s.repo.DocumentKindExists(ctx, docType)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidDocumentType
+	}
+	return nil
+}
+
+// check finds the edge from → to and verifies its role, comment and
+// attachment requirements. from is nil for creation.
+func check(edges []document_status.Transition, from *int, to int, comment *string, roles []string, attachments func() (int, error)) error {
+	var edge *document_status.Transition
+	for i := range edges {
+		if sameFrom(edges[i].FromStatus, from) && edges[i].ToStatus.ID == to {
+			edge = &edges[i]
+			break
+		}
+	}
+
+	fail := func(code string) error {
+		return &TransitionError{Code: code, FromStatusID: from, ToStatusID: to, Allowed: allowedFrom(edges, from, roles)}
+	}
+
+	if edge == nil {
+		return fail(ReasonNotAllowed)
+	}
+	if !roleAllowed(edge.AllowedRoles, roles) {
+		return fail(ReasonRoleForbidden)
+	}
+	if edge.RequireComment && (comment == nil || *comment == "") {
+		return fail(ReasonCommentRequired)
+	}
+	if edge.RequireAttachments {
+		n, err := attachments()
+		if err != nil {
+			return fmt.Errorf("count attachments: %w", err)
+		}
+		if n == 0 {
+			return fail(ReasonAttachmentsRequired)
+		}
+	}
+	return nil
+}
+
+func allowedFrom(edges []document_status.Transition, from *int, roles []string) []document_status.Transition {
+	result := make([]document_status.Transition, 0)
+	for _, e := range edges {
+		if sameFrom(e.FromStatus, from) && roleAllowed(e.AllowedRoles, roles) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func sameFrom(edgeFrom *document_status.ShortModel, from *int) bool {
+	if edgeFrom == nil || from == nil {
+		return edgeFrom == nil && from == nil
+	}
+	return edgeFrom.ID == *from
+}
+
+func roleAllowed(allowed, roles []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, r := range roles {
+		if slices.Contains(allowed, r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,228 @@
+package docworkflow
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	document_status "srmt-admin/internal/lib/model/document-status"
+)
+
+const (
+	stDraft = iota + 1
+	stPendingApproval
+	stApproved
+	stRejected
+	stInExecution
+	stExecuted
+	stCancelled
+	stPendingSignature
+	stSigned
+)
+
+func status(id int, code string) document_status.ShortModel {
+	return document_status.ShortModel{ID: id, Code: code, Name: code}
+}
+
+func edge(from *document_status.ShortModel, to document_status.ShortModel, roles []string, comment, files bool) document_status.Transition {
+	return document_status.Transition{DocumentType: "decree", FromStatus: from, ToStatus: to,
+		AllowedRoles: roles, RequireComment: comment, RequireAttachments: files}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+type fakeRepo struct {
+	current   int
+	files     int
+	edges     []document_status.Transition
+	changed   bool
+	changedTo int
+	replaced  []document_status.TransitionInput
+}
+
+func (f *fakeRepo) DocumentKindExists(_ context.Context, code string) (bool, error) {
+	return slices.Contains([]string{"decree", "report", "letter", "instruction"}, code), nil
+}
+
+func (f *fakeRepo) GetAllDocumentStatuses(context.Context) ([]document_status.Model, error) {
+	return []document_status.Model{
+		{ID: stDraft, Code: "draft"},
+		{ID: stPendingApproval, Code: "pending_approval"},
+		{ID: stApproved, Code: "approved"},
+		{ID: stRejected, Code: "rejected", IsTerminal: true},
+		{ID: stInExecution, Code: "in_execution"},
+		{ID: stExecuted, Code: "executed", IsTerminal: true},
+		{ID: stCancelled, Code: "cancelled", IsTerminal: true},
+		{ID: stPendingSignature, Code: "pending_signature"},
+		{ID: stSigned, Code: "signed"},
+	}, nil
+}
+
+func (f *fakeRepo) GetDocumentTransitions(context.Context, string) ([]document_status.Transition, error) {
+	return f.edges, nil
+}
+
+func (f *fakeRepo) ReplaceDocumentTransitions(_ context.Context, _ string, edges []document_status.TransitionInput) error {
+	f.replaced = edges
+	return nil
+}
+
+func (f *fakeRepo) GetDocumentStatusID(context.Context, string, int64) (int, error) {
+	return f.current, nil
+}
+
+func (f *fakeRepo) CountDocumentFiles(context.Context, string, int64) (int, error) {
+	return f.files, nil
+}
+
+func (f *fakeRepo) ChangeDocumentStatus(_ context.Context, _ string, _ int64, _, to int, _ int64, _ *string) error {
+	f.changed = true
+	f.changedTo = to
+	return nil
+}
+
+func discardLogger() *slog.Logger { return slog.New(slog.NewTextHandler(io.Discard, nil)) }
+
+func defaultGraph() []document_status.Transition {
+	draft := status(stDraft, "draft")
+	pending := status(stPendingSignature, "pending_signature")
+	signed := status(stSigned, "signed")
+	exec := status(stInExecution, "in_execution")
+	return []document_status.Transition{
+		edge(nil, draft, nil, false, false),
+		edge(&draft, pending, nil, false, false),
+		edge(&draft, status(stCancelled, "cancelled"), nil, true, false),
+		edge(&pending, signed, []string{"rais"}, false, false),
+		edge(&signed, exec, nil, false, false),
+		edge(&exec, status(stExecuted, "executed"), nil, false, true),
+	}
+}
+
+func TestChangeStatus_Enforcement(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  int
+		to       int
+		roles    []string
+		comment  *string
+		files    int
+		wantCode string
+	}{
+		{"allowed edge", stDraft, stPendingSignature, []string{"chancellery"}, nil, 0, ""},
+		{"signed back to draft", stSigned, stDraft, []string{"rais"}, nil, 0, ReasonNotAllowed},
+		{"terminal reopened", stExecuted, stInExecution, []string{"rais"}, nil, 0, ReasonNotAllowed},
+		{"role restricted", stPendingSignature, stSigned, []string{"chancellery"}, nil, 0, ReasonRoleForbidden},
+		{"role granted", stPendingSignature, stSigned, []string{"chancellery", "rais"}, nil, 0, ""},
+		{"comment missing", stDraft, stCancelled, nil, ptr(""), 0, ReasonCommentRequired},
+		{"comment given", stDraft, stCancelled, nil, ptr("дубликат"), 0, ""},
+		{"attachments missing", stInExecution, stExecuted, nil, nil, 0, ReasonAttachmentsRequired},
+		{"attachments given", stInExecution, stExecuted, nil, nil, 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepo{current: tt.current, files: tt.files, edges: defaultGraph()}
+			svc := NewService(repo, discardLogger())
+
+			err := svc.ChangeStatus(context.Background(), "decree", 1, tt.to, tt.comment, Actor{UserID: 1, Roles: tt.roles})
+			if tt.wantCode == "" {
+				if err != nil || !repo.changed || repo.changedTo != tt.to {
+					t.Fatalf("expected change to %d, got err=%v changed=%v", tt.to, err, repo.changed)
+				}
+				return
+			}
+			var tErr *TransitionError
+			if !errors.As(err, &tErr) || tErr.Code != tt.wantCode {
+				t.Fatalf("expected %s, got %v", tt.wantCode, err)
+			}
+			if !errors.Is(err, ErrTransitionNotAllowed) {
+				t.Fatal("TransitionError must unwrap to ErrTransitionNotAllowed")
+			}
+			if repo.changed {
+				t.Fatal("status must not change on refused transition")
+			}
+		})
+	}
+}
+
+func TestChangeStatus_SameStatusIsNoop(t *testing.T) {
+	repo := &fakeRepo{current: stSigned, edges: defaultGraph()}
+	err := NewService(repo, discardLogger()).ChangeStatus(context.Background(), "decree", 1, stSigned, nil, Actor{})
+	if err != nil || repo.changed {
+		t.Fatalf("expected no-op, got err=%v changed=%v", err, repo.changed)
+	}
+}
+
+func TestTransitionError_ListsAllowedForRoles(t *testing.T) {
+	repo := &fakeRepo{current: stPendingSignature, edges: defaultGraph()}
+	svc := NewService(repo, discardLogger())
+
+	_, err := svc.Authorize(context.Background(), "decree", 1, stDraft, nil, Actor{Roles: []string{"chancellery"}})
+	var tErr *TransitionError
+	if !errors.As(err, &tErr) {
+		t.Fatalf("expected TransitionError, got %v", err)
+	}
+	if len(tErr.Allowed) != 0 {
+		t.Fatalf("chancellery may not sign, allowed must be empty: %+v", tErr.Allowed)
+	}
+
+	_, err = svc.Authorize(context.Background(), "decree", 1, stDraft, nil, Actor{Roles: []string{"rais"}})
+	if !errors.As(err, &tErr) || len(tErr.Allowed) != 1 || tErr.Allowed[0].ToStatus.ID != stSigned {
+		t.Fatalf("rais must see the signing edge: %+v", tErr)
+	}
+}
+
+func TestAuthorizeInitial(t *testing.T) {
+	svc := NewService(&fakeRepo{edges: defaultGraph()}, discardLogger())
+	if err := svc.AuthorizeInitial(context.Background(), "decree", stDraft, Actor{}); err != nil {
+		t.Fatalf("draft must be a creation status: %v", err)
+	}
+	if err := svc.AuthorizeInitial(context.Background(), "decree", stSigned, Actor{}); !errors.Is(err, ErrTransitionNotAllowed) {
+		t.Fatalf("creating a signed document must be refused, got %v", err)
+	}
+	if err := svc.AuthorizeInitial(context.Background(), "memo", stDraft, Actor{}); !errors.Is(err, ErrInvalidDocumentType) {
+		t.Fatalf("unregistered kind must be refused, got %v", err)
+	}
+}
+
+func TestReplaceTransitions_Validation(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []document_status.TransitionInput
+		ok    bool
+	}{
+		{"valid", []document_status.TransitionInput{
+			{ToStatusID: stDraft},
+			{FromStatusID: ptr(stDraft), ToStatusID: stPendingSignature},
+		}, true},
+		{"no creation edge", []document_status.TransitionInput{{FromStatusID: ptr(stDraft), ToStatusID: stPendingSignature}}, false},
+		{"leaves terminal", []document_status.TransitionInput{
+			{ToStatusID: stDraft},
+			{FromStatusID: ptr(stExecuted), ToStatusID: stDraft},
+		}, false},
+		{"self loop", []document_status.TransitionInput{
+			{ToStatusID: stDraft},
+			{FromStatusID: ptr(stDraft), ToStatusID: stDraft},
+		}, false},
+		{"duplicate", []document_status.TransitionInput{{ToStatusID: stDraft}, {ToStatusID: stDraft}}, false},
+		{"unknown status", []document_status.TransitionInput{{ToStatusID: 99}}, false},
+		{"creation requires files", []document_status.TransitionInput{{ToStatusID: stDraft, RequireAttachments: true}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepo{}
+			err := NewService(repo, discardLogger()).ReplaceTransitions(context.Background(), "letter", tt.edges)
+			if tt.ok {
+				if err != nil || repo.replaced == nil {
+					t.Fatalf("expected save, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidGraph) || repo.replaced != nil {
+				t.Fatalf("expected ErrInvalidGraph without save, got %v", err)
+			}
+		})
+	}
+}
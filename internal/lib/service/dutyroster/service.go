@@ -0,0 +1,348 @@
+// Package dutyroster holds the business logic for the station shift
+// roster: expanding rotation patterns into monthly shifts, approving
+// shift swaps and answering "who is on duty now".
+package dutyroster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	rostermodel "srmt-admin/internal/lib/model/duty-roster"
+	"srmt-admin/internal/storage"
+)
+
+// Repository describes the storage-level methods the service depends on.
+// Defined here (not in the repo package) so tests can satisfy it with a
+// thin mock and the service stays decoupled from *Repo.
+type Repository interface {
+	CreateShiftPattern(ctx context.Context, req rostermodel.PatternRequest, createdByUserID int64) (int64, error)
+	UpdateShiftPattern(ctx context.Context, id int64, req rostermodel.PatternRequest) error
+	DeleteShiftPattern(ctx context.Context, id int64) error
+	GetShiftPatterns(ctx context.Context, orgID *int64) ([]rostermodel.Pattern, error)
+	GetShiftPatternByID(ctx context.Context, id int64) (*rostermodel.Pattern, error)
+
+	ReplaceGeneratedShifts(ctx context.Context, patternID int64, from, to time.Time, shifts []rostermodel.Shift, createdByUserID int64) (*rostermodel.GenerateResult, error)
+	GetRoster(ctx context.Context, f rostermodel.RosterFilter) ([]rostermodel.Shift, error)
+	GetEmployeeShifts(ctx context.Context, employeeID int64, from, to time.Time) ([]rostermodel.Shift, error)
+	GetOnDutyShifts(ctx context.Context, orgIDs []int64, at time.Time) ([]rostermodel.Shift, error)
+	GetShiftByID(ctx context.Context, id int64) (*rostermodel.Shift, error)
+	CreateShift(ctx context.Context, req rostermodel.CreateShiftRequest, createdByUserID int64) (int64, error)
+	DeleteShift(ctx context.Context, id int64) error
+
+	CreateShiftSwap(ctx context.Context, shiftID int64, req rostermodel.SwapRequest, requestedByUserID int64) (int64, error)
+	GetShiftSwaps(ctx context.Context, f rostermodel.SwapFilter) ([]rostermodel.Swap, error)
+	GetShiftSwapByID(ctx context.Context, id int64) (*rostermodel.Swap, error)
+	DecideShiftSwap(ctx context.Context, id int64, approve bool, decidedByUserID int64) error
+}
+
+// Service is the duty-roster business layer.
+type Service struct {
+	repo Repository
+	loc  *time.Location
+	now  func() time.Time
+}
+
+// NewService wires the Service to a Repository. loc is the station
+// wall-clock zone: pattern start times and month boundaries are read in it.
+func NewService(repo Repository, loc *time.Location) *Service {
+	return &Service{repo: repo, loc: loc, now: time.Now}
+}
+
+// --- Patterns ---
+
+// CreatePattern validates the pattern's shift codes and stores it.
+func (s *Service) CreatePattern(ctx context.Context, req rostermodel.PatternRequest, createdByUserID int64) (*rostermodel.Pattern, error) {
+	const op = "service.dutyroster.CreatePattern"
+
+	if err := checkPattern(req); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	id, err := s.repo.CreateShiftPattern(ctx, req, createdByUserID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return s.repo.GetShiftPatternByID(ctx, id)
+}
+
+// UpdatePattern replaces the pattern definition and members. The
+// organization of a pattern never changes.
+func (s *Service) UpdatePattern(ctx context.Context, id int64, req rostermodel.PatternRequest) (*rostermodel.Pattern, error) {
+	const op = "service.dutyroster.UpdatePattern"
+
+	if err := checkPattern(req); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.repo.UpdateShiftPattern(ctx, id, req); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return s.repo.GetShiftPatternByID(ctx, id)
+}
+
+// GetPattern returns one pattern or storage.ErrShiftPatternNotFound.
+func (s *Service) GetPattern(ctx context.Context, id int64) (*rostermodel.Pattern, error) {
+	const op = "service.dutyroster.GetPattern"
+	p, err := s.repo.GetShiftPatternByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return p, nil
+}
+
+// ListPatterns returns the patterns of one organization, or of all when
+// orgID is nil.
+func (s *Service) ListPatterns(ctx context.Context, orgID *int64) ([]rostermodel.Pattern, error) {
+	const op = "service.dutyroster.ListPatterns"
+	out, err := s.repo.GetShiftPatterns(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return out, nil
+}
+
+// DeletePattern removes a pattern; its shifts stay in the roster.
+func (s *Service) DeletePattern(ctx context.Context, id int64) error {
+	const op = "service.dutyroster.DeletePattern"
+	if err := s.repo.DeleteShiftPattern(ctx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// --- Roster ---
+
+// Generate expands a pattern into the shifts of one month and replaces the
+// month's previously generated shifts. Swapped shifts and shifts with an
+// open swap request are kept as they are.
+func (s *Service) Generate(ctx context.Context, patternID int64, year, month int, createdByUserID int64) (*rostermodel.GenerateResult, error) {
+	const op = "service.dutyroster.Generate"
+
+	p, err := s.repo.GetShiftPatternByID(ctx, patternID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	shifts, err := buildShifts(p, year, time.Month(month), s.loc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, s.loc)
+	res, err := s.repo.ReplaceGeneratedShifts(ctx, patternID, from, from.AddDate(0, 1, 0), shifts, createdByUserID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	res.Year, res.Month = year, month
+	return res, nil
+}
+
+// Roster returns an organization's shifts in a window.
+func (s *Service) Roster(ctx context.Context, f rostermodel.RosterFilter) ([]rostermodel.Shift, error) {
+	const op = "service.dutyroster.Roster"
+	out, err := s.repo.GetRoster(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return out, nil
+}
+
+// EmployeeShifts returns one employee's shifts in a window.
+func (s *Service) EmployeeShifts(ctx context.Context, employeeID int64, from, to time.Time) ([]rostermodel.Shift, error) {
+	const op = "service.dutyroster.EmployeeShifts"
+	out, err := s.repo.GetEmployeeShifts(ctx, employeeID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return out, nil
+}
+
+// OnDuty returns who stands duty at the organization at the given instant.
+func (s *Service) OnDuty(ctx context.Context, orgID int64, at time.Time) (*rostermodel.OnDuty, error) {
+	const op = "service.dutyroster.OnDuty"
+
+	shifts, err := s.repo.GetOnDutyShifts(ctx, []int64{orgID}, at)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if shifts == nil {
+		shifts = []rostermodel.Shift{}
+	}
+	return &rostermodel.OnDuty{OrganizationID: orgID, At: at, Officers: shifts}, nil
+}
+
+// GetShift returns one shift or storage.ErrShiftNotFound.
+func (s *Service) GetShift(ctx context.Context, id int64) (*rostermodel.Shift, error) {
+	const op = "service.dutyroster.GetShift"
+	shift, err := s.repo.GetShiftByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return shift, nil
+}
+
+// CreateShift adds a manual shift outside any pattern.
+func (s *Service) CreateShift(ctx context.Context, req rostermodel.CreateShiftRequest, createdByUserID int64) (*rostermodel.Shift, error) {
+	const op = "service.dutyroster.CreateShift"
+
+	id, err := s.repo.CreateShift(ctx, req, createdByUserID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return s.repo.GetShiftByID(ctx, id)
+}
+
+// DeleteShift removes a shift and its swap requests.
+func (s *Service) DeleteShift(ctx context.Context, id int64) error {
+	const op = "service.dutyroster.DeleteShift"
+	if err := s.repo.DeleteShift(ctx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// --- Swaps ---
+
+// RequestSwap files a request to hand a shift over. Shifts that have
+// already ended cannot be swapped, and the replacement must be someone
+// other than the employee on the shift.
+func (s *Service) RequestSwap(ctx context.Context, shiftID int64, req rostermodel.SwapRequest, requestedByUserID int64) (*rostermodel.Swap, error) {
+	const op = "service.dutyroster.RequestSwap"
+
+	shift, err := s.repo.GetShiftByID(ctx, shiftID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !shift.EndsAt.After(s.now()) {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrShiftAlreadyEnded)
+	}
+	if req.ReplacementID == shift.EmployeeID {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrShiftOverlap)
+	}
+
+	id, err := s.repo.CreateShiftSwap(ctx, shiftID, req, requestedByUserID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return s.repo.GetShiftSwapByID(ctx, id)
+}
+
+// ListSwaps returns swap requests matching the filter.
+func (s *Service) ListSwaps(ctx context.Context, f rostermodel.SwapFilter) ([]rostermodel.Swap, error) {
+	const op = "service.dutyroster.ListSwaps"
+	out, err := s.repo.GetShiftSwaps(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return out, nil
+}
+
+// GetSwap returns one swap request or storage.ErrSwapNotFound.
+func (s *Service) GetSwap(ctx context.Context, id int64) (*rostermodel.Swap, error) {
+	const op = "service.dutyroster.GetSwap"
+	w, err := s.repo.GetShiftSwapByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return w, nil
+}
+
+// DecideSwap approves or rejects a pending swap. The requester cannot
+// decide their own request, and a shift that has already ended can only
+// be rejected.
+func (s *Service) DecideSwap(ctx context.Context, id int64, approve bool, decidedByUserID int64) (*rostermodel.Swap, error) {
+	const op = "service.dutyroster.DecideSwap"
+
+	w, err := s.repo.GetShiftSwapByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if w.RequestedByUserID != nil && *w.RequestedByUserID == decidedByUserID {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrSwapSelfDecision)
+	}
+	if approve && !w.EndsAt.After(s.now()) {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrShiftAlreadyEnded)
+	}
+
+	if err := s.repo.DecideShiftSwap(ctx, id, approve, decidedByUserID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return s.repo.GetShiftSwapByID(ctx, id)
+}
+
+// --- Generation ---
+
+// checkPattern rejects duplicate shift codes and cycle days naming a code
+// the pattern does not define.
+func checkPattern(req rostermodel.PatternRequest) error {
+	defined := make(map[string]bool, len(req.Shifts))
+	for _, d := range req.Shifts {
+		if defined[d.Code] {
+			return storage.ErrInvalidShiftPattern
+		}
+		defined[d.Code] = true
+	}
+	for _, code := range req.Cycle {
+		if code != rostermodel.OffDay && !defined[code] {
+			return storage.ErrInvalidShiftPattern
+		}
+	}
+	return nil
+}
+
+// buildShifts expands the pattern into the shifts starting in the given
+// month. Day d of the month is cycle position (days since anchor + member
+// offset) mod cycle length; shifts that run past midnight end the next day.
+func buildShifts(p *rostermodel.Pattern, year int, month time.Month, loc *time.Location) ([]rostermodel.Shift, error) {
+	anchor, err := time.Parse(time.DateOnly, p.AnchorDate)
+	if err != nil {
+		return nil, fmt.Errorf("anchor date %q: %w", p.AnchorDate, err)
+	}
+
+	type start struct{ hour, minute int }
+	defs := make(map[string]rostermodel.ShiftDef, len(p.Shifts))
+	starts := make(map[string]start, len(p.Shifts))
+	for _, d := range p.Shifts {
+		t, err := time.Parse("15:04", d.Start)
+		if err != nil {
+			return nil, fmt.Errorf("shift %s start %q: %w", d.Code, d.Start, err)
+		}
+		defs[d.Code] = d
+		starts[d.Code] = start{t.Hour(), t.Minute()}
+	}
+
+	n := len(p.Cycle)
+	if n == 0 {
+		return nil, nil
+	}
+	days := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	// Day arithmetic runs on UTC calendar dates so DST jumps in loc never
+	// skew the cycle position.
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	sinceAnchor := int(first.Sub(anchor).Hours() / 24)
+
+	var shifts []rostermodel.Shift
+	for _, m := range p.Members {
+		for d := 0; d < days; d++ {
+			pos := ((sinceAnchor+d+m.OffsetDays)%n + n) % n
+			code := p.Cycle[pos]
+			if code == rostermodel.OffDay {
+				continue
+			}
+			def, ok := defs[code]
+			if !ok {
+				return nil, fmt.Errorf("cycle day %d: %w", pos, storage.ErrInvalidShiftPattern)
+			}
+			st := starts[code]
+			begin := time.Date(year, month, d+1, st.hour, st.minute, 0, 0, loc)
+			shifts = append(shifts, rostermodel.Shift{
+				OrganizationID: p.OrganizationID,
+				PatternID:      &p.ID,
+				EmployeeID:     m.EmployeeID,
+				ShiftCode:      code,
+				StartsAt:       begin,
+				EndsAt:         begin.Add(time.Duration(def.Hours) * time.Hour),
+				Source:         rostermodel.SourceGenerated,
+			})
+		}
+	}
+	return shifts, nil
+}
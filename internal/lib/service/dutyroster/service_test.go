@@ -0,0 +1,260 @@
+package dutyroster
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	rostermodel "srmt-admin/internal/lib/model/duty-roster"
+	"srmt-admin/internal/storage"
+)
+
+// --- mock Repository ---
+
+// mockRepo records what the service wrote. The pattern CRUD and roster
+// reads are plain pass-throughs with no tests here, so they panic.
+type mockRepo struct {
+	pattern *rostermodel.Pattern
+
+	replaceFrom, replaceTo time.Time
+	replaceShifts          []rostermodel.Shift
+
+	shift *rostermodel.Shift
+	swap  *rostermodel.Swap
+
+	swapCreated bool
+	decided     *bool
+}
+
+func (m *mockRepo) GetShiftPatternByID(context.Context, int64) (*rostermodel.Pattern, error) {
+	if m.pattern == nil {
+		return nil, storage.ErrShiftPatternNotFound
+	}
+	return m.pattern, nil
+}
+
+func (m *mockRepo) ReplaceGeneratedShifts(_ context.Context, patternID int64, from, to time.Time, shifts []rostermodel.Shift, _ int64) (*rostermodel.GenerateResult, error) {
+	m.replaceFrom, m.replaceTo, m.replaceShifts = from, to, shifts
+	return &rostermodel.GenerateResult{PatternID: patternID, Created: len(shifts)}, nil
+}
+
+func (m *mockRepo) GetShiftByID(context.Context, int64) (*rostermodel.Shift, error) {
+	return m.shift, nil
+}
+
+func (m *mockRepo) CreateShiftSwap(context.Context, int64, rostermodel.SwapRequest, int64) (int64, error) {
+	m.swapCreated = true
+	return 1, nil
+}
+
+func (m *mockRepo) GetShiftSwapByID(context.Context, int64) (*rostermodel.Swap, error) {
+	return m.swap, nil
+}
+
+func (m *mockRepo) DecideShiftSwap(_ context.Context, _ int64, approve bool, _ int64) error {
+	m.decided = &approve
+	return nil
+}
+
+func (m *mockRepo) CreateShiftPattern(context.Context, rostermodel.PatternRequest, int64) (int64, error) {
+	panic("not implemented")
+}
+func (m *mockRepo) UpdateShiftPattern(context.Context, int64, rostermodel.PatternRequest) error {
+	panic("not implemented")
+}
+func (m *mockRepo) DeleteShiftPattern(context.Context, int64) error {
+	panic("not implemented")
+}
+func (m *mockRepo) GetShiftPatterns(context.Context, *int64) ([]rostermodel.Pattern, error) {
+	panic("not implemented")
+}
+func (m *mockRepo) GetRoster(context.Context, rostermodel.RosterFilter) ([]rostermodel.Shift, error) {
+	panic("not implemented")
+}
+func (m *mockRepo) GetEmployeeShifts(context.Context, int64, time.Time, time.Time) ([]rostermodel.Shift, error) {
+	panic("not implemented")
+}
+func (m *mockRepo) GetOnDutyShifts(context.Context, []int64, time.Time) ([]rostermodel.Shift, error) {
+	panic("not implemented")
+}
+func (m *mockRepo) CreateShift(context.Context, rostermodel.CreateShiftRequest, int64) (int64, error) {
+	panic("not implemented")
+}
+func (m *mockRepo) DeleteShift(context.Context, int64) error {
+	panic("not implemented")
+}
+func (m *mockRepo) GetShiftSwaps(context.Context, rostermodel.SwapFilter) ([]rostermodel.Swap, error) {
+	panic("not implemented")
+}
+
+func newTestService(repo *mockRepo, now time.Time) *Service {
+	svc := NewService(repo, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+func int64Ptr(v int64) *int64 { return &v }
+
+// fourCrewPattern is the classic 12h day/night rotation: each crew works a
+// day shift, then a night shift, then has two days off.
+func fourCrewPattern() *rostermodel.Pattern {
+	return &rostermodel.Pattern{
+		ID:             7,
+		OrganizationID: 3,
+		Shifts: []rostermodel.ShiftDef{
+			{Code: "D", Start: "08:00", Hours: 12},
+			{Code: "N", Start: "20:00", Hours: 12},
+		},
+		Cycle:      []string{"D", "N", "-", "-"},
+		AnchorDate: "2026-01-01",
+		Members: []rostermodel.Member{
+			{EmployeeID: 100, OffsetDays: 0},
+			{EmployeeID: 101, OffsetDays: 1},
+			{EmployeeID: 102, OffsetDays: 2},
+			{EmployeeID: 103, OffsetDays: 3},
+		},
+	}
+}
+
+func TestGenerate_FourCrewRotationCoversEveryHour(t *testing.T) {
+	repo := &mockRepo{pattern: fourCrewPattern()}
+	svc := newTestService(repo, time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC))
+
+	res, err := svc.Generate(context.Background(), 7, 2026, 3, 1)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if res.Year != 2026 || res.Month != 3 {
+		t.Errorf("result month = %d-%d, want 2026-3", res.Year, res.Month)
+	}
+	if !repo.replaceFrom.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) ||
+		!repo.replaceTo.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("window = [%v, %v), want March", repo.replaceFrom, repo.replaceTo)
+	}
+
+	// 31 days × one day and one night shift.
+	if len(repo.replaceShifts) != 62 {
+		t.Fatalf("%d shifts, want 62", len(repo.replaceShifts))
+	}
+
+	// Every hour of March from 08:00 on the 1st is covered by exactly one shift.
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	for h := start; h.Before(time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)); h = h.Add(time.Hour) {
+		covering := 0
+		for _, s := range repo.replaceShifts {
+			if !s.StartsAt.After(h) && s.EndsAt.After(h) {
+				covering++
+			}
+		}
+		if covering != 1 {
+			t.Fatalf("%v covered by %d shifts, want 1", h, covering)
+		}
+	}
+
+	// 2026-03-01 is day 59 since the anchor: 59 mod 4 = 3, so the crew at
+	// offset 1 reaches cycle position 0 (day) and offset 2 position 1 (night).
+	for _, s := range repo.replaceShifts {
+		if s.StartsAt.Day() != 1 {
+			continue
+		}
+		switch s.ShiftCode {
+		case "D":
+			if s.EmployeeID != 101 {
+				t.Errorf("day shift on March 1 stood by %d, want 101", s.EmployeeID)
+			}
+		case "N":
+			if s.EmployeeID != 102 {
+				t.Errorf("night shift on March 1 stood by %d, want 102", s.EmployeeID)
+			}
+			if !s.EndsAt.Equal(time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)) {
+				t.Errorf("night shift ends %v, want next morning", s.EndsAt)
+			}
+		}
+		if s.Source != rostermodel.SourceGenerated || s.PatternID == nil || *s.PatternID != 7 {
+			t.Errorf("shift not marked as generated from pattern 7: %+v", s)
+		}
+	}
+}
+
+func TestGenerate_StartTimesAreLocal(t *testing.T) {
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	p := fourCrewPattern()
+
+	shifts, err := buildShifts(p, 2026, time.March, loc)
+	if err != nil {
+		t.Fatalf("buildShifts: %v", err)
+	}
+	for _, s := range shifts {
+		if s.ShiftCode == "D" && s.StartsAt.UTC().Hour() != 3 {
+			t.Fatalf("day shift starts %v, want 08:00 local (03:00 UTC)", s.StartsAt)
+		}
+	}
+}
+
+func TestCheckPattern_RejectsUndefinedAndDuplicateCodes(t *testing.T) {
+	valid := rostermodel.PatternRequest{
+		Shifts: []rostermodel.ShiftDef{{Code: "D", Start: "08:00", Hours: 12}},
+		Cycle:  []string{"D", "-"},
+	}
+	if err := checkPattern(valid); err != nil {
+		t.Errorf("valid pattern: %v", err)
+	}
+
+	undefined := valid
+	undefined.Cycle = []string{"D", "N"}
+	if err := checkPattern(undefined); !errors.Is(err, storage.ErrInvalidShiftPattern) {
+		t.Errorf("undefined code: err = %v, want ErrInvalidShiftPattern", err)
+	}
+
+	duplicate := valid
+	duplicate.Shifts = append(duplicate.Shifts, rostermodel.ShiftDef{Code: "D", Start: "20:00", Hours: 12})
+	if err := checkPattern(duplicate); !errors.Is(err, storage.ErrInvalidShiftPattern) {
+		t.Errorf("duplicate code: err = %v, want ErrInvalidShiftPattern", err)
+	}
+}
+
+func TestRequestSwap_RefusesEndedShiftAndSameEmployee(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo := &mockRepo{shift: &rostermodel.Shift{
+		ID: 5, EmployeeID: 100,
+		StartsAt: now.Add(-13 * time.Hour), EndsAt: now.Add(-time.Hour),
+	}}
+	svc := newTestService(repo, now)
+
+	if _, err := svc.RequestSwap(context.Background(), 5, rostermodel.SwapRequest{ReplacementID: 101}, 1); !errors.Is(err, storage.ErrShiftAlreadyEnded) {
+		t.Errorf("ended shift: err = %v, want ErrShiftAlreadyEnded", err)
+	}
+
+	repo.shift.EndsAt = now.Add(time.Hour)
+	if _, err := svc.RequestSwap(context.Background(), 5, rostermodel.SwapRequest{ReplacementID: 100}, 1); !errors.Is(err, storage.ErrShiftOverlap) {
+		t.Errorf("same employee: err = %v, want ErrShiftOverlap", err)
+	}
+	if repo.swapCreated {
+		t.Error("swap was stored")
+	}
+}
+
+func TestDecideSwap_RequesterCannotDecide(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo := &mockRepo{swap: &rostermodel.Swap{
+		ID: 9, Status: rostermodel.SwapPending,
+		RequestedByUserID: int64Ptr(1),
+		EndsAt:            now.Add(time.Hour),
+	}}
+	svc := newTestService(repo, now)
+
+	if _, err := svc.DecideSwap(context.Background(), 9, true, 1); !errors.Is(err, storage.ErrSwapSelfDecision) {
+		t.Errorf("self decision: err = %v, want ErrSwapSelfDecision", err)
+	}
+	if repo.decided != nil {
+		t.Fatal("swap was decided by its requester")
+	}
+
+	if _, err := svc.DecideSwap(context.Background(), 9, true, 2); err != nil {
+		t.Fatalf("DecideSwap: %v", err)
+	}
+	if repo.decided == nil || !*repo.decided {
+		t.Error("swap was not approved")
+	}
+}
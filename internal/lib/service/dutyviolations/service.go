@@ -6,9 +6,15 @@ package dutyviolations
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"srmt-admin/internal/lib/model/contact"
+	rostermodel "srmt-admin/internal/lib/model/duty-roster"
 	dvmodel "srmt-admin/internal/lib/model/duty-violations"
+	"srmt-admin/internal/storage"
 )
 
 // Repository describes the storage-level methods the service depends on.
@@ -25,6 +31,11 @@ type Repository interface {
 	GetDutyViolations(ctx context.Context, f dvmodel.ListFilter) ([]dvmodel.OrgGroup, error)
 	GetDutyViolationByID(ctx context.Context, id int64) (*dvmodel.DutyViolation, error)
 	DeleteDutyViolation(ctx context.Context, id int64) error
+
+	// Duty officer lookups: the picked employee's name, and the shift
+	// roster at the violation's start time.
+	GetContactByID(ctx context.Context, id int64) (*contact.Model, error)
+	GetOnDutyShifts(ctx context.Context, orgIDs []int64, at time.Time) ([]rostermodel.Shift, error)
 }
 
 // Service is the duty-violations business layer.
@@ -45,6 +56,12 @@ func NewService(repo Repository) *Service {
 func (s *Service) Create(ctx context.Context, req dvmodel.CreateRequest, createdByUserID int64) (*dvmodel.DutyViolation, error) {
 	const op = "service.dutyviolations.Create"
 
+	officerID, officerName, err := s.resolveOfficer(ctx, req.OrganizationID, req.StartTime, req.DutyOfficerID, req.DutyOfficerName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	req.DutyOfficerID, req.DutyOfficerName = officerID, officerName
+
 	id, err := s.repo.AddDutyViolationWithFiles(ctx, req, createdByUserID)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -86,6 +103,12 @@ func (s *Service) List(ctx context.Context, f dvmodel.ListFilter) ([]dvmodel.Org
 func (s *Service) Update(ctx context.Context, id int64, req dvmodel.UpdateRequest) (*dvmodel.DutyViolation, error) {
 	const op = "service.dutyviolations.Update"
 
+	officerID, officerName, err := s.resolveOfficer(ctx, req.OrganizationID, req.StartTime, req.DutyOfficerID, req.DutyOfficerName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	req.DutyOfficerID, req.DutyOfficerName = officerID, officerName
+
 	if err := s.repo.UpdateDutyViolationWithFiles(ctx, id, req); err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -102,3 +125,35 @@ func (s *Service) Delete(ctx context.Context, id int64) error {
 	}
 	return nil
 }
+
+// resolveOfficer links the record to a real employee. An explicit
+// officer id wins and supplies the stored name, so the text always
+// matches the linked contact. Without one, the roster is consulted: if
+// an officer on shift at the start time carries exactly the typed name,
+// the record is linked to them; otherwise it keeps the free-text name
+// unlinked — guessing a person from a partial name is worse than no link.
+func (s *Service) resolveOfficer(ctx context.Context, orgID int64, at time.Time, officerID *int64, name string) (*int64, string, error) {
+	if officerID != nil {
+		c, err := s.repo.GetContactByID(ctx, *officerID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return nil, "", fmt.Errorf("duty officer %d: %w", *officerID, storage.ErrForeignKeyViolation)
+			}
+			return nil, "", fmt.Errorf("load duty officer: %w", err)
+		}
+		return officerID, c.Name, nil
+	}
+
+	shifts, err := s.repo.GetOnDutyShifts(ctx, []int64{orgID}, at)
+	if err != nil {
+		return nil, "", fmt.Errorf("load roster: %w", err)
+	}
+	typed := strings.TrimSpace(name)
+	for _, sh := range shifts {
+		if strings.EqualFold(strings.TrimSpace(sh.EmployeeName), typed) {
+			id := sh.EmployeeID
+			return &id, sh.EmployeeName, nil
+		}
+	}
+	return nil, name, nil
+}
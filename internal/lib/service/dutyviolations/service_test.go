@@ -7,7 +7,10 @@ import (
 	"testing"
 	"time"
 
+	"srmt-admin/internal/lib/model/contact"
+	rostermodel "srmt-admin/internal/lib/model/duty-roster"
 	dvmodel "srmt-admin/internal/lib/model/duty-violations"
+	"srmt-admin/internal/storage"
 )
 
 // --- mock Repository ---
@@ -32,6 +35,10 @@ type mockRepo struct {
 	listResult    []dvmodel.OrgGroup
 	listErr       error
 	listGotFilter dvmodel.ListFilter
+
+	contacts  map[int64]string
+	onDuty    []rostermodel.Shift
+	onDutyAt  time.Time
 }
 
 func (m *mockRepo) AddDutyViolationWithFiles(_ context.Context, req dvmodel.CreateRequest, userID int64) (int64, error) {
@@ -65,6 +72,19 @@ func (m *mockRepo) DeleteDutyViolation(_ context.Context, _ int64) error {
 	return m.deleteErr
 }
 
+func (m *mockRepo) GetContactByID(_ context.Context, id int64) (*contact.Model, error) {
+	name, ok := m.contacts[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &contact.Model{ID: id, Name: name}, nil
+}
+
+func (m *mockRepo) GetOnDutyShifts(_ context.Context, _ []int64, at time.Time) ([]rostermodel.Shift, error) {
+	m.onDutyAt = at
+	return m.onDuty, nil
+}
+
 // --- helpers ---
 
 func validReq() dvmodel.CreateRequest {
@@ -235,3 +255,68 @@ func TestList_PassesFilterAndReturnsGroups(t *testing.T) {
 		t.Errorf("filter not forwarded correctly: %+v", repo.listGotFilter)
 	}
 }
+
+// --- Duty officer linking ---
+
+// An explicit duty_officer_id wins: the stored name is the employee's, not
+// whatever text came with the request.
+func TestCreate_ExplicitOfficerSuppliesName(t *testing.T) {
+	repo := &mockRepo{addID: 42, contacts: map[int64]string{100: "Каримов Азиз"}}
+	svc := NewService(repo)
+	req := validReq()
+	officer := int64(100)
+	req.DutyOfficerID = &officer
+	req.DutyOfficerName = ""
+
+	if _, err := svc.Create(context.Background(), req, 7); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if repo.addReq.DutyOfficerID == nil || *repo.addReq.DutyOfficerID != 100 || repo.addReq.DutyOfficerName != "Каримов Азиз" {
+		t.Errorf("officer not linked: id=%v name=%q", repo.addReq.DutyOfficerID, repo.addReq.DutyOfficerName)
+	}
+}
+
+func TestCreate_UnknownOfficerIsForeignKeyViolation(t *testing.T) {
+	repo := &mockRepo{addID: 42}
+	svc := NewService(repo)
+	req := validReq()
+	officer := int64(100)
+	req.DutyOfficerID = &officer
+
+	if _, err := svc.Create(context.Background(), req, 7); !errors.Is(err, storage.ErrForeignKeyViolation) {
+		t.Errorf("err = %v, want ErrForeignKeyViolation", err)
+	}
+	if repo.addCalled {
+		t.Error("record stored with an unknown officer")
+	}
+}
+
+// A typed name is linked to the rostered officer only on an exact
+// (case-insensitive) match; anything else stays free text.
+func TestCreate_TypedNameLinkedToRosteredOfficer(t *testing.T) {
+	repo := &mockRepo{addID: 42, onDuty: []rostermodel.Shift{
+		{EmployeeID: 100, EmployeeName: "Каримов Азиз"},
+		{EmployeeID: 101, EmployeeName: "Иванов И.И."},
+	}}
+	svc := NewService(repo)
+	req := validReq()
+	req.DutyOfficerName = " иванов и.и. "
+
+	if _, err := svc.Create(context.Background(), req, 7); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !repo.onDutyAt.Equal(req.StartTime) {
+		t.Errorf("roster consulted at %v, want the start time", repo.onDutyAt)
+	}
+	if repo.addReq.DutyOfficerID == nil || *repo.addReq.DutyOfficerID != 101 || repo.addReq.DutyOfficerName != "Иванов И.И." {
+		t.Errorf("typed name not linked: id=%v name=%q", repo.addReq.DutyOfficerID, repo.addReq.DutyOfficerName)
+	}
+
+	req.DutyOfficerName = "Петров"
+	if _, err := svc.Create(context.Background(), req, 7); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if repo.addReq.DutyOfficerID != nil || repo.addReq.DutyOfficerName != "Петров" {
+		t.Errorf("unmatched name must stay unlinked: id=%v name=%q", repo.addReq.DutyOfficerID, repo.addReq.DutyOfficerName)
+	}
+}
@@ -0,0 +1,76 @@
+package execcontrol
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"srmt-admin/internal/lib/service/notification"
+)
+
+// RemindDue notifies executors of their items due within soonDays of date or
+// already overdue, and controllers of the overdue ones. It runs once a day,
+// so an overdue item is repeated daily until it is submitted.
+func (s *Service) RemindDue(ctx context.Context, date string) {
+	items, err := s.Due(ctx, date, s.soonDays)
+	if err != nil {
+		s.log.Error("failed to list due items", slog.String("date", date), slog.String("error", err.Error()))
+		return
+	}
+
+	var sent int
+	for i := range items {
+		c := &items[i]
+		if c.DaysLeft == nil {
+			continue
+		}
+		label := documentLabel(c)
+		if c.Overdue {
+			message := fmt.Sprintf("%s: срок исполнения %s просрочен на %d дн.", label, c.DueDate.Format("02.01.2006"), -*c.DaysLeft)
+			s.notify(ctx, notification.ExecutionOverdue, c.Executor.ID, message, c.ID)
+			sent++
+			if c.ControllerContactID != nil {
+				s.notify(ctx, notification.ExecutionOverdue, *c.ControllerContactID,
+					fmt.Sprintf("%s (исполнитель %s)", message, c.Executor.Name), c.ID)
+				sent++
+			}
+			continue
+		}
+		message := fmt.Sprintf("%s: срок исполнения %s, осталось %d дн.", label, c.DueDate.Format("02.01.2006"), *c.DaysLeft)
+		s.notify(ctx, notification.ExecutionDueSoon, c.Executor.ID, message, c.ID)
+		sent++
+	}
+
+	s.log.Info("execution reminders completed",
+		slog.String("date", date),
+		slog.Int("items", len(items)),
+		slog.Int("sent", sent))
+}
+
+// StartScheduler sends due-date reminders once a day at runHour. Blocks
+// until ctx is cancelled.
+func (s *Service) StartScheduler(ctx context.Context) {
+	for {
+		now := time.Now().In(s.loc)
+		next := time.Date(now.Year(), now.Month(), now.Day(), s.runHour, 0, 0, 0, s.loc)
+		if !next.After(now) {
+			next = next.Add(24 * time.Hour)
+		}
+		wait := next.Sub(now)
+
+		s.log.Info("next execution reminder sweep scheduled",
+			slog.String("run_at", next.Format(time.RFC3339)),
+			slog.Duration("in", wait))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.log.Info("execution-control scheduler stopped")
+			return
+		case <-timer.C:
+			s.RemindDue(ctx, next.Format(dateLayout))
+		}
+	}
+}
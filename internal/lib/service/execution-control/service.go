@@ -0,0 +1,409 @@
+// Package execcontrol tracks the execution of signed resolutions. Signing a
+// document with an executor opens a control item; the executor reports
+// progress and completion, the controller (the signer) accepts the work or
+// returns it, and a daily sweep reminds executors and controllers of items
+// nearing or past their due date.
+package execcontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	execution_control "srmt-admin/internal/lib/model/execution-control"
+	"srmt-admin/internal/lib/service/notification"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	// DefaultSoonDays is how many days ahead an item counts as due soon.
+	DefaultSoonDays = 3
+)
+
+var (
+	ErrNotExecutor     = errors.New("user is not the executor of this item")
+	ErrNotController   = errors.New("user is not the controller of this item")
+	ErrForbidden       = errors.New("user may not view this item")
+	ErrInvalidState    = errors.New("operation not allowed in the item's current status")
+	ErrCommentRequired = errors.New("comment is required to return the work")
+	ErrInvalidGroupBy  = errors.New("invalid dashboard grouping")
+	ErrDueDateInPast   = errors.New("new due date must not be in the past")
+	ErrInvalidDate     = errors.New("invalid date, expected YYYY-MM-DD")
+)
+
+// supervisorRoles may view every item; rais may also review any item.
+var supervisorRoles = []string{"chancellery", "rais"}
+
+// Actor is the user acting on a control item.
+type Actor struct {
+	UserID    int64
+	ContactID int64
+	Roles     []string
+}
+
+func (a Actor) hasAnyRole(roles ...string) bool {
+	for _, r := range roles {
+		if slices.Contains(a.Roles, r) {
+			return true
+		}
+	}
+	return false
+}
+
+type Repository interface {
+	GetExecutionControls(ctx context.Context, f execution_control.Filter) ([]execution_control.Control, error)
+	GetExecutionControlByID(ctx context.Context, id int64) (*execution_control.Control, error)
+	AddExecutionReport(ctx context.Context, controlID int64, fromStatus, toStatus string, req execution_control.SubmitReportRequest, userID int64) (int64, error)
+	ReviewExecution(ctx context.Context, controlID int64, decision string, comment *string, newDueDate *time.Time, userID int64) error
+}
+
+// Notifier delivers reminders and review outcomes to executors and
+// controllers
+type Notifier interface {
+	Notify(ctx context.Context, e notification.Event) error
+}
+
+type Service struct {
+	repo     Repository
+	notifier Notifier
+	loc      *time.Location
+	log      *slog.Logger
+	runHour  int
+	soonDays int
+}
+
+func NewService(repo Repository, notifier Notifier, loc *time.Location, log *slog.Logger) *Service {
+	return &Service{
+		repo:     repo,
+		notifier: notifier,
+		loc:      loc,
+		log:      log.With(slog.String("service", "execution-control")),
+		runHour:  8, // start of the working day
+		soonDays: DefaultSoonDays,
+	}
+}
+
+func (s *Service) today() time.Time {
+	now := time.Now().In(s.loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.loc)
+}
+
+// annotate fills DaysLeft and Overdue relative to today. Accepted items and
+// items without a due date are never overdue; a submitted item is waiting on
+// the controller but still counts against its due date.
+func annotate(c *execution_control.Control, today time.Time) {
+	c.DaysLeft = nil
+	c.Overdue = false
+	if c.DueDate == nil || c.Status == execution_control.StatusAccepted {
+		return
+	}
+	due := time.Date(c.DueDate.Year(), c.DueDate.Month(), c.DueDate.Day(), 0, 0, 0, 0, today.Location())
+	days := int(due.Sub(today).Hours() / 24)
+	c.DaysLeft = &days
+	c.Overdue = days < 0
+}
+
+// List returns control items matching f with their due-date state.
+func (s *Service) List(ctx context.Context, f execution_control.Filter) ([]execution_control.Control, error) {
+	const op = "service.execution-control.List"
+
+	items, err := s.repo.GetExecutionControls(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	today := s.today()
+	for i := range items {
+		annotate(&items[i], today)
+	}
+	return items, nil
+}
+
+// Mine returns the open items assigned to the actor.
+func (s *Service) Mine(ctx context.Context, actor Actor) ([]execution_control.Control, error) {
+	contactID := actor.ContactID
+	return s.List(ctx, execution_control.Filter{ExecutorContactID: &contactID, OpenOnly: true})
+}
+
+// Get returns a control item with its reports. The executor, the controller
+// and chancellery/rais staff may view it.
+func (s *Service) Get(ctx context.Context, id int64, actor Actor) (*execution_control.Control, error) {
+	const op = "service.execution-control.Get"
+
+	c, err := s.repo.GetExecutionControlByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !canView(c, actor) {
+		return nil, fmt.Errorf("%s: %w", op, ErrForbidden)
+	}
+	annotate(c, s.today())
+	return c, nil
+}
+
+func canView(c *execution_control.Control, actor Actor) bool {
+	return c.Executor.ID == actor.ContactID ||
+		(c.Controller != nil && c.Controller.ID == actor.UserID) ||
+		actor.hasAnyRole(supervisorRoles...)
+}
+
+// reportTransition returns the status an item moves to when its executor
+// files a report of kind, or false when the report is not accepted in the
+// current status. Progress may be reported while the work is open; a
+// completion hands the item to the controller.
+func reportTransition(status, kind string) (string, bool) {
+	switch status {
+	case execution_control.StatusOnControl, execution_control.StatusReturned:
+		if kind == execution_control.ReportCompletion {
+			return execution_control.StatusSubmitted, true
+		}
+		return status, true
+	default:
+		return "", false
+	}
+}
+
+// SubmitReport files a progress or completion report of the item's executor.
+func (s *Service) SubmitReport(ctx context.Context, id int64, req execution_control.SubmitReportRequest, actor Actor) (int64, error) {
+	const op = "service.execution-control.SubmitReport"
+
+	c, err := s.repo.GetExecutionControlByID(ctx, id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if c.Executor.ID != actor.ContactID {
+		return 0, fmt.Errorf("%s: %w", op, ErrNotExecutor)
+	}
+	to, ok := reportTransition(c.Status, req.Kind)
+	if !ok {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidState)
+	}
+
+	reportID, err := s.repo.AddExecutionReport(ctx, id, c.Status, to, req, actor.UserID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if req.Kind == execution_control.ReportCompletion && c.ControllerContactID != nil {
+		s.notify(ctx, notification.ExecutionSubmitted, *c.ControllerContactID,
+			fmt.Sprintf("%s: исполнитель %s сообщил о завершении", documentLabel(c), c.Executor.Name), id)
+	}
+	return reportID, nil
+}
+
+// Review accepts or returns a submitted completion. Only the controller or
+// rais may review; returning requires a comment and may set a new due date.
+func (s *Service) Review(ctx context.Context, id int64, req execution_control.ReviewRequest, actor Actor) error {
+	const op = "service.execution-control.Review"
+
+	c, err := s.repo.GetExecutionControlByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	isController := c.Controller != nil && c.Controller.ID == actor.UserID
+	if !isController && !actor.hasAnyRole("rais") {
+		return fmt.Errorf("%s: %w", op, ErrNotController)
+	}
+	if c.Status != execution_control.StatusSubmitted {
+		return fmt.Errorf("%s: %w", op, ErrInvalidState)
+	}
+
+	var newDue *time.Time
+	if req.Decision == execution_control.DecisionReturned {
+		if req.Comment == nil || *req.Comment == "" {
+			return fmt.Errorf("%s: %w", op, ErrCommentRequired)
+		}
+		if req.NewDueDate != nil && *req.NewDueDate != "" {
+			d, err := time.ParseInLocation(dateLayout, *req.NewDueDate, s.loc)
+			if err != nil {
+				return fmt.Errorf("%s: new due date: %w", op, ErrInvalidDate)
+			}
+			if d.Before(s.today()) {
+				return fmt.Errorf("%s: %w", op, ErrDueDateInPast)
+			}
+			newDue = &d
+		}
+	}
+
+	if err := s.repo.ReviewExecution(ctx, id, req.Decision, req.Comment, newDue, actor.UserID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if req.Decision == execution_control.DecisionReturned {
+		s.notify(ctx, notification.ExecutionReturned, c.Executor.ID,
+			fmt.Sprintf("%s: %s", documentLabel(c), *req.Comment), id)
+	} else {
+		s.notify(ctx, notification.ExecutionAccepted, c.Executor.ID,
+			fmt.Sprintf("%s: исполнение принято", documentLabel(c)), id)
+	}
+	return nil
+}
+
+// Due returns the open items whose due date is at most days away from date
+// (today when empty), overdue ones included. Submitted items are left out:
+// they wait on the controller, not the executor.
+func (s *Service) Due(ctx context.Context, date string, days int) ([]execution_control.Control, error) {
+	const op = "service.execution-control.Due"
+
+	day, err := s.parseDate(date)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	limit := day.AddDate(0, 0, days).Format(dateLayout)
+	items, err := s.repo.GetExecutionControls(ctx, execution_control.Filter{OpenOnly: true, DueBefore: &limit})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	result := make([]execution_control.Control, 0, len(items))
+	for _, c := range items {
+		if c.Status == execution_control.StatusSubmitted {
+			continue
+		}
+		annotate(&c, day)
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// Dashboard counts the open items per executor or department as of date
+// (today when empty).
+func (s *Service) Dashboard(ctx context.Context, groupBy, date string, soonDays int) (*execution_control.Dashboard, error) {
+	const op = "service.execution-control.Dashboard"
+
+	if groupBy != execution_control.GroupByExecutor && groupBy != execution_control.GroupByDepartment {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidGroupBy)
+	}
+	day, err := s.parseDate(date)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	items, err := s.repo.GetExecutionControls(ctx, execution_control.Filter{OpenOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for i := range items {
+		annotate(&items[i], day)
+	}
+
+	d := aggregate(items, groupBy, soonDays)
+	d.Date = day.Format(dateLayout)
+	return d, nil
+}
+
+// aggregate builds dashboard rows from annotated open items. Rows are sorted
+// by overdue count, then by name; items of executors without a department
+// share a row with a nil ID.
+func aggregate(items []execution_control.Control, groupBy string, soonDays int) *execution_control.Dashboard {
+	type key struct {
+		id    int64
+		valid bool
+	}
+	rows := make(map[key]*execution_control.DashboardRow)
+	var order []key
+	total := execution_control.DashboardRow{Name: "Итого"}
+
+	for _, c := range items {
+		k := key{id: c.Executor.ID, valid: true}
+		name := c.Executor.Name
+		if groupBy == execution_control.GroupByDepartment {
+			k = key{}
+			name = "Без подразделения"
+			if c.Executor.DepartmentID != nil {
+				k = key{id: *c.Executor.DepartmentID, valid: true}
+				if c.Executor.DepartmentName != nil {
+					name = *c.Executor.DepartmentName
+				}
+			}
+		}
+		row, ok := rows[k]
+		if !ok {
+			row = &execution_control.DashboardRow{Name: name}
+			if k.valid {
+				id := k.id
+				row.ID = &id
+			}
+			rows[k] = row
+			order = append(order, k)
+		}
+		for _, r := range []*execution_control.DashboardRow{row, &total} {
+			switch c.Status {
+			case execution_control.StatusOnControl:
+				r.OnControl++
+			case execution_control.StatusSubmitted:
+				r.Submitted++
+			case execution_control.StatusReturned:
+				r.Returned++
+			}
+			switch {
+			case c.Overdue:
+				r.Overdue++
+			case c.DaysLeft != nil && *c.DaysLeft <= soonDays:
+				r.DueSoon++
+			}
+		}
+	}
+
+	result := make([]execution_control.DashboardRow, 0, len(order))
+	for _, k := range order {
+		result = append(result, *rows[k])
+	}
+	slices.SortStableFunc(result, func(a, b execution_control.DashboardRow) int {
+		if a.Overdue != b.Overdue {
+			return b.Overdue - a.Overdue
+		}
+		switch {
+		case a.Name < b.Name:
+			return -1
+		case a.Name > b.Name:
+			return 1
+		}
+		return 0
+	})
+
+	return &execution_control.Dashboard{
+		GroupBy:  groupBy,
+		SoonDays: soonDays,
+		Rows:     result,
+		Total:    total,
+	}
+}
+
+func (s *Service) parseDate(date string) (time.Time, error) {
+	if date == "" {
+		return s.today(), nil
+	}
+	d, err := time.ParseInLocation(dateLayout, date, s.loc)
+	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+	return d, nil
+}
+
+func documentLabel(c *execution_control.Control) string {
+	if c.DocumentNumber != nil && *c.DocumentNumber != "" {
+		return fmt.Sprintf("№%s «%s»", *c.DocumentNumber, c.DocumentName)
+	}
+	return fmt.Sprintf("«%s»", c.DocumentName)
+}
+
+func (s *Service) notify(ctx context.Context, typ notification.EventType, contactID int64, message string, controlID int64) {
+	if s.notifier == nil {
+		return
+	}
+	err := s.notifier.Notify(ctx, notification.Event{
+		Type:     typ,
+		Contacts: []int64{contactID},
+		Message:  message,
+		Link:     fmt.Sprintf("/my-execution/%d", controlID),
+	})
+	if err != nil {
+		s.log.Error("failed to create notification",
+			slog.Int64("contact_id", contactID),
+			slog.Int64("control_id", controlID),
+			slog.String("error", err.Error()))
+	}
+}
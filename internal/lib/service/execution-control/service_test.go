@@ -0,0 +1,260 @@
+package execcontrol
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	execution_control "srmt-admin/internal/lib/model/execution-control"
+	"srmt-admin/internal/lib/model/user"
+	"srmt-admin/internal/lib/service/notification"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func date(s string) *time.Time {
+	t, _ := time.Parse(dateLayout, s)
+	return &t
+}
+
+type fakeRepo struct {
+	items     []execution_control.Control
+	filter    execution_control.Filter
+	reported  *[2]string // from, to
+	reviewed  string
+	newDue    *time.Time
+	notified  []int64
+	notifyMsg []string
+}
+
+func (f *fakeRepo) GetExecutionControls(_ context.Context, flt execution_control.Filter) ([]execution_control.Control, error) {
+	f.filter = flt
+	out := make([]execution_control.Control, len(f.items))
+	copy(out, f.items)
+	return out, nil
+}
+
+func (f *fakeRepo) GetExecutionControlByID(_ context.Context, id int64) (*execution_control.Control, error) {
+	for _, c := range f.items {
+		if c.ID == id {
+			return &c, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (f *fakeRepo) AddExecutionReport(_ context.Context, _ int64, from, to string, _ execution_control.SubmitReportRequest, _ int64) (int64, error) {
+	f.reported = &[2]string{from, to}
+	return 1, nil
+}
+
+func (f *fakeRepo) ReviewExecution(_ context.Context, _ int64, decision string, _ *string, newDue *time.Time, _ int64) error {
+	f.reviewed = decision
+	f.newDue = newDue
+	return nil
+}
+
+func (f *fakeRepo) Notify(_ context.Context, e notification.Event) error {
+	f.notified = append(f.notified, e.Contacts...)
+	f.notifyMsg = append(f.notifyMsg, e.Message)
+	return nil
+}
+
+func newTestService(repo *fakeRepo) *Service {
+	return NewService(repo, repo, time.UTC, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// item: executor contact 10 in department 5, controller user 2 (contact 20).
+func item(id int64, status string, due *time.Time) execution_control.Control {
+	return execution_control.Control{
+		ID:                  id,
+		DocumentType:        "decree",
+		DocumentName:        "Приказ",
+		Executor:            execution_control.Executor{ID: 10, Name: "Исполнитель", DepartmentID: ptr(int64(5)), DepartmentName: ptr("ПТО")},
+		Controller:          &user.ShortInfo{ID: 2},
+		ControllerContactID: ptr(int64(20)),
+		Status:              status,
+		DueDate:             due,
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	today := *date("2026-03-10")
+
+	c := item(1, execution_control.StatusOnControl, date("2026-03-12"))
+	annotate(&c, today)
+	if c.DaysLeft == nil || *c.DaysLeft != 2 || c.Overdue {
+		t.Fatalf("due in 2 days: got days_left=%v overdue=%v", c.DaysLeft, c.Overdue)
+	}
+
+	c = item(1, execution_control.StatusReturned, date("2026-03-09"))
+	annotate(&c, today)
+	if c.DaysLeft == nil || *c.DaysLeft != -1 || !c.Overdue {
+		t.Fatalf("due yesterday: got days_left=%v overdue=%v", c.DaysLeft, c.Overdue)
+	}
+
+	c = item(1, execution_control.StatusAccepted, date("2026-03-01"))
+	annotate(&c, today)
+	if c.DaysLeft != nil || c.Overdue {
+		t.Fatal("accepted items are never overdue")
+	}
+
+	c = item(1, execution_control.StatusOnControl, nil)
+	annotate(&c, today)
+	if c.DaysLeft != nil || c.Overdue {
+		t.Fatal("items without due date are never overdue")
+	}
+}
+
+func TestReportTransition(t *testing.T) {
+	tests := []struct {
+		status, kind, want string
+		ok                 bool
+	}{
+		{execution_control.StatusOnControl, execution_control.ReportProgress, execution_control.StatusOnControl, true},
+		{execution_control.StatusOnControl, execution_control.ReportCompletion, execution_control.StatusSubmitted, true},
+		{execution_control.StatusReturned, execution_control.ReportProgress, execution_control.StatusReturned, true},
+		{execution_control.StatusReturned, execution_control.ReportCompletion, execution_control.StatusSubmitted, true},
+		{execution_control.StatusSubmitted, execution_control.ReportProgress, "", false},
+		{execution_control.StatusAccepted, execution_control.ReportCompletion, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := reportTransition(tt.status, tt.kind)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("%s + %s: got (%q, %v), want (%q, %v)", tt.status, tt.kind, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSubmitReport_OnlyExecutor(t *testing.T) {
+	repo := &fakeRepo{items: []execution_control.Control{item(1, execution_control.StatusOnControl, nil)}}
+	svc := newTestService(repo)
+	req := execution_control.SubmitReportRequest{Kind: execution_control.ReportCompletion, Text: "Сделано"}
+
+	if _, err := svc.SubmitReport(context.Background(), 1, req, Actor{UserID: 3, ContactID: 11}); !errors.Is(err, ErrNotExecutor) {
+		t.Fatalf("got %v, want ErrNotExecutor", err)
+	}
+
+	if _, err := svc.SubmitReport(context.Background(), 1, req, Actor{UserID: 3, ContactID: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if repo.reported == nil || repo.reported[1] != execution_control.StatusSubmitted {
+		t.Fatalf("completion must move the item to submitted, got %v", repo.reported)
+	}
+	if len(repo.notified) != 1 || repo.notified[0] != 20 {
+		t.Fatalf("controller must be notified of the completion, got %v", repo.notified)
+	}
+}
+
+func TestReview(t *testing.T) {
+	repo := &fakeRepo{items: []execution_control.Control{item(1, execution_control.StatusSubmitted, nil)}}
+	svc := newTestService(repo)
+	ctx := context.Background()
+
+	returned := execution_control.ReviewRequest{Decision: execution_control.DecisionReturned}
+	if err := svc.Review(ctx, 1, returned, Actor{UserID: 2}); !errors.Is(err, ErrCommentRequired) {
+		t.Fatalf("got %v, want ErrCommentRequired", err)
+	}
+
+	accept := execution_control.ReviewRequest{Decision: execution_control.DecisionAccepted}
+	if err := svc.Review(ctx, 1, accept, Actor{UserID: 7, Roles: []string{"chancellery"}}); !errors.Is(err, ErrNotController) {
+		t.Fatalf("got %v, want ErrNotController", err)
+	}
+	if err := svc.Review(ctx, 1, accept, Actor{UserID: 7, Roles: []string{"rais"}}); err != nil {
+		t.Fatalf("rais may review any item: %v", err)
+	}
+
+	returned.Comment = ptr("Нет приложения")
+	returned.NewDueDate = ptr(time.Now().AddDate(0, 0, 5).Format(dateLayout))
+	if err := svc.Review(ctx, 1, returned, Actor{UserID: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if repo.reviewed != execution_control.DecisionReturned || repo.newDue == nil {
+		t.Fatalf("expected return with a new due date, got %q %v", repo.reviewed, repo.newDue)
+	}
+
+	repo.items[0].Status = execution_control.StatusOnControl
+	if err := svc.Review(ctx, 1, accept, Actor{UserID: 2}); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("got %v, want ErrInvalidState", err)
+	}
+}
+
+func TestDueSkipsSubmitted(t *testing.T) {
+	repo := &fakeRepo{items: []execution_control.Control{
+		item(1, execution_control.StatusOnControl, date("2026-03-08")),
+		item(2, execution_control.StatusSubmitted, date("2026-03-09")),
+		item(3, execution_control.StatusReturned, date("2026-03-12")),
+	}}
+	svc := newTestService(repo)
+
+	items, err := svc.Due(context.Background(), "2026-03-10", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.filter.DueBefore == nil || *repo.filter.DueBefore != "2026-03-13" || !repo.filter.OpenOnly {
+		t.Fatalf("unexpected filter %+v", repo.filter)
+	}
+	if len(items) != 2 || items[0].ID != 1 || !items[0].Overdue || items[1].ID != 3 || *items[1].DaysLeft != 2 {
+		t.Fatalf("unexpected due items %+v", items)
+	}
+}
+
+func TestRemindDue(t *testing.T) {
+	repo := &fakeRepo{items: []execution_control.Control{
+		item(1, execution_control.StatusOnControl, date("2026-03-08")),
+		item(2, execution_control.StatusOnControl, date("2026-03-12")),
+	}}
+	svc := newTestService(repo)
+
+	svc.RemindDue(context.Background(), "2026-03-10")
+
+	// Overdue: executor and controller; due soon: executor only.
+	want := []int64{10, 20, 10}
+	if len(repo.notified) != len(want) {
+		t.Fatalf("notified %v, want %v", repo.notified, want)
+	}
+	for i := range want {
+		if repo.notified[i] != want[i] {
+			t.Fatalf("notified %v, want %v", repo.notified, want)
+		}
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	today := *date("2026-03-10")
+	other := item(4, execution_control.StatusOnControl, date("2026-03-20"))
+	other.Executor = execution_control.Executor{ID: 11, Name: "Без отдела"}
+
+	items := []execution_control.Control{
+		item(1, execution_control.StatusOnControl, date("2026-03-08")),
+		item(2, execution_control.StatusSubmitted, date("2026-03-11")),
+		item(3, execution_control.StatusReturned, date("2026-03-20")),
+		other,
+	}
+	for i := range items {
+		annotate(&items[i], today)
+	}
+
+	d := aggregate(items, execution_control.GroupByDepartment, 3)
+	if len(d.Rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(d.Rows))
+	}
+	pto := d.Rows[0]
+	if pto.ID == nil || *pto.ID != 5 || pto.OnControl != 1 || pto.Submitted != 1 || pto.Returned != 1 || pto.Overdue != 1 || pto.DueSoon != 1 {
+		t.Fatalf("unexpected department row %+v", pto)
+	}
+	if d.Rows[1].ID != nil {
+		t.Fatalf("executors without a department share a row with nil id, got %+v", d.Rows[1])
+	}
+	if d.Total.OnControl != 2 || d.Total.Overdue != 1 {
+		t.Fatalf("unexpected total %+v", d.Total)
+	}
+
+	d = aggregate(items, execution_control.GroupByExecutor, 3)
+	if len(d.Rows) != 2 || *d.Rows[0].ID != 10 {
+		t.Fatalf("unexpected executor rows %+v", d.Rows)
+	}
+}
@@ -0,0 +1,253 @@
+package filelifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"srmt-admin/internal/lib/model/file"
+	"srmt-admin/internal/storage"
+	"srmt-admin/internal/storage/minio"
+)
+
+// cleanup accumulates the report of one run.
+type cleanup struct {
+	run        file.CleanupRun
+	categories map[int64]*file.CleanupCategory
+	names      map[int64]string
+}
+
+func (c *cleanup) category(id int64) *file.CleanupCategory {
+	cat, ok := c.categories[id]
+	if !ok {
+		cat = &file.CleanupCategory{CategoryID: id, CategoryName: c.names[id]}
+		c.categories[id] = cat
+	}
+	return cat
+}
+
+func (c *cleanup) fail(format string, args ...any) {
+	if len(c.run.Errors) < maxReportErrors {
+		c.run.Errors = append(c.run.Errors, fmt.Sprintf(format, args...))
+	}
+}
+
+func (c *cleanup) count(cat *file.CleanupCategory, reason string) {
+	if reason == file.PurgeExpired {
+		cat.ExpiredFiles++
+		c.run.ExpiredFiles++
+		return
+	}
+	cat.OrphanFiles++
+	c.run.OrphanFiles++
+}
+
+// Cleanup purges files due under the retention policies, then objects in
+// storage no file refers to, and records the report. A dry run only counts.
+// userID is nil for the scheduled run. Only one cleanup runs at a time;
+// another is refused with ErrCleanupRunning.
+func (s *Service) Cleanup(ctx context.Context, dryRun bool, userID *int64) (*file.CleanupRun, error) {
+	const op = "service.file-lifecycle.Cleanup"
+
+	if !s.running.TryLock() {
+		return nil, fmt.Errorf("%s: %w", op, ErrCleanupRunning)
+	}
+	defer s.running.Unlock()
+
+	policies, err := s.repo.GetRetentionPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	c := &cleanup{
+		run: file.CleanupRun{
+			DryRun:      dryRun,
+			TriggeredBy: userID,
+			StartedAt:   time.Now(),
+			Categories:  make([]file.CleanupCategory, 0),
+			Errors:      make([]string, 0),
+		},
+		categories: make(map[int64]*file.CleanupCategory),
+		names:      make(map[int64]string, len(policies)),
+	}
+	for _, p := range policies {
+		c.names[p.CategoryID] = p.CategoryName
+	}
+
+	if err := s.purgeFiles(ctx, c); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.purgeObjects(ctx, c); err != nil {
+		// Files already purged stay purged; report what was done
+		c.fail("objects: %v", err)
+	}
+
+	for _, cat := range c.categories {
+		c.run.Categories = append(c.run.Categories, *cat)
+	}
+	slices.SortFunc(c.run.Categories, func(a, b file.CleanupCategory) int {
+		return strings.Compare(a.CategoryName, b.CategoryName)
+	})
+	c.run.FinishedAt = time.Now()
+
+	id, err := s.repo.AddFileCleanupRun(ctx, c.run)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to record run: %w", op, err)
+	}
+	c.run.ID = id
+
+	s.log.Info("file cleanup completed",
+		slog.Bool("dry_run", dryRun),
+		slog.Int("expired_files", c.run.ExpiredFiles),
+		slog.Int("orphan_files", c.run.OrphanFiles),
+		slog.Int("orphan_objects", c.run.OrphanObjects),
+		slog.Int("held_files", c.run.HeldFiles),
+		slog.Int64("reclaimed_bytes", c.run.ReclaimedBytes),
+		slog.Int("errors", len(c.run.Errors)))
+	return &c.run, nil
+}
+
+// purgeFiles removes the files the policies make due. A file the database
+// refuses to delete (a signed attachment, a template) is counted as held.
+func (s *Service) purgeFiles(ctx context.Context, c *cleanup) error {
+	var afterID int64
+	for {
+		candidates, err := s.repo.GetPurgeCandidates(ctx, afterID, candidateBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, f := range candidates {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			afterID = f.ID
+			s.purgeFile(ctx, c, f)
+		}
+		if len(candidates) < candidateBatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *Service) purgeFile(ctx context.Context, c *cleanup, f file.PurgeCandidate) {
+	cat := c.category(f.CategoryID)
+
+	if c.run.DryRun {
+		c.count(cat, f.Reason)
+		// Reclaimed only if no other file shares the object
+		if n, err := s.repo.CountFilesByObjectKey(ctx, f.ObjectKey); err == nil && n == 1 {
+			cat.ReclaimedBytes += f.SizeBytes
+			c.run.ReclaimedBytes += f.SizeBytes
+		}
+		return
+	}
+
+	if err := s.repo.DeleteFile(ctx, f.ID); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			// deleted meanwhile
+		case errors.Is(err, storage.ErrAttachmentsLocked), errors.Is(err, storage.ErrForeignKeyViolation):
+			cat.HeldFiles++
+			c.run.HeldFiles++
+		default:
+			c.fail("file %d: %v", f.ID, err)
+		}
+		return
+	}
+	c.count(cat, f.Reason)
+
+	released, err := s.releaseObject(ctx, f.ObjectKey)
+	if err != nil {
+		// The object is unreferenced now and goes with the object sweep
+		c.fail("object of file %d: %v", f.ID, err)
+		return
+	}
+	if released {
+		cat.ReclaimedBytes += f.SizeBytes
+		c.run.ReclaimedBytes += f.SizeBytes
+	}
+}
+
+// purgeObjects removes objects older than objectGrace that no file refers
+// to: leftovers of failed uploads, of failed deletes and of rows removed with
+// their category.
+func (s *Service) purgeObjects(ctx context.Context, c *cleanup) error {
+	cutoff := time.Now().Add(-objectGrace)
+	batch := make([]minio.ObjectInfo, 0, objectBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		keys := make([]string, len(batch))
+		sizes := make(map[string]int64, len(batch))
+		for i, o := range batch {
+			keys[i] = o.Key
+			sizes[o.Key] = o.Size
+		}
+		batch = batch[:0]
+
+		orphans, err := s.repo.UnreferencedObjectKeys(ctx, keys)
+		if err != nil {
+			return err
+		}
+		for _, key := range orphans {
+			if !c.run.DryRun {
+				if err := s.storage.DeleteFile(ctx, key); err != nil {
+					c.fail("object %s: %v", key, err)
+					continue
+				}
+			}
+			c.run.OrphanObjects++
+			c.run.ReclaimedBytes += sizes[key]
+		}
+		return nil
+	}
+
+	err := s.storage.WalkObjects(ctx, func(o minio.ObjectInfo) error {
+		if !o.LastModified.Before(cutoff) {
+			return nil
+		}
+		batch = append(batch, o)
+		if len(batch) < objectBatchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// StartScheduler runs the cleanup once a day at runHour. Blocks until ctx is
+// cancelled.
+func (s *Service) StartScheduler(ctx context.Context) {
+	for {
+		now := time.Now().In(s.loc)
+		next := time.Date(now.Year(), now.Month(), now.Day(), s.runHour, 0, 0, 0, s.loc)
+		if !next.After(now) {
+			next = next.Add(24 * time.Hour)
+		}
+		wait := next.Sub(now)
+
+		s.log.Info("next file cleanup scheduled",
+			slog.String("run_at", next.Format(time.RFC3339)),
+			slog.Duration("in", wait))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.log.Info("file cleanup scheduler stopped")
+			return
+		case <-timer.C:
+			if _, err := s.Cleanup(ctx, false, nil); err != nil {
+				s.log.Error("file cleanup failed", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
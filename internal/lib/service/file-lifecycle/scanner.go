@@ -0,0 +1,154 @@
+package filelifecycle
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// ErrInfected is matched by every *InfectedError.
+var ErrInfected = errors.New("file is infected")
+
+// InfectedError is returned by a Scanner that found malware.
+type InfectedError struct {
+	Signature string
+}
+
+func (e *InfectedError) Error() string { return "file is infected: " + e.Signature }
+
+func (e *InfectedError) Is(target error) bool { return target == ErrInfected }
+
+// Scanner checks an upload before it is stored. Scan returns nil for a clean
+// file, an *InfectedError for malware and any other error when the scan
+// could not be done; uploads are refused then too.
+type Scanner interface {
+	Name() string
+	Scan(ctx context.Context, r io.Reader) error
+}
+
+// Scanner names accepted in the configuration
+const (
+	ScannerNone   = "none"
+	ScannerStub   = "stub"
+	ScannerClamAV = "clamav"
+)
+
+// NewScanner returns the scanner configured by name, nil for none.
+func NewScanner(name, clamdAddress string) (Scanner, error) {
+	switch name {
+	case "", ScannerNone:
+		return nil, nil
+	case ScannerStub:
+		return StubScanner{}, nil
+	case ScannerClamAV:
+		if clamdAddress == "" {
+			return nil, errors.New("clamav scanner needs clamd_address")
+		}
+		return &ClamAVScanner{Address: clamdAddress}, nil
+	}
+	return nil, fmt.Errorf("unknown file scanner %q", name)
+}
+
+// eicarSignature is the distinctive part of the EICAR anti-virus test file.
+const eicarSignature = "EICAR-STANDARD-ANTIVIRUS-TEST-FILE"
+
+// StubScanner is a local stand-in for a real scanner: it flags the EICAR
+// test file and passes everything else.
+type StubScanner struct{}
+
+func (StubScanner) Name() string { return ScannerStub }
+
+func (StubScanner) Scan(_ context.Context, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if bytes.Contains(data, []byte(eicarSignature)) {
+		return &InfectedError{Signature: "Eicar-Test-Signature"}
+	}
+	return nil
+}
+
+// clamdChunkSize is the size of the INSTREAM chunks sent to clamd; it must
+// stay below clamd's StreamMaxLength.
+const clamdChunkSize = 64 << 10
+
+// ClamAVScanner streams files to a clamd daemon with the INSTREAM command.
+// Address is host:port, or the path of clamd's unix socket.
+type ClamAVScanner struct {
+	Address string
+}
+
+func (*ClamAVScanner) Name() string { return ScannerClamAV }
+
+func (s *ClamAVScanner) Scan(ctx context.Context, r io.Reader) error {
+	network := "tcp"
+	if strings.HasPrefix(s.Address, "/") {
+		network = "unix"
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, s.Address)
+	if err != nil {
+		return fmt.Errorf("clamd unavailable: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Minute))
+	}
+
+	if _, err := io.WriteString(conn, "zINSTREAM\x00"); err != nil {
+		return fmt.Errorf("clamd: %w", err)
+	}
+	buf := make([]byte, clamdChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return fmt.Errorf("clamd: %w", err)
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return fmt.Errorf("clamd: %w", err)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return fmt.Errorf("clamd: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("clamd: %w", err)
+	}
+	return parseClamdReply(reply)
+}
+
+// parseClamdReply reads "stream: OK", "stream: <signature> FOUND" or an
+// "... ERROR" reply.
+func parseClamdReply(reply string) error {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	reply = strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case reply == "OK":
+		return nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return &InfectedError{Signature: strings.TrimSuffix(reply, " FOUND")}
+	}
+	return fmt.Errorf("clamd: %s", reply)
+}
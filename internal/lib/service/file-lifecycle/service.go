@@ -0,0 +1,311 @@
+// Package filelifecycle stores uploads and keeps file storage tidy. Uploads
+// are scanned before they are stored and deduplicated by content: several
+// files rows may then share one object, which is removed only with its last
+// row. Retention policies per category and a daily cleanup purge expired and
+// orphaned files and objects and report the space reclaimed.
+package filelifecycle
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"srmt-admin/internal/lib/model/file"
+	"srmt-admin/internal/storage"
+	"srmt-admin/internal/storage/minio"
+)
+
+var (
+	ErrEmptyPolicy     = errors.New("a policy must set retention_days or purge_orphans")
+	ErrCleanupRunning  = errors.New("a cleanup is already running")
+	ErrScanUnavailable = errors.New("file could not be scanned")
+)
+
+const (
+	candidateBatchSize = 200
+	objectBatchSize    = 500
+
+	// objectGrace keeps objects whose row is still being written, and
+	// uploads of requests that failed midway until they are surely dead.
+	objectGrace = 24 * time.Hour
+
+	maxReportErrors = 20
+	defaultScanTime = 2 * time.Minute
+)
+
+type Repository interface {
+	AddFile(ctx context.Context, fileData file.Model) (int64, error)
+	AddFileFromDuplicate(ctx context.Context, fileData file.Model) (int64, string, error)
+	GetFileByID(ctx context.Context, id int64) (file.Model, error)
+	DeleteFile(ctx context.Context, id int64) error
+	CountFilesByObjectKey(ctx context.Context, objectKey string) (int, error)
+	UnreferencedObjectKeys(ctx context.Context, keys []string) ([]string, error)
+	GetRetentionPolicies(ctx context.Context) ([]file.RetentionPolicy, error)
+	SetRetentionPolicy(ctx context.Context, categoryID int64, req file.RetentionPolicyRequest, userID int64) error
+	DeleteRetentionPolicy(ctx context.Context, categoryID int64) error
+	GetPurgeCandidates(ctx context.Context, afterID int64, limit int) ([]file.PurgeCandidate, error)
+	AddFileCleanupRun(ctx context.Context, run file.CleanupRun) (int64, error)
+	GetFileCleanupRuns(ctx context.Context, limit int) ([]file.CleanupRun, error)
+}
+
+type ObjectStorage interface {
+	UploadFile(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) error
+	GetObject(ctx context.Context, objectName string) (io.ReadCloser, error)
+	DeleteFile(ctx context.Context, objectName string) error
+	WalkObjects(ctx context.Context, fn func(minio.ObjectInfo) error) error
+}
+
+type Service struct {
+	repo     Repository
+	storage  ObjectStorage
+	scanner  Scanner
+	scanTime time.Duration
+	loc      *time.Location
+	runHour  int
+	running  sync.Mutex
+	log      *slog.Logger
+}
+
+// NewService stores uploads after scanner has passed them; a nil scanner
+// stores them unscanned.
+func NewService(repo Repository, storage ObjectStorage, scanner Scanner, loc *time.Location, log *slog.Logger) *Service {
+	return &Service{
+		repo:     repo,
+		storage:  storage,
+		scanner:  scanner,
+		scanTime: defaultScanTime,
+		loc:      loc,
+		runHour:  3, // night, away from working hours
+		log:      log.With(slog.String("service", "file-lifecycle")),
+	}
+}
+
+// Upload is a file to store. ObjectKey is used only when no stored file has
+// the same content.
+type Upload struct {
+	FileName    string
+	ContentType string
+	Size        int64
+	Body        io.ReadSeeker
+	CategoryID  int64
+	TargetDate  time.Time
+	ObjectKey   string
+}
+
+func (u Upload) model(digest string) file.Model {
+	return file.Model{
+		FileName:      u.FileName,
+		ObjectKey:     u.ObjectKey,
+		CategoryID:    u.CategoryID,
+		MimeType:      u.ContentType,
+		SizeBytes:     u.Size,
+		CreatedAt:     time.Now(),
+		TargetDate:    u.TargetDate,
+		ContentSHA256: digest,
+	}
+}
+
+// Stored is the outcome of Store.
+type Stored struct {
+	ID           int64
+	ObjectKey    string
+	Deduplicated bool // the content was already stored; no object was written
+}
+
+// Store scans an upload, then records it as a new file. Content already in
+// storage is not written again: the new row shares the existing object.
+// Malware is refused with an *InfectedError, a failed scan with
+// ErrScanUnavailable.
+func (s *Service) Store(ctx context.Context, u Upload) (*Stored, error) {
+	const op = "service.file-lifecycle.Store"
+
+	digest, err := s.inspect(ctx, u.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := u.Body.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("%s: failed to rewind upload: %w", op, err)
+	}
+
+	f := u.model(digest)
+
+	id, objectKey, err := s.repo.AddFileFromDuplicate(ctx, f)
+	if err == nil {
+		s.log.Info("upload deduplicated", slog.Int64("id", id), slog.String("object_key", objectKey))
+		return &Stored{ID: id, ObjectKey: objectKey, Deduplicated: true}, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.storage.UploadFile(ctx, u.ObjectKey, u.Body, u.Size, u.ContentType); err != nil {
+		return nil, fmt.Errorf("%s: failed to upload object: %w", op, err)
+	}
+	id, err = s.repo.AddFile(ctx, f)
+	if err != nil {
+		s.discardObject(ctx, u.ObjectKey)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &Stored{ID: id, ObjectKey: u.ObjectKey}, nil
+}
+
+// StoreUploaded records an object already written to storage under
+// u.ObjectKey, such as an assembled resumable upload, as a new file; u.Body is
+// not used. The object is read back, scanned and deduplicated as in Store. It
+// is removed when it is infected or its content is already stored, and kept
+// on other errors so that the call can be repeated.
+func (s *Service) StoreUploaded(ctx context.Context, u Upload) (*Stored, error) {
+	const op = "service.file-lifecycle.StoreUploaded"
+
+	rc, err := s.storage.GetObject(ctx, u.ObjectKey)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	digest, err := s.inspect(ctx, rc)
+	rc.Close()
+	if err != nil {
+		if errors.Is(err, ErrInfected) {
+			s.discardObject(ctx, u.ObjectKey)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	f := u.model(digest)
+	id, objectKey, err := s.repo.AddFileFromDuplicate(ctx, f)
+	if err == nil {
+		s.discardObject(ctx, u.ObjectKey)
+		s.log.Info("upload deduplicated", slog.Int64("id", id), slog.String("object_key", objectKey))
+		return &Stored{ID: id, ObjectKey: objectKey, Deduplicated: true}, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err = s.repo.AddFile(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &Stored{ID: id, ObjectKey: u.ObjectKey}, nil
+}
+
+// discardObject removes an object no file will refer to; a failure is left
+// to the cleanup.
+func (s *Service) discardObject(ctx context.Context, objectKey string) {
+	if err := s.storage.DeleteFile(ctx, objectKey); err != nil {
+		s.log.Error("failed to remove unused object", slog.String("object_key", objectKey), slog.String("error", err.Error()))
+	}
+}
+
+// inspect hashes the upload and, with a scanner, scans it in the same pass.
+func (s *Service) inspect(ctx context.Context, body io.Reader) (string, error) {
+	h := sha256.New()
+	tee := io.TeeReader(body, h)
+
+	if s.scanner != nil {
+		scanCtx, cancel := context.WithTimeout(ctx, s.scanTime)
+		err := s.scanner.Scan(scanCtx, tee)
+		cancel()
+		if err != nil {
+			if errors.Is(err, ErrInfected) {
+				s.log.Warn("infected upload refused", slog.String("scanner", s.scanner.Name()), slog.String("error", err.Error()))
+				return "", err
+			}
+			return "", fmt.Errorf("%w: %s: %v", ErrScanUnavailable, s.scanner.Name(), err)
+		}
+	}
+	// Hash whatever the scanner did not read
+	if _, err := io.Copy(io.Discard, tee); err != nil {
+		return "", fmt.Errorf("failed to read upload: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Delete removes a file and, unless other files share it, its object. A file
+// still needed is refused by the database: storage.ErrAttachmentsLocked for
+// attachments of a signed document, storage.ErrForeignKeyViolation for a
+// document template.
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	const op = "service.file-lifecycle.Delete"
+
+	f, err := s.repo.GetFileByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.repo.DeleteFile(ctx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := s.releaseObject(ctx, f.ObjectKey); err != nil {
+		// The cleanup removes the object later
+		s.log.Error("failed to remove object of deleted file",
+			slog.Int64("id", id), slog.String("object_key", f.ObjectKey), slog.String("error", err.Error()))
+	}
+	return nil
+}
+
+// releaseObject removes an object no file refers to any more and reports
+// whether it did.
+func (s *Service) releaseObject(ctx context.Context, objectKey string) (bool, error) {
+	n, err := s.repo.CountFilesByObjectKey(ctx, objectKey)
+	if err != nil {
+		return false, err
+	}
+	if n > 0 {
+		return false, nil
+	}
+	if err := s.storage.DeleteFile(ctx, objectKey); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Policies lists the retention policies.
+func (s *Service) Policies(ctx context.Context) ([]file.RetentionPolicy, error) {
+	const op = "service.file-lifecycle.Policies"
+
+	policies, err := s.repo.GetRetentionPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return policies, nil
+}
+
+// SetPolicy creates or replaces the retention policy of a category.
+func (s *Service) SetPolicy(ctx context.Context, categoryID int64, req file.RetentionPolicyRequest, userID int64) error {
+	const op = "service.file-lifecycle.SetPolicy"
+
+	if req.RetentionDays == nil && !req.PurgeOrphans {
+		return fmt.Errorf("%s: %w", op, ErrEmptyPolicy)
+	}
+	if err := s.repo.SetRetentionPolicy(ctx, categoryID, req, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	s.log.Info("retention policy set", slog.Int64("category_id", categoryID), slog.Int64("user_id", userID))
+	return nil
+}
+
+// DeletePolicy removes the retention policy of a category.
+func (s *Service) DeletePolicy(ctx context.Context, categoryID int64) error {
+	const op = "service.file-lifecycle.DeletePolicy"
+
+	if err := s.repo.DeleteRetentionPolicy(ctx, categoryID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Runs returns the latest cleanup reports.
+func (s *Service) Runs(ctx context.Context, limit int) ([]file.CleanupRun, error) {
+	const op = "service.file-lifecycle.Runs"
+
+	runs, err := s.repo.GetFileCleanupRuns(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return runs, nil
+}
@@ -0,0 +1,360 @@
+package filelifecycle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"srmt-admin/internal/lib/model/file"
+	"srmt-admin/internal/storage"
+	"srmt-admin/internal/storage/minio"
+)
+
+type fakeRepo struct {
+	files      map[int64]file.Model
+	nextID     int64
+	policies   []file.RetentionPolicy
+	candidates []file.PurgeCandidate
+	held       map[int64]error
+	runs       []file.CleanupRun
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{files: make(map[int64]file.Model), held: make(map[int64]error), nextID: 100}
+}
+
+func (f *fakeRepo) AddFile(_ context.Context, m file.Model) (int64, error) {
+	f.nextID++
+	m.ID = f.nextID
+	f.files[m.ID] = m
+	return m.ID, nil
+}
+
+func (f *fakeRepo) AddFileFromDuplicate(ctx context.Context, m file.Model) (int64, string, error) {
+	for _, existing := range f.files {
+		if existing.ContentSHA256 == m.ContentSHA256 && existing.SizeBytes == m.SizeBytes {
+			m.ObjectKey = existing.ObjectKey
+			id, _ := f.AddFile(ctx, m)
+			return id, m.ObjectKey, nil
+		}
+	}
+	return 0, "", storage.ErrNotFound
+}
+
+func (f *fakeRepo) GetFileByID(_ context.Context, id int64) (file.Model, error) {
+	m, ok := f.files[id]
+	if !ok {
+		return file.Model{}, storage.ErrNotFound
+	}
+	return m, nil
+}
+
+func (f *fakeRepo) DeleteFile(_ context.Context, id int64) error {
+	if err := f.held[id]; err != nil {
+		return err
+	}
+	if _, ok := f.files[id]; !ok {
+		return storage.ErrNotFound
+	}
+	delete(f.files, id)
+	return nil
+}
+
+func (f *fakeRepo) CountFilesByObjectKey(_ context.Context, key string) (int, error) {
+	var n int
+	for _, m := range f.files {
+		if m.ObjectKey == key {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeRepo) UnreferencedObjectKeys(ctx context.Context, keys []string) ([]string, error) {
+	var out []string
+	for _, k := range keys {
+		if n, _ := f.CountFilesByObjectKey(ctx, k); n == 0 {
+			out = append(out, k)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepo) GetRetentionPolicies(_ context.Context) ([]file.RetentionPolicy, error) {
+	return f.policies, nil
+}
+
+func (f *fakeRepo) SetRetentionPolicy(_ context.Context, _ int64, _ file.RetentionPolicyRequest, _ int64) error {
+	return nil
+}
+
+func (f *fakeRepo) DeleteRetentionPolicy(_ context.Context, _ int64) error { return nil }
+
+func (f *fakeRepo) GetPurgeCandidates(_ context.Context, afterID int64, limit int) ([]file.PurgeCandidate, error) {
+	var out []file.PurgeCandidate
+	for _, c := range f.candidates {
+		if c.ID > afterID && len(out) < limit {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepo) AddFileCleanupRun(_ context.Context, run file.CleanupRun) (int64, error) {
+	f.runs = append(f.runs, run)
+	return int64(len(f.runs)), nil
+}
+
+func (f *fakeRepo) GetFileCleanupRuns(_ context.Context, _ int) ([]file.CleanupRun, error) {
+	return f.runs, nil
+}
+
+type fakeStorage struct {
+	objects map[string]minio.ObjectInfo
+	content map[string][]byte
+	uploads int
+}
+
+func newFakeStorage() *fakeStorage {
+	return &fakeStorage{objects: make(map[string]minio.ObjectInfo), content: make(map[string][]byte)}
+}
+
+func (f *fakeStorage) UploadFile(_ context.Context, key string, r io.Reader, size int64, _ string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	f.uploads++
+	f.objects[key] = minio.ObjectInfo{Key: key, Size: size, LastModified: time.Now()}
+	f.content[key] = data
+	return nil
+}
+
+func (f *fakeStorage) GetObject(_ context.Context, key string) (io.ReadCloser, error) {
+	data, ok := f.content[key]
+	if !ok {
+		return nil, errors.New("no such object")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (f *fakeStorage) DeleteFile(_ context.Context, key string) error {
+	delete(f.objects, key)
+	delete(f.content, key)
+	return nil
+}
+
+func (f *fakeStorage) WalkObjects(_ context.Context, fn func(minio.ObjectInfo) error) error {
+	for _, o := range f.objects {
+		if err := fn(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func upload(key, content string) Upload {
+	return Upload{
+		FileName: key, ContentType: "text/plain", Size: int64(len(content)),
+		Body: strings.NewReader(content), CategoryID: 1, ObjectKey: key,
+	}
+}
+
+func TestStore_Deduplicates(t *testing.T) {
+	repo, objects := newFakeRepo(), newFakeStorage()
+	svc := NewService(repo, objects, StubScanner{}, time.UTC, testLogger())
+
+	first, err := svc.Store(context.Background(), upload("a.txt", "same content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := svc.Store(context.Background(), upload("b.txt", "same content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Deduplicated || !second.Deduplicated || second.ObjectKey != "a.txt" || first.ID == second.ID {
+		t.Fatalf("first = %+v, second = %+v", first, second)
+	}
+	if objects.uploads != 1 {
+		t.Errorf("uploads = %d, want 1", objects.uploads)
+	}
+	if repo.files[first.ID].ContentSHA256 == "" || len(repo.files[first.ID].ContentSHA256) != 64 {
+		t.Errorf("hash = %q", repo.files[first.ID].ContentSHA256)
+	}
+
+	// The shared object survives the first delete and goes with the last
+	if err := svc.Delete(context.Background(), first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := objects.objects["a.txt"]; !ok {
+		t.Fatal("shared object removed while still referenced")
+	}
+	if err := svc.Delete(context.Background(), second.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := objects.objects["a.txt"]; ok {
+		t.Fatal("object kept after its last file was deleted")
+	}
+}
+
+func TestStore_RefusesInfected(t *testing.T) {
+	repo, objects := newFakeRepo(), newFakeStorage()
+	svc := NewService(repo, objects, StubScanner{}, time.UTC, testLogger())
+
+	_, err := svc.Store(context.Background(), upload("x.com", `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`))
+	var infected *InfectedError
+	if !errors.Is(err, ErrInfected) || !errors.As(err, &infected) || infected.Signature == "" {
+		t.Fatalf("err = %v", err)
+	}
+	if len(repo.files) != 0 || objects.uploads != 0 {
+		t.Error("infected upload was stored")
+	}
+}
+
+type brokenScanner struct{}
+
+func (brokenScanner) Name() string { return "broken" }
+
+func (brokenScanner) Scan(_ context.Context, _ io.Reader) error {
+	return errors.New("connection refused")
+}
+
+func TestStore_ScanUnavailable(t *testing.T) {
+	svc := NewService(newFakeRepo(), newFakeStorage(), brokenScanner{}, time.UTC, testLogger())
+
+	if _, err := svc.Store(context.Background(), upload("a.txt", "data")); !errors.Is(err, ErrScanUnavailable) {
+		t.Fatalf("err = %v, want ErrScanUnavailable", err)
+	}
+}
+
+func TestStoreUploaded(t *testing.T) {
+	repo, objects := newFakeRepo(), newFakeStorage()
+	svc := NewService(repo, objects, StubScanner{}, time.UTC, testLogger())
+	ctx := context.Background()
+
+	if _, err := svc.Store(ctx, upload("a.txt", "same content")); err != nil {
+		t.Fatal(err)
+	}
+
+	// An assembled upload with content already stored shares that object
+	_ = objects.UploadFile(ctx, "assembled", strings.NewReader("same content"), 12, "text/plain")
+	stored, err := svc.StoreUploaded(ctx, Upload{FileName: "b.txt", Size: 12, CategoryID: 1, ObjectKey: "assembled"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.Deduplicated || stored.ObjectKey != "a.txt" {
+		t.Errorf("stored = %+v", stored)
+	}
+	if _, ok := objects.objects["assembled"]; ok {
+		t.Error("duplicate object kept")
+	}
+
+	// An infected one is removed
+	_ = objects.UploadFile(ctx, "bad", strings.NewReader(eicarSignature), int64(len(eicarSignature)), "")
+	if _, err := svc.StoreUploaded(ctx, Upload{FileName: "bad", CategoryID: 1, ObjectKey: "bad"}); !errors.Is(err, ErrInfected) {
+		t.Fatalf("err = %v, want ErrInfected", err)
+	}
+	if _, ok := objects.objects["bad"]; ok {
+		t.Error("infected object kept")
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+
+	setup := func() (*fakeRepo, *fakeStorage) {
+		repo, objects := newFakeRepo(), newFakeStorage()
+		repo.policies = []file.RetentionPolicy{{CategoryID: 1, CategoryName: "events"}}
+		repo.files = map[int64]file.Model{
+			1: {ID: 1, CategoryID: 1, ObjectKey: "k1", SizeBytes: 10},
+			2: {ID: 2, CategoryID: 1, ObjectKey: "shared", SizeBytes: 20},
+			3: {ID: 3, CategoryID: 1, ObjectKey: "shared", SizeBytes: 20},
+			4: {ID: 4, CategoryID: 1, ObjectKey: "signed", SizeBytes: 40},
+		}
+		repo.candidates = []file.PurgeCandidate{
+			{Model: repo.files[1], Reason: file.PurgeOrphan},
+			{Model: repo.files[2], Reason: file.PurgeExpired},
+			{Model: repo.files[4], Reason: file.PurgeExpired},
+		}
+		repo.held[4] = storage.ErrAttachmentsLocked
+		for _, k := range []string{"k1", "shared", "signed"} {
+			objects.objects[k] = minio.ObjectInfo{Key: k, LastModified: old}
+		}
+		objects.objects["lost"] = minio.ObjectInfo{Key: "lost", Size: 5, LastModified: old}
+		objects.objects["fresh"] = minio.ObjectInfo{Key: "fresh", Size: 7, LastModified: time.Now()}
+		return repo, objects
+	}
+
+	t.Run("purges", func(t *testing.T) {
+		repo, objects := setup()
+		run, err := NewService(repo, objects, nil, time.UTC, testLogger()).Cleanup(context.Background(), false, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if run.OrphanFiles != 1 || run.ExpiredFiles != 1 || run.HeldFiles != 1 || run.OrphanObjects != 1 {
+			t.Errorf("run = %+v", run)
+		}
+		// k1 (10) and the lost object (5); the shared object is still used by file 3
+		if run.ReclaimedBytes != 15 {
+			t.Errorf("reclaimed = %d, want 15", run.ReclaimedBytes)
+		}
+		for _, k := range []string{"shared", "signed", "fresh"} {
+			if _, ok := objects.objects[k]; !ok {
+				t.Errorf("object %s removed", k)
+			}
+		}
+		if len(run.Categories) != 1 || run.Categories[0].CategoryName != "events" || run.Categories[0].HeldFiles != 1 {
+			t.Errorf("categories = %+v", run.Categories)
+		}
+		if len(repo.runs) != 1 {
+			t.Error("run not recorded")
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		repo, objects := setup()
+		run, err := NewService(repo, objects, nil, time.UTC, testLogger()).Cleanup(context.Background(), true, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(repo.files) != 4 || len(objects.objects) != 5 {
+			t.Fatal("dry run removed something")
+		}
+		if run.OrphanFiles != 1 || run.ExpiredFiles != 2 || run.OrphanObjects != 1 || !repo.runs[0].DryRun {
+			t.Errorf("run = %+v", run)
+		}
+	})
+}
+
+func TestSetPolicy_RequiresAnAction(t *testing.T) {
+	svc := NewService(newFakeRepo(), newFakeStorage(), nil, time.UTC, testLogger())
+
+	err := svc.SetPolicy(context.Background(), 1, file.RetentionPolicyRequest{}, 1)
+	if !errors.Is(err, ErrEmptyPolicy) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestParseClamdReply(t *testing.T) {
+	if err := parseClamdReply("stream: OK\x00"); err != nil {
+		t.Errorf("OK: %v", err)
+	}
+	var infected *InfectedError
+	if err := parseClamdReply("stream: Win.Test.EICAR_HDB-1 FOUND\x00"); !errors.As(err, &infected) || infected.Signature != "Win.Test.EICAR_HDB-1" {
+		t.Errorf("FOUND: %v", err)
+	}
+	if err := parseClamdReply("INSTREAM size limit exceeded. ERROR\x00"); err == nil || errors.Is(err, ErrInfected) {
+		t.Errorf("ERROR: %v", err)
+	}
+}
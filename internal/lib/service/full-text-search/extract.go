@@ -0,0 +1,222 @@
+package fts
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var (
+	ErrUnsupportedFormat    = errors.New("no text extractor for the file format")
+	ErrExtractorUnavailable = errors.New("text extractor is not installed")
+)
+
+// maxTextBytes caps the text kept per file; the index keeps less.
+const maxTextBytes = 400_000
+
+const pdfTimeout = time.Minute
+
+type extractFunc func(ctx context.Context, data []byte) (string, error)
+
+var extractors = map[string]extractFunc{
+	".docx": extractDOCX,
+	".xlsx": extractXLSX,
+	".pdf":  extractPDF,
+	".txt":  extractPlain,
+	".csv":  extractPlain,
+}
+
+var mimeExtensions = map[string]string{
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": ".docx",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":       ".xlsx",
+	"application/pdf": ".pdf",
+	"text/plain":      ".txt",
+	"text/csv":        ".csv",
+}
+
+// formatOf picks the extractor key from the file name, falling back to the
+// stored mime type.
+func formatOf(fileName string, mimeType *string) string {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if _, ok := extractors[ext]; ok {
+		return ext
+	}
+	if mimeType != nil {
+		return mimeExtensions[strings.ToLower(strings.TrimSpace(strings.Split(*mimeType, ";")[0]))]
+	}
+	return ""
+}
+
+// ExtractText returns the plain text of a PDF, DOCX, XLSX, TXT or CSV file.
+func ExtractText(ctx context.Context, fileName string, mimeType *string, data []byte) (string, error) {
+	extract, ok := extractors[formatOf(fileName, mimeType)]
+	if !ok {
+		return "", ErrUnsupportedFormat
+	}
+	text, err := extract(ctx, data)
+	if err != nil {
+		return "", err
+	}
+	return normalizeText(text), nil
+}
+
+// normalizeText drops invalid UTF-8 and NUL bytes (Postgres rejects both in
+// TEXT), collapses blank runs and caps the length.
+func normalizeText(s string) string {
+	s = strings.ToValidUTF8(s, "")
+	s = strings.ReplaceAll(s, "\x00", "")
+
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			out = append(out, line)
+		}
+	}
+	s = strings.Join(out, "\n")
+
+	if len(s) > maxTextBytes {
+		s = s[:maxTextBytes]
+		for !utf8.ValidString(s) {
+			s = s[:len(s)-1]
+		}
+	}
+	return s
+}
+
+func extractPlain(_ context.Context, data []byte) (string, error) {
+	return string(data), nil
+}
+
+// extractDOCX reads the paragraphs of word/document.xml.
+func extractDOCX(_ context.Context, data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to open docx: %w", err)
+	}
+
+	var doc *zip.File
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			doc = f
+			break
+		}
+	}
+	if doc == nil {
+		return "", errors.New("docx has no word/document.xml")
+	}
+
+	rc, err := doc.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open document.xml: %w", err)
+	}
+	defer rc.Close()
+
+	var sb strings.Builder
+	dec := xml.NewDecoder(rc)
+	inText := false
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to parse document.xml: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteByte(' ')
+			case "br", "cr":
+				sb.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				sb.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+// extractXLSX reads the cell values of every sheet, one row per line.
+func extractXLSX(_ context.Context, data []byte) (string, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to open xlsx: %w", err)
+	}
+	defer f.Close()
+
+	var sb strings.Builder
+	for _, sheet := range f.GetSheetList() {
+		rows, err := f.GetRows(sheet)
+		if err != nil {
+			return "", fmt.Errorf("failed to read sheet %q: %w", sheet, err)
+		}
+		sb.WriteString(sheet)
+		sb.WriteByte('\n')
+		for _, row := range rows {
+			sb.WriteString(strings.Join(row, " "))
+			sb.WriteByte('\n')
+			if sb.Len() > maxTextBytes {
+				return sb.String(), nil
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+// extractPDF runs poppler's pdftotext, like the report exports run soffice.
+func extractPDF(ctx context.Context, data []byte) (string, error) {
+	if _, err := exec.LookPath("pdftotext"); err != nil {
+		return "", ErrExtractorUnavailable
+	}
+
+	tmp, err := os.CreateTemp("", "fts-*.pdf")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pdfTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "pdftotext", "-enc", "UTF-8", "-q", tmp.Name(), "-")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("pdftotext failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
@@ -0,0 +1,120 @@
+package fts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"srmt-admin/internal/lib/model/search"
+)
+
+const (
+	indexBatchSize = 20
+	maxAttempts    = 3
+	maxFileBytes   = 50 << 20
+)
+
+// StartIndexer extracts the text of queued attachments every interval.
+// Blocks until ctx is cancelled.
+func (s *Service) StartIndexer(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := s.IndexPending(ctx)
+			if err != nil {
+				s.log.Error("attachment text extraction failed", slog.String("error", err.Error()))
+				break
+			}
+			if n < indexBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			s.log.Info("full-text search indexer stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// IndexPending extracts one batch of queued attachments and returns its size.
+// A file that fails is retried on later runs up to maxAttempts.
+func (s *Service) IndexPending(ctx context.Context) (int, error) {
+	const op = "service.full-text-search.IndexPending"
+
+	files, err := s.repo.GetPendingFileTexts(ctx, indexBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, f := range files {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		status, content, errMsg := s.extract(ctx, f)
+		if err := s.repo.SaveFileText(ctx, f.FileID, status, content, errMsg); err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+		if status == search.FileTextExtracted {
+			s.log.Debug("attachment text extracted", slog.Int64("file_id", f.FileID), slog.Int("bytes", len(*content)))
+		}
+	}
+	return len(files), nil
+}
+
+func (s *Service) extract(ctx context.Context, f search.PendingFile) (status string, content, errMsg *string) {
+	fail := func(status string, err error) (string, *string, *string) {
+		msg := err.Error()
+		s.log.Warn("attachment text not extracted",
+			slog.Int64("file_id", f.FileID), slog.String("file_name", f.FileName), slog.String("error", msg))
+		return status, nil, &msg
+	}
+
+	if formatOf(f.FileName, f.MimeType) == "" {
+		return fail(search.FileTextUnsupported, ErrUnsupportedFormat)
+	}
+	if f.SizeBytes != nil && *f.SizeBytes > maxFileBytes {
+		return fail(search.FileTextUnsupported, fmt.Errorf("file is larger than %d MB", maxFileBytes>>20))
+	}
+
+	data, err := s.download(ctx, f.ObjectKey)
+	if err == nil {
+		var text string
+		if text, err = ExtractText(ctx, f.FileName, f.MimeType, data); err == nil {
+			return search.FileTextExtracted, &text, nil
+		}
+	}
+
+	switch {
+	case errors.Is(err, ErrUnsupportedFormat):
+		return fail(search.FileTextUnsupported, err)
+	case errors.Is(err, ErrExtractorUnavailable), f.Attempts+1 >= maxAttempts:
+		return fail(search.FileTextFailed, err)
+	default:
+		return fail(search.FileTextPending, err)
+	}
+}
+
+func (s *Service) download(ctx context.Context, objectKey string) ([]byte, error) {
+	obj, err := s.files.GetObject(ctx, objectKey)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	data, err := io.ReadAll(io.LimitReader(obj, maxFileBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+	if len(data) > maxFileBytes {
+		return nil, fmt.Errorf("%w: file is larger than %d MB", ErrUnsupportedFormat, maxFileBytes>>20)
+	}
+	return data, nil
+}
@@ -0,0 +1,144 @@
+// Package fts serves full-text search over registry documents, legal
+// documents and the text of their attachments. The index itself lives in
+// Postgres and is kept current by triggers; this service applies the
+// caller's organization scope, builds entity links and extracts attachment
+// text from MinIO in the background.
+package fts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"srmt-admin/internal/lib/model/search"
+)
+
+var (
+	ErrQueryTooShort = errors.New("search query must be at least 2 characters")
+	ErrQueryTooLong  = errors.New("search query must be at most 200 characters")
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+
+	minQueryLen = 2
+	maxQueryLen = 200
+)
+
+// registryRoles may open the registry, matching its route group; everyone
+// else finds legal documents only.
+var registryRoles = []string{"chancellery", "rais"}
+
+// fullAccessRoles see registry documents of every organization, the same
+// rule as auth.CheckOrgAccess.
+var fullAccessRoles = []string{"sc", "rais"}
+
+type Repository interface {
+	SearchDocuments(ctx context.Context, q search.Query, scope search.Scope) (*search.Result, error)
+	GetPendingFileTexts(ctx context.Context, limit int) ([]search.PendingFile, error)
+	SaveFileText(ctx context.Context, fileID int64, status string, content, errMsg *string) error
+	ReindexSearch(ctx context.Context) (int, error)
+}
+
+// ObjectGetter reads attachment objects from MinIO.
+type ObjectGetter interface {
+	GetObject(ctx context.Context, objectName string) (io.ReadCloser, error)
+}
+
+// Actor is the caller whose organization access limits the results.
+type Actor struct {
+	Roles           []string
+	OrganizationIDs []int64
+}
+
+type Service struct {
+	repo     Repository
+	files    ObjectGetter
+	log      *slog.Logger
+	interval time.Duration
+}
+
+func NewService(repo Repository, files ObjectGetter, log *slog.Logger) *Service {
+	return &Service{
+		repo:     repo,
+		files:    files,
+		log:      log.With(slog.String("service", "full-text-search")),
+		interval: time.Minute,
+	}
+}
+
+// Search runs q within the actor's scope and links every hit to its entity.
+func (s *Service) Search(ctx context.Context, q search.Query, actor Actor) (*search.Result, error) {
+	const op = "service.full-text-search.Search"
+
+	q.Text = strings.TrimSpace(q.Text)
+	if n := utf8.RuneCountInString(q.Text); n < minQueryLen {
+		return nil, fmt.Errorf("%s: %w", op, ErrQueryTooShort)
+	} else if n > maxQueryLen {
+		return nil, fmt.Errorf("%s: %w", op, ErrQueryTooLong)
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultLimit
+	}
+	q.Limit = min(q.Limit, MaxLimit)
+	q.Offset = max(q.Offset, 0)
+
+	result, err := s.repo.SearchDocuments(ctx, q, scopeOf(actor))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for i := range result.Hits {
+		result.Hits[i].Link = EntityLink(result.Hits[i].EntityType, result.Hits[i].EntityID)
+	}
+	return result, nil
+}
+
+// Reindex rebuilds the index and requeues failed extractions.
+func (s *Service) Reindex(ctx context.Context) (int, error) {
+	const op = "service.full-text-search.Reindex"
+
+	n, err := s.repo.ReindexSearch(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	s.log.Info("search index rebuilt", slog.Int("entries", n))
+	return n, nil
+}
+
+// EntityLink is the API path of the entity a hit refers to.
+func EntityLink(entityType string, id int64) string {
+	if entityType == search.EntityLegalDocument {
+		return fmt.Sprintf("/legal-documents/%d", id)
+	}
+	return fmt.Sprintf("/documents/%s/%d", entityType, id)
+}
+
+func scopeOf(actor Actor) search.Scope {
+	if !hasAnyRole(actor, registryRoles) {
+		return search.Scope{}
+	}
+	if hasAnyRole(actor, fullAccessRoles) {
+		return search.Scope{Registry: true, All: true}
+	}
+	ids := actor.OrganizationIDs
+	if ids == nil {
+		ids = []int64{}
+	}
+	return search.Scope{Registry: true, OrganizationIDs: ids}
+}
+
+func hasAnyRole(actor Actor, roles []string) bool {
+	for _, role := range actor.Roles {
+		if slices.Contains(roles, role) {
+			return true
+		}
+	}
+	return false
+}
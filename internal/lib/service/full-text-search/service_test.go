@@ -0,0 +1,213 @@
+package fts
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"srmt-admin/internal/lib/model/search"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+type savedText struct {
+	status  string
+	content *string
+	errMsg  *string
+}
+
+type fakeRepo struct {
+	scope   search.Scope
+	query   search.Query
+	hits    []search.Hit
+	pending []search.PendingFile
+	saved   map[int64]savedText
+}
+
+func (f *fakeRepo) SearchDocuments(_ context.Context, q search.Query, scope search.Scope) (*search.Result, error) {
+	f.query, f.scope = q, scope
+	return &search.Result{Total: len(f.hits), Hits: f.hits}, nil
+}
+
+func (f *fakeRepo) GetPendingFileTexts(_ context.Context, limit int) ([]search.PendingFile, error) {
+	return f.pending[:min(limit, len(f.pending))], nil
+}
+
+func (f *fakeRepo) SaveFileText(_ context.Context, fileID int64, status string, content, errMsg *string) error {
+	if f.saved == nil {
+		f.saved = make(map[int64]savedText)
+	}
+	f.saved[fileID] = savedText{status: status, content: content, errMsg: errMsg}
+	return nil
+}
+
+func (f *fakeRepo) ReindexSearch(_ context.Context) (int, error) {
+	return 0, nil
+}
+
+type fakeObjects map[string][]byte
+
+func (f fakeObjects) GetObject(_ context.Context, name string) (io.ReadCloser, error) {
+	data, ok := f[name]
+	if !ok {
+		return nil, errors.New("object not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func newTestService(repo *fakeRepo, objects fakeObjects) *Service {
+	return NewService(repo, objects, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestSearchScopeAndLinks(t *testing.T) {
+	repo := &fakeRepo{hits: []search.Hit{
+		{EntityType: "decree", EntityID: 4},
+		{EntityType: search.EntityLegalDocument, EntityID: 9},
+	}}
+	svc := newTestService(repo, nil)
+
+	res, err := svc.Search(context.Background(), search.Query{Text: "  приказ  "}, Actor{Roles: []string{"chancellery"}, OrganizationIDs: []int64{7}})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !repo.scope.Registry || repo.scope.All || len(repo.scope.OrganizationIDs) != 1 || repo.scope.OrganizationIDs[0] != 7 {
+		t.Fatalf("chancellery user got scope %+v", repo.scope)
+	}
+	if repo.query.Text != "приказ" || repo.query.Limit != DefaultLimit {
+		t.Fatalf("query not normalized: %+v", repo.query)
+	}
+	if res.Hits[0].Link != "/documents/decree/4" || res.Hits[1].Link != "/legal-documents/9" {
+		t.Fatalf("unexpected links %q, %q", res.Hits[0].Link, res.Hits[1].Link)
+	}
+
+	if _, err := svc.Search(context.Background(), search.Query{Text: "x", Limit: 500}, Actor{}); !errors.Is(err, ErrQueryTooShort) {
+		t.Fatalf("got %v, want ErrQueryTooShort", err)
+	}
+
+	if _, err := svc.Search(context.Background(), search.Query{Text: "отчёт", Limit: 500}, Actor{Roles: []string{"rais"}}); err != nil {
+		t.Fatal(err)
+	}
+	if !repo.scope.Registry || !repo.scope.All || repo.query.Limit != MaxLimit {
+		t.Fatalf("rais got scope %+v, limit %d", repo.scope, repo.query.Limit)
+	}
+
+	if _, err := svc.Search(context.Background(), search.Query{Text: "отчёт"}, Actor{Roles: []string{"chancellery"}}); err != nil {
+		t.Fatal(err)
+	}
+	if repo.scope.All || repo.scope.OrganizationIDs == nil || len(repo.scope.OrganizationIDs) != 0 {
+		t.Fatalf("chancellery user without organizations got scope %+v", repo.scope)
+	}
+
+	// Without a registry role only legal documents are searched, even for sc.
+	for _, roles := range [][]string{{"ges"}, {"sc"}} {
+		if _, err := svc.Search(context.Background(), search.Query{Text: "отчёт"}, Actor{Roles: roles, OrganizationIDs: []int64{7}}); err != nil {
+			t.Fatal(err)
+		}
+		if repo.scope.Registry || repo.scope.All {
+			t.Fatalf("%v got scope %+v", roles, repo.scope)
+		}
+	}
+}
+
+func buildDOCX(t *testing.T, paragraphs ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("word/document.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body strings.Builder
+	for _, p := range paragraphs {
+		body.WriteString(`<w:p><w:r><w:t>` + p + `</w:t></w:r></w:p>`)
+	}
+	io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?>`+
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`+
+		body.String()+`</w:body></w:document>`)
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func buildXLSX(t *testing.T) []byte {
+	t.Helper()
+	f := excelize.NewFile()
+	f.SetCellValue("Sheet1", "A1", "Выработка")
+	f.SetCellValue("Sheet1", "B1", 125.5)
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractText(t *testing.T) {
+	text, err := ExtractText(context.Background(), "Приказ.DOCX", nil, buildDOCX(t, "О графике   ремонта", "Buyruq ijrosi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "О графике ремонта\nBuyruq ijrosi" {
+		t.Fatalf("docx text %q", text)
+	}
+
+	text, err = ExtractText(context.Background(), "report", ptr("application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"), buildXLSX(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "Выработка 125.5") {
+		t.Fatalf("xlsx text %q", text)
+	}
+
+	if _, err := ExtractText(context.Background(), "scan.jpg", ptr("image/jpeg"), nil); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("got %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func TestIndexPending(t *testing.T) {
+	repo := &fakeRepo{pending: []search.PendingFile{
+		{FileID: 1, FileName: "letter.docx", ObjectKey: "a"},
+		{FileID: 2, FileName: "photo.png", ObjectKey: "b"},
+		{FileID: 3, FileName: "broken.docx", ObjectKey: "c"},
+		{FileID: 4, FileName: "broken.docx", ObjectKey: "c", Attempts: maxAttempts - 1},
+		{FileID: 5, FileName: "huge.txt", ObjectKey: "d", SizeBytes: ptr(int64(maxFileBytes + 1))},
+	}}
+	objects := fakeObjects{
+		"a": buildDOCX(t, "Письмо в Минэнерго"),
+		"c": []byte("not a zip"),
+	}
+
+	n, err := newTestService(repo, objects).IndexPending(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 5 {
+		t.Fatalf("processed %d files, want 5", n)
+	}
+
+	want := map[int64]string{
+		1: search.FileTextExtracted,
+		2: search.FileTextUnsupported,
+		3: search.FileTextPending,
+		4: search.FileTextFailed,
+		5: search.FileTextUnsupported,
+	}
+	for id, status := range want {
+		if got := repo.saved[id].status; got != status {
+			t.Errorf("file %d: got status %q, want %q", id, got, status)
+		}
+	}
+	if c := repo.saved[1].content; c == nil || *c != "Письмо в Минэнерго" {
+		t.Fatalf("unexpected extracted text %v", c)
+	}
+	if repo.saved[3].errMsg == nil {
+		t.Fatal("retryable failure has no error recorded")
+	}
+}
@@ -0,0 +1,190 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/access"
+	"srmt-admin/internal/storage"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// cardAlerts maps a card status other than active to its alert
+var cardAlerts = map[string]string{
+	"blocked":     access.AlertCardBlocked,
+	"lost":        access.AlertCardLost,
+	"expired":     access.AlertCardExpired,
+	"deactivated": access.AlertCardDeactivated,
+}
+
+// Ingest stores controller events in time order. Each is matched to its card
+// and employee by card number and to its zone by zone or reader; attempts
+// with a blocked, lost, expired or unknown card are flagged, and granted
+// events move the employee through the zone, flagging anti-passback and
+// over-capacity entries. Presence expires relative to each event's own time,
+// so a controller uploading a buffered day is judged as it happened. The timesheets of the employees seen are refilled
+// for the days of their granted events.
+func (s *Service) Ingest(ctx context.Context, events []dto.AccessEventInput) (*access.IngestResult, error) {
+	order := make([]int, len(events))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return events[order[a]].Timestamp.Before(events[order[b]].Timestamp)
+	})
+
+	res := &access.IngestResult{Events: make([]access.IngestedEvent, len(events))}
+	cards := make(map[string]*access.AccessCard)
+	readers := make(map[int]*int64)
+	days := make(map[int64][2]string)
+
+	for _, i := range order {
+		ev := events[i]
+		rec := access.EventRecord{
+			ControllerID:  ev.ControllerID,
+			EventID:       ev.EventID,
+			CardNumber:    ev.CardNumber,
+			ZoneID:        ev.ZoneID,
+			ReaderID:      ev.ReaderID,
+			Direction:     ev.Direction,
+			Timestamp:     ev.Timestamp,
+			Status:        ev.Status,
+			DenialReason:  ev.DenialReason,
+			PresenceSince: ev.Timestamp.Add(-s.presenceTTL),
+		}
+
+		card, ok := cards[ev.CardNumber]
+		if !ok {
+			var err error
+			card, err = s.repo.GetAccessCardByNumber(ctx, ev.CardNumber)
+			if err != nil && !errors.Is(err, storage.ErrAccessCardNotFound) {
+				return nil, fmt.Errorf("match card: %w", err)
+			}
+			cards[ev.CardNumber] = card
+		}
+		rec.Alert = s.cardAlert(card, ev.Timestamp)
+		if card != nil {
+			rec.CardID = &card.ID
+			rec.EmployeeID = &card.EmployeeID
+		}
+
+		if rec.ZoneID == nil && ev.ReaderID != nil {
+			zoneID, ok := readers[*ev.ReaderID]
+			if !ok {
+				id, err := s.repo.GetAccessZoneIDByReader(ctx, *ev.ReaderID)
+				switch {
+				case err == nil:
+					zoneID = &id
+				case errors.Is(err, storage.ErrAccessZoneNotFound):
+					s.log.Warn("access event from a reader of no zone", slog.Int("reader_id", *ev.ReaderID))
+				default:
+					return nil, fmt.Errorf("match reader: %w", err)
+				}
+				readers[*ev.ReaderID] = zoneID
+			}
+			rec.ZoneID = zoneID
+		}
+
+		recorded, err := s.repo.RecordAccessEvent(ctx, rec)
+		if err != nil {
+			return nil, fmt.Errorf("record event %d: %w", i, err)
+		}
+
+		res.Events[i] = access.IngestedEvent{
+			Index:      i,
+			ID:         recorded.ID,
+			EmployeeID: rec.EmployeeID,
+			ZoneID:     rec.ZoneID,
+			Duplicate:  recorded.Duplicate,
+			Alert:      recorded.Alert,
+		}
+		if recorded.Duplicate {
+			res.Duplicates++
+			continue
+		}
+		res.Accepted++
+		if recorded.Alert != nil {
+			res.Alerts++
+			s.log.Warn("access alert",
+				slog.String("alert", *recorded.Alert),
+				slog.String("controller_id", ev.ControllerID),
+				slog.Int64("log_id", recorded.ID))
+		}
+
+		if ev.Status == "granted" && rec.EmployeeID != nil {
+			day := ev.Timestamp.In(s.loc).Format(dateLayout)
+			span, ok := days[*rec.EmployeeID]
+			if !ok || day < span[0] {
+				span[0] = day
+			}
+			if !ok || day > span[1] {
+				span[1] = day
+			}
+			days[*rec.EmployeeID] = span
+		}
+	}
+
+	s.recalculateTimesheets(ctx, days)
+	return res, nil
+}
+
+// cardAlert returns the alert for an event presented with card, if any
+func (s *Service) cardAlert(card *access.AccessCard, at time.Time) *string {
+	var alert string
+	switch {
+	case card == nil:
+		alert = access.AlertUnknownCard
+	case card.Status != "active":
+		alert = cardAlerts[card.Status]
+	case card.ExpiryDate < at.In(s.loc).Format(dateLayout):
+		alert = access.AlertCardExpired
+	}
+	if alert == "" {
+		return nil
+	}
+	return &alert
+}
+
+// recalculateTimesheets refills the timesheets for the days of the ingested
+// events. A failure is logged; the nightly refill catches up.
+func (s *Service) recalculateTimesheets(ctx context.Context, days map[int64][2]string) {
+	if s.timesheet == nil {
+		return
+	}
+	for employeeID, span := range days {
+		if err := s.timesheet.RecalculateTimesheet(ctx, employeeID, span[0], span[1]); err != nil {
+			s.log.Error("failed to recalculate timesheet", "error", err, "employee_id", employeeID)
+		}
+	}
+}
+
+// GetOccupancy returns every zone with the employees inside it now
+func (s *Service) GetOccupancy(ctx context.Context) ([]*access.ZoneOccupancy, error) {
+	s.expirePresence(ctx)
+	zones, err := s.repo.GetZoneOccupancy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if zones == nil {
+		zones = []*access.ZoneOccupancy{}
+	}
+	return zones, nil
+}
+
+// expirePresence forgets employees who stayed in a zone longer than the
+// presence TTL, taking them to have left without badging out
+func (s *Service) expirePresence(ctx context.Context) {
+	n, err := s.repo.DeleteStaleZonePresence(ctx, time.Now().Add(-s.presenceTTL))
+	if err != nil {
+		s.log.Error("failed to expire zone presence", "error", err)
+		return
+	}
+	if n > 0 {
+		s.log.Info("zone presence expired", slog.Int64("employees", n))
+	}
+}
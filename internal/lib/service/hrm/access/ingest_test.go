@@ -0,0 +1,291 @@
+package access
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/access"
+	"srmt-admin/internal/storage"
+)
+
+// fakeRepo implements RepoInterface. It matches cards and readers from
+// memory and records what it is asked to store; the card, zone and request
+// methods ingestion never calls panic.
+type fakeRepo struct {
+	cards   map[string]*access.AccessCard
+	readers map[int]int64
+	stored  map[string]bool
+
+	records     []access.EventRecord
+	readerCalls int
+	// presence is who is inside which zone since when, as [zone, employee]
+	presence map[[2]int64]time.Time
+}
+
+func (f *fakeRepo) GetAccessCardByNumber(_ context.Context, number string) (*access.AccessCard, error) {
+	if c, ok := f.cards[number]; ok {
+		return c, nil
+	}
+	return nil, storage.ErrAccessCardNotFound
+}
+
+func (f *fakeRepo) GetAccessZoneIDByReader(_ context.Context, readerID int) (int64, error) {
+	f.readerCalls++
+	if id, ok := f.readers[readerID]; ok {
+		return id, nil
+	}
+	return 0, storage.ErrAccessZoneNotFound
+}
+
+func (f *fakeRepo) RecordAccessEvent(_ context.Context, rec access.EventRecord) (*access.RecordedEvent, error) {
+	if rec.EventID != nil {
+		if f.stored == nil {
+			f.stored = make(map[string]bool)
+		}
+		key := rec.ControllerID + "/" + *rec.EventID
+		if f.stored[key] {
+			return &access.RecordedEvent{ID: 1, Duplicate: true}, nil
+		}
+		f.stored[key] = true
+	}
+	f.records = append(f.records, rec)
+	if rec.Alert == nil && rec.Status == "granted" && rec.ZoneID != nil && rec.EmployeeID != nil {
+		rec.Alert = f.moveThroughZone(rec)
+	}
+	return &access.RecordedEvent{ID: int64(len(f.records)), Alert: rec.Alert}, nil
+}
+
+// moveThroughZone mirrors the repository: stale presence is forgotten, then
+// an exit without an entry or a second entry is anti-passback.
+func (f *fakeRepo) moveThroughZone(rec access.EventRecord) *string {
+	if f.presence == nil {
+		f.presence = make(map[[2]int64]time.Time)
+	}
+	for key, enteredAt := range f.presence {
+		if key[0] == *rec.ZoneID && enteredAt.Before(rec.PresenceSince) {
+			delete(f.presence, key)
+		}
+	}
+	key := [2]int64{*rec.ZoneID, *rec.EmployeeID}
+	_, inside := f.presence[key]
+	if rec.Direction == "exit" {
+		delete(f.presence, key)
+	} else {
+		f.presence[key] = rec.Timestamp
+	}
+	if inside == (rec.Direction == "exit") {
+		return nil
+	}
+	alert := access.AlertAntiPassback
+	return &alert
+}
+
+func (f *fakeRepo) CreateAccessCard(context.Context, dto.CreateAccessCardRequest) (int64, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetAccessCardByID(context.Context, int64) (*access.AccessCard, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetAllAccessCards(context.Context, dto.AccessCardFilters) ([]*access.AccessCard, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) UpdateAccessCard(context.Context, int64, dto.UpdateAccessCardRequest) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) UpdateAccessCardStatus(context.Context, int64, string) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) CreateAccessZone(context.Context, dto.CreateAccessZoneRequest) (int64, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetAccessZoneByID(context.Context, int64) (*access.AccessZone, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetAllAccessZones(context.Context) ([]*access.AccessZone, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) UpdateAccessZone(context.Context, int64, dto.UpdateAccessZoneRequest) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetAccessLogs(context.Context, dto.AccessLogFilters) ([]*access.AccessLog, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) DeleteStaleZonePresence(context.Context, time.Time) (int64, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetZoneOccupancy(context.Context) ([]*access.ZoneOccupancy, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) CreateAccessRequest(context.Context, int64, dto.CreateAccessRequestReq) (int64, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetAllAccessRequests(context.Context, *int64) ([]*access.AccessRequest, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetAccessRequestByID(context.Context, int64) (*access.AccessRequest, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) UpdateAccessRequestStatus(context.Context, int64, string, *int64, *string) error {
+	panic("not implemented")
+}
+
+type fakeTimesheet struct {
+	calls map[int64][2]string
+}
+
+func (f *fakeTimesheet) RecalculateTimesheet(_ context.Context, employeeID int64, from, to string) error {
+	if f.calls == nil {
+		f.calls = make(map[int64][2]string)
+	}
+	f.calls[employeeID] = [2]string{from, to}
+	return nil
+}
+
+func newTestService(repo *fakeRepo, ts *fakeTimesheet) *Service {
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	return NewService(repo, ts, loc, 16*time.Hour, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func strPtr(s string) *string { return &s }
+func intPtr(i int) *int       { return &i }
+func int64Ptr(i int64) *int64 { return &i }
+
+func TestIngest_MatchesCardsAndFlagsCardProblems(t *testing.T) {
+	repo := &fakeRepo{
+		cards: map[string]*access.AccessCard{
+			"A1": {ID: 1, EmployeeID: 10, Status: "active", ExpiryDate: "2030-01-01"},
+			"B2": {ID: 2, EmployeeID: 11, Status: "blocked", ExpiryDate: "2030-01-01"},
+			"C3": {ID: 3, EmployeeID: 12, Status: "active", ExpiryDate: "2026-05-01"},
+		},
+	}
+	svc := newTestService(repo, &fakeTimesheet{})
+	at := time.Date(2026, 5, 4, 4, 0, 0, 0, time.UTC)
+
+	res, err := svc.Ingest(context.Background(), []dto.AccessEventInput{
+		{ControllerID: "gate-1", CardNumber: "A1", Direction: "entry", Timestamp: at, Status: "granted"},
+		{ControllerID: "gate-1", CardNumber: "B2", Direction: "entry", Timestamp: at, Status: "denied"},
+		{ControllerID: "gate-1", CardNumber: "C3", Direction: "entry", Timestamp: at, Status: "denied"},
+		{ControllerID: "gate-1", CardNumber: "ZZ", Direction: "entry", Timestamp: at, Status: "denied"},
+	})
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+	if res.Accepted != 4 || res.Alerts != 3 {
+		t.Fatalf("result = %+v, want 4 accepted, 3 alerts", res)
+	}
+
+	want := []struct {
+		employee *int64
+		alert    string
+	}{
+		{employee: int64Ptr(10)},
+		{employee: int64Ptr(11), alert: access.AlertCardBlocked},
+		{employee: int64Ptr(12), alert: access.AlertCardExpired},
+		{alert: access.AlertUnknownCard},
+	}
+	for i, w := range want {
+		got := res.Events[i]
+		if (got.EmployeeID == nil) != (w.employee == nil) || (got.EmployeeID != nil && *got.EmployeeID != *w.employee) {
+			t.Errorf("event %d: employee = %v, want %v", i, got.EmployeeID, w.employee)
+		}
+		alert := ""
+		if got.Alert != nil {
+			alert = *got.Alert
+		}
+		if alert != w.alert {
+			t.Errorf("event %d: alert = %q, want %q", i, alert, w.alert)
+		}
+	}
+}
+
+func TestIngest_StoresInTimeOrderAndSkipsDuplicates(t *testing.T) {
+	repo := &fakeRepo{
+		cards:   map[string]*access.AccessCard{"A1": {ID: 1, EmployeeID: 10, Status: "active", ExpiryDate: "2030-01-01"}},
+		readers: map[int]int64{7: 3},
+	}
+	svc := newTestService(repo, &fakeTimesheet{})
+	morning := time.Date(2026, 5, 4, 4, 0, 0, 0, time.UTC)
+	evening := morning.Add(9 * time.Hour)
+
+	events := []dto.AccessEventInput{
+		{ControllerID: "gate-1", EventID: strPtr("2"), CardNumber: "A1", ReaderID: intPtr(7), Direction: "exit", Timestamp: evening, Status: "granted"},
+		{ControllerID: "gate-1", EventID: strPtr("1"), CardNumber: "A1", ReaderID: intPtr(7), Direction: "entry", Timestamp: morning, Status: "granted"},
+	}
+	if _, err := svc.Ingest(context.Background(), events); err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+
+	if len(repo.records) != 2 || repo.records[0].Direction != "entry" || repo.records[1].Direction != "exit" {
+		t.Fatalf("records = %+v, want entry then exit", repo.records)
+	}
+	for _, rec := range repo.records {
+		if rec.ZoneID == nil || *rec.ZoneID != 3 {
+			t.Errorf("zone = %v, want 3 from reader 7", rec.ZoneID)
+		}
+	}
+	if repo.readerCalls != 1 {
+		t.Errorf("reader looked up %d times, want once per batch", repo.readerCalls)
+	}
+
+	res, err := svc.Ingest(context.Background(), events)
+	if err != nil {
+		t.Fatalf("Ingest again: %v", err)
+	}
+	if res.Duplicates != 2 || res.Accepted != 0 {
+		t.Errorf("resend result = %+v, want 2 duplicates", res)
+	}
+}
+
+func TestIngest_RecalculatesTimesheetForLocalDays(t *testing.T) {
+	repo := &fakeRepo{
+		cards: map[string]*access.AccessCard{
+			"A1": {ID: 1, EmployeeID: 10, Status: "active", ExpiryDate: "2030-01-01"},
+			"B2": {ID: 2, EmployeeID: 11, Status: "active", ExpiryDate: "2030-01-01"},
+		},
+	}
+	ts := &fakeTimesheet{}
+	svc := newTestService(repo, ts)
+
+	_, err := svc.Ingest(context.Background(), []dto.AccessEventInput{
+		// 20:30 UTC on the 3rd is already the 4th in UTC+5
+		{ControllerID: "gate-1", CardNumber: "A1", Direction: "entry", Timestamp: time.Date(2026, 5, 3, 20, 30, 0, 0, time.UTC), Status: "granted"},
+		{ControllerID: "gate-1", CardNumber: "A1", Direction: "exit", Timestamp: time.Date(2026, 5, 5, 12, 0, 0, 0, time.UTC), Status: "granted"},
+		{ControllerID: "gate-1", CardNumber: "B2", Direction: "entry", Timestamp: time.Date(2026, 5, 5, 4, 0, 0, 0, time.UTC), Status: "denied"},
+	})
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+
+	if got := ts.calls[10]; got != [2]string{"2026-05-04", "2026-05-05"} {
+		t.Errorf("employee 10 recalculated for %v, want 2026-05-04..2026-05-05", got)
+	}
+	if _, ok := ts.calls[11]; ok {
+		t.Error("denied event triggered a timesheet recalculation")
+	}
+}
+
+func TestIngest_ReplayedDayIsJudgedAtItsOwnTime(t *testing.T) {
+	repo := &fakeRepo{
+		cards:   map[string]*access.AccessCard{"A1": {ID: 1, EmployeeID: 10, Status: "active", ExpiryDate: "2030-01-01"}},
+		readers: map[int]int64{7: 3},
+	}
+	svc := newTestService(repo, &fakeTimesheet{})
+	// A controller uploads yesterday's shift, far longer ago than the TTL
+	entry := time.Now().Add(-30 * time.Hour)
+	exit := entry.Add(9 * time.Hour)
+
+	res, err := svc.Ingest(context.Background(), []dto.AccessEventInput{
+		{ControllerID: "gate-1", CardNumber: "A1", ReaderID: intPtr(7), Direction: "entry", Timestamp: entry, Status: "granted"},
+		{ControllerID: "gate-1", CardNumber: "A1", ReaderID: intPtr(7), Direction: "exit", Timestamp: exit, Status: "granted"},
+	})
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+	if res.Alerts != 0 {
+		t.Fatalf("alerts = %d (%+v), want none for a replayed entry/exit pair", res.Alerts, res.Events)
+	}
+}
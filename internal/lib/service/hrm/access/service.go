@@ -6,6 +6,7 @@ import (
 	"srmt-admin/internal/lib/dto"
 	"srmt-admin/internal/lib/model/hrm/access"
 	"srmt-admin/internal/storage"
+	"time"
 )
 
 type RepoInterface interface {
@@ -25,6 +26,13 @@ type RepoInterface interface {
 	// Logs
 	GetAccessLogs(ctx context.Context, filters dto.AccessLogFilters) ([]*access.AccessLog, error)
 
+	// Event ingestion
+	GetAccessCardByNumber(ctx context.Context, cardNumber string) (*access.AccessCard, error)
+	GetAccessZoneIDByReader(ctx context.Context, readerID int) (int64, error)
+	RecordAccessEvent(ctx context.Context, rec access.EventRecord) (*access.RecordedEvent, error)
+	DeleteStaleZonePresence(ctx context.Context, before time.Time) (int64, error)
+	GetZoneOccupancy(ctx context.Context) ([]*access.ZoneOccupancy, error)
+
 	// Requests
 	CreateAccessRequest(ctx context.Context, employeeID int64, req dto.CreateAccessRequestReq) (int64, error)
 	GetAllAccessRequests(ctx context.Context, employeeID *int64) ([]*access.AccessRequest, error)
@@ -32,13 +40,22 @@ type RepoInterface interface {
 	UpdateAccessRequestStatus(ctx context.Context, id int64, status string, approvedBy *int64, rejectionReason *string) error
 }
 
+// TimesheetRecalculator refills an employee's timesheet once new access
+// events arrive for some of its days
+type TimesheetRecalculator interface {
+	RecalculateTimesheet(ctx context.Context, employeeID int64, from, to string) error
+}
+
 type Service struct {
-	repo RepoInterface
-	log  *slog.Logger
+	repo        RepoInterface
+	timesheet   TimesheetRecalculator
+	loc         *time.Location
+	presenceTTL time.Duration
+	log         *slog.Logger
 }
 
-func NewService(repo RepoInterface, log *slog.Logger) *Service {
-	return &Service{repo: repo, log: log}
+func NewService(repo RepoInterface, timesheet TimesheetRecalculator, loc *time.Location, presenceTTL time.Duration, log *slog.Logger) *Service {
+	return &Service{repo: repo, timesheet: timesheet, loc: loc, presenceTTL: presenceTTL, log: log}
 }
 
 // ==================== Cards ====================
@@ -91,6 +108,7 @@ func (s *Service) CreateZone(ctx context.Context, req dto.CreateAccessZoneReques
 }
 
 func (s *Service) GetAllZones(ctx context.Context) ([]*access.AccessZone, error) {
+	s.expirePresence(ctx)
 	zones, err := s.repo.GetAllAccessZones(ctx)
 	if err != nil {
 		return nil, err
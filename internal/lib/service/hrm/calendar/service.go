@@ -0,0 +1,440 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/calendar"
+	"srmt-admin/internal/lib/model/hrm/timesheet"
+	"srmt-admin/internal/storage"
+	"strconv"
+	"time"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	// shortDayCut is how much shorter a shortened working day is, be it the
+	// day before a holiday or one the calendar shortens
+	shortDayCut = 1.0
+)
+
+type RepoInterface interface {
+	// Holidays
+	GetHolidays(ctx context.Context, year int) ([]*timesheet.Holiday, error)
+	GetHolidaysBetween(ctx context.Context, from, to string) ([]*timesheet.Holiday, error)
+	CreateHoliday(ctx context.Context, req dto.CreateHolidayRequest) (int64, error)
+	DeleteHoliday(ctx context.Context, id int64) (string, error)
+
+	// Exceptions and publishing
+	GetCalendarExceptions(ctx context.Context, from, to string) ([]*calendar.Exception, error)
+	GetCalendarYearPublishedAt(ctx context.Context, year int) (*time.Time, error)
+	PublishCalendarYear(ctx context.Context, year int, req dto.PublishCalendarRequest, publishedBy int64) error
+
+	// Week schedules
+	GetWorkSchedules(ctx context.Context) ([]*calendar.Schedule, error)
+	GetWorkSchedule(ctx context.Context, organizationID int64) (*calendar.Schedule, error)
+	SetWorkSchedule(ctx context.Context, organizationID int64, req dto.SetWorkScheduleRequest) error
+	DeleteWorkSchedule(ctx context.Context, organizationID int64) error
+	GetEmployeeOrganizations(ctx context.Context, employeeIDs []int64) (map[int64]int64, error)
+}
+
+// ChangeFunc is told the days (YYYY-MM-DD) whose calendar changed
+type ChangeFunc func(ctx context.Context, from, to string)
+
+// Service is the production calendar shared by the timesheet, vacation and
+// salary services
+type Service struct {
+	repo      RepoInterface
+	listeners []ChangeFunc
+	now       func() time.Time
+	log       *slog.Logger
+}
+
+func NewService(repo RepoInterface, log *slog.Logger) *Service {
+	return &Service{repo: repo, now: time.Now, log: log}
+}
+
+// OnChange registers fn to be called after holidays, exceptions or a week
+// schedule change
+func (s *Service) OnChange(fn ChangeFunc) {
+	s.listeners = append(s.listeners, fn)
+}
+
+func (s *Service) changed(ctx context.Context, from, to string) {
+	for _, fn := range s.listeners {
+		fn(ctx, from, to)
+	}
+}
+
+// Days returns the calendar between from and to under the week schedule of
+// the organization, or the default one when organizationID is nil
+func (s *Service) Days(ctx context.Context, organizationID *int64, from, to time.Time) ([]calendar.Day, error) {
+	sched, err := s.schedule(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	src, err := s.load(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return src.days(sched, from, to), nil
+}
+
+// EmployeeDays returns each employee's calendar between from and to under
+// the week schedule of their organization. Employees of one organization
+// share the same slice.
+func (s *Service) EmployeeDays(ctx context.Context, employeeIDs []int64, from, to time.Time) (map[int64][]calendar.Day, error) {
+	res := make(map[int64][]calendar.Day, len(employeeIDs))
+	if len(employeeIDs) == 0 {
+		return res, nil
+	}
+
+	orgs, err := s.repo.GetEmployeeOrganizations(ctx, employeeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get employee organizations: %w", err)
+	}
+	src, err := s.load(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Employees of no organization share key 0 with the default schedule
+	byOrg := make(map[int64][]calendar.Day)
+	for _, id := range employeeIDs {
+		orgID, known := orgs[id]
+		days, ok := byOrg[orgID]
+		if !ok {
+			var org *int64
+			if known {
+				org = &orgID
+			}
+			sched, err := s.schedule(ctx, org)
+			if err != nil {
+				return nil, err
+			}
+			days = src.days(sched, from, to)
+			byOrg[orgID] = days
+		}
+		res[id] = days
+	}
+	return res, nil
+}
+
+// WorkDays counts the employee's working days between from and to
+func (s *Service) WorkDays(ctx context.Context, employeeID int64, from, to time.Time) (int, error) {
+	days, err := s.EmployeeDays(ctx, []int64{employeeID}, from, to)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, d := range days[employeeID] {
+		if d.Working {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// GetYear returns the calendar of a year with its monthly working time
+func (s *Service) GetYear(ctx context.Context, year int, organizationID *int64) (*calendar.Year, error) {
+	sched, err := s.schedule(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	src, err := s.load(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	publishedAt, err := s.repo.GetCalendarYearPublishedAt(ctx, year)
+	if err != nil {
+		return nil, fmt.Errorf("get publication: %w", err)
+	}
+
+	res := &calendar.Year{
+		Year:        year,
+		Published:   publishedAt != nil,
+		PublishedAt: publishedAt,
+		Schedule:    sched,
+		Months:      make([]calendar.MonthSummary, 12),
+		Exceptions:  src.exceptions,
+		Days:        src.days(sched, from, to),
+	}
+	if res.Exceptions == nil {
+		res.Exceptions = []*calendar.Exception{}
+	}
+
+	for i := range res.Months {
+		res.Months[i].Month = i + 1
+	}
+	for _, d := range res.Days {
+		month, _ := strconv.Atoi(d.Date[5:7])
+		m := &res.Months[month-1]
+		switch {
+		case !d.Working:
+			m.DaysOff++
+		case d.Short:
+			m.ShortDays++
+			fallthrough
+		default:
+			m.WorkDays++
+			m.WorkHours += d.Hours
+		}
+	}
+	return res, nil
+}
+
+// Publish replaces the holidays and transferred and shortened days of a year
+// and marks it published
+func (s *Service) Publish(ctx context.Context, year int, req dto.PublishCalendarRequest, publishedBy int64) error {
+	prefix := strconv.Itoa(year) + "-"
+	holidays := make(map[string]bool, len(req.Holidays))
+	for _, h := range req.Holidays {
+		if _, err := time.Parse(dateLayout, h.Date); err != nil || h.Date[:5] != prefix {
+			return storage.ErrDateOutsideYear
+		}
+		if holidays[h.Date] {
+			return storage.ErrCalendarDayDuplicate
+		}
+		holidays[h.Date] = true
+	}
+	exceptions := make(map[string]bool, len(req.Exceptions))
+	for _, e := range req.Exceptions {
+		if e.Date[:5] != prefix {
+			return storage.ErrDateOutsideYear
+		}
+		key := e.Date
+		if e.WeekDays != nil {
+			key += "/" + strconv.Itoa(*e.WeekDays)
+		}
+		if exceptions[key] {
+			return storage.ErrCalendarDayDuplicate
+		}
+		exceptions[key] = true
+	}
+
+	if err := s.repo.PublishCalendarYear(ctx, year, req, publishedBy); err != nil {
+		return err
+	}
+
+	s.log.Info("production calendar published",
+		slog.Int("year", year),
+		slog.Int("holidays", len(req.Holidays)),
+		slog.Int("exceptions", len(req.Exceptions)))
+	s.changed(ctx, prefix+"01-01", prefix+"12-31")
+	return nil
+}
+
+// GetHolidays returns holidays for a year
+func (s *Service) GetHolidays(ctx context.Context, year int) ([]*timesheet.Holiday, error) {
+	holidays, err := s.repo.GetHolidays(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+	if holidays == nil {
+		holidays = []*timesheet.Holiday{}
+	}
+	return holidays, nil
+}
+
+// CreateHoliday adds a holiday to the calendar
+func (s *Service) CreateHoliday(ctx context.Context, req dto.CreateHolidayRequest) (int64, error) {
+	id, err := s.repo.CreateHoliday(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	s.changed(ctx, s.dayBefore(req.Date), req.Date)
+	return id, nil
+}
+
+// DeleteHoliday removes a holiday from the calendar
+func (s *Service) DeleteHoliday(ctx context.Context, id int64) error {
+	date, err := s.repo.DeleteHoliday(ctx, id)
+	if err != nil {
+		return err
+	}
+	s.changed(ctx, s.dayBefore(date), date)
+	return nil
+}
+
+// GetSchedules returns the week schedules set for organizations
+func (s *Service) GetSchedules(ctx context.Context) ([]*calendar.Schedule, error) {
+	schedules, err := s.repo.GetWorkSchedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if schedules == nil {
+		schedules = []*calendar.Schedule{}
+	}
+	return schedules, nil
+}
+
+// SetSchedule sets an organization's week schedule. It applies from the
+// start of the current month on.
+func (s *Service) SetSchedule(ctx context.Context, organizationID int64, req dto.SetWorkScheduleRequest) error {
+	if err := s.repo.SetWorkSchedule(ctx, organizationID, req); err != nil {
+		return err
+	}
+	s.scheduleChanged(ctx)
+	return nil
+}
+
+// DeleteSchedule returns an organization to the default week schedule
+func (s *Service) DeleteSchedule(ctx context.Context, organizationID int64) error {
+	if err := s.repo.DeleteWorkSchedule(ctx, organizationID); err != nil {
+		return err
+	}
+	s.scheduleChanged(ctx)
+	return nil
+}
+
+func (s *Service) scheduleChanged(ctx context.Context) {
+	now := s.now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(now.Year(), time.December, 31, 0, 0, 0, 0, time.UTC)
+	s.changed(ctx, from.Format(dateLayout), to.Format(dateLayout))
+}
+
+// dayBefore returns the day before date, whose length depends on whether
+// date is a holiday
+func (s *Service) dayBefore(date string) string {
+	d, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return date
+	}
+	return d.AddDate(0, 0, -1).Format(dateLayout)
+}
+
+// schedule returns the organization's week schedule, or the default one
+func (s *Service) schedule(ctx context.Context, organizationID *int64) (calendar.Schedule, error) {
+	def := calendar.Schedule{WeekDays: calendar.DefaultWeekDays, DayHours: calendar.DefaultDayHours}
+	if organizationID == nil {
+		return def, nil
+	}
+	sched, err := s.repo.GetWorkSchedule(ctx, *organizationID)
+	if err != nil {
+		if errors.Is(err, storage.ErrWorkScheduleNotFound) {
+			def.OrganizationID = organizationID
+			return def, nil
+		}
+		return calendar.Schedule{}, fmt.Errorf("get work schedule: %w", err)
+	}
+	return *sched, nil
+}
+
+// sources is the calendar data of a range of days
+type sources struct {
+	holidays   map[string]*timesheet.Holiday
+	exceptions []*calendar.Exception
+	// byDate keys exceptions by date, then by week_days (0 for both)
+	byDate map[string]map[int]*calendar.Exception
+}
+
+// load reads the holidays and exceptions between from and to, and the
+// holidays of the day after, which shorten the last day
+func (s *Service) load(ctx context.Context, from, to time.Time) (*sources, error) {
+	fromStr, toStr := from.Format(dateLayout), to.Format(dateLayout)
+
+	holidays, err := s.repo.GetHolidaysBetween(ctx, fromStr, to.AddDate(0, 0, 1).Format(dateLayout))
+	if err != nil {
+		return nil, fmt.Errorf("get holidays: %w", err)
+	}
+	exceptions, err := s.repo.GetCalendarExceptions(ctx, fromStr, toStr)
+	if err != nil {
+		return nil, fmt.Errorf("get calendar exceptions: %w", err)
+	}
+
+	src := &sources{
+		holidays:   make(map[string]*timesheet.Holiday, len(holidays)),
+		exceptions: exceptions,
+		byDate:     make(map[string]map[int]*calendar.Exception, len(exceptions)),
+	}
+	for _, h := range holidays {
+		src.holidays[h.Date] = h
+	}
+	for _, e := range exceptions {
+		if src.byDate[e.Date] == nil {
+			src.byDate[e.Date] = make(map[int]*calendar.Exception)
+		}
+		weekDays := 0
+		if e.WeekDays != nil {
+			weekDays = *e.WeekDays
+		}
+		src.byDate[e.Date][weekDays] = e
+	}
+	return src, nil
+}
+
+// exception returns the exception on date for the week schedule; one made
+// for the schedule outranks one made for both
+func (src *sources) exception(date string, weekDays int) *calendar.Exception {
+	if e, ok := src.byDate[date][weekDays]; ok {
+		return e
+	}
+	return src.byDate[date][0]
+}
+
+// days lays out the calendar between from and to for a week schedule.
+// Holidays are days off whatever the exceptions say. A working day is
+// shortened when an exception says so or when the next day is a holiday.
+func (src *sources) days(sched calendar.Schedule, from, to time.Time) []calendar.Day {
+	var days []calendar.Day
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format(dateLayout)
+		day := calendar.Day{Date: date, Working: regularWorkday(d.Weekday(), sched.WeekDays)}
+
+		if e := src.exception(date, sched.WeekDays); e != nil {
+			day.Note = e.Note
+			switch e.Type {
+			case calendar.ExceptionWorking:
+				day.Transferred = !day.Working
+				day.Working = true
+			case calendar.ExceptionDayOff:
+				day.Transferred = day.Working
+				day.Working = false
+			case calendar.ExceptionShort:
+				day.Short = true
+			}
+		}
+
+		if h, ok := src.holidays[date]; ok {
+			day.Holiday = true
+			day.HolidayName = h.Name
+			day.Working = false
+		}
+
+		if day.Working {
+			if _, ok := src.holidays[d.AddDate(0, 0, 1).Format(dateLayout)]; ok {
+				day.Short = true
+			}
+			day.Hours = sched.DayHours
+			if day.Short {
+				day.Hours = math.Max(0, sched.DayHours-shortDayCut)
+			}
+		} else {
+			day.Short = false
+		}
+
+		days = append(days, day)
+	}
+	return days
+}
+
+// regularWorkday reports whether the weekday is worked on the week schedule
+func regularWorkday(weekday time.Weekday, weekDays int) bool {
+	switch weekday {
+	case time.Sunday:
+		return false
+	case time.Saturday:
+		return weekDays >= 6
+	default:
+		return true
+	}
+}
@@ -0,0 +1,238 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/calendar"
+	"srmt-admin/internal/lib/model/hrm/timesheet"
+	"srmt-admin/internal/storage"
+)
+
+// fakeRepo serves the calendar from memory. Holiday and schedule edits are
+// not under test, so those methods panic.
+type fakeRepo struct {
+	holidays   []*timesheet.Holiday
+	exceptions []*calendar.Exception
+	schedules  map[int64]*calendar.Schedule
+	orgs       map[int64]int64
+
+	published int
+}
+
+func (f *fakeRepo) GetHolidaysBetween(_ context.Context, from, to string) ([]*timesheet.Holiday, error) {
+	var res []*timesheet.Holiday
+	for _, h := range f.holidays {
+		if from <= h.Date && h.Date <= to {
+			res = append(res, h)
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeRepo) GetCalendarExceptions(_ context.Context, from, to string) ([]*calendar.Exception, error) {
+	var res []*calendar.Exception
+	for _, e := range f.exceptions {
+		if from <= e.Date && e.Date <= to {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeRepo) GetWorkSchedule(_ context.Context, organizationID int64) (*calendar.Schedule, error) {
+	if s, ok := f.schedules[organizationID]; ok {
+		return s, nil
+	}
+	return nil, storage.ErrWorkScheduleNotFound
+}
+
+func (f *fakeRepo) GetEmployeeOrganizations(context.Context, []int64) (map[int64]int64, error) {
+	return f.orgs, nil
+}
+
+func (f *fakeRepo) GetCalendarYearPublishedAt(context.Context, int) (*time.Time, error) {
+	return nil, nil
+}
+
+func (f *fakeRepo) PublishCalendarYear(context.Context, int, dto.PublishCalendarRequest, int64) error {
+	f.published++
+	return nil
+}
+
+func (f *fakeRepo) GetHolidays(context.Context, int) ([]*timesheet.Holiday, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) CreateHoliday(context.Context, dto.CreateHolidayRequest) (int64, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) DeleteHoliday(context.Context, int64) (string, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetWorkSchedules(context.Context) ([]*calendar.Schedule, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) SetWorkSchedule(context.Context, int64, dto.SetWorkScheduleRequest) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) DeleteWorkSchedule(context.Context, int64) error {
+	panic("not implemented")
+}
+
+func newTestService(repo *fakeRepo) *Service {
+	return NewService(repo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func date(value string) time.Time {
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func intPtr(i int) *int       { return &i }
+func int64Ptr(i int64) *int64 { return &i }
+
+// March 2026: the 8th is a Sunday, the 21st (Navruz) a Saturday
+func marchRepo() *fakeRepo {
+	return &fakeRepo{
+		holidays: []*timesheet.Holiday{
+			{Date: "2026-03-20", Name: "Ramazon hayit"},
+			{Date: "2026-03-21", Name: "Navruz"},
+		},
+		exceptions: []*calendar.Exception{
+			{Date: "2026-03-14", Type: calendar.ExceptionWorking, WeekDays: intPtr(5)},
+			{Date: "2026-03-23", Type: calendar.ExceptionDayOff},
+			{Date: "2026-03-07", Type: calendar.ExceptionShort},
+		},
+		schedules: map[int64]*calendar.Schedule{
+			1: {OrganizationID: int64Ptr(1), WeekDays: 5, DayHours: 8},
+		},
+		orgs: map[int64]int64{10: 1, 11: 2},
+	}
+}
+
+func TestDays_AppliesScheduleHolidaysAndExceptions(t *testing.T) {
+	svc := newTestService(marchRepo())
+
+	cases := []struct {
+		org         *int64
+		date        string
+		working     bool
+		hours       float64
+		holiday     bool
+		transferred bool
+	}{
+		{org: int64Ptr(1), date: "2026-03-02", working: true, hours: 8},                    // Monday
+		{org: int64Ptr(1), date: "2026-03-07", working: false},                             // Saturday off on a five-day week
+		{org: nil, date: "2026-03-07", working: true, hours: 7},                            // shortened Saturday
+		{org: int64Ptr(1), date: "2026-03-14", working: true, hours: 8, transferred: true}, // Saturday worked on a five-day week
+		{org: nil, date: "2026-03-14", working: true, hours: 8},                            // the transfer is not for six-day weeks
+		{org: int64Ptr(1), date: "2026-03-19", working: true, hours: 7},                    // day before a holiday
+		{org: int64Ptr(1), date: "2026-03-20", holiday: true},                              // holiday
+		{org: nil, date: "2026-03-21", holiday: true},                                      // holiday on a Saturday
+		{org: nil, date: "2026-03-23", working: false, transferred: true},                  // bridge day off
+		{org: nil, date: "2026-03-22", working: false},                                     // Sunday
+	}
+	for _, c := range cases {
+		days, err := svc.Days(context.Background(), c.org, date(c.date), date(c.date))
+		if err != nil {
+			t.Fatalf("Days: %v", err)
+		}
+		d := days[0]
+		if d.Working != c.working || d.Hours != c.hours || d.Holiday != c.holiday || d.Transferred != c.transferred {
+			t.Errorf("org %v, %s: got %+v, want working=%v hours=%v holiday=%v transferred=%v",
+				c.org, c.date, d, c.working, c.hours, c.holiday, c.transferred)
+		}
+	}
+}
+
+func TestEmployeeDays_UsesScheduleOfEachOrganization(t *testing.T) {
+	svc := newTestService(marchRepo())
+	from, to := date("2026-03-01"), date("2026-03-31")
+
+	days, err := svc.EmployeeDays(context.Background(), []int64{10, 11, 12}, from, to)
+	if err != nil {
+		t.Fatalf("EmployeeDays: %v", err)
+	}
+
+	count := func(id int64) int {
+		n := 0
+		for _, d := range days[id] {
+			if d.Working {
+				n++
+			}
+		}
+		return n
+	}
+	// Five-day week: 22 weekdays, less the holiday and the bridge day, plus the worked Saturday
+	if got := count(10); got != 21 {
+		t.Errorf("five-day week: %d working days, want 21", got)
+	}
+	// Six-day week: 26 days, less two holidays and the bridge day
+	if got := count(11); got != 23 {
+		t.Errorf("six-day week by default: %d working days, want 23", got)
+	}
+	if got := count(12); got != 23 {
+		t.Errorf("employee of no organization: %d working days, want 23", got)
+	}
+}
+
+func TestGetYear_SummarisesMonths(t *testing.T) {
+	svc := newTestService(marchRepo())
+
+	year, err := svc.GetYear(context.Background(), 2026, int64Ptr(1))
+	if err != nil {
+		t.Fatalf("GetYear: %v", err)
+	}
+	if len(year.Days) != 365 {
+		t.Fatalf("days = %d, want 365", len(year.Days))
+	}
+	m := year.Months[2]
+	if m.WorkDays != 21 || m.ShortDays != 1 || m.DaysOff != 10 || m.WorkHours != 167 {
+		t.Errorf("March = %+v, want 21 work days, 1 short, 10 off, 167 hours", m)
+	}
+}
+
+func TestPublish_ValidatesDaysAndReportsChange(t *testing.T) {
+	repo := &fakeRepo{}
+	svc := newTestService(repo)
+	var changes [][2]string
+	svc.OnChange(func(_ context.Context, from, to string) {
+		changes = append(changes, [2]string{from, to})
+	})
+
+	outside := dto.PublishCalendarRequest{Holidays: []dto.CreateHolidayRequest{{Name: "Yangi yil", Date: "2027-01-01", Type: "national"}}}
+	if err := svc.Publish(context.Background(), 2026, outside, 1); !errors.Is(err, storage.ErrDateOutsideYear) {
+		t.Errorf("holiday of another year: err = %v, want ErrDateOutsideYear", err)
+	}
+
+	duplicate := dto.PublishCalendarRequest{Exceptions: []dto.CalendarExceptionInput{
+		{Date: "2026-03-14", Type: "working", WeekDays: intPtr(5)},
+		{Date: "2026-03-14", Type: "short"},
+		{Date: "2026-03-14", Type: "working", WeekDays: intPtr(5)},
+	}}
+	if err := svc.Publish(context.Background(), 2026, duplicate, 1); !errors.Is(err, storage.ErrCalendarDayDuplicate) {
+		t.Errorf("repeated exception: err = %v, want ErrCalendarDayDuplicate", err)
+	}
+	if repo.published != 0 || len(changes) != 0 {
+		t.Fatal("an invalid calendar was published")
+	}
+
+	valid := dto.PublishCalendarRequest{
+		Holidays:   []dto.CreateHolidayRequest{{Name: "Navruz", Date: "2026-03-21", Type: "national"}},
+		Exceptions: duplicate.Exceptions[:2],
+	}
+	if err := svc.Publish(context.Background(), 2026, valid, 1); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if repo.published != 1 || len(changes) != 1 || changes[0] != [2]string{"2026-01-01", "2026-12-31"} {
+		t.Errorf("published %d times, changes %v, want once for 2026", repo.published, changes)
+	}
+}
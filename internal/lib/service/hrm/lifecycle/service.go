@@ -0,0 +1,455 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/lifecycle"
+	"srmt-admin/internal/lib/model/hrm/personnel"
+	"srmt-admin/internal/lib/model/hrm/recruiting"
+	"srmt-admin/internal/lib/model/hrm/salary"
+	"srmt-admin/internal/storage"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type RepoInterface interface {
+	GetJobOfferByID(ctx context.Context, id int64) (*recruiting.JobOffer, error)
+	GetPersonnelRecordByEmployeeID(ctx context.Context, employeeID int64) (*personnel.Record, error)
+	GetPlacementNames(ctx context.Context, departmentID, positionID int64) (string, string, error)
+
+	CreateHireOrder(ctx context.Context, h *lifecycle.Hire, doc dto.CreateHRDocumentRequest) (int64, error)
+	CreateHROrder(ctx context.Context, o *lifecycle.Order, doc dto.CreateHRDocumentRequest) (int64, error)
+	ApplyTransferOrder(ctx context.Context, id int64) error
+	ApplyDismissalOrder(ctx context.Context, id int64, settlement *salary.Settlement) error
+	CancelHROrder(ctx context.Context, id int64) error
+
+	GetHROrderByID(ctx context.Context, id int64) (*lifecycle.Order, error)
+	GetAllHROrders(ctx context.Context, filters dto.HROrderFilters) ([]*lifecycle.Order, error)
+	GetScheduledHROrders(ctx context.Context, until string) ([]*lifecycle.Order, error)
+}
+
+// Settler works out the final settlement of a dismissed employee
+type Settler interface {
+	SettleFinal(ctx context.Context, employeeID int64, lastDay string, unusedDays float64) (*salary.Settlement, error)
+}
+
+// Vacations tells the vacation days an employee leaves with
+type Vacations interface {
+	UnusedDays(ctx context.Context, employeeID int64, lastDay string) (float64, error)
+}
+
+const (
+	dateLayout = "2006-01-02"
+
+	// applyHour is when orders due are applied, local time: before the
+	// nightly vacation accrual
+	applyHour = 1
+)
+
+type Service struct {
+	repo      RepoInterface
+	settler   Settler
+	vacations Vacations
+	loc       *time.Location
+	now       func() time.Time
+	log       *slog.Logger
+}
+
+func NewService(repo RepoInterface, settler Settler, vacations Vacations, loc *time.Location, log *slog.Logger) *Service {
+	return &Service{repo: repo, settler: settler, vacations: vacations, loc: loc, now: time.Now, log: log}
+}
+
+// Hire turns the candidate of an accepted offer into an employee by a hire
+// order, which takes effect at once
+func (s *Service) Hire(ctx context.Context, req dto.HireRequest, createdBy int64) (*lifecycle.Order, error) {
+	offer, err := s.repo.GetJobOfferByID(ctx, req.OfferID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.Status != "accepted" {
+		return nil, storage.ErrOfferNotAccepted
+	}
+
+	orderDate := dateOr(req.OrderDate, s.today())
+	hireDate := orderDate
+	switch {
+	case req.HireDate != nil:
+		hireDate = *req.HireDate
+	case offer.StartDate != nil:
+		hireDate = *offer.StartDate
+	}
+	if req.ContractEndDate != nil && *req.ContractEndDate < hireDate {
+		return nil, storage.ErrInvalidDateRange
+	}
+
+	h := &lifecycle.Hire{
+		OfferID:         offer.ID,
+		HireDate:        hireDate,
+		TabNumber:       req.TabNumber,
+		ContractType:    req.ContractType,
+		ContractEndDate: req.ContractEndDate,
+		BaseSalary:      offer.SalaryOffered,
+		MentorID:        req.MentorID,
+		OrderDate:       orderDate,
+		Reason:          req.Reason,
+		CreatedBy:       createdBy,
+	}
+	if h.Checklist, err = checklist(hireDate, req.MentorID); err != nil {
+		return nil, err
+	}
+	if req.Login != nil {
+		h.Login = req.Login
+		if h.PasswordHash, err = bcrypt.GenerateFromPassword([]byte(*req.Password), bcrypt.DefaultCost); err != nil {
+			return nil, fmt.Errorf("hash password: %w", err)
+		}
+	}
+
+	department, position, err := s.repo.GetPlacementNames(ctx, offer.DepartmentID, offer.PositionID)
+	if err != nil {
+		return nil, err
+	}
+	content := fmt.Sprintf("Принять %s на должность «%s» (%s) с %s, %s.",
+		offer.CandidateName, position, department, formatDate(hireDate), contractText(req.ContractType, req.ContractEndDate))
+	if offer.SalaryOffered != nil {
+		content += fmt.Sprintf(" Установить оклад %.2f.", *offer.SalaryOffered)
+	}
+	content += reasonText(req.Reason)
+
+	id, err := s.repo.CreateHireOrder(ctx, h, orderDocument("Приказ о приёме на работу", content, req.Signatures))
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetHROrderByID(ctx, id)
+}
+
+// Transfer orders an employee moved to another department or position, or
+// paid under a new salary structure, from the effective date. An order
+// effective today or earlier is applied at once; a later one by the
+// scheduler on its date.
+func (s *Service) Transfer(ctx context.Context, req dto.TransferRequest, createdBy int64) (*lifecycle.Order, error) {
+	rec, err := s.activeRecord(ctx, req.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	if req.EffectiveDate < rec.HireDate {
+		return nil, storage.ErrInvalidDateRange
+	}
+
+	o := &lifecycle.Order{
+		Kind:             lifecycle.KindTransfer,
+		OrderDate:        dateOr(req.OrderDate, s.today()),
+		EffectiveDate:    req.EffectiveDate,
+		EmployeeID:       req.EmployeeID,
+		FromDepartmentID: &rec.DepartmentID,
+		FromPositionID:   &rec.PositionID,
+		Reason:           req.Reason,
+		CreatedBy:        &createdBy,
+	}
+	departmentID, positionID := rec.DepartmentID, rec.PositionID
+	if req.ToDepartmentID != nil && *req.ToDepartmentID != rec.DepartmentID {
+		o.ToDepartmentID = req.ToDepartmentID
+		departmentID = *req.ToDepartmentID
+	}
+	if req.ToPositionID != nil && *req.ToPositionID != rec.PositionID {
+		o.ToPositionID = req.ToPositionID
+		positionID = *req.ToPositionID
+	}
+	if req.Salary != nil {
+		o.Salary = &lifecycle.SalaryChange{
+			BaseSalary:          req.Salary.BaseSalary,
+			RegionalAllowance:   req.Salary.RegionalAllowance,
+			SeniorityAllowance:  req.Salary.SeniorityAllowance,
+			QualificationAllow:  req.Salary.QualificationAllow,
+			HazardAllowance:     req.Salary.HazardAllowance,
+			NightShiftAllowance: req.Salary.NightShiftAllowance,
+		}
+	}
+	if o.ToDepartmentID == nil && o.ToPositionID == nil && o.Salary == nil {
+		return nil, storage.ErrNothingToTransfer
+	}
+
+	var content string
+	if o.ToDepartmentID != nil || o.ToPositionID != nil {
+		department, position, err := s.repo.GetPlacementNames(ctx, departmentID, positionID)
+		if err != nil {
+			return nil, err
+		}
+		content = fmt.Sprintf("Перевести %s (таб. № %s) на должность «%s» (%s) с %s.",
+			rec.EmployeeName, rec.TabNumber, position, department, formatDate(req.EffectiveDate))
+		if o.Salary != nil {
+			content += fmt.Sprintf(" Установить оклад %.2f.", o.Salary.BaseSalary)
+		}
+	} else {
+		content = fmt.Sprintf("Установить %s (таб. № %s) оклад %.2f с %s.",
+			rec.EmployeeName, rec.TabNumber, o.Salary.BaseSalary, formatDate(req.EffectiveDate))
+	}
+	content += reasonText(req.Reason)
+
+	id, err := s.repo.CreateHROrder(ctx, o, orderDocument("Приказ о переводе работника", content, req.Signatures))
+	if err != nil {
+		return nil, err
+	}
+	return s.applyIfDue(ctx, id)
+}
+
+// Dismiss orders an employee dismissed after their last day of work. The
+// order takes effect the day after: the employee loses their access cards
+// and user account, and the final settlement is worked out.
+func (s *Service) Dismiss(ctx context.Context, req dto.DismissalRequest, createdBy int64) (*lifecycle.Order, error) {
+	rec, err := s.activeRecord(ctx, req.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	if req.LastDay < rec.HireDate {
+		return nil, storage.ErrInvalidDateRange
+	}
+
+	o := &lifecycle.Order{
+		Kind:          lifecycle.KindDismissal,
+		OrderDate:     dateOr(req.OrderDate, s.today()),
+		EffectiveDate: req.LastDay,
+		EmployeeID:    req.EmployeeID,
+		Reason:        &req.Reason,
+		CreatedBy:     &createdBy,
+	}
+	content := fmt.Sprintf("Прекратить трудовой договор с %s (таб. № %s, %s, %s). Последний рабочий день — %s.%s",
+		rec.EmployeeName, rec.TabNumber, rec.PositionName, rec.DepartmentName,
+		formatDate(req.LastDay), reasonText(&req.Reason))
+
+	id, err := s.repo.CreateHROrder(ctx, o, orderDocument("Приказ о прекращении трудового договора", content, req.Signatures))
+	if err != nil {
+		return nil, err
+	}
+	return s.applyIfDue(ctx, id)
+}
+
+// Cancel calls off a transfer or dismissal that has not taken effect yet
+func (s *Service) Cancel(ctx context.Context, id int64) error {
+	return s.repo.CancelHROrder(ctx, id)
+}
+
+func (s *Service) GetOrder(ctx context.Context, id int64) (*lifecycle.Order, error) {
+	return s.repo.GetHROrderByID(ctx, id)
+}
+
+func (s *Service) GetOrders(ctx context.Context, filters dto.HROrderFilters) ([]*lifecycle.Order, error) {
+	orders, err := s.repo.GetAllHROrders(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	if orders == nil {
+		orders = []*lifecycle.Order{}
+	}
+	return orders, nil
+}
+
+// ApplyDue applies the scheduled orders that have taken effect by today and
+// returns how many were. A failed order is logged and left for the next run.
+func (s *Service) ApplyDue(ctx context.Context) (int, error) {
+	today := s.today()
+	orders, err := s.repo.GetScheduledHROrders(ctx, today)
+	if err != nil {
+		return 0, fmt.Errorf("get scheduled orders: %w", err)
+	}
+
+	applied := 0
+	for _, o := range orders {
+		if !due(o, today) {
+			continue
+		}
+		if err := s.apply(ctx, o); err != nil {
+			s.log.Error("failed to apply hr order", "error", err, "order_id", o.ID, "kind", o.Kind)
+			continue
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+// StartScheduler applies the orders due once a night. Blocks until ctx is
+// cancelled.
+func (s *Service) StartScheduler(ctx context.Context) {
+	for {
+		now := s.now().In(s.loc)
+		next := time.Date(now.Year(), now.Month(), now.Day(), applyHour, 0, 0, 0, s.loc)
+		if !next.After(now) {
+			next = next.Add(24 * time.Hour)
+		}
+		wait := next.Sub(now)
+
+		s.log.Info("next hr order run scheduled",
+			slog.String("run_at", next.Format(time.RFC3339)),
+			slog.Duration("in", wait))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.log.Info("hr order scheduler stopped")
+			return
+		case <-timer.C:
+			n, err := s.ApplyDue(ctx)
+			if err != nil {
+				s.log.Error("failed to apply hr orders", "error", err)
+				continue
+			}
+			s.log.Info("hr orders applied", "count", n)
+		}
+	}
+}
+
+// applyIfDue applies a new order at once when it is already in effect. A
+// failure is logged and the order waits for the scheduler.
+func (s *Service) applyIfDue(ctx context.Context, id int64) (*lifecycle.Order, error) {
+	o, err := s.repo.GetHROrderByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !due(o, s.today()) {
+		return o, nil
+	}
+	if err := s.apply(ctx, o); err != nil {
+		s.log.Error("failed to apply hr order", "error", err, "order_id", o.ID, "kind", o.Kind)
+		return o, nil
+	}
+	return s.repo.GetHROrderByID(ctx, id)
+}
+
+func (s *Service) apply(ctx context.Context, o *lifecycle.Order) error {
+	switch o.Kind {
+	case lifecycle.KindTransfer:
+		return s.repo.ApplyTransferOrder(ctx, o.ID)
+	case lifecycle.KindDismissal:
+		return s.repo.ApplyDismissalOrder(ctx, o.ID, s.settle(ctx, o))
+	}
+	return fmt.Errorf("order %d of kind %q does not take effect later", o.ID, o.Kind)
+}
+
+// settle works out the final settlement of a dismissal. Without it the
+// employee is still dismissed, since access must not outlast employment;
+// the failure is logged for payroll to settle by hand.
+func (s *Service) settle(ctx context.Context, o *lifecycle.Order) *salary.Settlement {
+	unused, err := s.vacations.UnusedDays(ctx, o.EmployeeID, o.EffectiveDate)
+	if err != nil {
+		s.log.Error("failed to count unused vacation days", "error", err, "order_id", o.ID, "employee_id", o.EmployeeID)
+		return nil
+	}
+	st, err := s.settler.SettleFinal(ctx, o.EmployeeID, o.EffectiveDate, unused)
+	if err != nil {
+		s.log.Error("failed to settle dismissal", "error", err, "order_id", o.ID, "employee_id", o.EmployeeID)
+		return nil
+	}
+	return st
+}
+
+// activeRecord is the personnel record of an employee still on staff
+func (s *Service) activeRecord(ctx context.Context, employeeID int64) (*personnel.Record, error) {
+	rec, err := s.repo.GetPersonnelRecordByEmployeeID(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if rec.Status == "dismissed" {
+		return nil, storage.ErrEmployeeDismissed
+	}
+	return rec, nil
+}
+
+func (s *Service) today() string {
+	now := s.now()
+	if s.loc != nil {
+		now = now.In(s.loc)
+	}
+	return now.Format(dateLayout)
+}
+
+// due tells whether an order has taken effect by today: a transfer on its
+// date, a dismissal the day after the last day of work
+func due(o *lifecycle.Order, today string) bool {
+	switch o.Kind {
+	case lifecycle.KindTransfer:
+		return o.EffectiveDate <= today
+	case lifecycle.KindDismissal:
+		return o.EffectiveDate < today
+	}
+	return false
+}
+
+// onboardingChecklist is what a new employee goes through, with the days
+// from the hire date each item is due in
+var onboardingChecklist = []struct {
+	title string
+	days  int
+}{
+	{"Ознакомление с правилами внутреннего трудового распорядка", 0},
+	{"Вводный инструктаж по охране труда", 0},
+	{"Оформление пропуска", 0},
+	{"Подготовка рабочего места и учётной записи", 1},
+	{"Инструктаж на рабочем месте", 1},
+	{"Ознакомление с должностной инструкцией", 3},
+	{"Стажировка и проверка знаний по охране труда", 14},
+	{"Подведение итогов испытательного срока", 90},
+}
+
+func checklist(hireDate string, mentorID *int64) ([]lifecycle.ChecklistTask, error) {
+	start, err := time.Parse(dateLayout, hireDate)
+	if err != nil {
+		return nil, storage.ErrInvalidDateRange
+	}
+	tasks := make([]lifecycle.ChecklistTask, 0, len(onboardingChecklist))
+	for _, item := range onboardingChecklist {
+		tasks = append(tasks, lifecycle.ChecklistTask{
+			Title:      item.title,
+			AssignedTo: mentorID,
+			DueDate:    start.AddDate(0, 0, item.days).Format(dateLayout),
+		})
+	}
+	return tasks, nil
+}
+
+func orderDocument(title, content string, signatures []dto.SignatureInput) dto.CreateHRDocumentRequest {
+	return dto.CreateHRDocumentRequest{
+		Title:      title,
+		Type:       "order",
+		Category:   "personnel",
+		Content:    &content,
+		Signatures: signatures,
+	}
+}
+
+func contractText(contractType string, endDate *string) string {
+	switch {
+	case contractType == "temporary" && endDate != nil:
+		return "по срочному трудовому договору до " + formatDate(*endDate)
+	case contractType == "temporary":
+		return "по срочному трудовому договору"
+	case contractType == "contract":
+		return "по контракту"
+	default:
+		return "по бессрочному трудовому договору"
+	}
+}
+
+func reasonText(reason *string) string {
+	if reason == nil || *reason == "" {
+		return ""
+	}
+	return " Основание: " + *reason + "."
+}
+
+func formatDate(date string) string {
+	d, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return date
+	}
+	return d.Format("02.01.2006")
+}
+
+func dateOr(date *string, fallback string) string {
+	if date != nil {
+		return *date
+	}
+	return fallback
+}
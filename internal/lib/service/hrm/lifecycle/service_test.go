@@ -0,0 +1,312 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/lifecycle"
+	"srmt-admin/internal/lib/model/hrm/personnel"
+	"srmt-admin/internal/lib/model/hrm/recruiting"
+	"srmt-admin/internal/lib/model/hrm/salary"
+	"srmt-admin/internal/storage"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeRepo keeps orders in memory. Listing and cancelling orders are not
+// under test and panic.
+type fakeRepo struct {
+	offer   *recruiting.JobOffer
+	records map[int64]*personnel.Record
+	orders  map[int64]*lifecycle.Order
+
+	hire        *lifecycle.Hire
+	docs        []dto.CreateHRDocumentRequest
+	settlements map[int64]*salary.Settlement
+}
+
+func (f *fakeRepo) GetJobOfferByID(context.Context, int64) (*recruiting.JobOffer, error) {
+	if f.offer == nil {
+		return nil, storage.ErrOfferNotFound
+	}
+	return f.offer, nil
+}
+
+func (f *fakeRepo) GetPersonnelRecordByEmployeeID(_ context.Context, employeeID int64) (*personnel.Record, error) {
+	rec, ok := f.records[employeeID]
+	if !ok {
+		return nil, storage.ErrPersonnelRecordNotFound
+	}
+	return rec, nil
+}
+
+func (f *fakeRepo) GetPlacementNames(context.Context, int64, int64) (string, string, error) {
+	return "Служба эксплуатации", "Инженер", nil
+}
+
+func (f *fakeRepo) CreateHireOrder(_ context.Context, h *lifecycle.Hire, doc dto.CreateHRDocumentRequest) (int64, error) {
+	f.hire = h
+	f.docs = append(f.docs, doc)
+	return f.add(&lifecycle.Order{Kind: lifecycle.KindHire, Status: lifecycle.StatusApplied, EffectiveDate: h.HireDate}), nil
+}
+
+func (f *fakeRepo) CreateHROrder(_ context.Context, o *lifecycle.Order, doc dto.CreateHRDocumentRequest) (int64, error) {
+	o.Status = lifecycle.StatusScheduled
+	f.docs = append(f.docs, doc)
+	return f.add(o), nil
+}
+
+func (f *fakeRepo) ApplyTransferOrder(_ context.Context, id int64) error {
+	f.orders[id].Status = lifecycle.StatusApplied
+	return nil
+}
+
+func (f *fakeRepo) ApplyDismissalOrder(_ context.Context, id int64, settlement *salary.Settlement) error {
+	f.orders[id].Status = lifecycle.StatusApplied
+	f.settlements[id] = settlement
+	return nil
+}
+
+func (f *fakeRepo) GetHROrderByID(_ context.Context, id int64) (*lifecycle.Order, error) {
+	o, ok := f.orders[id]
+	if !ok {
+		return nil, storage.ErrHROrderNotFound
+	}
+	c := *o
+	return &c, nil
+}
+
+func (f *fakeRepo) GetScheduledHROrders(_ context.Context, until string) ([]*lifecycle.Order, error) {
+	var res []*lifecycle.Order
+	for _, o := range f.orders {
+		if o.Status == lifecycle.StatusScheduled && o.EffectiveDate <= until {
+			res = append(res, o)
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeRepo) add(o *lifecycle.Order) int64 {
+	o.ID = int64(len(f.orders) + 1)
+	f.orders[o.ID] = o
+	return o.ID
+}
+
+func (f *fakeRepo) CancelHROrder(context.Context, int64) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetAllHROrders(context.Context, dto.HROrderFilters) ([]*lifecycle.Order, error) {
+	panic("not implemented")
+}
+
+type fakeSettler struct {
+	err      error
+	lastDay  string
+	unused   float64
+	employee int64
+}
+
+func (f *fakeSettler) SettleFinal(_ context.Context, employeeID int64, lastDay string, unusedDays float64) (*salary.Settlement, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.employee, f.lastDay, f.unused = employeeID, lastDay, unusedDays
+	return &salary.Settlement{EmployeeID: employeeID, LastDay: lastDay, UnusedVacationDays: unusedDays}, nil
+}
+
+type fakeVacations struct{ days float64 }
+
+func (f fakeVacations) UnusedDays(context.Context, int64, string) (float64, error) {
+	return f.days, nil
+}
+
+func newRepo() *fakeRepo {
+	return &fakeRepo{
+		records: map[int64]*personnel.Record{
+			7: {ID: 3, EmployeeID: 7, EmployeeName: "Каримов А.", TabNumber: "1024", HireDate: "2020-02-01",
+				DepartmentID: 1, PositionID: 10, Status: "active"},
+		},
+		orders:      make(map[int64]*lifecycle.Order),
+		settlements: make(map[int64]*salary.Settlement),
+	}
+}
+
+func newTestService(repo *fakeRepo, settler *fakeSettler, today string) *Service {
+	svc := NewService(repo, settler, fakeVacations{days: 12.5}, time.UTC, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	now, err := time.Parse(dateLayout, today)
+	if err != nil {
+		panic(err)
+	}
+	svc.now = func() time.Time { return now.Add(10 * time.Hour) }
+	return svc
+}
+
+func TestHire_SetsUpEmployeeFromAcceptedOffer(t *testing.T) {
+	repo := newRepo()
+	offered, start := 5000000.0, "2026-11-02"
+	repo.offer = &recruiting.JobOffer{ID: 4, CandidateName: "Юсупова Н.", DepartmentID: 1, PositionID: 10,
+		SalaryOffered: &offered, StartDate: &start, Status: "accepted"}
+	svc := newTestService(repo, &fakeSettler{}, "2026-10-20")
+
+	mentor, login, pass := int64(9), "n.yusupova", "s3cret-pass"
+	o, err := svc.Hire(context.Background(), dto.HireRequest{
+		OfferID: 4, TabNumber: "1077", ContractType: "permanent", MentorID: &mentor, Login: &login, Password: &pass,
+	}, 1)
+	if err != nil {
+		t.Fatalf("Hire: %v", err)
+	}
+	if o.Status != lifecycle.StatusApplied {
+		t.Errorf("hire order status = %s, want applied", o.Status)
+	}
+
+	h := repo.hire
+	if h.HireDate != start || h.OrderDate != "2026-10-20" {
+		t.Errorf("hire date %s, order date %s; want the offer's %s and today", h.HireDate, h.OrderDate, start)
+	}
+	if h.BaseSalary == nil || *h.BaseSalary != offered {
+		t.Errorf("base salary = %v, want the offered %v", h.BaseSalary, offered)
+	}
+	if err := bcrypt.CompareHashAndPassword(h.PasswordHash, []byte(pass)); err != nil {
+		t.Errorf("password hash does not match: %v", err)
+	}
+	if len(h.Checklist) == 0 {
+		t.Fatal("no onboarding checklist")
+	}
+	for _, task := range h.Checklist {
+		if task.AssignedTo == nil || *task.AssignedTo != mentor {
+			t.Errorf("task %q not assigned to the mentor", task.Title)
+		}
+		if task.DueDate < start {
+			t.Errorf("task %q due %s, before the hire date", task.Title, task.DueDate)
+		}
+	}
+	if doc := repo.docs[0]; doc.Type != "order" || doc.Category != "personnel" || doc.Content == nil {
+		t.Errorf("order document = %s/%s, want a personnel order with content", doc.Type, doc.Category)
+	}
+}
+
+func TestHire_RequiresAcceptedOffer(t *testing.T) {
+	repo := newRepo()
+	repo.offer = &recruiting.JobOffer{ID: 4, Status: "sent"}
+	svc := newTestService(repo, &fakeSettler{}, "2026-10-20")
+
+	_, err := svc.Hire(context.Background(), dto.HireRequest{OfferID: 4, TabNumber: "1", ContractType: "permanent"}, 1)
+	if !errors.Is(err, storage.ErrOfferNotAccepted) {
+		t.Fatalf("err = %v, want ErrOfferNotAccepted", err)
+	}
+	if repo.hire != nil {
+		t.Error("offer not accepted was hired on")
+	}
+}
+
+func TestTransfer_AppliesOnEffectiveDate(t *testing.T) {
+	repo := newRepo()
+	svc := newTestService(repo, &fakeSettler{}, "2026-10-20")
+	dept := int64(2)
+
+	later, err := svc.Transfer(context.Background(), dto.TransferRequest{EmployeeID: 7, ToDepartmentID: &dept, EffectiveDate: "2026-11-01"}, 1)
+	if err != nil {
+		t.Fatalf("Transfer: %v", err)
+	}
+	if later.Status != lifecycle.StatusScheduled {
+		t.Errorf("future transfer status = %s, want scheduled", later.Status)
+	}
+	if later.ToPositionID != nil || *later.FromDepartmentID != 1 {
+		t.Errorf("transfer from department %v to position %v; want from 1 and the position kept", later.FromDepartmentID, later.ToPositionID)
+	}
+
+	// The scheduler applies it on the date
+	svc = newTestService(repo, &fakeSettler{}, "2026-11-01")
+	n, err := svc.ApplyDue(context.Background())
+	if err != nil {
+		t.Fatalf("ApplyDue: %v", err)
+	}
+	if n != 1 || repo.orders[later.ID].Status != lifecycle.StatusApplied {
+		t.Errorf("applied %d orders, transfer %s; want 1, applied", n, repo.orders[later.ID].Status)
+	}
+}
+
+func TestTransfer_EffectiveTodayAppliesAtOnce(t *testing.T) {
+	repo := newRepo()
+	svc := newTestService(repo, &fakeSettler{}, "2026-10-20")
+
+	o, err := svc.Transfer(context.Background(), dto.TransferRequest{
+		EmployeeID: 7, EffectiveDate: "2026-10-20", Salary: &dto.SalaryChangeInput{BaseSalary: 6000000},
+	}, 1)
+	if err != nil {
+		t.Fatalf("Transfer: %v", err)
+	}
+	if o.Status != lifecycle.StatusApplied || o.Salary == nil || o.Salary.BaseSalary != 6000000 {
+		t.Errorf("salary change = %s, %+v; want applied with base 6000000", o.Status, o.Salary)
+	}
+}
+
+func TestTransfer_RejectsNoChange(t *testing.T) {
+	repo := newRepo()
+	svc := newTestService(repo, &fakeSettler{}, "2026-10-20")
+	same := int64(1)
+
+	_, err := svc.Transfer(context.Background(), dto.TransferRequest{EmployeeID: 7, ToDepartmentID: &same, EffectiveDate: "2026-11-01"}, 1)
+	if !errors.Is(err, storage.ErrNothingToTransfer) {
+		t.Fatalf("err = %v, want ErrNothingToTransfer", err)
+	}
+}
+
+func TestDismiss_TakesEffectAfterLastDayWithSettlement(t *testing.T) {
+	repo := newRepo()
+	settler := &fakeSettler{}
+	svc := newTestService(repo, settler, "2026-10-20")
+
+	o, err := svc.Dismiss(context.Background(), dto.DismissalRequest{EmployeeID: 7, LastDay: "2026-10-20", Reason: "по собственному желанию"}, 1)
+	if err != nil {
+		t.Fatalf("Dismiss: %v", err)
+	}
+	if o.Status != lifecycle.StatusScheduled {
+		t.Fatalf("dismissal on its last day = %s, want scheduled", o.Status)
+	}
+
+	svc = newTestService(repo, settler, "2026-10-21")
+	if _, err := svc.ApplyDue(context.Background()); err != nil {
+		t.Fatalf("ApplyDue: %v", err)
+	}
+	if repo.orders[o.ID].Status != lifecycle.StatusApplied {
+		t.Fatal("dismissal not applied the day after the last day")
+	}
+	st := repo.settlements[o.ID]
+	if st == nil || settler.lastDay != "2026-10-20" || settler.unused != 12.5 || settler.employee != 7 {
+		t.Errorf("settled %d to %s with %v unused days; want employee 7 to 2026-10-20 with 12.5",
+			settler.employee, settler.lastDay, settler.unused)
+	}
+}
+
+func TestDismiss_AppliedEvenWhenSettlementFails(t *testing.T) {
+	repo := newRepo()
+	svc := newTestService(repo, &fakeSettler{err: errors.New("no timesheet")}, "2026-10-21")
+
+	o, err := svc.Dismiss(context.Background(), dto.DismissalRequest{EmployeeID: 7, LastDay: "2026-10-20", Reason: "сокращение"}, 1)
+	if err != nil {
+		t.Fatalf("Dismiss: %v", err)
+	}
+	if o.Status != lifecycle.StatusApplied {
+		t.Errorf("past dismissal = %s, want applied at once", o.Status)
+	}
+	if st, ok := repo.settlements[o.ID]; !ok || st != nil {
+		t.Errorf("settlement = %v, want the dismissal applied without one", st)
+	}
+}
+
+func TestDismiss_RefusesDismissedEmployee(t *testing.T) {
+	repo := newRepo()
+	repo.records[7].Status = "dismissed"
+	svc := newTestService(repo, &fakeSettler{}, "2026-10-20")
+
+	_, err := svc.Dismiss(context.Background(), dto.DismissalRequest{EmployeeID: 7, LastDay: "2026-10-30", Reason: "x"}, 1)
+	if !errors.Is(err, storage.ErrEmployeeDismissed) {
+		t.Fatalf("err = %v, want ErrEmployeeDismissed", err)
+	}
+}
@@ -41,6 +41,17 @@ type RepoInterface interface {
 	GetAllInterviews(ctx context.Context, filters dto.InterviewFilters) ([]*recruiting.Interview, error)
 	UpdateInterview(ctx context.Context, id int64, req dto.UpdateInterviewRequest) error
 	GetInterviewsByCandidate(ctx context.Context, candidateID int64) ([]*recruiting.Interview, error)
+
+	// Offers
+	CreateJobOffer(ctx context.Context, req dto.CreateJobOfferRequest) (int64, error)
+	GetJobOfferByID(ctx context.Context, id int64) (*recruiting.JobOffer, error)
+	GetAllJobOffers(ctx context.Context, filters dto.JobOfferFilters) ([]*recruiting.JobOffer, error)
+	UpdateJobOffer(ctx context.Context, id int64, req dto.UpdateJobOfferRequest) error
+	UpdateJobOfferStatus(ctx context.Context, id int64, status string) error
+
+	// Onboardings
+	GetAllOnboardings(ctx context.Context, filters dto.OnboardingFilters) ([]*recruiting.Onboarding, error)
+	UpdateOnboardingTask(ctx context.Context, id int64, req dto.UpdateOnboardingTaskRequest) error
 }
 
 type Service struct {
@@ -265,3 +276,96 @@ func (s *Service) GetCandidateInterviews(ctx context.Context, candidateID int64)
 	}
 	return interviews, nil
 }
+
+// ==================== Offers ====================
+
+// offerTransitions lists where an offer may go from each status. An
+// accepted offer is final: it is turned into an employee by the hire order.
+var offerTransitions = map[string][]string{
+	"draft": {"sent", "withdrawn"},
+	"sent":  {"accepted", "rejected", "expired", "withdrawn"},
+}
+
+func (s *Service) CreateOffer(ctx context.Context, req dto.CreateJobOfferRequest) (int64, error) {
+	return s.repo.CreateJobOffer(ctx, req)
+}
+
+func (s *Service) GetAllOffers(ctx context.Context, filters dto.JobOfferFilters) ([]*recruiting.JobOffer, error) {
+	offers, err := s.repo.GetAllJobOffers(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	if offers == nil {
+		offers = []*recruiting.JobOffer{}
+	}
+	return offers, nil
+}
+
+// UpdateOffer changes the terms of an offer that has not been answered yet
+// and moves it along its statuses. Sending the offer puts the candidate at
+// the offer stage; accepting it makes them ready for hiring.
+func (s *Service) UpdateOffer(ctx context.Context, id int64, req dto.UpdateJobOfferRequest) error {
+	offer, err := s.repo.GetJobOfferByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	termsChanged := req.SalaryOffered != nil || req.StartDate != nil || req.Notes != nil
+	if termsChanged && offer.Status != "draft" && offer.Status != "sent" {
+		return storage.ErrInvalidStatus
+	}
+
+	if req.Status != nil && *req.Status != offer.Status {
+		allowed := false
+		for _, next := range offerTransitions[offer.Status] {
+			if next == *req.Status {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return storage.ErrInvalidStatus
+		}
+	}
+
+	if termsChanged {
+		if err := s.repo.UpdateJobOffer(ctx, id, req); err != nil {
+			return err
+		}
+	}
+
+	if req.Status == nil || *req.Status == offer.Status {
+		return nil
+	}
+	if err := s.repo.UpdateJobOfferStatus(ctx, id, *req.Status); err != nil {
+		return err
+	}
+
+	switch *req.Status {
+	case "sent":
+		err = s.repo.UpdateCandidateStatus(ctx, offer.CandidateID, "offer", "offer")
+	case "accepted":
+		err = s.repo.UpdateCandidateStatus(ctx, offer.CandidateID, "offer", "hiring")
+	}
+	if err != nil {
+		return fmt.Errorf("update candidate: %w", err)
+	}
+	return nil
+}
+
+// ==================== Onboardings ====================
+
+func (s *Service) GetAllOnboardings(ctx context.Context, filters dto.OnboardingFilters) ([]*recruiting.Onboarding, error) {
+	onboardings, err := s.repo.GetAllOnboardings(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	if onboardings == nil {
+		onboardings = []*recruiting.Onboarding{}
+	}
+	return onboardings, nil
+}
+
+func (s *Service) UpdateOnboardingTask(ctx context.Context, id int64, req dto.UpdateOnboardingTaskRequest) error {
+	return s.repo.UpdateOnboardingTask(ctx, id, req)
+}
@@ -0,0 +1,240 @@
+package salary
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/salary"
+	"srmt-admin/internal/lib/model/hrm/timesheet"
+	"srmt-admin/internal/storage"
+)
+
+// payrollRepo keeps salaries in memory for the payroll and settlement tests.
+// Unlike mockRepo it holds state across calls; methods neither test calls
+// panic.
+type payrollRepo struct {
+	employees []int64
+	structure *salary.SalaryStructure
+	rates     []*salary.PayrollRate
+	salaries  map[int64]*salary.Salary
+	bonuses   map[int64][]*salary.Bonus
+	saved     map[int64]salary.Salary
+}
+
+func (f *payrollRepo) GetActiveEmployeesByDepartment(context.Context, *int64) ([]int64, error) {
+	return f.employees, nil
+}
+
+func (f *payrollRepo) SalaryExists(_ context.Context, employeeID int64, year, month int) (bool, error) {
+	for _, s := range f.salaries {
+		if s.EmployeeID == employeeID && s.PeriodYear == year && s.PeriodMonth == month {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *payrollRepo) CreateSalary(_ context.Context, req dto.CreateSalaryRequest) (int64, error) {
+	id := int64(len(f.salaries) + 1)
+	f.salaries[id] = &salary.Salary{ID: id, EmployeeID: req.EmployeeID, PeriodYear: req.PeriodYear, PeriodMonth: req.PeriodMonth, Status: "draft"}
+	return id, nil
+}
+
+func (f *payrollRepo) GetSalaryByID(_ context.Context, id int64) (*salary.Salary, error) {
+	s, ok := f.salaries[id]
+	if !ok {
+		return nil, storage.ErrSalaryNotFound
+	}
+	c := *s
+	return &c, nil
+}
+
+func (f *payrollRepo) GetAllSalaries(_ context.Context, filters dto.SalaryFilters) ([]*salary.Salary, error) {
+	var res []*salary.Salary
+	for _, s := range f.salaries {
+		if filters.EmployeeID == nil || *filters.EmployeeID == s.EmployeeID {
+			c := *s
+			res = append(res, &c)
+		}
+	}
+	return res, nil
+}
+
+func (f *payrollRepo) GetActiveSalaryStructure(context.Context, int64, string) (*salary.SalaryStructure, error) {
+	return f.structure, nil
+}
+
+func (f *payrollRepo) GetEffectivePayrollRates(context.Context, string) ([]*salary.PayrollRate, error) {
+	return f.rates, nil
+}
+
+func (f *payrollRepo) GetBonuses(_ context.Context, salaryID int64) ([]*salary.Bonus, error) {
+	return f.bonuses[salaryID], nil
+}
+
+func (f *payrollRepo) GetDeductions(context.Context, int64) ([]*salary.Deduction, error) {
+	return nil, nil
+}
+
+func (f *payrollRepo) UpdateSalaryCalculation(_ context.Context, sal *salary.Salary) error {
+	if f.saved == nil {
+		f.saved = make(map[int64]salary.Salary)
+	}
+	f.saved[sal.ID] = *sal
+	return nil
+}
+
+func (f *payrollRepo) UpdateSalary(context.Context, int64, dto.UpdateSalaryRequest) error {
+	panic("not implemented")
+}
+func (f *payrollRepo) DeleteSalary(context.Context, int64) error {
+	panic("not implemented")
+}
+func (f *payrollRepo) ApproveSalary(context.Context, int64, int64) error {
+	panic("not implemented")
+}
+func (f *payrollRepo) MarkSalaryPaid(context.Context, int64) error {
+	panic("not implemented")
+}
+func (f *payrollRepo) GetSalaryStructureByEmployee(context.Context, int64) ([]*salary.SalaryStructure, error) {
+	panic("not implemented")
+}
+func (f *payrollRepo) GetAllSalaryStructures(context.Context) ([]*salary.SalaryStructure, error) {
+	panic("not implemented")
+}
+func (f *payrollRepo) CreateDeductions(context.Context, int64, []dto.DeductionInput) error {
+	panic("not implemented")
+}
+func (f *payrollRepo) GetAllBonuses(context.Context) ([]*salary.Bonus, error) {
+	panic("not implemented")
+}
+func (f *payrollRepo) GetAllDeductions(context.Context) ([]*salary.Deduction, error) {
+	panic("not implemented")
+}
+func (f *payrollRepo) GetPayrollRates(context.Context) ([]*salary.PayrollRate, error) {
+	panic("not implemented")
+}
+func (f *payrollRepo) CreatePayrollRate(context.Context, dto.CreatePayrollRateRequest, int64) (int64, error) {
+	panic("not implemented")
+}
+func (f *payrollRepo) DeletePayrollRate(context.Context, int64) error {
+	panic("not implemented")
+}
+func (f *payrollRepo) GetBankAccounts(context.Context, []int64) ([]*salary.BankAccount, error) {
+	panic("not implemented")
+}
+func (f *payrollRepo) SetBankAccount(context.Context, int64, dto.SetBankAccountRequest) error {
+	panic("not implemented")
+}
+func (f *payrollRepo) DeleteBankAccount(context.Context, int64) error {
+	panic("not implemented")
+}
+
+// fakeTimesheet returns a fixed monthly summary per employee
+type fakeTimesheet struct {
+	summaries map[int64]timesheet.Summary
+}
+
+func (f *fakeTimesheet) GetTimesheet(_ context.Context, filters dto.TimesheetFilters) ([]*timesheet.EmployeeTimesheet, error) {
+	var res []*timesheet.EmployeeTimesheet
+	for id, sum := range f.summaries {
+		if filters.EmployeeID == nil || *filters.EmployeeID == id {
+			res = append(res, &timesheet.EmployeeTimesheet{EmployeeID: id, Summary: sum})
+		}
+	}
+	return res, nil
+}
+
+func newPayrollService(repo *payrollRepo, ts *fakeTimesheet) *Service {
+	return NewService(repo, nil, ts, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// A 24-day month of 192 hours: 20 days present, one remote and one on a
+// business trip, two on sick leave, with 4 hours of overtime and 8 at night
+var marchSummary = timesheet.Summary{
+	TotalWorkDays:    24,
+	NormHours:        192,
+	PresentDays:      20,
+	RemoteDays:       1,
+	BusinessTripDays: 1,
+	SickDays:         2,
+	OvertimeHours:    4,
+	NightHours:       8,
+}
+
+func TestBulkCalculate_PaysTimesheetAttendanceAtEffectiveRates(t *testing.T) {
+	repo := &payrollRepo{
+		employees: []int64{5},
+		structure: &salary.SalaryStructure{BaseSalary: 2400000},
+		rates:     []*salary.PayrollRate{{Code: salary.RateNDFL, Rate: 0.1}},
+		salaries:  make(map[int64]*salary.Salary),
+	}
+	svc := newPayrollService(repo, &fakeTimesheet{summaries: map[int64]timesheet.Summary{5: marchSummary}})
+
+	n, err := svc.BulkCalculate(context.Background(), dto.BulkCalculateRequest{PeriodYear: 2026, PeriodMonth: 3})
+	if err != nil {
+		t.Fatalf("BulkCalculate: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("calculated %d salaries, want 1", n)
+	}
+
+	got := repo.saved[1]
+	if !got.FromTimesheet || got.WorkDays != 24 || got.ActualDays != 22 || got.SickDays != 2 {
+		t.Errorf("attendance = %d/%d days, %d sick, from timesheet %v; want 22/24, 2 sick, true",
+			got.ActualDays, got.WorkDays, got.SickDays, got.FromTimesheet)
+	}
+
+	// Hourly rate 12 500, daily rate 100 000
+	checks := []struct {
+		name      string
+		got, want float64
+	}{
+		{"base", got.BaseSalary, 2200000},
+		{"overtime", got.OvertimeAmount, 75000},
+		{"night", got.NightAmount, 50000},
+		{"sick pay", got.SickAmount, 120000},
+		{"gross", got.GrossSalary, 2445000},
+		{"ndfl at the effective 10%", got.NDFL, 244500},
+		{"pension at the default 3%", got.PensionFund, 73350},
+		{"net", got.NetSalary, 2078250},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestTimesheetChanged_RecalculatesUnapprovedTimesheetSalaries(t *testing.T) {
+	repo := &payrollRepo{
+		structure: &salary.SalaryStructure{BaseSalary: 2400000},
+		salaries: map[int64]*salary.Salary{
+			1: {ID: 1, EmployeeID: 5, PeriodYear: 2026, PeriodMonth: 3, Status: "calculated", FromTimesheet: true},
+			2: {ID: 2, EmployeeID: 5, PeriodYear: 2026, PeriodMonth: 3, Status: "approved", FromTimesheet: true},
+			3: {ID: 3, EmployeeID: 5, PeriodYear: 2026, PeriodMonth: 3, Status: "calculated"},
+		},
+		bonuses: map[int64][]*salary.Bonus{1: {{SalaryID: 1, Amount: 100000}}},
+	}
+	svc := newPayrollService(repo, &fakeTimesheet{summaries: map[int64]timesheet.Summary{5: marchSummary}})
+
+	svc.TimesheetChanged(context.Background(), 5, "2026-03-10", "2026-03-10")
+
+	if _, ok := repo.saved[2]; ok {
+		t.Error("approved salary was recalculated")
+	}
+	if _, ok := repo.saved[3]; ok {
+		t.Error("salary calculated by hand was recalculated")
+	}
+	got, ok := repo.saved[1]
+	if !ok {
+		t.Fatal("draft timesheet salary was not recalculated")
+	}
+	if got.BonusAmount != 100000 || got.GrossSalary != 2545000 || !got.FromTimesheet {
+		t.Errorf("recalculated salary = bonus %v, gross %v, from timesheet %v; want 100000, 2545000, true",
+			got.BonusAmount, got.GrossSalary, got.FromTimesheet)
+	}
+}
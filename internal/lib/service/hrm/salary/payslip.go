@@ -0,0 +1,192 @@
+package salary
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"srmt-admin/internal/lib/model/hrm/salary"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Payslip formats
+const (
+	FormatXLSX = "xlsx"
+	FormatPDF  = "pdf"
+)
+
+const (
+	payslipSheet = "Расчётный листок"
+
+	// convertTimeout bounds a LibreOffice PDF conversion
+	convertTimeout = time.Minute
+)
+
+// deductionLabels names the deduction types on a payslip
+var deductionLabels = map[string]string{
+	"tax":       "Налог",
+	"pension":   "Пенсионный взнос",
+	"insurance": "Страхование",
+	"loan":      "Погашение займа",
+	"alimony":   "Алименты",
+	"fine":      "Штраф",
+	"advance":   "Аванс",
+	"other":     "Прочее",
+}
+
+// Payslip renders the payslip of a calculated salary as XLSX or PDF
+func (s *Service) Payslip(ctx context.Context, sal *salary.Salary, format string) ([]byte, error) {
+	f, err := s.payslipWorkbook(ctx, sal)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if format == FormatPDF {
+		return convertToPDF(ctx, f)
+	}
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("write payslip: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *Service) payslipWorkbook(ctx context.Context, sal *salary.Salary) (*excelize.File, error) {
+	rates, err := s.EffectiveRates(ctx, fmt.Sprintf("%d-%02d-01", sal.PeriodYear, sal.PeriodMonth))
+	if err != nil {
+		return nil, err
+	}
+	deductions, err := s.repo.GetDeductions(ctx, sal.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get deductions: %w", err)
+	}
+
+	f := excelize.NewFile()
+	f.SetSheetName("Sheet1", payslipSheet)
+	sheet := payslipSheet
+
+	titleStyle, _ := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 14}})
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#DAEEF3"}, Pattern: 1},
+	})
+	totalStyle, _ := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}, NumFmt: 4})
+	amountStyle, _ := f.NewStyle(&excelize.Style{NumFmt: 4})
+
+	f.SetColWidth(sheet, "A", "A", 36)
+	f.SetColWidth(sheet, "B", "B", 18)
+
+	f.SetCellValue(sheet, "A1", fmt.Sprintf("Расчётный листок за %02d.%d", sal.PeriodMonth, sal.PeriodYear))
+	f.SetCellStyle(sheet, "A1", "A1", titleStyle)
+
+	row := 3
+	line := func(label string, value interface{}, style int) {
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), label)
+		f.SetCellValue(sheet, fmt.Sprintf("B%d", row), value)
+		if style != 0 {
+			f.SetCellStyle(sheet, fmt.Sprintf("B%d", row), fmt.Sprintf("B%d", row), style)
+		}
+		row++
+	}
+	header := func(label string) {
+		row++
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), label)
+		f.SetCellValue(sheet, fmt.Sprintf("B%d", row), "Сумма")
+		f.SetCellStyle(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("B%d", row), headerStyle)
+		row++
+	}
+	amount := func(label string, value float64) {
+		if value != 0 {
+			line(label, value, amountStyle)
+		}
+	}
+
+	line("Сотрудник", sal.EmployeeName, 0)
+	line("Подразделение", sal.Department, 0)
+	line("Должность", sal.Position, 0)
+	line("Отработано дней", fmt.Sprintf("%d из %d", sal.ActualDays, sal.WorkDays), 0)
+	if sal.SickDays > 0 {
+		line("Дней болезни", sal.SickDays, 0)
+	}
+	if sal.OvertimeHours > 0 {
+		line("Сверхурочных часов", sal.OvertimeHours, 0)
+	}
+	if sal.NightHours > 0 {
+		line("Ночных часов", sal.NightHours, 0)
+	}
+
+	header("Начислено")
+	line("Оклад", sal.BaseSalary, amountStyle)
+	amount("Региональная надбавка", sal.RegionalAllowance)
+	amount("Надбавка за стаж", sal.SeniorityAllowance)
+	amount("Надбавка за квалификацию", sal.QualificationAllow)
+	amount("Надбавка за вредность", sal.HazardAllowance)
+	amount("Надбавка за ночную смену", sal.NightShiftAllowance)
+	amount(fmt.Sprintf("Сверхурочные (×%s)", formatRate(rates.Overtime)), sal.OvertimeAmount)
+	amount(fmt.Sprintf("Ночные часы (+%s%%)", formatRate(rates.Night*100)), sal.NightAmount)
+	amount(fmt.Sprintf("Больничный (%s%%)", formatRate(rates.SickPay*100)), sal.SickAmount)
+	amount("Премии", sal.BonusAmount)
+	line("Итого начислено", sal.GrossSalary, totalStyle)
+
+	header("Удержано")
+	amount(fmt.Sprintf("НДФЛ (%s%%)", formatRate(rates.NDFL*100)), sal.NDFL)
+	amount(fmt.Sprintf("Социальный налог (%s%%)", formatRate(rates.Social*100)), sal.SocialTax)
+	amount(fmt.Sprintf("Пенсионный фонд (%s%%)", formatRate(rates.Pension*100)), sal.PensionFund)
+	amount(fmt.Sprintf("Медицинское страхование (%s%%)", formatRate(rates.Health*100)), sal.HealthInsurance)
+	amount(fmt.Sprintf("Профсоюзный взнос (%s%%)", formatRate(rates.TradeUnion*100)), sal.TradeUnion)
+	for _, d := range deductions {
+		label := deductionLabels[d.DeductionType]
+		if label == "" {
+			label = d.DeductionType
+		}
+		if d.Description != nil && *d.Description != "" {
+			label += ": " + *d.Description
+		}
+		amount(label, d.Amount)
+	}
+	line("Итого удержано", sal.TotalDeductions, totalStyle)
+
+	row++
+	line("К выплате", sal.NetSalary, totalStyle)
+	f.SetCellStyle(sheet, fmt.Sprintf("A%d", row-1), fmt.Sprintf("A%d", row-1), titleStyle)
+
+	return f, nil
+}
+
+// formatRate prints a rate without trailing zeros
+func formatRate(v float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", v), "0"), ".")
+}
+
+// convertToPDF converts a workbook with headless LibreOffice, as the report
+// exports do
+func convertToPDF(ctx context.Context, f *excelize.File) ([]byte, error) {
+	tempDir, err := os.MkdirTemp("", "payslip-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	xlsxPath := filepath.Join(tempDir, "payslip.xlsx")
+	if err := f.SaveAs(xlsxPath); err != nil {
+		return nil, fmt.Errorf("failed to save xlsx: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, convertTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "soffice", "--headless", "--convert-to", "pdf", "--outdir", tempDir, xlsxPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to convert xlsx to pdf: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	pdf, err := os.ReadFile(filepath.Join(tempDir, "payslip.pdf"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pdf: %w", err)
+	}
+	return pdf, nil
+}
@@ -0,0 +1,66 @@
+package salary
+
+import (
+	"context"
+	"fmt"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/salary"
+)
+
+// EffectiveRates returns the rates in effect on a date (YYYY-MM-DD): the
+// latest payroll rate of each code, and the default for codes without one
+func (s *Service) EffectiveRates(ctx context.Context, onDate string) (salary.TaxRates, error) {
+	rates, err := s.repo.GetEffectivePayrollRates(ctx, onDate)
+	if err != nil {
+		return salary.TaxRates{}, fmt.Errorf("get payroll rates: %w", err)
+	}
+	return salary.DefaultTaxRates.Apply(rates), nil
+}
+
+// GetRates returns the payroll rates in effect on onDate, or every rate with
+// its history when onDate is empty
+func (s *Service) GetRates(ctx context.Context, onDate string) ([]*salary.PayrollRate, error) {
+	var (
+		rates []*salary.PayrollRate
+		err   error
+	)
+	if onDate != "" {
+		rates, err = s.repo.GetEffectivePayrollRates(ctx, onDate)
+	} else {
+		rates, err = s.repo.GetPayrollRates(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if rates == nil {
+		rates = []*salary.PayrollRate{}
+	}
+	return rates, nil
+}
+
+func (s *Service) CreateRate(ctx context.Context, req dto.CreatePayrollRateRequest, createdBy int64) (int64, error) {
+	return s.repo.CreatePayrollRate(ctx, req, createdBy)
+}
+
+func (s *Service) DeleteRate(ctx context.Context, id int64) error {
+	return s.repo.DeletePayrollRate(ctx, id)
+}
+
+func (s *Service) GetBankAccounts(ctx context.Context) ([]*salary.BankAccount, error) {
+	accounts, err := s.repo.GetBankAccounts(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accounts == nil {
+		accounts = []*salary.BankAccount{}
+	}
+	return accounts, nil
+}
+
+func (s *Service) SetBankAccount(ctx context.Context, employeeID int64, req dto.SetBankAccountRequest) error {
+	return s.repo.SetBankAccount(ctx, employeeID, req)
+}
+
+func (s *Service) DeleteBankAccount(ctx context.Context, employeeID int64) error {
+	return s.repo.DeleteBankAccount(ctx, employeeID)
+}
@@ -0,0 +1,99 @@
+package salary
+
+import (
+	"context"
+	"fmt"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/salary"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const registerSheet = "Ведомость"
+
+// PaymentRegister builds the bank payment register of a month's approved
+// salaries: one line per employee with the account the net salary goes to.
+// Employees without a bank account are listed with the account left blank.
+func (s *Service) PaymentRegister(ctx context.Context, year, month int, departmentID *int64) (*excelize.File, error) {
+	status := "approved"
+	salaries, err := s.repo.GetAllSalaries(ctx, dto.SalaryFilters{
+		PeriodYear:   &year,
+		PeriodMonth:  &month,
+		DepartmentID: departmentID,
+		Status:       &status,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get salaries: %w", err)
+	}
+
+	ids := make([]int64, 0, len(salaries))
+	for _, sal := range salaries {
+		ids = append(ids, sal.EmployeeID)
+	}
+	accounts := make(map[int64]*salary.BankAccount, len(ids))
+	if len(ids) > 0 {
+		list, err := s.repo.GetBankAccounts(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("get bank accounts: %w", err)
+		}
+		for _, a := range list {
+			accounts[a.EmployeeID] = a
+		}
+	}
+
+	f := excelize.NewFile()
+	f.SetSheetName("Sheet1", registerSheet)
+	sheet := registerSheet
+
+	titleStyle, _ := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 14}})
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#DAEEF3"}, Pattern: 1},
+	})
+	amountStyle, _ := f.NewStyle(&excelize.Style{NumFmt: 4})
+	totalStyle, _ := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}, NumFmt: 4})
+
+	f.SetCellValue(sheet, "A1", fmt.Sprintf("Ведомость на перечисление заработной платы за %02d.%d", month, year))
+	f.SetCellStyle(sheet, "A1", "A1", titleStyle)
+
+	headers := []string{"№", "Сотрудник", "Подразделение", "Банк", "Код банка", "Счёт", "Сумма"}
+	for i, h := range headers {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 3)
+		f.SetCellValue(sheet, cell, h)
+	}
+	f.SetCellStyle(sheet, "A3", "G3", headerStyle)
+	f.SetColWidth(sheet, "B", "C", 32)
+	f.SetColWidth(sheet, "D", "D", 28)
+	f.SetColWidth(sheet, "E", "E", 10)
+	f.SetColWidth(sheet, "F", "F", 24)
+	f.SetColWidth(sheet, "G", "G", 16)
+
+	var total float64
+	missing := 0
+	row := 4
+	for i, sal := range salaries {
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), i+1)
+		f.SetCellValue(sheet, fmt.Sprintf("B%d", row), sal.EmployeeName)
+		f.SetCellValue(sheet, fmt.Sprintf("C%d", row), sal.Department)
+		if a, ok := accounts[sal.EmployeeID]; ok {
+			f.SetCellValue(sheet, fmt.Sprintf("D%d", row), a.BankName)
+			f.SetCellValue(sheet, fmt.Sprintf("E%d", row), a.BankCode)
+			f.SetCellValue(sheet, fmt.Sprintf("F%d", row), a.AccountNumber)
+		} else {
+			missing++
+		}
+		f.SetCellValue(sheet, fmt.Sprintf("G%d", row), sal.NetSalary)
+		f.SetCellStyle(sheet, fmt.Sprintf("G%d", row), fmt.Sprintf("G%d", row), amountStyle)
+		total += sal.NetSalary
+		row++
+	}
+
+	f.SetCellValue(sheet, fmt.Sprintf("F%d", row), "Итого")
+	f.SetCellValue(sheet, fmt.Sprintf("G%d", row), round2(total))
+	f.SetCellStyle(sheet, fmt.Sprintf("F%d", row), fmt.Sprintf("G%d", row), totalStyle)
+	if missing > 0 {
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", row+2), fmt.Sprintf("Без банковского счёта: %d", missing))
+	}
+
+	return f, nil
+}
@@ -6,7 +6,10 @@ import (
 	"log/slog"
 	"math"
 	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/calendar"
 	"srmt-admin/internal/lib/model/hrm/salary"
+	"srmt-admin/internal/lib/model/hrm/timesheet"
+	"srmt-admin/internal/lib/service/notification"
 	"srmt-admin/internal/storage"
 	"time"
 )
@@ -40,15 +43,40 @@ type RepoInterface interface {
 	// Helpers
 	GetActiveEmployeesByDepartment(ctx context.Context, departmentID *int64) ([]int64, error)
 	SalaryExists(ctx context.Context, employeeID int64, year, month int) (bool, error)
+
+	// Payroll rates
+	GetPayrollRates(ctx context.Context) ([]*salary.PayrollRate, error)
+	GetEffectivePayrollRates(ctx context.Context, onDate string) ([]*salary.PayrollRate, error)
+	CreatePayrollRate(ctx context.Context, req dto.CreatePayrollRateRequest, createdBy int64) (int64, error)
+	DeletePayrollRate(ctx context.Context, id int64) error
+
+	// Bank accounts
+	GetBankAccounts(ctx context.Context, employeeIDs []int64) ([]*salary.BankAccount, error)
+	SetBankAccount(ctx context.Context, employeeID int64, req dto.SetBankAccountRequest) error
+	DeleteBankAccount(ctx context.Context, employeeID int64) error
 }
 
+// Notifier tells employees their salary was paid
+type Notifier interface {
+	Notify(ctx context.Context, e notification.Event) error
+}
+
+// Timesheet gives employees' monthly attendance
+type Timesheet interface {
+	GetTimesheet(ctx context.Context, filters dto.TimesheetFilters) ([]*timesheet.EmployeeTimesheet, error)
+}
+
+const dateLayout = "2006-01-02"
+
 type Service struct {
-	repo RepoInterface
-	log  *slog.Logger
+	repo      RepoInterface
+	notifier  Notifier
+	timesheet Timesheet
+	log       *slog.Logger
 }
 
-func NewService(repo RepoInterface, log *slog.Logger) *Service {
-	return &Service{repo: repo, log: log}
+func NewService(repo RepoInterface, notifier Notifier, timesheet Timesheet, log *slog.Logger) *Service {
+	return &Service{repo: repo, notifier: notifier, timesheet: timesheet, log: log}
 }
 
 func (s *Service) Create(ctx context.Context, req dto.CreateSalaryRequest) (int64, error) {
@@ -96,74 +124,25 @@ func (s *Service) Calculate(ctx context.Context, id int64, req dto.CalculateSala
 		return nil, storage.ErrInvalidStatus
 	}
 
-	// Get salary structure effective for the period
-	periodDate := fmt.Sprintf("%d-%02d-01", sal.PeriodYear, sal.PeriodMonth)
-	structure, err := s.repo.GetActiveSalaryStructure(ctx, sal.EmployeeID, periodDate)
-	if err != nil {
-		return nil, fmt.Errorf("get salary structure: %w", err)
-	}
-
-	// Proportional ratio
-	ratio := 1.0
-	if req.WorkDays > 0 {
-		ratio = float64(req.ActualDays) / float64(req.WorkDays)
-	}
-
-	// Proportional allowances
-	sal.BaseSalary = round2(structure.BaseSalary * ratio)
-	sal.RegionalAllowance = round2(structure.RegionalAllowance * ratio)
-	sal.SeniorityAllowance = round2(structure.SeniorityAllowance * ratio)
-	sal.QualificationAllow = round2(structure.QualificationAllow * ratio)
-	sal.HazardAllowance = round2(structure.HazardAllowance * ratio)
-	sal.NightShiftAllowance = round2(structure.NightShiftAllowance * ratio)
-
-	// Overtime: hourlyRate = base_salary / (work_days * 8) * 1.5 * overtime_hours
-	sal.OvertimeAmount = 0
-	if req.WorkDays > 0 && req.OvertimeHours > 0 {
-		hourlyRate := structure.BaseSalary / (float64(req.WorkDays) * 8)
-		sal.OvertimeAmount = round2(hourlyRate * 1.5 * req.OvertimeHours)
-	}
-
-	// Bonuses
-	var bonusTotal float64
+	var bonusTotal, deductionTotal float64
 	for _, b := range req.Bonuses {
 		bonusTotal += b.Amount
 	}
-	sal.BonusAmount = round2(bonusTotal)
-
-	// Gross salary
-	sal.GrossSalary = round2(
-		sal.BaseSalary + sal.RegionalAllowance + sal.SeniorityAllowance +
-			sal.QualificationAllow + sal.HazardAllowance + sal.NightShiftAllowance +
-			sal.OvertimeAmount + sal.BonusAmount,
-	)
-
-	// Taxes (Uzbekistan rates)
-	taxes := salary.DefaultTaxRates
-	sal.NDFL = round2(sal.GrossSalary * taxes.NDFL)
-	sal.SocialTax = round2(sal.GrossSalary * taxes.Social)
-	sal.PensionFund = round2(sal.GrossSalary * taxes.Pension)
-	sal.HealthInsurance = round2(sal.GrossSalary * taxes.Health)
-	sal.TradeUnion = round2(sal.GrossSalary * taxes.TradeUnion)
-
-	taxDeductions := sal.NDFL + sal.SocialTax + sal.PensionFund + sal.HealthInsurance + sal.TradeUnion
-
-	// Additional deductions
-	var extraDeductions float64
 	for _, d := range req.Deductions {
-		extraDeductions += d.Amount
+		deductionTotal += d.Amount
 	}
 
-	sal.TotalDeductions = round2(taxDeductions + extraDeductions)
-	sal.NetSalary = round2(sal.GrossSalary - sal.TotalDeductions)
-
-	sal.WorkDays = req.WorkDays
-	sal.ActualDays = req.ActualDays
-	sal.OvertimeHours = req.OvertimeHours
-
-	// Save calculation
-	if err := s.repo.UpdateSalaryCalculation(ctx, sal); err != nil {
-		return nil, fmt.Errorf("save calculation: %w", err)
+	att := attendance{
+		WorkDays:      req.WorkDays,
+		ActualDays:    req.ActualDays,
+		NormHours:     float64(req.WorkDays) * calendar.DefaultDayHours,
+		OvertimeHours: req.OvertimeHours,
+		NightHours:    req.NightHours,
+		SickDays:      req.SickDays,
+	}
+	sal.FromTimesheet = false
+	if err := s.calculate(ctx, sal, att, bonusTotal, deductionTotal); err != nil {
+		return nil, err
 	}
 
 	// Save bonuses and deductions
@@ -181,16 +160,28 @@ func (s *Service) Calculate(ctx context.Context, id int64, req dto.CalculateSala
 	return sal, nil
 }
 
-// BulkCalculate creates and calculates salary for all active employees in a department.
+// BulkCalculate creates and calculates salary for all active employees in a
+// department from their timesheets of the month.
 func (s *Service) BulkCalculate(ctx context.Context, req dto.BulkCalculateRequest) (int, error) {
 	employees, err := s.repo.GetActiveEmployeesByDepartment(ctx, req.DepartmentID)
 	if err != nil {
 		return 0, fmt.Errorf("get employees: %w", err)
 	}
 
-	workDays := calculateWorkDays(req.PeriodYear, req.PeriodMonth)
-	calculated := 0
+	sheets, err := s.timesheet.GetTimesheet(ctx, dto.TimesheetFilters{
+		Year:         req.PeriodYear,
+		Month:        req.PeriodMonth,
+		DepartmentID: req.DepartmentID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("get timesheets: %w", err)
+	}
+	summaries := make(map[int64]timesheet.Summary, len(sheets))
+	for _, sh := range sheets {
+		summaries[sh.EmployeeID] = sh.Summary
+	}
 
+	calculated := 0
 	for _, empID := range employees {
 		// Skip if salary already exists
 		exists, err := s.repo.SalaryExists(ctx, empID, req.PeriodYear, req.PeriodMonth)
@@ -202,6 +193,12 @@ func (s *Service) BulkCalculate(ctx context.Context, req dto.BulkCalculateReques
 			continue
 		}
 
+		summary, ok := summaries[empID]
+		if !ok {
+			s.log.Warn("no timesheet for employee", "employee_id", empID)
+			continue
+		}
+
 		// Create draft
 		createReq := dto.CreateSalaryRequest{
 			EmployeeID:  empID,
@@ -214,12 +211,13 @@ func (s *Service) BulkCalculate(ctx context.Context, req dto.BulkCalculateReques
 			continue
 		}
 
-		// Calculate with full attendance
-		calcReq := dto.CalculateSalaryRequest{
-			WorkDays:   workDays,
-			ActualDays: workDays,
+		sal, err := s.repo.GetSalaryByID(ctx, salaryID)
+		if err != nil {
+			s.log.Error("failed to get created salary", "error", err, "salary_id", salaryID)
+			continue
 		}
-		if _, err := s.Calculate(ctx, salaryID, calcReq); err != nil {
+		sal.FromTimesheet = true
+		if err := s.calculate(ctx, sal, attendanceOf(summary), 0, 0); err != nil {
 			s.log.Error("failed to calculate salary", "error", err, "employee_id", empID, "salary_id", salaryID)
 			continue
 		}
@@ -230,6 +228,178 @@ func (s *Service) BulkCalculate(ctx context.Context, req dto.BulkCalculateReques
 	return calculated, nil
 }
 
+// TimesheetChanged recalculates the salaries calculated from the employee's
+// timesheet between from and to (YYYY-MM-DD) that are not approved yet.
+// Stored bonuses and deductions are kept. A failure is logged; the timesheet
+// change stands.
+func (s *Service) TimesheetChanged(ctx context.Context, employeeID int64, from, to string) {
+	start, err := time.Parse(dateLayout, from)
+	if err != nil {
+		s.log.Error("invalid timesheet change", "error", err, "from", from)
+		return
+	}
+	end, err := time.Parse(dateLayout, to)
+	if err != nil {
+		s.log.Error("invalid timesheet change", "error", err, "to", to)
+		return
+	}
+
+	for m := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC); !m.After(end); m = m.AddDate(0, 1, 0) {
+		year, month := m.Year(), int(m.Month())
+		salaries, err := s.repo.GetAllSalaries(ctx, dto.SalaryFilters{EmployeeID: &employeeID, PeriodYear: &year, PeriodMonth: &month})
+		if err != nil {
+			s.log.Error("failed to get salaries to recalculate", "error", err, "employee_id", employeeID)
+			return
+		}
+		for _, sal := range salaries {
+			if !sal.FromTimesheet || (sal.Status != "draft" && sal.Status != "calculated") {
+				continue
+			}
+			if err := s.recalculate(ctx, sal); err != nil {
+				s.log.Error("failed to recalculate salary", "error", err, "salary_id", sal.ID)
+			}
+		}
+	}
+}
+
+func (s *Service) recalculate(ctx context.Context, sal *salary.Salary) error {
+	sheets, err := s.timesheet.GetTimesheet(ctx, dto.TimesheetFilters{
+		Year:       sal.PeriodYear,
+		Month:      sal.PeriodMonth,
+		EmployeeID: &sal.EmployeeID,
+	})
+	if err != nil {
+		return fmt.Errorf("get timesheet: %w", err)
+	}
+	if len(sheets) == 0 {
+		return nil
+	}
+
+	bonuses, err := s.repo.GetBonuses(ctx, sal.ID)
+	if err != nil {
+		return fmt.Errorf("get bonuses: %w", err)
+	}
+	deductions, err := s.repo.GetDeductions(ctx, sal.ID)
+	if err != nil {
+		return fmt.Errorf("get deductions: %w", err)
+	}
+	var bonusTotal, deductionTotal float64
+	for _, b := range bonuses {
+		bonusTotal += b.Amount
+	}
+	for _, d := range deductions {
+		deductionTotal += d.Amount
+	}
+
+	return s.calculate(ctx, sal, attendanceOf(sheets[0].Summary), bonusTotal, deductionTotal)
+}
+
+// calculate works out the salary under the structure and rates in effect for
+// its period and saves it
+func (s *Service) calculate(ctx context.Context, sal *salary.Salary, att attendance, bonuses, deductions float64) error {
+	periodDate := fmt.Sprintf("%d-%02d-01", sal.PeriodYear, sal.PeriodMonth)
+	structure, err := s.repo.GetActiveSalaryStructure(ctx, sal.EmployeeID, periodDate)
+	if err != nil {
+		return fmt.Errorf("get salary structure: %w", err)
+	}
+	rates, err := s.EffectiveRates(ctx, periodDate)
+	if err != nil {
+		return err
+	}
+
+	compute(sal, structure, att, bonuses, deductions, rates)
+
+	if err := s.repo.UpdateSalaryCalculation(ctx, sal); err != nil {
+		return fmt.Errorf("save calculation: %w", err)
+	}
+	sal.Status = "calculated"
+	return nil
+}
+
+// attendance is what a month's salary is paid for
+type attendance struct {
+	WorkDays      int
+	ActualDays    int
+	NormHours     float64
+	OvertimeHours float64
+	NightHours    float64
+	SickDays      int
+}
+
+// attendanceOf reads the attendance off a month's timesheet. Days worked
+// are days present, remote or on a business trip.
+func attendanceOf(sum timesheet.Summary) attendance {
+	actual := sum.PresentDays + sum.RemoteDays + sum.BusinessTripDays
+	if actual > sum.TotalWorkDays {
+		actual = sum.TotalWorkDays
+	}
+	return attendance{
+		WorkDays:      sum.TotalWorkDays,
+		ActualDays:    actual,
+		NormHours:     sum.NormHours,
+		OvertimeHours: sum.OvertimeHours,
+		NightHours:    sum.NightHours,
+		SickDays:      sum.SickDays,
+	}
+}
+
+// compute fills in the amounts of sal. Allowances are paid in proportion to
+// the days worked; overtime and night hours at the hourly rate of the base
+// salary over the month's norm hours, and sick days at a share of its daily
+// rate.
+func compute(sal *salary.Salary, structure *salary.SalaryStructure, att attendance, bonuses, deductions float64, rates salary.TaxRates) {
+	// Proportional ratio
+	ratio := 1.0
+	if att.WorkDays > 0 {
+		ratio = float64(att.ActualDays) / float64(att.WorkDays)
+	}
+
+	// Proportional allowances
+	sal.BaseSalary = round2(structure.BaseSalary * ratio)
+	sal.RegionalAllowance = round2(structure.RegionalAllowance * ratio)
+	sal.SeniorityAllowance = round2(structure.SeniorityAllowance * ratio)
+	sal.QualificationAllow = round2(structure.QualificationAllow * ratio)
+	sal.HazardAllowance = round2(structure.HazardAllowance * ratio)
+	sal.NightShiftAllowance = round2(structure.NightShiftAllowance * ratio)
+
+	var hourlyRate, dailyRate float64
+	if att.NormHours > 0 {
+		hourlyRate = structure.BaseSalary / att.NormHours
+	}
+	if att.WorkDays > 0 {
+		dailyRate = structure.BaseSalary / float64(att.WorkDays)
+	}
+	sal.OvertimeAmount = round2(hourlyRate * rates.Overtime * att.OvertimeHours)
+	sal.NightAmount = round2(hourlyRate * rates.Night * att.NightHours)
+	sal.SickAmount = round2(dailyRate * rates.SickPay * float64(att.SickDays))
+	sal.BonusAmount = round2(bonuses)
+
+	// Gross salary
+	sal.GrossSalary = round2(
+		sal.BaseSalary + sal.RegionalAllowance + sal.SeniorityAllowance +
+			sal.QualificationAllow + sal.HazardAllowance + sal.NightShiftAllowance +
+			sal.OvertimeAmount + sal.NightAmount + sal.SickAmount + sal.BonusAmount,
+	)
+
+	// Taxes and contributions
+	sal.NDFL = round2(sal.GrossSalary * rates.NDFL)
+	sal.SocialTax = round2(sal.GrossSalary * rates.Social)
+	sal.PensionFund = round2(sal.GrossSalary * rates.Pension)
+	sal.HealthInsurance = round2(sal.GrossSalary * rates.Health)
+	sal.TradeUnion = round2(sal.GrossSalary * rates.TradeUnion)
+
+	taxDeductions := sal.NDFL + sal.SocialTax + sal.PensionFund + sal.HealthInsurance + sal.TradeUnion
+
+	sal.TotalDeductions = round2(taxDeductions + deductions)
+	sal.NetSalary = round2(sal.GrossSalary - sal.TotalDeductions)
+
+	sal.WorkDays = att.WorkDays
+	sal.ActualDays = att.ActualDays
+	sal.OvertimeHours = att.OvertimeHours
+	sal.NightHours = att.NightHours
+	sal.SickDays = att.SickDays
+}
+
 func (s *Service) Approve(ctx context.Context, id int64, approvedBy int64) error {
 	sal, err := s.repo.GetSalaryByID(ctx, id)
 	if err != nil {
@@ -249,7 +419,22 @@ func (s *Service) MarkPaid(ctx context.Context, id int64) error {
 	if sal.Status != "approved" {
 		return storage.ErrInvalidStatus
 	}
-	return s.repo.MarkSalaryPaid(ctx, id)
+	if err := s.repo.MarkSalaryPaid(ctx, id); err != nil {
+		return err
+	}
+
+	if s.notifier != nil {
+		err := s.notifier.Notify(ctx, notification.Event{
+			Type:     notification.SalaryPaid,
+			Contacts: []int64{sal.EmployeeID},
+			Message:  fmt.Sprintf("Зарплата за %02d.%d выплачена: %.2f", sal.PeriodMonth, sal.PeriodYear, sal.NetSalary),
+			Link:     "/my-salary",
+		})
+		if err != nil {
+			s.log.Error("failed to notify about paid salary", "error", err, "salary_id", id)
+		}
+	}
+	return nil
 }
 
 func (s *Service) GetStructure(ctx context.Context, employeeID int64) ([]*salary.SalaryStructure, error) {
@@ -318,19 +503,6 @@ func (s *Service) GetAllDeductions(ctx context.Context) ([]*salary.Deduction, er
 	return deductions, nil
 }
 
-// calculateWorkDays counts Mon-Sat days in a month (Uzbekistan work week: Mon-Sat, Sunday off).
-func calculateWorkDays(year, month int) int {
-	firstDay := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
-	lastDay := firstDay.AddDate(0, 1, -1)
-	days := 0
-	for d := firstDay; !d.After(lastDay); d = d.AddDate(0, 0, 1) {
-		if d.Weekday() != time.Sunday {
-			days++
-		}
-	}
-	return days
-}
-
 func round2(v float64) float64 {
 	return math.Round(v*100) / 100
 }
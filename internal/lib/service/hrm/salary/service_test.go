@@ -89,10 +89,31 @@ func (m *mockRepo) GetActiveEmployeesByDepartment(context.Context, *int64) ([]in
 func (m *mockRepo) SalaryExists(context.Context, int64, int, int) (bool, error) {
 	panic("not implemented")
 }
+func (m *mockRepo) GetPayrollRates(context.Context) ([]*salary.PayrollRate, error) {
+	panic("not implemented")
+}
+func (m *mockRepo) GetEffectivePayrollRates(context.Context, string) ([]*salary.PayrollRate, error) {
+	panic("not implemented")
+}
+func (m *mockRepo) CreatePayrollRate(context.Context, dto.CreatePayrollRateRequest, int64) (int64, error) {
+	panic("not implemented")
+}
+func (m *mockRepo) DeletePayrollRate(context.Context, int64) error {
+	panic("not implemented")
+}
+func (m *mockRepo) GetBankAccounts(context.Context, []int64) ([]*salary.BankAccount, error) {
+	panic("not implemented")
+}
+func (m *mockRepo) SetBankAccount(context.Context, int64, dto.SetBankAccountRequest) error {
+	panic("not implemented")
+}
+func (m *mockRepo) DeleteBankAccount(context.Context, int64) error {
+	panic("not implemented")
+}
 
 func newTestService(repo *mockRepo) *Service {
 	log := slog.New(slog.NewTextHandler(io.Discard, nil))
-	return NewService(repo, log)
+	return NewService(repo, nil, nil, log)
 }
 
 func TestGetAll(t *testing.T) {
@@ -0,0 +1,148 @@
+package salary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/salary"
+	"srmt-admin/internal/storage"
+	"time"
+)
+
+const (
+	// averageMonthDays divides average monthly earnings into daily ones: the
+	// working days of an average month of six-day weeks, which vacation days
+	// are counted in
+	averageMonthDays = 25.4
+
+	// earningsMonths is how far back average earnings are taken
+	earningsMonths = 12
+
+	compensationNote = "Компенсация за неиспользованный отпуск"
+)
+
+// SettleFinal works out the final settlement of an employee whose last day
+// of work is lastDay (YYYY-MM-DD): the salary of that month, calculated from
+// the timesheet, with compensation for unusedDays of vacation added as a
+// bonus. A salary already approved or paid is left alone and the
+// compensation is only reported. Running it again does not add the
+// compensation twice.
+func (s *Service) SettleFinal(ctx context.Context, employeeID int64, lastDay string, unusedDays float64) (*salary.Settlement, error) {
+	day, err := time.Parse(dateLayout, lastDay)
+	if err != nil {
+		return nil, fmt.Errorf("invalid last day: %w", err)
+	}
+	year, month := day.Year(), int(day.Month())
+
+	salaries, err := s.repo.GetAllSalaries(ctx, dto.SalaryFilters{EmployeeID: &employeeID})
+	if err != nil {
+		return nil, fmt.Errorf("get salaries: %w", err)
+	}
+
+	daily, err := s.averageDailyEarnings(ctx, employeeID, day, salaries)
+	if err != nil {
+		return nil, err
+	}
+	st := &salary.Settlement{
+		EmployeeID:           employeeID,
+		LastDay:              lastDay,
+		PeriodYear:           year,
+		PeriodMonth:          month,
+		AverageDailyEarnings: daily,
+	}
+	if unusedDays > 0 {
+		st.UnusedVacationDays = round2(unusedDays)
+		st.VacationCompensation = round2(daily * unusedDays)
+	}
+
+	var sal *salary.Salary
+	for _, other := range salaries {
+		if other.PeriodYear == year && other.PeriodMonth == month {
+			sal = other
+			break
+		}
+	}
+	if sal == nil {
+		id, err := s.repo.CreateSalary(ctx, dto.CreateSalaryRequest{EmployeeID: employeeID, PeriodMonth: month, PeriodYear: year})
+		if err != nil {
+			return nil, fmt.Errorf("create final salary: %w", err)
+		}
+		if sal, err = s.repo.GetSalaryByID(ctx, id); err != nil {
+			return nil, fmt.Errorf("get final salary: %w", err)
+		}
+	}
+	st.SalaryID = &sal.ID
+
+	if sal.Status != "draft" && sal.Status != "calculated" {
+		s.log.Warn("final salary already approved, vacation compensation to be paid separately",
+			"employee_id", employeeID, "salary_id", sal.ID, "compensation", st.VacationCompensation)
+		st.GrossSalary, st.NetSalary = sal.GrossSalary, sal.NetSalary
+		return st, nil
+	}
+
+	if st.VacationCompensation > 0 {
+		bonuses, err := s.repo.GetBonuses(ctx, sal.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get bonuses: %w", err)
+		}
+		added := false
+		for _, b := range bonuses {
+			if b.Description != nil && *b.Description == compensationNote {
+				added = true
+				break
+			}
+		}
+		if !added {
+			note := compensationNote
+			err := s.repo.CreateBonuses(ctx, sal.ID, []dto.BonusInput{{Type: "other", Amount: st.VacationCompensation, Description: &note}})
+			if err != nil {
+				return nil, fmt.Errorf("add vacation compensation: %w", err)
+			}
+		}
+	}
+
+	sal.FromTimesheet = true
+	if err := s.recalculate(ctx, sal); err != nil {
+		return nil, fmt.Errorf("calculate final salary: %w", err)
+	}
+	st.GrossSalary, st.NetSalary = sal.GrossSalary, sal.NetSalary
+	return st, nil
+}
+
+// averageDailyEarnings is the average monthly gross of the salaries
+// calculated for the months before the one of day, up to earningsMonths
+// back, per averageMonthDays. Without any, the salary structure in effect on
+// day stands in for the month.
+func (s *Service) averageDailyEarnings(ctx context.Context, employeeID int64, day time.Time, salaries []*salary.Salary) (float64, error) {
+	end := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, -earningsMonths, 0)
+
+	var gross float64
+	months := 0
+	for _, other := range salaries {
+		if other.Status == "draft" {
+			continue
+		}
+		period := time.Date(other.PeriodYear, time.Month(other.PeriodMonth), 1, 0, 0, 0, 0, time.UTC)
+		if period.Before(start) || !period.Before(end) {
+			continue
+		}
+		gross += other.GrossSalary
+		months++
+	}
+	if months > 0 {
+		return round2(gross / float64(months) / averageMonthDays), nil
+	}
+
+	structure, err := s.repo.GetActiveSalaryStructure(ctx, employeeID, day.Format(dateLayout))
+	if err != nil {
+		if errors.Is(err, storage.ErrSalaryStructureNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("get salary structure: %w", err)
+	}
+	monthly := structure.BaseSalary + structure.RegionalAllowance + structure.SeniorityAllowance +
+		structure.QualificationAllow + structure.HazardAllowance + structure.NightShiftAllowance
+	return round2(monthly / averageMonthDays), nil
+}
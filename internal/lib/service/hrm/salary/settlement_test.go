@@ -0,0 +1,99 @@
+package salary
+
+import (
+	"context"
+	"testing"
+
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/salary"
+	"srmt-admin/internal/lib/model/hrm/timesheet"
+)
+
+func (f *payrollRepo) CreateBonuses(_ context.Context, salaryID int64, bonuses []dto.BonusInput) error {
+	if f.bonuses == nil {
+		f.bonuses = make(map[int64][]*salary.Bonus)
+	}
+	for _, b := range bonuses {
+		f.bonuses[salaryID] = append(f.bonuses[salaryID], &salary.Bonus{
+			SalaryID: salaryID, BonusType: b.Type, Amount: b.Amount, Description: b.Description,
+		})
+	}
+	return nil
+}
+
+func TestSettleFinal_PaysUnusedVacationAtAverageEarnings(t *testing.T) {
+	repo := &payrollRepo{
+		structure: &salary.SalaryStructure{BaseSalary: 2400000},
+		salaries: map[int64]*salary.Salary{
+			// Two months before the last one count; a draft and a month over
+			// a year back do not
+			1: {ID: 1, EmployeeID: 5, PeriodYear: 2026, PeriodMonth: 1, Status: "paid", GrossSalary: 2540000},
+			2: {ID: 2, EmployeeID: 5, PeriodYear: 2026, PeriodMonth: 2, Status: "approved", GrossSalary: 2540000},
+			3: {ID: 3, EmployeeID: 5, PeriodYear: 2025, PeriodMonth: 2, Status: "paid", GrossSalary: 9000000},
+			4: {ID: 4, EmployeeID: 5, PeriodYear: 2025, PeriodMonth: 12, Status: "draft", GrossSalary: 9000000},
+		},
+	}
+	svc := newPayrollService(repo, &fakeTimesheet{summaries: map[int64]timesheet.Summary{5: marchSummary}})
+
+	st, err := svc.SettleFinal(context.Background(), 5, "2026-03-31", 10.5)
+	if err != nil {
+		t.Fatalf("SettleFinal: %v", err)
+	}
+
+	// 2 540 000 a month over 25.4 days
+	if st.AverageDailyEarnings != 100000 {
+		t.Errorf("average daily earnings = %v, want 100000", st.AverageDailyEarnings)
+	}
+	if st.VacationCompensation != 1050000 {
+		t.Errorf("compensation = %v, want 1050000", st.VacationCompensation)
+	}
+	if st.SalaryID == nil || *st.SalaryID != 5 {
+		t.Fatalf("final salary = %v, want a new salary 5", st.SalaryID)
+	}
+
+	got, ok := repo.saved[5]
+	if !ok {
+		t.Fatal("final salary was not calculated")
+	}
+	// The March salary of the payroll test with the compensation on top
+	if got.BonusAmount != 1050000 || got.GrossSalary != 3495000 {
+		t.Errorf("final salary = bonus %v, gross %v; want 1050000, 3495000", got.BonusAmount, got.GrossSalary)
+	}
+	if st.GrossSalary != got.GrossSalary || st.NetSalary != got.NetSalary {
+		t.Errorf("settlement reports gross %v net %v, salary has %v %v", st.GrossSalary, st.NetSalary, got.GrossSalary, got.NetSalary)
+	}
+
+	// A second run finds the salary and the compensation already there
+	repo.salaries[5].Status = "calculated"
+	if _, err := svc.SettleFinal(context.Background(), 5, "2026-03-31", 10.5); err != nil {
+		t.Fatalf("SettleFinal again: %v", err)
+	}
+	if n := len(repo.bonuses[5]); n != 1 {
+		t.Errorf("compensation added %d times, want once", n)
+	}
+}
+
+func TestSettleFinal_FallsBackToStructureWithoutPastSalaries(t *testing.T) {
+	repo := &payrollRepo{
+		structure: &salary.SalaryStructure{BaseSalary: 2032000, RegionalAllowance: 508000},
+		salaries: map[int64]*salary.Salary{
+			1: {ID: 1, EmployeeID: 5, PeriodYear: 2026, PeriodMonth: 3, Status: "approved", GrossSalary: 2000000, NetSalary: 1700000},
+		},
+	}
+	svc := newPayrollService(repo, &fakeTimesheet{})
+
+	st, err := svc.SettleFinal(context.Background(), 5, "2026-03-15", 2)
+	if err != nil {
+		t.Fatalf("SettleFinal: %v", err)
+	}
+	if st.AverageDailyEarnings != 100000 || st.VacationCompensation != 200000 {
+		t.Errorf("daily %v, compensation %v; want 100000, 200000", st.AverageDailyEarnings, st.VacationCompensation)
+	}
+	// The approved salary is not touched
+	if len(repo.bonuses[1]) != 0 || len(repo.saved) != 0 {
+		t.Error("approved final salary was changed")
+	}
+	if st.GrossSalary != 2000000 || st.NetSalary != 1700000 {
+		t.Errorf("settlement gross %v net %v, want the approved 2000000 1700000", st.GrossSalary, st.NetSalary)
+	}
+}
@@ -0,0 +1,56 @@
+package timesheet
+
+import (
+	"context"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/timesheet"
+	"srmt-admin/internal/storage"
+)
+
+// GetAbsences returns sick leaves and business trips
+func (s *Service) GetAbsences(ctx context.Context, filters dto.AbsenceFilters) ([]*timesheet.Absence, error) {
+	absences, err := s.repo.GetAbsences(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	if absences == nil {
+		absences = []*timesheet.Absence{}
+	}
+	return absences, nil
+}
+
+// CreateAbsence records a sick leave or business trip and refills the
+// employee's timesheet for its days
+func (s *Service) CreateAbsence(ctx context.Context, req dto.CreateAbsenceRequest, createdBy int64) (int64, error) {
+	if req.EndDate < req.StartDate {
+		return 0, storage.ErrInvalidDateRange
+	}
+
+	id, err := s.repo.CreateAbsence(ctx, req, createdBy)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.RecalculateTimesheet(ctx, req.EmployeeID, req.StartDate, req.EndDate); err != nil {
+		s.log.Error("failed to recalculate timesheet on absence create", "error", err, "absence_id", id)
+	}
+	return id, nil
+}
+
+// DeleteAbsence deletes a sick leave or business trip and refills the
+// employee's timesheet for its days
+func (s *Service) DeleteAbsence(ctx context.Context, id int64) error {
+	a, err := s.repo.GetAbsenceByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteAbsence(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.RecalculateTimesheet(ctx, a.EmployeeID, a.StartDate, a.EndDate); err != nil {
+		s.log.Error("failed to recalculate timesheet on absence delete", "error", err, "absence_id", id)
+	}
+	return nil
+}
@@ -0,0 +1,427 @@
+package timesheet
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/calendar"
+	"srmt-admin/internal/lib/model/hrm/timesheet"
+	"time"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	// autofillHour is the local hour of the nightly refill that picks up the
+	// access-control events of the day before.
+	autofillHour = 1
+
+	// Night work is paid extra between nightStartHour and nightEndHour local
+	// time.
+	nightStartHour = 22
+	nightEndHour   = 6
+)
+
+// vacationStatuses maps a vacation type to its timesheet status
+var vacationStatuses = map[string]string{
+	"maternity": "maternity",
+	"study":     "study_leave",
+}
+
+// sources is everything the generator knows about a range of days
+type sources struct {
+	entries   map[int64]map[string]*timesheet.Day
+	vacations map[int64][]timesheet.Period
+	absences  map[int64][]timesheet.Period
+	presence  map[int64]map[string]timesheet.Presence
+	tracked   map[int64]bool
+	calendar  map[int64]map[string]calendar.Day
+}
+
+// Generate fills the month's timesheets of the matching employees from
+// approved vacations, absences, the production calendar and the access-control
+// logs. Manual entries are kept as they are.
+func (s *Service) Generate(ctx context.Context, req dto.GenerateTimesheetRequest) (*timesheet.GenerateResult, error) {
+	employees, err := s.repo.GetEmployeesForTimesheet(ctx, dto.TimesheetFilters{
+		Year:         req.Year,
+		Month:        req.Month,
+		DepartmentID: req.DepartmentID,
+		EmployeeID:   req.EmployeeID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get employees: %w", err)
+	}
+
+	ids := make([]int64, 0, len(employees))
+	for _, e := range employees {
+		ids = append(ids, e.EmployeeID)
+	}
+
+	from := time.Date(req.Year, time.Month(req.Month), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, -1)
+
+	res, err := s.fill(ctx, ids, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("timesheet generated",
+		slog.Int("year", req.Year),
+		slog.Int("month", req.Month),
+		slog.Int("employees", res.Employees),
+		slog.Int("written", res.Written),
+		slog.Int("manual", res.Manual))
+	return res, nil
+}
+
+// RecalculateTimesheet refills an employee's timesheet between from and to
+// (YYYY-MM-DD) after one of its sources changed.
+func (s *Service) RecalculateTimesheet(ctx context.Context, employeeID int64, from, to string) error {
+	start, end, err := parseRange(from, to)
+	if err != nil {
+		return err
+	}
+
+	employees, err := s.repo.GetEmployeesForTimesheet(ctx, dto.TimesheetFilters{EmployeeID: &employeeID})
+	if err != nil {
+		return fmt.Errorf("get employees: %w", err)
+	}
+	if len(employees) == 0 {
+		return nil
+	}
+
+	_, err = s.fill(ctx, []int64{employeeID}, start, end)
+	return err
+}
+
+// CalendarChanged refills every timesheet between from and to after the
+// production calendar changed. Days after the current month are left alone:
+// their entries are filled when their month comes.
+func (s *Service) CalendarChanged(ctx context.Context, from, to string) {
+	now := s.now().In(s.loc)
+	if last := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.UTC).Format(dateLayout); to > last {
+		to = last
+	}
+	if from > to {
+		return
+	}
+	s.recalculateAll(ctx, from, to)
+}
+
+// recalculateAll refills every timesheet between from and to. A failure is
+// logged; the change that caused it stands.
+func (s *Service) recalculateAll(ctx context.Context, from, to string) {
+	start, end, err := parseRange(from, to)
+	if err == nil {
+		var employees []*timesheet.EmployeeInfo
+		employees, err = s.repo.GetEmployeesForTimesheet(ctx, dto.TimesheetFilters{})
+		if err == nil {
+			ids := make([]int64, 0, len(employees))
+			for _, e := range employees {
+				ids = append(ids, e.EmployeeID)
+			}
+			_, err = s.fill(ctx, ids, start, end)
+		}
+	}
+	if err != nil {
+		s.log.Error("failed to recalculate timesheets", "error", err, "from", from, "to", to)
+	}
+}
+
+// StartScheduler refills the current month, and the previous one on the
+// first of the month, once a night so that yesterday's access-control events
+// land in the timesheet. Blocks until ctx is cancelled.
+func (s *Service) StartScheduler(ctx context.Context) {
+	for {
+		now := time.Now().In(s.loc)
+		next := time.Date(now.Year(), now.Month(), now.Day(), autofillHour, 0, 0, 0, s.loc)
+		if !next.After(now) {
+			next = next.Add(24 * time.Hour)
+		}
+		wait := next.Sub(now)
+
+		s.log.Info("next timesheet refill scheduled",
+			slog.String("run_at", next.Format(time.RFC3339)),
+			slog.Duration("in", wait))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.log.Info("timesheet scheduler stopped")
+			return
+		case <-timer.C:
+			yesterday := next.AddDate(0, 0, -1)
+			first := time.Date(yesterday.Year(), yesterday.Month(), 1, 0, 0, 0, 0, time.UTC)
+			last := time.Date(next.Year(), next.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+			s.recalculateAll(ctx, first.Format(dateLayout), last.Format(dateLayout))
+		}
+	}
+}
+
+// fill writes the auto entries of the employees between from and to
+func (s *Service) fill(ctx context.Context, employeeIDs []int64, from, to time.Time) (*timesheet.GenerateResult, error) {
+	res := &timesheet.GenerateResult{Employees: len(employeeIDs)}
+	if len(employeeIDs) == 0 {
+		return res, nil
+	}
+
+	src, err := s.loadSources(ctx, employeeIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	today := s.now().In(s.loc).Format(dateLayout)
+	for _, id := range employeeIDs {
+		var first, last string
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			date := d.Format(dateLayout)
+			existing := src.entries[id][date]
+			if existing != nil && existing.Source != timesheet.SourceAuto {
+				res.Manual++
+				continue
+			}
+
+			day := s.resolveDay(id, d, today, src)
+			if existing != nil && sameDay(existing, &day) {
+				res.Unchanged++
+				continue
+			}
+
+			written, err := s.repo.UpsertAutoTimesheetEntry(ctx, day)
+			if err != nil {
+				return nil, fmt.Errorf("write entry for employee %d on %s: %w", id, date, err)
+			}
+			if written {
+				res.Written++
+				if first == "" {
+					first = date
+				}
+				last = date
+			} else {
+				// Turned manual since the sources were loaded
+				res.Manual++
+			}
+		}
+		if first != "" {
+			s.changed(ctx, id, first, last)
+		}
+	}
+	return res, nil
+}
+
+func (s *Service) loadSources(ctx context.Context, employeeIDs []int64, from, to time.Time) (*sources, error) {
+	fromStr, toStr := from.Format(dateLayout), to.Format(dateLayout)
+	src := &sources{
+		entries:   make(map[int64]map[string]*timesheet.Day),
+		vacations: make(map[int64][]timesheet.Period),
+		absences:  make(map[int64][]timesheet.Period),
+		presence:  make(map[int64]map[string]timesheet.Presence),
+		tracked:   make(map[int64]bool),
+		calendar:  make(map[int64]map[string]calendar.Day),
+	}
+
+	entries, err := s.repo.GetTimesheetEntriesBetween(ctx, employeeIDs, fromStr, toStr)
+	if err != nil {
+		return nil, fmt.Errorf("get entries: %w", err)
+	}
+	for _, e := range entries {
+		if src.entries[e.EmployeeID] == nil {
+			src.entries[e.EmployeeID] = make(map[string]*timesheet.Day)
+		}
+		src.entries[e.EmployeeID][e.Date] = e
+	}
+
+	vacations, err := s.repo.GetApprovedVacationPeriods(ctx, employeeIDs, fromStr, toStr)
+	if err != nil {
+		return nil, fmt.Errorf("get vacations: %w", err)
+	}
+	for _, p := range vacations {
+		src.vacations[p.EmployeeID] = append(src.vacations[p.EmployeeID], p)
+	}
+
+	absences, err := s.repo.GetAbsencePeriods(ctx, employeeIDs, fromStr, toStr)
+	if err != nil {
+		return nil, fmt.Errorf("get absences: %w", err)
+	}
+	for _, p := range absences {
+		src.absences[p.EmployeeID] = append(src.absences[p.EmployeeID], p)
+	}
+
+	presence, err := s.repo.GetAccessPresence(ctx, employeeIDs, fromStr, toStr, s.loc.String())
+	if err != nil {
+		return nil, fmt.Errorf("get access presence: %w", err)
+	}
+	for _, p := range presence {
+		if src.presence[p.EmployeeID] == nil {
+			src.presence[p.EmployeeID] = make(map[string]timesheet.Presence)
+		}
+		src.presence[p.EmployeeID][p.Date] = p
+	}
+
+	tracked, err := s.repo.GetAccessTrackedEmployees(ctx, employeeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get tracked employees: %w", err)
+	}
+	for _, id := range tracked {
+		src.tracked[id] = true
+	}
+
+	calendars, err := s.calendar.EmployeeDays(ctx, employeeIDs, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("get calendar: %w", err)
+	}
+	for id, days := range calendars {
+		src.calendar[id] = make(map[string]calendar.Day, len(days))
+		for _, d := range days {
+			src.calendar[id][d.Date] = d
+		}
+	}
+
+	return src, nil
+}
+
+// resolveDay works out an employee's day from the sources. In order of
+// precedence: an approved vacation, a sick leave or business trip, a
+// holiday, a day off of the employee's calendar, then the access-control
+// logs. An employee with an access card and no events on a past working day
+// is absent; one without a card, or on a day still to come, is present.
+// Hours beyond the calendar length of a working day, and all hours on a day
+// off or holiday, are overtime. Hours between 22:00 and 06:00 are night
+// hours.
+func (s *Service) resolveDay(employeeID int64, d time.Time, today string, src *sources) timesheet.Day {
+	date := d.Format(dateLayout)
+	cd := src.calendar[employeeID][date]
+	day := timesheet.Day{
+		EmployeeID: employeeID,
+		Date:       date,
+		IsWeekend:  !cd.Working && !cd.Holiday,
+		IsHoliday:  cd.Holiday,
+		Source:     timesheet.SourceAuto,
+	}
+
+	if p, ok := coveringPeriod(src.vacations[employeeID], date); ok {
+		day.Status = "vacation"
+		if status, ok := vacationStatuses[p.Kind]; ok {
+			day.Status = status
+		}
+		return day
+	}
+	if p, ok := coveringPeriod(src.absences[employeeID], date); ok {
+		day.Status = p.Kind
+		return day
+	}
+
+	switch {
+	case day.IsHoliday:
+		day.Status = "holiday"
+	case day.IsWeekend:
+		day.Status = "day_off"
+	default:
+		day.Status = "present"
+	}
+
+	presence, ok := src.presence[employeeID][date]
+	if !ok {
+		if day.Status == "present" && src.tracked[employeeID] && date < today {
+			day.Status = "absent"
+		}
+		return day
+	}
+
+	if presence.FirstIn != nil {
+		checkIn := presence.FirstIn.In(s.loc).Format("15:04")
+		day.CheckIn = &checkIn
+	}
+	if presence.LastOut != nil {
+		checkOut := presence.LastOut.In(s.loc).Format("15:04")
+		day.CheckOut = &checkOut
+	}
+	if presence.FirstIn != nil && presence.LastOut != nil && presence.LastOut.After(*presence.FirstIn) {
+		hours := math.Round(presence.LastOut.Sub(*presence.FirstIn).Hours()*100) / 100
+		overtime := hours
+		if day.Status == "present" {
+			overtime = math.Max(0, math.Round((hours-cd.Hours)*100)/100)
+		}
+		night := math.Round(nightHours(*presence.FirstIn, *presence.LastOut, s.loc)*100) / 100
+		day.HoursWorked = &hours
+		day.Overtime = &overtime
+		day.NightHours = &night
+	}
+	return day
+}
+
+// nightHours is the part of the span from in to out that falls between
+// nightStartHour and nightEndHour
+func nightHours(in, out time.Time, loc *time.Location) float64 {
+	in, out = in.In(loc), out.In(loc)
+	var total time.Duration
+	// The night that began the evening before in's day may reach into it
+	for d := time.Date(in.Year(), in.Month(), in.Day()-1, 0, 0, 0, 0, loc); d.Before(out); d = d.AddDate(0, 0, 1) {
+		start := time.Date(d.Year(), d.Month(), d.Day(), nightStartHour, 0, 0, 0, loc)
+		end := time.Date(d.Year(), d.Month(), d.Day()+1, nightEndHour, 0, 0, 0, loc)
+		if start.Before(in) {
+			start = in
+		}
+		if end.After(out) {
+			end = out
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total.Hours()
+}
+
+func coveringPeriod(periods []timesheet.Period, date string) (timesheet.Period, bool) {
+	for _, p := range periods {
+		if p.StartDate <= date && date <= p.EndDate {
+			return p, true
+		}
+	}
+	return timesheet.Period{}, false
+}
+
+// sameDay reports whether a stored entry already holds the resolved day.
+// Times come back from the database as HH:MM:SS.
+func sameDay(stored, day *timesheet.Day) bool {
+	return stored.Status == day.Status &&
+		stored.IsWeekend == day.IsWeekend &&
+		stored.IsHoliday == day.IsHoliday &&
+		sameTime(stored.CheckIn, day.CheckIn) &&
+		sameTime(stored.CheckOut, day.CheckOut) &&
+		sameHours(stored.HoursWorked, day.HoursWorked) &&
+		sameHours(stored.Overtime, day.Overtime) &&
+		sameHours(stored.NightHours, day.NightHours)
+}
+
+func sameTime(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return len(*a) >= 5 && len(*b) >= 5 && (*a)[:5] == (*b)[:5]
+}
+
+func sameHours(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return math.Abs(*a-*b) < 0.005
+}
+
+func parseRange(from, to string) (time.Time, time.Time, error) {
+	start, err := time.Parse(dateLayout, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("parse from: %w", err)
+	}
+	end, err := time.Parse(dateLayout, to)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("parse to: %w", err)
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("range %s..%s is empty", from, to)
+	}
+	return start, end, nil
+}
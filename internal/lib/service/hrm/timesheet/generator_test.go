@@ -0,0 +1,324 @@
+package timesheet
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/calendar"
+	"srmt-admin/internal/lib/model/hrm/timesheet"
+)
+
+// fakeRepo serves the auto-fill sources from memory and records the written
+// entries. Manual entry edits, corrections and absences panic, as the
+// generator never touches them.
+type fakeRepo struct {
+	employees []int64
+	entries   []*timesheet.Day
+	vacations []timesheet.Period
+	absences  []timesheet.Period
+	presence  []timesheet.Presence
+	tracked   []int64
+
+	written map[string]timesheet.Day
+}
+
+func (f *fakeRepo) GetEmployeesForTimesheet(_ context.Context, filters dto.TimesheetFilters) ([]*timesheet.EmployeeInfo, error) {
+	var res []*timesheet.EmployeeInfo
+	for _, id := range f.employees {
+		if filters.EmployeeID == nil || *filters.EmployeeID == id {
+			res = append(res, &timesheet.EmployeeInfo{EmployeeID: id})
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeRepo) GetTimesheetEntriesBetween(context.Context, []int64, string, string) ([]*timesheet.Day, error) {
+	return f.entries, nil
+}
+
+func (f *fakeRepo) GetApprovedVacationPeriods(context.Context, []int64, string, string) ([]timesheet.Period, error) {
+	return f.vacations, nil
+}
+
+func (f *fakeRepo) GetAbsencePeriods(context.Context, []int64, string, string) ([]timesheet.Period, error) {
+	return f.absences, nil
+}
+
+func (f *fakeRepo) GetAccessPresence(context.Context, []int64, string, string, string) ([]timesheet.Presence, error) {
+	return f.presence, nil
+}
+
+func (f *fakeRepo) GetAccessTrackedEmployees(context.Context, []int64) ([]int64, error) {
+	return f.tracked, nil
+}
+
+func (f *fakeRepo) UpsertAutoTimesheetEntry(_ context.Context, d timesheet.Day) (bool, error) {
+	if f.written == nil {
+		f.written = make(map[string]timesheet.Day)
+	}
+	f.written[d.Date] = d
+	return true, nil
+}
+
+func (f *fakeRepo) GetTimesheetEntries(context.Context, int64, int, int) ([]*timesheet.Day, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetTimesheetEntry(context.Context, int64) (*timesheet.Day, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetTimesheetEntryByEmployeeDate(context.Context, int64, string) (*timesheet.Day, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) UpsertTimesheetEntry(context.Context, int64, string, string, *string, *string, *float64, *float64, bool, bool, *string) (int64, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) UpdateTimesheetEntry(context.Context, int64, dto.UpdateTimesheetEntryRequest) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetTimesheetCorrections(context.Context, dto.CorrectionFilters) ([]*timesheet.Correction, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetTimesheetCorrectionByID(context.Context, int64) (*timesheet.Correction, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) CreateTimesheetCorrection(context.Context, dto.CreateTimesheetCorrectionRequest, *string, *string, *string, int64) (int64, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) ApproveTimesheetCorrection(context.Context, int64, int64) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) RejectTimesheetCorrection(context.Context, int64, int64, string) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) CreateAbsence(context.Context, dto.CreateAbsenceRequest, int64) (int64, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetAbsences(context.Context, dto.AbsenceFilters) ([]*timesheet.Absence, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetAbsenceByID(context.Context, int64) (*timesheet.Absence, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) DeleteAbsence(context.Context, int64) error {
+	panic("not implemented")
+}
+
+// fakeCalendar works a six-day week of 8-hour days with the given holidays
+type fakeCalendar struct {
+	holidays map[string]bool
+}
+
+func (f *fakeCalendar) EmployeeDays(_ context.Context, employeeIDs []int64, from, to time.Time) (map[int64][]calendar.Day, error) {
+	var days []calendar.Day
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		day := calendar.Day{Date: d.Format(dateLayout), Holiday: f.holidays[d.Format(dateLayout)]}
+		if d.Weekday() != time.Sunday && !day.Holiday {
+			day.Working = true
+			day.Hours = 8
+		}
+		days = append(days, day)
+	}
+	res := make(map[int64][]calendar.Day, len(employeeIDs))
+	for _, id := range employeeIDs {
+		res[id] = days
+	}
+	return res, nil
+}
+
+func newTestService(repo *fakeRepo, cal *fakeCalendar, now time.Time) *Service {
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	svc := NewService(repo, cal, loc, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+func at(loc *time.Location, value string) *time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+	if err != nil {
+		panic(err)
+	}
+	return &t
+}
+
+func TestGenerate_ResolvesDaysFromSources(t *testing.T) {
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	note := "set by HR"
+	repo := &fakeRepo{
+		employees: []int64{7},
+		tracked:   []int64{7},
+		entries: []*timesheet.Day{
+			{EmployeeID: 7, Date: "2026-03-02", Status: "remote", Note: &note, Source: timesheet.SourceManual},
+		},
+		vacations: []timesheet.Period{
+			{EmployeeID: 7, Kind: "annual", StartDate: "2026-03-20", EndDate: "2026-03-22"},
+			{EmployeeID: 7, Kind: "study", StartDate: "2026-03-27", EndDate: "2026-03-27"},
+		},
+		absences: []timesheet.Period{
+			{EmployeeID: 7, Kind: "sick_leave", StartDate: "2026-03-09", EndDate: "2026-03-10"},
+			{EmployeeID: 7, Kind: "business_trip", StartDate: "2026-03-21", EndDate: "2026-03-21"},
+		},
+		presence: []timesheet.Presence{
+			{EmployeeID: 7, Date: "2026-03-03", FirstIn: at(loc, "2026-03-03 08:55"), LastOut: at(loc, "2026-03-03 18:25")},
+			{EmployeeID: 7, Date: "2026-03-04", FirstIn: at(loc, "2026-03-04 09:10")},
+			{EmployeeID: 7, Date: "2026-03-15", FirstIn: at(loc, "2026-03-15 10:00"), LastOut: at(loc, "2026-03-15 13:30")},
+		},
+	}
+	cal := &fakeCalendar{holidays: map[string]bool{"2026-03-21": true, "2026-03-08": true}}
+	svc := newTestService(repo, cal, time.Date(2026, 3, 25, 12, 0, 0, 0, loc))
+
+	res, err := svc.Generate(context.Background(), dto.GenerateTimesheetRequest{Year: 2026, Month: 3})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if res.Employees != 1 || res.Manual != 1 || res.Written != 30 {
+		t.Fatalf("result = %+v, want 1 employee, 1 manual, 30 written", res)
+	}
+
+	if _, ok := repo.written["2026-03-02"]; ok {
+		t.Error("manual entry on 2026-03-02 was overwritten")
+	}
+
+	cases := []struct {
+		date   string
+		status string
+	}{
+		{"2026-03-03", "present"},
+		{"2026-03-05", "absent"},      // tracked, past working day, no events
+		{"2026-03-08", "holiday"},     // holiday on a Sunday
+		{"2026-03-09", "sick_leave"},  // absence
+		{"2026-03-21", "vacation"},    // vacation wins over trip and holiday
+		{"2026-03-27", "study_leave"}, // study vacation
+		{"2026-03-26", "present"},     // still to come
+		{"2026-03-29", "day_off"},     // Sunday
+		{"2026-03-15", "day_off"},     // Sunday, worked
+		{"2026-03-25", "present"},     // today, no events yet
+		{"2026-03-31", "present"},     // still to come
+		{"2026-03-10", "sick_leave"},  // absence, last day
+		{"2026-03-22", "vacation"},    // vacation on a Sunday
+	}
+	for _, c := range cases {
+		got, ok := repo.written[c.date]
+		if !ok {
+			t.Errorf("%s: not written", c.date)
+			continue
+		}
+		if got.Status != c.status {
+			t.Errorf("%s: status = %q, want %q", c.date, got.Status, c.status)
+		}
+	}
+
+	d := repo.written["2026-03-03"]
+	if d.CheckIn == nil || *d.CheckIn != "08:55" || d.CheckOut == nil || *d.CheckOut != "18:25" {
+		t.Errorf("2026-03-03: check-in/out = %v/%v, want 08:55/18:25", d.CheckIn, d.CheckOut)
+	}
+	if d.HoursWorked == nil || *d.HoursWorked != 9.5 || d.Overtime == nil || *d.Overtime != 1.5 {
+		t.Errorf("2026-03-03: hours/overtime = %v/%v, want 9.5/1.5", d.HoursWorked, d.Overtime)
+	}
+
+	d = repo.written["2026-03-04"]
+	if d.CheckIn == nil || *d.CheckIn != "09:10" || d.CheckOut != nil || d.HoursWorked != nil {
+		t.Errorf("2026-03-04: entry without exit = %+v, want check-in only", d)
+	}
+
+	d = repo.written["2026-03-15"]
+	if !d.IsWeekend || d.HoursWorked == nil || *d.HoursWorked != 3.5 || d.Overtime == nil || *d.Overtime != 3.5 {
+		t.Errorf("2026-03-15: work on a day off = %+v, want 3.5 hours, all overtime", d)
+	}
+
+	if d := repo.written["2026-03-21"]; !d.IsHoliday {
+		t.Error("2026-03-21: holiday flag lost under vacation")
+	}
+}
+
+func TestGenerate_UntrackedEmployeeIsPresent(t *testing.T) {
+	repo := &fakeRepo{employees: []int64{9}}
+	svc := newTestService(repo, &fakeCalendar{}, time.Date(2026, 4, 30, 12, 0, 0, 0, time.UTC))
+
+	if _, err := svc.Generate(context.Background(), dto.GenerateTimesheetRequest{Year: 2026, Month: 4}); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if got := repo.written["2026-04-01"].Status; got != "present" {
+		t.Errorf("status = %q, want present for an employee without an access card", got)
+	}
+}
+
+func TestGenerate_SkipsUnchangedAutoEntries(t *testing.T) {
+	repo := &fakeRepo{
+		employees: []int64{3},
+		entries: []*timesheet.Day{
+			{EmployeeID: 3, Date: "2026-04-01", Status: "present", Source: timesheet.SourceAuto},
+			{EmployeeID: 3, Date: "2026-04-02", Status: "absent", Source: timesheet.SourceAuto},
+		},
+	}
+	svc := newTestService(repo, &fakeCalendar{}, time.Date(2026, 4, 30, 12, 0, 0, 0, time.UTC))
+
+	res, err := svc.Generate(context.Background(), dto.GenerateTimesheetRequest{Year: 2026, Month: 4})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if res.Unchanged != 1 {
+		t.Errorf("unchanged = %d, want 1", res.Unchanged)
+	}
+	if _, ok := repo.written["2026-04-01"]; ok {
+		t.Error("unchanged auto entry was rewritten")
+	}
+	if got := repo.written["2026-04-02"].Status; got != "present" {
+		t.Errorf("stale auto entry status = %q, want present", got)
+	}
+}
+
+func TestRecalculateTimesheet_IgnoresInactiveEmployee(t *testing.T) {
+	repo := &fakeRepo{employees: []int64{1}}
+	svc := newTestService(repo, &fakeCalendar{}, time.Now())
+
+	if err := svc.RecalculateTimesheet(context.Background(), 2, "2026-05-04", "2026-05-08"); err != nil {
+		t.Fatalf("RecalculateTimesheet: %v", err)
+	}
+	if len(repo.written) != 0 {
+		t.Errorf("written %d entries for an employee without an active record", len(repo.written))
+	}
+}
+
+func TestGenerate_CountsNightHoursAndReportsChange(t *testing.T) {
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	repo := &fakeRepo{
+		employees: []int64{4},
+		tracked:   []int64{4},
+		entries: []*timesheet.Day{
+			{EmployeeID: 4, Date: "2026-04-01", Status: "absent", Source: timesheet.SourceAuto},
+		},
+		presence: []timesheet.Presence{
+			// Night shift from 20:00 to 08:00 the next morning
+			{EmployeeID: 4, Date: "2026-04-02", FirstIn: at(loc, "2026-04-02 20:00"), LastOut: at(loc, "2026-04-03 08:00")},
+			// Early start before six
+			{EmployeeID: 4, Date: "2026-04-04", FirstIn: at(loc, "2026-04-04 05:15"), LastOut: at(loc, "2026-04-04 14:00")},
+		},
+	}
+	svc := newTestService(repo, &fakeCalendar{}, time.Date(2026, 4, 5, 12, 0, 0, 0, loc))
+	var changes [][2]string
+	svc.OnChange(func(_ context.Context, employeeID int64, from, to string) {
+		if employeeID != 4 {
+			t.Errorf("change reported for employee %d", employeeID)
+		}
+		changes = append(changes, [2]string{from, to})
+	})
+
+	if err := svc.RecalculateTimesheet(context.Background(), 4, "2026-04-01", "2026-04-04"); err != nil {
+		t.Fatalf("RecalculateTimesheet: %v", err)
+	}
+
+	if d := repo.written["2026-04-02"]; d.NightHours == nil || *d.NightHours != 8 {
+		t.Errorf("2026-04-02: night hours = %v, want 8", d.NightHours)
+	}
+	if d := repo.written["2026-04-04"]; d.NightHours == nil || *d.NightHours != 0.75 {
+		t.Errorf("2026-04-04: night hours = %v, want 0.75", d.NightHours)
+	}
+	// The unchanged first day is not part of the reported change
+	if len(changes) != 1 || changes[0] != [2]string{"2026-04-02", "2026-04-04"} {
+		t.Errorf("changes = %v, want one for 2026-04-02..2026-04-04", changes)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/calendar"
 	"srmt-admin/internal/lib/model/hrm/timesheet"
 	"srmt-admin/internal/storage"
 	"time"
@@ -19,27 +20,60 @@ type RepoInterface interface {
 	UpdateTimesheetEntry(ctx context.Context, id int64, req dto.UpdateTimesheetEntryRequest) error
 	GetEmployeesForTimesheet(ctx context.Context, filters dto.TimesheetFilters) ([]*timesheet.EmployeeInfo, error)
 
-	// Holidays
-	GetHolidays(ctx context.Context, year int) ([]*timesheet.Holiday, error)
-	GetHolidaysByMonth(ctx context.Context, year, month int) ([]*timesheet.Holiday, error)
-	CreateHoliday(ctx context.Context, req dto.CreateHolidayRequest) (int64, error)
-	DeleteHoliday(ctx context.Context, id int64) error
-
 	// Corrections
 	GetTimesheetCorrections(ctx context.Context, filters dto.CorrectionFilters) ([]*timesheet.Correction, error)
 	GetTimesheetCorrectionByID(ctx context.Context, id int64) (*timesheet.Correction, error)
 	CreateTimesheetCorrection(ctx context.Context, req dto.CreateTimesheetCorrectionRequest, originalStatus, originalCheckIn, originalCheckOut *string, requestedBy int64) (int64, error)
 	ApproveTimesheetCorrection(ctx context.Context, id int64, approvedBy int64) error
 	RejectTimesheetCorrection(ctx context.Context, id int64, approvedBy int64, reason string) error
+
+	// Auto-fill sources
+	GetTimesheetEntriesBetween(ctx context.Context, employeeIDs []int64, from, to string) ([]*timesheet.Day, error)
+	GetApprovedVacationPeriods(ctx context.Context, employeeIDs []int64, from, to string) ([]timesheet.Period, error)
+	GetAbsencePeriods(ctx context.Context, employeeIDs []int64, from, to string) ([]timesheet.Period, error)
+	GetAccessPresence(ctx context.Context, employeeIDs []int64, from, to, tz string) ([]timesheet.Presence, error)
+	GetAccessTrackedEmployees(ctx context.Context, employeeIDs []int64) ([]int64, error)
+	UpsertAutoTimesheetEntry(ctx context.Context, d timesheet.Day) (bool, error)
+
+	// Absences
+	CreateAbsence(ctx context.Context, req dto.CreateAbsenceRequest, createdBy int64) (int64, error)
+	GetAbsences(ctx context.Context, filters dto.AbsenceFilters) ([]*timesheet.Absence, error)
+	GetAbsenceByID(ctx context.Context, id int64) (*timesheet.Absence, error)
+	DeleteAbsence(ctx context.Context, id int64) error
 }
 
+// Calendar tells each employee's working days from days off
+type Calendar interface {
+	EmployeeDays(ctx context.Context, employeeIDs []int64, from, to time.Time) (map[int64][]calendar.Day, error)
+}
+
+// ChangeFunc is told the days (YYYY-MM-DD) of an employee's timesheet that
+// changed
+type ChangeFunc func(ctx context.Context, employeeID int64, from, to string)
+
 type Service struct {
-	repo RepoInterface
-	log  *slog.Logger
+	repo      RepoInterface
+	calendar  Calendar
+	listeners []ChangeFunc
+	loc       *time.Location
+	now       func() time.Time
+	log       *slog.Logger
 }
 
-func NewService(repo RepoInterface, log *slog.Logger) *Service {
-	return &Service{repo: repo, log: log}
+func NewService(repo RepoInterface, calendar Calendar, loc *time.Location, log *slog.Logger) *Service {
+	return &Service{repo: repo, calendar: calendar, loc: loc, now: time.Now, log: log}
+}
+
+// OnChange registers fn to be called after entries of a timesheet are
+// written, edited or corrected
+func (s *Service) OnChange(fn ChangeFunc) {
+	s.listeners = append(s.listeners, fn)
+}
+
+func (s *Service) changed(ctx context.Context, employeeID int64, from, to string) {
+	for _, fn := range s.listeners {
+		fn(ctx, employeeID, from, to)
+	}
 }
 
 // GetTimesheet returns timesheets for all matching employees for a given month.
@@ -51,14 +85,15 @@ func (s *Service) GetTimesheet(ctx context.Context, filters dto.TimesheetFilters
 		return nil, fmt.Errorf("get employees: %w", err)
 	}
 
-	// 2. Get holidays for the month
-	holidays, err := s.repo.GetHolidaysByMonth(ctx, filters.Year, filters.Month)
-	if err != nil {
-		return nil, fmt.Errorf("get holidays: %w", err)
+	// 2. Get each employee's calendar for the month
+	ids := make([]int64, 0, len(employees))
+	for _, e := range employees {
+		ids = append(ids, e.EmployeeID)
 	}
-	holidayMap := make(map[string]bool)
-	for _, h := range holidays {
-		holidayMap[h.Date] = true
+	firstDay := time.Date(filters.Year, time.Month(filters.Month), 1, 0, 0, 0, 0, time.UTC)
+	calendars, err := s.calendar.EmployeeDays(ctx, ids, firstDay, firstDay.AddDate(0, 1, -1))
+	if err != nil {
+		return nil, fmt.Errorf("get calendar: %w", err)
 	}
 
 	// 3. Build timesheet for each employee
@@ -76,7 +111,7 @@ func (s *Service) GetTimesheet(ctx context.Context, filters dto.TimesheetFilters
 		}
 
 		// Generate full month of days
-		days, summary := s.generateMonthDays(filters.Year, filters.Month, entryMap, holidayMap)
+		days, summary := s.generateMonthDays(calendars[emp.EmployeeID], entryMap)
 
 		result = append(result, &timesheet.EmployeeTimesheet{
 			EmployeeID:   emp.EmployeeID,
@@ -96,19 +131,15 @@ func (s *Service) GetTimesheet(ctx context.Context, filters dto.TimesheetFilters
 	return result, nil
 }
 
-// generateMonthDays builds the full array of days for a month with summary
-func (s *Service) generateMonthDays(year, month int, entryMap map[string]*timesheet.Day, holidayMap map[string]bool) ([]timesheet.Day, timesheet.Summary) {
-	loc := time.UTC
-	firstDay := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc)
-	lastDay := firstDay.AddDate(0, 1, -1)
-
+// generateMonthDays builds the full array of days of a month's calendar with summary
+func (s *Service) generateMonthDays(calendarDays []calendar.Day, entryMap map[string]*timesheet.Day) ([]timesheet.Day, timesheet.Summary) {
 	var days []timesheet.Day
 	var summary timesheet.Summary
 
-	for d := firstDay; !d.After(lastDay); d = d.AddDate(0, 0, 1) {
-		dateStr := d.Format("2006-01-02")
-		isWeekend := d.Weekday() == time.Sunday
-		isHoliday := holidayMap[dateStr]
+	for _, cd := range calendarDays {
+		dateStr := cd.Date
+		isWeekend := !cd.Working && !cd.Holiday
+		isHoliday := cd.Holiday
 
 		day := timesheet.Day{
 			Date:      dateStr,
@@ -125,6 +156,7 @@ func (s *Service) generateMonthDays(year, month int, entryMap map[string]*timesh
 			day.CheckOut = entry.CheckOut
 			day.HoursWorked = entry.HoursWorked
 			day.Overtime = entry.Overtime
+			day.NightHours = entry.NightHours
 			day.Note = entry.Note
 			day.IsWeekend = entry.IsWeekend
 			day.IsHoliday = entry.IsHoliday
@@ -141,13 +173,14 @@ func (s *Service) generateMonthDays(year, month int, entryMap map[string]*timesh
 		}
 
 		// Update summary
-		if !isWeekend && !isHoliday {
+		if cd.Working {
 			summary.TotalWorkDays++
+			summary.NormHours += cd.Hours
 		}
 
 		switch day.Status {
 		case "present":
-			if !isWeekend && !isHoliday {
+			if cd.Working {
 				summary.PresentDays++
 			}
 		case "absent", "unauthorized":
@@ -168,6 +201,9 @@ func (s *Service) generateMonthDays(year, month int, entryMap map[string]*timesh
 		if day.Overtime != nil {
 			summary.OvertimeHours += *day.Overtime
 		}
+		if day.NightHours != nil {
+			summary.NightHours += *day.NightHours
+		}
 
 		days = append(days, day)
 	}
@@ -177,29 +213,17 @@ func (s *Service) generateMonthDays(year, month int, entryMap map[string]*timesh
 
 // UpdateEntry updates an existing timesheet entry
 func (s *Service) UpdateEntry(ctx context.Context, id int64, req dto.UpdateTimesheetEntryRequest) error {
-	return s.repo.UpdateTimesheetEntry(ctx, id, req)
-}
+	if err := s.repo.UpdateTimesheetEntry(ctx, id, req); err != nil {
+		return err
+	}
 
-// GetHolidays returns holidays for a year
-func (s *Service) GetHolidays(ctx context.Context, year int) ([]*timesheet.Holiday, error) {
-	holidays, err := s.repo.GetHolidays(ctx, year)
+	entry, err := s.repo.GetTimesheetEntry(ctx, id)
 	if err != nil {
-		return nil, err
+		s.log.Error("failed to get updated timesheet entry", "error", err, "entry_id", id)
+		return nil
 	}
-	if holidays == nil {
-		holidays = []*timesheet.Holiday{}
-	}
-	return holidays, nil
-}
-
-// CreateHoliday creates a new holiday
-func (s *Service) CreateHoliday(ctx context.Context, req dto.CreateHolidayRequest) (int64, error) {
-	return s.repo.CreateHoliday(ctx, req)
-}
-
-// DeleteHoliday deletes a holiday
-func (s *Service) DeleteHoliday(ctx context.Context, id int64) error {
-	return s.repo.DeleteHoliday(ctx, id)
+	s.changed(ctx, entry.EmployeeID, entry.Date, entry.Date)
+	return nil
 }
 
 // GetCorrections returns timesheet corrections
@@ -244,22 +268,28 @@ func (s *Service) ApproveCorrection(ctx context.Context, id int64, approvedBy in
 		return storage.ErrInvalidStatus
 	}
 
-	// Approve the correction
-	if err := s.repo.ApproveTimesheetCorrection(ctx, id, approvedBy); err != nil {
-		return err
-	}
-
-	// Apply the correction to timesheet_entries via upsert
+	// Look the day up in the employee's calendar
 	dateStr := cor.Date
 	date, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
 		return fmt.Errorf("parse date: %w", err)
 	}
+	calendars, err := s.calendar.EmployeeDays(ctx, []int64{cor.EmployeeID}, date, date)
+	if err != nil {
+		return fmt.Errorf("get calendar: %w", err)
+	}
+	var isWeekend, isHoliday bool
+	if days := calendars[cor.EmployeeID]; len(days) == 1 {
+		isWeekend = !days[0].Working && !days[0].Holiday
+		isHoliday = days[0].Holiday
+	}
 
-	isWeekend := date.Weekday() == time.Sunday
-	// We can't easily check holiday here without an extra query, so default to false
-	// The actual holiday flag will be set if there was an existing entry
-	isHoliday := false
+	// Approve the correction
+	if err := s.repo.ApproveTimesheetCorrection(ctx, id, approvedBy); err != nil {
+		return err
+	}
+
+	// Apply the correction to timesheet_entries via upsert
 
 	_, err = s.repo.UpsertTimesheetEntry(ctx, cor.EmployeeID, dateStr, cor.NewStatus,
 		cor.NewCheckIn, cor.NewCheckOut, nil, nil, isWeekend, isHoliday, nil)
@@ -268,6 +298,7 @@ func (s *Service) ApproveCorrection(ctx context.Context, id int64, approvedBy in
 		return fmt.Errorf("apply correction: %w", err)
 	}
 
+	s.changed(ctx, cor.EmployeeID, dateStr, dateStr)
 	return nil
 }
 
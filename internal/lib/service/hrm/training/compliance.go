@@ -0,0 +1,361 @@
+package training
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/training"
+	"srmt-admin/internal/lib/service/notification"
+	"time"
+)
+
+// alertHour is when the daily expiry alerts go out, local time
+const alertHour = 8
+
+// GetCompliance is the certification compliance matrix as of today
+func (s *Service) GetCompliance(ctx context.Context, filters dto.ComplianceFilters) (*training.ComplianceMatrix, error) {
+	today := s.today()
+	reqs, err := s.repo.GetRequiredCertifications(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &training.ComplianceMatrix{
+		Date:           today,
+		Certifications: []training.ComplianceColumn{},
+		Employees:      []*training.EmployeeCompliance{},
+	}
+	columns := make(map[int64]bool)
+	var row *training.EmployeeCompliance
+	for _, rc := range reqs {
+		if row == nil || row.EmployeeID != rc.EmployeeID {
+			row = &training.EmployeeCompliance{
+				EmployeeID:     rc.EmployeeID,
+				EmployeeName:   rc.EmployeeName,
+				DepartmentID:   rc.DepartmentID,
+				DepartmentName: rc.DepartmentName,
+				PositionID:     rc.PositionID,
+				PositionName:   rc.PositionName,
+				Compliant:      true,
+			}
+			m.Employees = append(m.Employees, row)
+		}
+		if !columns[rc.CertificationTypeID] {
+			columns[rc.CertificationTypeID] = true
+			m.Certifications = append(m.Certifications, training.ComplianceColumn{ID: rc.CertificationTypeID, Name: rc.CertificationTypeName})
+		}
+
+		cell := certificationStatus(rc, today)
+		row.Certifications = append(row.Certifications, cell)
+		m.Summary.Required++
+		switch cell.Status {
+		case training.ComplianceValid:
+			m.Summary.Valid++
+		case training.ComplianceExpiring:
+			m.Summary.Expiring++
+		case training.ComplianceExpired:
+			m.Summary.Expired++
+			row.Compliant = false
+		case training.ComplianceMissing:
+			m.Summary.Missing++
+			row.Compliant = false
+		}
+	}
+
+	sort.Slice(m.Certifications, func(i, j int) bool { return m.Certifications[i].Name < m.Certifications[j].Name })
+	m.Summary.Employees = len(m.Employees)
+	for _, row := range m.Employees {
+		if row.Compliant {
+			m.Summary.Compliant++
+		}
+	}
+	return m, nil
+}
+
+// GetEnrolmentSuggestions proposes the nearest upcoming training granting
+// each required certification that is missing, expired or expiring, unless
+// the employee is already enrolled in one. Missing and expired ones are
+// placed first, so the seats of a limited training go to them.
+func (s *Service) GetEnrolmentSuggestions(ctx context.Context, filters dto.ComplianceFilters) ([]*training.EnrolmentSuggestion, error) {
+	today := s.today()
+	reqs, err := s.repo.GetRequiredCertifications(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	type gap struct {
+		rc   *training.RequiredCertification
+		cell training.CertificationStatus
+	}
+	var gaps []gap
+	var typeIDs []int64
+	seen := make(map[int64]bool)
+	for _, rc := range reqs {
+		cell := certificationStatus(rc, today)
+		if cell.Status == training.ComplianceValid {
+			continue
+		}
+		gaps = append(gaps, gap{rc: rc, cell: cell})
+		if !seen[rc.CertificationTypeID] {
+			seen[rc.CertificationTypeID] = true
+			typeIDs = append(typeIDs, rc.CertificationTypeID)
+		}
+	}
+
+	suggestions := []*training.EnrolmentSuggestion{}
+	if len(gaps) == 0 {
+		return suggestions, nil
+	}
+
+	trainings, err := s.repo.GetCertificationTrainings(ctx, today, typeIDs)
+	if err != nil {
+		return nil, err
+	}
+	byType := make(map[int64][]*training.CertificationTraining)
+	for _, t := range trainings {
+		byType[t.CertificationTypeID] = append(byType[t.CertificationTypeID], t)
+	}
+
+	sort.SliceStable(gaps, func(i, j int) bool {
+		ei, ej := gaps[i].cell.Status == training.ComplianceExpiring, gaps[j].cell.Status == training.ComplianceExpiring
+		if ei != ej {
+			return ej
+		}
+		return ei && *gaps[i].cell.ExpiryDate < *gaps[j].cell.ExpiryDate
+	})
+
+	for _, g := range gaps {
+		t := nearestTraining(byType[g.rc.CertificationTypeID], g.rc.EmployeeID)
+		if t == nil {
+			continue
+		}
+		if t.SeatsLeft != nil {
+			*t.SeatsLeft--
+		}
+		suggestions = append(suggestions, &training.EnrolmentSuggestion{
+			EmployeeID:            g.rc.EmployeeID,
+			EmployeeName:          g.rc.EmployeeName,
+			DepartmentName:        g.rc.DepartmentName,
+			PositionName:          g.rc.PositionName,
+			CertificationTypeID:   g.rc.CertificationTypeID,
+			CertificationTypeName: g.rc.CertificationTypeName,
+			Status:                g.cell.Status,
+			ExpiryDate:            g.cell.ExpiryDate,
+			TrainingID:            t.ID,
+			TrainingTitle:         t.Title,
+			TrainingStartDate:     t.StartDate,
+		})
+	}
+	return suggestions, nil
+}
+
+// AlertExpiring alerts the holders of required certifications expiring
+// within the alert period of their type as of date, and again once they
+// have expired, pointing them to the nearest training granting it. HR gets
+// a summary. A certificate is alerted once per stage; a failed alert is
+// retried on the next run. Returns how many certificates were alerted about.
+func (s *Service) AlertExpiring(ctx context.Context, date string) (int, error) {
+	if s.notifier == nil {
+		return 0, nil
+	}
+	certs, err := s.repo.GetExpiringCertificates(ctx, date)
+	if err != nil {
+		return 0, fmt.Errorf("get expiring certificates: %w", err)
+	}
+	if len(certs) == 0 {
+		return 0, nil
+	}
+
+	var typeIDs []int64
+	seen := make(map[int64]bool)
+	for _, c := range certs {
+		if !seen[c.CertificationTypeID] {
+			seen[c.CertificationTypeID] = true
+			typeIDs = append(typeIDs, c.CertificationTypeID)
+		}
+	}
+	trainings, err := s.repo.GetCertificationTrainings(ctx, date, typeIDs)
+	if err != nil {
+		return 0, fmt.Errorf("get certification trainings: %w", err)
+	}
+	byType := make(map[int64][]*training.CertificationTraining)
+	for _, t := range trainings {
+		byType[t.CertificationTypeID] = append(byType[t.CertificationTypeID], t)
+	}
+
+	var expiring, expired int
+	for _, c := range certs {
+		typ, stage := notification.CertificationExpiring, training.ComplianceExpiring
+		message := fmt.Sprintf("Срок действия удостоверения «%s» истекает %s (через %d дн.).",
+			c.CertificationTypeName, formatDate(c.ExpiryDate), daysBetween(date, c.ExpiryDate))
+		if c.ExpiryDate < date {
+			typ, stage = notification.CertificationExpired, training.ComplianceExpired
+			message = fmt.Sprintf("Срок действия удостоверения «%s» истёк %s.",
+				c.CertificationTypeName, formatDate(c.ExpiryDate))
+		}
+		if c.Alert != nil && *c.Alert == stage {
+			continue
+		}
+		message += trainingHint(byType[c.CertificationTypeID], c.EmployeeID)
+
+		err := s.notifier.Notify(ctx, notification.Event{
+			Type:     typ,
+			Contacts: []int64{c.EmployeeID},
+			Message:  message,
+			Link:     "/my-training",
+		})
+		if err != nil {
+			s.log.Error("failed to alert about expiring certificate", "error", err, "certificate_id", c.ID)
+			continue
+		}
+		if err := s.repo.SetCertificateExpiryAlert(ctx, c.ID, stage); err != nil {
+			s.log.Error("failed to record certificate expiry alert", "error", err, "certificate_id", c.ID)
+		}
+		if stage == training.ComplianceExpired {
+			expired++
+		} else {
+			expiring++
+		}
+	}
+
+	if expiring+expired > 0 {
+		typ := notification.CertificationExpiring
+		if expired > 0 {
+			typ = notification.CertificationExpired
+		}
+		err := s.notifier.Notify(ctx, notification.Event{
+			Type:    typ,
+			Roles:   []string{"hrm_admin"},
+			Title:   "Сроки действия удостоверений работников",
+			Message: fmt.Sprintf("Истекает удостоверений: %d, истекло: %d.", expiring, expired),
+			Link:    "/hrm/training/compliance",
+		})
+		if err != nil {
+			s.log.Error("failed to alert HR about expiring certificates", "error", err)
+		}
+	}
+	return expiring + expired, nil
+}
+
+// StartScheduler sends the expiry alerts once a day at alertHour. Blocks
+// until ctx is cancelled.
+func (s *Service) StartScheduler(ctx context.Context) {
+	for {
+		now := s.now().In(s.loc)
+		next := time.Date(now.Year(), now.Month(), now.Day(), alertHour, 0, 0, 0, s.loc)
+		if !next.After(now) {
+			next = next.Add(24 * time.Hour)
+		}
+		wait := next.Sub(now)
+
+		s.log.Info("next certification expiry alerts scheduled",
+			slog.String("run_at", next.Format(time.RFC3339)),
+			slog.Duration("in", wait))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.log.Info("training scheduler stopped")
+			return
+		case <-timer.C:
+			date := next.Format(dateLayout)
+			n, err := s.AlertExpiring(ctx, date)
+			if err != nil {
+				s.log.Error("certification expiry alerts failed", slog.String("date", date), slog.String("error", err.Error()))
+				continue
+			}
+			s.log.Info("certification expiry alerts completed", slog.String("date", date), slog.Int("alerted", n))
+		}
+	}
+}
+
+func (s *Service) today() string {
+	now := s.now()
+	if s.loc != nil {
+		now = now.In(s.loc)
+	}
+	return now.Format(dateLayout)
+}
+
+// certificationStatus is the status of a required certification on date. A
+// certificate is valid through its expiry date.
+func certificationStatus(rc *training.RequiredCertification, date string) training.CertificationStatus {
+	cell := training.CertificationStatus{
+		CertificationTypeID: rc.CertificationTypeID,
+		CertificateID:       rc.CertificateID,
+		ExpiryDate:          rc.ExpiryDate,
+	}
+	switch {
+	case rc.CertificateID == nil:
+		cell.Status = training.ComplianceMissing
+	case rc.ExpiryDate == nil:
+		cell.Status = training.ComplianceValid
+	default:
+		left := daysBetween(date, *rc.ExpiryDate)
+		cell.DaysLeft = &left
+		switch {
+		case left < 0:
+			cell.Status = training.ComplianceExpired
+		case left <= rc.AlertDays:
+			cell.Status = training.ComplianceExpiring
+		default:
+			cell.Status = training.ComplianceValid
+		}
+	}
+	return cell
+}
+
+// nearestTraining is the earliest of the trainings with a seat left, or nil
+// when the employee is enrolled in any of them already
+func nearestTraining(trainings []*training.CertificationTraining, employeeID int64) *training.CertificationTraining {
+	var nearest *training.CertificationTraining
+	for _, t := range trainings {
+		for _, id := range t.Enrolled {
+			if id == employeeID {
+				return nil
+			}
+		}
+		if nearest == nil && (t.SeatsLeft == nil || *t.SeatsLeft > 0) {
+			nearest = t
+		}
+	}
+	return nearest
+}
+
+// trainingHint points an employee to the training granting the certification
+// they are enrolled in, or else to the nearest one with a seat left
+func trainingHint(trainings []*training.CertificationTraining, employeeID int64) string {
+	for _, t := range trainings {
+		for _, id := range t.Enrolled {
+			if id == employeeID {
+				return fmt.Sprintf(" Вы записаны на обучение «%s» с %s.", t.Title, formatDate(t.StartDate))
+			}
+		}
+	}
+	if t := nearestTraining(trainings, employeeID); t != nil {
+		return fmt.Sprintf(" Ближайшее обучение: «%s» с %s.", t.Title, formatDate(t.StartDate))
+	}
+	return ""
+}
+
+// daysBetween counts the days from one date to another, negative when to is
+// earlier
+func daysBetween(from, to string) int {
+	f, err1 := time.Parse(dateLayout, from)
+	t, err2 := time.Parse(dateLayout, to)
+	if err1 != nil || err2 != nil {
+		return 0
+	}
+	return int(t.Sub(f).Hours() / 24)
+}
+
+func formatDate(date string) string {
+	t, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return date
+	}
+	return t.Format("02.01.2006")
+}
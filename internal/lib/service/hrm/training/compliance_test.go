@@ -0,0 +1,352 @@
+package training
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/training"
+	"srmt-admin/internal/lib/service/notification"
+	"srmt-admin/internal/storage"
+)
+
+// fakeRepo serves canned compliance data. Training, certification type and
+// development plan management have no tests here; those methods panic.
+type fakeRepo struct {
+	types     map[int64]*training.CertificationType
+	required  []*training.RequiredCertification
+	trainings []*training.CertificationTraining
+	expiring  []*training.ExpiringCertificate
+
+	created *dto.CreateCertificateRequest
+	alerts  map[int64]string
+}
+
+func (f *fakeRepo) GetCertificationTypeByID(_ context.Context, id int64) (*training.CertificationType, error) {
+	ct, ok := f.types[id]
+	if !ok {
+		return nil, storage.ErrCertificationTypeNotFound
+	}
+	return ct, nil
+}
+
+func (f *fakeRepo) CreateCertificate(_ context.Context, req dto.CreateCertificateRequest) (int64, error) {
+	f.created = &req
+	return 1, nil
+}
+
+func (f *fakeRepo) GetRequiredCertifications(context.Context, dto.ComplianceFilters) ([]*training.RequiredCertification, error) {
+	return f.required, nil
+}
+
+func (f *fakeRepo) GetCertificationTrainings(context.Context, string, []int64) ([]*training.CertificationTraining, error) {
+	return f.trainings, nil
+}
+
+func (f *fakeRepo) GetExpiringCertificates(context.Context, string) ([]*training.ExpiringCertificate, error) {
+	return f.expiring, nil
+}
+
+func (f *fakeRepo) SetCertificateExpiryAlert(_ context.Context, id int64, alert string) error {
+	f.alerts[id] = alert
+	return nil
+}
+
+func (f *fakeRepo) CreateTraining(context.Context, dto.CreateTrainingRequest, int64) (int64, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetTrainingByID(context.Context, int64) (*training.Training, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetAllTrainings(context.Context, dto.TrainingFilters) ([]*training.Training, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) UpdateTraining(context.Context, int64, dto.UpdateTrainingRequest) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) DeleteTraining(context.Context, int64) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) AddParticipant(context.Context, int64, int64) (int64, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetParticipantByID(context.Context, int64) (*training.Participant, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetTrainingParticipants(context.Context, int64) ([]*training.Participant, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) CompleteParticipant(context.Context, int64, *int, *int64, *string) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetEmployeeTrainings(context.Context, int64) ([]*training.Training, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetEmployeeCertificates(context.Context, int64) ([]*training.Certificate, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) CreateCertificationType(context.Context, dto.CreateCertificationTypeRequest) (int64, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetAllCertificationTypes(context.Context) ([]*training.CertificationType, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) UpdateCertificationType(context.Context, int64, dto.UpdateCertificationTypeRequest) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) DeleteCertificationType(context.Context, int64) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetPositionRequirements(context.Context, *int64) ([]*training.PositionRequirement, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) SetPositionCertifications(context.Context, int64, []int64) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) CreateDevelopmentPlan(context.Context, dto.CreateDevelopmentPlanRequest, int64) (int64, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetDevelopmentPlanByID(context.Context, int64) (*training.DevelopmentPlan, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetAllDevelopmentPlans(context.Context, *int64) ([]*training.DevelopmentPlan, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) AddDevelopmentGoal(context.Context, int64, dto.AddDevelopmentGoalRequest) (int64, error) {
+	panic("not implemented")
+}
+
+type fakeNotifier struct {
+	events []notification.Event
+	fail   map[int64]bool
+}
+
+func (f *fakeNotifier) Notify(_ context.Context, e notification.Event) error {
+	if len(e.Contacts) == 1 && f.fail[e.Contacts[0]] {
+		return errors.New("smtp down")
+	}
+	f.events = append(f.events, e)
+	return nil
+}
+
+func newTestService(repo *fakeRepo, notifier *fakeNotifier, today string) *Service {
+	svc := NewService(repo, notifier, time.UTC, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	now, err := time.Parse(dateLayout, today)
+	if err != nil {
+		panic(err)
+	}
+	svc.now = func() time.Time { return now.Add(9 * time.Hour) }
+	return svc
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// required is a certification of typeID the position of employee requires,
+// held with the given certificate (nil for none)
+func required(employee int64, typeID int64, typeName string, certID *int64, expiry *string) *training.RequiredCertification {
+	return &training.RequiredCertification{
+		EmployeeID: employee, EmployeeName: map[int64]string{1: "Алиев Р.", 2: "Бобоев С.", 3: "Валиев Т."}[employee],
+		DepartmentID: 5, DepartmentName: "Машинный зал", PositionID: 7, PositionName: "Машинист",
+		CertificationTypeID: typeID, CertificationTypeName: typeName, AlertDays: 30,
+		CertificateID: certID, ExpiryDate: expiry,
+	}
+}
+
+func TestGetCompliance_Matrix(t *testing.T) {
+	repo := &fakeRepo{required: []*training.RequiredCertification{
+		required(1, 20, "Электробезопасность, IV группа", ptr(int64(100)), ptr("2026-11-10")),
+		required(1, 10, "Работы на высоте", ptr(int64(101)), nil),
+		required(2, 20, "Электробезопасность, IV группа", ptr(int64(102)), ptr("2026-10-19")),
+		required(2, 10, "Работы на высоте", nil, nil),
+	}}
+	svc := newTestService(repo, &fakeNotifier{}, "2026-10-20")
+
+	m, err := svc.GetCompliance(context.Background(), dto.ComplianceFilters{})
+	if err != nil {
+		t.Fatalf("GetCompliance: %v", err)
+	}
+
+	if len(m.Certifications) != 2 || m.Certifications[0].Name != "Работы на высоте" {
+		t.Errorf("columns = %+v, want both types by name", m.Certifications)
+	}
+	if len(m.Employees) != 2 {
+		t.Fatalf("rows = %d, want 2", len(m.Employees))
+	}
+
+	first, second := m.Employees[0], m.Employees[1]
+	if !first.Compliant || second.Compliant {
+		t.Errorf("compliant = %v, %v; want an expiring certificate to stay compliant and a gap not to", first.Compliant, second.Compliant)
+	}
+	if c := first.Certifications[0]; c.Status != training.ComplianceExpiring || c.DaysLeft == nil || *c.DaysLeft != 21 {
+		t.Errorf("certificate expiring in 21 days = %s, %v", c.Status, c.DaysLeft)
+	}
+	if c := first.Certifications[1]; c.Status != training.ComplianceValid || c.DaysLeft != nil {
+		t.Errorf("certificate valid for good = %s, %v", c.Status, c.DaysLeft)
+	}
+	if c := second.Certifications[0]; c.Status != training.ComplianceExpired || *c.DaysLeft != -1 {
+		t.Errorf("certificate expired yesterday = %s, %v", c.Status, *c.DaysLeft)
+	}
+	if c := second.Certifications[1]; c.Status != training.ComplianceMissing {
+		t.Errorf("no certificate = %s, want missing", c.Status)
+	}
+
+	want := training.ComplianceSummary{Employees: 2, Compliant: 1, Required: 4, Valid: 1, Expiring: 1, Expired: 1, Missing: 1}
+	if m.Summary != want {
+		t.Errorf("summary = %+v, want %+v", m.Summary, want)
+	}
+}
+
+func TestGetCompliance_ValidThroughExpiryDate(t *testing.T) {
+	repo := &fakeRepo{required: []*training.RequiredCertification{
+		required(1, 10, "Работы на высоте", ptr(int64(100)), ptr("2026-10-20")),
+	}}
+	svc := newTestService(repo, &fakeNotifier{}, "2026-10-20")
+
+	m, err := svc.GetCompliance(context.Background(), dto.ComplianceFilters{})
+	if err != nil {
+		t.Fatalf("GetCompliance: %v", err)
+	}
+	if c := m.Employees[0].Certifications[0]; c.Status != training.ComplianceExpiring {
+		t.Errorf("certificate on its expiry date = %s, want expiring", c.Status)
+	}
+}
+
+func TestGetEnrolmentSuggestions_UrgentGapsGetSeatsFirst(t *testing.T) {
+	repo := &fakeRepo{
+		required: []*training.RequiredCertification{
+			required(1, 10, "Работы на высоте", ptr(int64(100)), ptr("2026-11-01")),
+			required(2, 10, "Работы на высоте", nil, nil),
+			required(3, 10, "Работы на высоте", ptr(int64(101)), ptr("2026-10-01")),
+			required(3, 20, "Электробезопасность, IV группа", nil, nil),
+		},
+		trainings: []*training.CertificationTraining{
+			{ID: 50, CertificationTypeID: 10, Title: "Допуск к работам на высоте", StartDate: "2026-11-02", SeatsLeft: ptr(2)},
+			{ID: 51, CertificationTypeID: 20, Title: "Электробезопасность", StartDate: "2026-11-05", Enrolled: []int64{3}},
+		},
+	}
+	svc := newTestService(repo, &fakeNotifier{}, "2026-10-20")
+
+	got, err := svc.GetEnrolmentSuggestions(context.Background(), dto.ComplianceFilters{})
+	if err != nil {
+		t.Fatalf("GetEnrolmentSuggestions: %v", err)
+	}
+
+	// Two seats: the missing and the expired certificates take them, the
+	// expiring one waits; employee 3 is already enrolled for the other type.
+	if len(got) != 2 {
+		t.Fatalf("suggestions = %d, want 2: %+v", len(got), got)
+	}
+	for i, want := range []struct {
+		employee int64
+		status   string
+	}{{2, training.ComplianceMissing}, {3, training.ComplianceExpired}} {
+		if got[i].EmployeeID != want.employee || got[i].Status != want.status || got[i].TrainingID != 50 {
+			t.Errorf("suggestion %d = employee %d (%s) to %d; want employee %d (%s) to 50",
+				i, got[i].EmployeeID, got[i].Status, got[i].TrainingID, want.employee, want.status)
+		}
+	}
+}
+
+func TestAlertExpiring_OncePerStage(t *testing.T) {
+	repo := &fakeRepo{
+		expiring: []*training.ExpiringCertificate{
+			{ID: 100, EmployeeID: 1, CertificationTypeID: 10, CertificationTypeName: "Работы на высоте", ExpiryDate: "2026-11-09"},
+			{ID: 101, EmployeeID: 2, CertificationTypeID: 20, CertificationTypeName: "Электробезопасность", ExpiryDate: "2026-10-19", Alert: ptr(training.ComplianceExpiring)},
+			{ID: 102, EmployeeID: 3, CertificationTypeID: 20, CertificationTypeName: "Электробезопасность", ExpiryDate: "2026-10-10", Alert: ptr(training.ComplianceExpired)},
+		},
+		trainings: []*training.CertificationTraining{
+			{ID: 50, CertificationTypeID: 10, Title: "Допуск к работам на высоте", StartDate: "2026-11-02"},
+		},
+		alerts: make(map[int64]string),
+	}
+	notifier := &fakeNotifier{}
+	svc := newTestService(repo, notifier, "2026-10-20")
+
+	n, err := svc.AlertExpiring(context.Background(), "2026-10-20")
+	if err != nil {
+		t.Fatalf("AlertExpiring: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("alerted %d, want 2 (one already alerted as expired)", n)
+	}
+	if repo.alerts[100] != training.ComplianceExpiring || repo.alerts[101] != training.ComplianceExpired {
+		t.Errorf("alerts recorded = %v", repo.alerts)
+	}
+	if _, ok := repo.alerts[102]; ok {
+		t.Error("certificate alerted as expired was alerted again")
+	}
+
+	if len(notifier.events) != 3 {
+		t.Fatalf("events = %d, want two holders and HR", len(notifier.events))
+	}
+	first := notifier.events[0]
+	if first.Type != notification.CertificationExpiring || !strings.Contains(first.Message, "через 20 дн.") ||
+		!strings.Contains(first.Message, "Ближайшее обучение: «Допуск к работам на высоте» с 02.11.2026") {
+		t.Errorf("expiring alert = %s: %q", first.Type, first.Message)
+	}
+	if notifier.events[1].Type != notification.CertificationExpired {
+		t.Errorf("second alert = %s, want expired", notifier.events[1].Type)
+	}
+	hr := notifier.events[2]
+	if len(hr.Roles) != 1 || hr.Roles[0] != "hrm_admin" || hr.Type != notification.CertificationExpired ||
+		hr.Message != "Истекает удостоверений: 1, истекло: 1." {
+		t.Errorf("HR summary = %+v", hr)
+	}
+}
+
+func TestAlertExpiring_RetriesFailedAlert(t *testing.T) {
+	repo := &fakeRepo{
+		expiring: []*training.ExpiringCertificate{
+			{ID: 100, EmployeeID: 1, CertificationTypeID: 10, CertificationTypeName: "Работы на высоте", ExpiryDate: "2026-11-09"},
+		},
+		alerts: make(map[int64]string),
+	}
+	notifier := &fakeNotifier{fail: map[int64]bool{1: true}}
+	svc := newTestService(repo, notifier, "2026-10-20")
+
+	n, err := svc.AlertExpiring(context.Background(), "2026-10-20")
+	if err != nil {
+		t.Fatalf("AlertExpiring: %v", err)
+	}
+	if n != 0 || len(repo.alerts) != 0 || len(notifier.events) != 0 {
+		t.Errorf("failed alert: alerted %d, recorded %v, events %d; want nothing so it is retried", n, repo.alerts, len(notifier.events))
+	}
+}
+
+func TestCreateCertificate_ExpiryFromValidity(t *testing.T) {
+	repo := &fakeRepo{types: map[int64]*training.CertificationType{
+		10: {ID: 10, Name: "Работы на высоте", ValidityMonths: ptr(12)},
+		20: {ID: 20, Name: "Первая помощь"},
+	}}
+	svc := newTestService(repo, &fakeNotifier{}, "2026-10-20")
+
+	if _, err := svc.CreateCertificate(context.Background(), dto.CreateCertificateRequest{
+		EmployeeID: 1, CertificationTypeID: ptr(int64(10)), IssueDate: "2026-03-01",
+	}); err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	if c := repo.created; c.Title != "Работы на высоте" || c.ExpiryDate == nil || *c.ExpiryDate != "2027-02-28" {
+		t.Errorf("certificate %q expires %v, want titled after the type and valid through 2027-02-28", c.Title, c.ExpiryDate)
+	}
+
+	if _, err := svc.CreateCertificate(context.Background(), dto.CreateCertificateRequest{
+		EmployeeID: 1, CertificationTypeID: ptr(int64(20)), Title: "Оказание первой помощи", IssueDate: "2026-03-01",
+	}); err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	if c := repo.created; c.Title != "Оказание первой помощи" || c.ExpiryDate != nil {
+		t.Errorf("certificate %q expires %v, want the given title, valid for good", c.Title, c.ExpiryDate)
+	}
+
+	_, err := svc.CreateCertificate(context.Background(), dto.CreateCertificateRequest{
+		EmployeeID: 1, Title: "Курс", IssueDate: "2026-03-01", ExpiryDate: ptr("2026-02-01"),
+	})
+	if !errors.Is(err, storage.ErrInvalidDateRange) {
+		t.Errorf("expiry before issue: err = %v, want ErrInvalidDateRange", err)
+	}
+}
@@ -6,10 +6,13 @@ import (
 	"log/slog"
 	"srmt-admin/internal/lib/dto"
 	"srmt-admin/internal/lib/model/hrm/training"
+	"srmt-admin/internal/lib/service/notification"
 	"srmt-admin/internal/storage"
 	"time"
 )
 
+const dateLayout = "2006-01-02"
+
 type RepoInterface interface {
 	// Trainings
 	CreateTraining(ctx context.Context, req dto.CreateTrainingRequest, createdBy int64) (int64, error)
@@ -26,9 +29,26 @@ type RepoInterface interface {
 	GetEmployeeTrainings(ctx context.Context, employeeID int64) ([]*training.Training, error)
 
 	// Certificates
-	CreateCertificate(ctx context.Context, employeeID int64, trainingID *int64, title string, issuer *string, issueDate string) (int64, error)
+	CreateCertificate(ctx context.Context, req dto.CreateCertificateRequest) (int64, error)
 	GetEmployeeCertificates(ctx context.Context, employeeID int64) ([]*training.Certificate, error)
 
+	// Certification Types
+	CreateCertificationType(ctx context.Context, req dto.CreateCertificationTypeRequest) (int64, error)
+	GetCertificationTypeByID(ctx context.Context, id int64) (*training.CertificationType, error)
+	GetAllCertificationTypes(ctx context.Context) ([]*training.CertificationType, error)
+	UpdateCertificationType(ctx context.Context, id int64, req dto.UpdateCertificationTypeRequest) error
+	DeleteCertificationType(ctx context.Context, id int64) error
+
+	// Position Requirements
+	GetPositionRequirements(ctx context.Context, positionID *int64) ([]*training.PositionRequirement, error)
+	SetPositionCertifications(ctx context.Context, positionID int64, typeIDs []int64) error
+
+	// Compliance
+	GetRequiredCertifications(ctx context.Context, filters dto.ComplianceFilters) ([]*training.RequiredCertification, error)
+	GetCertificationTrainings(ctx context.Context, from string, typeIDs []int64) ([]*training.CertificationTraining, error)
+	GetExpiringCertificates(ctx context.Context, date string) ([]*training.ExpiringCertificate, error)
+	SetCertificateExpiryAlert(ctx context.Context, id int64, alert string) error
+
 	// Development Plans
 	CreateDevelopmentPlan(ctx context.Context, req dto.CreateDevelopmentPlanRequest, createdBy int64) (int64, error)
 	GetDevelopmentPlanByID(ctx context.Context, id int64) (*training.DevelopmentPlan, error)
@@ -38,13 +58,21 @@ type RepoInterface interface {
 	AddDevelopmentGoal(ctx context.Context, planID int64, req dto.AddDevelopmentGoalRequest) (int64, error)
 }
 
+// Notifier alerts employees and HR about expiring certifications
+type Notifier interface {
+	Notify(ctx context.Context, e notification.Event) error
+}
+
 type Service struct {
-	repo RepoInterface
-	log  *slog.Logger
+	repo     RepoInterface
+	notifier Notifier
+	loc      *time.Location
+	now      func() time.Time
+	log      *slog.Logger
 }
 
-func NewService(repo RepoInterface, log *slog.Logger) *Service {
-	return &Service{repo: repo, log: log}
+func NewService(repo RepoInterface, notifier Notifier, loc *time.Location, log *slog.Logger) *Service {
+	return &Service{repo: repo, notifier: notifier, loc: loc, now: time.Now, log: log}
 }
 
 // ==================== Trainings ====================
@@ -128,9 +156,15 @@ func (s *Service) CompleteParticipant(ctx context.Context, participantID int64,
 		return err
 	}
 
-	// Create certificate
-	today := time.Now().Format("2006-01-02")
-	certID, err := s.repo.CreateCertificate(ctx, p.EmployeeID, &p.TrainingID, tr.Title, tr.Provider, today)
+	// Create certificate, of the certification the training grants if any
+	certID, err := s.CreateCertificate(ctx, dto.CreateCertificateRequest{
+		EmployeeID:          p.EmployeeID,
+		TrainingID:          &p.TrainingID,
+		CertificationTypeID: tr.CertificationTypeID,
+		Title:               tr.Title,
+		Issuer:              tr.Provider,
+		IssueDate:           s.today(),
+	})
 	if err != nil {
 		s.log.Error("failed to create certificate", "error", err, "participant_id", participantID)
 		// Still complete participant even if certificate creation fails
@@ -164,6 +198,77 @@ func (s *Service) GetEmployeeCertificates(ctx context.Context, employeeID int64)
 	return certs, nil
 }
 
+// ==================== Certificates ====================
+
+// CreateCertificate records a certificate. One of a certification type is
+// titled after the type unless given a title, and unless given an expiry
+// date is valid for the validity period of the type from the issue date.
+func (s *Service) CreateCertificate(ctx context.Context, req dto.CreateCertificateRequest) (int64, error) {
+	if req.CertificationTypeID != nil {
+		ct, err := s.repo.GetCertificationTypeByID(ctx, *req.CertificationTypeID)
+		if err != nil {
+			return 0, err
+		}
+		if req.Title == "" {
+			req.Title = ct.Name
+		}
+		if req.ExpiryDate == nil && ct.ValidityMonths != nil {
+			issued, err := time.Parse(dateLayout, req.IssueDate)
+			if err != nil {
+				return 0, storage.ErrInvalidDateRange
+			}
+			expiry := issued.AddDate(0, *ct.ValidityMonths, -1).Format(dateLayout)
+			req.ExpiryDate = &expiry
+		}
+	}
+	if req.ExpiryDate != nil && *req.ExpiryDate < req.IssueDate {
+		return 0, storage.ErrInvalidDateRange
+	}
+	return s.repo.CreateCertificate(ctx, req)
+}
+
+// ==================== Certification Types ====================
+
+func (s *Service) CreateCertificationType(ctx context.Context, req dto.CreateCertificationTypeRequest) (int64, error) {
+	return s.repo.CreateCertificationType(ctx, req)
+}
+
+func (s *Service) GetAllCertificationTypes(ctx context.Context) ([]*training.CertificationType, error) {
+	types, err := s.repo.GetAllCertificationTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if types == nil {
+		types = []*training.CertificationType{}
+	}
+	return types, nil
+}
+
+func (s *Service) UpdateCertificationType(ctx context.Context, id int64, req dto.UpdateCertificationTypeRequest) error {
+	return s.repo.UpdateCertificationType(ctx, id, req)
+}
+
+func (s *Service) DeleteCertificationType(ctx context.Context, id int64) error {
+	return s.repo.DeleteCertificationType(ctx, id)
+}
+
+// ==================== Position Requirements ====================
+
+func (s *Service) GetPositionRequirements(ctx context.Context, positionID *int64) ([]*training.PositionRequirement, error) {
+	reqs, err := s.repo.GetPositionRequirements(ctx, positionID)
+	if err != nil {
+		return nil, err
+	}
+	if reqs == nil {
+		reqs = []*training.PositionRequirement{}
+	}
+	return reqs, nil
+}
+
+func (s *Service) SetPositionCertifications(ctx context.Context, positionID int64, req dto.SetPositionCertificationsRequest) error {
+	return s.repo.SetPositionCertifications(ctx, positionID, req.CertificationTypeIDs)
+}
+
 // ==================== Development Plans ====================
 
 func (s *Service) CreateDevelopmentPlan(ctx context.Context, req dto.CreateDevelopmentPlanRequest, createdBy int64) (int64, error) {
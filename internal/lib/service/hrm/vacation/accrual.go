@@ -0,0 +1,480 @@
+package vacation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/vacation"
+	"srmt-admin/internal/storage"
+	"time"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	// accrualHour is when the nightly accrual runs, local time
+	accrualHour = 2
+
+	// DefaultAnnualDays is the yearly entitlement when no rule applies: the
+	// Labour Code minimum
+	DefaultAnnualDays = 15
+)
+
+// AccrueMonth writes a month's accrual for everyone on staff during it,
+// replacing what an earlier run wrote for the month. A twelfth of the yearly
+// entitlement, and of the hazard days for work with a hazard allowance, is
+// prorated by the days on staff in the month.
+func (s *Service) AccrueMonth(ctx context.Context, year, month int) (*vacation.AccrualResult, error) {
+	first := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1)
+	if !last.Before(s.today()) {
+		return nil, storage.ErrAccrualPeriodNotOver
+	}
+
+	closed, err := s.repo.IsVacationYearClosed(ctx, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check year closing: %w", err)
+	}
+	if closed {
+		return nil, storage.ErrVacationYearClosed
+	}
+
+	policy, err := s.repo.GetLeavePolicy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leave policy: %w", err)
+	}
+	rules, err := s.repo.GetEntitlementRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entitlement rules: %w", err)
+	}
+	employees, err := s.repo.GetAccrualEmployees(ctx, first.Format(dateLayout), last.Format(dateLayout), year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get employees: %w", err)
+	}
+
+	res := &vacation.AccrualResult{Year: year, Month: month}
+	var entries []*vacation.LedgerEntry
+	for _, e := range employees {
+		earned, ok := s.monthEntries(e, rules, policy, first, last)
+		if !ok {
+			continue
+		}
+		res.Employees++
+		for _, en := range earned {
+			res.Days += en.Days
+		}
+		entries = append(entries, earned...)
+	}
+	res.Days = round2(res.Days)
+
+	if err := s.repo.ReplaceMonthAccruals(ctx, last.Format(dateLayout), year, entries); err != nil {
+		return nil, fmt.Errorf("failed to write accruals: %w", err)
+	}
+	return res, nil
+}
+
+// CloseYear accrues December and closes the year: what every employee has
+// left, net of used and pending days, moves to the next year up to the
+// carry-over cap and the rest expires. Overdrawn days move over in full.
+func (s *Service) CloseYear(ctx context.Context, year int, closedBy *int64) (*vacation.ClosingResult, error) {
+	if _, err := s.AccrueMonth(ctx, year, 12); err != nil {
+		return nil, err
+	}
+
+	policy, err := s.repo.GetLeavePolicy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leave policy: %w", err)
+	}
+	totals, err := s.repo.GetLedgerTotals(ctx, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger totals: %w", err)
+	}
+	balances, err := s.repo.GetAllVacationBalances(ctx, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balances: %w", err)
+	}
+	committed := make(map[int64]int, len(balances))
+	for _, b := range balances {
+		committed[b.EmployeeID] = b.UsedDays + b.PendingDays
+	}
+
+	ids := make([]int64, 0, len(totals))
+	for id := range totals {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	yearEnd := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	nextYearStart := yearEnd.AddDate(0, 0, 1)
+
+	res := &vacation.ClosingResult{Year: year}
+	var entries []*vacation.LedgerEntry
+	for _, id := range ids {
+		remaining := round2(totals[id] - float64(committed[id]))
+		if remaining == 0 {
+			continue
+		}
+		res.Employees++
+
+		carry, expired := carryOver(remaining, policy.CarryOverCap)
+		if carry != 0 {
+			entries = append(entries,
+				s.entry(id, year, yearEnd, vacation.LedgerCarriedForward, -carry, fmt.Sprintf("Перенос на %d год", year+1)),
+				s.entry(id, year+1, nextYearStart, vacation.LedgerCarryOver, carry, fmt.Sprintf("Перенос с %d года", year)))
+			res.CarriedOver += carry
+		}
+		if expired != 0 {
+			note := fmt.Sprintf("Сверх лимита переноса %d дн.", policy.CarryOverCap)
+			entries = append(entries, s.entry(id, year, yearEnd, vacation.LedgerExpiry, -expired, note))
+			res.Expired += expired
+		}
+	}
+	res.CarriedOver = round2(res.CarriedOver)
+	res.Expired = round2(res.Expired)
+
+	if err := s.repo.CloseVacationYear(ctx, year, entries, closedBy); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetLedger lists what makes up an employee's balance for the year: the
+// stored accruals, carry-overs and corrections, and the vacations taken or
+// requested, with the running balance after each
+func (s *Service) GetLedger(ctx context.Context, employeeID int64, year int) (*vacation.Ledger, error) {
+	stored, err := s.repo.GetLedgerEntries(ctx, employeeID, year)
+	if err != nil {
+		return nil, err
+	}
+	vacations, err := s.repo.GetLedgerVacations(ctx, employeeID, year, balanceTypes())
+	if err != nil {
+		return nil, err
+	}
+	closed, err := s.repo.IsVacationYearClosed(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stored rows come before vacations of the same day
+	entries := append(stored, vacations...)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Date < entries[j].Date })
+
+	ledger := &vacation.Ledger{EmployeeID: employeeID, Year: year, YearClosed: closed, Entries: entries}
+	var balance float64
+	for _, e := range entries {
+		balance = round2(balance + e.Days)
+		e.Balance = balance
+		switch e.Kind {
+		case vacation.LedgerCarryOver:
+			ledger.CarriedOver += e.Days
+		case vacation.LedgerUsage:
+			ledger.Used -= e.Days
+		case vacation.LedgerPending:
+			ledger.Pending -= e.Days
+		case vacation.LedgerCarriedForward, vacation.LedgerExpiry:
+			ledger.Closed -= e.Days
+		default:
+			ledger.Earned += e.Days
+		}
+	}
+	ledger.Earned = round2(ledger.Earned)
+	ledger.CarriedOver = round2(ledger.CarriedOver)
+	ledger.Closed = round2(ledger.Closed)
+	ledger.Remaining = balance
+	if ledger.Entries == nil {
+		ledger.Entries = []*vacation.LedgerEntry{}
+	}
+	return ledger, nil
+}
+
+// UnusedDays is what an employee leaving on lastDay (YYYY-MM-DD) has left of
+// vacation: the balance of the year, with the accrual of the last month,
+// which is written only once the month is over, worked out up to lastDay.
+// Overdrawn days come out negative.
+func (s *Service) UnusedDays(ctx context.Context, employeeID int64, lastDay string) (float64, error) {
+	day, err := time.Parse(dateLayout, lastDay)
+	if err != nil {
+		return 0, storage.ErrInvalidDateRange
+	}
+	ledger, err := s.GetLedger(ctx, employeeID, day.Year())
+	if err != nil {
+		return 0, err
+	}
+
+	first := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1)
+	for _, e := range ledger.Entries {
+		if e.Date == last.Format(dateLayout) && (e.Kind == vacation.LedgerAccrual || e.Kind == vacation.LedgerHazard) {
+			return ledger.Remaining, nil
+		}
+	}
+
+	policy, err := s.repo.GetLeavePolicy(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get leave policy: %w", err)
+	}
+	rules, err := s.repo.GetEntitlementRules(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get entitlement rules: %w", err)
+	}
+	employees, err := s.repo.GetAccrualEmployees(ctx, first.Format(dateLayout), last.Format(dateLayout), day.Year())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get employees: %w", err)
+	}
+
+	remaining := ledger.Remaining
+	for _, e := range employees {
+		if e.EmployeeID != employeeID {
+			continue
+		}
+		// The employee is on staff to lastDay, whatever the record says
+		e.DismissalDate = &lastDay
+		earned, _ := s.monthEntries(e, rules, policy, first, last)
+		for _, en := range earned {
+			remaining += en.Days
+		}
+	}
+	return round2(remaining), nil
+}
+
+// AddAdjustment records a manual correction of an employee's balance in the
+// year of its date
+func (s *Service) AddAdjustment(ctx context.Context, employeeID int64, req dto.CreateLedgerAdjustmentRequest, createdBy int64) (int64, error) {
+	date, err := time.Parse(dateLayout, req.Date)
+	if err != nil {
+		return 0, storage.ErrInvalidDateRange
+	}
+	closed, err := s.repo.IsVacationYearClosed(ctx, date.Year())
+	if err != nil {
+		return 0, fmt.Errorf("failed to check year closing: %w", err)
+	}
+	if closed {
+		return 0, storage.ErrVacationYearClosed
+	}
+	return s.repo.AddLedgerAdjustment(ctx, employeeID, date.Year(), req, createdBy)
+}
+
+func (s *Service) GetPolicy(ctx context.Context) (*vacation.Policy, error) {
+	return s.repo.GetLeavePolicy(ctx)
+}
+
+func (s *Service) UpdatePolicy(ctx context.Context, req dto.UpdateLeavePolicyRequest, updatedBy int64) error {
+	return s.repo.UpdateLeavePolicy(ctx, req, updatedBy)
+}
+
+func (s *Service) GetEntitlementRules(ctx context.Context) ([]*vacation.EntitlementRule, error) {
+	return s.repo.GetEntitlementRules(ctx)
+}
+
+func (s *Service) CreateEntitlementRule(ctx context.Context, req dto.CreateEntitlementRuleRequest) (int64, error) {
+	return s.repo.CreateEntitlementRule(ctx, req)
+}
+
+func (s *Service) DeleteEntitlementRule(ctx context.Context, id int64) error {
+	return s.repo.DeleteEntitlementRule(ctx, id)
+}
+
+// StartScheduler accrues the month just ended once a night, and in January
+// closes the year before. Runs repeat harmlessly. Blocks until ctx is
+// cancelled.
+func (s *Service) StartScheduler(ctx context.Context) {
+	for {
+		now := s.now().In(s.loc)
+		next := time.Date(now.Year(), now.Month(), now.Day(), accrualHour, 0, 0, 0, s.loc)
+		if !next.After(now) {
+			next = next.Add(24 * time.Hour)
+		}
+		wait := next.Sub(now)
+
+		s.log.Info("next vacation accrual scheduled",
+			slog.String("run_at", next.Format(time.RFC3339)),
+			slog.Duration("in", wait))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.log.Info("vacation accrual scheduler stopped")
+			return
+		case <-timer.C:
+			s.runAccrual(ctx, next)
+		}
+	}
+}
+
+// runAccrual accrues the month before at, or closes the year before in
+// January
+func (s *Service) runAccrual(ctx context.Context, at time.Time) {
+	prev := time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+
+	closed, err := s.repo.IsVacationYearClosed(ctx, prev.Year())
+	if err != nil {
+		s.log.Error("failed to check vacation year closing", "error", err, "year", prev.Year())
+		return
+	}
+	if closed {
+		return
+	}
+
+	if prev.Month() == time.December {
+		res, err := s.CloseYear(ctx, prev.Year(), nil)
+		if err != nil {
+			s.log.Error("failed to close vacation year", "error", err, "year", prev.Year())
+			return
+		}
+		s.log.Info("vacation year closed", "year", res.Year, "employees", res.Employees,
+			"carried_over", res.CarriedOver, "expired", res.Expired)
+		return
+	}
+
+	res, err := s.AccrueMonth(ctx, prev.Year(), int(prev.Month()))
+	if err != nil {
+		s.log.Error("failed to accrue vacation days", "error", err, "year", prev.Year(), "month", int(prev.Month()))
+		return
+	}
+	s.log.Info("vacation days accrued", "year", res.Year, "month", res.Month,
+		"employees", res.Employees, "days", res.Days)
+}
+
+// monthEntries works out what e earns in the month from first to last. ok is
+// false when e was not on staff in the month.
+func (s *Service) monthEntries(e *vacation.AccrualEmployee, rules []*vacation.EntitlementRule, policy *vacation.Policy, first, last time.Time) ([]*vacation.LedgerEntry, bool) {
+	hire, err := time.Parse(dateLayout, e.HireDate)
+	if err != nil {
+		s.log.Warn("skipping employee with invalid hire date", "employee_id", e.EmployeeID, "hire_date", e.HireDate)
+		return nil, false
+	}
+	var dismissal *time.Time
+	if e.DismissalDate != nil {
+		if d, err := time.Parse(dateLayout, *e.DismissalDate); err == nil {
+			dismissal = &d
+		}
+	}
+
+	share := servedShare(hire, dismissal, first, last)
+	if share == 0 {
+		return nil, false
+	}
+
+	var entries []*vacation.LedgerEntry
+	seniority := seniorityYears(hire, last)
+	annual := entitlement(rules, e.PositionID, seniority)
+	days := round2(float64(annual) / 12 * share)
+	if days > 0 {
+		note := fmt.Sprintf("%d дн./год, стаж %d л.", annual, seniority)
+		entries = append(entries, s.entry(e.EmployeeID, first.Year(), last, vacation.LedgerAccrual, days, note))
+	}
+
+	if e.Hazard && policy.HazardDays > 0 {
+		days := round2(float64(policy.HazardDays) / 12 * share)
+		note := fmt.Sprintf("%d дн./год за вредные условия труда", policy.HazardDays)
+		entries = append(entries, s.entry(e.EmployeeID, first.Year(), last, vacation.LedgerHazard, days, note))
+	}
+	return entries, true
+}
+
+func (s *Service) today() time.Time {
+	now := s.now()
+	if s.loc != nil {
+		now = now.In(s.loc)
+	}
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (s *Service) entry(employeeID int64, year int, date time.Time, kind string, days float64, note string) *vacation.LedgerEntry {
+	return &vacation.LedgerEntry{
+		EmployeeID: employeeID,
+		Year:       year,
+		Date:       date.Format(dateLayout),
+		Kind:       kind,
+		Days:       days,
+		Note:       &note,
+	}
+}
+
+// servedShare is the share of the month from first to last the employee was
+// on staff, hire and dismissal days included
+func servedShare(hire time.Time, dismissal *time.Time, first, last time.Time) float64 {
+	from, to := first, last
+	if hire.After(from) {
+		from = hire
+	}
+	if dismissal != nil && dismissal.Before(to) {
+		to = *dismissal
+	}
+	if to.Before(from) {
+		return 0
+	}
+	served := int(to.Sub(from).Hours()/24) + 1
+	total := int(last.Sub(first).Hours()/24) + 1
+	return float64(served) / float64(total)
+}
+
+// seniorityYears counts the full years of service from hire to at
+func seniorityYears(hire, at time.Time) int {
+	years := at.Year() - hire.Year()
+	if at.Month() < hire.Month() || (at.Month() == hire.Month() && at.Day() < hire.Day()) {
+		years--
+	}
+	if years < 0 {
+		return 0
+	}
+	return years
+}
+
+// entitlement picks the yearly days for a position and seniority: the rule of
+// the position with the highest seniority reached, else such a rule for all
+// positions, else DefaultAnnualDays
+func entitlement(rules []*vacation.EntitlementRule, positionID int64, seniority int) int {
+	var own, general *vacation.EntitlementRule
+	for _, r := range rules {
+		if r.MinSeniorityYears > seniority {
+			continue
+		}
+		switch {
+		case r.PositionID != nil && *r.PositionID == positionID:
+			if own == nil || r.MinSeniorityYears > own.MinSeniorityYears {
+				own = r
+			}
+		case r.PositionID == nil:
+			if general == nil || r.MinSeniorityYears > general.MinSeniorityYears {
+				general = r
+			}
+		}
+	}
+	switch {
+	case own != nil:
+		return own.AnnualDays
+	case general != nil:
+		return general.AnnualDays
+	default:
+		return DefaultAnnualDays
+	}
+}
+
+// carryOver splits the days left at year end into those moving to the next
+// year, whole days up to the cap, and those that expire
+func carryOver(remaining float64, limit int) (carry, expired float64) {
+	if remaining < 0 {
+		return remaining, 0
+	}
+	carry = math.Min(math.Floor(remaining), float64(limit))
+	return carry, round2(remaining - carry)
+}
+
+// balanceTypes lists the vacation types drawn from the balance
+func balanceTypes() []string {
+	types := make([]string, 0, len(BalanceRequiredTypes))
+	for t := range BalanceRequiredTypes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
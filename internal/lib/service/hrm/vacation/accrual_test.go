@@ -0,0 +1,327 @@
+package vacation
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/vacation"
+	"srmt-admin/internal/storage"
+)
+
+// fakeRepo keeps the accrual data in memory. Vacation requests, approvals
+// and policy edits are outside these tests, so those methods panic.
+type fakeRepo struct {
+	policy    vacation.Policy
+	rules     []*vacation.EntitlementRule
+	employees []*vacation.AccrualEmployee
+	closed    map[int]bool
+	totals    map[int64]float64
+	balances  []*vacation.Balance
+	stored    []*vacation.LedgerEntry
+	vacations []*vacation.LedgerEntry
+
+	replacedMonth string
+	accruals      []*vacation.LedgerEntry
+	closing       []*vacation.LedgerEntry
+}
+
+func (f *fakeRepo) GetLeavePolicy(context.Context) (*vacation.Policy, error) {
+	return &f.policy, nil
+}
+
+func (f *fakeRepo) GetEntitlementRules(context.Context) ([]*vacation.EntitlementRule, error) {
+	return f.rules, nil
+}
+
+func (f *fakeRepo) GetAccrualEmployees(context.Context, string, string, int) ([]*vacation.AccrualEmployee, error) {
+	return f.employees, nil
+}
+
+func (f *fakeRepo) IsVacationYearClosed(_ context.Context, year int) (bool, error) {
+	return f.closed[year], nil
+}
+
+func (f *fakeRepo) ReplaceMonthAccruals(_ context.Context, monthEnd string, _ int, entries []*vacation.LedgerEntry) error {
+	f.replacedMonth = monthEnd
+	f.accruals = entries
+	return nil
+}
+
+func (f *fakeRepo) GetLedgerTotals(context.Context, int) (map[int64]float64, error) {
+	return f.totals, nil
+}
+
+func (f *fakeRepo) GetAllVacationBalances(context.Context, int) ([]*vacation.Balance, error) {
+	return f.balances, nil
+}
+
+func (f *fakeRepo) CloseVacationYear(_ context.Context, year int, entries []*vacation.LedgerEntry, _ *int64) error {
+	f.closed[year] = true
+	f.closing = entries
+	return nil
+}
+
+func (f *fakeRepo) GetLedgerEntries(context.Context, int64, int) ([]*vacation.LedgerEntry, error) {
+	return f.stored, nil
+}
+
+func (f *fakeRepo) GetLedgerVacations(context.Context, int64, int, []string) ([]*vacation.LedgerEntry, error) {
+	return f.vacations, nil
+}
+
+func (f *fakeRepo) CreateVacation(context.Context, dto.CreateVacationRequest, int, int64) (int64, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetVacationByID(context.Context, int64) (*vacation.Vacation, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetAllVacations(context.Context, dto.VacationFilters) ([]*vacation.Vacation, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) UpdateVacation(context.Context, int64, dto.EditVacationRequest, *int) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) DeleteVacation(context.Context, int64) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) UpdateVacationStatus(context.Context, int64, string) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) ApproveVacation(context.Context, int64, int64) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) RejectVacation(context.Context, int64, int64, string) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) CheckVacationOverlap(context.Context, int64, string, string, *int64) (bool, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) CheckBlockedPeriod(context.Context, int64, string, string) (bool, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetVacationBalance(context.Context, int64, int) (*vacation.Balance, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetPendingVacations(context.Context) ([]*vacation.Vacation, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetVacationCalendar(context.Context, dto.VacationCalendarFilters) ([]*vacation.CalendarEntry, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) UpdateVacationBalancePending(context.Context, int64, int, int) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) UpdateVacationBalanceApprove(context.Context, int64, int, int) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) UpdateVacationBalanceReject(context.Context, int64, int, int) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) UpdateVacationBalanceCancelApproved(context.Context, int64, int, int) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) GetEmployeeDepartmentID(context.Context, int64) (int64, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) UpdateLeavePolicy(context.Context, dto.UpdateLeavePolicyRequest, int64) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) CreateEntitlementRule(context.Context, dto.CreateEntitlementRuleRequest) (int64, error) {
+	panic("not implemented")
+}
+func (f *fakeRepo) DeleteEntitlementRule(context.Context, int64) error {
+	panic("not implemented")
+}
+func (f *fakeRepo) AddLedgerAdjustment(context.Context, int64, int, dto.CreateLedgerAdjustmentRequest, int64) (int64, error) {
+	panic("not implemented")
+}
+
+func newTestService(repo *fakeRepo, today string) *Service {
+	svc := NewService(repo, nil, nil, nil, time.UTC, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	now, err := time.Parse(dateLayout, today)
+	if err != nil {
+		panic(err)
+	}
+	svc.now = func() time.Time { return now.Add(12 * time.Hour) }
+	return svc
+}
+
+func strPtr(s string) *string { return &s }
+func int64Ptr(i int64) *int64 { return &i }
+
+func daysOf(entries []*vacation.LedgerEntry, employeeID int64, kind string) float64 {
+	var days float64
+	for _, e := range entries {
+		if e.EmployeeID == employeeID && e.Kind == kind {
+			days += e.Days
+		}
+	}
+	return days
+}
+
+func TestAccrueMonth_ProratesByServiceAndAddsHazardDays(t *testing.T) {
+	repo := &fakeRepo{
+		policy: vacation.Policy{HazardDays: 6, CarryOverCap: 15},
+		rules: []*vacation.EntitlementRule{
+			{PositionID: nil, MinSeniorityYears: 0, AnnualDays: 15},
+			{PositionID: int64Ptr(1), MinSeniorityYears: 0, AnnualDays: 18},
+			{PositionID: int64Ptr(1), MinSeniorityYears: 5, AnnualDays: 24},
+		},
+		employees: []*vacation.AccrualEmployee{
+			{EmployeeID: 10, PositionID: 1, HireDate: "2020-01-01", Hazard: true},                        // full month, 6 years
+			{EmployeeID: 11, PositionID: 2, HireDate: "2026-03-16"},                                      // hired mid-month
+			{EmployeeID: 12, PositionID: 2, HireDate: "2025-05-01", DismissalDate: strPtr("2026-03-10")}, // dismissed mid-month
+			{EmployeeID: 13, PositionID: 1, HireDate: "2023-06-01", DismissalDate: strPtr("2026-02-27")}, // gone before March
+		},
+		closed: map[int]bool{},
+	}
+	svc := newTestService(repo, "2026-04-10")
+
+	res, err := svc.AccrueMonth(context.Background(), 2026, 3)
+	if err != nil {
+		t.Fatalf("AccrueMonth: %v", err)
+	}
+	if repo.replacedMonth != "2026-03-31" {
+		t.Errorf("accruals dated %q, want 2026-03-31", repo.replacedMonth)
+	}
+
+	cases := []struct {
+		employee int64
+		kind     string
+		days     float64
+	}{
+		{10, vacation.LedgerAccrual, 2},    // 24 / 12
+		{10, vacation.LedgerHazard, 0.5},   // 6 / 12
+		{11, vacation.LedgerAccrual, 0.65}, // 15 / 12 * 16 / 31
+		{11, vacation.LedgerHazard, 0},
+		{12, vacation.LedgerAccrual, 0.4}, // 15 / 12 * 10 / 31
+		{13, vacation.LedgerAccrual, 0},
+	}
+	for _, c := range cases {
+		if got := daysOf(repo.accruals, c.employee, c.kind); got != c.days {
+			t.Errorf("employee %d %s = %v, want %v", c.employee, c.kind, got, c.days)
+		}
+	}
+	if res.Employees != 3 || res.Days != 3.55 {
+		t.Errorf("result = %+v, want 3 employees and 3.55 days", res)
+	}
+}
+
+func TestAccrueMonth_RefusesOpenMonthAndClosedYear(t *testing.T) {
+	repo := &fakeRepo{closed: map[int]bool{2025: true}}
+	svc := newTestService(repo, "2026-03-31")
+
+	if _, err := svc.AccrueMonth(context.Background(), 2026, 3); !errors.Is(err, storage.ErrAccrualPeriodNotOver) {
+		t.Errorf("month not over: err = %v, want ErrAccrualPeriodNotOver", err)
+	}
+	if _, err := svc.AccrueMonth(context.Background(), 2025, 11); !errors.Is(err, storage.ErrVacationYearClosed) {
+		t.Errorf("closed year: err = %v, want ErrVacationYearClosed", err)
+	}
+	if repo.accruals != nil {
+		t.Error("accruals were written")
+	}
+}
+
+func TestCloseYear_CapsCarryOverAndExpiresTheRest(t *testing.T) {
+	repo := &fakeRepo{
+		policy: vacation.Policy{CarryOverCap: 15},
+		closed: map[int]bool{},
+		totals: map[int64]float64{10: 20.5, 11: 5, 12: 9.75, 13: 4},
+		balances: []*vacation.Balance{
+			{EmployeeID: 10, UsedDays: 3},
+			{EmployeeID: 11, UsedDays: 8},                 // overdrawn
+			{EmployeeID: 12, UsedDays: 2},                 // under the cap
+			{EmployeeID: 13, UsedDays: 2, PendingDays: 2}, // nothing left
+		},
+	}
+	svc := newTestService(repo, "2027-01-01")
+
+	res, err := svc.CloseYear(context.Background(), 2026, nil)
+	if err != nil {
+		t.Fatalf("CloseYear: %v", err)
+	}
+	if repo.replacedMonth != "2026-12-31" {
+		t.Errorf("December was not accrued before closing")
+	}
+
+	cases := []struct {
+		employee       int64
+		carry, expired float64
+	}{
+		{10, 15, 2.5},
+		{11, -3, 0},
+		{12, 7, 0.75},
+		{13, 0, 0},
+	}
+	for _, c := range cases {
+		if got := daysOf(repo.closing, c.employee, vacation.LedgerCarryOver); got != c.carry {
+			t.Errorf("employee %d carried over %v, want %v", c.employee, got, c.carry)
+		}
+		if got := daysOf(repo.closing, c.employee, vacation.LedgerCarriedForward); got != -c.carry {
+			t.Errorf("employee %d carried forward %v, want %v", c.employee, got, -c.carry)
+		}
+		if got := daysOf(repo.closing, c.employee, vacation.LedgerExpiry); got != -c.expired {
+			t.Errorf("employee %d expired %v, want %v", c.employee, got, -c.expired)
+		}
+	}
+	for _, e := range repo.closing {
+		want := 2026
+		if e.Kind == vacation.LedgerCarryOver {
+			want = 2027
+		}
+		if e.Year != want {
+			t.Errorf("%s entry of employee %d in %d, want %d", e.Kind, e.EmployeeID, e.Year, want)
+		}
+	}
+	if res.Employees != 3 || res.CarriedOver != 19 || res.Expired != 3.25 {
+		t.Errorf("result = %+v, want 3 employees, 19 carried over, 3.25 expired", res)
+	}
+
+	if _, err := svc.CloseYear(context.Background(), 2026, nil); !errors.Is(err, storage.ErrVacationYearClosed) {
+		t.Errorf("second closing: err = %v, want ErrVacationYearClosed", err)
+	}
+}
+
+func TestGetLedger_ExplainsTheBalance(t *testing.T) {
+	repo := &fakeRepo{
+		closed: map[int]bool{},
+		stored: []*vacation.LedgerEntry{
+			{Date: "2026-01-01", Kind: vacation.LedgerCarryOver, Days: 4},
+			{Date: "2026-01-31", Kind: vacation.LedgerAccrual, Days: 1.25},
+			{Date: "2026-02-28", Kind: vacation.LedgerAccrual, Days: 1.25},
+			{Date: "2026-02-28", Kind: vacation.LedgerHazard, Days: 0.5},
+		},
+		vacations: []*vacation.LedgerEntry{
+			{Date: "2026-02-10", Kind: vacation.LedgerUsage, Days: -3},
+			{Date: "2026-02-28", Kind: vacation.LedgerPending, Days: -2},
+		},
+	}
+	svc := newTestService(repo, "2026-03-05")
+
+	ledger, err := svc.GetLedger(context.Background(), 10, 2026)
+	if err != nil {
+		t.Fatalf("GetLedger: %v", err)
+	}
+
+	wantKinds := []string{
+		vacation.LedgerCarryOver, vacation.LedgerAccrual, vacation.LedgerUsage,
+		vacation.LedgerAccrual, vacation.LedgerHazard, vacation.LedgerPending,
+	}
+	wantBalances := []float64{4, 5.25, 2.25, 3.5, 4, 2}
+	if len(ledger.Entries) != len(wantKinds) {
+		t.Fatalf("%d entries, want %d", len(ledger.Entries), len(wantKinds))
+	}
+	for i, e := range ledger.Entries {
+		if e.Kind != wantKinds[i] || e.Balance != wantBalances[i] {
+			t.Errorf("entry %d = %s %v, want %s %v", i, e.Kind, e.Balance, wantKinds[i], wantBalances[i])
+		}
+	}
+	if ledger.Earned != 3 || ledger.CarriedOver != 4 || ledger.Used != 3 || ledger.Pending != 2 || ledger.Remaining != 2 {
+		t.Errorf("ledger totals = %+v", ledger)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"srmt-admin/internal/lib/dto"
 	"srmt-admin/internal/lib/model/hrm/vacation"
+	"srmt-admin/internal/lib/service/notification"
 	"srmt-admin/internal/storage"
 	"time"
 )
@@ -38,15 +39,51 @@ type RepoInterface interface {
 	UpdateVacationBalanceReject(ctx context.Context, employeeID int64, year int, days int) error
 	UpdateVacationBalanceCancelApproved(ctx context.Context, employeeID int64, year int, days int) error
 	GetEmployeeDepartmentID(ctx context.Context, employeeID int64) (int64, error)
+
+	// Accrual
+	GetLeavePolicy(ctx context.Context) (*vacation.Policy, error)
+	UpdateLeavePolicy(ctx context.Context, req dto.UpdateLeavePolicyRequest, updatedBy int64) error
+	GetEntitlementRules(ctx context.Context) ([]*vacation.EntitlementRule, error)
+	CreateEntitlementRule(ctx context.Context, req dto.CreateEntitlementRuleRequest) (int64, error)
+	DeleteEntitlementRule(ctx context.Context, id int64) error
+	GetAccrualEmployees(ctx context.Context, from, to string, year int) ([]*vacation.AccrualEmployee, error)
+	ReplaceMonthAccruals(ctx context.Context, monthEnd string, year int, entries []*vacation.LedgerEntry) error
+	IsVacationYearClosed(ctx context.Context, year int) (bool, error)
+	GetLedgerTotals(ctx context.Context, year int) (map[int64]float64, error)
+	CloseVacationYear(ctx context.Context, year int, entries []*vacation.LedgerEntry, closedBy *int64) error
+	AddLedgerAdjustment(ctx context.Context, employeeID int64, year int, req dto.CreateLedgerAdjustmentRequest, createdBy int64) (int64, error)
+	GetLedgerEntries(ctx context.Context, employeeID int64, year int) ([]*vacation.LedgerEntry, error)
+	GetLedgerVacations(ctx context.Context, employeeID int64, year int, types []string) ([]*vacation.LedgerEntry, error)
+}
+
+// Notifier tells employees about decisions on their vacations
+type Notifier interface {
+	Notify(ctx context.Context, e notification.Event) error
+}
+
+// TimesheetRecalculator refills an employee's timesheet once an approved
+// vacation appears or goes away
+type TimesheetRecalculator interface {
+	RecalculateTimesheet(ctx context.Context, employeeID int64, from, to string) error
+}
+
+// Calendar counts an employee's working days
+type Calendar interface {
+	WorkDays(ctx context.Context, employeeID int64, from, to time.Time) (int, error)
 }
 
 type Service struct {
-	repo RepoInterface
-	log  *slog.Logger
+	repo      RepoInterface
+	notifier  Notifier
+	timesheet TimesheetRecalculator
+	calendar  Calendar
+	loc       *time.Location
+	now       func() time.Time
+	log       *slog.Logger
 }
 
-func NewService(repo RepoInterface, log *slog.Logger) *Service {
-	return &Service{repo: repo, log: log}
+func NewService(repo RepoInterface, notifier Notifier, timesheet TimesheetRecalculator, calendar Calendar, loc *time.Location, log *slog.Logger) *Service {
+	return &Service{repo: repo, notifier: notifier, timesheet: timesheet, calendar: calendar, loc: loc, now: time.Now, log: log}
 }
 
 func (s *Service) Create(ctx context.Context, req dto.CreateVacationRequest, createdBy int64) (int64, error) {
@@ -68,8 +105,11 @@ func (s *Service) Create(ctx context.Context, req dto.CreateVacationRequest, cre
 		return 0, storage.ErrInvalidDateRange
 	}
 
-	// 2. Calculate business days (Mon-Sat for Uzbekistan, Sunday is day off)
-	days := calculateBusinessDays(startDate, endDate)
+	// 2. Count working days of the employee's production calendar
+	days, err := s.calendar.WorkDays(ctx, req.EmployeeID, startDate, endDate)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count working days: %w", err)
+	}
 
 	// 3. Balance check for required types
 	year := startDate.Year()
@@ -155,7 +195,10 @@ func (s *Service) Update(ctx context.Context, id int64, req dto.EditVacationRequ
 	if req.StartDate != nil || req.EndDate != nil {
 		start, _ := time.Parse("2006-01-02", startStr)
 		end, _ := time.Parse("2006-01-02", endStr)
-		d := calculateBusinessDays(start, end)
+		d, err := s.calendar.WorkDays(ctx, vac.EmployeeID, start, end)
+		if err != nil {
+			return fmt.Errorf("failed to count working days: %w", err)
+		}
 		days = &d
 	}
 
@@ -198,6 +241,10 @@ func (s *Service) Approve(ctx context.Context, id int64, approvedBy int64) error
 		}
 	}
 
+	s.recalculateTimesheet(ctx, vac)
+	s.notify(ctx, notification.VacationApproved, vac,
+		fmt.Sprintf("Отпуск с %s по %s (%d дн.) согласован", vac.StartDate, vac.EndDate, vac.Days))
+
 	return nil
 }
 
@@ -223,9 +270,43 @@ func (s *Service) Reject(ctx context.Context, id int64, rejectedBy int64, reason
 		}
 	}
 
+	message := fmt.Sprintf("Отпуск с %s по %s отклонён", vac.StartDate, vac.EndDate)
+	if reason != "" {
+		message += ": " + reason
+	}
+	s.notify(ctx, notification.VacationRejected, vac, message)
+
 	return nil
 }
 
+// recalculateTimesheet refills the timesheet days of the vacation. A failure
+// is logged; the nightly refill catches up.
+func (s *Service) recalculateTimesheet(ctx context.Context, vac *vacation.Vacation) {
+	if s.timesheet == nil {
+		return
+	}
+	if err := s.timesheet.RecalculateTimesheet(ctx, vac.EmployeeID, vac.StartDate, vac.EndDate); err != nil {
+		s.log.Error("failed to recalculate timesheet", "error", err, "vacation_id", vac.ID)
+	}
+}
+
+// notify tells the employee about a decision on their vacation. A failure
+// is logged; the decision itself stands.
+func (s *Service) notify(ctx context.Context, typ notification.EventType, vac *vacation.Vacation, message string) {
+	if s.notifier == nil {
+		return
+	}
+	err := s.notifier.Notify(ctx, notification.Event{
+		Type:     typ,
+		Contacts: []int64{vac.EmployeeID},
+		Message:  message,
+		Link:     "/my-vacations",
+	})
+	if err != nil {
+		s.log.Error("failed to notify about vacation", "error", err, "vacation_id", vac.ID)
+	}
+}
+
 func (s *Service) Cancel(ctx context.Context, id int64) error {
 	vac, err := s.repo.GetVacationByID(ctx, id)
 	if err != nil {
@@ -261,6 +342,10 @@ func (s *Service) Cancel(ctx context.Context, id int64) error {
 		}
 	}
 
+	if wasApproved {
+		s.recalculateTimesheet(ctx, vac)
+	}
+
 	return nil
 }
 
@@ -279,14 +364,3 @@ func (s *Service) GetPending(ctx context.Context) ([]*vacation.Vacation, error)
 func (s *Service) GetCalendar(ctx context.Context, filters dto.VacationCalendarFilters) ([]*vacation.CalendarEntry, error) {
 	return s.repo.GetVacationCalendar(ctx, filters)
 }
-
-// calculateBusinessDays counts Mon-Sat days (Uzbekistan work week: Mon-Sat)
-func calculateBusinessDays(start, end time.Time) int {
-	days := 0
-	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
-		if d.Weekday() != time.Sunday {
-			days++
-		}
-	}
-	return days
-}
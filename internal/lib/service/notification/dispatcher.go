@@ -0,0 +1,161 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	model "srmt-admin/internal/lib/model/notification"
+	"srmt-admin/internal/storage"
+)
+
+const (
+	dispatchBatch = 50
+	minRetryDelay = time.Minute
+	maxRetryDelay = time.Hour
+)
+
+// errNoAddress marks deliveries to contacts the channel has no address for
+var errNoAddress = errors.New("no address")
+
+// retryDelay is the delay before the next attempt after attempts failed
+// ones: a minute, doubled on every failure up to an hour.
+func retryDelay(attempts int) time.Duration {
+	d := minRetryDelay
+	for i := 1; i < attempts && d < maxRetryDelay; i++ {
+		d *= 2
+	}
+	return min(d, maxRetryDelay)
+}
+
+func permanent(err error) bool {
+	var p interface{ Permanent() bool }
+	return errors.As(err, &p) && p.Permanent()
+}
+
+// deliver makes one attempt of a delivery and records its outcome.
+func (s *Service) deliver(ctx context.Context, d model.Delivery) {
+	notificationID, err := s.send(ctx, d)
+
+	var outcome error
+	switch {
+	case err == nil:
+		outcome = s.repo.CompleteNotificationDelivery(ctx, d.ID, model.StatusSent, nil, notificationID)
+	case errors.Is(err, errNoAddress):
+		msg := err.Error()
+		outcome = s.repo.CompleteNotificationDelivery(ctx, d.ID, model.StatusSkipped, &msg, nil)
+	case permanent(err) || d.Attempts+1 >= s.opts.MaxAttempts:
+		msg := err.Error()
+		s.log.Warn("notification delivery failed",
+			slog.Int64("delivery_id", d.ID),
+			slog.String("channel", d.Channel),
+			slog.Int("attempts", d.Attempts+1),
+			slog.String("error", msg))
+		outcome = s.repo.CompleteNotificationDelivery(ctx, d.ID, model.StatusFailed, &msg, nil)
+	default:
+		outcome = s.repo.RetryNotificationDelivery(ctx, d.ID, err.Error(), time.Now().Add(retryDelay(d.Attempts+1)))
+	}
+	if outcome != nil {
+		s.log.Error("failed to record notification delivery",
+			slog.Int64("delivery_id", d.ID),
+			slog.String("error", outcome.Error()))
+	}
+}
+
+// send delivers d on its channel. It returns the ID of the in-app
+// notification it created, if any.
+func (s *Service) send(ctx context.Context, d model.Delivery) (*int64, error) {
+	if d.Channel == model.ChannelInApp {
+		id, err := s.repo.CreateHRMNotification(ctx, d.ContactID, d.Title, d.Message, d.Severity, d.Link)
+		if err != nil {
+			return nil, err
+		}
+		return &id, nil
+	}
+
+	sender := s.senders[d.Channel]
+	if sender == nil {
+		return nil, fmt.Errorf("%w: channel %s is not configured", errNoAddress, d.Channel)
+	}
+
+	var to string
+	switch d.Channel {
+	case model.ChannelEmail:
+		email, err := s.repo.GetContactEmail(ctx, d.ContactID)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("%w: contact has no email", errNoAddress)
+		}
+		if err != nil {
+			return nil, err
+		}
+		to = email
+	case model.ChannelTelegram:
+		chatID, err := s.repo.GetTelegramChatID(ctx, d.ContactID)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("%w: contact has no linked Telegram chat", errNoAddress)
+		}
+		if err != nil {
+			return nil, err
+		}
+		to = strconv.FormatInt(chatID, 10)
+	}
+
+	m := Message{Title: d.Title, Text: d.Message, Severity: d.Severity}
+	if d.Link != nil && s.opts.PublicURL != "" {
+		m.URL = s.opts.PublicURL + *d.Link
+	}
+	return nil, sender.Send(ctx, to, m)
+}
+
+// Dispatch sends the deliveries that are due, in batches until none is left.
+// It returns how many deliveries were attempted.
+func (s *Service) Dispatch(ctx context.Context) (int, error) {
+	const op = "service.notification.Dispatch"
+
+	channels := s.Channels()
+	attempted := 0
+	for {
+		due, err := s.repo.ClaimNotificationDeliveries(ctx, channels, dispatchBatch, claimLease)
+		if err != nil {
+			return attempted, fmt.Errorf("%s: %w", op, err)
+		}
+		for _, d := range due {
+			if ctx.Err() != nil {
+				return attempted, ctx.Err()
+			}
+			s.deliver(ctx, d)
+			attempted++
+		}
+		if len(due) < dispatchBatch {
+			return attempted, nil
+		}
+	}
+}
+
+// StartScheduler runs the dispatcher every poll interval and right after an
+// event queues external deliveries. Blocks until ctx is cancelled.
+func (s *Service) StartScheduler(ctx context.Context) {
+	s.log.Info("notification dispatcher started",
+		slog.Any("channels", s.Channels()),
+		slog.Duration("poll_interval", s.opts.PollInterval))
+
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info("notification dispatcher stopped")
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+
+		if _, err := s.Dispatch(ctx); err != nil && ctx.Err() == nil {
+			s.log.Error("notification dispatch failed", slog.String("error", err.Error()))
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package notification
+
+import (
+	model "srmt-admin/internal/lib/model/notification"
+)
+
+// EventType identifies an event of the catalogue. Recipients choose the
+// channels per event type.
+type EventType string
+
+const (
+	VacationApproved          EventType = "vacation.approved"
+	VacationRejected          EventType = "vacation.rejected"
+	SalaryPaid                EventType = "salary.paid"
+	SignatureRequested        EventType = "document.signature_requested"
+	ExecutionSubmitted        EventType = "execution.submitted"
+	ExecutionReturned         EventType = "execution.returned"
+	ExecutionAccepted         EventType = "execution.accepted"
+	ExecutionDueSoon          EventType = "execution.due_soon"
+	ExecutionOverdue          EventType = "execution.overdue"
+	ShutdownAutoCreated       EventType = "shutdown.auto_created"
+	ShutdownCreated           EventType = "shutdown.created"
+	DischargeAwaitingApproval EventType = "discharge.awaiting_approval"
+	FiltrationReadingsMissing EventType = "filtration.readings_missing"
+	GESDailyReport            EventType = "ges.daily_report"
+	CertificationExpiring     EventType = "certification.expiring"
+	CertificationExpired      EventType = "certification.expired"
+)
+
+// Severities match the types of in-app notifications.
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeveritySuccess = "success"
+	SeverityError   = "error"
+	SeverityTask    = "task"
+)
+
+var (
+	inApp         = []string{model.ChannelInApp}
+	telegramOnly  = []string{model.ChannelTelegram}
+	inAppEmail    = []string{model.ChannelInApp, model.ChannelEmail}
+	inAppTelegram = []string{model.ChannelInApp, model.ChannelTelegram}
+	allChannels   = []string{model.ChannelInApp, model.ChannelEmail, model.ChannelTelegram}
+)
+
+// catalogue lists the event types in the order they are shown to users.
+// Name doubles as the notification title when the event carries none.
+var catalogue = []model.EventKind{
+	{Type: string(VacationApproved), Name: "Отпуск согласован", Severity: SeveritySuccess, DefaultChannels: inAppEmail},
+	{Type: string(VacationRejected), Name: "Отпуск отклонён", Severity: SeverityWarning, DefaultChannels: inAppEmail},
+	{Type: string(SalaryPaid), Name: "Зарплата выплачена", Severity: SeveritySuccess, DefaultChannels: inApp},
+	{Type: string(SignatureRequested), Name: "Документ ожидает подписи", Severity: SeverityTask, DefaultChannels: allChannels},
+	{Type: string(ExecutionSubmitted), Name: "Исполнение на проверке", Severity: SeverityInfo, DefaultChannels: inApp},
+	{Type: string(ExecutionReturned), Name: "Исполнение возвращено на доработку", Severity: SeverityWarning, DefaultChannels: inApp},
+	{Type: string(ExecutionAccepted), Name: "Исполнение принято", Severity: SeveritySuccess, DefaultChannels: inApp},
+	{Type: string(ExecutionDueSoon), Name: "Приближается срок исполнения", Severity: SeverityWarning, DefaultChannels: inApp},
+	{Type: string(ExecutionOverdue), Name: "Просрочено исполнение", Severity: SeverityError, DefaultChannels: allChannels},
+	{Type: string(ShutdownAutoCreated), Name: "Автоматически зарегистрирован останов", Severity: SeverityWarning, DefaultChannels: inAppTelegram},
+	{Type: string(ShutdownCreated), Name: "Зарегистрирован останов", Severity: SeverityWarning, DefaultChannels: inAppTelegram},
+	{Type: string(DischargeAwaitingApproval), Name: "Сброс ожидает подтверждения", Severity: SeverityTask, DefaultChannels: inAppTelegram},
+	{Type: string(FiltrationReadingsMissing), Name: "Пропущены замеры фильтрации", Severity: SeverityWarning, DefaultChannels: inApp},
+	{Type: string(GESDailyReport), Name: "Утренняя сводка ГЭС", Severity: SeverityInfo, DefaultChannels: telegramOnly},
+	{Type: string(CertificationExpiring), Name: "Истекает срок действия удостоверения", Severity: SeverityWarning, DefaultChannels: inAppEmail},
+	{Type: string(CertificationExpired), Name: "Истёк срок действия удостоверения", Severity: SeverityError, DefaultChannels: inAppEmail},
+}
+
+func kind(t string) (model.EventKind, bool) {
+	for _, k := range catalogue {
+		if k.Type == t {
+			return k, true
+		}
+	}
+	return model.EventKind{}, false
+}
+
+// Event is something recipients are notified about. Recipients are the
+// union of Contacts, the contacts of Users and those of active users
+// holding any of Roles.
+type Event struct {
+	Type     EventType
+	Contacts []int64
+	Users    []int64
+	Roles    []string
+	// Title defaults to the name of the event type
+	Title   string
+	Message string
+	// Link is a path of the web app, e.g. "/my-vacations"
+	Link string
+	// Severity defaults to the severity of the event type
+	Severity string
+}
@@ -0,0 +1,170 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"srmt-admin/internal/lib/service/telegram"
+)
+
+// SMTPConfig is the mail server emails are sent through.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// EmailSender sends notifications as plain text emails. Port 465 uses
+// implicit TLS; other ports upgrade with STARTTLS when the server offers it.
+type EmailSender struct {
+	cfg     SMTPConfig
+	timeout time.Duration
+}
+
+func NewEmailSender(cfg SMTPConfig) *EmailSender {
+	return &EmailSender{cfg: cfg, timeout: 30 * time.Second}
+}
+
+// smtpError is a reply of the mail server; 5xx replies are permanent.
+type smtpError struct {
+	err *textproto.Error
+}
+
+func (e *smtpError) Error() string   { return fmt.Sprintf("smtp: %d %s", e.err.Code, e.err.Msg) }
+func (e *smtpError) Unwrap() error   { return e.err }
+func (e *smtpError) Permanent() bool { return e.err.Code >= 500 }
+
+func (s *EmailSender) Send(ctx context.Context, to string, m Message) error {
+	err := s.send(ctx, to, m)
+	var reply *textproto.Error
+	if errors.As(err, &reply) {
+		return &smtpError{err: reply}
+	}
+	return err
+}
+
+func (s *EmailSender) send(ctx context.Context, to string, m Message) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	dialer := &net.Dialer{}
+	var conn net.Conn
+	var err error
+	if s.cfg.Port == 465 {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: s.cfg.Host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("smtp: dial: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && s.cfg.Port != 465 {
+		if err := c.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	from, err := mail.ParseAddress(s.cfg.From)
+	if err != nil {
+		return fmt.Errorf("smtp: invalid from address: %w", err)
+	}
+	if err := c.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(composeEmail(from.String(), to, m)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// composeEmail builds a UTF-8 plain text message with a base64 body, so
+// Cyrillic text survives any relay.
+func composeEmail(from, to string, m Message) []byte {
+	body := m.Text
+	if m.URL != "" {
+		body += "\r\n\r\n" + m.URL
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Title))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(body))
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return b.Bytes()
+}
+
+// TelegramSender sends notifications to linked Telegram chats.
+type TelegramSender struct {
+	client *telegram.Client
+}
+
+func NewTelegramSender(client *telegram.Client) *TelegramSender {
+	return &TelegramSender{client: client}
+}
+
+func (s *TelegramSender) Send(ctx context.Context, to string, m Message) error {
+	chatID, err := strconv.ParseInt(to, 10, 64)
+	if err != nil {
+		return fmt.Errorf("telegram: invalid chat id %q: %w", to, err)
+	}
+
+	parts := []string{m.Title}
+	if m.Text != "" {
+		parts = append(parts, m.Text)
+	}
+	if m.URL != "" {
+		parts = append(parts, m.URL)
+	}
+	return s.client.SendMessage(ctx, chatID, strings.Join(parts, "\n\n"))
+}
@@ -0,0 +1,288 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	model "srmt-admin/internal/lib/model/notification"
+)
+
+var (
+	ErrUnknownEvent = errors.New("unknown notification event type")
+)
+
+const (
+	// defaultMaxAttempts applies when NewService is given no limit
+	defaultMaxAttempts = 8
+	// claimLease is how long a delivery being sent is hidden from other
+	// dispatchers
+	claimLease = 5 * time.Minute
+)
+
+// Repository defines the data access of notifications.
+type Repository interface {
+	GetContactIDsByUserIDs(ctx context.Context, userIDs []int64) ([]int64, error)
+	GetContactIDsByRoles(ctx context.Context, roles []string) ([]int64, error)
+	GetNotificationPreferences(ctx context.Context, contactID int64) (map[string][]string, error)
+	GetNotificationChannels(ctx context.Context, eventType string, contactIDs []int64) (map[int64][]string, error)
+	SetNotificationPreferences(ctx context.Context, contactID int64, prefs []model.PreferenceInput) error
+	AddNotificationDeliveries(ctx context.Context, deliveries []model.Delivery) ([]int64, error)
+	ClaimNotificationDeliveries(ctx context.Context, channels []string, limit int, lease time.Duration) ([]model.Delivery, error)
+	CompleteNotificationDelivery(ctx context.Context, id int64, status string, lastError *string, notificationID *int64) error
+	RetryNotificationDelivery(ctx context.Context, id int64, lastError string, at time.Time) error
+	GetNotificationDeliveries(ctx context.Context, f model.DeliveryFilter) ([]model.Delivery, error)
+	GetContactEmail(ctx context.Context, contactID int64) (string, error)
+	GetTelegramChatID(ctx context.Context, contactID int64) (int64, error)
+	CreateHRMNotification(ctx context.Context, contactID int64, title, message, typ string, link *string) (int64, error)
+}
+
+// Message is a notification as an external channel sends it.
+type Message struct {
+	Title    string
+	Text     string
+	Severity string
+	// URL is the absolute link of the notification, empty when the event
+	// has no link or no public URL is configured
+	URL string
+}
+
+// Sender delivers messages on an external channel. An error that repeating
+// the send cannot fix should satisfy interface{ Permanent() bool }, so the
+// delivery fails without retries.
+type Sender interface {
+	Send(ctx context.Context, to string, m Message) error
+}
+
+// Options configure the delivery of notifications.
+type Options struct {
+	// PublicURL is the base URL of the web app, used to turn event links
+	// into absolute ones in emails and Telegram messages
+	PublicURL    string
+	PollInterval time.Duration
+	MaxAttempts  int
+}
+
+// Service fans events out to recipients on the channels they chose. In-app
+// notifications are written at once; email and Telegram deliveries are
+// queued and sent by the dispatcher, see StartScheduler.
+type Service struct {
+	repo    Repository
+	senders map[string]Sender
+	opts    Options
+	wake    chan struct{}
+	log     *slog.Logger
+}
+
+// NewService creates the notification service. senders maps the external
+// channels that are configured to their senders; events are not queued on
+// channels without a sender.
+func NewService(repo Repository, senders map[string]Sender, opts Options, log *slog.Logger) *Service {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 30 * time.Second
+	}
+	opts.PublicURL = strings.TrimRight(opts.PublicURL, "/")
+	return &Service{
+		repo:    repo,
+		senders: senders,
+		opts:    opts,
+		wake:    make(chan struct{}, 1),
+		log:     log.With(slog.String("service", "notification")),
+	}
+}
+
+// Catalogue returns the event types recipients can be notified about.
+func (s *Service) Catalogue() []model.EventKind {
+	return slices.Clone(catalogue)
+}
+
+// Channels returns the channels notifications can be delivered on.
+func (s *Service) Channels() []string {
+	channels := []string{model.ChannelInApp}
+	for _, ch := range []string{model.ChannelEmail, model.ChannelTelegram} {
+		if s.senders[ch] != nil {
+			channels = append(channels, ch)
+		}
+	}
+	return channels
+}
+
+func (s *Service) available(channel string) bool {
+	return channel == model.ChannelInApp || s.senders[channel] != nil
+}
+
+// Notify records the deliveries of an event to its recipients and writes
+// the in-app ones. An event without recipients is not an error.
+func (s *Service) Notify(ctx context.Context, e Event) error {
+	const op = "service.notification.Notify"
+
+	k, ok := kind(string(e.Type))
+	if !ok {
+		return fmt.Errorf("%s: %w: %s", op, ErrUnknownEvent, e.Type)
+	}
+
+	recipients, err := s.recipients(ctx, e)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	chosen, err := s.repo.GetNotificationChannels(ctx, k.Type, recipients)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	title := e.Title
+	if title == "" {
+		title = k.Name
+	}
+	severity := e.Severity
+	if severity == "" {
+		severity = k.Severity
+	}
+	var link *string
+	if e.Link != "" {
+		link = &e.Link
+	}
+
+	now := time.Now()
+	var deliveries []model.Delivery
+	for _, contactID := range recipients {
+		channels, ok := chosen[contactID]
+		if !ok {
+			channels = k.DefaultChannels
+		}
+		for _, ch := range channels {
+			if !s.available(ch) {
+				continue
+			}
+			d := model.Delivery{
+				ContactID:     contactID,
+				EventType:     k.Type,
+				Channel:       ch,
+				Severity:      severity,
+				Title:         title,
+				Message:       e.Message,
+				Link:          link,
+				NextAttemptAt: now,
+			}
+			// In-app deliveries are made below; the dispatcher only
+			// picks them up to retry a failure
+			if ch == model.ChannelInApp {
+				d.NextAttemptAt = now.Add(claimLease)
+			}
+			deliveries = append(deliveries, d)
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	ids, err := s.repo.AddNotificationDeliveries(ctx, deliveries)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	queued := false
+	for i := range deliveries {
+		deliveries[i].ID = ids[i]
+		if deliveries[i].Channel == model.ChannelInApp {
+			s.deliver(ctx, deliveries[i])
+		} else {
+			queued = true
+		}
+	}
+	if queued {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// recipients resolves the contacts of an event, without duplicates.
+func (s *Service) recipients(ctx context.Context, e Event) ([]int64, error) {
+	contacts := slices.Clone(e.Contacts)
+	if len(e.Users) > 0 {
+		ids, err := s.repo.GetContactIDsByUserIDs(ctx, e.Users)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, ids...)
+	}
+	if len(e.Roles) > 0 {
+		ids, err := s.repo.GetContactIDsByRoles(ctx, e.Roles)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, ids...)
+	}
+	slices.Sort(contacts)
+	return slices.Compact(contacts), nil
+}
+
+// Preferences returns the channels of a contact for every event type.
+func (s *Service) Preferences(ctx context.Context, contactID int64) (*model.PreferencesResponse, error) {
+	const op = "service.notification.Preferences"
+
+	custom, err := s.repo.GetNotificationPreferences(ctx, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	prefs := make([]model.Preference, 0, len(catalogue))
+	for _, k := range catalogue {
+		p := model.Preference{EventType: k.Type, Name: k.Name, Channels: k.DefaultChannels}
+		if channels, ok := custom[k.Type]; ok {
+			p.Channels = channels
+			p.Custom = true
+		}
+		if p.Channels == nil {
+			p.Channels = []string{}
+		}
+		prefs = append(prefs, p)
+	}
+	return &model.PreferencesResponse{Channels: s.Channels(), Preferences: prefs}, nil
+}
+
+// SetPreferences stores the channels a contact chose for event types.
+func (s *Service) SetPreferences(ctx context.Context, contactID int64, prefs []model.PreferenceInput) error {
+	const op = "service.notification.SetPreferences"
+
+	for i, p := range prefs {
+		if _, ok := kind(p.EventType); !ok {
+			return fmt.Errorf("%s: %w: %s", op, ErrUnknownEvent, p.EventType)
+		}
+		if p.Channels != nil {
+			channels := slices.Clone(p.Channels)
+			slices.Sort(channels)
+			prefs[i].Channels = slices.Compact(channels)
+		}
+	}
+
+	if err := s.repo.SetNotificationPreferences(ctx, contactID, prefs); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Deliveries returns the delivery log.
+func (s *Service) Deliveries(ctx context.Context, f model.DeliveryFilter) ([]model.Delivery, error) {
+	const op = "service.notification.Deliveries"
+
+	deliveries, err := s.repo.GetNotificationDeliveries(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,332 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	model "srmt-admin/internal/lib/model/notification"
+	"srmt-admin/internal/storage"
+)
+
+type fakeRepo struct {
+	channels   map[int64][]string
+	emails     map[int64]string
+	deliveries []model.Delivery
+	inApp      []int64
+	inAppErr   error
+}
+
+func (f *fakeRepo) GetContactIDsByUserIDs(_ context.Context, userIDs []int64) ([]int64, error) {
+	out := make([]int64, len(userIDs))
+	for i, id := range userIDs {
+		out[i] = id + 100
+	}
+	return out, nil
+}
+
+func (f *fakeRepo) GetContactIDsByRoles(_ context.Context, _ []string) ([]int64, error) {
+	return []int64{1, 2}, nil
+}
+
+func (f *fakeRepo) GetNotificationPreferences(_ context.Context, contactID int64) (map[string][]string, error) {
+	return map[string][]string{string(SalaryPaid): f.channels[contactID]}, nil
+}
+
+func (f *fakeRepo) GetNotificationChannels(_ context.Context, _ string, contactIDs []int64) (map[int64][]string, error) {
+	out := make(map[int64][]string)
+	for _, id := range contactIDs {
+		if ch, ok := f.channels[id]; ok {
+			out[id] = ch
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepo) SetNotificationPreferences(_ context.Context, _ int64, _ []model.PreferenceInput) error {
+	return nil
+}
+
+func (f *fakeRepo) AddNotificationDeliveries(_ context.Context, deliveries []model.Delivery) ([]int64, error) {
+	ids := make([]int64, len(deliveries))
+	for i, d := range deliveries {
+		d.ID = int64(len(f.deliveries) + 1)
+		d.Status = model.StatusPending
+		f.deliveries = append(f.deliveries, d)
+		ids[i] = d.ID
+	}
+	return ids, nil
+}
+
+func (f *fakeRepo) ClaimNotificationDeliveries(_ context.Context, channels []string, limit int, lease time.Duration) ([]model.Delivery, error) {
+	var out []model.Delivery
+	for i := range f.deliveries {
+		d := &f.deliveries[i]
+		if d.Status == model.StatusPending && !d.NextAttemptAt.After(time.Now()) && contains(channels, d.Channel) && len(out) < limit {
+			d.NextAttemptAt = time.Now().Add(lease)
+			out = append(out, *d)
+		}
+	}
+	return out, nil
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeRepo) CompleteNotificationDelivery(_ context.Context, id int64, status string, lastError *string, _ *int64) error {
+	d := &f.deliveries[id-1]
+	d.Status = status
+	d.Attempts++
+	d.LastError = lastError
+	return nil
+}
+
+func (f *fakeRepo) RetryNotificationDelivery(_ context.Context, id int64, lastError string, at time.Time) error {
+	d := &f.deliveries[id-1]
+	d.Attempts++
+	d.LastError = &lastError
+	d.NextAttemptAt = at
+	return nil
+}
+
+func (f *fakeRepo) GetNotificationDeliveries(_ context.Context, _ model.DeliveryFilter) ([]model.Delivery, error) {
+	return f.deliveries, nil
+}
+
+func (f *fakeRepo) GetContactEmail(_ context.Context, contactID int64) (string, error) {
+	email, ok := f.emails[contactID]
+	if !ok {
+		return "", storage.ErrNotFound
+	}
+	return email, nil
+}
+
+func (f *fakeRepo) GetTelegramChatID(_ context.Context, _ int64) (int64, error) {
+	return 0, storage.ErrNotFound
+}
+
+func (f *fakeRepo) CreateHRMNotification(_ context.Context, contactID int64, _, _, _ string, _ *string) (int64, error) {
+	if f.inAppErr != nil {
+		return 0, f.inAppErr
+	}
+	f.inApp = append(f.inApp, contactID)
+	return int64(len(f.inApp)), nil
+}
+
+type permanentErr struct{}
+
+func (permanentErr) Error() string   { return "mailbox unavailable" }
+func (permanentErr) Permanent() bool { return true }
+
+type fakeSender struct {
+	sent []string
+	errs []error
+}
+
+func (f *fakeSender) Send(_ context.Context, to string, m Message) error {
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		if err != nil {
+			return err
+		}
+	}
+	f.sent = append(f.sent, to+" "+m.URL)
+	return nil
+}
+
+func newTestService(repo *fakeRepo, email *fakeSender) *Service {
+	senders := map[string]Sender{}
+	if email != nil {
+		senders[model.ChannelEmail] = email
+	}
+	return NewService(repo, senders, Options{PublicURL: "https://srmt.example/", MaxAttempts: 3},
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func (f *fakeRepo) byStatus(status string) int {
+	n := 0
+	for _, d := range f.deliveries {
+		if d.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+func TestNotify_ChannelsAndPreferences(t *testing.T) {
+	repo := &fakeRepo{
+		// Contact 2 turned the event off, contact 3 wants email only
+		channels: map[int64][]string{2: {}, 3: {model.ChannelEmail}},
+		emails:   map[int64]string{3: "c3@example.com"},
+	}
+	email := &fakeSender{}
+	svc := newTestService(repo, email)
+	ctx := context.Background()
+
+	err := svc.Notify(ctx, Event{Type: VacationApproved, Contacts: []int64{1, 2, 3, 1}, Message: "с 1 по 14 июля", Link: "/my-vacations"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Default channels of the event for contact 1; Telegram is not configured
+	if len(repo.inApp) != 1 || repo.inApp[0] != 1 {
+		t.Fatalf("in-app notifications to %v, want [1]", repo.inApp)
+	}
+	if len(repo.deliveries) != 3 {
+		t.Fatalf("got %d deliveries, want in_app+email for 1 and email for 3", len(repo.deliveries))
+	}
+	if repo.deliveries[0].Title != "Отпуск согласован" {
+		t.Errorf("title = %q, want the catalogue name", repo.deliveries[0].Title)
+	}
+
+	if _, err := svc.Dispatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(email.sent) != 1 || email.sent[0] != "c3@example.com https://srmt.example/my-vacations" {
+		t.Errorf("sent = %v", email.sent)
+	}
+	// Contact 1 has no email address
+	if repo.byStatus(model.StatusSkipped) != 1 || repo.byStatus(model.StatusSent) != 2 {
+		t.Errorf("deliveries = %+v", repo.deliveries)
+	}
+}
+
+func TestNotify_Recipients(t *testing.T) {
+	repo := &fakeRepo{}
+	svc := newTestService(repo, nil)
+
+	err := svc.Notify(context.Background(), Event{
+		Type: DischargeAwaitingApproval, Contacts: []int64{2}, Users: []int64{5}, Roles: []string{"sc"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := repo.inApp; len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 105 {
+		t.Errorf("notified %v, want [1 2 105]", got)
+	}
+
+	if err := svc.Notify(context.Background(), Event{Type: "nope", Contacts: []int64{1}}); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("unknown event: err = %v", err)
+	}
+}
+
+func TestDispatch_Retries(t *testing.T) {
+	repo := &fakeRepo{
+		channels: map[int64][]string{1: {model.ChannelEmail}, 2: {model.ChannelEmail}},
+		emails:   map[int64]string{1: "c1@example.com", 2: "c2@example.com"},
+	}
+	email := &fakeSender{errs: []error{errors.New("connection refused"), permanentErr{}}}
+	svc := newTestService(repo, email)
+	ctx := context.Background()
+
+	if err := svc.Notify(ctx, Event{Type: SalaryPaid, Contacts: []int64{1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Dispatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	first, second := repo.deliveries[0], repo.deliveries[1]
+	if first.Status != model.StatusPending || first.Attempts != 1 || !first.NextAttemptAt.After(time.Now().Add(50*time.Second)) {
+		t.Errorf("transient failure must be retried later: %+v", first)
+	}
+	if second.Status != model.StatusFailed || second.Attempts != 1 {
+		t.Errorf("permanent failure must not be retried: %+v", second)
+	}
+
+	// Due again: the retry goes through
+	repo.deliveries[0].NextAttemptAt = time.Now()
+	if _, err := svc.Dispatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if repo.deliveries[0].Status != model.StatusSent || len(email.sent) != 1 {
+		t.Errorf("retry: %+v, sent %v", repo.deliveries[0], email.sent)
+	}
+}
+
+func TestDispatch_GivesUpAfterMaxAttempts(t *testing.T) {
+	repo := &fakeRepo{
+		channels: map[int64][]string{1: {model.ChannelEmail}},
+		emails:   map[int64]string{1: "c1@example.com"},
+	}
+	fail := errors.New("timeout")
+	svc := newTestService(repo, &fakeSender{errs: []error{fail, fail, fail}})
+	ctx := context.Background()
+
+	if err := svc.Notify(ctx, Event{Type: SalaryPaid, Contacts: []int64{1}}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		repo.deliveries[0].NextAttemptAt = time.Now()
+		if _, err := svc.Dispatch(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := repo.deliveries[0]; d.Status != model.StatusFailed || d.Attempts != 3 {
+		t.Errorf("delivery = %+v, want failed after 3 attempts", d)
+	}
+}
+
+func TestNotify_InAppFailureIsRetried(t *testing.T) {
+	repo := &fakeRepo{inAppErr: errors.New("db down")}
+	svc := newTestService(repo, nil)
+	ctx := context.Background()
+
+	if err := svc.Notify(ctx, Event{Type: SalaryPaid, Contacts: []int64{1}}); err != nil {
+		t.Fatal(err)
+	}
+	if d := repo.deliveries[0]; d.Status != model.StatusPending || d.Attempts != 1 {
+		t.Fatalf("delivery = %+v", d)
+	}
+
+	repo.inAppErr = nil
+	repo.deliveries[0].NextAttemptAt = time.Now()
+	if _, err := svc.Dispatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.inApp) != 1 || repo.deliveries[0].Status != model.StatusSent {
+		t.Errorf("in-app retry: %+v", repo.deliveries[0])
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{4, 8 * time.Minute},
+		{7, time.Hour},
+		{20, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestComposeEmail(t *testing.T) {
+	msg := string(composeEmail("SRMT <noreply@example.com>", "a@example.com", Message{
+		Title: "Отпуск согласован", Text: "с 1 по 14 июля", URL: "https://srmt.example/my-vacations",
+	}))
+
+	if !strings.Contains(msg, "Subject: =?utf-8?q?") {
+		t.Errorf("subject is not encoded:\n%s", msg)
+	}
+	if !strings.Contains(msg, "Content-Transfer-Encoding: base64\r\n\r\n") {
+		t.Errorf("body is not base64:\n%s", msg)
+	}
+}
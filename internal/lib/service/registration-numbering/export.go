@@ -0,0 +1,76 @@
+package regnumbering
+
+import (
+	"context"
+	"fmt"
+
+	registration_number "srmt-admin/internal/lib/model/registration-number"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var statusNames = map[string]string{
+	registration_number.StatusReserved:  "Зарезервирован",
+	registration_number.StatusIssued:    "Присвоен",
+	registration_number.StatusCancelled: "Аннулирован",
+}
+
+// ExportJournal renders the journal selected by f as the chancellery's
+// registry workbook, one row per number including cancelled ones.
+func (s *Service) ExportJournal(ctx context.Context, f registration_number.JournalFilter) (*excelize.File, error) {
+	const op = "service.registration-numbering.ExportJournal"
+
+	entries, err := s.repo.GetRegistrationJournal(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	file := excelize.NewFile()
+	sheet := "Journal"
+	file.SetSheetName("Sheet1", sheet)
+
+	headerStyle, _ := file.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#DAEEF3"}, Pattern: 1},
+	})
+
+	headers := []string{"№", "Вид", "Организация", "Рег. номер", "Дата регистрации", "Статус", "Документ", "Примечание"}
+	for i, h := range headers {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+		file.SetCellValue(sheet, cell, h)
+	}
+	file.SetCellStyle(sheet, "A1", "H1", headerStyle)
+
+	for i, e := range entries {
+		row := i + 2
+		values := []interface{}{
+			i + 1,
+			e.DocumentKind,
+			deref(e.OrganizationName),
+			e.Number,
+			e.RegisteredOn.Format("02.01.2006"),
+			statusNames[e.Status],
+			deref(e.DocumentName),
+			deref(e.CancelReason),
+		}
+		for col, v := range values {
+			cell, _ := excelize.CoordinatesToCellName(col+1, row)
+			file.SetCellValue(sheet, cell, v)
+		}
+	}
+
+	file.SetColWidth(sheet, "A", "A", 6)
+	file.SetColWidth(sheet, "B", "C", 20)
+	file.SetColWidth(sheet, "D", "E", 18)
+	file.SetColWidth(sheet, "F", "F", 16)
+	file.SetColWidth(sheet, "G", "H", 40)
+
+	return file, nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
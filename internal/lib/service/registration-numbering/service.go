@@ -0,0 +1,209 @@
+// Package regnumbering manages the registration numbering schemes of the
+// document registry: scheme configuration, number reservations and the
+// journal of issued numbers with its annual export. Numbers of new documents
+// are allocated by the repository in the document's insert transaction.
+package regnumbering
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	registration_number "srmt-admin/internal/lib/model/registration-number"
+	"srmt-admin/internal/storage"
+)
+
+var (
+	ErrInvalidScheme         = errors.New("invalid numbering scheme")
+	ErrUnknownKind           = errors.New("unknown document kind")
+	ErrNoScheme              = errors.New("no active numbering scheme for the document kind")
+	ErrReservationNotAllowed = errors.New("numbering scheme does not allow reservations")
+	ErrSchemeInUse           = errors.New("numbering scheme has numbers in the journal")
+)
+
+type Repository interface {
+	DocumentKindExists(ctx context.Context, code string) (bool, error)
+	GetNumberingSchemes(ctx context.Context, kind *string) ([]registration_number.Scheme, error)
+	GetNumberingScheme(ctx context.Context, id int64) (*registration_number.Scheme, error)
+	FindNumberingScheme(ctx context.Context, kind string, orgID *int64) (*registration_number.Scheme, error)
+	NumberingSchemeInUse(ctx context.Context, id int64) (bool, error)
+	AddNumberingScheme(ctx context.Context, req registration_number.AddSchemeRequest, userID int64) (int64, error)
+	EditNumberingScheme(ctx context.Context, id int64, req registration_number.EditSchemeRequest) error
+	PeekRegistrationNumber(ctx context.Context, scheme registration_number.Scheme, date time.Time) (string, error)
+	ReserveRegistrationNumber(ctx context.Context, scheme registration_number.Scheme, date time.Time, userID int64) (int64, string, error)
+	CancelRegistrationNumber(ctx context.Context, id int64, reason *string, userID int64) error
+	GetRegistrationJournal(ctx context.Context, f registration_number.JournalFilter) ([]registration_number.Entry, error)
+}
+
+type Service struct {
+	repo Repository
+	loc  *time.Location
+	log  *slog.Logger
+}
+
+func NewService(repo Repository, loc *time.Location, log *slog.Logger) *Service {
+	return &Service{
+		repo: repo,
+		loc:  loc,
+		log:  log.With(slog.String("service", "registration-numbering")),
+	}
+}
+
+// Schemes lists the schemes, optionally of one kind.
+func (s *Service) Schemes(ctx context.Context, kind *string) ([]registration_number.Scheme, error) {
+	const op = "service.registration-numbering.Schemes"
+
+	schemes, err := s.repo.GetNumberingSchemes(ctx, kind)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return schemes, nil
+}
+
+// CreateScheme validates and stores a new scheme.
+func (s *Service) CreateScheme(ctx context.Context, req registration_number.AddSchemeRequest, userID int64) (int64, error) {
+	const op = "service.registration-numbering.CreateScheme"
+
+	ok, err := s.repo.DocumentKindExists(ctx, req.DocumentKind)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if !ok {
+		return 0, fmt.Errorf("%s: %w", op, ErrUnknownKind)
+	}
+
+	scheme := registration_number.Scheme{
+		DocumentKind:   req.DocumentKind,
+		OrganizationID: req.OrganizationID,
+		OrgCode:        req.OrgCode,
+		Pattern:        req.Pattern,
+		ResetPeriod:    req.ResetPeriod,
+	}
+	if err := scheme.Validate(); err != nil {
+		return 0, fmt.Errorf("%s: %w: %w", op, ErrInvalidScheme, err)
+	}
+
+	id, err := s.repo.AddNumberingScheme(ctx, req, userID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	s.log.Info("numbering scheme created", slog.Int64("id", id), slog.String("document_kind", req.DocumentKind))
+	return id, nil
+}
+
+// EditScheme applies req to the scheme and validates the result. Changing
+// the numbering of a scheme that has numbers in the journal is
+// ErrSchemeInUse: a new pattern or org code may render a number issued
+// before, and a new reset period restarts the counter.
+func (s *Service) EditScheme(ctx context.Context, id int64, req registration_number.EditSchemeRequest) error {
+	const op = "service.registration-numbering.EditScheme"
+
+	scheme, err := s.repo.GetNumberingScheme(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if changesNumbering(*scheme, req) {
+		inUse, err := s.repo.NumberingSchemeInUse(ctx, id)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if inUse {
+			return fmt.Errorf("%s: %w", op, ErrSchemeInUse)
+		}
+	}
+	if req.OrgCode != nil {
+		scheme.OrgCode = req.OrgCode
+	}
+	if req.Pattern != nil {
+		scheme.Pattern = *req.Pattern
+	}
+	if req.ResetPeriod != nil {
+		scheme.ResetPeriod = *req.ResetPeriod
+	}
+	if err := scheme.Validate(); err != nil {
+		return fmt.Errorf("%s: %w: %w", op, ErrInvalidScheme, err)
+	}
+
+	if err := s.repo.EditNumberingScheme(ctx, id, req); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Preview returns the number the scheme would issue next on date (today
+// when nil) without allocating it.
+func (s *Service) Preview(ctx context.Context, id int64, date *time.Time) (string, error) {
+	const op = "service.registration-numbering.Preview"
+
+	scheme, err := s.repo.GetNumberingScheme(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	number, err := s.repo.PeekRegistrationNumber(ctx, *scheme, s.dateOrToday(date))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	return number, nil
+}
+
+// Reserve allocates the next number of the scheme applying to the kind and
+// organization and holds it for a document to be registered later.
+func (s *Service) Reserve(ctx context.Context, req registration_number.ReserveRequest, userID int64) (int64, string, error) {
+	const op = "service.registration-numbering.Reserve"
+
+	scheme, err := s.repo.FindNumberingScheme(ctx, req.DocumentKind, req.OrganizationID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return 0, "", fmt.Errorf("%s: %w", op, ErrNoScheme)
+		}
+		return 0, "", fmt.Errorf("%s: %w", op, err)
+	}
+	if !scheme.AllowReservation {
+		return 0, "", fmt.Errorf("%s: %w", op, ErrReservationNotAllowed)
+	}
+
+	id, number, err := s.repo.ReserveRegistrationNumber(ctx, *scheme, s.dateOrToday(req.RegisteredOn), userID)
+	if err != nil {
+		return 0, "", fmt.Errorf("%s: %w", op, err)
+	}
+	s.log.Info("registration number reserved", slog.Int64("id", id), slog.String("number", number), slog.Int64("user_id", userID))
+	return id, number, nil
+}
+
+// Cancel withdraws a reserved number.
+func (s *Service) Cancel(ctx context.Context, id int64, reason *string, userID int64) error {
+	const op = "service.registration-numbering.Cancel"
+
+	if err := s.repo.CancelRegistrationNumber(ctx, id, reason, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	s.log.Info("registration number cancelled", slog.Int64("id", id), slog.Int64("user_id", userID))
+	return nil
+}
+
+// Journal lists the registration journal.
+func (s *Service) Journal(ctx context.Context, f registration_number.JournalFilter) ([]registration_number.Entry, error) {
+	const op = "service.registration-numbering.Journal"
+
+	entries, err := s.repo.GetRegistrationJournal(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return entries, nil
+}
+
+// changesNumbering reports whether req changes the numbers the scheme renders
+func changesNumbering(scheme registration_number.Scheme, req registration_number.EditSchemeRequest) bool {
+	return (req.OrgCode != nil && (scheme.OrgCode == nil || *scheme.OrgCode != *req.OrgCode)) ||
+		(req.Pattern != nil && *req.Pattern != scheme.Pattern) ||
+		(req.ResetPeriod != nil && *req.ResetPeriod != scheme.ResetPeriod)
+}
+
+func (s *Service) dateOrToday(date *time.Time) time.Time {
+	if date != nil {
+		return *date
+	}
+	return time.Now().In(s.loc)
+}
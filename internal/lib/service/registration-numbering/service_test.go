@@ -0,0 +1,258 @@
+package regnumbering
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	registration_number "srmt-admin/internal/lib/model/registration-number"
+	"srmt-admin/internal/storage"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+type fakeRepo struct {
+	schemes    []registration_number.Scheme
+	journal    []registration_number.Entry
+	added      *registration_number.AddSchemeRequest
+	edited     *registration_number.EditSchemeRequest
+	inUse      bool
+	reservedOn time.Time
+}
+
+func (f *fakeRepo) DocumentKindExists(_ context.Context, code string) (bool, error) {
+	return code == "decree" || code == "letter", nil
+}
+
+func (f *fakeRepo) GetNumberingSchemes(_ context.Context, _ *string) ([]registration_number.Scheme, error) {
+	return f.schemes, nil
+}
+
+func (f *fakeRepo) GetNumberingScheme(_ context.Context, id int64) (*registration_number.Scheme, error) {
+	for _, s := range f.schemes {
+		if s.ID == id {
+			return &s, nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+func (f *fakeRepo) FindNumberingScheme(_ context.Context, kind string, orgID *int64) (*registration_number.Scheme, error) {
+	var fallback *registration_number.Scheme
+	for _, s := range f.schemes {
+		if s.DocumentKind != kind || !s.IsActive {
+			continue
+		}
+		if s.OrganizationID == nil {
+			fallback = &s
+			continue
+		}
+		if orgID != nil && *s.OrganizationID == *orgID {
+			return &s, nil
+		}
+	}
+	if fallback == nil {
+		return nil, storage.ErrNotFound
+	}
+	return fallback, nil
+}
+
+func (f *fakeRepo) NumberingSchemeInUse(_ context.Context, _ int64) (bool, error) {
+	return f.inUse, nil
+}
+
+func (f *fakeRepo) AddNumberingScheme(_ context.Context, req registration_number.AddSchemeRequest, _ int64) (int64, error) {
+	f.added = &req
+	return 9, nil
+}
+
+func (f *fakeRepo) EditNumberingScheme(_ context.Context, _ int64, req registration_number.EditSchemeRequest) error {
+	f.edited = &req
+	return nil
+}
+
+func (f *fakeRepo) PeekRegistrationNumber(_ context.Context, scheme registration_number.Scheme, date time.Time) (string, error) {
+	return scheme.Format(1, date), nil
+}
+
+func (f *fakeRepo) ReserveRegistrationNumber(_ context.Context, scheme registration_number.Scheme, date time.Time, _ int64) (int64, string, error) {
+	f.reservedOn = date
+	return 1, scheme.Format(1, date), nil
+}
+
+func (f *fakeRepo) CancelRegistrationNumber(_ context.Context, _ int64, _ *string, _ int64) error {
+	return nil
+}
+
+func (f *fakeRepo) GetRegistrationJournal(_ context.Context, _ registration_number.JournalFilter) ([]registration_number.Entry, error) {
+	return f.journal, nil
+}
+
+func newTestService(repo *fakeRepo) *Service {
+	return NewService(repo, time.UTC, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestSchemeFormat(t *testing.T) {
+	date := time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		pattern string
+		orgCode *string
+		seq     int
+		want    string
+	}{
+		{pattern: "{seq}-{yy}", seq: 12, want: "12-26"},
+		{pattern: "{org_code}/{seq:4}/{yyyy}", orgCode: ptr("ГЭС"), seq: 7, want: "ГЭС/0007/2026"},
+		{pattern: "П-{dd}.{mm}.{yyyy}-{seq:2}", seq: 123, want: "П-07.03.2026-123"},
+	}
+	for _, tt := range tests {
+		s := registration_number.Scheme{Pattern: tt.pattern, OrgCode: tt.orgCode, ResetPeriod: registration_number.ResetYearly}
+		if got := s.Format(tt.seq, date); got != tt.want {
+			t.Errorf("Format(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestCreateSchemeValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     registration_number.AddSchemeRequest
+		wantErr error
+	}{
+		{name: "valid", req: registration_number.AddSchemeRequest{DocumentKind: "decree", Pattern: "{seq}-{yy}", ResetPeriod: "yearly"}},
+		{name: "unknown kind", req: registration_number.AddSchemeRequest{DocumentKind: "memo", Pattern: "{seq}", ResetPeriod: "never"},
+			wantErr: ErrUnknownKind},
+		{name: "no seq", req: registration_number.AddSchemeRequest{DocumentKind: "decree", Pattern: "П-{yyyy}", ResetPeriod: "yearly"},
+			wantErr: registration_number.ErrPatternNoSeq},
+		{name: "yearly without year", req: registration_number.AddSchemeRequest{DocumentKind: "decree", Pattern: "{seq}", ResetPeriod: "yearly"},
+			wantErr: registration_number.ErrPatternNoYear},
+		{name: "org code missing", req: registration_number.AddSchemeRequest{DocumentKind: "letter", Pattern: "{org_code}-{seq}", ResetPeriod: "never"},
+			wantErr: registration_number.ErrPatternOrgCode},
+		{name: "organization without org code", req: registration_number.AddSchemeRequest{DocumentKind: "letter", OrganizationID: ptr(int64(5)),
+			Pattern: "{seq}/{yy}", ResetPeriod: "yearly"}, wantErr: registration_number.ErrPatternNoOrgCode},
+		{name: "organization with org code", req: registration_number.AddSchemeRequest{DocumentKind: "letter", OrganizationID: ptr(int64(5)),
+			OrgCode: ptr("ГЭС"), Pattern: "{org_code}-{seq}/{yy}", ResetPeriod: "yearly"}},
+		{name: "unknown placeholder", req: registration_number.AddSchemeRequest{DocumentKind: "letter", Pattern: "{seq}-{hh}", ResetPeriod: "never"},
+			wantErr: registration_number.ErrPatternUnknownPart},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepo{}
+			_, err := newTestService(repo).CreateScheme(context.Background(), tt.req, 1)
+			if tt.wantErr == nil {
+				if err != nil || repo.added == nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if repo.added != nil {
+				t.Fatal("invalid scheme was stored")
+			}
+		})
+	}
+}
+
+func TestEditSchemeValidatesMergedScheme(t *testing.T) {
+	repo := &fakeRepo{schemes: []registration_number.Scheme{
+		{ID: 1, DocumentKind: "decree", Pattern: "{seq}-{yy}", ResetPeriod: registration_number.ResetYearly},
+	}}
+	svc := newTestService(repo)
+
+	err := svc.EditScheme(context.Background(), 1, registration_number.EditSchemeRequest{Pattern: ptr("{seq}")})
+	if !errors.Is(err, ErrInvalidScheme) || !errors.Is(err, registration_number.ErrPatternNoYear) {
+		t.Fatalf("got %v, want ErrPatternNoYear", err)
+	}
+
+	err = svc.EditScheme(context.Background(), 1, registration_number.EditSchemeRequest{
+		Pattern: ptr("{seq}"), ResetPeriod: ptr(registration_number.ResetNever),
+	})
+	if err != nil || repo.edited == nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestEditSchemeInUse(t *testing.T) {
+	repo := &fakeRepo{inUse: true, schemes: []registration_number.Scheme{
+		{ID: 1, DocumentKind: "decree", Pattern: "{seq}-{yy}", ResetPeriod: registration_number.ResetYearly},
+	}}
+	svc := newTestService(repo)
+
+	for name, req := range map[string]registration_number.EditSchemeRequest{
+		"pattern":      {Pattern: ptr("{seq:3}-{yy}")},
+		"reset period": {Pattern: ptr("{seq}"), ResetPeriod: ptr(registration_number.ResetNever)},
+		"org code":     {OrgCode: ptr("ГЭС")},
+	} {
+		if err := svc.EditScheme(context.Background(), 1, req); !errors.Is(err, ErrSchemeInUse) {
+			t.Fatalf("%s: got %v, want ErrSchemeInUse", name, err)
+		}
+	}
+	if repo.edited != nil {
+		t.Fatal("numbering of a used scheme was changed")
+	}
+
+	err := svc.EditScheme(context.Background(), 1, registration_number.EditSchemeRequest{
+		Pattern: ptr("{seq}-{yy}"), AllowReservation: ptr(true),
+	})
+	if err != nil || repo.edited == nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestReserve(t *testing.T) {
+	repo := &fakeRepo{schemes: []registration_number.Scheme{
+		{ID: 1, DocumentKind: "decree", Pattern: "{seq}-{yy}", ResetPeriod: registration_number.ResetYearly, IsActive: true},
+		{ID: 2, DocumentKind: "decree", OrganizationID: ptr(int64(5)), OrgCode: ptr("ГЭС"), Pattern: "{org_code}-{seq}/{yy}",
+			ResetPeriod: registration_number.ResetYearly, AllowReservation: true, IsActive: true},
+	}}
+	svc := newTestService(repo)
+	on := time.Date(2025, 12, 30, 0, 0, 0, 0, time.UTC)
+
+	_, number, err := svc.Reserve(context.Background(), registration_number.ReserveRequest{
+		DocumentKind: "decree", OrganizationID: ptr(int64(5)), RegisteredOn: &on,
+	}, 1)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if number != "ГЭС-1/25" || !repo.reservedOn.Equal(on) {
+		t.Fatalf("got %q on %v", number, repo.reservedOn)
+	}
+
+	_, _, err = svc.Reserve(context.Background(), registration_number.ReserveRequest{DocumentKind: "decree"}, 1)
+	if !errors.Is(err, ErrReservationNotAllowed) {
+		t.Fatalf("got %v, want ErrReservationNotAllowed", err)
+	}
+
+	_, _, err = svc.Reserve(context.Background(), registration_number.ReserveRequest{DocumentKind: "letter"}, 1)
+	if !errors.Is(err, ErrNoScheme) {
+		t.Fatalf("got %v, want ErrNoScheme", err)
+	}
+}
+
+func TestExportJournal(t *testing.T) {
+	repo := &fakeRepo{journal: []registration_number.Entry{
+		{ID: 1, DocumentKind: "decree", Number: "1-26", RegisteredOn: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+			Status: registration_number.StatusIssued, DocumentName: ptr("О графике")},
+		{ID: 2, DocumentKind: "decree", Number: "2-26", RegisteredOn: time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC),
+			Status: registration_number.StatusCancelled, CancelReason: ptr("Ошибочно")},
+	}}
+
+	f, err := newTestService(repo).ExportJournal(context.Background(), registration_number.JournalFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	rows, err := f.GetRows("Journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want header + 2", len(rows))
+	}
+	if rows[2][3] != "2-26" || rows[2][5] != "Аннулирован" || rows[2][7] != "Ошибочно" {
+		t.Fatalf("unexpected cancelled row %v", rows[2])
+	}
+}
@@ -0,0 +1,165 @@
+// Package runoff estimates the spring/summer inflow of a reservoir from the
+// snow cover of its catchment. Past seasons pair the modsnow cover on the
+// same day of year with the inflow volume that followed; a linear fit of the
+// two gives the expected range for the coming season.
+package runoff
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"srmt-admin/internal/lib/model/runoff"
+	"srmt-admin/internal/lib/stats"
+)
+
+const (
+	// The flood season (вегетационный период): April through September.
+	seasonFromMonth = 4
+	seasonToMonth   = 9
+
+	// snowToleranceDays is how far a modsnow reading may be from the forecast
+	// day of year and still count for that year.
+	snowToleranceDays = 10
+	// minSamples is the number of past seasons with snow cover needed before
+	// the regression is trusted over climatology.
+	minSamples = 5
+	// minSeasonCoverage is the share of season days with inflow data needed
+	// for a past season to be used at all.
+	minSeasonCoverage = 0.9
+	// intervalZ gives an ~80% range (±1.28σ) around the expected inflow.
+	intervalZ = 1.28
+)
+
+type Repository interface {
+	GetReservoirOrganization(ctx context.Context, resID int) (string, *int64, error)
+	GetSeasonInflowByYears(ctx context.Context, resID int, fromMonth, toMonth int) ([]runoff.YearValue, error)
+	GetSnowCoverNearDay(ctx context.Context, orgID int64, month, day, toleranceDays int) ([]runoff.CoverReading, error)
+}
+
+type Service struct {
+	repo Repository
+	log  *slog.Logger
+}
+
+func NewService(repo Repository, log *slog.Logger) *Service {
+	return &Service{
+		repo: repo,
+		log:  log.With(slog.String("service", "runoff")),
+	}
+}
+
+// Forecast estimates the flood-season inflow of asOf's year for the
+// reservoir. Snow cover is taken from the reading closest to asOf (not after
+// it); past seasons use the reading closest to the same day of year. Returns
+// storage.ErrNotFound when the reservoir does not exist.
+func (s *Service) Forecast(ctx context.Context, resID int, asOf time.Time) (*runoff.Forecast, error) {
+	const op = "service.runoff.Forecast"
+
+	name, orgID, err := s.repo.GetReservoirOrganization(ctx, resID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	seasonYear := asOf.Year()
+	result := &runoff.Forecast{
+		ReservoirID:    resID,
+		Reservoir:      name,
+		OrganizationID: orgID,
+		AsOf:           asOf.Format("2006-01-02"),
+		SeasonYear:     seasonYear,
+		SeasonFrom:     time.Date(seasonYear, seasonFromMonth, 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02"),
+		SeasonTo:       time.Date(seasonYear, seasonToMonth+1, 0, 0, 0, 0, 0, time.UTC).Format("2006-01-02"),
+		Samples:        []runoff.SeasonSample{},
+	}
+
+	inflows, err := s.repo.GetSeasonInflowByYears(ctx, resID, seasonFromMonth, seasonToMonth)
+	if err != nil {
+		return nil, fmt.Errorf("%s: inflow: %w", op, err)
+	}
+
+	covers := make(map[int]runoff.CoverReading)
+	if orgID != nil {
+		month, day := asOf.Month(), asOf.Day()
+		if month == time.February && day == 29 {
+			day = 28
+		}
+		readings, err := s.repo.GetSnowCoverNearDay(ctx, *orgID, int(month), day, snowToleranceDays)
+		if err != nil {
+			return nil, fmt.Errorf("%s: snow cover: %w", op, err)
+		}
+		covers = closestCovers(readings, month, day, asOf)
+	}
+
+	if c, ok := covers[seasonYear]; ok {
+		date, cover := c.Date, c.Cover
+		result.SnowDate, result.Cover = &date, &cover
+	}
+
+	seasonDays := int(time.Date(seasonYear, seasonToMonth+1, 1, 0, 0, 0, 0, time.UTC).
+		Sub(time.Date(seasonYear, seasonFromMonth, 1, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+	var allInflow, xs, ys []float64
+	for _, v := range inflows {
+		if v.Year >= seasonYear || float64(v.Days) < minSeasonCoverage*float64(seasonDays) {
+			continue
+		}
+		sample := runoff.SeasonSample{Year: v.Year, Inflow: v.Value}
+		if c, ok := covers[v.Year]; ok {
+			date, cover := c.Date, c.Cover
+			sample.SnowDate, sample.Cover = &date, &cover
+			xs = append(xs, cover)
+			ys = append(ys, v.Value)
+		}
+		allInflow = append(allInflow, v.Value)
+		result.Samples = append(result.Samples, sample)
+	}
+
+	if result.Cover != nil {
+		if reg := stats.FitLinear(xs, ys, minSamples); reg != nil {
+			expected := reg.Intercept + reg.Slope*(*result.Cover)
+			half := intervalZ * reg.ResidualStd
+			result.Method = runoff.MethodRegression
+			result.Regression = reg
+			result.Range = &runoff.Range{
+				Low:      max(expected-half, 0),
+				Expected: max(expected, 0),
+				High:     max(expected+half, 0),
+			}
+			return result, nil
+		}
+	}
+
+	result.Method = runoff.MethodClimatology
+	if len(allInflow) > 0 {
+		result.Range = &runoff.Range{
+			Low:      percentile(allInflow, 0.1),
+			Expected: percentile(allInflow, 0.5),
+			High:     percentile(allInflow, 0.9),
+		}
+	}
+	return result, nil
+}
+
+// closestCovers picks, for every year, the reading closest to month/day;
+// on a tie the later one. In asOf's own year only readings up to asOf
+// count, as later ones are not known yet on the forecast date.
+func closestCovers(readings []runoff.CoverReading, month time.Month, day int, asOf time.Time) map[int]runoff.CoverReading {
+	asOfDate := asOf.Format("2006-01-02")
+	covers := make(map[int]runoff.CoverReading)
+	distances := make(map[int]float64)
+	for _, c := range readings {
+		d, err := time.Parse("2006-01-02", c.Date)
+		if err != nil || c.Date > asOfDate {
+			continue
+		}
+		dist := math.Abs(d.Sub(time.Date(d.Year(), month, day, 0, 0, 0, 0, time.UTC)).Hours())
+		if prev, ok := distances[d.Year()]; ok && (dist > prev || dist == prev && c.Date < covers[d.Year()].Date) {
+			continue
+		}
+		covers[d.Year()] = c
+		distances[d.Year()] = dist
+	}
+	return covers
+}
@@ -0,0 +1,147 @@
+package runoff
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"testing"
+	"time"
+
+	"srmt-admin/internal/lib/model/runoff"
+)
+
+type fakeRepo struct {
+	orgID   *int64
+	inflows []runoff.YearValue
+	covers  []runoff.CoverReading
+}
+
+func (f *fakeRepo) GetReservoirOrganization(_ context.Context, _ int) (string, *int64, error) {
+	return "Чарвак", f.orgID, nil
+}
+
+func (f *fakeRepo) GetSeasonInflowByYears(_ context.Context, _ int, _, _ int) ([]runoff.YearValue, error) {
+	return f.inflows, nil
+}
+
+func (f *fakeRepo) GetSnowCoverNearDay(_ context.Context, _ int64, _, _, _ int) ([]runoff.CoverReading, error) {
+	return f.covers, nil
+}
+
+func discardLogger() *slog.Logger { return slog.New(slog.NewTextHandler(io.Discard, nil)) }
+
+// history builds seasons 2015..2024 where inflow = 30*cover + 500 with a
+// small alternating residual.
+func history() ([]runoff.YearValue, []runoff.CoverReading) {
+	var inflows []runoff.YearValue
+	var covers []runoff.CoverReading
+	for i, year := 0, 2015; year <= 2024; i, year = i+1, year+1 {
+		cover := float64(40 + 4*i)
+		resid := 5.0
+		if i%2 == 1 {
+			resid = -5
+		}
+		inflows = append(inflows, runoff.YearValue{Year: year, Value: 30*cover + 500 + resid, Days: 183})
+		covers = append(covers, runoff.CoverReading{Date: fmt.Sprintf("%d-03-14", year), Cover: cover})
+	}
+	return inflows, covers
+}
+
+func TestForecast_Regression(t *testing.T) {
+	orgID := int64(100)
+	inflows, covers := history()
+	covers = append(covers, runoff.CoverReading{Date: "2026-03-10", Cover: 60})
+	// Incomplete season must be ignored.
+	inflows = append(inflows, runoff.YearValue{Year: 2025, Value: 1, Days: 20})
+
+	svc := NewService(&fakeRepo{orgID: &orgID, inflows: inflows, covers: covers}, discardLogger())
+	f, err := svc.Forecast(context.Background(), 1, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Method != runoff.MethodRegression {
+		t.Fatalf("want regression, got %s", f.Method)
+	}
+	if len(f.Samples) != 10 {
+		t.Errorf("want 10 samples, got %d", len(f.Samples))
+	}
+	if math.Abs(f.Range.Expected-(30*60+500)) > 5 {
+		t.Errorf("want expected ≈ 2300, got %.1f", f.Range.Expected)
+	}
+	if !(f.Range.Low < f.Range.Expected && f.Range.Expected < f.Range.High) {
+		t.Errorf("range not ordered: %+v", f.Range)
+	}
+	if f.SeasonFrom != "2026-04-01" || f.SeasonTo != "2026-09-30" {
+		t.Errorf("unexpected season %s..%s", f.SeasonFrom, f.SeasonTo)
+	}
+}
+
+func TestForecast_ClimatologyWithoutCurrentCover(t *testing.T) {
+	orgID := int64(100)
+	inflows, covers := history()
+	// A reading after the forecast date must not be used.
+	covers = append(covers, runoff.CoverReading{Date: "2026-03-20", Cover: 60})
+
+	svc := NewService(&fakeRepo{orgID: &orgID, inflows: inflows, covers: covers}, discardLogger())
+	f, err := svc.Forecast(context.Background(), 1, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Method != runoff.MethodClimatology || f.Cover != nil {
+		t.Fatalf("want climatology without cover, got %s cover=%v", f.Method, f.Cover)
+	}
+	if f.Range == nil || f.Range.Low > f.Range.Expected || f.Range.Expected > f.Range.High {
+		t.Errorf("bad climatology range: %+v", f.Range)
+	}
+}
+
+func TestForecast_CurrentCoverNotAfterAsOf(t *testing.T) {
+	orgID := int64(100)
+	inflows, covers := history()
+	// The reading a day after the forecast date is closer to it but not yet
+	// known; the one five days before must be used.
+	covers = append(covers,
+		runoff.CoverReading{Date: "2026-03-10", Cover: 60},
+		runoff.CoverReading{Date: "2026-03-16", Cover: 80},
+	)
+
+	svc := NewService(&fakeRepo{orgID: &orgID, inflows: inflows, covers: covers}, discardLogger())
+	f, err := svc.Forecast(context.Background(), 1, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Method != runoff.MethodRegression {
+		t.Fatalf("want regression, got %s", f.Method)
+	}
+	if f.SnowDate == nil || *f.SnowDate != "2026-03-10" || *f.Cover != 60 {
+		t.Errorf("want the 2026-03-10 cover, got %v", f.SnowDate)
+	}
+}
+
+func TestForecast_UnlinkedReservoirHasNoSnow(t *testing.T) {
+	inflows, _ := history()
+	svc := NewService(&fakeRepo{inflows: inflows}, discardLogger())
+
+	f, err := svc.Forecast(context.Background(), 1, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Method != runoff.MethodClimatology || f.OrganizationID != nil {
+		t.Errorf("want climatology for unlinked reservoir, got %s", f.Method)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	v := []float64{5, 1, 3, 2, 4}
+	if got := percentile(v, 0.5); got != 3 {
+		t.Errorf("median: want 3, got %v", got)
+	}
+	if got := percentile(v, 0.1); math.Abs(got-1.4) > 1e-9 {
+		t.Errorf("p10: want 1.4, got %v", got)
+	}
+}
@@ -0,0 +1,21 @@
+package runoff
+
+import (
+	"math"
+	"sort"
+)
+
+// percentile returns the p-th percentile (0..1) of values by linear
+// interpolation between closest ranks. values must be non-empty.
+func percentile(values []float64, p float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	pos := p * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	if lo == hi {
+		return sorted[lo]
+	}
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
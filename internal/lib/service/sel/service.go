@@ -123,7 +123,8 @@ func (s *Service) BuildReport(ctx context.Context, date time.Time, hour int, aut
 // duty_name takes the current-hour value; if absent, falls back to prev.
 // This fallback may surface a months-old duty name when curr is empty —
 // accepted as "show last known operator rather than blank" per business
-// requirement. Documented in docs/sel-export.md.
+// requirement. Documented in docs/sel-export.md. On each record the linked
+// employee (explicit or from the shift roster) wins over the free text.
 //
 // Other fields take their hour's value directly; no cross-hour fallback.
 func (s *Service) buildRow(cfg floodmodel.Config, prev, curr *floodmodel.HourlyRecord) selgen.ReservoirRow {
@@ -153,12 +154,22 @@ func (s *Service) buildRow(cfg floodmodel.Config, prev, curr *floodmodel.HourlyR
 			row.WeatherCondition = *curr.WeatherCondition
 		}
 		row.TemperatureC = curr.TemperatureC
-		if curr.DutyName != nil {
-			row.DutyName = *curr.DutyName
-		}
+		row.DutyName = dutyNameOf(curr)
 	}
-	if row.DutyName == "" && prev != nil && prev.DutyName != nil {
-		row.DutyName = *prev.DutyName
+	if row.DutyName == "" && prev != nil {
+		row.DutyName = dutyNameOf(prev)
 	}
 	return row
 }
+
+// dutyNameOf returns the duty officer's name of one record, preferring the
+// linked employee over the free-text name.
+func dutyNameOf(rec *floodmodel.HourlyRecord) string {
+	if rec.DutyEmployeeName != nil {
+		return *rec.DutyEmployeeName
+	}
+	if rec.DutyName != nil {
+		return *rec.DutyName
+	}
+	return ""
+}
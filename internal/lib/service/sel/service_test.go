@@ -205,6 +205,24 @@ func TestBuildReport_DutyFallbackToPrev(t *testing.T) {
 	}
 }
 
+// A record linked to an employee (explicitly or via the shift roster)
+// reports the employee's name, even over a typed duty_name.
+func TestBuildReport_DutyPrefersLinkedEmployee(t *testing.T) {
+	loc := tashkent(t)
+	tCurr := time.Date(2026, 5, 4, 0, 0, 0, 0, loc)
+	repo := &fakeRepo{
+		rangeOut: []floodmodel.HourlyRecord{
+			{OrganizationID: 96, RecordedAt: tCurr, DutyName: sptr("Петров"), DutyEmployeeName: sptr("Петров Пётр Петрович")},
+		},
+	}
+	cfg := &fakeConfig{out: []floodmodel.Config{{OrganizationID: 96, OrganizationName: "X", SortOrder: 1, IsActive: true}}}
+	svc := NewService(repo, cfg, loc, discardLogger())
+	r, _ := svc.BuildReport(context.Background(), tCurr, 0, "")
+	if r.Reservoirs[0].DutyName != "Петров Пётр Петрович" {
+		t.Errorf("DutyName: want the linked employee, got %q", r.Reservoirs[0].DutyName)
+	}
+}
+
 func TestBuildReport_OrderingFromConfig(t *testing.T) {
 	loc := tashkent(t)
 	tCurr := time.Date(2026, 5, 4, 0, 0, 0, 0, loc)
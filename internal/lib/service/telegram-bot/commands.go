@@ -0,0 +1,222 @@
+package telegrambot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	mwauth "srmt-admin/internal/http-server/middleware/auth"
+	"srmt-admin/internal/lib/service/auth"
+	"srmt-admin/internal/lib/service/telegram"
+	"srmt-admin/internal/storage"
+	"srmt-admin/internal/token"
+)
+
+const helpText = `Команды:
+/levels — уровни водохранилищ
+/production — выработка ГЭС за сегодня
+/discharges — текущие холостые сбросы
+/unlink — отвязать аккаунт
+
+Чтобы привязать аккаунт, получите код в личном кабинете и отправьте /link КОД.`
+
+const notLinkedText = "Аккаунт не привязан. Получите код в личном кабинете и отправьте /link КОД."
+
+// HandleUpdate answers a message sent to the bot. Only private chats are
+// served, so that a link code or data never leaks into a group.
+func (s *Service) HandleUpdate(ctx context.Context, u telegram.Update) {
+	m := u.Message
+	if m == nil || m.Chat.Type != "private" || !strings.HasPrefix(m.Text, "/") {
+		return
+	}
+
+	fields := strings.Fields(m.Text)
+	// "/levels@srmt_bot" is how commands arrive from the command menu
+	cmd, _, _ := strings.Cut(strings.ToLower(fields[0]), "@")
+	arg := strings.Join(fields[1:], "")
+	chatID := m.Chat.ID
+
+	switch cmd {
+	case "/start":
+		if arg != "" {
+			s.link(ctx, chatID, arg)
+			return
+		}
+		s.reply(ctx, chatID, "Бот диспетчерской службы.\n\n"+helpText)
+	case "/help":
+		s.reply(ctx, chatID, helpText)
+	case "/link":
+		if arg == "" {
+			s.reply(ctx, chatID, "Укажите код: /link КОД")
+			return
+		}
+		s.link(ctx, chatID, arg)
+	case "/unlink":
+		if err := s.repo.DeleteTelegramChatByChatID(ctx, chatID); err != nil {
+			s.log.Error("failed to unlink chat", slog.Int64("chat_id", chatID), slog.String("error", err.Error()))
+			s.reply(ctx, chatID, "Не удалось отвязать аккаунт, попробуйте позже.")
+			return
+		}
+		s.reply(ctx, chatID, "Аккаунт отвязан, уведомления больше не придут.")
+	case "/levels", "/production", "/discharges":
+		ctx, err := s.userContext(ctx, chatID)
+		if errors.Is(err, storage.ErrNotFound) {
+			s.reply(ctx, chatID, notLinkedText)
+			return
+		}
+		if err != nil {
+			s.log.Error("failed to resolve chat user", slog.Int64("chat_id", chatID), slog.String("error", err.Error()))
+			s.reply(ctx, chatID, "Не удалось выполнить команду, попробуйте позже.")
+			return
+		}
+
+		var text string
+		switch cmd {
+		case "/levels":
+			text, err = s.levels(ctx)
+		case "/production":
+			text, err = s.production(ctx)
+		default:
+			text, err = s.discharges(ctx)
+		}
+		if err != nil {
+			s.log.Error("command failed", slog.String("command", cmd), slog.String("error", err.Error()))
+			text = "Не удалось получить данные, попробуйте позже."
+		}
+		s.reply(ctx, chatID, text)
+	default:
+		s.reply(ctx, chatID, "Неизвестная команда.\n\n"+helpText)
+	}
+}
+
+func (s *Service) link(ctx context.Context, chatID int64, code string) {
+	userID, err := s.repo.RedeemTelegramLinkCode(ctx, hashCode(code), chatID)
+	if errors.Is(err, storage.ErrNotFound) {
+		s.reply(ctx, chatID, "Код недействителен или устарел. Получите новый код в личном кабинете.")
+		return
+	}
+	if err != nil {
+		s.log.Error("failed to link chat", slog.Int64("chat_id", chatID), slog.String("error", err.Error()))
+		s.reply(ctx, chatID, "Не удалось привязать аккаунт, попробуйте позже.")
+		return
+	}
+
+	s.log.Info("telegram chat linked", slog.Int64("user_id", userID), slog.Int64("chat_id", chatID))
+	s.reply(ctx, chatID, "Аккаунт привязан. Сюда будут приходить уведомления.\n\n"+helpText)
+}
+
+// userContext returns ctx carrying the claims of the user linked to the
+// chat, so that commands are scoped by auth.CheckOrgAccess like HTTP
+// requests.
+func (s *Service) userContext(ctx context.Context, chatID int64) (context.Context, error) {
+	userID, err := s.repo.GetTelegramChatUserID(ctx, chatID)
+	if err != nil {
+		return ctx, err
+	}
+	u, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return ctx, err
+	}
+	if !u.IsActive {
+		return ctx, storage.ErrNotFound
+	}
+	return mwauth.ContextWithClaims(ctx, &token.Claims{
+		UserID:          u.ID,
+		ContactID:       u.ContactID,
+		OrganizationIDs: u.OrganizationIDs,
+		Name:            u.Name,
+		Roles:           u.Roles,
+	}), nil
+}
+
+func allowed(ctx context.Context, orgID int64) bool {
+	return auth.CheckOrgAccess(ctx, orgID) == nil
+}
+
+func (s *Service) today() time.Time {
+	return time.Now().In(s.loc)
+}
+
+// levels lists the reservoir levels of today's summary.
+func (s *Service) levels(ctx context.Context) (string, error) {
+	day := s.today()
+	rows, err := s.repo.GetReservoirSummary(ctx, day.Format(time.DateOnly))
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, row := range rows {
+		// The total row has no organization
+		if row.OrganizationID == nil || !allowed(ctx, *row.OrganizationID) {
+			continue
+		}
+		fmt.Fprintf(&b, "\n• %s: %.2f м (%s за сутки), объём %.1f млн м³",
+			row.OrganizationName, row.Level.Current, signed(row.Level.Current-row.Level.Previous), row.Volume.Current)
+	}
+	if b.Len() == 0 {
+		return "Нет данных по доступным водохранилищам.", nil
+	}
+	return fmt.Sprintf("Уровни водохранилищ на %s:%s", day.Format("02.01.2006"), b.String()), nil
+}
+
+// production lists today's production of the stations of the GES report.
+func (s *Service) production(ctx context.Context) (string, error) {
+	day := s.today()
+	report, err := s.reports.BuildDailyReport(ctx, day.Format(time.DateOnly), nil)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	var total, power float64
+	for _, c := range report.Cascades {
+		for _, st := range c.Stations {
+			if !allowed(ctx, st.OrganizationID) {
+				continue
+			}
+			fmt.Fprintf(&b, "\n• %s: %.3f млн кВт·ч, %.1f МВт", st.Name, st.Current.DailyProductionMlnKWh, st.Current.PowerMWt)
+			total += st.Current.DailyProductionMlnKWh
+			power += st.Current.PowerMWt
+		}
+	}
+	if b.Len() == 0 {
+		return "Нет данных по доступным станциям.", nil
+	}
+	fmt.Fprintf(&b, "\n\nИтого: %.3f млн кВт·ч, %.1f МВт", total, power)
+	return fmt.Sprintf("Выработка на %s:%s", day.Format("02.01.2006"), b.String()), nil
+}
+
+// discharges lists the idle discharges going on now.
+func (s *Service) discharges(ctx context.Context) (string, error) {
+	list, err := s.repo.GetCurrentDischarges(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, d := range list {
+		if d.Organization == nil || !allowed(ctx, d.Organization.ID) {
+			continue
+		}
+		fmt.Fprintf(&b, "\n• %s: %.1f м³/с с %s", d.Organization.Name, d.FlowRate, d.StartedAt.In(s.loc).Format("02.01 15:04"))
+		if d.Reason != nil && *d.Reason != "" {
+			fmt.Fprintf(&b, " (%s)", *d.Reason)
+		}
+	}
+	if b.Len() == 0 {
+		return "Холостых сбросов сейчас нет.", nil
+	}
+	return "Текущие холостые сбросы:" + b.String(), nil
+}
+
+func signed(v float64) string {
+	if math.Abs(v) < 0.005 {
+		return "±0.00 м"
+	}
+	return fmt.Sprintf("%+.2f м", v)
+}
@@ -0,0 +1,167 @@
+package telegrambot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"srmt-admin/internal/lib/service/notification"
+)
+
+const retryInterval = 5 * time.Second
+
+// Start receives updates and sends the morning report until ctx is
+// cancelled. In webhook mode it registers the webhook; updates then arrive
+// through the HTTP handler.
+func (s *Service) Start(ctx context.Context) {
+	s.log.Info("telegram bot started", slog.String("mode", s.opts.Mode), slog.String("report_time", s.opts.ReportTime))
+
+	if s.opts.ReportTime != "" {
+		go s.runReports(ctx)
+	}
+
+	if s.opts.Mode == ModeWebhook {
+		s.retry(ctx, "set webhook", func() error {
+			return s.client.SetWebhook(ctx, s.opts.WebhookURL, s.opts.WebhookSecret)
+		})
+		<-ctx.Done()
+	} else {
+		s.retry(ctx, "delete webhook", func() error {
+			return s.client.DeleteWebhook(ctx)
+		})
+		s.poll(ctx)
+	}
+	s.log.Info("telegram bot stopped")
+}
+
+// retry calls fn until it succeeds or ctx is cancelled.
+func (s *Service) retry(ctx context.Context, what string, fn func() error) {
+	for {
+		err := fn()
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		s.log.Error("telegram: failed to "+what, slog.String("error", err.Error()))
+		if !sleep(ctx, retryInterval) {
+			return
+		}
+	}
+}
+
+// poll long-polls for updates. Asking for the updates after the last one
+// confirms it, so an update is handled once even across restarts.
+func (s *Service) poll(ctx context.Context) {
+	var offset int64
+	for ctx.Err() == nil {
+		updates, err := s.client.GetUpdates(ctx, offset, s.opts.PollTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.log.Error("telegram: failed to get updates", slog.String("error", err.Error()))
+			sleep(ctx, retryInterval)
+			continue
+		}
+		for _, u := range updates {
+			s.HandleUpdate(ctx, u)
+			offset = u.UpdateID + 1
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// nextReport returns the first report time after now.
+func (s *Service) nextReport(now time.Time) (time.Time, error) {
+	at, err := time.ParseInLocation("15:04", s.opts.ReportTime, s.loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid report time %q: %w", s.opts.ReportTime, err)
+	}
+	now = now.In(s.loc)
+	next := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, s.loc)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next, nil
+}
+
+func (s *Service) runReports(ctx context.Context) {
+	for {
+		next, err := s.nextReport(time.Now())
+		if err != nil {
+			s.log.Error("morning report disabled", slog.String("error", err.Error()))
+			return
+		}
+		if !sleep(ctx, time.Until(next)) {
+			return
+		}
+
+		date := next.AddDate(0, 0, -1).Format(time.DateOnly)
+		if err := s.SendMorningReport(ctx, date); err != nil {
+			s.log.Error("failed to send morning report", slog.String("date", date), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// SendMorningReport sends the GES report of date (YYYY-MM-DD) to the
+// report roles. The report covers the whole cascade, so the roles should
+// be ones with access to every organization.
+func (s *Service) SendMorningReport(ctx context.Context, date string) error {
+	const op = "service.telegram-bot.SendMorningReport"
+
+	report, err := s.reports.BuildDailyReport(ctx, date, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	day, err := time.ParseInLocation(time.DateOnly, date, s.loc)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var b strings.Builder
+	for _, c := range report.Cascades {
+		if c.Summary == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %.3f млн кВт·ч (%s)\n", c.CascadeName, c.Summary.DailyProductionMlnKWh, fulfillment(c.Summary.FulfillmentPct))
+	}
+	if t := report.GrandTotal; t != nil {
+		fmt.Fprintf(&b, "\nИтого: %.3f млн кВт·ч, с начала месяца %.3f, с начала года %.3f млн кВт·ч.\nАгрегатов в работе %d из %d, в ремонте %d.",
+			t.DailyProductionMlnKWh, t.MTDProductionMlnKWh, t.YTDProductionMlnKWh,
+			t.WorkingAggregates, t.TotalAggregates, t.RepairAggregates)
+		if t.IdleDischargeM3s > 0 {
+			fmt.Fprintf(&b, "\nХолостые сбросы: %.1f м³/с.", t.IdleDischargeM3s)
+		}
+	}
+
+	err = s.notifier.Notify(ctx, notification.Event{
+		Type:    notification.GESDailyReport,
+		Roles:   s.opts.ReportRoles,
+		Title:   "Сводка ГЭС за " + day.Format("02.01.2006"),
+		Message: strings.TrimSpace(b.String()),
+		Link:    "/ges-report?date=" + date,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// fulfillment formats the share of the quarterly plan produced.
+func fulfillment(ratio *float64) string {
+	if ratio == nil {
+		return "план не задан"
+	}
+	return fmt.Sprintf("квартальный план выполнен на %.1f%%", *ratio*100)
+}
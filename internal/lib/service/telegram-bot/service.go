@@ -0,0 +1,179 @@
+package telegrambot
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"srmt-admin/internal/lib/model/discharge"
+	gesreport "srmt-admin/internal/lib/model/ges-report"
+	reservoirsummary "srmt-admin/internal/lib/model/reservoir-summary"
+	model "srmt-admin/internal/lib/model/telegram-bot"
+	"srmt-admin/internal/lib/model/user"
+	"srmt-admin/internal/lib/service/notification"
+	"srmt-admin/internal/lib/service/telegram"
+	"srmt-admin/internal/storage"
+)
+
+// Modes of receiving updates
+const (
+	ModePolling = "polling"
+	ModeWebhook = "webhook"
+)
+
+const (
+	codeLength = 8
+	// codeAlphabet has 32 symbols without look-alikes (0/O, 1/I)
+	codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+type Repository interface {
+	SaveTelegramLinkCode(ctx context.Context, userID int64, codeHash string, expiresAt time.Time) error
+	RedeemTelegramLinkCode(ctx context.Context, codeHash string, chatID int64) (int64, error)
+	GetTelegramChatUserID(ctx context.Context, chatID int64) (int64, error)
+	GetTelegramLinkedAt(ctx context.Context, contactID int64) (time.Time, error)
+	DeleteTelegramChat(ctx context.Context, contactID int64) error
+	DeleteTelegramChatByChatID(ctx context.Context, chatID int64) error
+	GetUserByID(ctx context.Context, id int64) (*user.Model, error)
+	GetReservoirSummary(ctx context.Context, date string) ([]*reservoirsummary.ResponseModel, error)
+	GetCurrentDischarges(ctx context.Context) ([]discharge.Model, error)
+}
+
+// ReportBuilder builds the GES daily report
+type ReportBuilder interface {
+	BuildDailyReport(ctx context.Context, date string, cascadeOrgID *int64) (*gesreport.DailyReport, error)
+}
+
+// Notifier delivers the morning report to its recipients
+type Notifier interface {
+	Notify(ctx context.Context, e notification.Event) error
+}
+
+// Options configure the bot.
+type Options struct {
+	// Mode is ModePolling or ModeWebhook. Long polling works from one
+	// instance only; run several instances in webhook mode.
+	Mode          string
+	WebhookURL    string
+	WebhookSecret string
+	// BotUsername, without @, lets link codes come with a t.me link
+	BotUsername string
+	PollTimeout time.Duration
+	LinkCodeTTL time.Duration
+	// ReportTime is the local "15:04" time of the morning GES report for
+	// the previous day; empty turns the report off
+	ReportTime  string
+	ReportRoles []string
+}
+
+// Service is the Telegram bot: it links chats to users, answers commands
+// within the organizations of the linked user and sends the morning GES
+// report. Event notifications reach linked chats through the notification
+// service.
+type Service struct {
+	client   *telegram.Client
+	repo     Repository
+	reports  ReportBuilder
+	notifier Notifier
+	opts     Options
+	loc      *time.Location
+	log      *slog.Logger
+}
+
+func NewService(client *telegram.Client, repo Repository, reports ReportBuilder, notifier Notifier, opts Options, loc *time.Location, log *slog.Logger) *Service {
+	if opts.Mode == "" {
+		opts.Mode = ModePolling
+	}
+	if opts.PollTimeout <= 0 {
+		opts.PollTimeout = 25 * time.Second
+	}
+	if opts.LinkCodeTTL <= 0 {
+		opts.LinkCodeTTL = 10 * time.Minute
+	}
+	return &Service{
+		client:   client,
+		repo:     repo,
+		reports:  reports,
+		notifier: notifier,
+		opts:     opts,
+		loc:      loc,
+		log:      log.With(slog.String("service", "telegram-bot")),
+	}
+}
+
+// hashCode normalises a code as typed by the user and hashes it.
+func hashCode(code string) string {
+	code = strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+func newCode() (string, error) {
+	b := make([]byte, codeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = codeAlphabet[int(b[i])%len(codeAlphabet)]
+	}
+	return string(b), nil
+}
+
+// CreateLinkCode issues a one-time code that links the chat it is sent
+// from to the user. It replaces the previous code of the user.
+func (s *Service) CreateLinkCode(ctx context.Context, userID int64) (*model.LinkCode, error) {
+	const op = "service.telegram-bot.CreateLinkCode"
+
+	code, err := newCode()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	expiresAt := time.Now().Add(s.opts.LinkCodeTTL)
+	if err := s.repo.SaveTelegramLinkCode(ctx, userID, hashCode(code), expiresAt); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	lc := &model.LinkCode{Code: code, ExpiresAt: expiresAt}
+	if s.opts.BotUsername != "" {
+		u := fmt.Sprintf("https://t.me/%s?start=%s", s.opts.BotUsername, code)
+		lc.BotURL = &u
+	}
+	return lc, nil
+}
+
+// Link returns whether the contact has a linked chat.
+func (s *Service) Link(ctx context.Context, contactID int64) (*model.Link, error) {
+	const op = "service.telegram-bot.Link"
+
+	linkedAt, err := s.repo.GetTelegramLinkedAt(ctx, contactID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return &model.Link{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &model.Link{Linked: true, LinkedAt: &linkedAt}, nil
+}
+
+// Unlink removes the chat of the contact.
+func (s *Service) Unlink(ctx context.Context, contactID int64) error {
+	const op = "service.telegram-bot.Unlink"
+
+	if err := s.repo.DeleteTelegramChat(ctx, contactID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// reply sends text to a chat; failures are logged only.
+func (s *Service) reply(ctx context.Context, chatID int64, text string) {
+	if err := s.client.SendMessage(ctx, chatID, text); err != nil {
+		s.log.Warn("failed to reply", slog.Int64("chat_id", chatID), slog.String("error", err.Error()))
+	}
+}
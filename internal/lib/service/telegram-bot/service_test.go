@@ -0,0 +1,284 @@
+package telegrambot
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"srmt-admin/internal/lib/model/discharge"
+	gesreport "srmt-admin/internal/lib/model/ges-report"
+	"srmt-admin/internal/lib/model/organization"
+	reservoirsummary "srmt-admin/internal/lib/model/reservoir-summary"
+	"srmt-admin/internal/lib/model/user"
+	"srmt-admin/internal/lib/service/notification"
+	"srmt-admin/internal/lib/service/telegram/telegramtest"
+	"srmt-admin/internal/storage"
+)
+
+type fakeRepo struct {
+	codes map[string]int64 // hash -> user
+	chats map[int64]int64  // chat -> user
+	users map[int64]*user.Model
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		codes: make(map[string]int64),
+		chats: make(map[int64]int64),
+		users: map[int64]*user.Model{
+			1: {ID: 1, ContactID: 101, IsActive: true, Roles: []string{"cascade"}, OrganizationIDs: []int64{10}},
+			2: {ID: 2, ContactID: 102, IsActive: true, Roles: []string{"sc"}},
+		},
+	}
+}
+
+func (f *fakeRepo) SaveTelegramLinkCode(_ context.Context, userID int64, codeHash string, _ time.Time) error {
+	for h, u := range f.codes {
+		if u == userID {
+			delete(f.codes, h)
+		}
+	}
+	f.codes[codeHash] = userID
+	return nil
+}
+
+func (f *fakeRepo) RedeemTelegramLinkCode(_ context.Context, codeHash string, chatID int64) (int64, error) {
+	userID, ok := f.codes[codeHash]
+	if !ok {
+		return 0, storage.ErrNotFound
+	}
+	delete(f.codes, codeHash)
+	f.chats[chatID] = userID
+	return userID, nil
+}
+
+func (f *fakeRepo) GetTelegramChatUserID(_ context.Context, chatID int64) (int64, error) {
+	userID, ok := f.chats[chatID]
+	if !ok {
+		return 0, storage.ErrNotFound
+	}
+	return userID, nil
+}
+
+func (f *fakeRepo) GetTelegramLinkedAt(_ context.Context, _ int64) (time.Time, error) {
+	return time.Time{}, storage.ErrNotFound
+}
+
+func (f *fakeRepo) DeleteTelegramChat(_ context.Context, _ int64) error { return nil }
+
+func (f *fakeRepo) DeleteTelegramChatByChatID(_ context.Context, chatID int64) error {
+	delete(f.chats, chatID)
+	return nil
+}
+
+func (f *fakeRepo) GetUserByID(_ context.Context, id int64) (*user.Model, error) {
+	u, ok := f.users[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return u, nil
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func (f *fakeRepo) GetReservoirSummary(_ context.Context, _ string) ([]*reservoirsummary.ResponseModel, error) {
+	return []*reservoirsummary.ResponseModel{
+		{OrganizationID: ptr(int64(10)), OrganizationName: "Чарвакское", Level: reservoirsummary.ValueResponse{Current: 885.12, Previous: 885.02}},
+		{OrganizationID: ptr(int64(20)), OrganizationName: "Андижанское", Level: reservoirsummary.ValueResponse{Current: 890, Previous: 890}},
+		{OrganizationName: "ИТОГО"},
+	}, nil
+}
+
+func (f *fakeRepo) GetCurrentDischarges(_ context.Context) ([]discharge.Model, error) {
+	return []discharge.Model{
+		{Organization: &organization.Model{ID: 10, Name: "Чарвакская ГЭС"}, FlowRate: 120, StartedAt: time.Now()},
+		{Organization: &organization.Model{ID: 20, Name: "Андижанская ГЭС"}, FlowRate: 80, StartedAt: time.Now()},
+	}, nil
+}
+
+type fakeReports struct{}
+
+func (fakeReports) BuildDailyReport(_ context.Context, date string, _ *int64) (*gesreport.DailyReport, error) {
+	return &gesreport.DailyReport{
+		Date: date,
+		Cascades: []gesreport.CascadeReport{{
+			CascadeName: "Чирчикский каскад",
+			Summary:     &gesreport.SummaryBlock{DailyProductionMlnKWh: 4.5, FulfillmentPct: ptr(0.97)},
+			Stations: []gesreport.StationReport{
+				{OrganizationID: 10, Name: "Чарвакская ГЭС", Current: gesreport.CurrentData{DailyProductionMlnKWh: 3}},
+				{OrganizationID: 20, Name: "Андижанская ГЭС", Current: gesreport.CurrentData{DailyProductionMlnKWh: 1.5}},
+			},
+		}},
+		GrandTotal: &gesreport.SummaryBlock{DailyProductionMlnKWh: 4.5},
+	}, nil
+}
+
+type fakeNotifier struct {
+	events []notification.Event
+}
+
+func (f *fakeNotifier) Notify(_ context.Context, e notification.Event) error {
+	f.events = append(f.events, e)
+	return nil
+}
+
+func newTestBot(t *testing.T, opts Options) (*Service, *fakeRepo, *fakeNotifier, *telegramtest.Server) {
+	t.Helper()
+	srv := telegramtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	repo := newFakeRepo()
+	notifier := &fakeNotifier{}
+	svc := NewService(srv.Client(), repo, fakeReports{}, notifier, opts, time.UTC,
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+	return svc, repo, notifier, srv
+}
+
+func lastReply(t *testing.T, srv *telegramtest.Server, chatID int64) string {
+	t.Helper()
+	sent := srv.Sent()
+	if len(sent) == 0 {
+		t.Fatal("no reply sent")
+	}
+	last := sent[len(sent)-1]
+	if last.ChatID != chatID {
+		t.Fatalf("reply went to chat %d, want %d", last.ChatID, chatID)
+	}
+	return last.Text
+}
+
+func TestLinkAndScopedCommands(t *testing.T) {
+	svc, repo, _, srv := newTestBot(t, Options{BotUsername: "srmt_bot"})
+	ctx := context.Background()
+
+	svc.HandleUpdate(ctx, srv.PushMessage(500, "/levels"))
+	if got := lastReply(t, srv, 500); got != notLinkedText {
+		t.Errorf("unlinked chat: %q", got)
+	}
+
+	code, err := svc.CreateLinkCode(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code.BotURL == nil || *code.BotURL != "https://t.me/srmt_bot?start="+code.Code {
+		t.Errorf("bot url = %v", code.BotURL)
+	}
+
+	// Codes are accepted in any case and with separators
+	typed := strings.ToLower(code.Code[:4]) + "-" + code.Code[4:]
+	svc.HandleUpdate(ctx, srv.PushMessage(500, "/link "+typed))
+	if got := lastReply(t, srv, 500); !strings.HasPrefix(got, "Аккаунт привязан") {
+		t.Fatalf("link reply: %q", got)
+	}
+	if repo.chats[500] != 1 {
+		t.Fatalf("chat not linked: %v", repo.chats)
+	}
+
+	// The code is single-use
+	svc.HandleUpdate(ctx, srv.PushMessage(501, "/start "+code.Code))
+	if got := lastReply(t, srv, 501); !strings.HasPrefix(got, "Код недействителен") {
+		t.Errorf("reused code: %q", got)
+	}
+
+	// User 1 only has access to organization 10
+	for _, cmd := range []string{"/levels", "/discharges", "/production@srmt_bot"} {
+		svc.HandleUpdate(ctx, srv.PushMessage(500, cmd))
+		got := lastReply(t, srv, 500)
+		if !strings.Contains(got, "Чарвакск") || strings.Contains(got, "Андижанск") || strings.Contains(got, "ИТОГО") {
+			t.Errorf("%s is not scoped to the user's organizations:\n%s", cmd, got)
+		}
+	}
+	if got := lastReply(t, srv, 500); !strings.Contains(got, "Итого: 3.000 млн кВт·ч") {
+		t.Errorf("production total must only count accessible stations:\n%s", got)
+	}
+
+	svc.HandleUpdate(ctx, srv.PushMessage(500, "/unlink"))
+	if _, ok := repo.chats[500]; ok {
+		t.Error("chat still linked after /unlink")
+	}
+}
+
+func TestFullAccessRoleSeesEverything(t *testing.T) {
+	svc, repo, _, srv := newTestBot(t, Options{})
+	repo.chats[600] = 2
+
+	svc.HandleUpdate(context.Background(), srv.PushMessage(600, "/discharges"))
+	got := lastReply(t, srv, 600)
+	if !strings.Contains(got, "Чарвакская ГЭС: 120.0 м³/с") || !strings.Contains(got, "Андижанская ГЭС: 80.0 м³/с") {
+		t.Errorf("sc must see all discharges:\n%s", got)
+	}
+}
+
+func TestHandleUpdate_IgnoresGroups(t *testing.T) {
+	svc, _, _, srv := newTestBot(t, Options{})
+
+	u := srv.PushMessage(-100, "/help")
+	u.Message.Chat.Type = "group"
+	svc.HandleUpdate(context.Background(), u)
+	if len(srv.Sent()) != 0 {
+		t.Errorf("bot answered in a group: %v", srv.Sent())
+	}
+}
+
+func TestStart_Polling(t *testing.T) {
+	svc, _, _, srv := newTestBot(t, Options{PollTimeout: time.Second})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		svc.Start(ctx)
+		close(done)
+	}()
+
+	srv.PushMessage(700, "/help")
+	deadline := time.Now().Add(5 * time.Second)
+	for len(srv.Sent()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if sent := srv.Sent(); len(sent) != 1 || sent[0].Text != helpText {
+		t.Fatalf("sent = %v", sent)
+	}
+}
+
+func TestSendMorningReport(t *testing.T) {
+	svc, _, notifier, _ := newTestBot(t, Options{ReportRoles: []string{"sc", "rais"}})
+
+	if err := svc.SendMorningReport(context.Background(), "2026-10-17"); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.events) != 1 {
+		t.Fatalf("events = %v", notifier.events)
+	}
+	e := notifier.events[0]
+	if e.Type != notification.GESDailyReport || len(e.Roles) != 2 || e.Title != "Сводка ГЭС за 17.10.2026" {
+		t.Errorf("event = %+v", e)
+	}
+	if !strings.Contains(e.Message, "квартальный план выполнен на 97.0%") {
+		t.Errorf("message:\n%s", e.Message)
+	}
+}
+
+func TestNextReport(t *testing.T) {
+	svc, _, _, _ := newTestBot(t, Options{ReportTime: "08:00"})
+
+	tests := []struct {
+		now, want time.Time
+	}{
+		{time.Date(2026, 10, 18, 7, 59, 0, 0, time.UTC), time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC), time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := svc.nextReport(tt.now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("nextReport(%v) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultBaseURL = "https://api.telegram.org"
+
+// APIError is an error answer of the Bot API.
+type APIError struct {
+	Code        int
+	Description string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("telegram: API returned %d: %s", e.Code, e.Description)
+}
+
+// Permanent reports whether repeating the request cannot succeed: the chat
+// does not exist, the bot was blocked by the user or the request is invalid.
+func (e *APIError) Permanent() bool {
+	return e.Code == http.StatusBadRequest || e.Code == http.StatusForbidden
+}
+
+// Client calls the Telegram Bot API.
+type Client struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+// NewClient creates a Bot API client. An empty baseURL selects the public
+// API; a self-hosted Bot API server or a test fake may be given instead.
+func NewClient(client *http.Client, baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Client{
+		client:  client,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+	}
+}
+
+// SendMessage sends a plain text message to a chat.
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string) error {
+	return c.call(ctx, "sendMessage", map[string]any{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	}, nil)
+}
+
+// GetUpdates long-polls for updates with an ID of at least offset, waiting
+// up to timeout when there are none. The HTTP client timeout must exceed it.
+func (c *Client) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error) {
+	var updates []Update
+	err := c.call(ctx, "getUpdates", map[string]any{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	return updates, err
+}
+
+// SetWebhook makes Telegram push updates to url. Every request carries
+// secret in the X-Telegram-Bot-Api-Secret-Token header.
+func (c *Client) SetWebhook(ctx context.Context, url, secret string) error {
+	return c.call(ctx, "setWebhook", map[string]any{
+		"url":             url,
+		"secret_token":    secret,
+		"allowed_updates": []string{"message"},
+	}, nil)
+}
+
+// DeleteWebhook turns the webhook off so that GetUpdates can be used.
+func (c *Client) DeleteWebhook(ctx context.Context) error {
+	return c.call(ctx, "deleteWebhook", map[string]any{}, nil)
+}
+
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
+}
+
+// call posts params to a Bot API method and decodes its result into out
+// when out is non-nil.
+func (c *Client) call(ctx context.Context, method string, params any, out any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("telegram: encode %s: %w", method, err)
+	}
+
+	endpoint := fmt.Sprintf("%s/bot%s/%s", c.baseURL, c.token, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("telegram: create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// The URL carries the token; keep it out of logs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram: %s request failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var result apiResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return fmt.Errorf("telegram: decode %s response (status %d): %w", method, resp.StatusCode, err)
+	}
+	if !result.OK {
+		code := result.ErrorCode
+		if code == 0 {
+			code = resp.StatusCode
+		}
+		return &APIError{Code: code, Description: result.Description}
+	}
+	if out != nil {
+		if err := json.Unmarshal(result.Result, out); err != nil {
+			return fmt.Errorf("telegram: decode %s result: %w", method, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package telegram_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"srmt-admin/internal/lib/service/telegram"
+	"srmt-admin/internal/lib/service/telegram/telegramtest"
+)
+
+func TestSendMessage_BlockedChatIsPermanent(t *testing.T) {
+	srv := telegramtest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+
+	if err := client.SendMessage(ctx, 1, "hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.Block(2)
+	err := client.SendMessage(ctx, 2, "hello")
+	var apiErr *telegram.APIError
+	if !errors.As(err, &apiErr) || !apiErr.Permanent() {
+		t.Fatalf("err = %v, want a permanent API error", err)
+	}
+
+	bad := telegram.NewClient(http.DefaultClient, srv.URL(), "wrong")
+	if err := bad.SendMessage(ctx, 1, "hello"); err == nil {
+		t.Fatal("wrong token accepted")
+	}
+	if sent := srv.Sent(); len(sent) != 1 || sent[0].Text != "hello" {
+		t.Errorf("sent = %v", sent)
+	}
+}
@@ -0,0 +1,199 @@
+// Package telegramtest provides a local fake of the Telegram Bot API for
+// tests: it records sent messages and serves queued updates.
+package telegramtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"srmt-admin/internal/lib/service/telegram"
+)
+
+// Token is the bot token the fake accepts.
+const Token = "123456:TEST"
+
+// SentMessage is a message sent through the fake.
+type SentMessage struct {
+	ChatID int64  `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+// Server is a fake Bot API server.
+type Server struct {
+	srv *httptest.Server
+
+	mu         sync.Mutex
+	pushed     chan struct{}
+	nextUpdate int64
+	updates    []telegram.Update
+	sent       []SentMessage
+	webhook    string
+	// blocked chats answer sendMessage with 403, as after the user blocked
+	// the bot
+	blocked map[int64]bool
+}
+
+func NewServer() *Server {
+	s := &Server{nextUpdate: 1, pushed: make(chan struct{}, 1), blocked: make(map[int64]bool)}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *Server) Close() { s.srv.Close() }
+
+// URL is the base URL to create clients with.
+func (s *Server) URL() string { return s.srv.URL }
+
+// Client returns a Bot API client talking to the fake.
+func (s *Server) Client() *telegram.Client {
+	return telegram.NewClient(s.srv.Client(), s.srv.URL, Token)
+}
+
+// PushMessage queues a text message from chatID and returns its update.
+func (s *Server) PushMessage(chatID int64, text string) telegram.Update {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := telegram.Update{
+		UpdateID: s.nextUpdate,
+		Message: &telegram.Message{
+			MessageID: s.nextUpdate,
+			From:      &telegram.User{ID: chatID, FirstName: "Test"},
+			Chat:      telegram.Chat{ID: chatID, Type: "private"},
+			Text:      text,
+		},
+	}
+	s.nextUpdate++
+	s.updates = append(s.updates, u)
+	select {
+	case s.pushed <- struct{}{}:
+	default:
+	}
+	return u
+}
+
+// Block makes messages to chatID fail as if the user blocked the bot.
+func (s *Server) Block(chatID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blocked[chatID] = true
+}
+
+// Sent returns the messages sent so far.
+func (s *Server) Sent() []SentMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SentMessage(nil), s.sent...)
+}
+
+// Pending returns how many queued updates were not confirmed yet.
+func (s *Server) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.updates)
+}
+
+// Webhook returns the URL set by setWebhook.
+func (s *Server) Webhook() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.webhook
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	method, ok := strings.CutPrefix(r.URL.Path, "/bot"+Token+"/")
+	if !ok {
+		reply(w, http.StatusUnauthorized, nil, "Unauthorized")
+		return
+	}
+
+	var params map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		reply(w, http.StatusBadRequest, nil, "Bad Request: invalid JSON")
+		return
+	}
+
+	if method == "getUpdates" {
+		s.getUpdates(w, r, params)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch method {
+	case "sendMessage":
+		chatID := int64(number(params["chat_id"]))
+		if s.blocked[chatID] {
+			reply(w, http.StatusForbidden, nil, "Forbidden: bot was blocked by the user")
+			return
+		}
+		text, _ := params["text"].(string)
+		s.sent = append(s.sent, SentMessage{ChatID: chatID, Text: text})
+		reply(w, http.StatusOK, map[string]any{"message_id": len(s.sent), "chat": map[string]any{"id": chatID}}, "")
+	case "setWebhook":
+		s.webhook, _ = params["url"].(string)
+		reply(w, http.StatusOK, true, "")
+	case "deleteWebhook":
+		s.webhook = ""
+		reply(w, http.StatusOK, true, "")
+	default:
+		reply(w, http.StatusNotFound, nil, "Not Found: method not found")
+	}
+}
+
+// getUpdates drops the updates below offset, as confirmed, and answers
+// with the rest, waiting up to the timeout for one when there are none.
+func (s *Server) getUpdates(w http.ResponseWriter, r *http.Request, params map[string]any) {
+	offset := int64(number(params["offset"]))
+	timer := time.NewTimer(time.Duration(number(params["timeout"])) * time.Second)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		kept := s.updates[:0]
+		for _, u := range s.updates {
+			if u.UpdateID >= offset {
+				kept = append(kept, u)
+			}
+		}
+		s.updates = kept
+		updates := append([]telegram.Update{}, s.updates...)
+		s.mu.Unlock()
+
+		if len(updates) > 0 {
+			reply(w, http.StatusOK, updates, "")
+			return
+		}
+		select {
+		case <-s.pushed:
+		case <-timer.C:
+			reply(w, http.StatusOK, updates, "")
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func number(v any) float64 {
+	f, _ := v.(float64)
+	return f
+}
+
+func reply(w http.ResponseWriter, status int, result any, description string) {
+	body := map[string]any{"ok": status == http.StatusOK}
+	if status == http.StatusOK {
+		body["result"] = result
+	} else {
+		body["error_code"] = status
+		body["description"] = description
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,373 @@
+// Package uploadsession implements resumable uploads of large files. A
+// session is a multipart upload in object storage: each chunk the client
+// sends at the session offset becomes one part, so a broken link costs only
+// the chunk in flight. A finished session is assembled into the object and
+// stored as a file through the file lifecycle service (scan, deduplication).
+// Sessions left idle past their TTL are aborted by an hourly cleanup.
+package uploadsession
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"srmt-admin/internal/lib/model/category"
+	"srmt-admin/internal/lib/model/file"
+	filelifecycle "srmt-admin/internal/lib/service/file-lifecycle"
+	"srmt-admin/internal/storage"
+	"srmt-admin/internal/storage/minio"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTooLarge         = errors.New("file exceeds the maximum upload size")
+	ErrUnknownCategory  = errors.New("file category not found")
+	ErrOffsetMismatch   = errors.New("chunk does not start at the upload offset")
+	ErrChunkTooSmall    = errors.New("only the last chunk may be smaller than the minimum chunk size")
+	ErrChunkPastEnd     = errors.New("chunk goes past the declared file size")
+	ErrTooManyParts     = errors.New("upload has too many chunks")
+	ErrUploadIncomplete = errors.New("upload is not complete")
+	ErrUploadFinished   = errors.New("upload is already finished")
+)
+
+// OffsetError is returned for a chunk that does not start at the session
+// offset; Offset is where the next chunk must start.
+type OffsetError struct {
+	Offset int64
+}
+
+func (e *OffsetError) Error() string {
+	return fmt.Sprintf("%s: expected offset %d", ErrOffsetMismatch, e.Offset)
+}
+
+func (e *OffsetError) Is(target error) bool { return target == ErrOffsetMismatch }
+
+const (
+	// MinChunkSize is the smallest part object storage accepts; only the
+	// last chunk may be smaller.
+	MinChunkSize = 5 << 20
+	// MaxChunkSize bounds a single chunk request.
+	MaxChunkSize = 64 << 20
+
+	maxParts           = 10000
+	defaultCategoryID  = 1 // "other" category fallback
+	defaultContentType = "application/octet-stream"
+	expiredBatchSize   = 100
+	cleanupInterval    = time.Hour
+)
+
+type Repository interface {
+	GetCategoryByID(ctx context.Context, id int64) (category.Model, error)
+	AddUploadSession(ctx context.Context, s file.UploadSession) error
+	GetUploadSession(ctx context.Context, id string) (*file.UploadSession, error)
+	AdvanceUploadSession(ctx context.Context, id string, offset int64, part file.UploadPart, expiresAt time.Time) (bool, error)
+	MarkUploadAssembled(ctx context.Context, id string) error
+	FinishUploadSession(ctx context.Context, id string, fileID int64, expiresAt time.Time) error
+	DeleteUploadSession(ctx context.Context, id string) error
+	GetExpiredUploadSessions(ctx context.Context, limit int) ([]*file.UploadSession, error)
+}
+
+type ObjectStorage interface {
+	NewMultipartUpload(ctx context.Context, objectName, contentType string) (string, error)
+	UploadPart(ctx context.Context, objectName, uploadID string, number int, reader io.Reader, size int64) (string, error)
+	CompleteMultipartUpload(ctx context.Context, objectName, uploadID string, parts []minio.Part) error
+	AbortMultipartUpload(ctx context.Context, objectName, uploadID string) error
+}
+
+type FileStore interface {
+	StoreUploaded(ctx context.Context, u filelifecycle.Upload) (*filelifecycle.Stored, error)
+}
+
+type Service struct {
+	repo    Repository
+	storage ObjectStorage
+	files   FileStore
+	maxSize int64
+	ttl     time.Duration
+	loc     *time.Location
+	log     *slog.Logger
+}
+
+// NewService creates the service. maxSize caps the declared file size; ttl
+// is how long a session may stay idle before it is aborted.
+func NewService(repo Repository, storage ObjectStorage, files FileStore, maxSize int64, ttl time.Duration, loc *time.Location, log *slog.Logger) *Service {
+	return &Service{
+		repo:    repo,
+		storage: storage,
+		files:   files,
+		maxSize: maxSize,
+		ttl:     ttl,
+		loc:     loc,
+		log:     log.With(slog.String("service", "upload-session")),
+	}
+}
+
+// Create starts a resumable upload of req.Size bytes.
+func (s *Service) Create(ctx context.Context, req file.CreateUploadRequest, userID int64) (*file.UploadSession, error) {
+	const op = "service.upload-session.Create"
+
+	if req.Size > s.maxSize {
+		return nil, fmt.Errorf("%s: %w", op, ErrTooLarge)
+	}
+
+	categoryID := int64(defaultCategoryID)
+	if req.CategoryID != nil {
+		categoryID = *req.CategoryID
+	}
+	cat, err := s.repo.GetCategoryByID(ctx, categoryID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrUnknownCategory)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := time.Now()
+	targetDate := now.In(s.loc)
+	if req.TargetDate != nil {
+		if targetDate, err = time.Parse("2006-01-02", *req.TargetDate); err != nil {
+			return nil, fmt.Errorf("%s: invalid date: %w", op, err)
+		}
+	}
+	contentType := req.ContentType
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+
+	sess := file.UploadSession{
+		ID: uuid.New().String(),
+		ObjectKey: fmt.Sprintf("%s/%s/%s%s",
+			cat.DisplayName,
+			targetDate.Format("2006/01/02"),
+			uuid.New().String(),
+			filepath.Ext(req.FileName),
+		),
+		FileName:    req.FileName,
+		ContentType: contentType,
+		Size:        req.Size,
+		Parts:       make([]file.UploadPart, 0),
+		CategoryID:  cat.ID,
+		TargetDate:  targetDate,
+		CreatedBy:   userID,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+	}
+
+	sess.MultipartID, err = s.storage.NewMultipartUpload(ctx, sess.ObjectKey, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.repo.AddUploadSession(ctx, sess); err != nil {
+		s.abort(ctx, &sess)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.log.Info("upload started", slog.String("id", sess.ID), slog.Int64("size", sess.Size), slog.Int64("user_id", userID))
+	return &sess, nil
+}
+
+// Session returns an upload of the user, storage.ErrNotFound for anyone else's.
+func (s *Service) Session(ctx context.Context, id string, userID int64) (*file.UploadSession, error) {
+	const op = "service.upload-session.Session"
+
+	sess, err := s.repo.GetUploadSession(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if sess.CreatedBy != userID {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	return sess, nil
+}
+
+// WriteChunk stores size bytes of body at offset, which must be the session
+// offset, and returns the session moved past them. A chunk at the wrong
+// offset fails with an *OffsetError telling where to resume.
+func (s *Service) WriteChunk(ctx context.Context, id string, userID int64, offset int64, body io.Reader, size int64) (*file.UploadSession, error) {
+	const op = "service.upload-session.WriteChunk"
+
+	sess, err := s.Session(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	switch end := offset + size; {
+	case sess.FileID != nil || sess.AssembledAt != nil:
+		return nil, fmt.Errorf("%s: %w", op, ErrUploadFinished)
+	case offset != sess.Offset:
+		return nil, fmt.Errorf("%s: %w", op, &OffsetError{Offset: sess.Offset})
+	case size <= 0 || end > sess.Size:
+		return nil, fmt.Errorf("%s: %w", op, ErrChunkPastEnd)
+	case end < sess.Size && size < MinChunkSize:
+		return nil, fmt.Errorf("%s: %w", op, ErrChunkTooSmall)
+	case len(sess.Parts) >= maxParts:
+		return nil, fmt.Errorf("%s: %w", op, ErrTooManyParts)
+	}
+
+	part := file.UploadPart{Number: len(sess.Parts) + 1, Size: size}
+	part.ETag, err = s.storage.UploadPart(ctx, sess.ObjectKey, sess.MultipartID, part.Number, body, size)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// A concurrent chunk at the same offset may have won; its part number is
+	// the same, and assembling then fails on the ETag rather than mixing data
+	expiresAt := time.Now().Add(s.ttl)
+	advanced, err := s.repo.AdvanceUploadSession(ctx, id, offset, part, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !advanced {
+		current, err := s.repo.GetUploadSession(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		return nil, fmt.Errorf("%s: %w", op, &OffsetError{Offset: current.Offset})
+	}
+
+	sess.Offset += size
+	sess.Parts = append(sess.Parts, part)
+	sess.ExpiresAt = expiresAt
+	return sess, nil
+}
+
+// Finish assembles a complete upload and stores it as a file. Finishing a
+// finished upload again returns the same file.
+func (s *Service) Finish(ctx context.Context, id string, userID int64) (*filelifecycle.Stored, error) {
+	const op = "service.upload-session.Finish"
+
+	sess, err := s.Session(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sess.FileID != nil {
+		return &filelifecycle.Stored{ID: *sess.FileID}, nil
+	}
+	if !sess.Complete() {
+		return nil, fmt.Errorf("%s: %w", op, ErrUploadIncomplete)
+	}
+
+	if sess.AssembledAt == nil {
+		parts := make([]minio.Part, len(sess.Parts))
+		for i, p := range sess.Parts {
+			parts[i] = minio.Part{Number: p.Number, ETag: p.ETag}
+		}
+		if err := s.storage.CompleteMultipartUpload(ctx, sess.ObjectKey, sess.MultipartID, parts); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if err := s.repo.MarkUploadAssembled(ctx, id); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	stored, err := s.files.StoreUploaded(ctx, filelifecycle.Upload{
+		FileName:    sess.FileName,
+		ContentType: sess.ContentType,
+		Size:        sess.Size,
+		CategoryID:  sess.CategoryID,
+		TargetDate:  sess.TargetDate,
+		ObjectKey:   sess.ObjectKey,
+	})
+	if err != nil {
+		if errors.Is(err, filelifecycle.ErrInfected) {
+			// The object is gone; nothing left to resume
+			if delErr := s.repo.DeleteUploadSession(ctx, id); delErr != nil {
+				s.log.Error("failed to delete refused upload", slog.String("id", id), slog.String("error", delErr.Error()))
+			}
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.repo.FinishUploadSession(ctx, id, stored.ID, time.Now().Add(s.ttl)); err != nil {
+		// The file exists; only a repeated finish would miss it
+		s.log.Error("failed to record finished upload",
+			slog.String("id", id), slog.Int64("file_id", stored.ID), slog.String("error", err.Error()))
+	}
+	s.log.Info("upload finished", slog.String("id", id), slog.Int64("file_id", stored.ID), slog.Bool("deduplicated", stored.Deduplicated))
+	return stored, nil
+}
+
+// Abort cancels an upload and discards what was sent. A finished upload's
+// file is kept.
+func (s *Service) Abort(ctx context.Context, id string, userID int64) error {
+	const op = "service.upload-session.Abort"
+
+	sess, err := s.Session(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.abort(ctx, sess); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.repo.DeleteUploadSession(ctx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// abort discards the parts of an unassembled upload. An assembled object is
+// left to the storage cleanup, which removes it once no file refers to it.
+func (s *Service) abort(ctx context.Context, sess *file.UploadSession) error {
+	if sess.FileID != nil || sess.AssembledAt != nil {
+		return nil
+	}
+	if err := s.storage.AbortMultipartUpload(ctx, sess.ObjectKey, sess.MultipartID); err != nil {
+		s.log.Error("failed to abort multipart upload", slog.String("id", sess.ID), slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// CleanupExpired aborts and removes the sessions past their expiry and
+// returns how many were removed. It stops at the first storage error; the
+// rest are retried by the next run.
+func (s *Service) CleanupExpired(ctx context.Context) (int, error) {
+	const op = "service.upload-session.CleanupExpired"
+
+	var removed int
+	for {
+		sessions, err := s.repo.GetExpiredUploadSessions(ctx, expiredBatchSize)
+		if err != nil {
+			return removed, fmt.Errorf("%s: %w", op, err)
+		}
+		for _, sess := range sessions {
+			if err := s.abort(ctx, sess); err != nil {
+				return removed, fmt.Errorf("%s: %w", op, err)
+			}
+			if err := s.repo.DeleteUploadSession(ctx, sess.ID); err != nil {
+				return removed, fmt.Errorf("%s: %w", op, err)
+			}
+			removed++
+		}
+		if len(sessions) < expiredBatchSize {
+			return removed, nil
+		}
+	}
+}
+
+// StartScheduler removes expired sessions every hour. Blocks until ctx is
+// cancelled.
+func (s *Service) StartScheduler(ctx context.Context) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info("upload session cleanup stopped")
+			return
+		case <-ticker.C:
+			removed, err := s.CleanupExpired(ctx)
+			if err != nil {
+				s.log.Error("upload session cleanup failed", slog.String("error", err.Error()))
+			}
+			if removed > 0 {
+				s.log.Info("expired upload sessions removed", slog.Int("count", removed))
+			}
+		}
+	}
+}
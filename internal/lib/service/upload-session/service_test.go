@@ -0,0 +1,245 @@
+package uploadsession
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"srmt-admin/internal/lib/model/category"
+	"srmt-admin/internal/lib/model/file"
+	filelifecycle "srmt-admin/internal/lib/service/file-lifecycle"
+	"srmt-admin/internal/storage"
+	"srmt-admin/internal/storage/minio"
+)
+
+type fakeRepo struct {
+	sessions map[string]*file.UploadSession
+}
+
+func (f *fakeRepo) GetCategoryByID(_ context.Context, id int64) (category.Model, error) {
+	if id != 1 {
+		return category.Model{}, storage.ErrNotFound
+	}
+	return category.Model{ID: 1, Name: "other", DisplayName: "Другое"}, nil
+}
+
+func (f *fakeRepo) AddUploadSession(_ context.Context, s file.UploadSession) error {
+	f.sessions[s.ID] = &s
+	return nil
+}
+
+func (f *fakeRepo) GetUploadSession(_ context.Context, id string) (*file.UploadSession, error) {
+	s, ok := f.sessions[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	c := *s
+	c.Parts = append([]file.UploadPart(nil), s.Parts...)
+	return &c, nil
+}
+
+func (f *fakeRepo) AdvanceUploadSession(_ context.Context, id string, offset int64, part file.UploadPart, expiresAt time.Time) (bool, error) {
+	s := f.sessions[id]
+	if s.Offset != offset {
+		return false, nil
+	}
+	s.Offset += part.Size
+	s.Parts = append(s.Parts, part)
+	s.ExpiresAt = expiresAt
+	return true, nil
+}
+
+func (f *fakeRepo) MarkUploadAssembled(_ context.Context, id string) error {
+	now := time.Now()
+	f.sessions[id].AssembledAt = &now
+	return nil
+}
+
+func (f *fakeRepo) FinishUploadSession(_ context.Context, id string, fileID int64, expiresAt time.Time) error {
+	f.sessions[id].FileID = &fileID
+	f.sessions[id].ExpiresAt = expiresAt
+	return nil
+}
+
+func (f *fakeRepo) DeleteUploadSession(_ context.Context, id string) error {
+	delete(f.sessions, id)
+	return nil
+}
+
+func (f *fakeRepo) GetExpiredUploadSessions(_ context.Context, limit int) ([]*file.UploadSession, error) {
+	var out []*file.UploadSession
+	for _, s := range f.sessions {
+		if s.ExpiresAt.Before(time.Now()) && len(out) < limit {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+type fakeStorage struct {
+	parts     map[int][]byte
+	assembled []byte
+	aborted   int
+}
+
+func (f *fakeStorage) NewMultipartUpload(_ context.Context, _, _ string) (string, error) {
+	f.parts = make(map[int][]byte)
+	return "mp-1", nil
+}
+
+func (f *fakeStorage) UploadPart(_ context.Context, _, _ string, number int, r io.Reader, size int64) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) != size {
+		return "", io.ErrUnexpectedEOF
+	}
+	f.parts[number] = data
+	return "etag", nil
+}
+
+func (f *fakeStorage) CompleteMultipartUpload(_ context.Context, _, _ string, parts []minio.Part) error {
+	f.assembled = nil
+	for _, p := range parts {
+		f.assembled = append(f.assembled, f.parts[p.Number]...)
+	}
+	return nil
+}
+
+func (f *fakeStorage) AbortMultipartUpload(_ context.Context, _, _ string) error {
+	f.aborted++
+	return nil
+}
+
+type fakeFiles struct {
+	stored []filelifecycle.Upload
+	err    error
+}
+
+func (f *fakeFiles) StoreUploaded(_ context.Context, u filelifecycle.Upload) (*filelifecycle.Stored, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.stored = append(f.stored, u)
+	return &filelifecycle.Stored{ID: int64(len(f.stored)), ObjectKey: u.ObjectKey}, nil
+}
+
+func newTestService() (*Service, *fakeRepo, *fakeStorage, *fakeFiles) {
+	repo := &fakeRepo{sessions: make(map[string]*file.UploadSession)}
+	objects := &fakeStorage{}
+	files := &fakeFiles{}
+	svc := NewService(repo, objects, files, 1<<30, time.Hour, time.UTC, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	return svc, repo, objects, files
+}
+
+func TestResumableUpload(t *testing.T) {
+	svc, _, objects, files := newTestService()
+	ctx := context.Background()
+
+	content := bytes.Repeat([]byte("x"), MinChunkSize+10)
+	sess, err := svc.Create(ctx, file.CreateUploadRequest{FileName: "video.mp4", Size: int64(len(content))}, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the last chunk may be small
+	if _, err := svc.WriteChunk(ctx, sess.ID, 7, 0, bytes.NewReader(content[:10]), 10); !errors.Is(err, ErrChunkTooSmall) {
+		t.Fatalf("small chunk: err = %v", err)
+	}
+	if _, err := svc.WriteChunk(ctx, sess.ID, 7, 0, bytes.NewReader(content[:MinChunkSize]), MinChunkSize); err != nil {
+		t.Fatal(err)
+	}
+
+	// A resent chunk is refused with the offset to resume from
+	_, err = svc.WriteChunk(ctx, sess.ID, 7, 0, bytes.NewReader(content[:MinChunkSize]), MinChunkSize)
+	var offsetErr *OffsetError
+	if !errors.As(err, &offsetErr) || offsetErr.Offset != MinChunkSize {
+		t.Fatalf("resent chunk: err = %v", err)
+	}
+
+	if _, err := svc.Finish(ctx, sess.ID, 7); !errors.Is(err, ErrUploadIncomplete) {
+		t.Fatalf("early finish: err = %v", err)
+	}
+	if _, err := svc.Session(ctx, sess.ID, 8); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("other user: err = %v", err)
+	}
+
+	last, err := svc.WriteChunk(ctx, sess.ID, 7, MinChunkSize, bytes.NewReader(content[MinChunkSize:]), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !last.Complete() {
+		t.Fatalf("offset = %d, size = %d", last.Offset, last.Size)
+	}
+
+	stored, err := svc.Finish(ctx, sess.ID, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(objects.assembled, content) {
+		t.Error("assembled object differs from the upload")
+	}
+	again, err := svc.Finish(ctx, sess.ID, 7)
+	if err != nil || again.ID != stored.ID || len(files.stored) != 1 {
+		t.Fatalf("repeated finish: %+v, %v, stored %d times", again, err, len(files.stored))
+	}
+	if files.stored[0].FileName != "video.mp4" || files.stored[0].CategoryID != 1 {
+		t.Errorf("stored = %+v", files.stored[0])
+	}
+}
+
+func TestCreate_Limits(t *testing.T) {
+	svc, _, _, _ := newTestService()
+	ctx := context.Background()
+
+	if _, err := svc.Create(ctx, file.CreateUploadRequest{FileName: "a", Size: 2 << 30}, 1); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("too large: err = %v", err)
+	}
+	cat := int64(99)
+	if _, err := svc.Create(ctx, file.CreateUploadRequest{FileName: "a", Size: 1, CategoryID: &cat}, 1); !errors.Is(err, ErrUnknownCategory) {
+		t.Errorf("unknown category: err = %v", err)
+	}
+}
+
+func TestFinish_Infected(t *testing.T) {
+	svc, repo, _, files := newTestService()
+	ctx := context.Background()
+
+	sess, _ := svc.Create(ctx, file.CreateUploadRequest{FileName: "a", Size: 3}, 1)
+	if _, err := svc.WriteChunk(ctx, sess.ID, 1, 0, bytes.NewReader([]byte("bad")), 3); err != nil {
+		t.Fatal(err)
+	}
+	files.err = &filelifecycle.InfectedError{Signature: "Eicar-Test-Signature"}
+
+	if _, err := svc.Finish(ctx, sess.ID, 1); !errors.Is(err, filelifecycle.ErrInfected) {
+		t.Fatalf("err = %v", err)
+	}
+	if _, ok := repo.sessions[sess.ID]; ok {
+		t.Error("refused session kept")
+	}
+}
+
+func TestCleanupExpired(t *testing.T) {
+	svc, repo, objects, _ := newTestService()
+	ctx := context.Background()
+
+	idle, _ := svc.Create(ctx, file.CreateUploadRequest{FileName: "a", Size: 10}, 1)
+	active, _ := svc.Create(ctx, file.CreateUploadRequest{FileName: "b", Size: 10}, 1)
+	repo.sessions[idle.ID].ExpiresAt = time.Now().Add(-time.Minute)
+
+	removed, err := svc.CleanupExpired(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 || objects.aborted != 1 {
+		t.Errorf("removed = %d, aborted = %d", removed, objects.aborted)
+	}
+	if _, ok := repo.sessions[active.ID]; !ok {
+		t.Error("active session removed")
+	}
+}
@@ -0,0 +1,73 @@
+// Package stats holds the small numeric helpers shared by the analytics
+// services.
+package stats
+
+import "math"
+
+// Regression is the least-squares fit y = Intercept + Slope * x.
+// ResidualStd is the standard deviation of the residuals.
+type Regression struct {
+	Slope       float64 `json:"slope"`
+	Intercept   float64 `json:"intercept"`
+	R2          float64 `json:"r2"`
+	ResidualStd float64 `json:"residual_std"`
+	Samples     int     `json:"samples"`
+}
+
+// FitLinear fits y = intercept + slope*x by ordinary least squares. Returns
+// nil when there are fewer than minSamples pairs or x has no spread, which
+// carries no information about the slope.
+func FitLinear(xs, ys []float64, minSamples int) *Regression {
+	n := len(xs)
+	if n != len(ys) || n < minSamples || n < 2 {
+		return nil
+	}
+
+	var meanX, meanY float64
+	for i := 0; i < n; i++ {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(n)
+	meanY /= float64(n)
+
+	var sxx, sxy, syy float64
+	for i := 0; i < n; i++ {
+		dx := xs[i] - meanX
+		dy := ys[i] - meanY
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 {
+		return nil
+	}
+
+	slope := sxy / sxx
+	intercept := meanY - slope*meanX
+
+	var sse float64
+	for i := 0; i < n; i++ {
+		res := ys[i] - (intercept + slope*xs[i])
+		sse += res * res
+	}
+
+	r2 := 1.0
+	if syy > 0 {
+		r2 = 1 - sse/syy
+	}
+
+	// n-2 degrees of freedom: two parameters were estimated from the data.
+	dof := n - 2
+	if dof < 1 {
+		dof = 1
+	}
+
+	return &Regression{
+		Slope:       slope,
+		Intercept:   intercept,
+		R2:          r2,
+		ResidualStd: math.Sqrt(sse / float64(dof)),
+		Samples:     n,
+	}
+}
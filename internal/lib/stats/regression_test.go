@@ -0,0 +1,54 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+// line builds n points on y = 2x + 1 with a small alternating residual.
+func line(n int) (xs, ys []float64) {
+	for i := 0; i < n; i++ {
+		x := 100 + float64(i)
+		noise := 0.1
+		if i%2 == 0 {
+			noise = -0.1
+		}
+		xs = append(xs, x)
+		ys = append(ys, 2*x+1+noise)
+	}
+	return xs, ys
+}
+
+func TestFitLinear(t *testing.T) {
+	xs, ys := line(20)
+	reg := FitLinear(xs, ys, 10)
+	if reg == nil {
+		t.Fatal("want regression, got nil")
+	}
+	if math.Abs(reg.Slope-2) > 0.01 || math.Abs(reg.Intercept-1) > 1 {
+		t.Errorf("want ~2x+1, got %.3fx+%.3f", reg.Slope, reg.Intercept)
+	}
+	if reg.R2 < 0.99 {
+		t.Errorf("want R² close to 1, got %.4f", reg.R2)
+	}
+	if reg.Samples != 20 {
+		t.Errorf("want 20 samples, got %d", reg.Samples)
+	}
+}
+
+func TestFitLinear_TooFewOrFlat(t *testing.T) {
+	xs, ys := line(9)
+	if reg := FitLinear(xs, ys, 10); reg != nil {
+		t.Errorf("want nil below minSamples, got %+v", reg)
+	}
+
+	flatX := make([]float64, 10)
+	flatY := make([]float64, 10)
+	for i := range flatX {
+		flatX[i] = 100
+		flatY[i] = float64(i)
+	}
+	if reg := FitLinear(flatX, flatY, 10); reg != nil {
+		t.Errorf("want nil for constant x, got %+v", reg)
+	}
+}
@@ -10,9 +10,11 @@ import (
 	"srmt-admin/internal/lib/service/alarm"
 	hrmaccess "srmt-admin/internal/lib/service/hrm/access"
 	hrmanalytics "srmt-admin/internal/lib/service/hrm/analytics"
+	hrmcalendar "srmt-admin/internal/lib/service/hrm/calendar"
 	hrmcompetency "srmt-admin/internal/lib/service/hrm/competency"
 	hrmdashboard "srmt-admin/internal/lib/service/hrm/dashboard"
 	hrmdocument "srmt-admin/internal/lib/service/hrm/document"
+	hrmlifecycle "srmt-admin/internal/lib/service/hrm/lifecycle"
 	hrmorgstructure "srmt-admin/internal/lib/service/hrm/orgstructure"
 	hrmperformance "srmt-admin/internal/lib/service/hrm/performance"
 	hrmpersonnel "srmt-admin/internal/lib/service/hrm/personnel"
@@ -21,9 +23,23 @@ import (
 	hrmtimesheet "srmt-admin/internal/lib/service/hrm/timesheet"
 	hrmtraining "srmt-admin/internal/lib/service/hrm/training"
 	hrmvacation "srmt-admin/internal/lib/service/hrm/vacation"
+	"srmt-admin/internal/lib/service/damsafety"
+	runoffsvc "srmt-admin/internal/lib/service/runoff"
+	cascaderouting "srmt-admin/internal/lib/service/cascade-routing"
+	docworkflow "srmt-admin/internal/lib/service/document-workflow"
+	execcontrol "srmt-admin/internal/lib/service/execution-control"
+	regnumbering "srmt-admin/internal/lib/service/registration-numbering"
+	fts "srmt-admin/internal/lib/service/full-text-search"
+	doctemplates "srmt-admin/internal/lib/service/document-templates"
+	docintegrity "srmt-admin/internal/lib/service/document-integrity"
+	filelifecycle "srmt-admin/internal/lib/service/file-lifecycle"
+	"srmt-admin/internal/lib/service/notification"
+	telegrambot "srmt-admin/internal/lib/service/telegram-bot"
+	uploadsession "srmt-admin/internal/lib/service/upload-session"
 	"srmt-admin/internal/lib/service/dayrotation"
 	dischargesvc "srmt-admin/internal/lib/service/discharge"
 	dutyviolationssvc "srmt-admin/internal/lib/service/dutyviolations"
+	dutyrostersvc "srmt-admin/internal/lib/service/dutyroster"
 	gesreportsvc "srmt-admin/internal/lib/service/ges-report"
 	"srmt-admin/internal/lib/service/metrics"
 	"srmt-admin/internal/lib/service/reservoir"
@@ -71,6 +87,7 @@ type AppContainer struct {
 	HRMTimesheetService    *hrmtimesheet.Service
 	HRMSalaryService       *hrmsalary.Service
 	HRMRecruitingService   *hrmrecruiting.Service
+	HRMLifecycleService    *hrmlifecycle.Service
 	HRMTrainingService     *hrmtraining.Service
 	HRMDocumentService     *hrmdocument.Service
 	HRMAccessService       *hrmaccess.Service
@@ -84,6 +101,13 @@ type AppContainer struct {
 	DischargeService       *dischargesvc.Service
 	DutyViolationsService  *dutyviolationssvc.Service
 	SelService             *selsvc.Service
+	DamSafetyService       *damsafety.Service
+	ExecControlService     *execcontrol.Service
+	FullTextSearchService  *fts.Service
+	FileLifecycleService   *filelifecycle.Service
+	UploadSessionService   *uploadsession.Service
+	NotificationService    *notification.Service
+	TelegramBot            *telegrambot.Service
 }
 
 // ProvideAppContainer creates the application container
@@ -108,6 +132,7 @@ func ProvideAppContainer(
 	hrmTimesheetSvc *hrmtimesheet.Service,
 	hrmSalarySvc *hrmsalary.Service,
 	hrmRecruitingSvc *hrmrecruiting.Service,
+	hrmLifecycleSvc *hrmlifecycle.Service,
 	hrmTrainingSvc *hrmtraining.Service,
 	hrmDocumentSvc *hrmdocument.Service,
 	hrmAccessSvc *hrmaccess.Service,
@@ -121,6 +146,13 @@ func ProvideAppContainer(
 	dischargeSvc *dischargesvc.Service,
 	dutyViolationsSvc *dutyviolationssvc.Service,
 	selSvc *selsvc.Service,
+	damSafetySvc *damsafety.Service,
+	execControlSvc *execcontrol.Service,
+	ftsSvc *fts.Service,
+	fileLifecycleSvc *filelifecycle.Service,
+	uploadSessionSvc *uploadsession.Service,
+	notificationSvc *notification.Service,
+	telegramBot *telegrambot.Service,
 ) *AppContainer {
 	return &AppContainer{
 		Router:                 r,
@@ -143,6 +175,7 @@ func ProvideAppContainer(
 		HRMTimesheetService:    hrmTimesheetSvc,
 		HRMSalaryService:       hrmSalarySvc,
 		HRMRecruitingService:   hrmRecruitingSvc,
+		HRMLifecycleService:    hrmLifecycleSvc,
 		HRMTrainingService:     hrmTrainingSvc,
 		HRMDocumentService:     hrmDocumentSvc,
 		HRMAccessService:       hrmAccessSvc,
@@ -156,6 +189,13 @@ func ProvideAppContainer(
 		DischargeService:       dischargeSvc,
 		DutyViolationsService:  dutyViolationsSvc,
 		SelService:             selSvc,
+		DamSafetyService:       damSafetySvc,
+		ExecControlService:     execControlSvc,
+		FullTextSearchService:  ftsSvc,
+		FileLifecycleService:   fileLifecycleSvc,
+		UploadSessionService:   uploadSessionSvc,
+		NotificationService:    notificationSvc,
+		TelegramBot:            telegramBot,
 	}
 }
 
@@ -177,8 +217,10 @@ func ProvideRouter(
 	hrmVacationSvc *hrmvacation.Service,
 	hrmDashboardSvc *hrmdashboard.Service,
 	hrmTimesheetSvc *hrmtimesheet.Service,
+	hrmCalendarSvc *hrmcalendar.Service,
 	hrmSalarySvc *hrmsalary.Service,
 	hrmRecruitingSvc *hrmrecruiting.Service,
+	hrmLifecycleSvc *hrmlifecycle.Service,
 	hrmTrainingSvc *hrmtraining.Service,
 	hrmDocumentSvc *hrmdocument.Service,
 	hrmAccessSvc *hrmaccess.Service,
@@ -190,7 +232,21 @@ func ProvideRouter(
 	gesReportSvc *gesreportsvc.Service,
 	dischargeSvc *dischargesvc.Service,
 	dutyViolationsSvc *dutyviolationssvc.Service,
+	dutyRosterSvc *dutyrostersvc.Service,
 	selSvc *selsvc.Service,
+	damSafetySvc *damsafety.Service,
+	runoffSvc *runoffsvc.Service,
+	cascadeRoutingSvc *cascaderouting.Service,
+	docWorkflowSvc *docworkflow.Service,
+	execControlSvc *execcontrol.Service,
+	regNumberingSvc *regnumbering.Service,
+	ftsSvc *fts.Service,
+	docTemplateSvc *doctemplates.Service,
+	docIntegritySvc *docintegrity.Service,
+	fileLifecycleSvc *filelifecycle.Service,
+	uploadSessionSvc *uploadsession.Service,
+	notificationSvc *notification.Service,
+	telegramBot *telegrambot.Service,
 ) *chi.Mux {
 	r := chi.NewRouter()
 
@@ -220,8 +276,10 @@ func ProvideRouter(
 		HRMVacationService:         hrmVacationSvc,
 		HRMDashboardService:        hrmDashboardSvc,
 		HRMTimesheetService:        hrmTimesheetSvc,
+		HRMCalendarService:         hrmCalendarSvc,
 		HRMSalaryService:           hrmSalarySvc,
 		HRMRecruitingService:       hrmRecruitingSvc,
+		HRMLifecycleService:        hrmLifecycleSvc,
 		HRMTrainingService:         hrmTrainingSvc,
 		HRMDocumentService:         hrmDocumentSvc,
 		HRMAccessService:           hrmAccessSvc,
@@ -233,7 +291,21 @@ func ProvideRouter(
 		GESReportService:           gesReportSvc,
 		DischargeService:           dischargeSvc,
 		DutyViolationsService:      dutyViolationsSvc,
+		DutyRosterService:          dutyRosterSvc,
 		SelService:                 selSvc,
+		DamSafetyService:           damSafetySvc,
+		RunoffService:              runoffSvc,
+		CascadeRoutingService:      cascadeRoutingSvc,
+		DocWorkflowService:         docWorkflowSvc,
+		ExecControlService:         execControlSvc,
+		RegNumberingService:        regNumberingSvc,
+		FullTextSearchService:      ftsSvc,
+		DocTemplateService:         docTemplateSvc,
+		DocIntegrityService:        docIntegritySvc,
+		FileLifecycleService:       fileLifecycleSvc,
+		UploadSessionService:       uploadSessionSvc,
+		NotificationService:        notificationSvc,
+		TelegramBot:                telegramBot,
 	}
 
 	router.SetupRoutes(r, deps)
@@ -8,9 +8,11 @@ import (
 	"srmt-admin/internal/lib/service/ascue"
 	hrmaccess "srmt-admin/internal/lib/service/hrm/access"
 	hrmanalytics "srmt-admin/internal/lib/service/hrm/analytics"
+	hrmcalendar "srmt-admin/internal/lib/service/hrm/calendar"
 	hrmcompetency "srmt-admin/internal/lib/service/hrm/competency"
 	hrmdashboard "srmt-admin/internal/lib/service/hrm/dashboard"
 	hrmdocument "srmt-admin/internal/lib/service/hrm/document"
+	hrmlifecycle "srmt-admin/internal/lib/service/hrm/lifecycle"
 	hrmorgstructure "srmt-admin/internal/lib/service/hrm/orgstructure"
 	hrmperformance "srmt-admin/internal/lib/service/hrm/performance"
 	hrmpersonnel "srmt-admin/internal/lib/service/hrm/personnel"
@@ -19,16 +21,33 @@ import (
 	hrmtimesheet "srmt-admin/internal/lib/service/hrm/timesheet"
 	hrmtraining "srmt-admin/internal/lib/service/hrm/training"
 	hrmvacation "srmt-admin/internal/lib/service/hrm/vacation"
+	"srmt-admin/internal/lib/service/damsafety"
+	runoffsvc "srmt-admin/internal/lib/service/runoff"
+	cascaderouting "srmt-admin/internal/lib/service/cascade-routing"
+	docworkflow "srmt-admin/internal/lib/service/document-workflow"
+	execcontrol "srmt-admin/internal/lib/service/execution-control"
+	regnumbering "srmt-admin/internal/lib/service/registration-numbering"
+	fts "srmt-admin/internal/lib/service/full-text-search"
+	doctemplates "srmt-admin/internal/lib/service/document-templates"
+	docintegrity "srmt-admin/internal/lib/service/document-integrity"
+	filelifecycle "srmt-admin/internal/lib/service/file-lifecycle"
+	uploadsession "srmt-admin/internal/lib/service/upload-session"
 	"srmt-admin/internal/lib/service/dayrotation"
 	dischargesvc "srmt-admin/internal/lib/service/discharge"
 	dutyviolationssvc "srmt-admin/internal/lib/service/dutyviolations"
+	dutyrostersvc "srmt-admin/internal/lib/service/dutyroster"
 	gesreportsvc "srmt-admin/internal/lib/service/ges-report"
 	"srmt-admin/internal/lib/service/metrics"
 	"srmt-admin/internal/lib/service/reservoir"
 	"srmt-admin/internal/lib/service/weather"
+	notificationmodel "srmt-admin/internal/lib/model/notification"
+	"srmt-admin/internal/lib/service/notification"
 	reservoirhourly "srmt-admin/internal/lib/service/reservoir-hourly"
 	selsvc "srmt-admin/internal/lib/service/sel"
+	"srmt-admin/internal/lib/service/telegram"
+	telegrambot "srmt-admin/internal/lib/service/telegram-bot"
 	"srmt-admin/internal/storage/redis"
+	"srmt-admin/internal/storage/minio"
 	"srmt-admin/internal/storage/repo"
 	"srmt-admin/internal/token"
 	"time"
@@ -47,9 +66,11 @@ var ServiceProviderSet = wire.NewSet(
 	ProvideHRMPersonnelService,
 	ProvideHRMVacationService,
 	ProvideHRMDashboardService,
+	ProvideHRMCalendarService,
 	ProvideHRMTimesheetService,
 	ProvideHRMSalaryService,
 	ProvideHRMRecruitingService,
+	ProvideHRMLifecycleService,
 	ProvideHRMTrainingService,
 	ProvideHRMDocumentService,
 	ProvideHRMAccessService,
@@ -64,6 +85,21 @@ var ServiceProviderSet = wire.NewSet(
 	ProvideGESReportService,
 	ProvideDischargeService,
 	ProvideDutyViolationsService,
+	ProvideDutyRosterService,
+	ProvideDamSafetyService,
+	ProvideRunoffService,
+	ProvideCascadeRoutingService,
+	ProvideDocWorkflowService,
+	ProvideExecutionControlService,
+	ProvideRegistrationNumberingService,
+	ProvideFullTextSearchService,
+	ProvideDocumentTemplateService,
+	ProvideDocumentIntegrityService,
+	ProvideFileLifecycleService,
+	ProvideUploadSessionService,
+	ProvideNotificationService,
+	ProvideTelegramClient,
+	ProvideTelegramBot,
 )
 
 // ProvideTokenService creates JWT token service
@@ -110,8 +146,8 @@ func ProvideHTTPClient() *http.Client {
 }
 
 // ProvideAlarmProcessor creates the alarm processor for automatic shutdown creation
-func ProvideAlarmProcessor(pgRepo *repo.Repo, redisRepo *redis.Repo, log *slog.Logger) *alarm.Processor {
-	return alarm.NewProcessor(pgRepo, redisRepo, log)
+func ProvideAlarmProcessor(pgRepo *repo.Repo, redisRepo *redis.Repo, notifier *notification.Service, log *slog.Logger) *alarm.Processor {
+	return alarm.NewProcessor(pgRepo, redisRepo, notifier, log)
 }
 
 // ProvideHRMPersonnelService creates the HRM personnel service
@@ -120,8 +156,8 @@ func ProvideHRMPersonnelService(pgRepo *repo.Repo, log *slog.Logger) *hrmpersonn
 }
 
 // ProvideHRMVacationService creates the HRM vacation service
-func ProvideHRMVacationService(pgRepo *repo.Repo, log *slog.Logger) *hrmvacation.Service {
-	return hrmvacation.NewService(pgRepo, log)
+func ProvideHRMVacationService(pgRepo *repo.Repo, notifier *notification.Service, timesheet *hrmtimesheet.Service, calendar *hrmcalendar.Service, loc *time.Location, log *slog.Logger) *hrmvacation.Service {
+	return hrmvacation.NewService(pgRepo, notifier, timesheet, calendar, loc, log)
 }
 
 // ProvideHRMDashboardService creates the HRM dashboard service
@@ -129,14 +165,25 @@ func ProvideHRMDashboardService(pgRepo *repo.Repo, log *slog.Logger) *hrmdashboa
 	return hrmdashboard.NewService(pgRepo, log)
 }
 
-// ProvideHRMTimesheetService creates the HRM timesheet service
-func ProvideHRMTimesheetService(pgRepo *repo.Repo, log *slog.Logger) *hrmtimesheet.Service {
-	return hrmtimesheet.NewService(pgRepo, log)
+// ProvideHRMCalendarService creates the HRM production calendar service
+func ProvideHRMCalendarService(pgRepo *repo.Repo, log *slog.Logger) *hrmcalendar.Service {
+	return hrmcalendar.NewService(pgRepo, log)
 }
 
-// ProvideHRMSalaryService creates the HRM salary service
-func ProvideHRMSalaryService(pgRepo *repo.Repo, log *slog.Logger) *hrmsalary.Service {
-	return hrmsalary.NewService(pgRepo, log)
+// ProvideHRMTimesheetService creates the HRM timesheet service and has it
+// refill timesheets whenever the production calendar changes
+func ProvideHRMTimesheetService(pgRepo *repo.Repo, calendar *hrmcalendar.Service, loc *time.Location, log *slog.Logger) *hrmtimesheet.Service {
+	svc := hrmtimesheet.NewService(pgRepo, calendar, loc, log)
+	calendar.OnChange(svc.CalendarChanged)
+	return svc
+}
+
+// ProvideHRMSalaryService creates the HRM salary service and has it
+// recalculate draft salaries whenever a timesheet changes
+func ProvideHRMSalaryService(pgRepo *repo.Repo, notifier *notification.Service, timesheet *hrmtimesheet.Service, log *slog.Logger) *hrmsalary.Service {
+	svc := hrmsalary.NewService(pgRepo, notifier, timesheet, log)
+	timesheet.OnChange(svc.TimesheetChanged)
+	return svc
 }
 
 // ProvideHRMRecruitingService creates the HRM recruiting service
@@ -144,9 +191,17 @@ func ProvideHRMRecruitingService(pgRepo *repo.Repo, log *slog.Logger) *hrmrecrui
 	return hrmrecruiting.NewService(pgRepo, log)
 }
 
-// ProvideHRMTrainingService creates the HRM training service
-func ProvideHRMTrainingService(pgRepo *repo.Repo, log *slog.Logger) *hrmtraining.Service {
-	return hrmtraining.NewService(pgRepo, log)
+// ProvideHRMLifecycleService creates the HRM employee lifecycle service. A
+// dismissal settles the final salary with the unused vacation days from the
+// vacation ledger.
+func ProvideHRMLifecycleService(pgRepo *repo.Repo, salary *hrmsalary.Service, vacation *hrmvacation.Service, loc *time.Location, log *slog.Logger) *hrmlifecycle.Service {
+	return hrmlifecycle.NewService(pgRepo, salary, vacation, loc, log)
+}
+
+// ProvideHRMTrainingService creates the HRM training service. It alerts
+// employees and HR about expiring certifications.
+func ProvideHRMTrainingService(pgRepo *repo.Repo, notifier *notification.Service, loc *time.Location, log *slog.Logger) *hrmtraining.Service {
+	return hrmtraining.NewService(pgRepo, notifier, loc, log)
 }
 
 // ProvideHRMDocumentService creates the HRM document service
@@ -155,8 +210,8 @@ func ProvideHRMDocumentService(pgRepo *repo.Repo, log *slog.Logger) *hrmdocument
 }
 
 // ProvideHRMAccessService creates the HRM access control service
-func ProvideHRMAccessService(pgRepo *repo.Repo, log *slog.Logger) *hrmaccess.Service {
-	return hrmaccess.NewService(pgRepo, log)
+func ProvideHRMAccessService(pgRepo *repo.Repo, timesheet *hrmtimesheet.Service, cfg *config.Config, loc *time.Location, log *slog.Logger) *hrmaccess.Service {
+	return hrmaccess.NewService(pgRepo, timesheet, loc, cfg.AccessControl.PresenceTTL, log)
 }
 
 // ProvideHRMOrgStructureService creates the HRM org structure service
@@ -198,8 +253,8 @@ func ProvideGESReportService(pgRepo *repo.Repo, loc *time.Location, log *slog.Lo
 }
 
 // ProvideDischargeService creates the discharge service for ongoing discharge validation
-func ProvideDischargeService(pgRepo *repo.Repo) *dischargesvc.Service {
-	return dischargesvc.NewService(pgRepo)
+func ProvideDischargeService(pgRepo *repo.Repo, notifier *notification.Service) *dischargesvc.Service {
+	return dischargesvc.NewService(pgRepo, notifier)
 }
 
 // ProvideDutyViolationsService wires the duty-officer violations service.
@@ -208,6 +263,131 @@ func ProvideDutyViolationsService(pgRepo *repo.Repo) *dutyviolationssvc.Service
 	return dutyviolationssvc.NewService(pgRepo)
 }
 
+// ProvideDutyRosterService wires the shift-roster service. Roster months
+// and shift start times are read in the plant's location.
+func ProvideDutyRosterService(pgRepo *repo.Repo, loc *time.Location) *dutyrostersvc.Service {
+	return dutyrostersvc.NewService(pgRepo, loc)
+}
+
+// ProvideDamSafetyService creates the filtration/piezometer trend analytics
+// and alerting service
+func ProvideDamSafetyService(pgRepo *repo.Repo, notifier *notification.Service, loc *time.Location, log *slog.Logger) *damsafety.Service {
+	return damsafety.NewService(pgRepo, notifier, loc, log)
+}
+
+// ProvideRunoffService creates the seasonal runoff (snow cover → inflow)
+// forecast service
+func ProvideRunoffService(pgRepo *repo.Repo, log *slog.Logger) *runoffsvc.Service {
+	return runoffsvc.NewService(pgRepo, log)
+}
+
+// ProvideCascadeRoutingService creates the cascade topology and hydraulic
+// routing service
+func ProvideCascadeRoutingService(pgRepo *repo.Repo, log *slog.Logger) *cascaderouting.Service {
+	return cascaderouting.NewService(pgRepo, log)
+}
+
+// ProvideDocWorkflowService creates the document status graph service
+func ProvideDocWorkflowService(pgRepo *repo.Repo, log *slog.Logger) *docworkflow.Service {
+	return docworkflow.NewService(pgRepo, log)
+}
+
+// ProvideExecutionControlService creates the resolution execution control
+// service (reports, review, due-date reminders)
+func ProvideExecutionControlService(pgRepo *repo.Repo, notifier *notification.Service, loc *time.Location, log *slog.Logger) *execcontrol.Service {
+	return execcontrol.NewService(pgRepo, notifier, loc, log)
+}
+
+// ProvideRegistrationNumberingService creates the document registration
+// numbering service (schemes, reservations, journal)
+func ProvideRegistrationNumberingService(pgRepo *repo.Repo, loc *time.Location, log *slog.Logger) *regnumbering.Service {
+	return regnumbering.NewService(pgRepo, loc, log)
+}
+
+// ProvideFullTextSearchService creates the document full-text search service
+// and its attachment text indexer
+func ProvideFullTextSearchService(pgRepo *repo.Repo, minioRepo *minio.Repo, log *slog.Logger) *fts.Service {
+	return fts.NewService(pgRepo, minioRepo, log)
+}
+
+// ProvideDocumentTemplateService creates the DOCX document template service
+func ProvideDocumentTemplateService(pgRepo *repo.Repo, minioRepo *minio.Repo, loc *time.Location, log *slog.Logger) *doctemplates.Service {
+	return doctemplates.NewService(pgRepo, minioRepo, loc, log)
+}
+
+// ProvideDocumentIntegrityService creates the signature seal and verification
+// service
+func ProvideDocumentIntegrityService(pgRepo *repo.Repo, minioRepo *minio.Repo, cfg *config.Config, log *slog.Logger) *docintegrity.Service {
+	return docintegrity.NewService(pgRepo, minioRepo, cfg.DocumentSigning.SealKey, log)
+}
+
+// ProvideFileLifecycleService creates the file storage service (upload
+// scanning, deduplication, retention and orphan cleanup)
+func ProvideFileLifecycleService(pgRepo *repo.Repo, minioRepo *minio.Repo, cfg *config.Config, loc *time.Location, log *slog.Logger) (*filelifecycle.Service, error) {
+	scanner, err := filelifecycle.NewScanner(cfg.FileStorage.Scanner, cfg.FileStorage.ClamdAddress)
+	if err != nil {
+		return nil, err
+	}
+	return filelifecycle.NewService(pgRepo, minioRepo, scanner, loc, log), nil
+}
+
+// ProvideUploadSessionService creates the resumable (chunked) upload service
+func ProvideUploadSessionService(pgRepo *repo.Repo, minioRepo *minio.Repo, files *filelifecycle.Service, cfg *config.Config, loc *time.Location, log *slog.Logger) *uploadsession.Service {
+	return uploadsession.NewService(pgRepo, minioRepo, files, cfg.ResumableUpload.MaxSize, cfg.ResumableUpload.SessionTTL, loc, log)
+}
+
+// ProvideTelegramClient creates the Telegram Bot API client (returns nil if
+// no bot token is configured)
+func ProvideTelegramClient(cfg *config.Config, client *http.Client) *telegram.Client {
+	if cfg.Telegram.APIKey == "" {
+		return nil
+	}
+	return telegram.NewClient(client, cfg.Telegram.APIURL, cfg.Telegram.APIKey)
+}
+
+// ProvideTelegramBot creates the Telegram bot (returns nil if no bot token is
+// configured)
+func ProvideTelegramBot(tgClient *telegram.Client, pgRepo *repo.Repo, gesReport *gesreportsvc.Service, notifier *notification.Service, cfg *config.Config, loc *time.Location, log *slog.Logger) *telegrambot.Service {
+	if tgClient == nil {
+		return nil
+	}
+	return telegrambot.NewService(tgClient, pgRepo, gesReport, notifier, telegrambot.Options{
+		Mode:          cfg.Telegram.Mode,
+		WebhookURL:    cfg.Telegram.WebhookURL,
+		WebhookSecret: cfg.Telegram.WebhookSecret,
+		BotUsername:   cfg.Telegram.BotUsername,
+		PollTimeout:   cfg.Telegram.PollTimeout,
+		LinkCodeTTL:   cfg.Telegram.LinkCodeTTL,
+		ReportTime:    cfg.Telegram.ReportTime,
+		ReportRoles:   cfg.Telegram.ReportRoles,
+	}, loc, log)
+}
+
+// ProvideNotificationService creates the notification service. Email and
+// Telegram delivery are enabled when their settings are present; in-app
+// notifications are always delivered.
+func ProvideNotificationService(pgRepo *repo.Repo, cfg *config.Config, tgClient *telegram.Client, log *slog.Logger) *notification.Service {
+	senders := make(map[string]notification.Sender)
+	if cfg.SMTP.Host != "" {
+		senders[notificationmodel.ChannelEmail] = notification.NewEmailSender(notification.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.SMTP.From,
+		})
+	}
+	if tgClient != nil {
+		senders[notificationmodel.ChannelTelegram] = notification.NewTelegramSender(tgClient)
+	}
+
+	return notification.NewService(pgRepo, senders, notification.Options{
+		PublicURL:    cfg.Notifications.PublicURL,
+		PollInterval: cfg.Notifications.PollInterval,
+		MaxAttempts:  cfg.Notifications.MaxAttempts,
+	}, log)
+}
+
 // ProvideReservoirHourlyService creates the reservoir-hourly report service
 func ProvideReservoirHourlyService(fetcher *reservoir.Fetcher, pgRepo *repo.Repo, log *slog.Logger) *reservoirhourly.Service {
 	if fetcher == nil {
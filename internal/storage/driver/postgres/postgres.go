@@ -43,6 +43,10 @@ func New(storagePath string, migrationsPath string) (*storage.Driver, error) {
 
 }
 
+// attachmentsLockedCode is the custom SQLSTATE raised by the attachment lock
+// of signed documents (migration 000099).
+const attachmentsLockedCode = "SR001"
+
 type Translator struct{}
 
 func (t *Translator) Translate(err error, op string) error {
@@ -57,6 +61,8 @@ func (t *Translator) Translate(err error, op string) error {
 			return fmt.Errorf("%s: %w", op, storage.ErrNotNullViolation)
 		case "23514":
 			return fmt.Errorf("%s: %w", op, storage.ErrCheckConstraintViolation)
+		case attachmentsLockedCode:
+			return fmt.Errorf("%s: %w", op, storage.ErrAttachmentsLocked)
 		}
 	}
 	return fmt.Errorf("%s: %w", op, err)
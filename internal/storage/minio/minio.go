@@ -150,3 +150,41 @@ func (r *Repo) DeleteFile(ctx context.Context, objectName string) error {
 	}
 	return nil
 }
+
+// GetObject открывает файл из бакета для чтения. Ошибка отсутствия объекта
+// возвращается при первом чтении.
+func (r *Repo) GetObject(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	const op = "repo.minio.GetObject"
+
+	obj, err := r.client.GetObject(ctx, r.bucket, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return obj, nil
+}
+
+// ObjectInfo describes an object of the bucket.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// WalkObjects calls fn for every object of the bucket and stops at the first
+// error fn returns.
+func (r *Repo) WalkObjects(ctx context.Context, fn func(ObjectInfo) error) error {
+	const op = "repo.minio.WalkObjects"
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // stops the listing when fn fails
+
+	for object := range r.client.ListObjects(ctx, r.bucket, minio.ListObjectsOptions{Recursive: true}) {
+		if object.Err != nil {
+			return fmt.Errorf("%s: %w", op, object.Err)
+		}
+		if err := fn(ObjectInfo{Key: object.Key, Size: object.Size, LastModified: object.LastModified}); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,70 @@
+package minio
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// Part is an uploaded part of a multipart upload.
+type Part struct {
+	Number int
+	ETag   string
+}
+
+func (r *Repo) core() minio.Core {
+	return minio.Core{Client: r.client}
+}
+
+// NewMultipartUpload starts a multipart upload of an object and returns its ID.
+func (r *Repo) NewMultipartUpload(ctx context.Context, objectName, contentType string) (string, error) {
+	const op = "repo.minio.NewMultipartUpload"
+
+	uploadID, err := r.core().NewMultipartUpload(ctx, r.bucket, objectName, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	return uploadID, nil
+}
+
+// UploadPart stores part number of a multipart upload and returns its ETag.
+// Every part but the last must be at least 5 MiB.
+func (r *Repo) UploadPart(ctx context.Context, objectName, uploadID string, number int, reader io.Reader, size int64) (string, error) {
+	const op = "repo.minio.UploadPart"
+
+	part, err := r.core().PutObjectPart(ctx, r.bucket, objectName, uploadID, number, reader, size, minio.PutObjectPartOptions{})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	return part.ETag, nil
+}
+
+// CompleteMultipartUpload assembles the parts into the object.
+func (r *Repo) CompleteMultipartUpload(ctx context.Context, objectName, uploadID string, parts []Part) error {
+	const op = "repo.minio.CompleteMultipartUpload"
+
+	complete := make([]minio.CompletePart, len(parts))
+	for i, p := range parts {
+		complete[i] = minio.CompletePart{PartNumber: p.Number, ETag: p.ETag}
+	}
+	if _, err := r.core().CompleteMultipartUpload(ctx, r.bucket, objectName, uploadID, complete, minio.PutObjectOptions{}); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// AbortMultipartUpload discards a multipart upload and its parts. An upload
+// that no longer exists is not an error.
+func (r *Repo) AbortMultipartUpload(ctx context.Context, objectName, uploadID string) error {
+	const op = "repo.minio.AbortMultipartUpload"
+
+	err := r.core().AbortMultipartUpload(ctx, r.bucket, objectName, uploadID)
+	if err != nil && minio.ToErrorResponse(err).Code != "NoSuchUpload" {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	screport "srmt-admin/internal/lib/model/sc-report"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Typed callback reports live next to the raw payload collections, one
+// document per report date. A re-sent report for the same date replaces the
+// earlier one.
+const (
+	stockReportsCollection   = "stock_reports"
+	modsnowReportsCollection = "modsnow_reports"
+)
+
+type storedReport[T any] struct {
+	Report     T         `bson:",inline"`
+	ReceivedAt time.Time `bson:"received_at"`
+}
+
+func (r *Repo) UpsertStockReport(ctx context.Context, report screport.StockReport) error {
+	const op = "storage.mongo.UpsertStockReport"
+	return upsertReport(ctx, r, stockReportsCollection, report.Date, report, op)
+}
+
+func (r *Repo) GetStockReports(ctx context.Context, from, to string) ([]screport.StockReport, error) {
+	const op = "storage.mongo.GetStockReports"
+	return findReports[screport.StockReport](ctx, r, stockReportsCollection, from, to, op)
+}
+
+// GetStockReportBefore returns the last report dated before date, or nil.
+func (r *Repo) GetStockReportBefore(ctx context.Context, date string) (*screport.StockReport, error) {
+	const op = "storage.mongo.GetStockReportBefore"
+	return findReportBefore[screport.StockReport](ctx, r, stockReportsCollection, date, op)
+}
+
+func (r *Repo) UpsertModsnowReport(ctx context.Context, report screport.ModsnowReport) error {
+	const op = "storage.mongo.UpsertModsnowReport"
+	return upsertReport(ctx, r, modsnowReportsCollection, report.Date, report, op)
+}
+
+func (r *Repo) GetModsnowReports(ctx context.Context, from, to string) ([]screport.ModsnowReport, error) {
+	const op = "storage.mongo.GetModsnowReports"
+	return findReports[screport.ModsnowReport](ctx, r, modsnowReportsCollection, from, to, op)
+}
+
+// GetModsnowReportBefore returns the last report dated before date, or nil.
+func (r *Repo) GetModsnowReportBefore(ctx context.Context, date string) (*screport.ModsnowReport, error) {
+	const op = "storage.mongo.GetModsnowReportBefore"
+	return findReportBefore[screport.ModsnowReport](ctx, r, modsnowReportsCollection, date, op)
+}
+
+func upsertReport[T any](ctx context.Context, r *Repo, collectionName, date string, report T, op string) error {
+	collection := r.Client.Database("srmt").Collection(collectionName)
+
+	doc := storedReport[T]{Report: report, ReceivedAt: time.Now()}
+	filter := bson.D{{Key: "report_date", Value: date}}
+
+	if _, err := collection.ReplaceOne(ctx, filter, doc, options.Replace().SetUpsert(true)); err != nil {
+		return fmt.Errorf("%s: failed to upsert report: %w", op, err)
+	}
+	return nil
+}
+
+// findReports returns the reports dated in [from, to], ascending. Dates are
+// stored as YYYY-MM-DD strings, so lexical order is date order.
+func findReports[T any](ctx context.Context, r *Repo, collectionName, from, to, op string) ([]T, error) {
+	collection := r.Client.Database("srmt").Collection(collectionName)
+
+	filter := bson.D{{Key: "report_date", Value: bson.D{
+		{Key: "$gte", Value: from},
+		{Key: "$lte", Value: to},
+	}}}
+	findOptions := options.Find().SetSort(bson.D{{Key: "report_date", Value: 1}})
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to find reports: %w", op, err)
+	}
+	defer cursor.Close(ctx)
+
+	result := make([]T, 0)
+	for cursor.Next(ctx) {
+		var doc storedReport[T]
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("%s: failed to decode report: %w", op, err)
+		}
+		result = append(result, doc.Report)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("%s: cursor error: %w", op, err)
+	}
+	return result, nil
+}
+
+func findReportBefore[T any](ctx context.Context, r *Repo, collectionName, date, op string) (*T, error) {
+	collection := r.Client.Database("srmt").Collection(collectionName)
+
+	filter := bson.D{{Key: "report_date", Value: bson.D{{Key: "$lt", Value: date}}}}
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "report_date", Value: -1}})
+
+	var doc storedReport[T]
+	if err := collection.FindOne(ctx, filter, findOptions).Decode(&doc); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s: failed to find report: %w", op, err)
+	}
+	return &doc.Report, nil
+}
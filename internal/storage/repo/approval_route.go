@@ -0,0 +1,536 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"srmt-admin/internal/lib/model/signature"
+	"srmt-admin/internal/lib/model/user"
+	"srmt-admin/internal/storage"
+)
+
+// Statuses from which a document may be put on an approval route: before
+// signing starts, or while it is waiting for signatures.
+var routableStatusCodes = map[string]bool{
+	"draft":             true,
+	"pending_approval":  true,
+	"approved":          true,
+	"pending_signature": true,
+}
+
+// pendingStepColumns selects the current stage of the document's active
+// route and the earliest due date of the steps awaiting the user ($2) in it.
+// Both are NULL for documents without an active route.
+func pendingStepColumns(alias string) string {
+	return fmt.Sprintf(`(SELECT ar.current_stage FROM approval_routes ar
+				WHERE ar.document_type = %[1]s.document_type AND ar.document_id = %[1]s.id AND ar.status = 'active') AS approval_stage,
+			   (SELECT MIN(s.due_date) FROM approval_routes ar
+				JOIN approval_route_steps s ON s.route_id = ar.id AND s.stage = ar.current_stage AND s.status = 'pending'
+				WHERE ar.document_type = %[1]s.document_type AND ar.document_id = %[1]s.id AND ar.status = 'active'
+				  AND %[2]s) AS step_due_date`, alias, stepActorFilter("s", "$2"))
+}
+
+// pendingTurnFilter keeps documents without an active route and those whose
+// current stage has a pending step the user ($2) may act on.
+func pendingTurnFilter(alias string) string {
+	return fmt.Sprintf(`(NOT EXISTS (SELECT 1 FROM approval_routes ar
+				WHERE ar.document_type = %[1]s.document_type AND ar.document_id = %[1]s.id AND ar.status = 'active')
+			OR EXISTS (SELECT 1 FROM approval_routes ar
+				JOIN approval_route_steps s ON s.route_id = ar.id AND s.stage = ar.current_stage AND s.status = 'pending'
+				WHERE ar.document_type = %[1]s.document_type AND ar.document_id = %[1]s.id AND ar.status = 'active'
+				  AND %[2]s))`, alias, stepActorFilter("s", "$2"))
+}
+
+// stepActorFilter matches steps signed by user or by someone who delegated
+// their signature to user for today.
+func stepActorFilter(step, user string) string {
+	return fmt.Sprintf(`(%[1]s.signer_user_id = %[2]s OR EXISTS (SELECT 1 FROM approval_delegations dl
+					WHERE dl.user_id = %[1]s.signer_user_id AND dl.delegate_user_id = %[2]s
+					  AND CURRENT_DATE BETWEEN dl.date_from AND dl.date_to))`, step, user)
+}
+
+// CreateApprovalRoute puts a document on an approval route. Stages are
+// numbered in request order; signers of one stage sign in parallel. A
+// document has at most one active route; a second one is storage.ErrDuplicate.
+func (r *Repo) CreateApprovalRoute(ctx context.Context, docType string, docID int64, req signature.CreateRouteRequest, userID int64) (int64, error) {
+	const op = "storage.repo.CreateApprovalRoute"
+
+	tableName, err := r.documentTable(ctx, docType)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	statusQuery := fmt.Sprintf(`
+		SELECT ds.code FROM %s t
+		JOIN document_status ds ON ds.id = t.status_id
+		WHERE t.id = $1
+		FOR UPDATE OF t`, tableName)
+
+	var statusCode string
+	if err := tx.QueryRowContext(ctx, statusQuery, docID).Scan(&statusCode); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: document not found: %w", op, storage.ErrNotFound)
+		}
+		return 0, fmt.Errorf("%s: failed to check document: %w", op, err)
+	}
+	if !routableStatusCodes[statusCode] {
+		return 0, fmt.Errorf("%s: document in status %q cannot be routed: %w", op, statusCode, storage.ErrInvalidStatus)
+	}
+
+	var routeID int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO approval_routes (document_type, document_id, created_by_user_id)
+		VALUES ($1, $2, $3)
+		RETURNING id`, docType, docID, userID).Scan(&routeID)
+	if err != nil {
+		if translatedErr := r.translator.Translate(err, op); translatedErr != nil {
+			return 0, translatedErr
+		}
+		return 0, fmt.Errorf("%s: failed to insert route: %w", op, err)
+	}
+
+	const insertStep = `
+		INSERT INTO approval_route_steps (route_id, stage, signer_user_id, due_date)
+		VALUES ($1, $2, $3, $4)`
+
+	for i, stage := range req.Stages {
+		for _, signer := range stage.Signers {
+			var dueDate *time.Time
+			if signer.DueDate != nil && *signer.DueDate != "" {
+				parsed, err := time.Parse("2006-01-02", *signer.DueDate)
+				if err != nil {
+					return 0, fmt.Errorf("%s: invalid due date format: %w", op, err)
+				}
+				dueDate = &parsed
+			}
+			if _, err := tx.ExecContext(ctx, insertStep, routeID, i+1, signer.UserID, dueDate); err != nil {
+				if translatedErr := r.translator.Translate(err, op); translatedErr != nil {
+					return 0, translatedErr
+				}
+				return 0, fmt.Errorf("%s: failed to insert step: %w", op, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: failed to commit: %w", op, err)
+	}
+	return routeID, nil
+}
+
+// GetApprovalRoute returns the most recent route of a document with its
+// steps, or storage.ErrNotFound when it was never routed.
+func (r *Repo) GetApprovalRoute(ctx context.Context, docType string, docID int64) (*signature.ApprovalRoute, error) {
+	const op = "storage.repo.GetApprovalRoute"
+
+	const routeQuery = `
+		SELECT ar.id, ar.document_type, ar.document_id, ar.status, ar.current_stage,
+			   ar.created_by_user_id, c.fio, ar.created_at, ar.completed_at
+		FROM approval_routes ar
+		LEFT JOIN users u ON ar.created_by_user_id = u.id
+		LEFT JOIN contacts c ON u.contact_id = c.id
+		WHERE ar.document_type = $1 AND ar.document_id = $2
+		ORDER BY ar.id DESC
+		LIMIT 1`
+
+	var route signature.ApprovalRoute
+	var createdByID sql.NullInt64
+	var createdByName sql.NullString
+	var completedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, routeQuery, docType, docID).Scan(
+		&route.ID, &route.DocumentType, &route.DocumentID, &route.Status, &route.CurrentStage,
+		&createdByID, &createdByName, &route.CreatedAt, &completedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+		return nil, fmt.Errorf("%s: failed to query route: %w", op, err)
+	}
+	if createdByID.Valid {
+		route.CreatedBy = &user.ShortInfo{ID: createdByID.Int64, Name: nullStringPtr(createdByName)}
+	}
+	if completedAt.Valid {
+		route.CompletedAt = &completedAt.Time
+	}
+
+	const stepsQuery = `
+		SELECT s.id, s.stage, s.signer_user_id, sc.fio, s.due_date, s.status,
+			   s.acted_by_user_id, ac.fio, s.acted_at, s.comment
+		FROM approval_route_steps s
+		LEFT JOIN users su ON s.signer_user_id = su.id
+		LEFT JOIN contacts sc ON su.contact_id = sc.id
+		LEFT JOIN users au ON s.acted_by_user_id = au.id
+		LEFT JOIN contacts ac ON au.contact_id = ac.id
+		WHERE s.route_id = $1
+		ORDER BY s.stage, s.id`
+
+	rows, err := r.db.QueryContext(ctx, stepsQuery, route.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query steps: %w", op, err)
+	}
+	defer rows.Close()
+
+	route.Steps = make([]signature.ApprovalStep, 0)
+	for rows.Next() {
+		var step signature.ApprovalStep
+		var signerName, actedByName sql.NullString
+		var dueDate, actedAt sql.NullTime
+		var actedByID sql.NullInt64
+		if err := rows.Scan(
+			&step.ID, &step.Stage, &step.Signer.ID, &signerName, &dueDate, &step.Status,
+			&actedByID, &actedByName, &actedAt, &step.Comment,
+		); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan step: %w", op, err)
+		}
+		step.Signer.Name = nullStringPtr(signerName)
+		if dueDate.Valid {
+			step.DueDate = &dueDate.Time
+		}
+		if actedByID.Valid {
+			step.ActedBy = &user.ShortInfo{ID: actedByID.Int64, Name: nullStringPtr(actedByName)}
+		}
+		if actedAt.Valid {
+			step.ActedAt = &actedAt.Time
+		}
+		route.Steps = append(route.Steps, step)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	return &route, nil
+}
+
+// HasActiveApprovalRoute reports whether the document is on an active route.
+func (r *Repo) HasActiveApprovalRoute(ctx context.Context, docType string, docID int64) (bool, error) {
+	const op = "storage.repo.HasActiveApprovalRoute"
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM approval_routes
+			WHERE document_type = $1 AND document_id = $2 AND status = 'active')`,
+		docType, docID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return exists, nil
+}
+
+// CancelApprovalRoute cancels the document's active route. Signatures already
+// given stay in the history; the document can then be signed directly or put
+// on a new route.
+func (r *Repo) CancelApprovalRoute(ctx context.Context, docType string, docID int64) error {
+	const op = "storage.repo.CancelApprovalRoute"
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE approval_routes SET status = 'cancelled', completed_at = NOW()
+		WHERE document_type = $1 AND document_id = $2 AND status = 'active'`,
+		docType, docID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: no active route: %w", op, storage.ErrNotFound)
+	}
+	return nil
+}
+
+// activeRoute is the locked state of a route while a signer acts on it.
+type activeRoute struct {
+	id           int64
+	currentStage int
+}
+
+// routeStep is a pending step of the current stage.
+type routeStep struct {
+	id       int64
+	signerID int64
+}
+
+// lockActiveRoute returns the document's active route locked for update, or
+// nil when there is none.
+func lockActiveRoute(ctx context.Context, tx *sql.Tx, docType string, docID int64) (*activeRoute, error) {
+	var route activeRoute
+	err := tx.QueryRowContext(ctx, `
+		SELECT id, current_stage FROM approval_routes
+		WHERE document_type = $1 AND document_id = $2 AND status = 'active'
+		FOR UPDATE`, docType, docID).Scan(&route.id, &route.currentStage)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &route, nil
+}
+
+// claimRouteStep finds the step of the current stage that userID may act on
+// and marks it with the given status. Returns storage.ErrNotYourTurn when
+// there is none.
+func claimRouteStep(ctx context.Context, tx *sql.Tx, route *activeRoute, userID int64, status string, comment *string) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, signer_user_id FROM approval_route_steps
+		WHERE route_id = $1 AND stage = $2 AND status = 'pending'
+		ORDER BY id
+		FOR UPDATE`, route.id, route.currentStage)
+	if err != nil {
+		return fmt.Errorf("query steps: %w", err)
+	}
+	var pending []routeStep
+	for rows.Next() {
+		var s routeStep
+		if err := rows.Scan(&s.id, &s.signerID); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan step: %w", err)
+		}
+		pending = append(pending, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("steps rows: %w", err)
+	}
+
+	delegators, err := activeDelegators(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+
+	step, ok := pickRouteStep(pending, userID, delegators)
+	if !ok {
+		return storage.ErrNotYourTurn
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE approval_route_steps
+		SET status = $1, acted_by_user_id = $2, acted_at = NOW(), comment = $3
+		WHERE id = $4`, status, userID, comment, step.id)
+	if err != nil {
+		return fmt.Errorf("update step: %w", err)
+	}
+	return nil
+}
+
+// activeDelegators returns the users who delegated their signature to userID
+// for today.
+func activeDelegators(ctx context.Context, tx *sql.Tx, userID int64) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT user_id FROM approval_delegations
+		WHERE delegate_user_id = $1 AND CURRENT_DATE BETWEEN date_from AND date_to`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query delegations: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan delegation: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// pickRouteStep prefers the user's own step over one they hold by
+// delegation, so a signer who is also someone's delegate signs for
+// themselves first.
+func pickRouteStep(pending []routeStep, userID int64, delegators []int64) (routeStep, bool) {
+	for _, s := range pending {
+		if s.signerID == userID {
+			return s, true
+		}
+	}
+	for _, s := range pending {
+		for _, d := range delegators {
+			if s.signerID == d {
+				return s, true
+			}
+		}
+	}
+	return routeStep{}, false
+}
+
+// advanceRoute moves the route past the current stage once none of its steps
+// is pending. Returns true when the last stage has been signed and the route
+// is completed.
+func advanceRoute(ctx context.Context, tx *sql.Tx, route *activeRoute) (bool, error) {
+	var remaining int
+	err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM approval_route_steps
+		WHERE route_id = $1 AND stage = $2 AND status = 'pending'`,
+		route.id, route.currentStage).Scan(&remaining)
+	if err != nil {
+		return false, fmt.Errorf("count pending steps: %w", err)
+	}
+	if remaining > 0 {
+		return false, nil
+	}
+
+	var next sql.NullInt64
+	err = tx.QueryRowContext(ctx, `
+		SELECT MIN(stage) FROM approval_route_steps
+		WHERE route_id = $1 AND stage > $2`,
+		route.id, route.currentStage).Scan(&next)
+	if err != nil {
+		return false, fmt.Errorf("find next stage: %w", err)
+	}
+
+	if next.Valid {
+		_, err = tx.ExecContext(ctx, `UPDATE approval_routes SET current_stage = $1 WHERE id = $2`, next.Int64, route.id)
+		if err != nil {
+			return false, fmt.Errorf("advance stage: %w", err)
+		}
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE approval_routes SET status = 'completed', completed_at = NOW() WHERE id = $1`, route.id)
+	if err != nil {
+		return false, fmt.Errorf("complete route: %w", err)
+	}
+	return true, nil
+}
+
+// rejectRoute closes the route as rejected.
+func rejectRoute(ctx context.Context, tx *sql.Tx, route *activeRoute) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE approval_routes SET status = 'rejected', completed_at = NOW() WHERE id = $1`, route.id)
+	if err != nil {
+		return fmt.Errorf("reject route: %w", err)
+	}
+	return nil
+}
+
+// CreateApprovalDelegation stores a delegation and returns its ID.
+func (r *Repo) CreateApprovalDelegation(ctx context.Context, userID, delegateUserID int64, dateFrom, dateTo time.Time, createdBy int64) (int64, error) {
+	const op = "storage.repo.CreateApprovalDelegation"
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO approval_delegations (user_id, delegate_user_id, date_from, date_to, created_by_user_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`, userID, delegateUserID, dateFrom, dateTo, createdBy).Scan(&id)
+	if err != nil {
+		if translatedErr := r.translator.Translate(err, op); translatedErr != nil {
+			return 0, translatedErr
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+// GetApprovalDelegations returns delegations given by or to userID that have
+// not ended yet, or all unexpired delegations when userID is 0.
+func (r *Repo) GetApprovalDelegations(ctx context.Context, userID int64) ([]signature.Delegation, error) {
+	const op = "storage.repo.GetApprovalDelegations"
+
+	const query = `
+		SELECT dl.id, dl.user_id, uc.fio, dl.delegate_user_id, dc.fio,
+			   dl.date_from, dl.date_to, dl.created_at
+		FROM approval_delegations dl
+		LEFT JOIN users u ON dl.user_id = u.id
+		LEFT JOIN contacts uc ON u.contact_id = uc.id
+		LEFT JOIN users du ON dl.delegate_user_id = du.id
+		LEFT JOIN contacts dc ON du.contact_id = dc.id
+		WHERE dl.date_to >= CURRENT_DATE
+		  AND ($1 = 0 OR dl.user_id = $1 OR dl.delegate_user_id = $1)
+		ORDER BY dl.date_from, dl.id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query: %w", op, err)
+	}
+	defer rows.Close()
+
+	delegations := make([]signature.Delegation, 0)
+	for rows.Next() {
+		var d signature.Delegation
+		var userName, delegateName sql.NullString
+		var dateFrom, dateTo time.Time
+		if err := rows.Scan(&d.ID, &d.UserID, &userName, &d.DelegateUserID, &delegateName,
+			&dateFrom, &dateTo, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan: %w", op, err)
+		}
+		d.UserName = nullStringPtr(userName)
+		d.DelegateName = nullStringPtr(delegateName)
+		d.DateFrom = dateFrom.Format("2006-01-02")
+		d.DateTo = dateTo.Format("2006-01-02")
+		delegations = append(delegations, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+	return delegations, nil
+}
+
+// GetApprovalDelegationByID returns a delegation, expired or not.
+func (r *Repo) GetApprovalDelegationByID(ctx context.Context, id int64) (*signature.Delegation, error) {
+	const op = "storage.repo.GetApprovalDelegationByID"
+
+	const query = `
+		SELECT dl.id, dl.user_id, uc.fio, dl.delegate_user_id, dc.fio,
+			   dl.date_from, dl.date_to, dl.created_at
+		FROM approval_delegations dl
+		LEFT JOIN users u ON dl.user_id = u.id
+		LEFT JOIN contacts uc ON u.contact_id = uc.id
+		LEFT JOIN users du ON dl.delegate_user_id = du.id
+		LEFT JOIN contacts dc ON du.contact_id = dc.id
+		WHERE dl.id = $1`
+
+	var d signature.Delegation
+	var userName, delegateName sql.NullString
+	var dateFrom, dateTo time.Time
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&d.ID, &d.UserID, &userName, &d.DelegateUserID, &delegateName,
+		&dateFrom, &dateTo, &d.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	d.UserName = nullStringPtr(userName)
+	d.DelegateName = nullStringPtr(delegateName)
+	d.DateFrom = dateFrom.Format("2006-01-02")
+	d.DateTo = dateTo.Format("2006-01-02")
+	return &d, nil
+}
+
+// DeleteApprovalDelegation revokes a delegation.
+func (r *Repo) DeleteApprovalDelegation(ctx context.Context, id int64) error {
+	const op = "storage.repo.DeleteApprovalDelegation"
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM approval_delegations WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	return nil
+}
+
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
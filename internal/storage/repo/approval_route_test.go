@@ -0,0 +1,36 @@
+package repo
+
+import "testing"
+
+func TestPickRouteStep(t *testing.T) {
+	pending := []routeStep{
+		{id: 1, signerID: 10},
+		{id: 2, signerID: 20},
+	}
+
+	tests := []struct {
+		name       string
+		userID     int64
+		delegators []int64
+		wantID     int64
+		wantOK     bool
+	}{
+		{name: "own step", userID: 20, wantID: 2, wantOK: true},
+		{name: "delegated step", userID: 30, delegators: []int64{10}, wantID: 1, wantOK: true},
+		{name: "own step before delegated one", userID: 20, delegators: []int64{10}, wantID: 2, wantOK: true},
+		{name: "delegation for a signer of another stage", userID: 30, delegators: []int64{40}},
+		{name: "not a signer", userID: 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := pickRouteStep(pending, tt.userID, tt.delegators)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && got.id != tt.wantID {
+				t.Fatalf("picked step %d, want %d", got.id, tt.wantID)
+			}
+		})
+	}
+}
@@ -8,36 +8,62 @@ import (
 
 	"github.com/lib/pq"
 	"srmt-admin/internal/lib/dto"
-	"srmt-admin/internal/lib/model/decree"
-	decree_type "srmt-admin/internal/lib/model/decree-type"
+	"srmt-admin/internal/lib/model/document"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
 	document_status "srmt-admin/internal/lib/model/document-status"
 	"srmt-admin/internal/lib/model/file"
 	"srmt-admin/internal/lib/model/user"
 	"srmt-admin/internal/storage"
 )
 
-// AddDecree creates a new decree
-func (r *Repo) AddDecree(ctx context.Context, req dto.AddDecreeRequest, createdByID int64) (int64, error) {
-	const op = "storage.repo.AddDecree"
+// Registry documents of every kind share one table layout, so the queries
+// below only differ in the table names taken from the kind catalogue.
+
+// AddDocument creates a document of kind and returns its id and number.
+// When a numbering scheme applies, the number is allocated from it (or taken
+// from the reservation in req) in the same transaction and recorded in the
+// registration journal. When the kind has unique numbers, a number already
+// used by another document of the kind is storage.ErrDuplicate.
+func (r *Repo) AddDocument(ctx context.Context, kind document_kind.Model, req dto.AddDocumentRequest, createdByID int64) (int64, *string, error) {
+	const op = "storage.repo.AddDocument"
 
 	statusID := 1 // Default to 'draft' status
 	if req.StatusID != nil {
 		statusID = *req.StatusID
 	}
 
-	const query = `
-		INSERT INTO decrees (
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	registered, reservationID, err := registerDocumentNumber(ctx, tx, kind, req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	number := req.Number
+	if registered != nil {
+		number = &registered.number
+	}
+
+	if err := checkDocumentNumberFree(ctx, tx, kind, number, 0); err != nil {
+		return 0, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (
 			name, number, document_date, description, type_id, status_id,
 			responsible_contact_id, organization_id, executor_contact_id,
 			due_date, parent_document_id, created_by_user_id
 		)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-		RETURNING id`
+		RETURNING id`, kind.TableName)
 
 	var id int64
-	err := r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		req.Name,
-		req.Number,
+		number,
 		req.DocumentDate,
 		req.Description,
 		req.TypeID,
@@ -51,38 +77,73 @@ func (r *Repo) AddDecree(ctx context.Context, req dto.AddDecreeRequest, createdB
 	).Scan(&id)
 	if err != nil {
 		if translatedErr := r.translator.Translate(err, op); translatedErr != nil {
-			return 0, translatedErr
+			return 0, nil, translatedErr
 		}
-		return 0, fmt.Errorf("%s: failed to insert decree: %w", op, err)
+		return 0, nil, fmt.Errorf("%s: failed to insert %s: %w", op, kind.Code, err)
+	}
+
+	if registered != nil {
+		if err := r.recordDocumentNumber(ctx, tx, registered, reservationID, id, createdByID, op); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, nil, fmt.Errorf("%s: failed to commit: %w", op, err)
+	}
+
+	return id, number, nil
+}
+
+// checkDocumentNumberFree fails with storage.ErrDuplicate when the kind has
+// unique numbers and number is taken by a document other than excludeID. The
+// advisory lock serializes concurrent registrations of the kind until the
+// caller's transaction ends.
+func checkDocumentNumberFree(ctx context.Context, tx *sql.Tx, kind document_kind.Model, number *string, excludeID int64) error {
+	if !kind.UniqueNumber || number == nil || *number == "" {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, kind.TableName); err != nil {
+		return fmt.Errorf("failed to lock numbering: %w", err)
 	}
 
-	return id, nil
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE number = $1 AND id <> $2)`, kind.TableName)
+	var taken bool
+	if err := tx.QueryRowContext(ctx, query, *number, excludeID).Scan(&taken); err != nil {
+		return fmt.Errorf("failed to check number: %w", err)
+	}
+	if taken {
+		return fmt.Errorf("number %q is already used: %w", *number, storage.ErrDuplicate)
+	}
+	return nil
 }
 
-// GetDecreeByID retrieves a single decree with all joined data
-func (r *Repo) GetDecreeByID(ctx context.Context, id int64) (*decree.ResponseModel, error) {
-	const op = "storage.repo.GetDecreeByID"
+// GetDocumentByID retrieves a single document of kind with all joined data
+func (r *Repo) GetDocumentByID(ctx context.Context, kind document_kind.Model, id int64) (*document.ResponseModel, error) {
+	const op = "storage.repo.GetDocumentByID"
 
-	query := selectDecreeFields + fromDecreeJoins + ` WHERE d.id = $1`
+	query := selectDocumentFields + documentJoins(kind) + ` WHERE d.id = $1`
 
 	row := r.db.QueryRowContext(ctx, query, id)
-	doc, err := scanDecreeRow(row)
+	doc, err := scanDocumentRow(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, storage.ErrNotFound
 		}
-		return nil, fmt.Errorf("%s: failed to scan decree row: %w", op, err)
+		return nil, fmt.Errorf("%s: failed to scan %s row: %w", op, kind.Code, err)
 	}
+	doc.Kind = kind.Code
 
 	// Load files for this document
-	files, err := r.loadDecreeFiles(ctx, doc.ID)
+	files, err := r.loadDocumentFiles(ctx, kind, doc.ID)
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to load files: %w", op, err)
 	}
 	doc.Files = files
 
 	// Load linked documents
-	links, err := r.loadDecreeLinkedDocuments(ctx, doc.ID)
+	links, err := r.loadLinkedDocuments(ctx, kind, doc.ID)
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to load linked documents: %w", op, err)
 	}
@@ -91,11 +152,11 @@ func (r *Repo) GetDecreeByID(ctx context.Context, id int64) (*decree.ResponseMod
 	return doc, nil
 }
 
-// GetAllDecrees retrieves all decrees with optional filters
-func (r *Repo) GetAllDecrees(ctx context.Context, filters dto.GetAllDecreesFilters) ([]*decree.ResponseModel, error) {
-	const op = "storage.repo.GetAllDecrees"
+// GetAllDocuments retrieves all documents of kind with optional filters
+func (r *Repo) GetAllDocuments(ctx context.Context, kind document_kind.Model, filters dto.GetAllDocumentsFilters) ([]*document.ResponseModel, error) {
+	const op = "storage.repo.GetAllDocuments"
 
-	query := selectDecreeFields + fromDecreeJoins
+	query := selectDocumentFields + documentJoins(kind)
 
 	var whereClauses []string
 	var args []interface{}
@@ -165,16 +226,17 @@ func (r *Repo) GetAllDecrees(ctx context.Context, filters dto.GetAllDecreesFilte
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("%s: failed to query decrees: %w", op, err)
+		return nil, fmt.Errorf("%s: failed to query %s: %w", op, kind.TableName, err)
 	}
 	defer rows.Close()
 
-	var documents []*decree.ResponseModel
+	documents := make([]*document.ResponseModel, 0)
 	for rows.Next() {
-		doc, err := scanDecreeRow(rows)
+		doc, err := scanDocumentRow(rows)
 		if err != nil {
-			return nil, fmt.Errorf("%s: failed to scan decree row: %w", op, err)
+			return nil, fmt.Errorf("%s: failed to scan %s row: %w", op, kind.Code, err)
 		}
+		doc.Kind = kind.Code
 		documents = append(documents, doc)
 	}
 
@@ -182,15 +244,11 @@ func (r *Repo) GetAllDecrees(ctx context.Context, filters dto.GetAllDecreesFilte
 		return nil, fmt.Errorf("%s: rows iteration error: %w", op, err)
 	}
 
-	if documents == nil {
-		documents = make([]*decree.ResponseModel, 0)
-	}
-
 	// Load files for each document
 	for _, doc := range documents {
-		files, err := r.loadDecreeFiles(ctx, doc.ID)
+		files, err := r.loadDocumentFiles(ctx, kind, doc.ID)
 		if err != nil {
-			return nil, fmt.Errorf("%s: failed to load files for decree %d: %w", op, doc.ID, err)
+			return nil, fmt.Errorf("%s: failed to load files for %s %d: %w", op, kind.Code, doc.ID, err)
 		}
 		doc.Files = files
 	}
@@ -198,9 +256,10 @@ func (r *Repo) GetAllDecrees(ctx context.Context, filters dto.GetAllDecreesFilte
 	return documents, nil
 }
 
-// EditDecree updates a decree
-func (r *Repo) EditDecree(ctx context.Context, id int64, req dto.EditDecreeRequest, updatedByID int64) error {
-	const op = "storage.repo.EditDecree"
+// EditDocument updates a document of kind. Status changes go through
+// ChangeDocumentStatus; req.StatusID is ignored here.
+func (r *Repo) EditDocument(ctx context.Context, kind document_kind.Model, id int64, req dto.EditDocumentRequest, updatedByID int64) error {
+	const op = "storage.repo.EditDocument"
 
 	var updates []string
 	var args []interface{}
@@ -231,11 +290,6 @@ func (r *Repo) EditDecree(ctx context.Context, id int64, req dto.EditDecreeReque
 		args = append(args, *req.TypeID)
 		argID++
 	}
-	if req.StatusID != nil {
-		updates = append(updates, fmt.Sprintf("status_id = $%d", argID))
-		args = append(args, *req.StatusID)
-		argID++
-	}
 	if req.ResponsibleContactID != nil {
 		updates = append(updates, fmt.Sprintf("responsible_contact_id = $%d", argID))
 		args = append(args, *req.ResponsibleContactID)
@@ -271,18 +325,39 @@ func (r *Repo) EditDecree(ctx context.Context, id int64, req dto.EditDecreeReque
 		return nil // Only updated_by_user_id, nothing meaningful to update
 	}
 
-	query := fmt.Sprintf("UPDATE decrees SET %s WHERE id = $%d",
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if req.Number != nil {
+		issued, err := documentNumberIssued(ctx, tx, kind, id)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if issued {
+			return fmt.Errorf("%s: %w", op, storage.ErrNumberAssigned)
+		}
+	}
+
+	if err := checkDocumentNumberFree(ctx, tx, kind, req.Number, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d",
+		kind.TableName,
 		strings.Join(updates, ", "),
 		argID,
 	)
 	args = append(args, id)
 
-	res, err := r.db.ExecContext(ctx, query, args...)
+	res, err := tx.ExecContext(ctx, query, args...)
 	if err != nil {
 		if translatedErr := r.translator.Translate(err, op); translatedErr != nil {
 			return translatedErr
 		}
-		return fmt.Errorf("%s: failed to update decree: %w", op, err)
+		return fmt.Errorf("%s: failed to update %s: %w", op, kind.Code, err)
 	}
 
 	rowsAffected, _ := res.RowsAffected()
@@ -290,19 +365,30 @@ func (r *Repo) EditDecree(ctx context.Context, id int64, req dto.EditDecreeReque
 		return storage.ErrNotFound
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: failed to commit: %w", op, err)
+	}
+
 	return nil
 }
 
-// DeleteDecree deletes a decree
-func (r *Repo) DeleteDecree(ctx context.Context, id int64) error {
-	const op = "storage.repo.DeleteDecree"
+// DeleteDocument deletes a document of kind. A number it was issued from a
+// numbering scheme is cancelled in the journal.
+func (r *Repo) DeleteDocument(ctx context.Context, kind document_kind.Model, id int64) error {
+	const op = "storage.repo.DeleteDocument"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
 
-	res, err := r.db.ExecContext(ctx, "DELETE FROM decrees WHERE id = $1", id)
+	res, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = $1", kind.TableName), id)
 	if err != nil {
 		if translatedErr := r.translator.Translate(err, op); translatedErr != nil {
 			return translatedErr
 		}
-		return fmt.Errorf("%s: failed to delete decree: %w", op, err)
+		return fmt.Errorf("%s: failed to delete %s: %w", op, kind.Code, err)
 	}
 
 	rowsAffected, err := res.RowsAffected()
@@ -314,61 +400,85 @@ func (r *Repo) DeleteDecree(ctx context.Context, id int64) error {
 		return storage.ErrNotFound
 	}
 
+	if err := cancelDocumentNumber(ctx, tx, kind, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: failed to commit: %w", op, err)
+	}
+
 	return nil
 }
 
-// LinkDecreeFiles links files to a decree
-func (r *Repo) LinkDecreeFiles(ctx context.Context, decreeID int64, fileIDs []int64) error {
-	const op = "storage.repo.LinkDecreeFiles"
+// LinkDocumentFiles links files to a document of kind
+func (r *Repo) LinkDocumentFiles(ctx context.Context, kind document_kind.Model, docID int64, fileIDs []int64) error {
+	const op = "storage.repo.LinkDocumentFiles"
 
 	if len(fileIDs) == 0 {
 		return nil
 	}
 
-	query := `
-		INSERT INTO decree_file_links (decree_id, file_id)
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s, file_id)
 		VALUES ($1, unnest($2::bigint[]))
 		ON CONFLICT DO NOTHING
-	`
+	`, kind.FileLinksTable(), kind.ForeignKey())
 
-	_, err := r.db.ExecContext(ctx, query, decreeID, pq.Array(fileIDs))
+	_, err := r.db.ExecContext(ctx, query, docID, pq.Array(fileIDs))
 	if err != nil {
-		return fmt.Errorf("%s: failed to link files: %w", op, err)
+		return r.translator.Translate(err, op)
 	}
 
 	return nil
 }
 
-// UnlinkDecreeFiles removes all file links for a decree
-func (r *Repo) UnlinkDecreeFiles(ctx context.Context, decreeID int64) error {
-	const op = "storage.repo.UnlinkDecreeFiles"
+// UnlinkDocumentFiles removes all file links of a document of kind
+func (r *Repo) UnlinkDocumentFiles(ctx context.Context, kind document_kind.Model, docID int64) error {
+	const op = "storage.repo.UnlinkDocumentFiles"
 
-	query := `DELETE FROM decree_file_links WHERE decree_id = $1`
-	_, err := r.db.ExecContext(ctx, query, decreeID)
+	query := fmt.Sprintf(`DELETE FROM %s WHERE %s = $1`, kind.FileLinksTable(), kind.ForeignKey())
+	_, err := r.db.ExecContext(ctx, query, docID)
 	if err != nil {
-		return fmt.Errorf("%s: failed to unlink files: %w", op, err)
+		return r.translator.Translate(err, op)
 	}
 
 	return nil
 }
 
-// LinkDecreeDocuments creates links to other documents
-func (r *Repo) LinkDecreeDocuments(ctx context.Context, decreeID int64, links []dto.LinkedDocumentRequest, userID int64) error {
-	const op = "storage.repo.LinkDecreeDocuments"
+// LinkDocuments creates links from a document of kind to other documents.
+// Link targets are registered kinds or legal documents; any other type is
+// storage.ErrUnknownDocumentKind and nothing is linked.
+func (r *Repo) LinkDocuments(ctx context.Context, kind document_kind.Model, docID int64, links []dto.LinkedDocumentRequest, userID int64) error {
+	const op = "storage.repo.LinkDocuments"
 
 	if len(links) == 0 {
 		return nil
 	}
 
-	query := `
-		INSERT INTO decree_document_links (decree_id, linked_document_type, linked_document_id, link_description, created_by_user_id)
+	kinds, err := r.GetDocumentKinds(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	linkable := map[string]bool{document_kind.LegalDocumentLinkType: true}
+	for _, k := range kinds {
+		linkable[k.Code] = true
+	}
+	for _, link := range links {
+		if !linkable[link.LinkedDocumentType] {
+			return fmt.Errorf("%s: link type %q: %w", op, link.LinkedDocumentType, storage.ErrUnknownDocumentKind)
+		}
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s (%[2]s, linked_document_type, linked_document_id, link_description, created_by_user_id)
 		VALUES ($1, $2, $3, $4, $5)
-		ON CONFLICT (decree_id, linked_document_type, linked_document_id) DO UPDATE
+		ON CONFLICT (%[2]s, linked_document_type, linked_document_id) DO UPDATE
 		SET link_description = EXCLUDED.link_description
-	`
+	`, kind.DocumentLinksTable(), kind.ForeignKey())
 
 	for _, link := range links {
-		_, err := r.db.ExecContext(ctx, query, decreeID, link.LinkedDocumentType, link.LinkedDocumentID, link.LinkDescription, userID)
+		_, err := r.db.ExecContext(ctx, query, docID, link.LinkedDocumentType, link.LinkedDocumentID, link.LinkDescription, userID)
 		if err != nil {
 			return fmt.Errorf("%s: failed to link document: %w", op, err)
 		}
@@ -377,12 +487,12 @@ func (r *Repo) LinkDecreeDocuments(ctx context.Context, decreeID int64, links []
 	return nil
 }
 
-// UnlinkDecreeDocuments removes all document links for a decree
-func (r *Repo) UnlinkDecreeDocuments(ctx context.Context, decreeID int64) error {
-	const op = "storage.repo.UnlinkDecreeDocuments"
+// UnlinkDocuments removes all document links of a document of kind
+func (r *Repo) UnlinkDocuments(ctx context.Context, kind document_kind.Model, docID int64) error {
+	const op = "storage.repo.UnlinkDocuments"
 
-	query := `DELETE FROM decree_document_links WHERE decree_id = $1`
-	_, err := r.db.ExecContext(ctx, query, decreeID)
+	query := fmt.Sprintf(`DELETE FROM %s WHERE %s = $1`, kind.DocumentLinksTable(), kind.ForeignKey())
+	_, err := r.db.ExecContext(ctx, query, docID)
 	if err != nil {
 		return fmt.Errorf("%s: failed to unlink documents: %w", op, err)
 	}
@@ -390,11 +500,11 @@ func (r *Repo) UnlinkDecreeDocuments(ctx context.Context, decreeID int64) error
 	return nil
 }
 
-// GetDecreeStatusHistory retrieves status change history for a decree
-func (r *Repo) GetDecreeStatusHistory(ctx context.Context, decreeID int64) ([]decree.StatusHistory, error) {
-	const op = "storage.repo.GetDecreeStatusHistory"
+// GetDocumentStatusHistory retrieves status change history of a document of kind
+func (r *Repo) GetDocumentStatusHistory(ctx context.Context, kind document_kind.Model, docID int64) ([]document.StatusHistory, error) {
+	const op = "storage.repo.GetDocumentStatusHistory"
 
-	query := `
+	query := fmt.Sprintf(`
 		SELECT
 			h.id,
 			h.from_status_id,
@@ -407,24 +517,24 @@ func (r *Repo) GetDecreeStatusHistory(ctx context.Context, decreeID int64) ([]de
 			h.changed_by_user_id,
 			COALESCE(c.fio, '') as changed_by_fio,
 			h.comment
-		FROM decree_status_history h
+		FROM %s h
 		LEFT JOIN document_status fs ON h.from_status_id = fs.id
 		INNER JOIN document_status ts ON h.to_status_id = ts.id
 		LEFT JOIN users u ON h.changed_by_user_id = u.id
 		LEFT JOIN contacts c ON u.contact_id = c.id
-		WHERE h.decree_id = $1
+		WHERE h.%s = $1
 		ORDER BY h.changed_at DESC
-	`
+	`, kind.HistoryTable(), kind.ForeignKey())
 
-	rows, err := r.db.QueryContext(ctx, query, decreeID)
+	rows, err := r.db.QueryContext(ctx, query, docID)
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to query status history: %w", op, err)
 	}
 	defer rows.Close()
 
-	var history []decree.StatusHistory
+	history := make([]document.StatusHistory, 0)
 	for rows.Next() {
-		var h decree.StatusHistory
+		var h document.StatusHistory
 		var fromStatusID sql.NullInt64
 		var fromStatusCode, fromStatusName sql.NullString
 		var toStatusID int
@@ -482,50 +592,24 @@ func (r *Repo) GetDecreeStatusHistory(ctx context.Context, decreeID int64) ([]de
 		return nil, fmt.Errorf("%s: rows iteration error: %w", op, err)
 	}
 
-	if history == nil {
-		history = make([]decree.StatusHistory, 0)
-	}
-
 	return history, nil
 }
 
-// AddDecreeStatusHistoryComment adds a comment to the latest status history entry
-func (r *Repo) AddDecreeStatusHistoryComment(ctx context.Context, decreeID int64, comment string) error {
-	const op = "storage.repo.AddDecreeStatusHistoryComment"
-
-	query := `
-		UPDATE decree_status_history
-		SET comment = $2
-		WHERE id = (
-			SELECT id FROM decree_status_history
-			WHERE decree_id = $1
-			ORDER BY changed_at DESC
-			LIMIT 1
-		)
-	`
-
-	_, err := r.db.ExecContext(ctx, query, decreeID, comment)
-	if err != nil {
-		return fmt.Errorf("%s: failed to add comment: %w", op, err)
-	}
+// GetDocumentTypes retrieves the types of kind
+func (r *Repo) GetDocumentTypes(ctx context.Context, kind document_kind.Model) ([]document.Type, error) {
+	const op = "storage.repo.GetDocumentTypes"
 
-	return nil
-}
-
-// GetAllDecreeTypes retrieves all decree types
-func (r *Repo) GetAllDecreeTypes(ctx context.Context) ([]decree_type.Model, error) {
-	const op = "storage.repo.GetAllDecreeTypes"
-	const query = "SELECT id, name, COALESCE(description, '') as description FROM decree_type ORDER BY id"
+	query := fmt.Sprintf("SELECT id, name, COALESCE(description, '') as description FROM %s ORDER BY id", kind.TypeTable())
 
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("%s: failed to query decree types: %w", op, err)
+		return nil, fmt.Errorf("%s: failed to query %s types: %w", op, kind.Code, err)
 	}
 	defer rows.Close()
 
-	var types []decree_type.Model
+	types := make([]document.Type, 0)
 	for rows.Next() {
-		var t decree_type.Model
+		var t document.Type
 		if err := rows.Scan(&t.ID, &t.Name, &t.Description); err != nil {
 			return nil, fmt.Errorf("%s: failed to scan type row: %w", op, err)
 		}
@@ -536,10 +620,6 @@ func (r *Repo) GetAllDecreeTypes(ctx context.Context) ([]decree_type.Model, erro
 		return nil, fmt.Errorf("%s: rows iteration error: %w", op, err)
 	}
 
-	if types == nil {
-		types = make([]decree_type.Model, 0)
-	}
-
 	return types, nil
 }
 
@@ -580,8 +660,7 @@ func (r *Repo) GetAllDocumentStatuses(ctx context.Context) ([]document_status.Mo
 
 // --- Query fragments and helpers ---
 
-const (
-	selectDecreeFields = `
+const selectDocumentFields = `
 		SELECT
 			d.id,
 			d.name,
@@ -612,34 +691,36 @@ const (
 			d.updated_by_user_id,
 			COALESCE(uc.fio, '') as updated_by_fio
 	`
-	fromDecreeJoins = `
-		FROM decrees d
-		INNER JOIN decree_type t ON d.type_id = t.id
+
+func documentJoins(kind document_kind.Model) string {
+	return fmt.Sprintf(`
+		FROM %[1]s d
+		INNER JOIN %[2]s t ON d.type_id = t.id
 		INNER JOIN document_status s ON d.status_id = s.id
 		LEFT JOIN contacts rc ON d.responsible_contact_id = rc.id
 		LEFT JOIN organizations o ON d.organization_id = o.id
 		LEFT JOIN contacts ec ON d.executor_contact_id = ec.id
-		LEFT JOIN decrees pd ON d.parent_document_id = pd.id
+		LEFT JOIN %[1]s pd ON d.parent_document_id = pd.id
 		LEFT JOIN users cu ON d.created_by_user_id = cu.id
 		LEFT JOIN contacts cc ON cu.contact_id = cc.id
 		LEFT JOIN users uu ON d.updated_by_user_id = uu.id
 		LEFT JOIN contacts uc ON uu.contact_id = uc.id
-	`
-)
+	`, kind.TableName, kind.TypeTable())
+}
 
-// loadDecreeFiles loads files for a decree
-func (r *Repo) loadDecreeFiles(ctx context.Context, decreeID int64) ([]file.Model, error) {
-	const op = "storage.repo.loadDecreeFiles"
+// loadDocumentFiles loads files of a document of kind
+func (r *Repo) loadDocumentFiles(ctx context.Context, kind document_kind.Model, docID int64) ([]file.Model, error) {
+	const op = "storage.repo.loadDocumentFiles"
 
-	query := `
+	query := fmt.Sprintf(`
 		SELECT f.id, f.file_name, f.object_key, f.category_id, f.mime_type, f.size_bytes, f.created_at, f.target_date
 		FROM files f
-		INNER JOIN decree_file_links dfl ON f.id = dfl.file_id
-		WHERE dfl.decree_id = $1
+		INNER JOIN %s fl ON f.id = fl.file_id
+		WHERE fl.%s = $1
 		ORDER BY f.created_at DESC
-	`
+	`, kind.FileLinksTable(), kind.ForeignKey())
 
-	rows, err := r.db.QueryContext(ctx, query, decreeID)
+	rows, err := r.db.QueryContext(ctx, query, docID)
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to query files: %w", op, err)
 	}
@@ -661,46 +742,37 @@ func (r *Repo) loadDecreeFiles(ctx context.Context, decreeID int64) ([]file.Mode
 	return files, nil
 }
 
-// loadDecreeLinkedDocuments loads linked documents for a decree
-func (r *Repo) loadDecreeLinkedDocuments(ctx context.Context, decreeID int64) ([]decree.DocumentLink, error) {
-	const op = "storage.repo.loadDecreeLinkedDocuments"
+// loadLinkedDocuments loads the documents linked to a document of kind. Names
+// and numbers of registry documents come from the registry_documents view.
+func (r *Repo) loadLinkedDocuments(ctx context.Context, kind document_kind.Model, docID int64) ([]document.DocumentLink, error) {
+	const op = "storage.repo.loadLinkedDocuments"
 
-	query := `
+	query := fmt.Sprintf(`
 		SELECT
 			dl.id,
 			dl.linked_document_type,
 			dl.linked_document_id,
 			dl.link_description,
-			CASE dl.linked_document_type
-				WHEN 'decree' THEN (SELECT name FROM decrees WHERE id = dl.linked_document_id)
-				WHEN 'report' THEN (SELECT name FROM reports WHERE id = dl.linked_document_id)
-				WHEN 'letter' THEN (SELECT name FROM letters WHERE id = dl.linked_document_id)
-				WHEN 'instruction' THEN (SELECT name FROM instructions WHERE id = dl.linked_document_id)
-				WHEN 'legal_document' THEN (SELECT name FROM legal_documents WHERE id = dl.linked_document_id)
-				ELSE NULL
-			END as document_name,
-			CASE dl.linked_document_type
-				WHEN 'decree' THEN (SELECT number FROM decrees WHERE id = dl.linked_document_id)
-				WHEN 'report' THEN (SELECT number FROM reports WHERE id = dl.linked_document_id)
-				WHEN 'letter' THEN (SELECT number FROM letters WHERE id = dl.linked_document_id)
-				WHEN 'instruction' THEN (SELECT number FROM instructions WHERE id = dl.linked_document_id)
-				WHEN 'legal_document' THEN (SELECT number FROM legal_documents WHERE id = dl.linked_document_id)
-				ELSE NULL
-			END as document_number
-		FROM decree_document_links dl
-		WHERE dl.decree_id = $1
+			COALESCE(rd.name, ld.name) as document_name,
+			COALESCE(rd.number, ld.number) as document_number
+		FROM %s dl
+		LEFT JOIN registry_documents rd
+			ON rd.document_type = dl.linked_document_type AND rd.id = dl.linked_document_id
+		LEFT JOIN legal_documents ld
+			ON dl.linked_document_type = '%s' AND ld.id = dl.linked_document_id
+		WHERE dl.%s = $1
 		ORDER BY dl.created_at DESC
-	`
+	`, kind.DocumentLinksTable(), document_kind.LegalDocumentLinkType, kind.ForeignKey())
 
-	rows, err := r.db.QueryContext(ctx, query, decreeID)
+	rows, err := r.db.QueryContext(ctx, query, docID)
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to query linked documents: %w", op, err)
 	}
 	defer rows.Close()
 
-	var links []decree.DocumentLink
+	var links []document.DocumentLink
 	for rows.Next() {
-		var link decree.DocumentLink
+		var link document.DocumentLink
 		var linkDesc, docName, docNumber sql.NullString
 
 		err := rows.Scan(
@@ -735,10 +807,10 @@ func (r *Repo) loadDecreeLinkedDocuments(ctx context.Context, decreeID int64) ([
 	return links, nil
 }
 
-func scanDecreeRow(scanner interface {
+func scanDocumentRow(scanner interface {
 	Scan(dest ...interface{}) error
-}) (*decree.ResponseModel, error) {
-	var doc decree.ResponseModel
+}) (*document.ResponseModel, error) {
+	var doc document.ResponseModel
 	var number, description sql.NullString
 	var typeID int
 	var typeName, typeDescription string
@@ -809,7 +881,7 @@ func scanDecreeRow(scanner interface {
 	}
 
 	// Build type object
-	doc.Type = decree_type.Model{
+	doc.Type = document.Type{
 		ID:          typeID,
 		Name:        typeName,
 		Description: typeDescription,
@@ -824,7 +896,7 @@ func scanDecreeRow(scanner interface {
 
 	// Build responsible contact
 	if responsibleContactID.Valid {
-		doc.ResponsibleContact = &decree.ContactShortInfo{
+		doc.ResponsibleContact = &document.ContactShortInfo{
 			ID:   responsibleContactID.Int64,
 			Name: responsibleName,
 		}
@@ -832,7 +904,7 @@ func scanDecreeRow(scanner interface {
 
 	// Build organization
 	if organizationID.Valid {
-		doc.Organization = &decree.OrganizationShortInfo{
+		doc.Organization = &document.OrganizationShortInfo{
 			ID:   organizationID.Int64,
 			Name: organizationName,
 		}
@@ -840,7 +912,7 @@ func scanDecreeRow(scanner interface {
 
 	// Build executor contact
 	if executorContactID.Valid {
-		doc.ExecutorContact = &decree.ContactShortInfo{
+		doc.ExecutorContact = &document.ContactShortInfo{
 			ID:   executorContactID.Int64,
 			Name: executorName,
 		}
@@ -848,7 +920,7 @@ func scanDecreeRow(scanner interface {
 
 	// Build parent document
 	if parentDocumentID.Valid {
-		doc.ParentDocument = &decree.ParentDecreeInfo{
+		doc.ParentDocument = &document.ParentInfo{
 			ID:   parentDocumentID.Int64,
 			Name: parentName.String,
 		}
@@ -0,0 +1,156 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
+	"srmt-admin/internal/storage"
+)
+
+const selectDocumentKindFields = `
+	SELECT code, name, table_name, required_fields, unique_number, is_active, display_order, created_at, updated_at
+	FROM document_kinds`
+
+func scanDocumentKind(s interface{ Scan(...any) error }) (*document_kind.Model, error) {
+	var k document_kind.Model
+	var updatedAt sql.NullTime
+	if err := s.Scan(&k.Code, &k.Name, &k.TableName, pq.Array(&k.RequiredFields), &k.UniqueNumber,
+		&k.IsActive, &k.DisplayOrder, &k.CreatedAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	if k.RequiredFields == nil {
+		k.RequiredFields = make([]string, 0)
+	}
+	if updatedAt.Valid {
+		k.UpdatedAt = &updatedAt.Time
+	}
+	return &k, nil
+}
+
+// GetDocumentKinds returns the document kind catalogue
+func (r *Repo) GetDocumentKinds(ctx context.Context) ([]document_kind.Model, error) {
+	const op = "storage.repo.GetDocumentKinds"
+
+	rows, err := r.db.QueryContext(ctx, selectDocumentKindFields+` ORDER BY display_order, code`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query kinds: %w", op, err)
+	}
+	defer rows.Close()
+
+	kinds := make([]document_kind.Model, 0)
+	for rows.Next() {
+		k, err := scanDocumentKind(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to scan kind row: %w", op, err)
+		}
+		kinds = append(kinds, *k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows iteration error: %w", op, err)
+	}
+	return kinds, nil
+}
+
+// GetDocumentKind returns the catalogue entry of code. An unregistered code
+// is storage.ErrUnknownDocumentKind.
+func (r *Repo) GetDocumentKind(ctx context.Context, code string) (*document_kind.Model, error) {
+	const op = "storage.repo.GetDocumentKind"
+
+	k, err := scanDocumentKind(r.db.QueryRowContext(ctx, selectDocumentKindFields+` WHERE code = $1`, code))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %q: %w", op, code, storage.ErrUnknownDocumentKind)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return k, nil
+}
+
+// DocumentKindExists checks if code is a registered document kind
+func (r *Repo) DocumentKindExists(ctx context.Context, code string) (bool, error) {
+	const op = "storage.repo.DocumentKindExists"
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM document_kinds WHERE code = $1)`, code).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return exists, nil
+}
+
+// EditDocumentKind updates the name, required fields, number uniqueness,
+// activity and order of a kind. Its storage tables cannot be changed.
+func (r *Repo) EditDocumentKind(ctx context.Context, code string, req document_kind.EditRequest) error {
+	const op = "storage.repo.EditDocumentKind"
+
+	var updates []string
+	var args []interface{}
+	argID := 1
+
+	if req.Name != nil {
+		updates = append(updates, fmt.Sprintf("name = $%d", argID))
+		args = append(args, *req.Name)
+		argID++
+	}
+	if req.RequiredFields != nil {
+		updates = append(updates, fmt.Sprintf("required_fields = $%d", argID))
+		args = append(args, pq.Array(req.RequiredFields))
+		argID++
+	}
+	if req.UniqueNumber != nil {
+		updates = append(updates, fmt.Sprintf("unique_number = $%d", argID))
+		args = append(args, *req.UniqueNumber)
+		argID++
+	}
+	if req.IsActive != nil {
+		updates = append(updates, fmt.Sprintf("is_active = $%d", argID))
+		args = append(args, *req.IsActive)
+		argID++
+	}
+	if req.DisplayOrder != nil {
+		updates = append(updates, fmt.Sprintf("display_order = $%d", argID))
+		args = append(args, *req.DisplayOrder)
+		argID++
+	}
+
+	if len(updates) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf("UPDATE document_kinds SET %s WHERE code = $%d", strings.Join(updates, ", "), argID)
+	args = append(args, code)
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		if translatedErr := r.translator.Translate(err, op); translatedErr != nil {
+			return translatedErr
+		}
+		return fmt.Errorf("%s: failed to update kind: %w", op, err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get affected rows: %w", op, err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// documentTable returns the table holding documents of docType
+func (r *Repo) documentTable(ctx context.Context, docType string) (string, error) {
+	var table string
+	err := r.db.QueryRowContext(ctx, `SELECT table_name FROM document_kinds WHERE code = $1`, docType).Scan(&table)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("%q: %w", docType, storage.ErrUnknownDocumentKind)
+		}
+		return "", err
+	}
+	return table, nil
+}
@@ -0,0 +1,171 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	document_template "srmt-admin/internal/lib/model/document-template"
+	"srmt-admin/internal/lib/model/user"
+	"srmt-admin/internal/storage"
+
+	"github.com/lib/pq"
+)
+
+const selectDocumentTemplateFields = `
+	SELECT t.id, t.document_kind, t.name, t.description, t.file_id, t.placeholders, t.is_active,
+		t.created_by_user_id, COALESCE(c.fio, ''), t.created_at, t.updated_at,
+		f.file_name, f.object_key, f.category_id, COALESCE(f.mime_type, ''), COALESCE(f.size_bytes, 0), f.created_at
+	FROM document_templates t
+	JOIN files f ON f.id = t.file_id
+	LEFT JOIN users u ON u.id = t.created_by_user_id
+	LEFT JOIN contacts c ON c.id = u.contact_id`
+
+func scanDocumentTemplate(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*document_template.Model, error) {
+	var t document_template.Model
+	var description sql.NullString
+	var createdByID sql.NullInt64
+	var createdByName string
+	var updatedAt sql.NullTime
+	if err := scanner.Scan(&t.ID, &t.DocumentKind, &t.Name, &description, &t.FileID, pq.Array(&t.Placeholders), &t.IsActive,
+		&createdByID, &createdByName, &t.CreatedAt, &updatedAt,
+		&t.Source.FileName, &t.Source.ObjectKey, &t.Source.CategoryID, &t.Source.MimeType, &t.Source.SizeBytes, &t.Source.CreatedAt); err != nil {
+		return nil, err
+	}
+	t.Source.ID = t.FileID
+	if description.Valid {
+		t.Description = &description.String
+	}
+	if createdByID.Valid {
+		t.CreatedBy = &user.ShortInfo{ID: createdByID.Int64, Name: &createdByName}
+	}
+	if updatedAt.Valid {
+		t.UpdatedAt = &updatedAt.Time
+	}
+	if t.Placeholders == nil {
+		t.Placeholders = []string{}
+	}
+	return &t, nil
+}
+
+// GetDocumentTemplates lists templates, optionally of one kind and only the
+// active ones
+func (r *Repo) GetDocumentTemplates(ctx context.Context, kind *string, activeOnly bool) ([]document_template.Model, error) {
+	const op = "storage.repo.GetDocumentTemplates"
+
+	query := selectDocumentTemplateFields
+	var conditions []string
+	var args []interface{}
+	if kind != nil {
+		args = append(args, *kind)
+		conditions = append(conditions, fmt.Sprintf("t.document_kind = $%d", len(args)))
+	}
+	if activeOnly {
+		conditions = append(conditions, "t.is_active")
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY t.document_kind, t.name"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query templates: %w", op, err)
+	}
+	defer rows.Close()
+
+	templates := make([]document_template.Model, 0)
+	for rows.Next() {
+		t, err := scanDocumentTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to scan template: %w", op, err)
+		}
+		templates = append(templates, *t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows iteration error: %w", op, err)
+	}
+	return templates, nil
+}
+
+// GetDocumentTemplate retrieves one template
+func (r *Repo) GetDocumentTemplate(ctx context.Context, id int64) (*document_template.Model, error) {
+	const op = "storage.repo.GetDocumentTemplate"
+
+	t, err := scanDocumentTemplate(r.db.QueryRowContext(ctx, selectDocumentTemplateFields+` WHERE t.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return t, nil
+}
+
+// AddDocumentTemplate registers an uploaded template file. A second template
+// with the same name for the kind is storage.ErrDuplicate.
+func (r *Repo) AddDocumentTemplate(ctx context.Context, req document_template.AddRequest, userID int64) (int64, error) {
+	const op = "storage.repo.AddDocumentTemplate"
+
+	const query = `
+		INSERT INTO document_templates (document_kind, name, description, file_id, placeholders, created_by_user_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, query,
+		req.DocumentKind, req.Name, req.Description, req.FileID, pq.Array(req.Placeholders), userID,
+	).Scan(&id)
+	if err != nil {
+		return 0, r.translator.Translate(err, op)
+	}
+	return id, nil
+}
+
+// EditDocumentTemplate updates a template's name, description or activity
+func (r *Repo) EditDocumentTemplate(ctx context.Context, id int64, req document_template.EditRequest) error {
+	const op = "storage.repo.EditDocumentTemplate"
+
+	var updates []string
+	var args []interface{}
+	argID := 1
+
+	if req.Name != nil {
+		updates = append(updates, fmt.Sprintf("name = $%d", argID))
+		args = append(args, *req.Name)
+		argID++
+	}
+	if req.Description != nil {
+		updates = append(updates, fmt.Sprintf("description = $%d", argID))
+		args = append(args, *req.Description)
+		argID++
+	}
+	if req.IsActive != nil {
+		updates = append(updates, fmt.Sprintf("is_active = $%d", argID))
+		args = append(args, *req.IsActive)
+		argID++
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf("UPDATE document_templates SET %s WHERE id = $%d", strings.Join(updates, ", "), argID)
+	args = append(args, id)
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return r.translator.Translate(err, op)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get affected rows: %w", op, err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	document_status "srmt-admin/internal/lib/model/document-status"
+	"srmt-admin/internal/storage"
+
+	"github.com/lib/pq"
+)
+
+// GetDocumentTransitions returns the status graph of a document type, or of
+// all types when docType is empty.
+func (r *Repo) GetDocumentTransitions(ctx context.Context, docType string) ([]document_status.Transition, error) {
+	const op = "storage.repo.GetDocumentTransitions"
+
+	const query = `
+		SELECT t.id, t.document_type,
+			   fs.id, fs.code, fs.name,
+			   ts.id, ts.code, ts.name,
+			   t.allowed_roles, t.require_comment, t.require_attachments
+		FROM document_status_transitions t
+		LEFT JOIN document_status fs ON t.from_status_id = fs.id
+		INNER JOIN document_status ts ON t.to_status_id = ts.id
+		WHERE $1 = '' OR t.document_type = $1
+		ORDER BY t.document_type, fs.display_order NULLS FIRST, ts.display_order`
+
+	rows, err := r.db.QueryContext(ctx, query, docType)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query transitions: %w", op, err)
+	}
+	defer rows.Close()
+
+	transitions := make([]document_status.Transition, 0)
+	for rows.Next() {
+		var t document_status.Transition
+		var fromID sql.NullInt64
+		var fromCode, fromName sql.NullString
+		var roles pq.StringArray
+		if err := rows.Scan(&t.ID, &t.DocumentType,
+			&fromID, &fromCode, &fromName,
+			&t.ToStatus.ID, &t.ToStatus.Code, &t.ToStatus.Name,
+			&roles, &t.RequireComment, &t.RequireAttachments,
+		); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan transition: %w", op, err)
+		}
+		if fromID.Valid {
+			t.FromStatus = &document_status.ShortModel{ID: int(fromID.Int64), Code: fromCode.String, Name: fromName.String}
+		}
+		t.AllowedRoles = []string(roles)
+		if t.AllowedRoles == nil {
+			t.AllowedRoles = []string{}
+		}
+		transitions = append(transitions, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows iteration error: %w", op, err)
+	}
+	return transitions, nil
+}
+
+// ReplaceDocumentTransitions swaps the whole status graph of a document type
+// in one transaction.
+func (r *Repo) ReplaceDocumentTransitions(ctx context.Context, docType string, edges []document_status.TransitionInput) error {
+	const op = "storage.repo.ReplaceDocumentTransitions"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM document_status_transitions WHERE document_type = $1", docType); err != nil {
+		return fmt.Errorf("%s: failed to delete transitions: %w", op, err)
+	}
+
+	const insert = `
+		INSERT INTO document_status_transitions
+			(document_type, from_status_id, to_status_id, allowed_roles, require_comment, require_attachments)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	for _, e := range edges {
+		roles := e.AllowedRoles
+		if roles == nil {
+			roles = []string{}
+		}
+		if _, err := tx.ExecContext(ctx, insert, docType, e.FromStatusID, e.ToStatusID,
+			pq.Array(roles), e.RequireComment, e.RequireAttachments); err != nil {
+			if translatedErr := r.translator.Translate(err, op); translatedErr != nil {
+				return translatedErr
+			}
+			return fmt.Errorf("%s: failed to insert transition: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: failed to commit: %w", op, err)
+	}
+	return nil
+}
+
+// GetDocumentStatusID returns the current status of a document.
+func (r *Repo) GetDocumentStatusID(ctx context.Context, docType string, docID int64) (int, error) {
+	const op = "storage.repo.GetDocumentStatusID"
+
+	tableName, err := r.documentTable(ctx, docType)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := fmt.Sprintf("SELECT status_id FROM %s WHERE id = $1", tableName)
+
+	var statusID int
+	if err := r.db.QueryRowContext(ctx, query, docID).Scan(&statusID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: document not found: %w", op, storage.ErrNotFound)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return statusID, nil
+}
+
+// CountDocumentFiles returns the number of files attached to a document.
+func (r *Repo) CountDocumentFiles(ctx context.Context, docType string, docID int64) (int, error) {
+	const op = "storage.repo.CountDocumentFiles"
+
+	kind, err := r.GetDocumentKind(ctx, docType)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = $1", kind.FileLinksTable(), kind.ForeignKey())
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, docID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return count, nil
+}
+
+// ChangeDocumentStatus moves a document from fromStatusID to toStatusID and
+// stores the comment on the history row written by the status trigger. The
+// update is conditional on the current status, so a concurrent change yields
+// storage.ErrInvalidStatus instead of skipping the transition check.
+func (r *Repo) ChangeDocumentStatus(ctx context.Context, docType string, docID int64, fromStatusID, toStatusID int, userID int64, comment *string) error {
+	const op = "storage.repo.ChangeDocumentStatus"
+
+	kind, err := r.GetDocumentKind(ctx, docType)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	updateQuery := fmt.Sprintf(
+		"UPDATE %s SET status_id = $1, updated_by_user_id = $2 WHERE id = $3 AND status_id = $4",
+		kind.TableName)
+
+	res, err := tx.ExecContext(ctx, updateQuery, toStatusID, userID, docID, fromStatusID)
+	if err != nil {
+		if translatedErr := r.translator.Translate(err, op); translatedErr != nil {
+			return translatedErr
+		}
+		return fmt.Errorf("%s: failed to update status: %w", op, err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get affected rows: %w", op, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: status changed concurrently: %w", op, storage.ErrInvalidStatus)
+	}
+
+	if comment != nil && *comment != "" {
+		commentQuery := fmt.Sprintf(`
+			UPDATE %[1]s
+			SET comment = $2
+			WHERE id = (
+				SELECT id FROM %[1]s
+				WHERE %[2]s = $1
+				ORDER BY changed_at DESC, id DESC
+				LIMIT 1
+			)`, kind.HistoryTable(), kind.ForeignKey())
+		if _, err := tx.ExecContext(ctx, commentQuery, docID, *comment); err != nil {
+			return fmt.Errorf("%s: failed to add comment: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: failed to commit: %w", op, err)
+	}
+	return nil
+}
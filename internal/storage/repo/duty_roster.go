@@ -0,0 +1,728 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	rostermodel "srmt-admin/internal/lib/model/duty-roster"
+	"srmt-admin/internal/storage"
+)
+
+// --- Shift patterns ---
+
+// CreateShiftPattern inserts a pattern and its members in one transaction.
+// A second pattern with the same name in the organization is
+// storage.ErrShiftPatternExists.
+func (r *Repo) CreateShiftPattern(ctx context.Context, req rostermodel.PatternRequest, createdByUserID int64) (int64, error) {
+	const op = "storage.repo.CreateShiftPattern"
+
+	shifts, err := json.Marshal(req.Shifts)
+	if err != nil {
+		return 0, fmt.Errorf("%s: marshal shifts: %w", op, err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO shift_patterns (organization_id, name, shifts, cycle, anchor_date, created_by_user_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		req.OrganizationID, req.Name, shifts, pq.Array(req.Cycle), req.AnchorDate, createdByUserID,
+	).Scan(&id)
+	if err != nil {
+		return 0, r.translateShiftPatternErr(err, op)
+	}
+
+	if err := replaceShiftPatternMembers(ctx, tx, id, req.Members); err != nil {
+		return 0, r.translateShiftPatternErr(err, op)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return id, nil
+}
+
+// UpdateShiftPattern overwrites the pattern definition and replaces its
+// member list. Already generated shifts are not touched; the next
+// generation run picks up the change.
+func (r *Repo) UpdateShiftPattern(ctx context.Context, id int64, req rostermodel.PatternRequest) error {
+	const op = "storage.repo.UpdateShiftPattern"
+
+	shifts, err := json.Marshal(req.Shifts)
+	if err != nil {
+		return fmt.Errorf("%s: marshal shifts: %w", op, err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE shift_patterns
+		SET name        = $1,
+		    shifts      = $2,
+		    cycle       = $3,
+		    anchor_date = $4
+		WHERE id = $5`,
+		req.Name, shifts, pq.Array(req.Cycle), req.AnchorDate, id,
+	)
+	if err != nil {
+		return r.translateShiftPatternErr(err, op)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return storage.ErrShiftPatternNotFound
+	}
+
+	if err := replaceShiftPatternMembers(ctx, tx, id, req.Members); err != nil {
+		return r.translateShiftPatternErr(err, op)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return nil
+}
+
+// DeleteShiftPattern removes a pattern. Its shifts stay in the roster
+// (pattern_id is set to NULL) so past duty history is not lost.
+func (r *Repo) DeleteShiftPattern(ctx context.Context, id int64) error {
+	const op = "storage.repo.DeleteShiftPattern"
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM shift_patterns WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return storage.ErrShiftPatternNotFound
+	}
+	return nil
+}
+
+// GetShiftPatterns lists patterns, optionally of one organization, with
+// their members.
+func (r *Repo) GetShiftPatterns(ctx context.Context, orgID *int64) ([]rostermodel.Pattern, error) {
+	const op = "storage.repo.GetShiftPatterns"
+
+	query := selectShiftPatternFields + `
+		WHERE ($1::bigint IS NULL OR p.organization_id = $1)
+		ORDER BY organization_name, p.name`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	patterns := make([]rostermodel.Pattern, 0)
+	for rows.Next() {
+		p, err := scanShiftPattern(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		patterns = append(patterns, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	ids := make([]int64, len(patterns))
+	for i := range patterns {
+		ids[i] = patterns[i].ID
+	}
+	members, err := r.loadShiftPatternMembers(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for i := range patterns {
+		if m, ok := members[patterns[i].ID]; ok {
+			patterns[i].Members = m
+		}
+	}
+	return patterns, nil
+}
+
+// GetShiftPatternByID returns one pattern with its members, or
+// storage.ErrShiftPatternNotFound.
+func (r *Repo) GetShiftPatternByID(ctx context.Context, id int64) (*rostermodel.Pattern, error) {
+	const op = "storage.repo.GetShiftPatternByID"
+
+	p, err := scanShiftPattern(r.db.QueryRowContext(ctx, selectShiftPatternFields+" WHERE p.id = $1", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrShiftPatternNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	members, err := r.loadShiftPatternMembers(ctx, []int64{id})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if m, ok := members[id]; ok {
+		p.Members = m
+	}
+	return p, nil
+}
+
+// --- Roster ---
+
+// ReplaceGeneratedShifts regenerates one pattern's month in a transaction.
+// Generated shifts in [from, to) are deleted unless a swap request is open
+// on them, then the new shifts are inserted. Swapped shifts keep their
+// rostered_employee_id, so the slot index skips regenerating them.
+func (r *Repo) ReplaceGeneratedShifts(ctx context.Context, patternID int64, from, to time.Time, shifts []rostermodel.Shift, createdByUserID int64) (*rostermodel.GenerateResult, error) {
+	const op = "storage.repo.ReplaceGeneratedShifts"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		DELETE FROM duty_shifts s
+		WHERE s.pattern_id = $1
+		  AND s.source = 'generated'
+		  AND s.starts_at >= $2 AND s.starts_at < $3
+		  AND NOT EXISTS (
+		      SELECT 1 FROM duty_shift_swaps w
+		      WHERE w.shift_id = s.id AND w.status = 'pending'
+		  )`,
+		patternID, from, to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: delete: %w", op, err)
+	}
+	removed, _ := res.RowsAffected()
+
+	result := &rostermodel.GenerateResult{PatternID: patternID, Removed: int(removed)}
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM duty_shifts
+		WHERE pattern_id = $1 AND starts_at >= $2 AND starts_at < $3`,
+		patternID, from, to,
+	).Scan(&result.Kept); err != nil {
+		return nil, fmt.Errorf("%s: count kept: %w", op, err)
+	}
+
+	if len(shifts) > 0 {
+		var (
+			orgIDs, employeeIDs []int64
+			codes               []string
+			starts, ends        []time.Time
+		)
+		for _, s := range shifts {
+			orgIDs = append(orgIDs, s.OrganizationID)
+			employeeIDs = append(employeeIDs, s.EmployeeID)
+			codes = append(codes, s.ShiftCode)
+			starts = append(starts, s.StartsAt)
+			ends = append(ends, s.EndsAt)
+		}
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO duty_shifts
+			    (organization_id, pattern_id, employee_id, rostered_employee_id,
+			     shift_code, starts_at, ends_at, source, created_by_user_id)
+			SELECT t.org_id, $1, t.employee_id, t.employee_id,
+			       t.code, t.starts_at, t.ends_at, 'generated', $7
+			FROM unnest($2::bigint[], $3::bigint[], $4::text[], $5::timestamptz[], $6::timestamptz[])
+			     AS t(org_id, employee_id, code, starts_at, ends_at)
+			ON CONFLICT (pattern_id, rostered_employee_id, shift_code, starts_at)
+			    WHERE pattern_id IS NOT NULL
+			DO NOTHING`,
+			patternID, pq.Array(orgIDs), pq.Array(employeeIDs), pq.Array(codes),
+			pq.Array(starts), pq.Array(ends), createdByUserID,
+		)
+		if err != nil {
+			if translated := r.translator.Translate(err, op); translated != nil {
+				return nil, translated
+			}
+			return nil, fmt.Errorf("%s: insert: %w", op, err)
+		}
+		created, _ := res.RowsAffected()
+		result.Created = int(created)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return result, nil
+}
+
+// GetRoster returns an organization's shifts starting in [f.From, f.To),
+// ordered by start time.
+func (r *Repo) GetRoster(ctx context.Context, f rostermodel.RosterFilter) ([]rostermodel.Shift, error) {
+	const op = "storage.repo.GetRoster"
+
+	query := selectDutyShiftFields + `
+		WHERE s.organization_id = $1
+		  AND s.starts_at >= $2 AND s.starts_at < $3
+		  AND ($4::bigint IS NULL OR s.employee_id = $4)
+		ORDER BY s.starts_at, s.shift_code, employee_name`
+
+	return r.queryDutyShifts(ctx, op, query, f.OrganizationID, f.From, f.To, f.EmployeeID)
+}
+
+// GetEmployeeShifts returns one employee's shifts across organizations in
+// the half-open window [from, to).
+func (r *Repo) GetEmployeeShifts(ctx context.Context, employeeID int64, from, to time.Time) ([]rostermodel.Shift, error) {
+	const op = "storage.repo.GetEmployeeShifts"
+
+	query := selectDutyShiftFields + `
+		WHERE s.employee_id = $1
+		  AND s.starts_at >= $2 AND s.starts_at < $3
+		ORDER BY s.starts_at`
+
+	return r.queryDutyShifts(ctx, op, query, employeeID, from, to)
+}
+
+// GetOnDutyShifts returns the shifts covering the instant at, for the
+// given organizations. A shift covers [starts_at, ends_at).
+func (r *Repo) GetOnDutyShifts(ctx context.Context, orgIDs []int64, at time.Time) ([]rostermodel.Shift, error) {
+	const op = "storage.repo.GetOnDutyShifts"
+
+	if len(orgIDs) == 0 {
+		return nil, nil
+	}
+
+	query := selectDutyShiftFields + `
+		WHERE s.organization_id = ANY($1)
+		  AND s.starts_at <= $2 AND s.ends_at > $2
+		ORDER BY s.organization_id, s.starts_at, employee_name`
+
+	return r.queryDutyShifts(ctx, op, query, pq.Array(orgIDs), at)
+}
+
+// GetShiftByID returns one shift or storage.ErrShiftNotFound.
+func (r *Repo) GetShiftByID(ctx context.Context, id int64) (*rostermodel.Shift, error) {
+	const op = "storage.repo.GetShiftByID"
+
+	s, err := scanDutyShift(r.db.QueryRowContext(ctx, selectDutyShiftFields+" WHERE s.id = $1", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrShiftNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return s, nil
+}
+
+// CreateShift adds a manual shift. An employee cannot stand two
+// overlapping shifts: that is storage.ErrShiftOverlap.
+func (r *Repo) CreateShift(ctx context.Context, req rostermodel.CreateShiftRequest, createdByUserID int64) (int64, error) {
+	const op = "storage.repo.CreateShift"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if err := checkShiftOverlap(ctx, tx, req.EmployeeID, req.StartsAt, req.EndsAt, 0); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO duty_shifts
+		    (organization_id, employee_id, shift_code, starts_at, ends_at, source, created_by_user_id)
+		VALUES ($1, $2, $3, $4, $5, 'manual', $6)
+		RETURNING id`,
+		req.OrganizationID, req.EmployeeID, req.ShiftCode, req.StartsAt, req.EndsAt, createdByUserID,
+	).Scan(&id)
+	if err != nil {
+		if translated := r.translator.Translate(err, op); translated != nil {
+			return 0, translated
+		}
+		return 0, fmt.Errorf("%s: insert: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return id, nil
+}
+
+// DeleteShift removes a shift together with its swap requests.
+func (r *Repo) DeleteShift(ctx context.Context, id int64) error {
+	const op = "storage.repo.DeleteShift"
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM duty_shifts WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return storage.ErrShiftNotFound
+	}
+	return nil
+}
+
+// --- Swaps ---
+
+// CreateShiftSwap opens a swap request for a shift, recording who stands
+// it now. A shift with an open request is storage.ErrSwapPending.
+func (r *Repo) CreateShiftSwap(ctx context.Context, shiftID int64, req rostermodel.SwapRequest, requestedByUserID int64) (int64, error) {
+	const op = "storage.repo.CreateShiftSwap"
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO duty_shift_swaps
+		    (shift_id, original_employee_id, replacement_id, reason, requested_by_user_id)
+		SELECT s.id, s.employee_id, $2, $3, $4
+		FROM duty_shifts s
+		WHERE s.id = $1
+		RETURNING id`,
+		shiftID, req.ReplacementID, req.Reason, requestedByUserID,
+	).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, storage.ErrShiftNotFound
+		}
+		if translated := r.translator.Translate(err, op); translated != nil {
+			if errors.Is(translated, storage.ErrDuplicate) {
+				return 0, storage.ErrSwapPending
+			}
+			return 0, translated
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+// GetShiftSwaps lists swap requests, newest first.
+func (r *Repo) GetShiftSwaps(ctx context.Context, f rostermodel.SwapFilter) ([]rostermodel.Swap, error) {
+	const op = "storage.repo.GetShiftSwaps"
+
+	query := selectShiftSwapFields + `
+		WHERE ($1::bigint IS NULL OR s.organization_id = $1)
+		  AND ($2::text IS NULL OR w.status = $2)
+		ORDER BY w.created_at DESC, w.id DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, f.OrganizationID, f.Status)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	swaps := make([]rostermodel.Swap, 0)
+	for rows.Next() {
+		w, err := scanShiftSwap(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		swaps = append(swaps, *w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+	return swaps, nil
+}
+
+// GetShiftSwapByID returns one swap request or storage.ErrSwapNotFound.
+func (r *Repo) GetShiftSwapByID(ctx context.Context, id int64) (*rostermodel.Swap, error) {
+	const op = "storage.repo.GetShiftSwapByID"
+
+	w, err := scanShiftSwap(r.db.QueryRowContext(ctx, selectShiftSwapFields+" WHERE w.id = $1", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrSwapNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return w, nil
+}
+
+// DecideShiftSwap approves or rejects a pending swap. Approval hands the
+// shift over to the replacement in the same transaction; it fails with
+// storage.ErrShiftOverlap when the replacement is on another shift at the
+// time, and with storage.ErrInvalidStatus when the request is no longer
+// pending or the shift changed hands since it was filed.
+func (r *Repo) DecideShiftSwap(ctx context.Context, id int64, approve bool, decidedByUserID int64) error {
+	const op = "storage.repo.DecideShiftSwap"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var (
+		shiftID, originalID, replacementID, currentID int64
+		status                                        string
+		startsAt, endsAt                              time.Time
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT w.shift_id, w.original_employee_id, w.replacement_id, w.status,
+		       s.employee_id, s.starts_at, s.ends_at
+		FROM duty_shift_swaps w
+		JOIN duty_shifts s ON s.id = w.shift_id
+		WHERE w.id = $1
+		FOR UPDATE OF w, s`, id,
+	).Scan(&shiftID, &originalID, &replacementID, &status, &currentID, &startsAt, &endsAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrSwapNotFound
+		}
+		return fmt.Errorf("%s: lock: %w", op, err)
+	}
+	if status != rostermodel.SwapPending {
+		return storage.ErrInvalidStatus
+	}
+
+	newStatus := rostermodel.SwapRejected
+	if approve {
+		if currentID != originalID {
+			return storage.ErrInvalidStatus
+		}
+		if err := checkShiftOverlap(ctx, tx, replacementID, startsAt, endsAt, shiftID); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE duty_shifts SET employee_id = $1, source = 'swap' WHERE id = $2`,
+			replacementID, shiftID,
+		); err != nil {
+			return fmt.Errorf("%s: reassign shift: %w", op, err)
+		}
+		newStatus = rostermodel.SwapApproved
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE duty_shift_swaps
+		SET status = $1, decided_by_user_id = $2, decided_at = NOW()
+		WHERE id = $3`,
+		newStatus, decidedByUserID, id,
+	); err != nil {
+		return fmt.Errorf("%s: update swap: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return nil
+}
+
+// --- Internal helpers ---
+
+const selectShiftPatternFields = `
+	SELECT p.id, p.organization_id, COALESCE(o.name, '') AS organization_name,
+	       p.name, p.shifts, p.cycle, p.anchor_date::text,
+	       p.created_by_user_id, p.created_at, p.updated_at
+	FROM shift_patterns p
+	LEFT JOIN organizations o ON o.id = p.organization_id`
+
+const selectDutyShiftFields = `
+	SELECT s.id, s.organization_id, COALESCE(o.name, '') AS organization_name,
+	       s.pattern_id, s.employee_id, COALESCE(c.fio, '') AS employee_name,
+	       s.rostered_employee_id, s.shift_code, s.starts_at, s.ends_at, s.source,
+	       s.created_by_user_id, s.created_at, s.updated_at
+	FROM duty_shifts s
+	LEFT JOIN organizations o ON o.id = s.organization_id
+	LEFT JOIN contacts c ON c.id = s.employee_id`
+
+const selectShiftSwapFields = `
+	SELECT w.id, w.shift_id, s.organization_id, s.shift_code, s.starts_at, s.ends_at,
+	       w.original_employee_id, COALESCE(oc.fio, ''),
+	       w.replacement_id, COALESCE(rc.fio, ''),
+	       w.reason, w.status, w.requested_by_user_id, w.decided_by_user_id,
+	       w.decided_at, w.created_at
+	FROM duty_shift_swaps w
+	JOIN duty_shifts s ON s.id = w.shift_id
+	LEFT JOIN contacts oc ON oc.id = w.original_employee_id
+	LEFT JOIN contacts rc ON rc.id = w.replacement_id`
+
+// translateShiftPatternErr maps a write error on shift_patterns or its
+// members to the domain error the handlers understand.
+func (r *Repo) translateShiftPatternErr(err error, op string) error {
+	if translated := r.translator.Translate(err, op); translated != nil {
+		if errors.Is(translated, storage.ErrDuplicate) {
+			return storage.ErrShiftPatternExists
+		}
+		return translated
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+func replaceShiftPatternMembers(ctx context.Context, tx *sql.Tx, patternID int64, members []rostermodel.Member) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM shift_pattern_members WHERE pattern_id = $1", patternID); err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return nil
+	}
+
+	employeeIDs := make([]int64, len(members))
+	offsets := make([]int64, len(members))
+	for i, m := range members {
+		employeeIDs[i] = m.EmployeeID
+		offsets[i] = int64(m.OffsetDays)
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO shift_pattern_members (pattern_id, employee_id, offset_days)
+		SELECT $1, t.employee_id, t.offset_days
+		FROM unnest($2::bigint[], $3::int[]) AS t(employee_id, offset_days)
+		ON CONFLICT (pattern_id, employee_id) DO UPDATE SET offset_days = EXCLUDED.offset_days`,
+		patternID, pq.Array(employeeIDs), pq.Array(offsets),
+	)
+	return err
+}
+
+func (r *Repo) loadShiftPatternMembers(ctx context.Context, patternIDs []int64) (map[int64][]rostermodel.Member, error) {
+	out := make(map[int64][]rostermodel.Member)
+	if len(patternIDs) == 0 {
+		return out, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT m.pattern_id, m.employee_id, COALESCE(c.fio, ''), m.offset_days
+		FROM shift_pattern_members m
+		LEFT JOIN contacts c ON c.id = m.employee_id
+		WHERE m.pattern_id = ANY($1)
+		ORDER BY m.pattern_id, m.offset_days, c.fio`, pq.Array(patternIDs))
+	if err != nil {
+		return nil, fmt.Errorf("load members: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var patternID int64
+		var m rostermodel.Member
+		if err := rows.Scan(&patternID, &m.EmployeeID, &m.EmployeeName, &m.OffsetDays); err != nil {
+			return nil, fmt.Errorf("scan member: %w", err)
+		}
+		out[patternID] = append(out[patternID], m)
+	}
+	return out, rows.Err()
+}
+
+// checkShiftOverlap fails with storage.ErrShiftOverlap when the employee
+// already stands a shift intersecting [startsAt, endsAt). exceptShiftID
+// excludes the shift being reassigned.
+func checkShiftOverlap(ctx context.Context, tx *sql.Tx, employeeID int64, startsAt, endsAt time.Time, exceptShiftID int64) error {
+	var overlaps bool
+	if err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+		    SELECT 1 FROM duty_shifts
+		    WHERE employee_id = $1
+		      AND starts_at < $3 AND ends_at > $2
+		      AND id <> $4
+		)`, employeeID, startsAt, endsAt, exceptShiftID,
+	).Scan(&overlaps); err != nil {
+		return fmt.Errorf("check overlap: %w", err)
+	}
+	if overlaps {
+		return storage.ErrShiftOverlap
+	}
+	return nil
+}
+
+func (r *Repo) queryDutyShifts(ctx context.Context, op, query string, args ...any) ([]rostermodel.Shift, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	shifts := make([]rostermodel.Shift, 0)
+	for rows.Next() {
+		s, err := scanDutyShift(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		shifts = append(shifts, *s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+	return shifts, nil
+}
+
+func scanShiftPattern(scanner interface {
+	Scan(dest ...any) error
+}) (*rostermodel.Pattern, error) {
+	var p rostermodel.Pattern
+	var shifts []byte
+	var createdBy sql.NullInt64
+	if err := scanner.Scan(
+		&p.ID, &p.OrganizationID, &p.OrganizationName,
+		&p.Name, &shifts, pq.Array(&p.Cycle), &p.AnchorDate,
+		&createdBy, &p.CreatedAt, &p.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(shifts, &p.Shifts); err != nil {
+		return nil, fmt.Errorf("unmarshal shifts: %w", err)
+	}
+	if createdBy.Valid {
+		p.CreatedByUserID = &createdBy.Int64
+	}
+	p.Members = []rostermodel.Member{}
+	return &p, nil
+}
+
+func scanDutyShift(scanner interface {
+	Scan(dest ...any) error
+}) (*rostermodel.Shift, error) {
+	var s rostermodel.Shift
+	var patternID, rostered, createdBy sql.NullInt64
+	if err := scanner.Scan(
+		&s.ID, &s.OrganizationID, &s.OrganizationName,
+		&patternID, &s.EmployeeID, &s.EmployeeName,
+		&rostered, &s.ShiftCode, &s.StartsAt, &s.EndsAt, &s.Source,
+		&createdBy, &s.CreatedAt, &s.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if patternID.Valid {
+		s.PatternID = &patternID.Int64
+	}
+	if rostered.Valid {
+		s.RosteredEmployeeID = &rostered.Int64
+	}
+	if createdBy.Valid {
+		s.CreatedByUserID = &createdBy.Int64
+	}
+	return &s, nil
+}
+
+func scanShiftSwap(scanner interface {
+	Scan(dest ...any) error
+}) (*rostermodel.Swap, error) {
+	var w rostermodel.Swap
+	var reason sql.NullString
+	var requestedBy, decidedBy sql.NullInt64
+	var decidedAt sql.NullTime
+	if err := scanner.Scan(
+		&w.ID, &w.ShiftID, &w.OrganizationID, &w.ShiftCode, &w.StartsAt, &w.EndsAt,
+		&w.OriginalEmployeeID, &w.OriginalEmployeeName,
+		&w.ReplacementID, &w.ReplacementName,
+		&reason, &w.Status, &requestedBy, &decidedBy,
+		&decidedAt, &w.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if reason.Valid {
+		w.Reason = &reason.String
+	}
+	if requestedBy.Valid {
+		w.RequestedByUserID = &requestedBy.Int64
+	}
+	if decidedBy.Valid {
+		w.DecidedByUserID = &decidedBy.Int64
+	}
+	if decidedAt.Valid {
+		w.DecidedAt = &decidedAt.Time
+	}
+	return &w, nil
+}
@@ -32,11 +32,11 @@ func (r *Repo) AddDutyViolationWithFiles(ctx context.Context, req dvmodel.Create
 	var id int64
 	err = tx.QueryRowContext(ctx, `
 		INSERT INTO duty_violations
-		    (organization_id, start_time, end_time, duty_officer_name, reason, created_by_user_id)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		    (organization_id, start_time, end_time, duty_officer_id, duty_officer_name, reason, created_by_user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id`,
 		req.OrganizationID, req.StartTime, req.EndTime,
-		req.DutyOfficerName, req.Reason, createdByUserID,
+		req.DutyOfficerID, req.DutyOfficerName, req.Reason, createdByUserID,
 	).Scan(&id)
 	if err != nil {
 		if translatedErr := r.translator.Translate(err, op); translatedErr != nil {
@@ -165,11 +165,12 @@ func (r *Repo) UpdateDutyViolationWithFiles(ctx context.Context, id int64, req d
 		SET organization_id   = $1,
 		    start_time        = $2,
 		    end_time          = $3,
-		    duty_officer_name = $4,
-		    reason            = $5
-		WHERE id = $6`,
+		    duty_officer_id   = $4,
+		    duty_officer_name = $5,
+		    reason            = $6
+		WHERE id = $7`,
 		req.OrganizationID, req.StartTime, req.EndTime,
-		req.DutyOfficerName, req.Reason, id,
+		req.DutyOfficerID, req.DutyOfficerName, req.Reason, id,
 	)
 	if err != nil {
 		if translatedErr := r.translator.Translate(err, op); translatedErr != nil {
@@ -270,6 +271,7 @@ const selectDutyViolationFields = `
 	    COALESCE(o.name, '') AS organization_name,
 	    dv.start_time,
 	    dv.end_time,
+	    dv.duty_officer_id,
 	    dv.duty_officer_name,
 	    dv.reason,
 	    dv.created_at,
@@ -334,13 +336,14 @@ func scanDutyViolationRow(scanner interface {
 }) (*dvmodel.DutyViolation, error) {
 	var dv dvmodel.DutyViolation
 	var endTime sql.NullTime
-	var createdBy sql.NullInt64
+	var officerID, createdBy sql.NullInt64
 	if err := scanner.Scan(
 		&dv.ID,
 		&dv.OrganizationID,
 		&dv.OrganizationName,
 		&dv.StartTime,
 		&endTime,
+		&officerID,
 		&dv.DutyOfficerName,
 		&dv.Reason,
 		&dv.CreatedAt,
@@ -352,6 +355,9 @@ func scanDutyViolationRow(scanner interface {
 	if endTime.Valid {
 		dv.EndTime = &endTime.Time
 	}
+	if officerID.Valid {
+		dv.DutyOfficerID = &officerID.Int64
+	}
 	if createdBy.Valid {
 		dv.CreatedByUserID = &createdBy.Int64
 	}
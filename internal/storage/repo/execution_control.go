@@ -0,0 +1,339 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	execcontrol "srmt-admin/internal/lib/model/execution-control"
+	"srmt-admin/internal/lib/model/file"
+	"srmt-admin/internal/lib/model/user"
+	"srmt-admin/internal/storage"
+
+	"github.com/lib/pq"
+)
+
+const selectExecutionControlBase = `
+	SELECT c.id, c.document_type, c.document_id,
+		   COALESCE(d.name, ''), d.number,
+		   c.executor_contact_id, ec.fio, ec.department_id, dep.name,
+		   c.controller_user_id, cc.fio, cu.contact_id,
+		   c.resolution_text, c.due_date, c.status, c.accepted_at, c.created_at
+	FROM document_execution_controls c
+	LEFT JOIN registry_documents d ON d.document_type = c.document_type AND d.id = c.document_id
+	JOIN contacts ec ON c.executor_contact_id = ec.id
+	LEFT JOIN departments dep ON ec.department_id = dep.id
+	LEFT JOIN users cu ON c.controller_user_id = cu.id
+	LEFT JOIN contacts cc ON cu.contact_id = cc.id
+`
+
+func scanExecutionControl(s interface{ Scan(...any) error }) (*execcontrol.Control, error) {
+	var c execcontrol.Control
+	var departmentID, controllerID, controllerContactID sql.NullInt64
+	var departmentName, controllerName sql.NullString
+	var dueDate, acceptedAt sql.NullTime
+
+	if err := s.Scan(
+		&c.ID, &c.DocumentType, &c.DocumentID, &c.DocumentName, &c.DocumentNumber,
+		&c.Executor.ID, &c.Executor.Name, &departmentID, &departmentName,
+		&controllerID, &controllerName, &controllerContactID,
+		&c.ResolutionText, &dueDate, &c.Status, &acceptedAt, &c.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if departmentID.Valid {
+		c.Executor.DepartmentID = &departmentID.Int64
+	}
+	c.Executor.DepartmentName = nullStringPtr(departmentName)
+	if controllerID.Valid {
+		c.Controller = &user.ShortInfo{ID: controllerID.Int64, Name: nullStringPtr(controllerName)}
+	}
+	if controllerContactID.Valid {
+		c.ControllerContactID = &controllerContactID.Int64
+	}
+	if dueDate.Valid {
+		c.DueDate = &dueDate.Time
+	}
+	if acceptedAt.Valid {
+		c.AcceptedAt = &acceptedAt.Time
+	}
+	return &c, nil
+}
+
+// upsertExecutionControl puts a signed document on execution control, or
+// reopens its control with the new executor and due date when it is signed
+// again.
+func upsertExecutionControl(ctx context.Context, tx *sql.Tx, docType string, docID, executorID int64, dueDate *time.Time, controllerID int64, resolution *string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO document_execution_controls (
+			document_type, document_id, executor_contact_id, due_date, controller_user_id, resolution_text
+		)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (document_type, document_id) DO UPDATE SET
+			executor_contact_id = EXCLUDED.executor_contact_id,
+			due_date = EXCLUDED.due_date,
+			controller_user_id = EXCLUDED.controller_user_id,
+			resolution_text = EXCLUDED.resolution_text,
+			status = 'on_control',
+			accepted_at = NULL`,
+		docType, docID, executorID, dueDate, controllerID, resolution)
+	if err != nil {
+		return fmt.Errorf("upsert execution control: %w", err)
+	}
+	return nil
+}
+
+// GetExecutionControls returns control items matching the filter, earliest
+// due date first; items without a due date come last.
+func (r *Repo) GetExecutionControls(ctx context.Context, f execcontrol.Filter) ([]execcontrol.Control, error) {
+	const op = "storage.repo.GetExecutionControls"
+
+	var where []string
+	var args []any
+	add := func(cond string, v any) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if f.Status != nil {
+		add("c.status = $%d", *f.Status)
+	}
+	if f.DocumentType != nil {
+		add("c.document_type = $%d", *f.DocumentType)
+	}
+	if f.ExecutorContactID != nil {
+		add("c.executor_contact_id = $%d", *f.ExecutorContactID)
+	}
+	if f.DepartmentID != nil {
+		add("ec.department_id = $%d", *f.DepartmentID)
+	}
+	if f.DueBefore != nil {
+		add("c.due_date <= $%d", *f.DueBefore)
+	}
+	if f.OpenOnly {
+		where = append(where, "c.status <> 'accepted'")
+	}
+
+	query := selectExecutionControlBase
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY c.due_date ASC NULLS LAST, c.id"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query: %w", op, err)
+	}
+	defer rows.Close()
+
+	controls := make([]execcontrol.Control, 0)
+	for rows.Next() {
+		c, err := scanExecutionControl(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to scan: %w", op, err)
+		}
+		controls = append(controls, *c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+	return controls, nil
+}
+
+// GetExecutionControlByID returns a control item with its reports, oldest first.
+func (r *Repo) GetExecutionControlByID(ctx context.Context, id int64) (*execcontrol.Control, error) {
+	const op = "storage.repo.GetExecutionControlByID"
+
+	c, err := scanExecutionControl(r.db.QueryRowContext(ctx, selectExecutionControlBase+" WHERE c.id = $1", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+		return nil, fmt.Errorf("%s: failed to query control: %w", op, err)
+	}
+
+	const reportsQuery = `
+		SELECT er.id, er.kind, er.text, er.submitted_by_user_id, sc.fio, er.submitted_at,
+			   er.decision, er.decision_comment, er.decided_by_user_id, dc.fio, er.decided_at
+		FROM document_execution_reports er
+		LEFT JOIN users su ON er.submitted_by_user_id = su.id
+		LEFT JOIN contacts sc ON su.contact_id = sc.id
+		LEFT JOIN users du ON er.decided_by_user_id = du.id
+		LEFT JOIN contacts dc ON du.contact_id = dc.id
+		WHERE er.control_id = $1
+		ORDER BY er.submitted_at, er.id`
+
+	rows, err := r.db.QueryContext(ctx, reportsQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query reports: %w", op, err)
+	}
+	defer rows.Close()
+
+	c.Reports = make([]execcontrol.Report, 0)
+	index := make(map[int64]int)
+	for rows.Next() {
+		var rep execcontrol.Report
+		var submittedByID, decidedByID sql.NullInt64
+		var submittedByName, decidedByName sql.NullString
+		var decidedAt sql.NullTime
+		if err := rows.Scan(
+			&rep.ID, &rep.Kind, &rep.Text, &submittedByID, &submittedByName, &rep.SubmittedAt,
+			&rep.Decision, &rep.DecisionComment, &decidedByID, &decidedByName, &decidedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan report: %w", op, err)
+		}
+		if submittedByID.Valid {
+			rep.SubmittedBy = &user.ShortInfo{ID: submittedByID.Int64, Name: nullStringPtr(submittedByName)}
+		}
+		if decidedByID.Valid {
+			rep.DecidedBy = &user.ShortInfo{ID: decidedByID.Int64, Name: nullStringPtr(decidedByName)}
+		}
+		if decidedAt.Valid {
+			rep.DecidedAt = &decidedAt.Time
+		}
+		index[rep.ID] = len(c.Reports)
+		c.Reports = append(c.Reports, rep)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: reports rows error: %w", op, err)
+	}
+
+	if len(c.Reports) == 0 {
+		return c, nil
+	}
+
+	const filesQuery = `
+		SELECT rf.report_id, f.id, f.file_name, f.object_key, f.category_id, f.mime_type, f.size_bytes, f.created_at, f.target_date
+		FROM document_execution_report_files rf
+		JOIN files f ON f.id = rf.file_id
+		JOIN document_execution_reports er ON er.id = rf.report_id
+		WHERE er.control_id = $1
+		ORDER BY f.created_at`
+
+	fileRows, err := r.db.QueryContext(ctx, filesQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query files: %w", op, err)
+	}
+	defer fileRows.Close()
+
+	for fileRows.Next() {
+		var reportID int64
+		var f file.Model
+		if err := fileRows.Scan(&reportID, &f.ID, &f.FileName, &f.ObjectKey, &f.CategoryID, &f.MimeType, &f.SizeBytes, &f.CreatedAt, &f.TargetDate); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan file: %w", op, err)
+		}
+		if i, ok := index[reportID]; ok {
+			c.Reports[i].Files = append(c.Reports[i].Files, f)
+		}
+	}
+	if err := fileRows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: files rows error: %w", op, err)
+	}
+
+	return c, nil
+}
+
+// AddExecutionReport stores an executor report with its files and moves the
+// control item from fromStatus to toStatus. The status update is conditional,
+// so a concurrent change surfaces as storage.ErrInvalidStatus.
+func (r *Repo) AddExecutionReport(ctx context.Context, controlID int64, fromStatus, toStatus string, req execcontrol.SubmitReportRequest, userID int64) (int64, error) {
+	const op = "storage.repo.AddExecutionReport"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE document_execution_controls SET status = $1
+		WHERE id = $2 AND status = $3`, toStatus, controlID, fromStatus)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to update control: %w", op, err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return 0, fmt.Errorf("%s: rows affected: %w", op, err)
+	} else if n == 0 {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrInvalidStatus)
+	}
+
+	var reportID int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO document_execution_reports (control_id, kind, text, submitted_by_user_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`, controlID, req.Kind, req.Text, userID).Scan(&reportID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to insert report: %w", op, err)
+	}
+
+	if len(req.FileIDs) > 0 {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO document_execution_report_files (report_id, file_id)
+			VALUES ($1, unnest($2::bigint[]))
+			ON CONFLICT DO NOTHING`, reportID, pq.Array(req.FileIDs))
+		if err != nil {
+			if translatedErr := r.translator.Translate(err, op); translatedErr != nil {
+				return 0, translatedErr
+			}
+			return 0, fmt.Errorf("%s: failed to link files: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: failed to commit: %w", op, err)
+	}
+	return reportID, nil
+}
+
+// ReviewExecution records the controller's decision on the latest completion
+// report of a submitted item. Returning may move the due date.
+func (r *Repo) ReviewExecution(ctx context.Context, controlID int64, decision string, comment *string, newDueDate *time.Time, userID int64) error {
+	const op = "storage.repo.ReviewExecution"
+
+	status := execcontrol.StatusReturned
+	if decision == execcontrol.DecisionAccepted {
+		status = execcontrol.StatusAccepted
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE document_execution_controls SET
+			status = $1,
+			accepted_at = CASE WHEN $1 = 'accepted' THEN NOW() ELSE NULL END,
+			due_date = COALESCE($2, due_date)
+		WHERE id = $3 AND status = 'submitted'`, status, newDueDate, controlID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to update control: %w", op, err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	} else if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrInvalidStatus)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE document_execution_reports SET
+			decision = $1, decision_comment = $2, decided_by_user_id = $3, decided_at = NOW()
+		WHERE id = (
+			SELECT id FROM document_execution_reports
+			WHERE control_id = $4 AND kind = 'completion' AND decision IS NULL
+			ORDER BY submitted_at DESC, id DESC
+			LIMIT 1
+		)`, decision, comment, userID, controlID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to record decision: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: failed to commit: %w", op, err)
+	}
+	return nil
+}
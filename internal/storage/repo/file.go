@@ -12,8 +12,8 @@ import (
 func (r *Repo) AddFile(ctx context.Context, fileData file.Model) (int64, error) {
 	const op = "repo.file.AddFile"
 	const query = `
-		INSERT INTO files(file_name, object_key, category_id, mime_type, size_bytes, created_at, target_date)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO files(file_name, object_key, category_id, mime_type, size_bytes, created_at, target_date, content_sha256)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''))
 		RETURNING id
 	`
 	var id int64
@@ -25,6 +25,7 @@ func (r *Repo) AddFile(ctx context.Context, fileData file.Model) (int64, error)
 		fileData.SizeBytes,
 		fileData.CreatedAt,
 		fileData.TargetDate,
+		fileData.ContentSHA256,
 	).Scan(&id)
 	if err != nil {
 		return 0, r.translator.Translate(err, op)
@@ -113,7 +114,8 @@ func (r *Repo) DeleteFile(ctx context.Context, id int64) error {
 
 	res, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
-		return fmt.Errorf("%s: failed to execute statement: %w", op, err)
+		// A file attached to a signed document is kept by the attachment lock
+		return r.translator.Translate(err, op)
 	}
 
 	rowsAffected, err := res.RowsAffected()
@@ -0,0 +1,335 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"srmt-admin/internal/lib/model/file"
+	"srmt-admin/internal/storage"
+
+	"github.com/lib/pq"
+)
+
+// defaultOrphanGraceHours applies when a policy request leaves the grace out
+const defaultOrphanGraceHours = 24
+
+// AddFileFromDuplicate stores a file whose content is already stored under
+// another row: the new row shares that row's object. The source row is
+// locked FOR SHARE, so a concurrent delete of it either waits for the new row
+// (and then sees the object still referenced) or wins, and then no duplicate
+// is found. Returns the new ID and the shared object key, or
+// storage.ErrNotFound when no file has the same content.
+func (r *Repo) AddFileFromDuplicate(ctx context.Context, fileData file.Model) (int64, string, error) {
+	const op = "storage.repo.AddFileFromDuplicate"
+	const query = `
+		WITH source AS (
+			SELECT object_key FROM files
+			WHERE content_sha256 = $1 AND size_bytes = $2
+			ORDER BY id
+			LIMIT 1
+			FOR SHARE
+		)
+		INSERT INTO files (file_name, object_key, category_id, mime_type, size_bytes, created_at, target_date, content_sha256)
+		SELECT $3, source.object_key, $4, $5, $2, $6, $7, $1 FROM source
+		RETURNING id, object_key`
+
+	var id int64
+	var objectKey string
+	err := r.db.QueryRowContext(ctx, query,
+		fileData.ContentSHA256, fileData.SizeBytes, fileData.FileName, fileData.CategoryID,
+		fileData.MimeType, fileData.CreatedAt, fileData.TargetDate,
+	).Scan(&id, &objectKey)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, "", storage.ErrNotFound
+		}
+		return 0, "", r.translator.Translate(err, op)
+	}
+	return id, objectKey, nil
+}
+
+// CountFilesByObjectKey returns how many files rows refer to an object.
+func (r *Repo) CountFilesByObjectKey(ctx context.Context, objectKey string) (int, error) {
+	const op = "storage.repo.CountFilesByObjectKey"
+
+	var n int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM files WHERE object_key = $1`, objectKey).Scan(&n); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return n, nil
+}
+
+// UnreferencedObjectKeys returns the keys no files row refers to.
+func (r *Repo) UnreferencedObjectKeys(ctx context.Context, keys []string) ([]string, error) {
+	const op = "storage.repo.UnreferencedObjectKeys"
+	const query = `
+		SELECT k FROM unnest($1::text[]) AS k
+		WHERE NOT EXISTS (SELECT 1 FROM files f WHERE f.object_key = k)`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(keys))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	unreferenced := make([]string, 0)
+	for rows.Next() {
+		var k string
+		if err := rows.Scan(&k); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan key: %w", op, err)
+		}
+		unreferenced = append(unreferenced, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+	return unreferenced, nil
+}
+
+// GetRetentionPolicies returns the retention policies of all categories that
+// have one.
+func (r *Repo) GetRetentionPolicies(ctx context.Context) ([]file.RetentionPolicy, error) {
+	const op = "storage.repo.GetRetentionPolicies"
+	const query = `
+		SELECT p.category_id, c.name, p.retention_days, p.purge_orphans, p.orphan_grace_hours,
+			p.created_at, p.updated_at
+		FROM file_retention_policies p
+		JOIN categories c ON c.id = p.category_id
+		ORDER BY c.name`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	policies := make([]file.RetentionPolicy, 0)
+	for rows.Next() {
+		var p file.RetentionPolicy
+		var retentionDays sql.NullInt64
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&p.CategoryID, &p.CategoryName, &retentionDays, &p.PurgeOrphans,
+			&p.OrphanGraceHours, &p.CreatedAt, &updatedAt); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan policy: %w", op, err)
+		}
+		if retentionDays.Valid {
+			days := int(retentionDays.Int64)
+			p.RetentionDays = &days
+		}
+		if updatedAt.Valid {
+			p.UpdatedAt = &updatedAt.Time
+		}
+		policies = append(policies, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+	return policies, nil
+}
+
+// SetRetentionPolicy creates or replaces the policy of a category. An unknown
+// category is storage.ErrForeignKeyViolation.
+func (r *Repo) SetRetentionPolicy(ctx context.Context, categoryID int64, req file.RetentionPolicyRequest, userID int64) error {
+	const op = "storage.repo.SetRetentionPolicy"
+	const query = `
+		INSERT INTO file_retention_policies (category_id, retention_days, purge_orphans, orphan_grace_hours, updated_by_user_id)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (category_id) DO UPDATE SET
+			retention_days = EXCLUDED.retention_days,
+			purge_orphans = EXCLUDED.purge_orphans,
+			orphan_grace_hours = EXCLUDED.orphan_grace_hours,
+			updated_by_user_id = EXCLUDED.updated_by_user_id`
+
+	grace := defaultOrphanGraceHours
+	if req.OrphanGraceHours != nil {
+		grace = *req.OrphanGraceHours
+	}
+	if _, err := r.db.ExecContext(ctx, query, categoryID, req.RetentionDays, req.PurgeOrphans, grace, userID); err != nil {
+		return r.translator.Translate(err, op)
+	}
+	return nil
+}
+
+// DeleteRetentionPolicy removes the policy of a category; its files are kept
+// indefinitely from then on.
+func (r *Repo) DeleteRetentionPolicy(ctx context.Context, categoryID int64) error {
+	const op = "storage.repo.DeleteRetentionPolicy"
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM file_retention_policies WHERE category_id = $1`, categoryID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get affected rows: %w", op, err)
+	}
+	if n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// fileReferenceColumns returns every column with a foreign key to files,
+// except derived data such as extracted attachment text and the upload
+// session that created the file. A file no such column refers to is an
+// orphan.
+func (r *Repo) fileReferenceColumns(ctx context.Context) ([][2]string, error) {
+	const query = `
+		SELECT c.conrelid::regclass::text, a.attname
+		FROM pg_constraint c
+		JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = c.conkey[1]
+		WHERE c.contype = 'f'
+		  AND c.confrelid = 'files'::regclass
+		  AND c.conrelid NOT IN ('file_texts'::regclass, 'upload_sessions'::regclass)
+		ORDER BY 1, 2`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refs [][2]string
+	for rows.Next() {
+		var ref [2]string
+		if err := rows.Scan(&ref[0], &ref[1]); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+// GetPurgeCandidates returns, in ID order after afterID, files due for
+// removal under the retention policy of their category: expired ones and,
+// where the policy purges orphans, unreferenced ones past the grace period.
+// Categories without a policy never yield candidates.
+func (r *Repo) GetPurgeCandidates(ctx context.Context, afterID int64, limit int) ([]file.PurgeCandidate, error) {
+	const op = "storage.repo.GetPurgeCandidates"
+
+	refs, err := r.fileReferenceColumns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to list file references: %w", op, err)
+	}
+	unreferenced := "TRUE"
+	if len(refs) > 0 {
+		conds := make([]string, len(refs))
+		for i, ref := range refs {
+			// The table name comes quoted from regclass where needed
+			conds[i] = fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s x WHERE x.%s = f.id)", ref[0], pq.QuoteIdentifier(ref[1]))
+		}
+		unreferenced = strings.Join(conds, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT f.id, f.file_name, f.object_key, f.category_id, COALESCE(f.mime_type, ''),
+			COALESCE(f.size_bytes, 0), f.created_at, COALESCE(f.content_sha256, ''),
+			CASE WHEN expired THEN '%s' ELSE '%s' END
+		FROM files f
+		JOIN file_retention_policies p ON p.category_id = f.category_id
+		CROSS JOIN LATERAL (
+			SELECT p.retention_days IS NOT NULL
+				AND f.created_at < NOW() - make_interval(days => p.retention_days) AS expired
+		) e
+		WHERE f.id > $1
+		  AND (expired OR (p.purge_orphans
+			AND f.created_at < NOW() - make_interval(hours => p.orphan_grace_hours)
+			AND %s))
+		ORDER BY f.id
+		LIMIT $2`, file.PurgeExpired, file.PurgeOrphan, unreferenced)
+
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	candidates := make([]file.PurgeCandidate, 0)
+	for rows.Next() {
+		var c file.PurgeCandidate
+		if err := rows.Scan(&c.ID, &c.FileName, &c.ObjectKey, &c.CategoryID, &c.MimeType,
+			&c.SizeBytes, &c.CreatedAt, &c.ContentSHA256, &c.Reason); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan file: %w", op, err)
+		}
+		candidates = append(candidates, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+	return candidates, nil
+}
+
+// AddFileCleanupRun records the report of a cleanup run.
+func (r *Repo) AddFileCleanupRun(ctx context.Context, run file.CleanupRun) (int64, error) {
+	const op = "storage.repo.AddFileCleanupRun"
+	const query = `
+		INSERT INTO file_cleanup_runs (
+			dry_run, triggered_by_user_id, started_at, finished_at, expired_files, orphan_files,
+			orphan_objects, held_files, reclaimed_bytes, categories, errors
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`
+
+	categories, err := json.Marshal(run.Categories)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to encode categories: %w", op, err)
+	}
+	errs := run.Errors
+	if errs == nil {
+		errs = []string{}
+	}
+
+	var id int64
+	err = r.db.QueryRowContext(ctx, query,
+		run.DryRun, run.TriggeredBy, run.StartedAt, run.FinishedAt, run.ExpiredFiles, run.OrphanFiles,
+		run.OrphanObjects, run.HeldFiles, run.ReclaimedBytes, categories, pq.Array(errs),
+	).Scan(&id)
+	if err != nil {
+		return 0, r.translator.Translate(err, op)
+	}
+	return id, nil
+}
+
+// GetFileCleanupRuns returns the latest cleanup reports, newest first.
+func (r *Repo) GetFileCleanupRuns(ctx context.Context, limit int) ([]file.CleanupRun, error) {
+	const op = "storage.repo.GetFileCleanupRuns"
+	const query = `
+		SELECT id, dry_run, triggered_by_user_id, started_at, finished_at, expired_files, orphan_files,
+			orphan_objects, held_files, reclaimed_bytes, categories, errors
+		FROM file_cleanup_runs
+		ORDER BY started_at DESC, id DESC
+		LIMIT $1`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	runs := make([]file.CleanupRun, 0)
+	for rows.Next() {
+		var run file.CleanupRun
+		var triggeredBy sql.NullInt64
+		var categories []byte
+		if err := rows.Scan(&run.ID, &run.DryRun, &triggeredBy, &run.StartedAt, &run.FinishedAt,
+			&run.ExpiredFiles, &run.OrphanFiles, &run.OrphanObjects, &run.HeldFiles, &run.ReclaimedBytes,
+			&categories, pq.Array(&run.Errors)); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan run: %w", op, err)
+		}
+		if triggeredBy.Valid {
+			run.TriggeredBy = &triggeredBy.Int64
+		}
+		if err := json.Unmarshal(categories, &run.Categories); err != nil {
+			return nil, fmt.Errorf("%s: failed to decode categories of run %d: %w", op, run.ID, err)
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+	return runs, nil
+}
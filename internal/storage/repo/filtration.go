@@ -611,7 +611,7 @@ func (r *Repo) GetOrgFiltrationSummary(ctx context.Context, orgID int64, date st
 	// Get locations with measurements
 	const locQuery = `
 		SELECT l.id, l.organization_id, l.name, l.norm, l.sort_order, l.created_at, l.updated_at,
-		       m.flow_rate
+		       m.flow_rate, m.location_id IS NOT NULL
 		FROM filtration_locations l
 		LEFT JOIN filtration_measurements m ON m.location_id = l.id AND m.date = $2::date
 		WHERE l.organization_id = $1
@@ -629,7 +629,7 @@ func (r *Repo) GetOrgFiltrationSummary(ctx context.Context, orgID int64, date st
 		if err := locRows.Scan(
 			&lr.ID, &lr.OrganizationID, &lr.Name, &norm,
 			&lr.SortOrder, &lr.CreatedAt, &lr.UpdatedAt,
-			&flowRate,
+			&flowRate, &lr.Measured,
 		); err != nil {
 			return nil, fmt.Errorf("%s: failed to scan location reading: %w", op, err)
 		}
@@ -653,7 +653,7 @@ func (r *Repo) GetOrgFiltrationSummary(ctx context.Context, orgID int64, date st
 	// Get piezometers with measurements
 	const piezoQuery = `
 		SELECT p.id, p.organization_id, p.name, p.norm, p.sort_order, p.created_at, p.updated_at,
-		       m.level, COALESCE(m.anomaly, false)
+		       m.level, COALESCE(m.anomaly, false), m.piezometer_id IS NOT NULL
 		FROM piezometers p
 		LEFT JOIN piezometer_measurements m ON m.piezometer_id = p.id AND m.date = $2::date
 		WHERE p.organization_id = $1
@@ -671,7 +671,7 @@ func (r *Repo) GetOrgFiltrationSummary(ctx context.Context, orgID int64, date st
 		if err := piezoRows.Scan(
 			&pr.ID, &pr.OrganizationID, &pr.Name, &norm,
 			&pr.SortOrder, &pr.CreatedAt, &pr.UpdatedAt,
-			&level, &pr.Anomaly,
+			&level, &pr.Anomaly, &pr.Measured,
 		); err != nil {
 			return nil, fmt.Errorf("%s: failed to scan piezometer reading: %w", op, err)
 		}
@@ -0,0 +1,316 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"srmt-admin/internal/lib/model/filtration"
+	"srmt-admin/internal/storage"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// --- Instrument lookups ---
+
+func (r *Repo) GetFiltrationLocationByID(ctx context.Context, id int64) (*filtration.Location, error) {
+	const op = "storage.repo.Filtration.GetFiltrationLocationByID"
+
+	const query = `
+		SELECT id, organization_id, name, norm, sort_order, created_at, updated_at
+		FROM filtration_locations
+		WHERE id = $1`
+
+	var loc filtration.Location
+	var norm sql.NullFloat64
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&loc.ID, &loc.OrganizationID, &loc.Name, &norm,
+		&loc.SortOrder, &loc.CreatedAt, &loc.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if norm.Valid {
+		loc.Norm = &norm.Float64
+	}
+	return &loc, nil
+}
+
+func (r *Repo) GetPiezometerByID(ctx context.Context, id int64) (*filtration.Piezometer, error) {
+	const op = "storage.repo.Filtration.GetPiezometerByID"
+
+	const query = `
+		SELECT id, organization_id, name, norm, sort_order, created_at, updated_at
+		FROM piezometers
+		WHERE id = $1`
+
+	var p filtration.Piezometer
+	var norm sql.NullFloat64
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&p.ID, &p.OrganizationID, &p.Name, &norm,
+		&p.SortOrder, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if norm.Valid {
+		p.Norm = &norm.Float64
+	}
+	return &p, nil
+}
+
+// --- Time series ---
+
+// GetFiltrationSeries returns flow-rate readings of one location in
+// [from, to] (inclusive), oldest first, each paired with the reservoir level
+// of the owning organization on the same date (nil when reservoir_data has no row).
+func (r *Repo) GetFiltrationSeries(ctx context.Context, locationID int64, from, to string) ([]filtration.SeriesRow, error) {
+	const op = "storage.repo.Filtration.GetFiltrationSeries"
+
+	const query = `
+		SELECT m.date::text, m.flow_rate, rd.level_m
+		FROM filtration_measurements m
+		JOIN filtration_locations l ON l.id = m.location_id
+		LEFT JOIN reservoir_data rd ON rd.organization_id = l.organization_id AND rd.date = m.date
+		WHERE m.location_id = $1 AND m.date BETWEEN $2::date AND $3::date
+		ORDER BY m.date`
+
+	return r.querySeries(ctx, op, query, locationID, from, to)
+}
+
+// GetPiezometerSeries is the piezometer counterpart of GetFiltrationSeries.
+func (r *Repo) GetPiezometerSeries(ctx context.Context, piezometerID int64, from, to string) ([]filtration.SeriesRow, error) {
+	const op = "storage.repo.Filtration.GetPiezometerSeries"
+
+	const query = `
+		SELECT m.date::text, m.level, rd.level_m
+		FROM piezometer_measurements m
+		JOIN piezometers p ON p.id = m.piezometer_id
+		LEFT JOIN reservoir_data rd ON rd.organization_id = p.organization_id AND rd.date = m.date
+		WHERE m.piezometer_id = $1 AND m.date BETWEEN $2::date AND $3::date
+		ORDER BY m.date`
+
+	return r.querySeries(ctx, op, query, piezometerID, from, to)
+}
+
+func (r *Repo) querySeries(ctx context.Context, op, query string, id int64, from, to string) ([]filtration.SeriesRow, error) {
+	rows, err := r.db.QueryContext(ctx, query, id, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query series: %w", op, err)
+	}
+	defer rows.Close()
+
+	result := make([]filtration.SeriesRow, 0)
+	for rows.Next() {
+		var row filtration.SeriesRow
+		var value, level sql.NullFloat64
+		if err := rows.Scan(&row.Date, &value, &level); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan series row: %w", op, err)
+		}
+		if value.Valid {
+			row.Value = &value.Float64
+		}
+		if level.Valid {
+			row.ReservoirLevel = &level.Float64
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows iteration error: %w", op, err)
+	}
+	return result, nil
+}
+
+// --- Alerts ---
+
+// ReplaceFiltrationAlerts rebuilds the alert set of one organization for one
+// date in a single transaction: unacknowledged rows are deleted, then the given
+// alerts are inserted. Acknowledged rows survive; a re-raised alert that is
+// already acknowledged is skipped via ON CONFLICT DO NOTHING.
+func (r *Repo) ReplaceFiltrationAlerts(ctx context.Context, orgID int64, date string, alerts []filtration.Alert) error {
+	const op = "storage.repo.Filtration.ReplaceFiltrationAlerts"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM filtration_alerts
+		 WHERE organization_id = $1 AND date = $2::date AND acknowledged_at IS NULL`,
+		orgID, date,
+	); err != nil {
+		return fmt.Errorf("%s: delete open alerts: %w", op, err)
+	}
+
+	const insertQuery = `
+		INSERT INTO filtration_alerts (organization_id, location_id, piezometer_id, date, reason, value,
+		                               reservoir_level, norm, expected, band_low, band_high)
+		VALUES ($1, $2, $3, $4::date, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT DO NOTHING`
+
+	for _, a := range alerts {
+		var locationID, piezometerID *int64
+		switch a.InstrumentType {
+		case filtration.InstrumentLocation:
+			locationID = &a.InstrumentID
+		case filtration.InstrumentPiezometer:
+			piezometerID = &a.InstrumentID
+		default:
+			return fmt.Errorf("%s: unknown instrument type %q", op, a.InstrumentType)
+		}
+
+		if _, err := tx.ExecContext(ctx, insertQuery,
+			orgID, locationID, piezometerID, date, a.Reason, a.Value,
+			a.ReservoirLevel, a.Norm, a.Expected, a.BandLow, a.BandHigh,
+		); err != nil {
+			if translatedErr := r.translator.Translate(err, op); translatedErr != nil {
+				return translatedErr
+			}
+			return fmt.Errorf("%s: insert alert %s/%d: %w", op, a.InstrumentType, a.InstrumentID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return nil
+}
+
+// GetFiltrationAlerts lists alerts newest date first, composing the WHERE
+// clause from the non-empty filter fields.
+func (r *Repo) GetFiltrationAlerts(ctx context.Context, f filtration.AlertFilter) ([]filtration.Alert, error) {
+	const op = "storage.repo.Filtration.GetFiltrationAlerts"
+
+	var conds []string
+	var args []interface{}
+	argID := 1
+
+	if f.OrganizationIDs != nil {
+		conds = append(conds, fmt.Sprintf("a.organization_id = ANY($%d)", argID))
+		args = append(args, pq.Array(f.OrganizationIDs))
+		argID++
+	}
+	if f.From != nil {
+		conds = append(conds, fmt.Sprintf("a.date >= $%d::date", argID))
+		args = append(args, *f.From)
+		argID++
+	}
+	if f.To != nil {
+		conds = append(conds, fmt.Sprintf("a.date <= $%d::date", argID))
+		args = append(args, *f.To)
+		argID++
+	}
+	if f.OnlyOpen {
+		conds = append(conds, "a.acknowledged_at IS NULL")
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT a.id, a.organization_id, o.name,
+		       CASE WHEN a.location_id IS NOT NULL THEN 'location' ELSE 'piezometer' END,
+		       COALESCE(a.location_id, a.piezometer_id),
+		       COALESCE(l.name, p.name),
+		       a.date::text, a.reason, a.value, a.reservoir_level, a.norm, a.expected,
+		       a.band_low, a.band_high, a.created_at, a.acknowledged_at, a.acknowledged_by_user_id
+		FROM filtration_alerts a
+		JOIN organizations o ON o.id = a.organization_id
+		LEFT JOIN filtration_locations l ON l.id = a.location_id
+		LEFT JOIN piezometers p ON p.id = a.piezometer_id
+		%s
+		ORDER BY a.date DESC, o.name, a.id`, where)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query alerts: %w", op, err)
+	}
+	defer rows.Close()
+
+	alerts := make([]filtration.Alert, 0)
+	for rows.Next() {
+		var a filtration.Alert
+		var level, norm, expected, low, high sql.NullFloat64
+		var ackAt sql.NullTime
+		var ackBy sql.NullInt64
+		if err := rows.Scan(
+			&a.ID, &a.OrganizationID, &a.OrganizationName,
+			&a.InstrumentType, &a.InstrumentID, &a.InstrumentName,
+			&a.Date, &a.Reason, &a.Value, &level, &norm, &expected,
+			&low, &high, &a.CreatedAt, &ackAt, &ackBy,
+		); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan alert: %w", op, err)
+		}
+		a.ReservoirLevel = nullFloatPtr(level)
+		a.Norm = nullFloatPtr(norm)
+		a.Expected = nullFloatPtr(expected)
+		a.BandLow = nullFloatPtr(low)
+		a.BandHigh = nullFloatPtr(high)
+		if ackAt.Valid {
+			a.AcknowledgedAt = &ackAt.Time
+		}
+		if ackBy.Valid {
+			a.AcknowledgedByUserID = &ackBy.Int64
+		}
+		alerts = append(alerts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows iteration error: %w", op, err)
+	}
+	return alerts, nil
+}
+
+func (r *Repo) GetFiltrationAlertOrgID(ctx context.Context, id int64) (int64, error) {
+	const op = "storage.repo.Filtration.GetFiltrationAlertOrgID"
+
+	var orgID int64
+	err := r.db.QueryRowContext(ctx, "SELECT organization_id FROM filtration_alerts WHERE id = $1", id).Scan(&orgID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+		return 0, r.translator.Translate(err, op)
+	}
+	return orgID, nil
+}
+
+// AcknowledgeFiltrationAlert marks an alert as seen. Acknowledging an already
+// acknowledged alert keeps the original timestamp and user.
+func (r *Repo) AcknowledgeFiltrationAlert(ctx context.Context, id int64, userID int64) error {
+	const op = "storage.repo.Filtration.AcknowledgeFiltrationAlert"
+
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE filtration_alerts
+		 SET acknowledged_at = COALESCE(acknowledged_at, NOW()),
+		     acknowledged_by_user_id = COALESCE(acknowledged_by_user_id, $2)
+		 WHERE id = $1`,
+		id, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func nullFloatPtr(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Float64
+}
@@ -0,0 +1,194 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"srmt-admin/internal/lib/model/filtration"
+	"srmt-admin/internal/storage"
+)
+
+// --- Measurement schedules ---
+
+// UpsertFiltrationSchedule inserts or replaces the schedule for the target
+// implied by req: the organization default when neither instrument is set,
+// otherwise the per-instrument override. Each target has its own partial
+// unique index, so the ON CONFLICT clause is chosen accordingly.
+func (r *Repo) UpsertFiltrationSchedule(ctx context.Context, req filtration.UpsertScheduleRequest, userID int64) (int64, error) {
+	const op = "storage.repo.Filtration.UpsertFiltrationSchedule"
+
+	var conflict string
+	switch {
+	case req.LocationID != nil:
+		conflict = "(location_id) WHERE location_id IS NOT NULL"
+	case req.PiezometerID != nil:
+		conflict = "(piezometer_id) WHERE piezometer_id IS NOT NULL"
+	default:
+		conflict = "(organization_id) WHERE location_id IS NULL AND piezometer_id IS NULL"
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO filtration_measurement_schedules (organization_id, location_id, piezometer_id, frequency,
+		                                              created_by_user_id, updated_by_user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5, NOW(), NOW())
+		ON CONFLICT %s
+		DO UPDATE SET frequency = EXCLUDED.frequency,
+		              updated_by_user_id = EXCLUDED.updated_by_user_id,
+		              updated_at = NOW()
+		RETURNING id`, conflict)
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, query, req.OrganizationID, req.LocationID, req.PiezometerID, req.Frequency, userID).Scan(&id)
+	if err != nil {
+		if translatedErr := r.translator.Translate(err, op); translatedErr != nil {
+			return 0, translatedErr
+		}
+		return 0, fmt.Errorf("%s: failed to upsert schedule: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (r *Repo) GetFiltrationSchedules(ctx context.Context, orgID int64) ([]filtration.Schedule, error) {
+	const op = "storage.repo.Filtration.GetFiltrationSchedules"
+
+	const query = `
+		SELECT id, organization_id, location_id, piezometer_id, frequency, created_at, updated_at
+		FROM filtration_measurement_schedules
+		WHERE organization_id = $1
+		ORDER BY location_id NULLS FIRST, piezometer_id NULLS FIRST, id`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query schedules: %w", op, err)
+	}
+	defer rows.Close()
+
+	schedules := make([]filtration.Schedule, 0)
+	for rows.Next() {
+		var s filtration.Schedule
+		var locationID, piezometerID sql.NullInt64
+		if err := rows.Scan(&s.ID, &s.OrganizationID, &locationID, &piezometerID, &s.Frequency, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan schedule: %w", op, err)
+		}
+		if locationID.Valid {
+			s.LocationID = &locationID.Int64
+		}
+		if piezometerID.Valid {
+			s.PiezometerID = &piezometerID.Int64
+		}
+		schedules = append(schedules, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows iteration error: %w", op, err)
+	}
+	return schedules, nil
+}
+
+func (r *Repo) GetFiltrationScheduleOrgID(ctx context.Context, id int64) (int64, error) {
+	const op = "storage.repo.Filtration.GetFiltrationScheduleOrgID"
+
+	var orgID int64
+	err := r.db.QueryRowContext(ctx, "SELECT organization_id FROM filtration_measurement_schedules WHERE id = $1", id).Scan(&orgID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+		return 0, r.translator.Translate(err, op)
+	}
+	return orgID, nil
+}
+
+func (r *Repo) DeleteFiltrationSchedule(ctx context.Context, id int64) error {
+	const op = "storage.repo.Filtration.DeleteFiltrationSchedule"
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM filtration_measurement_schedules WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("%s: failed to delete schedule: %w", op, err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get affected rows: %w", op, err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// GetFiltrationReadDates returns every (instrument, date) in [from, to] that
+// has a non-null reading for the organization. Rows saved with a null value
+// count as not measured.
+func (r *Repo) GetFiltrationReadDates(ctx context.Context, orgID int64, from, to string) ([]filtration.ReadDate, error) {
+	const op = "storage.repo.Filtration.GetFiltrationReadDates"
+
+	const query = `
+		SELECT 'location', m.location_id, m.date::text
+		FROM filtration_measurements m
+		JOIN filtration_locations l ON l.id = m.location_id
+		WHERE l.organization_id = $1 AND m.date BETWEEN $2::date AND $3::date AND m.flow_rate IS NOT NULL
+		UNION ALL
+		SELECT 'piezometer', m.piezometer_id, m.date::text
+		FROM piezometer_measurements m
+		JOIN piezometers p ON p.id = m.piezometer_id
+		WHERE p.organization_id = $1 AND m.date BETWEEN $2::date AND $3::date AND m.level IS NOT NULL`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query read dates: %w", op, err)
+	}
+	defer rows.Close()
+
+	result := make([]filtration.ReadDate, 0)
+	for rows.Next() {
+		var d filtration.ReadDate
+		if err := rows.Scan(&d.InstrumentType, &d.InstrumentID, &d.Date); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan read date: %w", op, err)
+		}
+		result = append(result, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows iteration error: %w", op, err)
+	}
+	return result, nil
+}
+
+// GetFiltrationReminderRecipients returns the contact IDs of active users with
+// the reservoir role bound to the organization — the station staff who enter
+// filtration readings.
+func (r *Repo) GetFiltrationReminderRecipients(ctx context.Context, orgID int64) ([]int64, error) {
+	const op = "storage.repo.Filtration.GetFiltrationReminderRecipients"
+
+	const query = `
+		SELECT DISTINCT u.contact_id
+		FROM users u
+		JOIN user_organizations uo ON uo.user_id = u.id
+		JOIN users_roles ur ON ur.user_id = u.id
+		JOIN roles ro ON ro.id = ur.role_id
+		WHERE uo.organization_id = $1
+		  AND ro.name = 'reservoir'
+		  AND u.is_active = TRUE
+		  AND u.contact_id IS NOT NULL
+		ORDER BY u.contact_id`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query recipients: %w", op, err)
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan recipient: %w", op, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows iteration error: %w", op, err)
+	}
+	return ids, nil
+}
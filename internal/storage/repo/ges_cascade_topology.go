@@ -0,0 +1,150 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	model "srmt-admin/internal/lib/model/ges-report"
+
+	"github.com/lib/pq"
+)
+
+// --- Cascade topology ---
+
+// GetCascadeTopology returns the links of a cascade ordered upstream-first by
+// insertion; callers order the chain themselves.
+func (r *Repo) GetCascadeTopology(ctx context.Context, cascadeOrgID int64) ([]model.TopologyLink, error) {
+	const op = "storage.repo.GESReport.GetCascadeTopology"
+
+	const query = `
+		SELECT ct.station_org_id, o.name, ct.downstream_org_id, ct.lag_hours, ct.tolerance_pct
+		FROM cascade_topology ct
+		JOIN organizations o ON o.id = ct.station_org_id
+		WHERE ct.cascade_org_id = $1
+		ORDER BY ct.id`
+
+	rows, err := r.db.QueryContext(ctx, query, cascadeOrgID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	result := make([]model.TopologyLink, 0)
+	for rows.Next() {
+		var l model.TopologyLink
+		var downstream sql.NullInt64
+		if err := rows.Scan(&l.StationOrgID, &l.StationName, &downstream, &l.LagHours, &l.TolerancePct); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		if downstream.Valid {
+			v := downstream.Int64
+			l.DownstreamOrgID = &v
+		}
+		result = append(result, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+	return result, nil
+}
+
+// ReplaceCascadeTopology swaps the whole topology of a cascade in one
+// transaction.
+func (r *Repo) ReplaceCascadeTopology(ctx context.Context, req model.ReplaceTopologyRequest) error {
+	const op = "storage.repo.GESReport.ReplaceCascadeTopology"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM cascade_topology WHERE cascade_org_id = $1", req.CascadeOrgID); err != nil {
+		return fmt.Errorf("%s: delete: %w", op, err)
+	}
+
+	const insert = `
+		INSERT INTO cascade_topology (cascade_org_id, station_org_id, downstream_org_id, lag_hours, tolerance_pct)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	for _, l := range req.Links {
+		if _, err := tx.ExecContext(ctx, insert, req.CascadeOrgID, l.StationOrgID, l.DownstreamOrgID, l.LagHours, l.TolerancePct); err != nil {
+			if translatedErr := r.translator.Translate(err, op); translatedErr != nil {
+				return translatedErr
+			}
+			return fmt.Errorf("%s: insert station=%d: %w", op, l.StationOrgID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return nil
+}
+
+// GetCascadeStationIDs returns the direct children of the cascade
+// organization — the stations a topology may reference.
+func (r *Repo) GetCascadeStationIDs(ctx context.Context, cascadeOrgID int64) ([]int64, error) {
+	const op = "storage.repo.GESReport.GetCascadeStationIDs"
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id FROM organizations WHERE parent_organization_id = $1 ORDER BY id", cascadeOrgID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+	return ids, nil
+}
+
+// GetGESFlowSeries returns the daily inflow and total outflow of the given
+// stations in [from, to].
+func (r *Repo) GetGESFlowSeries(ctx context.Context, orgIDs []int64, from, to string) ([]model.FlowRow, error) {
+	const op = "storage.repo.GESReport.GetGESFlowSeries"
+
+	const query = `
+		SELECT organization_id, date::text, reservoir_income_m3s, total_outflow_m3s
+		FROM ges_daily_data
+		WHERE organization_id = ANY($1) AND date BETWEEN $2::date AND $3::date
+		ORDER BY date, organization_id`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(orgIDs), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	result := make([]model.FlowRow, 0)
+	for rows.Next() {
+		var row model.FlowRow
+		var inflow, outflow sql.NullFloat64
+		if err := rows.Scan(&row.OrganizationID, &row.Date, &inflow, &outflow); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		if inflow.Valid {
+			v := inflow.Float64
+			row.InflowM3s = &v
+		}
+		if outflow.Valid {
+			v := outflow.Float64
+			row.OutflowM3s = &v
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+	return result, nil
+}
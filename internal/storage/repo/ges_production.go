@@ -125,3 +125,53 @@ func (r *Repo) GetGesProductionStats(ctx context.Context) (*gesproduction.StatsR
 		YearTotal:  yearTotal,
 	}, nil
 }
+
+// GetGesProductionRange returns the records dated in [from, to], ascending
+func (r *Repo) GetGesProductionRange(ctx context.Context, from, to string) ([]gesproduction.Model, error) {
+	const op = "storage.repo.GetGesProductionRange"
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, date::text, total_energy_production, monthly_energy_production, yearly_energy_production, created_at, updated_at
+		FROM ges_production
+		WHERE date BETWEEN $1::date AND $2::date
+		ORDER BY date
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	result := make([]gesproduction.Model, 0)
+	for rows.Next() {
+		var m gesproduction.Model
+		if err := rows.Scan(&m.ID, &m.Date, &m.TotalEnergyProduction, &m.MonthlyEnergyProduction, &m.YearlyEnergyProduction, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		result = append(result, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return result, nil
+}
+
+// GetGesProductionBefore returns the last record dated before date, or nil
+func (r *Repo) GetGesProductionBefore(ctx context.Context, date string) (*gesproduction.Model, error) {
+	const op = "storage.repo.GetGesProductionBefore"
+
+	var m gesproduction.Model
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, date::text, total_energy_production, monthly_energy_production, yearly_energy_production, created_at, updated_at
+		FROM ges_production
+		WHERE date < $1::date
+		ORDER BY date DESC
+		LIMIT 1
+	`, date).Scan(&m.ID, &m.Date, &m.TotalEnergyProduction, &m.MonthlyEnergyProduction, &m.YearlyEnergyProduction, &m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &m, nil
+}
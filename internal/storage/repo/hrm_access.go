@@ -43,7 +43,7 @@ func (r *Repo) GetAccessCardByID(ctx context.Context, id int64) (*access.AccessC
 	const op = "repo.GetAccessCardByID"
 
 	query := `
-		SELECT ac.id, ac.employee_id, COALESCE(c.name, ''),
+		SELECT ac.id, ac.employee_id, COALESCE(c.fio, ''),
 			   ac.card_number, ac.status, ac.issued_date, ac.expiry_date,
 			   ac.access_zones, ac.access_level, ac.created_at, ac.updated_at
 		FROM access_cards ac
@@ -64,7 +64,7 @@ func (r *Repo) GetAllAccessCards(ctx context.Context, filters dto.AccessCardFilt
 	const op = "repo.GetAllAccessCards"
 
 	query := `
-		SELECT ac.id, ac.employee_id, COALESCE(c.name, ''),
+		SELECT ac.id, ac.employee_id, COALESCE(c.fio, ''),
 			   ac.card_number, ac.status, ac.issued_date, ac.expiry_date,
 			   ac.access_zones, ac.access_level, ac.created_at, ac.updated_at
 		FROM access_cards ac
@@ -85,7 +85,7 @@ func (r *Repo) GetAllAccessCards(ctx context.Context, filters dto.AccessCardFilt
 		argIdx++
 	}
 	if filters.Search != nil {
-		conditions = append(conditions, fmt.Sprintf("(ac.card_number ILIKE $%d OR c.name ILIKE $%d)", argIdx, argIdx))
+		conditions = append(conditions, fmt.Sprintf("(ac.card_number ILIKE $%d OR c.fio ILIKE $%d)", argIdx, argIdx))
 		args = append(args, "%"+*filters.Search+"%")
 		argIdx++
 	}
@@ -228,8 +228,7 @@ func (r *Repo) GetAccessZoneByID(ctx context.Context, id int64) (*access.AccessZ
 	query := `
 		SELECT az.id, az.name, az.description, az.security_level, az.building, az.floor,
 			   az.max_occupancy,
-			   (SELECT COUNT(*) FROM access_logs al WHERE al.zone_id = az.id AND al.direction = 'entry' AND al.status = 'granted')
-			   - (SELECT COUNT(*) FROM access_logs al WHERE al.zone_id = az.id AND al.direction = 'exit' AND al.status = 'granted'),
+			   (SELECT COUNT(*) FROM access_zone_presence p WHERE p.zone_id = az.id),
 			   az.readers, az.schedules, az.created_at, az.updated_at
 		FROM access_zones az
 		WHERE az.id = $1`
@@ -249,7 +248,8 @@ func (r *Repo) GetAllAccessZones(ctx context.Context) ([]*access.AccessZone, err
 
 	query := `
 		SELECT az.id, az.name, az.description, az.security_level, az.building, az.floor,
-			   az.max_occupancy, 0,
+			   az.max_occupancy,
+			   (SELECT COUNT(*) FROM access_zone_presence p WHERE p.zone_id = az.id),
 			   az.readers, az.schedules, az.created_at, az.updated_at
 		FROM access_zones az
 		ORDER BY az.name`
@@ -348,9 +348,10 @@ func (r *Repo) GetAccessLogs(ctx context.Context, filters dto.AccessLogFilters)
 	const op = "repo.GetAccessLogs"
 
 	query := `
-		SELECT al.id, al.employee_id, COALESCE(c.name, ''), COALESCE(ac.card_number, ''),
+		SELECT al.id, al.employee_id, COALESCE(c.fio, ''), COALESCE(ac.card_number, al.card_number, ''),
 			   al.zone_id, COALESCE(az.name, ''),
-			   al.reader_id, al.direction, al.timestamp, al.status, al.denial_reason
+			   al.reader_id, al.direction, al.timestamp, al.status, al.denial_reason,
+			   al.controller_id, al.alert
 		FROM access_logs al
 		LEFT JOIN contacts c ON al.employee_id = c.id
 		LEFT JOIN access_cards ac ON al.card_id = ac.id
@@ -390,6 +391,9 @@ func (r *Repo) GetAccessLogs(ctx context.Context, filters dto.AccessLogFilters)
 		args = append(args, *filters.DateTo)
 		argIdx++
 	}
+	if filters.AlertsOnly {
+		conditions = append(conditions, "al.alert IS NOT NULL")
+	}
 
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
@@ -438,7 +442,7 @@ func (r *Repo) GetAllAccessRequests(ctx context.Context, employeeID *int64) ([]*
 	const op = "repo.GetAllAccessRequests"
 
 	query := `
-		SELECT ar.id, ar.employee_id, COALESCE(c.name, ''),
+		SELECT ar.id, ar.employee_id, COALESCE(c.fio, ''),
 			   ar.zone_id, az.name,
 			   ar.reason, ar.status, ar.approved_by, ar.rejection_reason,
 			   ar.created_at, ar.updated_at
@@ -474,7 +478,7 @@ func (r *Repo) GetAccessRequestByID(ctx context.Context, id int64) (*access.Acce
 	const op = "repo.GetAccessRequestByID"
 
 	query := `
-		SELECT ar.id, ar.employee_id, COALESCE(c.name, ''),
+		SELECT ar.id, ar.employee_id, COALESCE(c.fio, ''),
 			   ar.zone_id, az.name,
 			   ar.reason, ar.status, ar.approved_by, ar.rejection_reason,
 			   ar.created_at, ar.updated_at
@@ -551,6 +555,7 @@ func scanAccessLog(s scannable) (*access.AccessLog, error) {
 		&al.ID, &al.EmployeeID, &al.EmployeeName, &al.CardNumber,
 		&al.ZoneID, &al.ZoneName,
 		&al.ReaderID, &al.Direction, &al.Timestamp, &al.Status, &al.DenialReason,
+		&al.ControllerID, &al.Alert,
 	)
 	if err != nil {
 		return nil, err
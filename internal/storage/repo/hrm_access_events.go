@@ -0,0 +1,275 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"srmt-admin/internal/lib/model/hrm/access"
+	"srmt-admin/internal/storage"
+	"time"
+)
+
+// ==================== Access Event Ingestion ====================
+
+// GetAccessCardByNumber returns the card with the given number
+func (r *Repo) GetAccessCardByNumber(ctx context.Context, cardNumber string) (*access.AccessCard, error) {
+	const op = "repo.GetAccessCardByNumber"
+
+	query := `
+		SELECT id, employee_id, status, expiry_date::text
+		FROM access_cards
+		WHERE card_number = $1`
+
+	var card access.AccessCard
+	err := r.db.QueryRowContext(ctx, query, cardNumber).Scan(&card.ID, &card.EmployeeID, &card.Status, &card.ExpiryDate)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, storage.ErrAccessCardNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	card.CardNumber = cardNumber
+	return &card, nil
+}
+
+// GetAccessZoneIDByReader returns the zone whose readers include readerID,
+// listed either as a number or as an object with that id.
+func (r *Repo) GetAccessZoneIDByReader(ctx context.Context, readerID int) (int64, error) {
+	const op = "repo.GetAccessZoneIDByReader"
+
+	query := `
+		SELECT id
+		FROM access_zones
+		WHERE readers @> jsonb_build_array($1::int)
+		   OR readers @> jsonb_build_array(jsonb_build_object('id', $1::int))
+		ORDER BY id
+		LIMIT 1`
+
+	var id int64
+	if err := r.db.QueryRowContext(ctx, query, readerID).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, storage.ErrAccessZoneNotFound
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+// RecordAccessEvent stores a matched controller event. A granted event of a
+// known employee in a known zone also moves the employee in or out of the
+// zone, under a lock on the zone row: entering while inside or leaving while
+// outside is an anti-passback violation, and an entry that takes the zone
+// over its max_occupancy is flagged. A card problem already in rec.Alert
+// outranks both. An event whose (controller_id, event_id) is already stored
+// changes nothing and is reported as a duplicate.
+func (r *Repo) RecordAccessEvent(ctx context.Context, rec access.EventRecord) (*access.RecordedEvent, error) {
+	const op = "repo.RecordAccessEvent"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if rec.EventID != nil {
+		var id int64
+		err := tx.QueryRowContext(ctx,
+			`SELECT id FROM access_logs WHERE controller_id = $1 AND event_id = $2`,
+			rec.ControllerID, *rec.EventID,
+		).Scan(&id)
+		if err == nil {
+			return &access.RecordedEvent{ID: id, Duplicate: true}, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, fmt.Errorf("%s: find duplicate: %w", op, err)
+		}
+	}
+
+	alert := rec.Alert
+	if rec.Status == "granted" && rec.EmployeeID != nil && rec.ZoneID != nil {
+		zoneAlert, err := moveThroughZone(ctx, tx, rec)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if alert == nil {
+			alert = zoneAlert
+		}
+	}
+
+	query := `
+		INSERT INTO access_logs
+			(employee_id, card_id, zone_id, reader_id, direction, timestamp, status, denial_reason,
+			 card_number, controller_id, event_id, alert)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (controller_id, event_id) WHERE event_id IS NOT NULL DO NOTHING
+		RETURNING id`
+
+	var id int64
+	err = tx.QueryRowContext(ctx, query,
+		rec.EmployeeID, rec.CardID, rec.ZoneID, rec.ReaderID, rec.Direction, rec.Timestamp, rec.Status, rec.DenialReason,
+		rec.CardNumber, rec.ControllerID, rec.EventID, alert,
+	).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Stored concurrently by another request; the rollback undoes
+			// the presence change made above.
+			return &access.RecordedEvent{Duplicate: true}, nil
+		}
+		if translated := r.translator.Translate(err, op); translated != nil {
+			return nil, translated
+		}
+		return nil, fmt.Errorf("%s: insert: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return &access.RecordedEvent{ID: id, Alert: alert}, nil
+}
+
+// moveThroughZone applies a granted event to the zone presence and returns
+// the zone alert it raises, if any.
+func moveThroughZone(ctx context.Context, tx *sql.Tx, rec access.EventRecord) (*string, error) {
+	var maxOccupancy int
+	err := tx.QueryRowContext(ctx,
+		`SELECT max_occupancy FROM access_zones WHERE id = $1 FOR UPDATE`, *rec.ZoneID,
+	).Scan(&maxOccupancy)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("lock zone: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM access_zone_presence WHERE zone_id = $1 AND entered_at < $2`,
+		*rec.ZoneID, rec.PresenceSince,
+	); err != nil {
+		return nil, fmt.Errorf("expire presence: %w", err)
+	}
+
+	var inside bool
+	err = tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM access_zone_presence WHERE zone_id = $1 AND employee_id = $2)`,
+		*rec.ZoneID, *rec.EmployeeID,
+	).Scan(&inside)
+	if err != nil {
+		return nil, fmt.Errorf("check presence: %w", err)
+	}
+
+	var alert string
+	if rec.Direction == "exit" {
+		if !inside {
+			alert = access.AlertAntiPassback
+		}
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM access_zone_presence WHERE zone_id = $1 AND employee_id = $2`,
+			*rec.ZoneID, *rec.EmployeeID,
+		); err != nil {
+			return nil, fmt.Errorf("leave zone: %w", err)
+		}
+	} else {
+		if inside {
+			alert = access.AlertAntiPassback
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO access_zone_presence (zone_id, employee_id, entered_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (zone_id, employee_id) DO UPDATE SET entered_at = EXCLUDED.entered_at`,
+			*rec.ZoneID, *rec.EmployeeID, rec.Timestamp,
+		); err != nil {
+			return nil, fmt.Errorf("enter zone: %w", err)
+		}
+
+		if alert == "" && maxOccupancy > 0 {
+			var occupancy int
+			err := tx.QueryRowContext(ctx,
+				`SELECT COUNT(*) FROM access_zone_presence WHERE zone_id = $1`, *rec.ZoneID,
+			).Scan(&occupancy)
+			if err != nil {
+				return nil, fmt.Errorf("count presence: %w", err)
+			}
+			if occupancy > maxOccupancy {
+				alert = access.AlertOccupancyExceeded
+			}
+		}
+	}
+
+	if alert == "" {
+		return nil, nil
+	}
+	return &alert, nil
+}
+
+// DeleteStaleZonePresence forgets employees who entered a zone before the
+// given time and never badged out.
+func (r *Repo) DeleteStaleZonePresence(ctx context.Context, before time.Time) (int64, error) {
+	const op = "repo.DeleteStaleZonePresence"
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM access_zone_presence WHERE entered_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	n, _ := result.RowsAffected()
+	return n, nil
+}
+
+// GetZoneOccupancy returns every zone with the employees inside it
+func (r *Repo) GetZoneOccupancy(ctx context.Context) ([]*access.ZoneOccupancy, error) {
+	const op = "repo.GetZoneOccupancy"
+
+	query := `
+		SELECT az.id, az.name, az.max_occupancy,
+			   p.employee_id, COALESCE(c.fio, ''), p.entered_at
+		FROM access_zones az
+		LEFT JOIN access_zone_presence p ON p.zone_id = az.id
+		LEFT JOIN contacts c ON p.employee_id = c.id
+		ORDER BY az.name, az.id, p.entered_at`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var zones []*access.ZoneOccupancy
+	var current *access.ZoneOccupancy
+	for rows.Next() {
+		var (
+			zoneID       int64
+			zoneName     string
+			maxOccupancy int
+			employeeID   sql.NullInt64
+			employeeName string
+			enteredAt    sql.NullTime
+		)
+		if err := rows.Scan(&zoneID, &zoneName, &maxOccupancy, &employeeID, &employeeName, &enteredAt); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		if current == nil || current.ZoneID != zoneID {
+			current = &access.ZoneOccupancy{
+				ZoneID:       zoneID,
+				ZoneName:     zoneName,
+				MaxOccupancy: maxOccupancy,
+				People:       []access.ZoneOccupant{},
+			}
+			zones = append(zones, current)
+		}
+		if employeeID.Valid {
+			current.People = append(current.People, access.ZoneOccupant{
+				EmployeeID:   employeeID.Int64,
+				EmployeeName: employeeName,
+				EnteredAt:    enteredAt.Time,
+			})
+			current.Occupancy++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, z := range zones {
+		z.OverCapacity = z.MaxOccupancy > 0 && z.Occupancy > z.MaxOccupancy
+	}
+	return zones, nil
+}
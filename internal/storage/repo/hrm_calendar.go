@@ -0,0 +1,277 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/calendar"
+	"srmt-admin/internal/lib/model/hrm/timesheet"
+	"srmt-admin/internal/storage"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ==================== Production Calendar ====================
+
+// GetHolidaysBetween returns the holidays between from and to (YYYY-MM-DD)
+func (r *Repo) GetHolidaysBetween(ctx context.Context, from, to string) ([]*timesheet.Holiday, error) {
+	const op = "repo.GetHolidaysBetween"
+
+	query := `
+		SELECT id, name, date::text, type, description, created_at
+		FROM holidays
+		WHERE date BETWEEN $1::date AND $2::date
+		ORDER BY date`
+
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var holidays []*timesheet.Holiday
+	for rows.Next() {
+		var h timesheet.Holiday
+		var desc sql.NullString
+		if err := rows.Scan(&h.ID, &h.Name, &h.Date, &h.Type, &desc, &h.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		if desc.Valid {
+			h.Description = &desc.String
+		}
+		holidays = append(holidays, &h)
+	}
+	return holidays, rows.Err()
+}
+
+// GetCalendarExceptions returns the transferred and shortened days between
+// from and to (YYYY-MM-DD)
+func (r *Repo) GetCalendarExceptions(ctx context.Context, from, to string) ([]*calendar.Exception, error) {
+	const op = "repo.GetCalendarExceptions"
+
+	query := `
+		SELECT id, date::text, type, week_days, note
+		FROM calendar_exceptions
+		WHERE date BETWEEN $1::date AND $2::date
+		ORDER BY date, week_days NULLS FIRST`
+
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var exceptions []*calendar.Exception
+	for rows.Next() {
+		var e calendar.Exception
+		var weekDays sql.NullInt64
+		var note sql.NullString
+		if err := rows.Scan(&e.ID, &e.Date, &e.Type, &weekDays, &note); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		if weekDays.Valid {
+			v := int(weekDays.Int64)
+			e.WeekDays = &v
+		}
+		if note.Valid {
+			e.Note = &note.String
+		}
+		exceptions = append(exceptions, &e)
+	}
+	return exceptions, rows.Err()
+}
+
+// GetCalendarYearPublishedAt returns when the year was published, or nil if
+// it has not been
+func (r *Repo) GetCalendarYearPublishedAt(ctx context.Context, year int) (*time.Time, error) {
+	const op = "repo.GetCalendarYearPublishedAt"
+
+	var publishedAt time.Time
+	err := r.db.QueryRowContext(ctx, `SELECT published_at FROM calendar_years WHERE year = $1`, year).Scan(&publishedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &publishedAt, nil
+}
+
+// PublishCalendarYear replaces the holidays and exceptions of the year and
+// marks it published
+func (r *Repo) PublishCalendarYear(ctx context.Context, year int, req dto.PublishCalendarRequest, publishedBy int64) error {
+	const op = "repo.PublishCalendarYear"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM holidays WHERE EXTRACT(YEAR FROM date) = $1`, year); err != nil {
+		return fmt.Errorf("%s: delete holidays: %w", op, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM calendar_exceptions WHERE EXTRACT(YEAR FROM date) = $1`, year); err != nil {
+		return fmt.Errorf("%s: delete exceptions: %w", op, err)
+	}
+
+	for _, h := range req.Holidays {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO holidays (name, date, type, description) VALUES ($1, $2::date, $3, $4)`,
+			h.Name, h.Date, h.Type, h.Description,
+		); err != nil {
+			if translated := r.translator.Translate(err, op); translated != nil {
+				return translated
+			}
+			return fmt.Errorf("%s: insert holiday: %w", op, err)
+		}
+	}
+
+	for _, e := range req.Exceptions {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO calendar_exceptions (date, type, week_days, note) VALUES ($1::date, $2, $3, $4)`,
+			e.Date, e.Type, e.WeekDays, e.Note,
+		); err != nil {
+			if translated := r.translator.Translate(err, op); translated != nil {
+				return translated
+			}
+			return fmt.Errorf("%s: insert exception: %w", op, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO calendar_years (year, published_at, published_by)
+		VALUES ($1, NOW(), $2)
+		ON CONFLICT (year) DO UPDATE SET published_at = NOW(), published_by = EXCLUDED.published_by`,
+		year, publishedBy,
+	); err != nil {
+		return fmt.Errorf("%s: mark published: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return nil
+}
+
+// --- Work schedules ---
+
+// GetWorkSchedules returns the week schedules set for organizations
+func (r *Repo) GetWorkSchedules(ctx context.Context) ([]*calendar.Schedule, error) {
+	const op = "repo.GetWorkSchedules"
+
+	query := `
+		SELECT s.organization_id, o.name, s.week_days, s.day_hours, s.updated_at
+		FROM organization_work_schedules s
+		JOIN organizations o ON o.id = s.organization_id
+		ORDER BY o.name`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var schedules []*calendar.Schedule
+	for rows.Next() {
+		var s calendar.Schedule
+		var orgID int64
+		var updatedAt time.Time
+		if err := rows.Scan(&orgID, &s.OrganizationName, &s.WeekDays, &s.DayHours, &updatedAt); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		s.OrganizationID = &orgID
+		s.UpdatedAt = &updatedAt
+		schedules = append(schedules, &s)
+	}
+	return schedules, rows.Err()
+}
+
+// GetWorkSchedule returns the week schedule of an organization
+func (r *Repo) GetWorkSchedule(ctx context.Context, organizationID int64) (*calendar.Schedule, error) {
+	const op = "repo.GetWorkSchedule"
+
+	var s calendar.Schedule
+	var updatedAt time.Time
+	err := r.db.QueryRowContext(ctx,
+		`SELECT week_days, day_hours, updated_at FROM organization_work_schedules WHERE organization_id = $1`,
+		organizationID,
+	).Scan(&s.WeekDays, &s.DayHours, &updatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, storage.ErrWorkScheduleNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	s.OrganizationID = &organizationID
+	s.UpdatedAt = &updatedAt
+	return &s, nil
+}
+
+// SetWorkSchedule sets the week schedule of an organization
+func (r *Repo) SetWorkSchedule(ctx context.Context, organizationID int64, req dto.SetWorkScheduleRequest) error {
+	const op = "repo.SetWorkSchedule"
+
+	query := `
+		INSERT INTO organization_work_schedules (organization_id, week_days, day_hours)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			week_days = EXCLUDED.week_days,
+			day_hours = EXCLUDED.day_hours,
+			updated_at = NOW()`
+
+	if _, err := r.db.ExecContext(ctx, query, organizationID, req.WeekDays, req.DayHours); err != nil {
+		if translated := r.translator.Translate(err, op); translated != nil {
+			return translated
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// DeleteWorkSchedule returns an organization to the default week schedule
+func (r *Repo) DeleteWorkSchedule(ctx context.Context, organizationID int64) error {
+	const op = "repo.DeleteWorkSchedule"
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM organization_work_schedules WHERE organization_id = $1`, organizationID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return storage.ErrWorkScheduleNotFound
+	}
+	return nil
+}
+
+// GetEmployeeOrganizations maps employees to their organization: that of the
+// department of their active personnel record, else the one on their contact.
+// Employees with neither are left out.
+func (r *Repo) GetEmployeeOrganizations(ctx context.Context, employeeIDs []int64) (map[int64]int64, error) {
+	const op = "repo.GetEmployeeOrganizations"
+
+	query := `
+		SELECT c.id, COALESCE(d.organization_id, c.organization_id)
+		FROM contacts c
+		LEFT JOIN personnel_records pr ON pr.employee_id = c.id AND pr.status <> 'dismissed'
+		LEFT JOIN departments d ON d.id = pr.department_id
+		WHERE c.id = ANY($1)
+		  AND COALESCE(d.organization_id, c.organization_id) IS NOT NULL`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(employeeIDs))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	orgs := make(map[int64]int64, len(employeeIDs))
+	for rows.Next() {
+		var employeeID, orgID int64
+		if err := rows.Scan(&employeeID, &orgID); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		orgs[employeeID] = orgID
+	}
+	return orgs, rows.Err()
+}
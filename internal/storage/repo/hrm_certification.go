@@ -0,0 +1,383 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/training"
+	"srmt-admin/internal/storage"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// ==================== Certification Types ====================
+
+const certificationTypeSelect = `
+	SELECT ct.id, ct.name, ct.description, ct.validity_months, ct.alert_days,
+		   (SELECT COUNT(*) FROM position_certifications pc WHERE pc.certification_type_id = ct.id),
+		   ct.created_at, ct.updated_at
+	FROM certification_types ct`
+
+func (r *Repo) CreateCertificationType(ctx context.Context, req dto.CreateCertificationTypeRequest) (int64, error) {
+	const op = "repo.CreateCertificationType"
+
+	alertDays := 30
+	if req.AlertDays != nil {
+		alertDays = *req.AlertDays
+	}
+
+	query := `
+		INSERT INTO certification_types (name, description, validity_months, alert_days)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, query, req.Name, req.Description, req.ValidityMonths, alertDays).Scan(&id)
+	if err != nil {
+		return 0, r.translator.Translate(err, op)
+	}
+	return id, nil
+}
+
+func (r *Repo) GetCertificationTypeByID(ctx context.Context, id int64) (*training.CertificationType, error) {
+	const op = "repo.GetCertificationTypeByID"
+
+	ct, err := scanCertificationType(r.db.QueryRowContext(ctx, certificationTypeSelect+" WHERE ct.id = $1", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, storage.ErrCertificationTypeNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ct, nil
+}
+
+func (r *Repo) GetAllCertificationTypes(ctx context.Context) ([]*training.CertificationType, error) {
+	const op = "repo.GetAllCertificationTypes"
+
+	rows, err := r.db.QueryContext(ctx, certificationTypeSelect+" ORDER BY ct.name")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var types []*training.CertificationType
+	for rows.Next() {
+		ct, err := scanCertificationType(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		types = append(types, ct)
+	}
+	return types, rows.Err()
+}
+
+func (r *Repo) UpdateCertificationType(ctx context.Context, id int64, req dto.UpdateCertificationTypeRequest) error {
+	const op = "repo.UpdateCertificationType"
+
+	var setClauses []string
+	var args []interface{}
+	argIdx := 1
+
+	if req.Name != nil {
+		setClauses = append(setClauses, fmt.Sprintf("name = $%d", argIdx))
+		args = append(args, *req.Name)
+		argIdx++
+	}
+	if req.Description != nil {
+		setClauses = append(setClauses, fmt.Sprintf("description = $%d", argIdx))
+		args = append(args, *req.Description)
+		argIdx++
+	}
+	if req.ValidityMonths != nil {
+		setClauses = append(setClauses, fmt.Sprintf("validity_months = $%d", argIdx))
+		args = append(args, *req.ValidityMonths)
+		argIdx++
+	}
+	if req.AlertDays != nil {
+		setClauses = append(setClauses, fmt.Sprintf("alert_days = $%d", argIdx))
+		args = append(args, *req.AlertDays)
+		argIdx++
+	}
+
+	if len(setClauses) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf("UPDATE certification_types SET %s WHERE id = $%d",
+		strings.Join(setClauses, ", "), argIdx)
+	args = append(args, id)
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return r.translator.Translate(err, op)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return storage.ErrCertificationTypeNotFound
+	}
+	return nil
+}
+
+// DeleteCertificationType fails with ErrForeignKeyViolation while positions
+// still require the type
+func (r *Repo) DeleteCertificationType(ctx context.Context, id int64) error {
+	const op = "repo.DeleteCertificationType"
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM certification_types WHERE id = $1", id)
+	if err != nil {
+		return r.translator.Translate(err, op)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return storage.ErrCertificationTypeNotFound
+	}
+	return nil
+}
+
+// ==================== Position Requirements ====================
+
+func (r *Repo) GetPositionRequirements(ctx context.Context, positionID *int64) ([]*training.PositionRequirement, error) {
+	const op = "repo.GetPositionRequirements"
+
+	query := `
+		SELECT pc.position_id, COALESCE(p.name, ''), pc.certification_type_id, ct.name
+		FROM position_certifications pc
+		JOIN certification_types ct ON ct.id = pc.certification_type_id
+		LEFT JOIN positions p ON p.id = pc.position_id`
+
+	var args []interface{}
+	if positionID != nil {
+		query += " WHERE pc.position_id = $1"
+		args = append(args, *positionID)
+	}
+	query += " ORDER BY p.name, ct.name"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var reqs []*training.PositionRequirement
+	for rows.Next() {
+		var pr training.PositionRequirement
+		if err := rows.Scan(&pr.PositionID, &pr.PositionName, &pr.CertificationTypeID, &pr.CertificationTypeName); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		reqs = append(reqs, &pr)
+	}
+	return reqs, rows.Err()
+}
+
+// SetPositionCertifications replaces the certification types a position
+// requires
+func (r *Repo) SetPositionCertifications(ctx context.Context, positionID int64, typeIDs []int64) error {
+	const op = "repo.SetPositionCertifications"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM position_certifications
+		WHERE position_id = $1 AND NOT (certification_type_id = ANY($2))`,
+		positionID, pq.Array(typeIDs)); err != nil {
+		return fmt.Errorf("%s: delete: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO position_certifications (position_id, certification_type_id)
+		SELECT $1, UNNEST($2::bigint[])
+		ON CONFLICT DO NOTHING`,
+		positionID, pq.Array(typeIDs)); err != nil {
+		return r.translator.Translate(err, op)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return nil
+}
+
+// ==================== Compliance ====================
+
+// GetRequiredCertifications lists the certifications the positions of
+// employees on staff require, each with the employee's latest certificate of
+// it: one valid for good first, then the one expiring last.
+func (r *Repo) GetRequiredCertifications(ctx context.Context, filters dto.ComplianceFilters) ([]*training.RequiredCertification, error) {
+	const op = "repo.GetRequiredCertifications"
+
+	query := `
+		SELECT pr.employee_id, COALESCE(c.name, ''), pr.department_id, COALESCE(d.name, ''),
+			   pr.position_id, COALESCE(p.name, ''), ct.id, ct.name, ct.alert_days,
+			   best.id, best.issue_date::text, best.expiry_date::text
+		FROM personnel_records pr
+		JOIN position_certifications pc ON pc.position_id = pr.position_id
+		JOIN certification_types ct ON ct.id = pc.certification_type_id
+		LEFT JOIN contacts c ON c.id = pr.employee_id
+		LEFT JOIN departments d ON d.id = pr.department_id
+		LEFT JOIN positions p ON p.id = pr.position_id
+		LEFT JOIN LATERAL (
+			SELECT cert.id, cert.issue_date, cert.expiry_date
+			FROM certificates cert
+			WHERE cert.employee_id = pr.employee_id AND cert.certification_type_id = ct.id
+			ORDER BY cert.expiry_date DESC NULLS FIRST, cert.issue_date DESC
+			LIMIT 1
+		) best ON TRUE`
+
+	conditions := []string{"pr.status <> 'dismissed'"}
+	var args []interface{}
+	argIdx := 1
+
+	if filters.OrganizationID != nil {
+		conditions = append(conditions, fmt.Sprintf("d.organization_id = $%d", argIdx))
+		args = append(args, *filters.OrganizationID)
+		argIdx++
+	}
+	if filters.DepartmentID != nil {
+		conditions = append(conditions, fmt.Sprintf("pr.department_id = $%d", argIdx))
+		args = append(args, *filters.DepartmentID)
+		argIdx++
+	}
+
+	query += " WHERE " + strings.Join(conditions, " AND ")
+	query += " ORDER BY d.name, c.name, pr.employee_id, ct.name"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var reqs []*training.RequiredCertification
+	for rows.Next() {
+		var rc training.RequiredCertification
+		err := rows.Scan(
+			&rc.EmployeeID, &rc.EmployeeName, &rc.DepartmentID, &rc.DepartmentName,
+			&rc.PositionID, &rc.PositionName, &rc.CertificationTypeID, &rc.CertificationTypeName, &rc.AlertDays,
+			&rc.CertificateID, &rc.IssueDate, &rc.ExpiryDate,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		reqs = append(reqs, &rc)
+	}
+	return reqs, rows.Err()
+}
+
+// GetCertificationTrainings lists the trainings granting any of the
+// certification types that start on or after from and still take
+// participants, earliest first
+func (r *Repo) GetCertificationTrainings(ctx context.Context, from string, typeIDs []int64) ([]*training.CertificationTraining, error) {
+	const op = "repo.GetCertificationTrainings"
+
+	query := `
+		SELECT t.id, t.certification_type_id, t.title, t.start_date::text,
+			   CASE WHEN t.max_participants > 0 THEN t.max_participants - COUNT(tp.id) END,
+			   COALESCE(ARRAY_AGG(tp.employee_id) FILTER (WHERE tp.id IS NOT NULL), '{}')
+		FROM trainings t
+		LEFT JOIN training_participants tp ON tp.training_id = t.id AND tp.status <> 'cancelled'
+		WHERE t.certification_type_id = ANY($2)
+		  AND t.start_date >= $1
+		  AND t.status IN ('planned', 'registration_open')
+		GROUP BY t.id
+		ORDER BY t.start_date, t.id`
+
+	rows, err := r.db.QueryContext(ctx, query, from, pq.Array(typeIDs))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var trainings []*training.CertificationTraining
+	for rows.Next() {
+		var ct training.CertificationTraining
+		if err := rows.Scan(&ct.ID, &ct.CertificationTypeID, &ct.Title, &ct.StartDate,
+			&ct.SeatsLeft, pq.Array(&ct.Enrolled)); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		trainings = append(trainings, &ct)
+	}
+	return trainings, rows.Err()
+}
+
+// GetExpiringCertificates lists the current certificates of required
+// certifications that expire within the alert period of their type as of
+// date and have not been alerted about at their stage yet: not at all, or
+// only as expiring while they have since expired.
+func (r *Repo) GetExpiringCertificates(ctx context.Context, date string) ([]*training.ExpiringCertificate, error) {
+	const op = "repo.GetExpiringCertificates"
+
+	query := `
+		SELECT cert.id, cert.employee_id, COALESCE(c.name, ''), ct.id, ct.name,
+			   cert.expiry_date::text, cert.expiry_alert
+		FROM certificates cert
+		JOIN certification_types ct ON ct.id = cert.certification_type_id
+		JOIN personnel_records pr ON pr.employee_id = cert.employee_id AND pr.status <> 'dismissed'
+		JOIN position_certifications pc ON pc.position_id = pr.position_id AND pc.certification_type_id = ct.id
+		LEFT JOIN contacts c ON c.id = cert.employee_id
+		WHERE cert.expiry_date IS NOT NULL
+		  AND cert.expiry_date <= $1::date + ct.alert_days
+		  AND (cert.expiry_alert IS NULL OR (cert.expiry_alert = 'expiring' AND cert.expiry_date < $1::date))
+		  AND NOT EXISTS (
+			SELECT 1 FROM certificates newer
+			WHERE newer.employee_id = cert.employee_id
+			  AND newer.certification_type_id = cert.certification_type_id
+			  AND newer.id <> cert.id
+			  AND (newer.expiry_date IS NULL OR newer.expiry_date > cert.expiry_date)
+		  )
+		ORDER BY cert.expiry_date, c.name`
+
+	rows, err := r.db.QueryContext(ctx, query, date)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var certs []*training.ExpiringCertificate
+	for rows.Next() {
+		var ec training.ExpiringCertificate
+		if err := rows.Scan(&ec.ID, &ec.EmployeeID, &ec.EmployeeName, &ec.CertificationTypeID,
+			&ec.CertificationTypeName, &ec.ExpiryDate, &ec.Alert); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		certs = append(certs, &ec)
+	}
+	return certs, rows.Err()
+}
+
+// SetCertificateExpiryAlert records the expiry alert last sent about a
+// certificate
+func (r *Repo) SetCertificateExpiryAlert(ctx context.Context, id int64, alert string) error {
+	const op = "repo.SetCertificateExpiryAlert"
+
+	result, err := r.db.ExecContext(ctx, "UPDATE certificates SET expiry_alert = $2 WHERE id = $1", id, alert)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return storage.ErrCertificateNotFound
+	}
+	return nil
+}
+
+func scanCertificationType(s scannable) (*training.CertificationType, error) {
+	var ct training.CertificationType
+	err := s.Scan(
+		&ct.ID, &ct.Name, &ct.Description, &ct.ValidityMonths, &ct.AlertDays,
+		&ct.PositionCount, &ct.CreatedAt, &ct.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &ct, nil
+}
@@ -21,6 +21,21 @@ func (r *Repo) CreateHRDocument(ctx context.Context, req dto.CreateHRDocumentReq
 	}
 	defer tx.Rollback()
 
+	id, err := r.insertHRDocument(ctx, tx, req, createdBy)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return id, nil
+}
+
+// insertHRDocument writes a document with its signatures in tx
+func (r *Repo) insertHRDocument(ctx context.Context, tx *sql.Tx, req dto.CreateHRDocumentRequest, createdBy int64) (int64, error) {
+	const op = "repo.CreateHRDocument"
+
 	query := `
 		INSERT INTO hr_documents (title, type, category, number, date, content, file_url,
 			department_id, employee_id, created_by)
@@ -28,7 +43,7 @@ func (r *Repo) CreateHRDocument(ctx context.Context, req dto.CreateHRDocumentReq
 		RETURNING id`
 
 	var id int64
-	err = tx.QueryRowContext(ctx, query,
+	err := tx.QueryRowContext(ctx, query,
 		req.Title, req.Type, req.Category, req.Number, req.Date,
 		req.Content, req.FileURL, req.DepartmentID, req.EmployeeID, createdBy,
 	).Scan(&id)
@@ -45,13 +60,12 @@ func (r *Repo) CreateHRDocument(ctx context.Context, req dto.CreateHRDocumentReq
 			id, sig.SignerID, sig.Order,
 		)
 		if err != nil {
+			if translated := r.translator.Translate(err, op); translated != nil {
+				return 0, translated
+			}
 			return 0, fmt.Errorf("%s: insert signature: %w", op, err)
 		}
 	}
-
-	if err := tx.Commit(); err != nil {
-		return 0, fmt.Errorf("%s: commit: %w", op, err)
-	}
 	return id, nil
 }
 
@@ -0,0 +1,688 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/lifecycle"
+	"srmt-admin/internal/lib/model/hrm/salary"
+	"srmt-admin/internal/storage"
+	"strings"
+)
+
+// ==================== Lifecycle Orders ====================
+
+const hrOrderSelect = `
+		SELECT o.id, o.kind, o.number, o.order_date::text, o.effective_date::text, o.status,
+			   o.employee_id, COALESCE(c.fio, ''), o.record_id, o.document_id, o.reason,
+			   o.offer_id, o.onboarding_id, o.user_id,
+			   o.from_department_id, o.to_department_id, o.from_position_id, o.to_position_id,
+			   o.salary, o.transfer_id, o.settlement,
+			   o.created_by, o.applied_at, o.created_at, o.updated_at
+		FROM hr_orders o
+		JOIN contacts c ON o.employee_id = c.id`
+
+// CreateHireOrder hires the candidate of an accepted offer: it creates the
+// employee's contact in the department and position of the vacancy, the
+// personnel record, the salary structure, the user account and the
+// onboarding checklist, marks the candidate hired, and writes the order and
+// its document, all at once. Returns the order.
+func (r *Repo) CreateHireOrder(ctx context.Context, h *lifecycle.Hire, doc dto.CreateHRDocumentRequest) (int64, error) {
+	const op = "repo.CreateHireOrder"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var (
+		status                   string
+		candidateID, vacancyID   int64
+		name                     string
+		email, phone             *string
+		departmentID, positionID int64
+		organizationID           *int64
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT o.status, o.candidate_id, o.vacancy_id, c.name, c.email, c.phone,
+			   v.department_id, v.position_id, d.organization_id
+		FROM job_offers o
+		JOIN candidates c ON o.candidate_id = c.id
+		JOIN vacancies v ON o.vacancy_id = v.id
+		JOIN departments d ON v.department_id = d.id
+		WHERE o.id = $1
+		FOR UPDATE OF o`, h.OfferID,
+	).Scan(&status, &candidateID, &vacancyID, &name, &email, &phone, &departmentID, &positionID, &organizationID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, storage.ErrOfferNotFound
+		}
+		return 0, fmt.Errorf("%s: get offer: %w", op, err)
+	}
+	if status != "accepted" {
+		return 0, storage.ErrOfferNotAccepted
+	}
+
+	var hired bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM hr_orders WHERE offer_id = $1)`, h.OfferID).Scan(&hired)
+	if err != nil {
+		return 0, fmt.Errorf("%s: check offer: %w", op, err)
+	}
+	if hired {
+		return 0, storage.ErrOfferAlreadyHired
+	}
+
+	var employeeID int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO contacts (fio, email, phone, organization_id, department_id, position_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		name, email, phone, organizationID, departmentID, positionID,
+	).Scan(&employeeID)
+	if err != nil {
+		return 0, r.translator.Translate(err, op)
+	}
+
+	var recordID int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO personnel_records (employee_id, tab_number, hire_date, department_id, position_id,
+			contract_type, contract_end_date, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 'active')
+		RETURNING id`,
+		employeeID, h.TabNumber, h.HireDate, departmentID, positionID, h.ContractType, h.ContractEndDate,
+	).Scan(&recordID)
+	if err != nil {
+		return 0, r.translator.Translate(err, op)
+	}
+
+	if h.BaseSalary != nil {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO salary_structures (employee_id, base_salary, effective_from) VALUES ($1, $2, $3)`,
+			employeeID, *h.BaseSalary, h.HireDate,
+		)
+		if err != nil {
+			return 0, r.translator.Translate(err, op)
+		}
+	}
+
+	var userID *int64
+	if h.Login != nil {
+		var id int64
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO users (login, pass_hash, contact_id, is_active)
+			VALUES ($1, $2, $3, TRUE)
+			RETURNING id`,
+			*h.Login, h.PasswordHash, employeeID,
+		).Scan(&id)
+		if err != nil {
+			return 0, r.translator.Translate(err, op)
+		}
+		userID = &id
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO users_roles (user_id, role_id)
+			SELECT $1, id FROM roles WHERE name = 'hrm_employee'
+			ON CONFLICT DO NOTHING`,
+			id,
+		)
+		if err != nil {
+			return 0, r.translator.Translate(err, op)
+		}
+	}
+
+	var onboardingID int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO onboardings (candidate_id, vacancy_id, employee_id, offer_id, start_date, mentor_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		candidateID, vacancyID, employeeID, h.OfferID, h.HireDate, h.MentorID,
+	).Scan(&onboardingID)
+	if err != nil {
+		return 0, r.translator.Translate(err, op)
+	}
+	for i, t := range h.Checklist {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO onboarding_tasks (onboarding_id, title, assigned_to, due_date, sort_order)
+			VALUES ($1, $2, $3, $4, $5)`,
+			onboardingID, t.Title, t.AssignedTo, t.DueDate, i+1,
+		)
+		if err != nil {
+			return 0, r.translator.Translate(err, op)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE candidates SET status = 'hired', stage = 'onboarding' WHERE id = $1`, candidateID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: update candidate: %w", op, err)
+	}
+
+	order := &lifecycle.Order{
+		Kind:          lifecycle.KindHire,
+		OrderDate:     h.OrderDate,
+		EffectiveDate: h.HireDate,
+		Status:        lifecycle.StatusApplied,
+		EmployeeID:    employeeID,
+		RecordID:      &recordID,
+		Reason:        h.Reason,
+		OfferID:       &h.OfferID,
+		OnboardingID:  &onboardingID,
+		UserID:        userID,
+		CreatedBy:     &h.CreatedBy,
+	}
+	doc.EmployeeID = &employeeID
+	doc.DepartmentID = &departmentID
+
+	id, err := r.insertHROrder(ctx, tx, order, doc)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return id, nil
+}
+
+// CreateHROrder writes a transfer or dismissal order with its document to
+// wait for its effective date. A dismissal sets the dismissal date of the
+// personnel record right away, so vacation accrual stops at the last day.
+func (r *Repo) CreateHROrder(ctx context.Context, o *lifecycle.Order, doc dto.CreateHRDocumentRequest) (int64, error) {
+	const op = "repo.CreateHROrder"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// Locking the record serializes orders of the employee
+	var recordID, departmentID int64
+	var status string
+	err = tx.QueryRowContext(ctx,
+		`SELECT id, department_id, status FROM personnel_records WHERE employee_id = $1 FOR UPDATE`, o.EmployeeID,
+	).Scan(&recordID, &departmentID, &status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, storage.ErrPersonnelRecordNotFound
+		}
+		return 0, fmt.Errorf("%s: get personnel record: %w", op, err)
+	}
+	if status == "dismissed" {
+		return 0, storage.ErrEmployeeDismissed
+	}
+
+	var scheduled bool
+	err = tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM hr_orders WHERE employee_id = $1 AND status = 'scheduled')`, o.EmployeeID,
+	).Scan(&scheduled)
+	if err != nil {
+		return 0, fmt.Errorf("%s: check scheduled orders: %w", op, err)
+	}
+	if scheduled {
+		return 0, storage.ErrHROrderScheduled
+	}
+
+	if o.Kind == lifecycle.KindDismissal {
+		_, err = tx.ExecContext(ctx,
+			`UPDATE personnel_records SET dismissal_date = $2 WHERE id = $1`, recordID, o.EffectiveDate)
+		if err != nil {
+			return 0, fmt.Errorf("%s: set dismissal date: %w", op, err)
+		}
+	}
+
+	o.RecordID = &recordID
+	o.Status = lifecycle.StatusScheduled
+	doc.EmployeeID = &o.EmployeeID
+	doc.DepartmentID = &departmentID
+
+	id, err := r.insertHROrder(ctx, tx, o, doc)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return id, nil
+}
+
+// ApplyTransferOrder moves the employee to the department and position of
+// a scheduled transfer, in the personnel record and the contact alike, adds
+// the move to the transfer history, and puts the new salary structure in
+// effect from the transfer date
+func (r *Repo) ApplyTransferOrder(ctx context.Context, id int64) error {
+	const op = "repo.ApplyTransferOrder"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	o, err := lockScheduledHROrder(ctx, tx, id, lifecycle.KindTransfer)
+	if err != nil {
+		return err
+	}
+	if o.RecordID == nil {
+		return fmt.Errorf("%s: order %d has no personnel record", op, id)
+	}
+
+	var fromDepartmentID, fromPositionID int64
+	err = tx.QueryRowContext(ctx,
+		`SELECT department_id, position_id FROM personnel_records WHERE id = $1 FOR UPDATE`, *o.RecordID,
+	).Scan(&fromDepartmentID, &fromPositionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return storage.ErrPersonnelRecordNotFound
+		}
+		return fmt.Errorf("%s: get personnel record: %w", op, err)
+	}
+
+	var transferID *int64
+	if o.ToDepartmentID != nil || o.ToPositionID != nil {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE personnel_records
+			SET department_id = COALESCE($2, department_id), position_id = COALESCE($3, position_id)
+			WHERE id = $1`,
+			*o.RecordID, o.ToDepartmentID, o.ToPositionID,
+		)
+		if err != nil {
+			return r.translator.Translate(err, op)
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE contacts
+			SET department_id = COALESCE($2, department_id), position_id = COALESCE($3, position_id)
+			WHERE id = $1`,
+			o.EmployeeID, o.ToDepartmentID, o.ToPositionID,
+		)
+		if err != nil {
+			return r.translator.Translate(err, op)
+		}
+
+		toDepartmentID, toPositionID := fromDepartmentID, fromPositionID
+		if o.ToDepartmentID != nil {
+			toDepartmentID = *o.ToDepartmentID
+		}
+		if o.ToPositionID != nil {
+			toPositionID = *o.ToPositionID
+		}
+		var tid int64
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO personnel_transfers (record_id, from_department_id, to_department_id,
+				from_position_id, to_position_id, transfer_date, order_number, reason)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id`,
+			*o.RecordID, fromDepartmentID, toDepartmentID, fromPositionID, toPositionID,
+			o.EffectiveDate, o.Number, o.Reason,
+		).Scan(&tid)
+		if err != nil {
+			return r.translator.Translate(err, op)
+		}
+		transferID = &tid
+	}
+
+	if o.Salary != nil {
+		// The structure in effect on the date ends the day before; a later
+		// one, if any, ends the new one
+		_, err = tx.ExecContext(ctx, `
+			UPDATE salary_structures
+			SET effective_to = $2::date - 1
+			WHERE employee_id = $1 AND effective_from < $2::date
+			  AND (effective_to IS NULL OR effective_to >= $2::date)`,
+			o.EmployeeID, o.EffectiveDate,
+		)
+		if err != nil {
+			return fmt.Errorf("%s: close salary structure: %w", op, err)
+		}
+		sc := o.Salary
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO salary_structures (employee_id, base_salary, regional_allowance, seniority_allowance,
+				qualification_allowance, hazard_allowance, night_shift_allowance, effective_from, effective_to)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8::date,
+				(SELECT MIN(effective_from) - 1 FROM salary_structures WHERE employee_id = $1 AND effective_from > $8::date))
+			ON CONFLICT (employee_id, effective_from) DO UPDATE SET
+				base_salary = EXCLUDED.base_salary,
+				regional_allowance = EXCLUDED.regional_allowance,
+				seniority_allowance = EXCLUDED.seniority_allowance,
+				qualification_allowance = EXCLUDED.qualification_allowance,
+				hazard_allowance = EXCLUDED.hazard_allowance,
+				night_shift_allowance = EXCLUDED.night_shift_allowance,
+				effective_to = EXCLUDED.effective_to`,
+			o.EmployeeID, sc.BaseSalary, sc.RegionalAllowance, sc.SeniorityAllowance,
+			sc.QualificationAllow, sc.HazardAllowance, sc.NightShiftAllowance, o.EffectiveDate,
+		)
+		if err != nil {
+			return r.translator.Translate(err, op)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE hr_orders
+		SET status = 'applied', applied_at = NOW(), transfer_id = $2,
+			from_department_id = $3, from_position_id = $4
+		WHERE id = $1`,
+		id, transferID, fromDepartmentID, fromPositionID,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: mark applied: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return nil
+}
+
+// ApplyDismissalOrder dismisses the employee of a scheduled dismissal:
+// their access cards are deactivated, their user account disabled and an
+// unfinished onboarding cancelled. The final settlement is kept with the
+// order.
+func (r *Repo) ApplyDismissalOrder(ctx context.Context, id int64, settlement *salary.Settlement) error {
+	const op = "repo.ApplyDismissalOrder"
+
+	var settlementJSON []byte
+	if settlement != nil {
+		var err error
+		if settlementJSON, err = json.Marshal(settlement); err != nil {
+			return fmt.Errorf("%s: marshal settlement: %w", op, err)
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	o, err := lockScheduledHROrder(ctx, tx, id, lifecycle.KindDismissal)
+	if err != nil {
+		return err
+	}
+
+	steps := []struct {
+		name  string
+		query string
+		args  []interface{}
+	}{
+		{"dismiss", `UPDATE personnel_records SET status = 'dismissed', dismissal_date = $2 WHERE employee_id = $1`,
+			[]interface{}{o.EmployeeID, o.EffectiveDate}},
+		{"deactivate access cards", `UPDATE access_cards SET status = 'deactivated' WHERE employee_id = $1 AND status IN ('active', 'blocked')`,
+			[]interface{}{o.EmployeeID}},
+		{"deactivate user", `UPDATE users SET is_active = FALSE WHERE contact_id = $1`,
+			[]interface{}{o.EmployeeID}},
+		{"cancel onboarding", `UPDATE onboardings SET status = 'cancelled' WHERE employee_id = $1 AND status IN ('pending', 'in_progress')`,
+			[]interface{}{o.EmployeeID}},
+		{"mark applied", `UPDATE hr_orders SET status = 'applied', applied_at = NOW(), settlement = $2 WHERE id = $1`,
+			[]interface{}{id, settlementJSON}},
+	}
+	for _, st := range steps {
+		if _, err := tx.ExecContext(ctx, st.query, st.args...); err != nil {
+			return fmt.Errorf("%s: %s: %w", op, st.name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return nil
+}
+
+// CancelHROrder calls off a scheduled transfer or dismissal and its
+// document. A dismissal's date is taken off the personnel record.
+func (r *Repo) CancelHROrder(ctx context.Context, id int64) error {
+	const op = "repo.CancelHROrder"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	o, err := lockScheduledHROrder(ctx, tx, id, "")
+	if err != nil {
+		return err
+	}
+
+	if o.Kind == lifecycle.KindDismissal {
+		_, err = tx.ExecContext(ctx,
+			`UPDATE personnel_records SET dismissal_date = NULL WHERE employee_id = $1 AND status <> 'dismissed'`,
+			o.EmployeeID)
+		if err != nil {
+			return fmt.Errorf("%s: clear dismissal date: %w", op, err)
+		}
+	}
+	if o.DocumentID != nil {
+		_, err = tx.ExecContext(ctx, `UPDATE hr_documents SET status = 'cancelled' WHERE id = $1`, *o.DocumentID)
+		if err != nil {
+			return fmt.Errorf("%s: cancel document: %w", op, err)
+		}
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE hr_orders SET status = 'cancelled' WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return nil
+}
+
+func (r *Repo) GetHROrderByID(ctx context.Context, id int64) (*lifecycle.Order, error) {
+	const op = "repo.GetHROrderByID"
+
+	o, err := scanHROrder(r.db.QueryRowContext(ctx, hrOrderSelect+" WHERE o.id = $1", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, storage.ErrHROrderNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return o, nil
+}
+
+func (r *Repo) GetAllHROrders(ctx context.Context, filters dto.HROrderFilters) ([]*lifecycle.Order, error) {
+	const op = "repo.GetAllHROrders"
+
+	query := hrOrderSelect
+	var conditions []string
+	var args []interface{}
+	argIdx := 1
+
+	if filters.EmployeeID != nil {
+		conditions = append(conditions, fmt.Sprintf("o.employee_id = $%d", argIdx))
+		args = append(args, *filters.EmployeeID)
+		argIdx++
+	}
+	if filters.Kind != nil {
+		conditions = append(conditions, fmt.Sprintf("o.kind = $%d", argIdx))
+		args = append(args, *filters.Kind)
+		argIdx++
+	}
+	if filters.Status != nil {
+		conditions = append(conditions, fmt.Sprintf("o.status = $%d", argIdx))
+		args = append(args, *filters.Status)
+		argIdx++
+	}
+	if filters.Year != nil {
+		conditions = append(conditions, fmt.Sprintf("o.year = $%d", argIdx))
+		args = append(args, *filters.Year)
+		argIdx++
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY o.order_date DESC, o.id DESC"
+
+	return r.queryHROrders(ctx, op, query, args...)
+}
+
+// GetScheduledHROrders lists the orders waiting to take effect on or before
+// until (YYYY-MM-DD), earliest first
+func (r *Repo) GetScheduledHROrders(ctx context.Context, until string) ([]*lifecycle.Order, error) {
+	const op = "repo.GetScheduledHROrders"
+
+	query := hrOrderSelect + `
+		WHERE o.status = 'scheduled' AND o.effective_date <= $1::date
+		ORDER BY o.effective_date, o.id`
+
+	return r.queryHROrders(ctx, op, query, until)
+}
+
+// GetPlacementNames names a department and a position for order texts
+func (r *Repo) GetPlacementNames(ctx context.Context, departmentID, positionID int64) (string, string, error) {
+	const op = "repo.GetPlacementNames"
+
+	var department, position string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT d.name, p.name FROM departments d, positions p WHERE d.id = $1 AND p.id = $2`,
+		departmentID, positionID,
+	).Scan(&department, &position)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", "", storage.ErrNotFound
+		}
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+	return department, position, nil
+}
+
+func (r *Repo) queryHROrders(ctx context.Context, op, query string, args ...interface{}) ([]*lifecycle.Order, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var orders []*lifecycle.Order
+	for rows.Next() {
+		o, err := scanHROrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		orders = append(orders, o)
+	}
+	return orders, rows.Err()
+}
+
+// insertHROrder numbers the order within the year of its date, writes its
+// document under that number and then the order itself. The advisory lock
+// serializes numbering until the caller's transaction ends.
+func (r *Repo) insertHROrder(ctx context.Context, tx *sql.Tx, o *lifecycle.Order, doc dto.CreateHRDocumentRequest) (int64, error) {
+	const op = "repo.insertHROrder"
+
+	year, err := yearOf(o.OrderDate)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('hr_orders'))`); err != nil {
+		return 0, fmt.Errorf("%s: failed to lock numbering: %w", op, err)
+	}
+	var seq int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(NULLIF(substring(number FROM '^[0-9]+'), '')::int), 0) + 1
+		FROM hr_orders
+		WHERE year = $1`, year,
+	).Scan(&seq)
+	if err != nil {
+		return 0, fmt.Errorf("%s: next number: %w", op, err)
+	}
+	o.Number = fmt.Sprintf("%d-к", seq)
+
+	var createdBy int64
+	if o.CreatedBy != nil {
+		createdBy = *o.CreatedBy
+	}
+	doc.Number = o.Number
+	doc.Date = o.OrderDate
+	documentID, err := r.insertHRDocument(ctx, tx, doc, createdBy)
+	if err != nil {
+		return 0, err
+	}
+	o.DocumentID = &documentID
+
+	var salaryJSON []byte
+	if o.Salary != nil {
+		if salaryJSON, err = json.Marshal(o.Salary); err != nil {
+			return 0, fmt.Errorf("%s: marshal salary: %w", op, err)
+		}
+	}
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO hr_orders (kind, year, number, order_date, effective_date, status, employee_id,
+			record_id, document_id, offer_id, onboarding_id, user_id,
+			from_department_id, to_department_id, from_position_id, to_position_id, salary,
+			reason, created_by, applied_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19,
+			CASE WHEN $20 THEN NOW() END)
+		RETURNING id`,
+		o.Kind, year, o.Number, o.OrderDate, o.EffectiveDate, o.Status, o.EmployeeID,
+		o.RecordID, o.DocumentID, o.OfferID, o.OnboardingID, o.UserID,
+		o.FromDepartmentID, o.ToDepartmentID, o.FromPositionID, o.ToPositionID, salaryJSON,
+		o.Reason, o.CreatedBy, o.Status == lifecycle.StatusApplied,
+	).Scan(&id)
+	if err != nil {
+		return 0, r.translator.Translate(err, op)
+	}
+	return id, nil
+}
+
+// lockScheduledHROrder locks an order to take effect or be cancelled. It
+// must be scheduled and, unless kind is empty, of that kind.
+func lockScheduledHROrder(ctx context.Context, tx *sql.Tx, id int64, kind string) (*lifecycle.Order, error) {
+	o, err := scanHROrder(tx.QueryRowContext(ctx, hrOrderSelect+" WHERE o.id = $1 FOR UPDATE OF o", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, storage.ErrHROrderNotFound
+		}
+		return nil, fmt.Errorf("lock hr order: %w", err)
+	}
+	if o.Status != lifecycle.StatusScheduled || (kind != "" && o.Kind != kind) {
+		return nil, storage.ErrInvalidStatus
+	}
+	return o, nil
+}
+
+func yearOf(date string) (int, error) {
+	var year int
+	if _, err := fmt.Sscanf(date, "%4d-", &year); err != nil {
+		return 0, fmt.Errorf("invalid date %q", date)
+	}
+	return year, nil
+}
+
+func scanHROrder(s scannable) (*lifecycle.Order, error) {
+	var o lifecycle.Order
+	var salaryJSON, settlementJSON []byte
+	err := s.Scan(
+		&o.ID, &o.Kind, &o.Number, &o.OrderDate, &o.EffectiveDate, &o.Status,
+		&o.EmployeeID, &o.EmployeeName, &o.RecordID, &o.DocumentID, &o.Reason,
+		&o.OfferID, &o.OnboardingID, &o.UserID,
+		&o.FromDepartmentID, &o.ToDepartmentID, &o.FromPositionID, &o.ToPositionID,
+		&salaryJSON, &o.TransferID, &settlementJSON,
+		&o.CreatedBy, &o.AppliedAt, &o.CreatedAt, &o.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if salaryJSON != nil {
+		o.Salary = &lifecycle.SalaryChange{}
+		if err := json.Unmarshal(salaryJSON, o.Salary); err != nil {
+			return nil, fmt.Errorf("unmarshal salary: %w", err)
+		}
+	}
+	if settlementJSON != nil {
+		o.Settlement = &salary.Settlement{}
+		if err := json.Unmarshal(settlementJSON, o.Settlement); err != nil {
+			return nil, fmt.Errorf("unmarshal settlement: %w", err)
+		}
+	}
+	return &o, nil
+}
@@ -63,3 +63,18 @@ func (r *Repo) MarkAllHRMNotificationsRead(ctx context.Context, userID int64) er
 	}
 	return nil
 }
+
+// CreateHRMNotification stores a notification for the contact; it shows up in
+// /my-notifications.
+func (r *Repo) CreateHRMNotification(ctx context.Context, contactID int64, title, message, typ string, link *string) (int64, error) {
+	const op = "repo.CreateHRMNotification"
+
+	var id int64
+	err := r.db.QueryRowContext(ctx,
+		"INSERT INTO hrm_notifications (user_id, title, message, type, link) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+		contactID, title, message, typ, link).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,169 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/salary"
+	"srmt-admin/internal/storage"
+
+	"github.com/lib/pq"
+)
+
+// --- Payroll Rates ---
+
+// GetPayrollRates returns every payroll rate, latest first within a code
+func (r *Repo) GetPayrollRates(ctx context.Context) ([]*salary.PayrollRate, error) {
+	const op = "repo.GetPayrollRates"
+
+	query := `
+		SELECT id, code, rate, effective_from::text, note, created_by, created_at
+		FROM payroll_rates
+		ORDER BY code, effective_from DESC`
+
+	return r.queryPayrollRates(ctx, op, query)
+}
+
+// GetEffectivePayrollRates returns the rate of each code in effect on a date
+// (YYYY-MM-DD)
+func (r *Repo) GetEffectivePayrollRates(ctx context.Context, onDate string) ([]*salary.PayrollRate, error) {
+	const op = "repo.GetEffectivePayrollRates"
+
+	query := `
+		SELECT DISTINCT ON (code) id, code, rate, effective_from::text, note, created_by, created_at
+		FROM payroll_rates
+		WHERE effective_from <= $1::date
+		ORDER BY code, effective_from DESC`
+
+	return r.queryPayrollRates(ctx, op, query, onDate)
+}
+
+func (r *Repo) queryPayrollRates(ctx context.Context, op, query string, args ...interface{}) ([]*salary.PayrollRate, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var rates []*salary.PayrollRate
+	for rows.Next() {
+		var pr salary.PayrollRate
+		var (
+			note      sql.NullString
+			createdBy sql.NullInt64
+		)
+		if err := rows.Scan(&pr.ID, &pr.Code, &pr.Rate, &pr.EffectiveFrom, &note, &createdBy, &pr.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		if note.Valid {
+			pr.Note = &note.String
+		}
+		if createdBy.Valid {
+			pr.CreatedBy = &createdBy.Int64
+		}
+		rates = append(rates, &pr)
+	}
+	return rates, rows.Err()
+}
+
+func (r *Repo) CreatePayrollRate(ctx context.Context, req dto.CreatePayrollRateRequest, createdBy int64) (int64, error) {
+	const op = "repo.CreatePayrollRate"
+
+	query := `
+		INSERT INTO payroll_rates (code, rate, effective_from, note, created_by)
+		VALUES ($1, $2, $3::date, $4, $5)
+		RETURNING id`
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, query, req.Code, req.Rate, req.EffectiveFrom, req.Note, createdBy).Scan(&id)
+	if err != nil {
+		if translated := r.translator.Translate(err, op); translated != nil {
+			if errors.Is(translated, storage.ErrDuplicate) {
+				return 0, storage.ErrPayrollRateExists
+			}
+			return 0, translated
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (r *Repo) DeletePayrollRate(ctx context.Context, id int64) error {
+	const op = "repo.DeletePayrollRate"
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM payroll_rates WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return storage.ErrPayrollRateNotFound
+	}
+	return nil
+}
+
+// --- Bank Accounts ---
+
+// GetBankAccounts returns the bank accounts of the employees, or of everyone
+// when employeeIDs is nil
+func (r *Repo) GetBankAccounts(ctx context.Context, employeeIDs []int64) ([]*salary.BankAccount, error) {
+	const op = "repo.GetBankAccounts"
+
+	query := `
+		SELECT a.employee_id, COALESCE(c.fio, ''), a.bank_name, a.bank_code, a.account_number, a.updated_at
+		FROM employee_bank_accounts a
+		JOIN contacts c ON c.id = a.employee_id
+		WHERE $1::bigint[] IS NULL OR a.employee_id = ANY($1)
+		ORDER BY c.fio`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(employeeIDs))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var accounts []*salary.BankAccount
+	for rows.Next() {
+		var a salary.BankAccount
+		if err := rows.Scan(&a.EmployeeID, &a.EmployeeName, &a.BankName, &a.BankCode, &a.AccountNumber, &a.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		accounts = append(accounts, &a)
+	}
+	return accounts, rows.Err()
+}
+
+func (r *Repo) SetBankAccount(ctx context.Context, employeeID int64, req dto.SetBankAccountRequest) error {
+	const op = "repo.SetBankAccount"
+
+	query := `
+		INSERT INTO employee_bank_accounts (employee_id, bank_name, bank_code, account_number)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (employee_id) DO UPDATE SET
+			bank_name = EXCLUDED.bank_name,
+			bank_code = EXCLUDED.bank_code,
+			account_number = EXCLUDED.account_number,
+			updated_at = NOW()`
+
+	if _, err := r.db.ExecContext(ctx, query, employeeID, req.BankName, req.BankCode, req.AccountNumber); err != nil {
+		if translated := r.translator.Translate(err, op); translated != nil {
+			return translated
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *Repo) DeleteBankAccount(ctx context.Context, employeeID int64) error {
+	const op = "repo.DeleteBankAccount"
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM employee_bank_accounts WHERE employee_id = $1`, employeeID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return storage.ErrBankAccountNotFound
+	}
+	return nil
+}
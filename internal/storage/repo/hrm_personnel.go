@@ -16,14 +16,14 @@ func (r *Repo) CreatePersonnelRecord(ctx context.Context, req dto.CreatePersonne
 	const op = "repo.CreatePersonnelRecord"
 
 	query := `
-		INSERT INTO personnel_records (employee_id, tab_number, hire_date, department_id, position_id, contract_type, contract_end_date, status)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO personnel_records (employee_id, tab_number, hire_date, department_id, position_id, contract_type, contract_end_date, dismissal_date, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id`
 
 	var id int64
 	err := r.db.QueryRowContext(ctx, query,
 		req.EmployeeID, req.TabNumber, req.HireDate, req.DepartmentID,
-		req.PositionID, req.ContractType, req.ContractEndDate, req.Status,
+		req.PositionID, req.ContractType, req.ContractEndDate, req.DismissalDate, req.Status,
 	).Scan(&id)
 	if err != nil {
 		if translated := r.translator.Translate(err, op); translated != nil {
@@ -40,7 +40,7 @@ func (r *Repo) GetPersonnelRecordByID(ctx context.Context, id int64) (*personnel
 	query := `
 		SELECT pr.id, pr.employee_id, c.fio, pr.tab_number,
 			   pr.hire_date::text, pr.department_id, d.name, pr.position_id, p.name,
-			   pr.contract_type, pr.contract_end_date::text, pr.status, pr.created_at, pr.updated_at
+			   pr.contract_type, pr.contract_end_date::text, pr.dismissal_date::text, pr.status, pr.created_at, pr.updated_at
 		FROM personnel_records pr
 		JOIN contacts c ON pr.employee_id = c.id
 		JOIN departments d ON pr.department_id = d.id
@@ -63,7 +63,7 @@ func (r *Repo) GetPersonnelRecordByEmployeeID(ctx context.Context, employeeID in
 	query := `
 		SELECT pr.id, pr.employee_id, c.fio, pr.tab_number,
 			   pr.hire_date::text, pr.department_id, d.name, pr.position_id, p.name,
-			   pr.contract_type, pr.contract_end_date::text, pr.status, pr.created_at, pr.updated_at
+			   pr.contract_type, pr.contract_end_date::text, pr.dismissal_date::text, pr.status, pr.created_at, pr.updated_at
 		FROM personnel_records pr
 		JOIN contacts c ON pr.employee_id = c.id
 		JOIN departments d ON pr.department_id = d.id
@@ -86,7 +86,7 @@ func (r *Repo) GetAllPersonnelRecords(ctx context.Context, filters dto.Personnel
 	query := `
 		SELECT pr.id, pr.employee_id, c.fio, pr.tab_number,
 			   pr.hire_date::text, pr.department_id, d.name, pr.position_id, p.name,
-			   pr.contract_type, pr.contract_end_date::text, pr.status, pr.created_at, pr.updated_at
+			   pr.contract_type, pr.contract_end_date::text, pr.dismissal_date::text, pr.status, pr.created_at, pr.updated_at
 		FROM personnel_records pr
 		JOIN contacts c ON pr.employee_id = c.id
 		JOIN departments d ON pr.department_id = d.id
@@ -179,6 +179,11 @@ func (r *Repo) UpdatePersonnelRecord(ctx context.Context, id int64, req dto.Edit
 		args = append(args, *req.ContractEndDate)
 		argIdx++
 	}
+	if req.DismissalDate != nil {
+		setClauses = append(setClauses, fmt.Sprintf("dismissal_date = $%d", argIdx))
+		args = append(args, *req.DismissalDate)
+		argIdx++
+	}
 	if req.Status != nil {
 		setClauses = append(setClauses, fmt.Sprintf("status = $%d", argIdx))
 		args = append(args, *req.Status)
@@ -287,13 +292,13 @@ func scanPersonnelRecord(scanner interface {
 	Scan(dest ...interface{}) error
 }) (*personnel.Record, error) {
 	var rec personnel.Record
-	var contractEndDate sql.NullString
+	var contractEndDate, dismissalDate sql.NullString
 
 	err := scanner.Scan(
 		&rec.ID, &rec.EmployeeID, &rec.EmployeeName, &rec.TabNumber,
 		&rec.HireDate, &rec.DepartmentID, &rec.DepartmentName,
 		&rec.PositionID, &rec.PositionName,
-		&rec.ContractType, &contractEndDate, &rec.Status,
+		&rec.ContractType, &contractEndDate, &dismissalDate, &rec.Status,
 		&rec.CreatedAt, &rec.UpdatedAt,
 	)
 	if err != nil {
@@ -302,5 +307,8 @@ func scanPersonnelRecord(scanner interface {
 	if contractEndDate.Valid {
 		rec.ContractEndDate = &contractEndDate.String
 	}
+	if dismissalDate.Valid {
+		rec.DismissalDate = &dismissalDate.String
+	}
 	return &rec, nil
 }
@@ -10,6 +10,8 @@ import (
 	"srmt-admin/internal/storage"
 	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // ==================== Vacancies ====================
@@ -819,6 +821,307 @@ func (r *Repo) GetInterviewsByCandidate(ctx context.Context, candidateID int64)
 	return r.GetAllInterviews(ctx, dto.InterviewFilters{CandidateID: &candidateID})
 }
 
+// ==================== Offers ====================
+
+const jobOfferSelect = `
+		SELECT o.id, o.candidate_id, c.name, o.vacancy_id, v.title, v.department_id, v.position_id,
+			   o.salary_offered, o.start_date::text, o.status, o.notes, o.decided_at,
+			   o.created_at, o.updated_at
+		FROM job_offers o
+		JOIN candidates c ON o.candidate_id = c.id
+		JOIN vacancies v ON o.vacancy_id = v.id`
+
+// CreateJobOffer makes an offer for the vacancy the candidate applied to
+func (r *Repo) CreateJobOffer(ctx context.Context, req dto.CreateJobOfferRequest) (int64, error) {
+	const op = "repo.CreateJobOffer"
+
+	query := `
+		INSERT INTO job_offers (candidate_id, vacancy_id, salary_offered, start_date, notes)
+		SELECT c.id, c.vacancy_id, $2, $3, $4
+		FROM candidates c
+		WHERE c.id = $1
+		RETURNING id`
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, query,
+		req.CandidateID, req.SalaryOffered, req.StartDate, req.Notes,
+	).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, storage.ErrCandidateNotFound
+		}
+		if translated := r.translator.Translate(err, op); translated != nil {
+			return 0, translated
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (r *Repo) GetJobOfferByID(ctx context.Context, id int64) (*recruiting.JobOffer, error) {
+	const op = "repo.GetJobOfferByID"
+
+	offer, err := scanJobOffer(r.db.QueryRowContext(ctx, jobOfferSelect+" WHERE o.id = $1", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, storage.ErrOfferNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return offer, nil
+}
+
+func (r *Repo) GetAllJobOffers(ctx context.Context, filters dto.JobOfferFilters) ([]*recruiting.JobOffer, error) {
+	const op = "repo.GetAllJobOffers"
+
+	query := jobOfferSelect
+	var conditions []string
+	var args []interface{}
+	argIdx := 1
+
+	if filters.CandidateID != nil {
+		conditions = append(conditions, fmt.Sprintf("o.candidate_id = $%d", argIdx))
+		args = append(args, *filters.CandidateID)
+		argIdx++
+	}
+	if filters.VacancyID != nil {
+		conditions = append(conditions, fmt.Sprintf("o.vacancy_id = $%d", argIdx))
+		args = append(args, *filters.VacancyID)
+		argIdx++
+	}
+	if filters.Status != nil {
+		conditions = append(conditions, fmt.Sprintf("o.status = $%d", argIdx))
+		args = append(args, *filters.Status)
+		argIdx++
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY o.created_at DESC"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var offers []*recruiting.JobOffer
+	for rows.Next() {
+		offer, err := scanJobOffer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		offers = append(offers, offer)
+	}
+	return offers, rows.Err()
+}
+
+// UpdateJobOffer changes the terms of an offer. Status moves through
+// UpdateJobOfferStatus.
+func (r *Repo) UpdateJobOffer(ctx context.Context, id int64, req dto.UpdateJobOfferRequest) error {
+	const op = "repo.UpdateJobOffer"
+
+	var setClauses []string
+	var args []interface{}
+	argIdx := 1
+
+	if req.SalaryOffered != nil {
+		setClauses = append(setClauses, fmt.Sprintf("salary_offered = $%d", argIdx))
+		args = append(args, *req.SalaryOffered)
+		argIdx++
+	}
+	if req.StartDate != nil {
+		setClauses = append(setClauses, fmt.Sprintf("start_date = $%d", argIdx))
+		args = append(args, *req.StartDate)
+		argIdx++
+	}
+	if req.Notes != nil {
+		setClauses = append(setClauses, fmt.Sprintf("notes = $%d", argIdx))
+		args = append(args, *req.Notes)
+		argIdx++
+	}
+
+	if len(setClauses) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf("UPDATE job_offers SET %s WHERE id = $%d", strings.Join(setClauses, ", "), argIdx)
+	args = append(args, id)
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return storage.ErrOfferNotFound
+	}
+	return nil
+}
+
+// UpdateJobOfferStatus sets the status of an offer; the candidate's answer,
+// or the offer running out, is stamped as the decision time
+func (r *Repo) UpdateJobOfferStatus(ctx context.Context, id int64, status string) error {
+	const op = "repo.UpdateJobOfferStatus"
+
+	query := `
+		UPDATE job_offers
+		SET status = $2,
+			decided_at = CASE WHEN $2 IN ('accepted', 'rejected', 'expired', 'withdrawn') THEN NOW() END
+		WHERE id = $1`
+
+	res, err := r.db.ExecContext(ctx, query, id, status)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return storage.ErrOfferNotFound
+	}
+	return nil
+}
+
+// ==================== Onboardings ====================
+
+func (r *Repo) GetAllOnboardings(ctx context.Context, filters dto.OnboardingFilters) ([]*recruiting.Onboarding, error) {
+	const op = "repo.GetAllOnboardings"
+
+	query := `
+		SELECT o.id, o.candidate_id, o.vacancy_id, o.employee_id, c.fio, o.offer_id,
+			   o.start_date::text, o.status, o.mentor_id, o.notes, o.created_at, o.updated_at
+		FROM onboardings o
+		LEFT JOIN contacts c ON o.employee_id = c.id`
+
+	var conditions []string
+	var args []interface{}
+	argIdx := 1
+
+	if filters.EmployeeID != nil {
+		conditions = append(conditions, fmt.Sprintf("o.employee_id = $%d", argIdx))
+		args = append(args, *filters.EmployeeID)
+		argIdx++
+	}
+	if filters.Status != nil {
+		conditions = append(conditions, fmt.Sprintf("o.status = $%d", argIdx))
+		args = append(args, *filters.Status)
+		argIdx++
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY o.start_date DESC NULLS LAST, o.id DESC"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var onboardings []*recruiting.Onboarding
+	byID := make(map[int64]*recruiting.Onboarding)
+	var ids []int64
+	for rows.Next() {
+		var o recruiting.Onboarding
+		if err := rows.Scan(
+			&o.ID, &o.CandidateID, &o.VacancyID, &o.EmployeeID, &o.EmployeeName, &o.OfferID,
+			&o.StartDate, &o.Status, &o.MentorID, &o.Notes, &o.CreatedAt, &o.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		o.Tasks = []recruiting.OnboardingTask{}
+		onboardings = append(onboardings, &o)
+		byID[o.ID] = &o
+		ids = append(ids, o.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+	if len(ids) == 0 {
+		return onboardings, nil
+	}
+
+	taskRows, err := r.db.QueryContext(ctx, `
+		SELECT id, onboarding_id, title, description, assigned_to, due_date::text, status, completed_at
+		FROM onboarding_tasks
+		WHERE onboarding_id = ANY($1)
+		ORDER BY onboarding_id, sort_order, id`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("%s: tasks: %w", op, err)
+	}
+	defer taskRows.Close()
+
+	for taskRows.Next() {
+		var t recruiting.OnboardingTask
+		if err := taskRows.Scan(&t.ID, &t.OnboardingID, &t.Title, &t.Description,
+			&t.AssignedTo, &t.DueDate, &t.Status, &t.CompletedAt); err != nil {
+			return nil, fmt.Errorf("%s: scan task: %w", op, err)
+		}
+		if o, ok := byID[t.OnboardingID]; ok {
+			o.Tasks = append(o.Tasks, t)
+		}
+	}
+	return onboardings, taskRows.Err()
+}
+
+// UpdateOnboardingTask changes a checklist item and moves its onboarding
+// along: in progress once any item is touched, completed when none is left
+// open
+func (r *Repo) UpdateOnboardingTask(ctx context.Context, id int64, req dto.UpdateOnboardingTaskRequest) error {
+	const op = "repo.UpdateOnboardingTask"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE onboarding_tasks
+		SET status = COALESCE($2, status),
+			assigned_to = COALESCE($3, assigned_to),
+			due_date = COALESCE($4::date, due_date),
+			completed_at = CASE
+				WHEN COALESCE($2, status) = 'completed' THEN COALESCE(completed_at, NOW())
+			END
+		WHERE id = $1
+		RETURNING onboarding_id`
+
+	var onboardingID int64
+	err = tx.QueryRowContext(ctx, query, id, req.Status, req.AssignedTo, req.DueDate).Scan(&onboardingID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return storage.ErrOnboardingTaskNotFound
+		}
+		if translated := r.translator.Translate(err, op); translated != nil {
+			return translated
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE onboardings o
+		SET status = CASE
+			WHEN NOT EXISTS (SELECT 1 FROM onboarding_tasks t
+			                 WHERE t.onboarding_id = o.id AND t.status IN ('pending', 'in_progress'))
+				THEN 'completed'
+			WHEN EXISTS (SELECT 1 FROM onboarding_tasks t
+			             WHERE t.onboarding_id = o.id AND t.status <> 'pending')
+				THEN 'in_progress'
+			ELSE 'pending'
+		END
+		WHERE o.id = $1 AND o.status <> 'cancelled'`, onboardingID)
+	if err != nil {
+		return fmt.Errorf("%s: onboarding status: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return nil
+}
+
 // ==================== Scanners ====================
 
 type scannable interface {
@@ -896,3 +1199,16 @@ func scanExperience(s scannable) (*recruiting.Experience, error) {
 	}
 	return &e, nil
 }
+
+func scanJobOffer(s scannable) (*recruiting.JobOffer, error) {
+	var o recruiting.JobOffer
+	err := s.Scan(
+		&o.ID, &o.CandidateID, &o.CandidateName, &o.VacancyID, &o.VacancyTitle,
+		&o.DepartmentID, &o.PositionID, &o.SalaryOffered, &o.StartDate,
+		&o.Status, &o.Notes, &o.DecidedAt, &o.CreatedAt, &o.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
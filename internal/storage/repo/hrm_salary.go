@@ -39,10 +39,10 @@ func (r *Repo) GetSalaryByID(ctx context.Context, id int64) (*salary.Salary, err
 			   s.period_month, s.period_year,
 			   s.base_salary, s.regional_allowance, s.seniority_allowance,
 			   s.qualification_allowance, s.hazard_allowance, s.night_shift_allowance,
-			   s.overtime_amount, s.bonus_amount, s.gross_salary,
+			   s.overtime_amount, s.night_amount, s.sick_amount, s.bonus_amount, s.gross_salary,
 			   s.ndfl, s.social_tax, s.pension_fund, s.health_insurance, s.trade_union,
 			   s.total_deductions, s.net_salary,
-			   s.work_days, s.actual_days, s.overtime_hours,
+			   s.work_days, s.actual_days, s.overtime_hours, s.night_hours, s.sick_days, s.from_timesheet,
 			   s.status, s.calculated_at, s.approved_by, s.approved_at, s.paid_at,
 			   s.created_at, s.updated_at
 		FROM salaries s
@@ -70,10 +70,10 @@ func (r *Repo) GetAllSalaries(ctx context.Context, filters dto.SalaryFilters) ([
 			   s.period_month, s.period_year,
 			   s.base_salary, s.regional_allowance, s.seniority_allowance,
 			   s.qualification_allowance, s.hazard_allowance, s.night_shift_allowance,
-			   s.overtime_amount, s.bonus_amount, s.gross_salary,
+			   s.overtime_amount, s.night_amount, s.sick_amount, s.bonus_amount, s.gross_salary,
 			   s.ndfl, s.social_tax, s.pension_fund, s.health_insurance, s.trade_union,
 			   s.total_deductions, s.net_salary,
-			   s.work_days, s.actual_days, s.overtime_hours,
+			   s.work_days, s.actual_days, s.overtime_hours, s.night_hours, s.sick_days, s.from_timesheet,
 			   s.status, s.calculated_at, s.approved_by, s.approved_at, s.paid_at,
 			   s.created_at, s.updated_at
 		FROM salaries s
@@ -199,20 +199,22 @@ func (r *Repo) UpdateSalaryCalculation(ctx context.Context, sal *salary.Salary)
 		UPDATE salaries SET
 			base_salary = $1, regional_allowance = $2, seniority_allowance = $3,
 			qualification_allowance = $4, hazard_allowance = $5, night_shift_allowance = $6,
-			overtime_amount = $7, bonus_amount = $8, gross_salary = $9,
-			ndfl = $10, social_tax = $11, pension_fund = $12, health_insurance = $13, trade_union = $14,
-			total_deductions = $15, net_salary = $16,
-			work_days = $17, actual_days = $18, overtime_hours = $19,
+			overtime_amount = $7, night_amount = $8, sick_amount = $9, bonus_amount = $10, gross_salary = $11,
+			ndfl = $12, social_tax = $13, pension_fund = $14, health_insurance = $15, trade_union = $16,
+			total_deductions = $17, net_salary = $18,
+			work_days = $19, actual_days = $20, overtime_hours = $21, night_hours = $22, sick_days = $23,
+			from_timesheet = $24,
 			status = 'calculated', calculated_at = NOW()
-		WHERE id = $20`
+		WHERE id = $25 AND status IN ('draft', 'calculated')`
 
 	result, err := r.db.ExecContext(ctx, query,
 		sal.BaseSalary, sal.RegionalAllowance, sal.SeniorityAllowance,
 		sal.QualificationAllow, sal.HazardAllowance, sal.NightShiftAllowance,
-		sal.OvertimeAmount, sal.BonusAmount, sal.GrossSalary,
+		sal.OvertimeAmount, sal.NightAmount, sal.SickAmount, sal.BonusAmount, sal.GrossSalary,
 		sal.NDFL, sal.SocialTax, sal.PensionFund, sal.HealthInsurance, sal.TradeUnion,
 		sal.TotalDeductions, sal.NetSalary,
-		sal.WorkDays, sal.ActualDays, sal.OvertimeHours,
+		sal.WorkDays, sal.ActualDays, sal.OvertimeHours, sal.NightHours, sal.SickDays,
+		sal.FromTimesheet,
 		sal.ID,
 	)
 	if err != nil {
@@ -543,10 +545,10 @@ func scanSalary(scanner interface {
 		&s.PeriodMonth, &s.PeriodYear,
 		&s.BaseSalary, &s.RegionalAllowance, &s.SeniorityAllowance,
 		&s.QualificationAllow, &s.HazardAllowance, &s.NightShiftAllowance,
-		&s.OvertimeAmount, &s.BonusAmount, &s.GrossSalary,
+		&s.OvertimeAmount, &s.NightAmount, &s.SickAmount, &s.BonusAmount, &s.GrossSalary,
 		&s.NDFL, &s.SocialTax, &s.PensionFund, &s.HealthInsurance, &s.TradeUnion,
 		&s.TotalDeductions, &s.NetSalary,
-		&s.WorkDays, &s.ActualDays, &s.OvertimeHours,
+		&s.WorkDays, &s.ActualDays, &s.OvertimeHours, &s.NightHours, &s.SickDays, &s.FromTimesheet,
 		&s.Status, &calculatedAt, &approvedBy, &approvedAt, &paidAt,
 		&s.CreatedAt, &s.UpdatedAt,
 	)
@@ -18,7 +18,7 @@ func (r *Repo) GetTimesheetEntries(ctx context.Context, employeeID int64, year,
 	query := `
 		SELECT id, employee_id, date::text, status,
 			   check_in::text, check_out::text,
-			   hours_worked, overtime, is_weekend, is_holiday, note
+			   hours_worked, overtime, night_hours, is_weekend, is_holiday, note, source
 		FROM timesheet_entries
 		WHERE employee_id = $1
 		  AND EXTRACT(YEAR FROM date) = $2
@@ -48,7 +48,7 @@ func (r *Repo) GetTimesheetEntry(ctx context.Context, id int64) (*timesheet.Day,
 	query := `
 		SELECT id, employee_id, date::text, status,
 			   check_in::text, check_out::text,
-			   hours_worked, overtime, is_weekend, is_holiday, note
+			   hours_worked, overtime, night_hours, is_weekend, is_holiday, note, source
 		FROM timesheet_entries
 		WHERE id = $1`
 
@@ -68,7 +68,7 @@ func (r *Repo) GetTimesheetEntryByEmployeeDate(ctx context.Context, employeeID i
 	query := `
 		SELECT id, employee_id, date::text, status,
 			   check_in::text, check_out::text,
-			   hours_worked, overtime, is_weekend, is_holiday, note
+			   hours_worked, overtime, night_hours, is_weekend, is_holiday, note, source
 		FROM timesheet_entries
 		WHERE employee_id = $1 AND date = $2::date`
 
@@ -96,7 +96,8 @@ func (r *Repo) UpsertTimesheetEntry(ctx context.Context, employeeID int64, date,
 			overtime = EXCLUDED.overtime,
 			is_weekend = EXCLUDED.is_weekend,
 			is_holiday = EXCLUDED.is_holiday,
-			note = EXCLUDED.note
+			note = EXCLUDED.note,
+			source = 'manual'
 		RETURNING id`
 
 	var id int64
@@ -144,6 +145,11 @@ func (r *Repo) UpdateTimesheetEntry(ctx context.Context, id int64, req dto.Updat
 		args = append(args, *req.Overtime)
 		argIdx++
 	}
+	if req.NightHours != nil {
+		setClauses = append(setClauses, fmt.Sprintf("night_hours = $%d", argIdx))
+		args = append(args, *req.NightHours)
+		argIdx++
+	}
 	if req.Note != nil {
 		setClauses = append(setClauses, fmt.Sprintf("note = $%d", argIdx))
 		args = append(args, *req.Note)
@@ -153,6 +159,8 @@ func (r *Repo) UpdateTimesheetEntry(ctx context.Context, id int64, req dto.Updat
 	if len(setClauses) == 0 {
 		return nil
 	}
+	// An edited entry is no longer the generator's to rewrite
+	setClauses = append(setClauses, "source = 'manual'")
 
 	query := fmt.Sprintf("UPDATE timesheet_entries SET %s WHERE id = $%d",
 		strings.Join(setClauses, ", "), argIdx)
@@ -299,18 +307,19 @@ func (r *Repo) CreateHoliday(ctx context.Context, req dto.CreateHolidayRequest)
 	return id, nil
 }
 
-func (r *Repo) DeleteHoliday(ctx context.Context, id int64) error {
+// DeleteHoliday deletes a holiday and returns its date
+func (r *Repo) DeleteHoliday(ctx context.Context, id int64) (string, error) {
 	const op = "repo.DeleteHoliday"
 
-	result, err := r.db.ExecContext(ctx, "DELETE FROM holidays WHERE id = $1", id)
+	var date string
+	err := r.db.QueryRowContext(ctx, "DELETE FROM holidays WHERE id = $1 RETURNING date::text", id).Scan(&date)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
-	}
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return storage.ErrHolidayNotFound
+		if err == sql.ErrNoRows {
+			return "", storage.ErrHolidayNotFound
+		}
+		return "", fmt.Errorf("%s: %w", op, err)
 	}
-	return nil
+	return date, nil
 }
 
 // --- Corrections ---
@@ -456,13 +465,14 @@ func scanTimesheetDay(scanner interface {
 		checkOut    sql.NullString
 		hoursWorked sql.NullFloat64
 		overtime    sql.NullFloat64
+		nightHours  sql.NullFloat64
 		note        sql.NullString
 	)
 
 	err := scanner.Scan(
 		&d.ID, &d.EmployeeID, &d.Date, &d.Status,
 		&checkIn, &checkOut,
-		&hoursWorked, &overtime, &d.IsWeekend, &d.IsHoliday, &note,
+		&hoursWorked, &overtime, &nightHours, &d.IsWeekend, &d.IsHoliday, &note, &d.Source,
 	)
 	if err != nil {
 		return nil, err
@@ -480,6 +490,9 @@ func scanTimesheetDay(scanner interface {
 	if overtime.Valid {
 		d.Overtime = &overtime.Float64
 	}
+	if nightHours.Valid {
+		d.NightHours = &nightHours.Float64
+	}
 	if note.Valid {
 		d.Note = &note.String
 	}
@@ -0,0 +1,338 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/timesheet"
+	"srmt-admin/internal/storage"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// --- Timesheet auto-fill sources ---
+
+// GetTimesheetEntriesBetween returns the stored entries of the employees
+// between from and to inclusive.
+func (r *Repo) GetTimesheetEntriesBetween(ctx context.Context, employeeIDs []int64, from, to string) ([]*timesheet.Day, error) {
+	const op = "repo.GetTimesheetEntriesBetween"
+
+	query := `
+		SELECT id, employee_id, date::text, status,
+			   check_in::text, check_out::text,
+			   hours_worked, overtime, night_hours, is_weekend, is_holiday, note, source
+		FROM timesheet_entries
+		WHERE employee_id = ANY($1)
+		  AND date BETWEEN $2::date AND $3::date
+		ORDER BY employee_id, date`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(employeeIDs), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var entries []*timesheet.Day
+	for rows.Next() {
+		d, err := scanTimesheetDay(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		entries = append(entries, d)
+	}
+	return entries, rows.Err()
+}
+
+// GetApprovedVacationPeriods returns the approved, active and completed
+// vacations of the employees that overlap from..to. Kind is the vacation type.
+func (r *Repo) GetApprovedVacationPeriods(ctx context.Context, employeeIDs []int64, from, to string) ([]timesheet.Period, error) {
+	const op = "repo.GetApprovedVacationPeriods"
+
+	query := `
+		SELECT employee_id, vacation_type, start_date::text, end_date::text
+		FROM vacations
+		WHERE employee_id = ANY($1)
+		  AND status IN ('approved', 'active', 'completed')
+		  AND start_date <= $3::date AND end_date >= $2::date
+		ORDER BY employee_id, start_date`
+
+	return r.queryPeriods(ctx, op, query, employeeIDs, from, to)
+}
+
+// GetAbsencePeriods returns the sick leaves and business trips of the
+// employees that overlap from..to. Kind is the absence type.
+func (r *Repo) GetAbsencePeriods(ctx context.Context, employeeIDs []int64, from, to string) ([]timesheet.Period, error) {
+	const op = "repo.GetAbsencePeriods"
+
+	query := `
+		SELECT employee_id, type, start_date::text, end_date::text
+		FROM employee_absences
+		WHERE employee_id = ANY($1)
+		  AND start_date <= $3::date AND end_date >= $2::date
+		ORDER BY employee_id, start_date`
+
+	return r.queryPeriods(ctx, op, query, employeeIDs, from, to)
+}
+
+func (r *Repo) queryPeriods(ctx context.Context, op, query string, employeeIDs []int64, from, to string) ([]timesheet.Period, error) {
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(employeeIDs), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var periods []timesheet.Period
+	for rows.Next() {
+		var p timesheet.Period
+		if err := rows.Scan(&p.EmployeeID, &p.Kind, &p.StartDate, &p.EndDate); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		periods = append(periods, p)
+	}
+	return periods, rows.Err()
+}
+
+// GetAccessPresence returns, per employee and local calendar day in tz, the
+// first granted entry and the last granted exit between from and to.
+func (r *Repo) GetAccessPresence(ctx context.Context, employeeIDs []int64, from, to, tz string) ([]timesheet.Presence, error) {
+	const op = "repo.GetAccessPresence"
+
+	query := `
+		SELECT al.employee_id,
+			   ((al.timestamp AT TIME ZONE $4)::date)::text AS day,
+			   MIN(al.timestamp) FILTER (WHERE al.direction = 'entry'),
+			   MAX(al.timestamp) FILTER (WHERE al.direction = 'exit')
+		FROM access_logs al
+		WHERE al.employee_id = ANY($1)
+		  AND al.status = 'granted'
+		  AND al.timestamp >= ($2::date)::timestamp AT TIME ZONE $4
+		  AND al.timestamp < ($3::date + 1)::timestamp AT TIME ZONE $4
+		GROUP BY al.employee_id, day
+		ORDER BY al.employee_id, day`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(employeeIDs), from, to, tz)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var presence []timesheet.Presence
+	for rows.Next() {
+		var (
+			p       timesheet.Presence
+			firstIn sql.NullTime
+			lastOut sql.NullTime
+		)
+		if err := rows.Scan(&p.EmployeeID, &p.Date, &firstIn, &lastOut); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		if firstIn.Valid {
+			p.FirstIn = &firstIn.Time
+		}
+		if lastOut.Valid {
+			p.LastOut = &lastOut.Time
+		}
+		presence = append(presence, p)
+	}
+	return presence, rows.Err()
+}
+
+// GetAccessTrackedEmployees returns those of the employees that hold an
+// access card, i.e. whose attendance the access-control logs can tell.
+func (r *Repo) GetAccessTrackedEmployees(ctx context.Context, employeeIDs []int64) ([]int64, error) {
+	const op = "repo.GetAccessTrackedEmployees"
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT DISTINCT employee_id FROM access_cards WHERE employee_id = ANY($1)`,
+		pq.Array(employeeIDs))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// UpsertAutoTimesheetEntry writes a generated entry. A manual entry for the
+// same day is left alone, in which case it reports false.
+func (r *Repo) UpsertAutoTimesheetEntry(ctx context.Context, d timesheet.Day) (bool, error) {
+	const op = "repo.UpsertAutoTimesheetEntry"
+
+	query := `
+		INSERT INTO timesheet_entries (employee_id, date, status, check_in, check_out, hours_worked, overtime, night_hours, is_weekend, is_holiday, note, source)
+		VALUES ($1, $2::date, $3, $4, $5, $6, $7, $8, $9, $10, $11, 'auto')
+		ON CONFLICT (employee_id, date) DO UPDATE SET
+			status = EXCLUDED.status,
+			check_in = EXCLUDED.check_in,
+			check_out = EXCLUDED.check_out,
+			hours_worked = EXCLUDED.hours_worked,
+			overtime = EXCLUDED.overtime,
+			night_hours = EXCLUDED.night_hours,
+			is_weekend = EXCLUDED.is_weekend,
+			is_holiday = EXCLUDED.is_holiday,
+			note = EXCLUDED.note
+		WHERE timesheet_entries.source = 'auto'
+		RETURNING id`
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, query,
+		d.EmployeeID, d.Date, d.Status, d.CheckIn, d.CheckOut, d.HoursWorked, d.Overtime, d.NightHours, d.IsWeekend, d.IsHoliday, d.Note,
+	).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		if translated := r.translator.Translate(err, op); translated != nil {
+			return false, translated
+		}
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return true, nil
+}
+
+// --- Absences ---
+
+func (r *Repo) CreateAbsence(ctx context.Context, req dto.CreateAbsenceRequest, createdBy int64) (int64, error) {
+	const op = "repo.CreateAbsence"
+
+	query := `
+		INSERT INTO employee_absences (employee_id, type, start_date, end_date, note, created_by)
+		VALUES ($1, $2, $3::date, $4::date, $5, $6)
+		RETURNING id`
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, query,
+		req.EmployeeID, req.Type, req.StartDate, req.EndDate, req.Note, createdBy,
+	).Scan(&id)
+	if err != nil {
+		if translated := r.translator.Translate(err, op); translated != nil {
+			return 0, translated
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (r *Repo) GetAbsences(ctx context.Context, filters dto.AbsenceFilters) ([]*timesheet.Absence, error) {
+	const op = "repo.GetAbsences"
+
+	query := `
+		SELECT a.id, a.employee_id, c.fio, a.type,
+			   a.start_date::text, a.end_date::text, a.note,
+			   a.created_by, a.created_at
+		FROM employee_absences a
+		JOIN contacts c ON a.employee_id = c.id`
+
+	var conditions []string
+	var args []interface{}
+	argIdx := 1
+
+	if filters.EmployeeID != nil {
+		conditions = append(conditions, fmt.Sprintf("a.employee_id = $%d", argIdx))
+		args = append(args, *filters.EmployeeID)
+		argIdx++
+	}
+	if filters.Type != nil {
+		conditions = append(conditions, fmt.Sprintf("a.type = $%d", argIdx))
+		args = append(args, *filters.Type)
+		argIdx++
+	}
+	if filters.From != nil {
+		conditions = append(conditions, fmt.Sprintf("a.end_date >= $%d::date", argIdx))
+		args = append(args, *filters.From)
+		argIdx++
+	}
+	if filters.To != nil {
+		conditions = append(conditions, fmt.Sprintf("a.start_date <= $%d::date", argIdx))
+		args = append(args, *filters.To)
+		argIdx++
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY a.start_date DESC, c.fio"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var absences []*timesheet.Absence
+	for rows.Next() {
+		a, err := scanAbsence(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		absences = append(absences, a)
+	}
+	return absences, rows.Err()
+}
+
+func (r *Repo) GetAbsenceByID(ctx context.Context, id int64) (*timesheet.Absence, error) {
+	const op = "repo.GetAbsenceByID"
+
+	query := `
+		SELECT a.id, a.employee_id, c.fio, a.type,
+			   a.start_date::text, a.end_date::text, a.note,
+			   a.created_by, a.created_at
+		FROM employee_absences a
+		JOIN contacts c ON a.employee_id = c.id
+		WHERE a.id = $1`
+
+	a, err := scanAbsence(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, storage.ErrAbsenceNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return a, nil
+}
+
+func (r *Repo) DeleteAbsence(ctx context.Context, id int64) error {
+	const op = "repo.DeleteAbsence"
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM employee_absences WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return storage.ErrAbsenceNotFound
+	}
+	return nil
+}
+
+func scanAbsence(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*timesheet.Absence, error) {
+	var (
+		a         timesheet.Absence
+		note      sql.NullString
+		createdBy sql.NullInt64
+	)
+	err := scanner.Scan(&a.ID, &a.EmployeeID, &a.EmployeeName, &a.Type,
+		&a.StartDate, &a.EndDate, &note, &createdBy, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if note.Valid {
+		a.Note = &note.String
+	}
+	if createdBy.Valid {
+		a.CreatedBy = &createdBy.Int64
+	}
+	return &a, nil
+}
@@ -35,15 +35,15 @@ func (r *Repo) CreateTraining(ctx context.Context, req dto.CreateTrainingRequest
 	query := `
 		INSERT INTO trainings (title, description, type, provider, trainer,
 			start_date, end_date, location, max_participants, cost,
-			mandatory, department_ids, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			mandatory, department_ids, created_by, certification_type_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id`
 
 	var id int64
 	err := r.db.QueryRowContext(ctx, query,
 		req.Title, req.Description, req.Type, req.Provider, req.Trainer,
 		req.StartDate, req.EndDate, req.Location, maxParticipants, req.Cost,
-		mandatory, deptIDs, createdBy,
+		mandatory, deptIDs, createdBy, req.CertificationTypeID,
 	).Scan(&id)
 	if err != nil {
 		if translated := r.translator.Translate(err, op); translated != nil {
@@ -62,7 +62,7 @@ func (r *Repo) GetTrainingByID(ctx context.Context, id int64) (*training.Trainin
 			   t.provider, t.trainer, t.start_date, t.end_date, t.location,
 			   t.max_participants,
 			   (SELECT COUNT(*) FROM training_participants tp WHERE tp.training_id = t.id AND tp.status != 'cancelled'),
-			   t.cost, t.mandatory, t.department_ids,
+			   t.cost, t.mandatory, t.department_ids, t.certification_type_id,
 			   t.created_by, t.created_at, t.updated_at
 		FROM trainings t
 		WHERE t.id = $1`
@@ -85,7 +85,7 @@ func (r *Repo) GetAllTrainings(ctx context.Context, filters dto.TrainingFilters)
 			   t.provider, t.trainer, t.start_date, t.end_date, t.location,
 			   t.max_participants,
 			   (SELECT COUNT(*) FROM training_participants tp WHERE tp.training_id = t.id AND tp.status != 'cancelled'),
-			   t.cost, t.mandatory, t.department_ids,
+			   t.cost, t.mandatory, t.department_ids, t.certification_type_id,
 			   t.created_by, t.created_at, t.updated_at
 		FROM trainings t`
 
@@ -203,6 +203,11 @@ func (r *Repo) UpdateTraining(ctx context.Context, id int64, req dto.UpdateTrain
 		args = append(args, *req.DepartmentIDs)
 		argIdx++
 	}
+	if req.CertificationTypeID != nil {
+		setClauses = append(setClauses, fmt.Sprintf("certification_type_id = $%d", argIdx))
+		args = append(args, *req.CertificationTypeID)
+		argIdx++
+	}
 
 	if len(setClauses) == 0 {
 		return nil
@@ -339,7 +344,7 @@ func (r *Repo) GetEmployeeTrainings(ctx context.Context, employeeID int64) ([]*t
 			   t.provider, t.trainer, t.start_date, t.end_date, t.location,
 			   t.max_participants,
 			   (SELECT COUNT(*) FROM training_participants tp2 WHERE tp2.training_id = t.id AND tp2.status != 'cancelled'),
-			   t.cost, t.mandatory, t.department_ids,
+			   t.cost, t.mandatory, t.department_ids, t.certification_type_id,
 			   t.created_by, t.created_at, t.updated_at
 		FROM trainings t
 		INNER JOIN training_participants tp ON t.id = tp.training_id
@@ -365,18 +370,22 @@ func (r *Repo) GetEmployeeTrainings(ctx context.Context, employeeID int64) ([]*t
 
 // ==================== Certificates ====================
 
-func (r *Repo) CreateCertificate(ctx context.Context, employeeID int64, trainingID *int64, title string, issuer *string, issueDate string) (int64, error) {
+func (r *Repo) CreateCertificate(ctx context.Context, req dto.CreateCertificateRequest) (int64, error) {
 	const op = "repo.CreateCertificate"
 
 	query := `
-		INSERT INTO certificates (employee_id, training_id, title, issuer, issue_date)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO certificates (employee_id, training_id, certification_type_id, title, issuer,
+			issue_date, expiry_date, certificate_url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id`
 
 	var id int64
-	err := r.db.QueryRowContext(ctx, query, employeeID, trainingID, title, issuer, issueDate).Scan(&id)
+	err := r.db.QueryRowContext(ctx, query,
+		req.EmployeeID, req.TrainingID, req.CertificationTypeID, req.Title, req.Issuer,
+		req.IssueDate, req.ExpiryDate, req.CertificateURL,
+	).Scan(&id)
 	if err != nil {
-		return 0, fmt.Errorf("%s: %w", op, err)
+		return 0, r.translator.Translate(err, op)
 	}
 	return id, nil
 }
@@ -386,12 +395,13 @@ func (r *Repo) GetEmployeeCertificates(ctx context.Context, employeeID int64) ([
 
 	query := `
 		SELECT cert.id, cert.employee_id, COALESCE(c.name, ''),
-			   cert.training_id, t.title,
+			   cert.training_id, t.title, cert.certification_type_id, ct.name,
 			   cert.title, cert.issuer, cert.issue_date, cert.expiry_date,
 			   cert.certificate_url, cert.created_at
 		FROM certificates cert
 		LEFT JOIN contacts c ON cert.employee_id = c.id
 		LEFT JOIN trainings t ON cert.training_id = t.id
+		LEFT JOIN certification_types ct ON cert.certification_type_id = ct.id
 		WHERE cert.employee_id = $1
 		ORDER BY cert.issue_date DESC`
 
@@ -522,7 +532,7 @@ func scanTraining(s scannable) (*training.Training, error) {
 		&t.ID, &t.Title, &t.Description, &t.Type, &t.Status,
 		&t.Provider, &t.Trainer, &t.StartDate, &t.EndDate, &t.Location,
 		&t.MaxParticipants, &t.CurrentParticipants,
-		&t.Cost, &t.Mandatory, &deptIDs,
+		&t.Cost, &t.Mandatory, &deptIDs, &t.CertificationTypeID,
 		&t.CreatedBy, &t.CreatedAt, &t.UpdatedAt,
 	)
 	if err != nil {
@@ -548,7 +558,7 @@ func scanCertificate(s scannable) (*training.Certificate, error) {
 	var c training.Certificate
 	err := s.Scan(
 		&c.ID, &c.EmployeeID, &c.EmployeeName,
-		&c.TrainingID, &c.TrainingTitle,
+		&c.TrainingID, &c.TrainingTitle, &c.CertificationTypeID, &c.CertificationTypeName,
 		&c.Title, &c.Issuer, &c.IssueDate, &c.ExpiryDate,
 		&c.CertificateURL, &c.CreatedAt,
 	)
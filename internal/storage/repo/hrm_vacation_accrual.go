@@ -0,0 +1,479 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"srmt-admin/internal/lib/dto"
+	"srmt-admin/internal/lib/model/hrm/vacation"
+	"srmt-admin/internal/storage"
+
+	"github.com/lib/pq"
+)
+
+// ==================== Vacation Accrual ====================
+
+// --- Policy and entitlement rules ---
+
+func (r *Repo) GetLeavePolicy(ctx context.Context) (*vacation.Policy, error) {
+	const op = "repo.GetLeavePolicy"
+
+	var p vacation.Policy
+	var updatedBy sql.NullInt64
+	err := r.db.QueryRowContext(ctx,
+		`SELECT hazard_days, carry_over_cap, updated_by, updated_at FROM leave_policy`,
+	).Scan(&p.HazardDays, &p.CarryOverCap, &updatedBy, &p.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if updatedBy.Valid {
+		p.UpdatedBy = &updatedBy.Int64
+	}
+	return &p, nil
+}
+
+func (r *Repo) UpdateLeavePolicy(ctx context.Context, req dto.UpdateLeavePolicyRequest, updatedBy int64) error {
+	const op = "repo.UpdateLeavePolicy"
+
+	query := `
+		INSERT INTO leave_policy (id, hazard_days, carry_over_cap, updated_by)
+		VALUES (TRUE, $1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET
+			hazard_days = EXCLUDED.hazard_days,
+			carry_over_cap = EXCLUDED.carry_over_cap,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()`
+
+	if _, err := r.db.ExecContext(ctx, query, req.HazardDays, req.CarryOverCap, updatedBy); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *Repo) GetEntitlementRules(ctx context.Context) ([]*vacation.EntitlementRule, error) {
+	const op = "repo.GetEntitlementRules"
+
+	query := `
+		SELECT er.id, er.position_id, p.name, er.min_seniority_years, er.annual_days, er.note, er.created_at
+		FROM leave_entitlement_rules er
+		LEFT JOIN positions p ON p.id = er.position_id
+		ORDER BY er.position_id NULLS FIRST, er.min_seniority_years`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var rules []*vacation.EntitlementRule
+	for rows.Next() {
+		var rule vacation.EntitlementRule
+		var positionID sql.NullInt64
+		var positionName, note sql.NullString
+		if err := rows.Scan(&rule.ID, &positionID, &positionName, &rule.MinSeniorityYears,
+			&rule.AnnualDays, &note, &rule.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		if positionID.Valid {
+			rule.PositionID = &positionID.Int64
+		}
+		if positionName.Valid {
+			rule.PositionName = &positionName.String
+		}
+		if note.Valid {
+			rule.Note = &note.String
+		}
+		rules = append(rules, &rule)
+	}
+	return rules, rows.Err()
+}
+
+func (r *Repo) CreateEntitlementRule(ctx context.Context, req dto.CreateEntitlementRuleRequest) (int64, error) {
+	const op = "repo.CreateEntitlementRule"
+
+	query := `
+		INSERT INTO leave_entitlement_rules (position_id, min_seniority_years, annual_days, note)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, query, req.PositionID, req.MinSeniorityYears, req.AnnualDays, req.Note).Scan(&id)
+	if err != nil {
+		if translated := r.translator.Translate(err, op); translated != nil {
+			if errors.Is(translated, storage.ErrDuplicate) {
+				return 0, storage.ErrEntitlementRuleExists
+			}
+			return 0, translated
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (r *Repo) DeleteEntitlementRule(ctx context.Context, id int64) error {
+	const op = "repo.DeleteEntitlementRule"
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM leave_entitlement_rules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return storage.ErrEntitlementRuleNotFound
+	}
+	return nil
+}
+
+// --- Accrual ---
+
+// GetAccrualEmployees returns the employees on staff at some point between
+// from and to (YYYY-MM-DD) whose year has no opening balance. A dismissed
+// record without a dismissal date is taken to end on its last update.
+func (r *Repo) GetAccrualEmployees(ctx context.Context, from, to string, year int) ([]*vacation.AccrualEmployee, error) {
+	const op = "repo.GetAccrualEmployees"
+
+	query := `
+		WITH staff AS (
+			SELECT employee_id, position_id, hire_date,
+			       COALESCE(dismissal_date, CASE WHEN status = 'dismissed' THEN updated_at::date END) AS dismissal_date
+			FROM personnel_records
+		)
+		SELECT s.employee_id, s.position_id, s.hire_date::text, s.dismissal_date::text,
+		       EXISTS (
+		           SELECT 1 FROM salary_structures ss
+		           WHERE ss.employee_id = s.employee_id
+		             AND ss.hazard_allowance > 0
+		             AND ss.effective_from <= $2::date
+		             AND (ss.effective_to IS NULL OR ss.effective_to >= $1::date)
+		       )
+		FROM staff s
+		WHERE s.hire_date <= $2::date
+		  AND (s.dismissal_date IS NULL OR s.dismissal_date >= $1::date)
+		  AND NOT EXISTS (
+		      SELECT 1 FROM vacation_ledger l
+		      WHERE l.employee_id = s.employee_id AND l.year = $3 AND l.kind = 'opening'
+		  )
+		ORDER BY s.employee_id`
+
+	rows, err := r.db.QueryContext(ctx, query, from, to, year)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var employees []*vacation.AccrualEmployee
+	for rows.Next() {
+		var e vacation.AccrualEmployee
+		var dismissal sql.NullString
+		if err := rows.Scan(&e.EmployeeID, &e.PositionID, &e.HireDate, &dismissal, &e.Hazard); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		if dismissal.Valid {
+			e.DismissalDate = &dismissal.String
+		}
+		employees = append(employees, &e)
+	}
+	return employees, rows.Err()
+}
+
+// ReplaceMonthAccruals replaces the accrual and hazard rows dated monthEnd
+// with entries and recounts the balances of everyone affected
+func (r *Repo) ReplaceMonthAccruals(ctx context.Context, monthEnd string, year int, entries []*vacation.LedgerEntry) error {
+	const op = "repo.ReplaceMonthAccruals"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		DELETE FROM vacation_ledger
+		WHERE kind IN ('accrual', 'hazard') AND entry_date = $1::date
+		RETURNING employee_id`, monthEnd)
+	if err != nil {
+		return fmt.Errorf("%s: delete: %w", op, err)
+	}
+	affected := map[int64]bool{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("%s: scan: %w", op, err)
+		}
+		affected[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	if err := insertLedgerEntries(ctx, tx, entries); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, e := range entries {
+		affected[e.EmployeeID] = true
+	}
+
+	if err := refreshVacationBalances(ctx, tx, employeeIDsOf(affected), year); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return nil
+}
+
+// --- Year-end closing ---
+
+func (r *Repo) IsVacationYearClosed(ctx context.Context, year int) (bool, error) {
+	const op = "repo.IsVacationYearClosed"
+
+	var closed bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM vacation_year_closings WHERE year = $1)`, year,
+	).Scan(&closed)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return closed, nil
+}
+
+// GetLedgerTotals sums the ledger rows of the year per employee
+func (r *Repo) GetLedgerTotals(ctx context.Context, year int) (map[int64]float64, error) {
+	const op = "repo.GetLedgerTotals"
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT employee_id, SUM(days) FROM vacation_ledger WHERE year = $1 GROUP BY employee_id`, year)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	totals := map[int64]float64{}
+	for rows.Next() {
+		var employeeID int64
+		var days float64
+		if err := rows.Scan(&employeeID, &days); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		totals[employeeID] = days
+	}
+	return totals, rows.Err()
+}
+
+// CloseVacationYear marks the year closed, writes the carry-over and expiry
+// entries and recounts the balances of the year and the next
+func (r *Repo) CloseVacationYear(ctx context.Context, year int, entries []*vacation.LedgerEntry, closedBy *int64) error {
+	const op = "repo.CloseVacationYear"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO vacation_year_closings (year, closed_by) VALUES ($1, $2)`, year, closedBy,
+	); err != nil {
+		if translated := r.translator.Translate(err, op); translated != nil {
+			if errors.Is(translated, storage.ErrDuplicate) {
+				return storage.ErrVacationYearClosed
+			}
+			return translated
+		}
+		return fmt.Errorf("%s: mark closed: %w", op, err)
+	}
+
+	if err := insertLedgerEntries(ctx, tx, entries); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected := map[int64]bool{}
+	for _, e := range entries {
+		affected[e.EmployeeID] = true
+	}
+	ids := employeeIDsOf(affected)
+	if err := refreshVacationBalances(ctx, tx, ids, year); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := refreshVacationBalances(ctx, tx, ids, year+1); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return nil
+}
+
+// --- Ledger ---
+
+// AddLedgerAdjustment records a manual correction and recounts the balance
+func (r *Repo) AddLedgerAdjustment(ctx context.Context, employeeID int64, year int, req dto.CreateLedgerAdjustmentRequest, createdBy int64) (int64, error) {
+	const op = "repo.AddLedgerAdjustment"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO vacation_ledger (employee_id, year, entry_date, kind, days, note, created_by)
+		VALUES ($1, $2, $3::date, 'adjustment', $4, $5, $6)
+		RETURNING id`,
+		employeeID, year, req.Date, req.Days, req.Note, createdBy,
+	).Scan(&id)
+	if err != nil {
+		if translated := r.translator.Translate(err, op); translated != nil {
+			return 0, translated
+		}
+		return 0, fmt.Errorf("%s: insert: %w", op, err)
+	}
+
+	if err := refreshVacationBalances(ctx, tx, []int64{employeeID}, year); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: commit: %w", op, err)
+	}
+	return id, nil
+}
+
+// GetLedgerEntries returns the stored ledger rows of an employee's year
+func (r *Repo) GetLedgerEntries(ctx context.Context, employeeID int64, year int) ([]*vacation.LedgerEntry, error) {
+	const op = "repo.GetLedgerEntries"
+
+	query := `
+		SELECT id, employee_id, year, entry_date::text, kind, days, note, created_by
+		FROM vacation_ledger
+		WHERE employee_id = $1 AND year = $2
+		ORDER BY entry_date, id`
+
+	rows, err := r.db.QueryContext(ctx, query, employeeID, year)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var entries []*vacation.LedgerEntry
+	for rows.Next() {
+		var e vacation.LedgerEntry
+		var id int64
+		var note sql.NullString
+		var createdBy sql.NullInt64
+		if err := rows.Scan(&id, &e.EmployeeID, &e.Year, &e.Date, &e.Kind, &e.Days, &note, &createdBy); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		e.ID = &id
+		if note.Valid {
+			e.Note = &note.String
+		}
+		if createdBy.Valid {
+			e.CreatedBy = &createdBy.Int64
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+// GetLedgerVacations returns the approved and pending vacations of the given
+// types starting in the year as usage and pending ledger entries
+func (r *Repo) GetLedgerVacations(ctx context.Context, employeeID int64, year int, types []string) ([]*vacation.LedgerEntry, error) {
+	const op = "repo.GetLedgerVacations"
+
+	query := `
+		SELECT id, start_date::text, vacation_type, days,
+		       CASE WHEN status = 'pending' THEN 'pending' ELSE 'usage' END
+		FROM vacations
+		WHERE employee_id = $1
+		  AND EXTRACT(YEAR FROM start_date) = $2
+		  AND vacation_type = ANY($3)
+		  AND status IN ('pending', 'approved', 'active', 'completed')
+		ORDER BY start_date, id`
+
+	rows, err := r.db.QueryContext(ctx, query, employeeID, year, pq.Array(types))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var entries []*vacation.LedgerEntry
+	for rows.Next() {
+		e := vacation.LedgerEntry{EmployeeID: employeeID, Year: year}
+		var vacationID int64
+		var vacationType string
+		var days int
+		if err := rows.Scan(&vacationID, &e.Date, &vacationType, &days, &e.Kind); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		e.VacationID = &vacationID
+		e.VacationType = &vacationType
+		e.Days = -float64(days)
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+// insertLedgerEntries writes ledger rows within tx
+func insertLedgerEntries(ctx context.Context, tx *sql.Tx, entries []*vacation.LedgerEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO vacation_ledger (employee_id, year, entry_date, kind, days, note, created_by)
+		VALUES ($1, $2, $3::date, $4, $5, $6, $7)`)
+	if err != nil {
+		return fmt.Errorf("prepare ledger insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, e := range entries {
+		if _, err := stmt.ExecContext(ctx, e.EmployeeID, e.Year, e.Date, e.Kind, e.Days, e.Note, e.CreatedBy); err != nil {
+			return fmt.Errorf("insert ledger entry of employee %d: %w", e.EmployeeID, err)
+		}
+	}
+	return nil
+}
+
+// refreshVacationBalances recounts the earned and carried-over days of the
+// employees' year from the ledger. Used and pending days are kept as they
+// are and taken off the remaining days.
+func refreshVacationBalances(ctx context.Context, tx *sql.Tx, employeeIDs []int64, year int) error {
+	if len(employeeIDs) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO vacation_balances (employee_id, year, total_days, carried_over, remaining_days)
+		SELECT e.employee_id, $2,
+		       ROUND(COALESCE(SUM(l.days) FILTER (WHERE l.kind IN ('opening', 'accrual', 'hazard', 'adjustment')), 0)),
+		       ROUND(COALESCE(SUM(l.days) FILTER (WHERE l.kind = 'carry_over'), 0)),
+		       ROUND(COALESCE(SUM(l.days), 0))
+		FROM unnest($1::bigint[]) AS e(employee_id)
+		LEFT JOIN vacation_ledger l ON l.employee_id = e.employee_id AND l.year = $2
+		GROUP BY e.employee_id
+		ON CONFLICT (employee_id, year) DO UPDATE SET
+			total_days = EXCLUDED.total_days,
+			carried_over = EXCLUDED.carried_over,
+			remaining_days = EXCLUDED.remaining_days - vacation_balances.used_days - vacation_balances.pending_days,
+			updated_at = NOW()`
+
+	if _, err := tx.ExecContext(ctx, query, pq.Array(employeeIDs), year); err != nil {
+		return fmt.Errorf("refresh balances: %w", err)
+	}
+	return nil
+}
+
+func employeeIDsOf(set map[int64]bool) []int64 {
+	ids := make([]int64, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,374 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"srmt-admin/internal/lib/model/notification"
+	"srmt-admin/internal/storage"
+
+	"github.com/lib/pq"
+)
+
+const deliveryColumns = `
+	d.id, d.contact_id, d.event_type, d.channel, d.severity, d.title, d.message, d.link,
+	d.status, d.attempts, d.next_attempt_at, d.last_error, d.notification_id, d.created_at, d.sent_at`
+
+func scanDelivery(s interface{ Scan(...any) error }, d *notification.Delivery, extra ...any) error {
+	var link, lastError sql.NullString
+	var notificationID sql.NullInt64
+	var sentAt sql.NullTime
+	dest := []any{
+		&d.ID, &d.ContactID, &d.EventType, &d.Channel, &d.Severity, &d.Title, &d.Message, &link,
+		&d.Status, &d.Attempts, &d.NextAttemptAt, &lastError, &notificationID, &d.CreatedAt, &sentAt,
+	}
+	if err := s.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	d.Link = nullStringPtr(link)
+	d.LastError = nullStringPtr(lastError)
+	if notificationID.Valid {
+		d.NotificationID = &notificationID.Int64
+	}
+	if sentAt.Valid {
+		d.SentAt = &sentAt.Time
+	}
+	return nil
+}
+
+// GetContactIDsByUserIDs returns the contacts of the given user accounts.
+func (r *Repo) GetContactIDsByUserIDs(ctx context.Context, userIDs []int64) ([]int64, error) {
+	const op = "storage.repo.GetContactIDsByUserIDs"
+	return r.queryContactIDs(ctx, op, `SELECT contact_id FROM users WHERE id = ANY($1)`, pq.Array(userIDs))
+}
+
+// GetContactIDsByRoles returns the contacts of the active users holding any
+// of the roles.
+func (r *Repo) GetContactIDsByRoles(ctx context.Context, roles []string) ([]int64, error) {
+	const op = "storage.repo.GetContactIDsByRoles"
+	const query = `
+		SELECT DISTINCT u.contact_id
+		FROM users u
+		JOIN users_roles ur ON ur.user_id = u.id
+		JOIN roles ro ON ro.id = ur.role_id
+		WHERE ro.name = ANY($1) AND u.is_active`
+	return r.queryContactIDs(ctx, op, query, pq.Array(roles))
+}
+
+func (r *Repo) queryContactIDs(ctx context.Context, op, query string, args ...any) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan contact id: %w", op, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+	return ids, nil
+}
+
+// GetNotificationPreferences returns the channels a contact chose, by event
+// type. Event types without a row use their default channels.
+func (r *Repo) GetNotificationPreferences(ctx context.Context, contactID int64) (map[string][]string, error) {
+	const op = "storage.repo.GetNotificationPreferences"
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT event_type, channels FROM notification_preferences WHERE contact_id = $1`, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	prefs := make(map[string][]string)
+	for rows.Next() {
+		var eventType string
+		var channels []string
+		if err := rows.Scan(&eventType, pq.Array(&channels)); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan preference: %w", op, err)
+		}
+		prefs[eventType] = channels
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+	return prefs, nil
+}
+
+// GetNotificationChannels returns the channels chosen for an event type by
+// those of the contacts that have a preference for it.
+func (r *Repo) GetNotificationChannels(ctx context.Context, eventType string, contactIDs []int64) (map[int64][]string, error) {
+	const op = "storage.repo.GetNotificationChannels"
+	const query = `
+		SELECT contact_id, channels FROM notification_preferences
+		WHERE event_type = $1 AND contact_id = ANY($2)`
+
+	rows, err := r.db.QueryContext(ctx, query, eventType, pq.Array(contactIDs))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	channels := make(map[int64][]string)
+	for rows.Next() {
+		var contactID int64
+		var ch []string
+		if err := rows.Scan(&contactID, pq.Array(&ch)); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan preference: %w", op, err)
+		}
+		channels[contactID] = ch
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+	return channels, nil
+}
+
+// SetNotificationPreferences stores the contact's channels per event type;
+// an input with nil Channels removes the preference.
+func (r *Repo) SetNotificationPreferences(ctx context.Context, contactID int64, prefs []notification.PreferenceInput) error {
+	const op = "storage.repo.SetNotificationPreferences"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	for _, p := range prefs {
+		if p.Channels == nil {
+			if _, err := tx.ExecContext(ctx,
+				`DELETE FROM notification_preferences WHERE contact_id = $1 AND event_type = $2`,
+				contactID, p.EventType); err != nil {
+				return fmt.Errorf("%s: failed to delete preference: %w", op, err)
+			}
+			continue
+		}
+		const upsert = `
+			INSERT INTO notification_preferences (contact_id, event_type, channels)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (contact_id, event_type)
+			DO UPDATE SET channels = EXCLUDED.channels, updated_at = NOW()`
+		if _, err := tx.ExecContext(ctx, upsert, contactID, p.EventType, pq.Array(p.Channels)); err != nil {
+			return r.translator.Translate(err, op)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+	return nil
+}
+
+// AddNotificationDeliveries records pending deliveries, due at their
+// NextAttemptAt, and returns their IDs in order.
+func (r *Repo) AddNotificationDeliveries(ctx context.Context, deliveries []notification.Delivery) ([]int64, error) {
+	const op = "storage.repo.AddNotificationDeliveries"
+	const query = `
+		INSERT INTO notification_deliveries (contact_id, event_type, channel, severity, title, message, link, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	ids := make([]int64, len(deliveries))
+	for i, d := range deliveries {
+		if err := tx.QueryRowContext(ctx, query,
+			d.ContactID, d.EventType, d.Channel, d.Severity, d.Title, d.Message, d.Link, d.NextAttemptAt,
+		).Scan(&ids[i]); err != nil {
+			return nil, r.translator.Translate(err, op)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+	return ids, nil
+}
+
+// ClaimNotificationDeliveries picks up to limit pending deliveries on the
+// channels that are due and moves their next attempt lease into the future,
+// so another dispatcher does not pick them up while they are being sent. A
+// dispatcher that dies mid-send leaves them to be retried after the lease.
+func (r *Repo) ClaimNotificationDeliveries(ctx context.Context, channels []string, limit int, lease time.Duration) ([]notification.Delivery, error) {
+	const op = "storage.repo.ClaimNotificationDeliveries"
+	query := `
+		UPDATE notification_deliveries d
+		SET next_attempt_at = NOW() + make_interval(secs => $3)
+		WHERE d.id IN (
+			SELECT id FROM notification_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW() AND channel = ANY($1)
+			ORDER BY next_attempt_at, id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING` + deliveryColumns
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(channels), limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]notification.Delivery, 0)
+	for rows.Next() {
+		var d notification.Delivery
+		if err := scanDelivery(rows, &d); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan delivery: %w", op, err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+	return deliveries, nil
+}
+
+// CompleteNotificationDelivery counts an attempt and closes the delivery
+// with a final status. notificationID links an in-app delivery to the
+// notification it created.
+func (r *Repo) CompleteNotificationDelivery(ctx context.Context, id int64, status string, lastError *string, notificationID *int64) error {
+	const op = "storage.repo.CompleteNotificationDelivery"
+	const query = `
+		UPDATE notification_deliveries
+		SET status = $2,
+			attempts = attempts + 1,
+			last_error = $3,
+			notification_id = COALESCE($4, notification_id),
+			sent_at = CASE WHEN $2 = 'sent' THEN NOW() END
+		WHERE id = $1`
+
+	res, err := r.db.ExecContext(ctx, query, id, status, lastError, notificationID)
+	if err != nil {
+		return r.translator.Translate(err, op)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// RetryNotificationDelivery counts a failed attempt and schedules the next
+// one.
+func (r *Repo) RetryNotificationDelivery(ctx context.Context, id int64, lastError string, at time.Time) error {
+	const op = "storage.repo.RetryNotificationDelivery"
+	const query = `
+		UPDATE notification_deliveries
+		SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		WHERE id = $1 AND status = 'pending'`
+
+	res, err := r.db.ExecContext(ctx, query, id, lastError, at)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// GetNotificationDeliveries returns the delivery log, newest first.
+func (r *Repo) GetNotificationDeliveries(ctx context.Context, f notification.DeliveryFilter) ([]notification.Delivery, error) {
+	const op = "storage.repo.GetNotificationDeliveries"
+
+	var conds []string
+	var args []any
+	add := func(cond string, v any) {
+		args = append(args, v)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.ContactID != nil {
+		add("d.contact_id = $%d", *f.ContactID)
+	}
+	if f.EventType != nil {
+		add("d.event_type = $%d", *f.EventType)
+	}
+	if f.Channel != nil {
+		add("d.channel = $%d", *f.Channel)
+	}
+	if f.Status != nil {
+		add("d.status = $%d", *f.Status)
+	}
+
+	query := `SELECT` + deliveryColumns + `, c.fio
+		FROM notification_deliveries d
+		JOIN contacts c ON c.id = d.contact_id`
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, f.Limit, f.Offset)
+	query += fmt.Sprintf(" ORDER BY d.created_at DESC, d.id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]notification.Delivery, 0)
+	for rows.Next() {
+		var d notification.Delivery
+		var name sql.NullString
+		if err := scanDelivery(rows, &d, &name); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan delivery: %w", op, err)
+		}
+		d.ContactName = nullStringPtr(name)
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+	return deliveries, nil
+}
+
+// GetContactEmail returns the email of a contact, or storage.ErrNotFound
+// when it has none.
+func (r *Repo) GetContactEmail(ctx context.Context, contactID int64) (string, error) {
+	const op = "storage.repo.GetContactEmail"
+
+	var email sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT email FROM contacts WHERE id = $1`, contactID).Scan(&email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", storage.ErrNotFound
+		}
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	if !email.Valid || strings.TrimSpace(email.String) == "" {
+		return "", storage.ErrNotFound
+	}
+	return email.String, nil
+}
+
+// GetTelegramChatID returns the Telegram chat linked to a contact, or
+// storage.ErrNotFound when none is linked.
+func (r *Repo) GetTelegramChatID(ctx context.Context, contactID int64) (int64, error) {
+	const op = "storage.repo.GetTelegramChatID"
+
+	var chatID int64
+	err := r.db.QueryRowContext(ctx,
+		`SELECT chat_id FROM notification_telegram_chats WHERE contact_id = $1`, contactID).Scan(&chatID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, storage.ErrNotFound
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return chatID, nil
+}
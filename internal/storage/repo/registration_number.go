@@ -0,0 +1,539 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"srmt-admin/internal/lib/dto"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
+	registration_number "srmt-admin/internal/lib/model/registration-number"
+	"srmt-admin/internal/lib/model/user"
+	"srmt-admin/internal/storage"
+)
+
+const selectNumberingSchemeFields = `
+	SELECT s.id, s.document_kind, s.organization_id, o.name, s.org_code, s.pattern,
+		s.reset_period, s.allow_reservation, s.is_active, s.created_at, s.updated_at
+	FROM numbering_schemes s
+	LEFT JOIN organizations o ON o.id = s.organization_id`
+
+func scanNumberingScheme(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*registration_number.Scheme, error) {
+	var s registration_number.Scheme
+	var orgID sql.NullInt64
+	var orgName, orgCode sql.NullString
+	var updatedAt sql.NullTime
+	if err := scanner.Scan(&s.ID, &s.DocumentKind, &orgID, &orgName, &orgCode, &s.Pattern,
+		&s.ResetPeriod, &s.AllowReservation, &s.IsActive, &s.CreatedAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	if orgID.Valid {
+		s.OrganizationID = &orgID.Int64
+	}
+	if orgName.Valid {
+		s.OrganizationName = &orgName.String
+	}
+	if orgCode.Valid {
+		s.OrgCode = &orgCode.String
+	}
+	if updatedAt.Valid {
+		s.UpdatedAt = &updatedAt.Time
+	}
+	return &s, nil
+}
+
+// GetNumberingSchemes lists the numbering schemes, optionally of one kind
+func (r *Repo) GetNumberingSchemes(ctx context.Context, kind *string) ([]registration_number.Scheme, error) {
+	const op = "storage.repo.GetNumberingSchemes"
+
+	query := selectNumberingSchemeFields
+	var args []interface{}
+	if kind != nil {
+		query += ` WHERE s.document_kind = $1`
+		args = append(args, *kind)
+	}
+	query += ` ORDER BY s.document_kind, s.organization_id NULLS FIRST`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query schemes: %w", op, err)
+	}
+	defer rows.Close()
+
+	schemes := make([]registration_number.Scheme, 0)
+	for rows.Next() {
+		s, err := scanNumberingScheme(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to scan scheme: %w", op, err)
+		}
+		schemes = append(schemes, *s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows iteration error: %w", op, err)
+	}
+	return schemes, nil
+}
+
+// GetNumberingScheme retrieves one scheme
+func (r *Repo) GetNumberingScheme(ctx context.Context, id int64) (*registration_number.Scheme, error) {
+	const op = "storage.repo.GetNumberingScheme"
+
+	s, err := scanNumberingScheme(r.db.QueryRowContext(ctx, selectNumberingSchemeFields+` WHERE s.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return s, nil
+}
+
+// FindNumberingScheme returns the active scheme numbering documents of kind
+// for the organization: the organization's own scheme, else the kind's
+// default. It returns storage.ErrNotFound when the kind is numbered by hand.
+func (r *Repo) FindNumberingScheme(ctx context.Context, kind string, orgID *int64) (*registration_number.Scheme, error) {
+	const op = "storage.repo.FindNumberingScheme"
+
+	s, err := findNumberingScheme(ctx, r.db, kind, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if s == nil {
+		return nil, storage.ErrNotFound
+	}
+	return s, nil
+}
+
+func findNumberingScheme(ctx context.Context, q interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}, kind string, orgID *int64) (*registration_number.Scheme, error) {
+	query := selectNumberingSchemeFields + `
+		WHERE s.document_kind = $1 AND s.is_active
+		  AND (s.organization_id = $2 OR s.organization_id IS NULL)
+		ORDER BY s.organization_id NULLS LAST
+		LIMIT 1`
+
+	s, err := scanNumberingScheme(q.QueryRowContext(ctx, query, kind, orgID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find numbering scheme: %w", err)
+	}
+	return s, nil
+}
+
+// AddNumberingScheme creates a scheme. A second scheme for the same kind and
+// organization is storage.ErrDuplicate.
+func (r *Repo) AddNumberingScheme(ctx context.Context, req registration_number.AddSchemeRequest, userID int64) (int64, error) {
+	const op = "storage.repo.AddNumberingScheme"
+
+	const query = `
+		INSERT INTO numbering_schemes (document_kind, organization_id, org_code, pattern,
+			reset_period, allow_reservation, created_by_user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, query, req.DocumentKind, req.OrganizationID, req.OrgCode, req.Pattern,
+		req.ResetPeriod, req.AllowReservation, userID).Scan(&id)
+	if err != nil {
+		return 0, r.translator.Translate(err, op)
+	}
+	return id, nil
+}
+
+// EditNumberingScheme updates a scheme. Counters and the journal are kept,
+// so a changed pattern applies to numbers issued from now on.
+func (r *Repo) EditNumberingScheme(ctx context.Context, id int64, req registration_number.EditSchemeRequest) error {
+	const op = "storage.repo.EditNumberingScheme"
+
+	var updates []string
+	var args []interface{}
+	argID := 1
+
+	if req.OrgCode != nil {
+		updates = append(updates, fmt.Sprintf("org_code = $%d", argID))
+		args = append(args, *req.OrgCode)
+		argID++
+	}
+	if req.Pattern != nil {
+		updates = append(updates, fmt.Sprintf("pattern = $%d", argID))
+		args = append(args, *req.Pattern)
+		argID++
+	}
+	if req.ResetPeriod != nil {
+		updates = append(updates, fmt.Sprintf("reset_period = $%d", argID))
+		args = append(args, *req.ResetPeriod)
+		argID++
+	}
+	if req.AllowReservation != nil {
+		updates = append(updates, fmt.Sprintf("allow_reservation = $%d", argID))
+		args = append(args, *req.AllowReservation)
+		argID++
+	}
+	if req.IsActive != nil {
+		updates = append(updates, fmt.Sprintf("is_active = $%d", argID))
+		args = append(args, *req.IsActive)
+		argID++
+	}
+
+	if len(updates) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf("UPDATE numbering_schemes SET %s WHERE id = $%d", strings.Join(updates, ", "), argID)
+	args = append(args, id)
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return r.translator.Translate(err, op)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get affected rows: %w", op, err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// NumberingSchemeInUse reports whether the scheme has numbers in the journal,
+// cancelled ones included.
+func (r *Repo) NumberingSchemeInUse(ctx context.Context, id int64) (bool, error) {
+	const op = "storage.repo.NumberingSchemeInUse"
+
+	var inUse bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM registration_numbers WHERE scheme_id = $1)`, id,
+	).Scan(&inUse)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return inUse, nil
+}
+
+// PeekRegistrationNumber returns the number the scheme would issue next on
+// date without allocating it.
+func (r *Repo) PeekRegistrationNumber(ctx context.Context, scheme registration_number.Scheme, date time.Time) (string, error) {
+	const op = "storage.repo.PeekRegistrationNumber"
+
+	var last int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COALESCE((SELECT last_seq FROM numbering_counters WHERE scheme_id = $1 AND period = $2), 0)`,
+		scheme.ID, scheme.Period(date),
+	).Scan(&last)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	return scheme.Format(last+1, date), nil
+}
+
+// allocatedNumber is a number taken from a scheme counter
+type allocatedNumber struct {
+	scheme registration_number.Scheme
+	period int
+	seq    int
+	number string
+	date   time.Time
+}
+
+// allocateRegistrationNumber takes the next value of the scheme's counter for
+// the period of date. The upsert locks the counter row until the caller's
+// transaction ends, so concurrent registrations get consecutive numbers and
+// a rolled back registration leaves no gap.
+func allocateRegistrationNumber(ctx context.Context, tx *sql.Tx, scheme registration_number.Scheme, date time.Time) (*allocatedNumber, error) {
+	period := scheme.Period(date)
+
+	const query = `
+		INSERT INTO numbering_counters (scheme_id, period, last_seq)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (scheme_id, period) DO UPDATE SET last_seq = numbering_counters.last_seq + 1
+		RETURNING last_seq`
+
+	var seq int
+	if err := tx.QueryRowContext(ctx, query, scheme.ID, period).Scan(&seq); err != nil {
+		return nil, fmt.Errorf("failed to allocate sequence: %w", err)
+	}
+	return &allocatedNumber{
+		scheme: scheme,
+		period: period,
+		seq:    seq,
+		number: scheme.Format(seq, date),
+		date:   date,
+	}, nil
+}
+
+// insertRegistrationEntry writes the allocated number to the journal
+func (r *Repo) insertRegistrationEntry(ctx context.Context, tx *sql.Tx, n *allocatedNumber, status string, documentID *int64, userID int64, op string) (int64, error) {
+	const query = `
+		INSERT INTO registration_numbers (scheme_id, document_kind, organization_id, period, seq, number,
+			registered_on, status, document_id, reserved_by_user_id, issued_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, CASE WHEN $8 = 'issued' THEN NOW() END)
+		RETURNING id`
+
+	var id int64
+	err := tx.QueryRowContext(ctx, query, n.scheme.ID, n.scheme.DocumentKind, n.scheme.OrganizationID,
+		n.period, n.seq, n.number, n.date, status, documentID, userID,
+	).Scan(&id)
+	if err != nil {
+		return 0, r.translator.Translate(err, op)
+	}
+	return id, nil
+}
+
+// ReserveRegistrationNumber allocates the next number of scheme and records
+// it as reserved by userID. The reservation is consumed by registering a
+// document with its id, or withdrawn by cancelling it.
+func (r *Repo) ReserveRegistrationNumber(ctx context.Context, scheme registration_number.Scheme, date time.Time, userID int64) (int64, string, error) {
+	const op = "storage.repo.ReserveRegistrationNumber"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	n, err := allocateRegistrationNumber(ctx, tx, scheme, date)
+	if err != nil {
+		return 0, "", fmt.Errorf("%s: %w", op, err)
+	}
+	id, err := r.insertRegistrationEntry(ctx, tx, n, registration_number.StatusReserved, nil, userID, op)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, "", fmt.Errorf("%s: failed to commit: %w", op, err)
+	}
+	return id, n.number, nil
+}
+
+// CancelRegistrationNumber withdraws a reserved number. The number stays in
+// the journal and is not issued again. Issued or already cancelled numbers
+// are storage.ErrInvalidStatus.
+func (r *Repo) CancelRegistrationNumber(ctx context.Context, id int64, reason *string, userID int64) error {
+	const op = "storage.repo.CancelRegistrationNumber"
+
+	const query = `
+		UPDATE registration_numbers
+		SET status = 'cancelled', cancelled_at = NOW(), cancelled_by_user_id = $2, cancel_reason = $3
+		WHERE id = $1 AND status = 'reserved'`
+
+	res, err := r.db.ExecContext(ctx, query, id, userID, reason)
+	if err != nil {
+		return r.translator.Translate(err, op)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get affected rows: %w", op, err)
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM registration_numbers WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return storage.ErrNotFound
+	}
+	return storage.ErrInvalidStatus
+}
+
+// registerDocumentNumber decides the number of a new document of kind. With
+// no active scheme the typed number is kept. Otherwise the number comes from
+// the reservation named in req, or is allocated from the scheme; typing a
+// number by hand is then storage.ErrNumberAssigned. A reservation must be
+// taken from the scheme that numbers the document's kind and organization.
+func registerDocumentNumber(ctx context.Context, tx *sql.Tx, kind document_kind.Model, req dto.AddDocumentRequest) (*allocatedNumber, *int64, error) {
+	scheme, err := findNumberingScheme(ctx, tx, kind.Code, req.OrganizationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if req.ReservationID != nil {
+		var n allocatedNumber
+		var status string
+		err := tx.QueryRowContext(ctx, `
+			SELECT scheme_id, document_kind, period, seq, number, registered_on, status
+			FROM registration_numbers WHERE id = $1 FOR UPDATE`, *req.ReservationID,
+		).Scan(&n.scheme.ID, &n.scheme.DocumentKind, &n.period, &n.seq, &n.number, &n.date, &status)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, nil, fmt.Errorf("reservation %d: %w", *req.ReservationID, storage.ErrNotFound)
+			}
+			return nil, nil, fmt.Errorf("failed to lock reservation: %w", err)
+		}
+		if status != registration_number.StatusReserved || n.scheme.DocumentKind != kind.Code {
+			return nil, nil, fmt.Errorf("reservation %d is %s for %s: %w", *req.ReservationID, status, n.scheme.DocumentKind, storage.ErrInvalidStatus)
+		}
+		if scheme == nil || scheme.ID != n.scheme.ID {
+			return nil, nil, fmt.Errorf("reservation %d is not of the scheme numbering the document's organization: %w", *req.ReservationID, storage.ErrInvalidStatus)
+		}
+		return &n, req.ReservationID, nil
+	}
+
+	if scheme == nil {
+		return nil, nil, nil
+	}
+	if req.Number != nil && strings.TrimSpace(*req.Number) != "" {
+		return nil, nil, storage.ErrNumberAssigned
+	}
+	n, err := allocateRegistrationNumber(ctx, tx, *scheme, req.DocumentDate)
+	if err != nil {
+		return nil, nil, err
+	}
+	return n, nil, nil
+}
+
+// recordDocumentNumber marks the number of the new document as issued in the
+// journal: a consumed reservation is updated, an allocated number is added.
+func (r *Repo) recordDocumentNumber(ctx context.Context, tx *sql.Tx, n *allocatedNumber, reservationID *int64, docID, userID int64, op string) error {
+	if reservationID != nil {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE registration_numbers SET status = 'issued', document_id = $2, issued_at = NOW()
+			WHERE id = $1`, *reservationID, docID)
+		if err != nil {
+			return r.translator.Translate(err, op)
+		}
+		return nil
+	}
+	_, err := r.insertRegistrationEntry(ctx, tx, n, registration_number.StatusIssued, &docID, userID, op)
+	return err
+}
+
+// documentNumberIssued reports whether the document's number was issued from
+// a numbering scheme; such numbers cannot be edited.
+func documentNumberIssued(ctx context.Context, tx *sql.Tx, kind document_kind.Model, docID int64) (bool, error) {
+	var issued bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM registration_numbers
+		               WHERE document_kind = $1 AND document_id = $2 AND status = 'issued')`,
+		kind.Code, docID,
+	).Scan(&issued)
+	if err != nil {
+		return false, fmt.Errorf("failed to check issued number: %w", err)
+	}
+	return issued, nil
+}
+
+// cancelDocumentNumber withdraws the number of a deleted document so the
+// journal shows why it has no document.
+func cancelDocumentNumber(ctx context.Context, tx *sql.Tx, kind document_kind.Model, docID int64) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE registration_numbers
+		SET status = 'cancelled', cancelled_at = NOW(), cancel_reason = 'document deleted'
+		WHERE document_kind = $1 AND document_id = $2 AND status = 'issued'`,
+		kind.Code, docID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to cancel issued number: %w", err)
+	}
+	return nil
+}
+
+// GetRegistrationJournal lists journal entries in registration order
+func (r *Repo) GetRegistrationJournal(ctx context.Context, f registration_number.JournalFilter) ([]registration_number.Entry, error) {
+	const op = "storage.repo.GetRegistrationJournal"
+
+	query := `
+		SELECT n.id, n.scheme_id, n.document_kind, n.organization_id, o.name, n.period, n.seq, n.number,
+			n.registered_on, n.status, n.document_id, d.name,
+			n.reserved_by_user_id, COALESCE(ru.fio, ''), n.issued_at,
+			n.cancelled_at, n.cancelled_by_user_id, COALESCE(cu.fio, ''), n.cancel_reason, n.created_at
+		FROM registration_numbers n
+		LEFT JOIN organizations o ON o.id = n.organization_id
+		LEFT JOIN registry_documents d ON d.document_type = n.document_kind AND d.id = n.document_id
+		LEFT JOIN users ru_u ON ru_u.id = n.reserved_by_user_id
+		LEFT JOIN contacts ru ON ru.id = ru_u.contact_id
+		LEFT JOIN users cu_u ON cu_u.id = n.cancelled_by_user_id
+		LEFT JOIN contacts cu ON cu.id = cu_u.contact_id`
+
+	var conditions []string
+	var args []interface{}
+	argID := 1
+	if f.DocumentKind != nil {
+		conditions = append(conditions, fmt.Sprintf("n.document_kind = $%d", argID))
+		args = append(args, *f.DocumentKind)
+		argID++
+	}
+	if f.OrganizationID != nil {
+		conditions = append(conditions, fmt.Sprintf("n.organization_id = $%d", argID))
+		args = append(args, *f.OrganizationID)
+		argID++
+	}
+	if f.Year != nil {
+		conditions = append(conditions, fmt.Sprintf("EXTRACT(YEAR FROM n.registered_on) = $%d", argID))
+		args = append(args, *f.Year)
+		argID++
+	}
+	if f.Status != nil {
+		conditions = append(conditions, fmt.Sprintf("n.status = $%d", argID))
+		args = append(args, *f.Status)
+		argID++
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY n.document_kind, n.registered_on, n.scheme_id, n.period, n.seq"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query journal: %w", op, err)
+	}
+	defer rows.Close()
+
+	entries := make([]registration_number.Entry, 0)
+	for rows.Next() {
+		var e registration_number.Entry
+		var orgID, docID, reservedByID, cancelledByID sql.NullInt64
+		var orgName, docName, reservedByName, cancelledByName, reason sql.NullString
+		var issuedAt, cancelledAt sql.NullTime
+		if err := rows.Scan(&e.ID, &e.SchemeID, &e.DocumentKind, &orgID, &orgName, &e.Period, &e.Seq, &e.Number,
+			&e.RegisteredOn, &e.Status, &docID, &docName,
+			&reservedByID, &reservedByName, &issuedAt,
+			&cancelledAt, &cancelledByID, &cancelledByName, &reason, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan journal entry: %w", op, err)
+		}
+		if orgID.Valid {
+			e.OrganizationID = &orgID.Int64
+		}
+		if orgName.Valid {
+			e.OrganizationName = &orgName.String
+		}
+		if docID.Valid {
+			e.DocumentID = &docID.Int64
+		}
+		if docName.Valid {
+			e.DocumentName = &docName.String
+		}
+		if reservedByID.Valid {
+			e.ReservedBy = &user.ShortInfo{ID: reservedByID.Int64, Name: &reservedByName.String}
+		}
+		if issuedAt.Valid {
+			e.IssuedAt = &issuedAt.Time
+		}
+		if cancelledAt.Valid {
+			e.CancelledAt = &cancelledAt.Time
+		}
+		if cancelledByID.Valid {
+			e.CancelledBy = &user.ShortInfo{ID: cancelledByID.Int64, Name: &cancelledByName.String}
+		}
+		if reason.Valid {
+			e.CancelReason = &reason.String
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows iteration error: %w", op, err)
+	}
+	return entries, nil
+}
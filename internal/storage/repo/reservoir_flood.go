@@ -129,13 +129,13 @@ func (r *Repo) UpsertReservoirFloodHourly(ctx context.Context, items []model.Ups
 		INSERT INTO reservoir_flood_hourly (
 			organization_id, recorded_at,
 			water_level_m, water_volume_mln_m3, inflow_m3s, outflow_m3s,
-			ges_flow_m3s, idle_discharge_m3s, duty_name,
+			ges_flow_m3s, idle_discharge_m3s, duty_name, duty_employee_id,
 			capacity_mwt, weather_condition, temperature_c,
 			created_by_user_id, updated_by_user_id, created_at, updated_at
 		)
 		VALUES ($1, $2,
 		        $3, $4, $5, $6,
-		        $7, $8, $9,
+		        $7, $8, $9, $24,
 		        $10, $11, $12,
 		        $13, $13, NOW(), NOW())
 		ON CONFLICT (organization_id, recorded_at) DO UPDATE SET
@@ -146,6 +146,7 @@ func (r *Repo) UpsertReservoirFloodHourly(ctx context.Context, items []model.Ups
 			ges_flow_m3s        = CASE WHEN $18::boolean THEN EXCLUDED.ges_flow_m3s        ELSE reservoir_flood_hourly.ges_flow_m3s        END,
 			idle_discharge_m3s  = CASE WHEN $19::boolean THEN EXCLUDED.idle_discharge_m3s  ELSE reservoir_flood_hourly.idle_discharge_m3s  END,
 			duty_name           = CASE WHEN $20::boolean THEN EXCLUDED.duty_name           ELSE reservoir_flood_hourly.duty_name           END,
+			duty_employee_id    = CASE WHEN $25::boolean THEN EXCLUDED.duty_employee_id    ELSE reservoir_flood_hourly.duty_employee_id    END,
 			capacity_mwt        = CASE WHEN $21::boolean THEN EXCLUDED.capacity_mwt        ELSE reservoir_flood_hourly.capacity_mwt        END,
 			weather_condition   = CASE WHEN $22::boolean THEN EXCLUDED.weather_condition   ELSE reservoir_flood_hourly.weather_condition   END,
 			temperature_c       = CASE WHEN $23::boolean THEN EXCLUDED.temperature_c       ELSE reservoir_flood_hourly.temperature_c       END,
@@ -181,6 +182,7 @@ func (r *Repo) UpsertReservoirFloodHourly(ctx context.Context, items []model.Ups
 			it.DutyName.Set, // $20
 			it.CapacityMwt.Set, it.WeatherCondition.Set, // $21, $22
 			it.TemperatureC.Set, // $23
+			it.DutyEmployeeID.Value, it.DutyEmployeeID.Set, // $24, $25
 		); execErr != nil {
 			if translatedErr := r.translator.Translate(execErr, op); translatedErr != nil {
 				return translatedErr
@@ -213,12 +215,13 @@ func (r *Repo) GetReservoirFloodHourlyRange(ctx context.Context, orgIDs []int64,
 			       h.recorded_at,
 			       h.water_level_m, h.water_volume_mln_m3, h.inflow_m3s, h.outflow_m3s,
 			       h.ges_flow_m3s, h.idle_discharge_m3s,
-			       h.duty_name,
+			       h.duty_name, COALESCE(h.duty_employee_id, ds.employee_id), dc.fio,
 			       h.capacity_mwt, h.weather_condition, h.temperature_c,
 			       h.created_by_user_id, h.updated_at
 			FROM reservoir_flood_hourly h
 			JOIN reservoir_flood_config c ON c.organization_id = h.organization_id AND c.is_active = TRUE
 			LEFT JOIN organizations o ON o.id = h.organization_id
+			` + hourlyDutyOfficerJoin + `
 			WHERE h.recorded_at >= $1 AND h.recorded_at < $2
 			ORDER BY h.organization_id, h.recorded_at`
 		rows, err = r.db.QueryContext(ctx, query, start, end)
@@ -228,11 +231,12 @@ func (r *Repo) GetReservoirFloodHourlyRange(ctx context.Context, orgIDs []int64,
 			       h.recorded_at,
 			       h.water_level_m, h.water_volume_mln_m3, h.inflow_m3s, h.outflow_m3s,
 			       h.ges_flow_m3s, h.idle_discharge_m3s,
-			       h.duty_name,
+			       h.duty_name, COALESCE(h.duty_employee_id, ds.employee_id), dc.fio,
 			       h.capacity_mwt, h.weather_condition, h.temperature_c,
 			       h.created_by_user_id, h.updated_at
 			FROM reservoir_flood_hourly h
 			LEFT JOIN organizations o ON o.id = h.organization_id
+			` + hourlyDutyOfficerJoin + `
 			WHERE h.recorded_at >= $1 AND h.recorded_at < $2
 			  AND h.organization_id = ANY($3)
 			ORDER BY h.organization_id, h.recorded_at`
@@ -257,18 +261,38 @@ func (r *Repo) GetReservoirFloodHourlyRange(ctx context.Context, orgIDs []int64,
 	return out, nil
 }
 
+// hourlyDutyOfficerJoin resolves the duty officer of an hourly row (alias h):
+// the explicitly linked employee, else — when no name was typed either —
+// whoever the roster had on a shift covering recorded_at. A typed name is
+// never overridden by the roster. Selected as COALESCE(h.duty_employee_id,
+// ds.employee_id) and dc.fio.
+const hourlyDutyOfficerJoin = `
+		LEFT JOIN LATERAL (
+		    SELECT s.employee_id
+		    FROM duty_shifts s
+		    WHERE h.duty_name IS NULL
+		      AND s.organization_id = h.organization_id
+		      AND s.starts_at <= h.recorded_at AND s.ends_at > h.recorded_at
+		    ORDER BY s.starts_at DESC, s.id
+		    LIMIT 1
+		) ds ON TRUE
+		LEFT JOIN contacts dc ON dc.id = COALESCE(h.duty_employee_id, ds.employee_id)`
+
 // scanHourlyRecord reads a single hourly row from rows. The column order must
 // match the SELECT lists in GetReservoirFloodHourlyRange and
 // GetReservoirFloodHourlyLatestBefore: id, organization_id, name, recorded_at,
 // water_level_m, water_volume_mln_m3, inflow_m3s, outflow_m3s, ges_flow_m3s,
-// idle_discharge_m3s, duty_name, capacity_mwt, weather_condition,
-// temperature_c, created_by_user_id, updated_at.
+// idle_discharge_m3s, duty_name, duty employee id, duty employee name,
+// capacity_mwt, weather_condition, temperature_c, created_by_user_id,
+// updated_at.
 func scanHourlyRecord(rows *sql.Rows) (model.HourlyRecord, error) {
 	var rec model.HourlyRecord
 	var (
 		waterLevel, waterVolume, inflow, outflow sql.NullFloat64
 		gesFlow, idleDischarge                   sql.NullFloat64
 		dutyName                                 sql.NullString
+		dutyEmployeeID                           sql.NullInt64
+		dutyEmployeeName                         sql.NullString
 		capacityMwt                              sql.NullFloat64
 		weatherCondition                         sql.NullString
 		temperatureC                             sql.NullFloat64
@@ -279,7 +303,7 @@ func scanHourlyRecord(rows *sql.Rows) (model.HourlyRecord, error) {
 		&rec.RecordedAt,
 		&waterLevel, &waterVolume, &inflow, &outflow,
 		&gesFlow, &idleDischarge,
-		&dutyName,
+		&dutyName, &dutyEmployeeID, &dutyEmployeeName,
 		&capacityMwt, &weatherCondition, &temperatureC,
 		&createdBy, &rec.UpdatedAt,
 	); err != nil {
@@ -313,6 +337,14 @@ func scanHourlyRecord(rows *sql.Rows) (model.HourlyRecord, error) {
 		s := dutyName.String
 		rec.DutyName = &s
 	}
+	if dutyEmployeeID.Valid {
+		i := dutyEmployeeID.Int64
+		rec.DutyEmployeeID = &i
+	}
+	if dutyEmployeeName.Valid {
+		s := dutyEmployeeName.String
+		rec.DutyEmployeeName = &s
+	}
 	if capacityMwt.Valid {
 		v := capacityMwt.Float64
 		rec.CapacityMwt = &v
@@ -358,7 +390,7 @@ func (r *Repo) GetReservoirFloodHourlyLatestBefore(ctx context.Context, orgIDs [
 		       h.recorded_at,
 		       h.water_level_m, h.water_volume_mln_m3, h.inflow_m3s, h.outflow_m3s,
 		       h.ges_flow_m3s, h.idle_discharge_m3s,
-		       h.duty_name,
+		       h.duty_name, COALESCE(h.duty_employee_id, ds.employee_id), dc.fio,
 		       h.capacity_mwt, h.weather_condition, h.temperature_c,
 		       h.created_by_user_id, h.updated_at
 		FROM unnest($1::bigint[]) AS orgs(organization_id)
@@ -371,6 +403,7 @@ func (r *Repo) GetReservoirFloodHourlyLatestBefore(ctx context.Context, orgIDs [
 		    LIMIT 1
 		) h ON TRUE
 		LEFT JOIN organizations o ON o.id = h.organization_id
+		` + hourlyDutyOfficerJoin + `
 		ORDER BY h.organization_id`
 
 	rows, err := r.db.QueryContext(ctx, query, pq.Array(orgIDs), before)
@@ -0,0 +1,102 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"srmt-admin/internal/lib/model/runoff"
+	"srmt-admin/internal/storage"
+)
+
+// GetReservoirOrganization returns the reservoir name and the organization
+// its snow cover is recorded under (nil when not linked).
+func (r *Repo) GetReservoirOrganization(ctx context.Context, resID int) (string, *int64, error) {
+	const op = "storage.repo.runoff.GetReservoirOrganization"
+
+	var name string
+	var orgID sql.NullInt64
+	err := r.db.QueryRowContext(ctx, "SELECT name, organization_id FROM reservoirs WHERE id = $1", resID).Scan(&name, &orgID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil, storage.ErrNotFound
+		}
+		return "", nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !orgID.Valid {
+		return name, nil, nil
+	}
+	return name, &orgID.Int64, nil
+}
+
+// GetSeasonInflowByYears sums the inflow volume (mln m³, as in
+// GetDataByYears) of months fromMonth..toMonth for every year.
+func (r *Repo) GetSeasonInflowByYears(ctx context.Context, resID int, fromMonth, toMonth int) ([]runoff.YearValue, error) {
+	const op = "storage.repo.runoff.GetSeasonInflowByYears"
+
+	const query = `
+		SELECT EXTRACT(YEAR FROM dv.date)::int AS year,
+		       SUM(dv.income * 86400) / 1000000 AS value,
+		       COUNT(*) AS days
+		FROM data dv
+		WHERE dv.res_id = $1
+		  AND EXTRACT(MONTH FROM dv.date) BETWEEN $2 AND $3
+		  AND dv.income IS NOT NULL
+		GROUP BY year
+		ORDER BY year`
+
+	rows, err := r.db.QueryContext(ctx, query, resID, fromMonth, toMonth)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to execute query: %w", op, err)
+	}
+	defer rows.Close()
+
+	result := make([]runoff.YearValue, 0)
+	for rows.Next() {
+		var v runoff.YearValue
+		if err := rows.Scan(&v.Year, &v.Value, &v.Days); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan row: %w", op, err)
+		}
+		result = append(result, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error during rows iteration: %w", op, err)
+	}
+
+	return result, nil
+}
+
+// GetSnowCoverNearDay returns the modsnow cover readings of the organization
+// within toleranceDays of month/day in any year, oldest first. month/day must
+// exist in every year (callers map 29 Feb to 28 Feb).
+func (r *Repo) GetSnowCoverNearDay(ctx context.Context, orgID int64, month, day, toleranceDays int) ([]runoff.CoverReading, error) {
+	const op = "storage.repo.runoff.GetSnowCoverNearDay"
+
+	const query = `
+		SELECT m.date::text, m.cover
+		FROM modsnow m
+		WHERE m.organization_id = $1
+		  AND m.cover IS NOT NULL
+		  AND ABS(m.date - make_date(EXTRACT(YEAR FROM m.date)::int, $2, $3)) <= $4
+		ORDER BY m.date`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID, month, day, toleranceDays)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to execute query: %w", op, err)
+	}
+	defer rows.Close()
+
+	result := make([]runoff.CoverReading, 0)
+	for rows.Next() {
+		var c runoff.CoverReading
+		if err := rows.Scan(&c.Date, &c.Cover); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan row: %w", op, err)
+		}
+		result = append(result, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error during rows iteration: %w", op, err)
+	}
+
+	return result, nil
+}
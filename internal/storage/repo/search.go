@@ -0,0 +1,322 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"srmt-admin/internal/lib/model/search"
+	"srmt-admin/internal/storage"
+
+	"github.com/lib/pq"
+)
+
+const searchHeadlineOptions = "StartSel=<mark>, StopSel=</mark>"
+
+// searchConditions builds the WHERE clause shared by the hit, count and
+// facet queries. $1 is the query text; withFacetFilters adds the
+// kind/type/status/year filters the facets ignore.
+func searchConditions(q search.Query, scope search.Scope, withFacetFilters bool) (string, []interface{}) {
+	conditions := []string{"e.tsv @@ q.query"}
+	args := []interface{}{q.Text}
+	argID := 2
+
+	if !scope.Registry {
+		conditions = append(conditions, fmt.Sprintf("e.entity_type = '%s'", search.EntityLegalDocument))
+	} else if !scope.All {
+		conditions = append(conditions, fmt.Sprintf("(e.entity_type = '%s' OR e.organization_id = ANY($%d))", search.EntityLegalDocument, argID))
+		args = append(args, pq.Array(scope.OrganizationIDs))
+		argID++
+	}
+	if q.OrganizationID != nil {
+		conditions = append(conditions, fmt.Sprintf("e.organization_id = $%d", argID))
+		args = append(args, *q.OrganizationID)
+		argID++
+	}
+
+	if withFacetFilters {
+		if len(q.Kinds) > 0 {
+			conditions = append(conditions, fmt.Sprintf("e.entity_type = ANY($%d)", argID))
+			args = append(args, pq.Array(q.Kinds))
+			argID++
+		}
+		if q.TypeID != nil {
+			conditions = append(conditions, fmt.Sprintf("e.type_id = $%d", argID))
+			args = append(args, *q.TypeID)
+			argID++
+		}
+		if q.StatusID != nil {
+			conditions = append(conditions, fmt.Sprintf("e.status_id = $%d", argID))
+			args = append(args, *q.StatusID)
+			argID++
+		}
+		if q.Year != nil {
+			conditions = append(conditions, fmt.Sprintf("EXTRACT(YEAR FROM e.document_date) = $%d", argID))
+			args = append(args, *q.Year)
+			argID++
+		}
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+const searchQueryCTE = `
+	WITH q AS (
+		SELECT websearch_to_tsquery('russian', $1) || websearch_to_tsquery('uzbek', $1) AS query
+	)`
+
+// SearchDocuments runs a full-text query over search_entries. Hits are ranked
+// by ts_rank_cd with title and number weighted highest; highlighting is done
+// for the returned page only.
+func (r *Repo) SearchDocuments(ctx context.Context, q search.Query, scope search.Scope) (*search.Result, error) {
+	const op = "storage.repo.SearchDocuments"
+
+	where, args := searchConditions(q, scope, true)
+	result := &search.Result{Hits: make([]search.Hit, 0)}
+
+	countQuery := searchQueryCTE + `
+		SELECT COUNT(*) FROM search_entries e, q WHERE ` + where
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&result.Total); err != nil {
+		return nil, fmt.Errorf("%s: failed to count matches: %w", op, err)
+	}
+
+	hitsQuery := searchQueryCTE + fmt.Sprintf(`
+		SELECT e.entity_type, e.entity_id, e.title, e.number, e.document_date, e.type_id, e.type_name,
+			e.status_id, s.name, e.organization_id, o.name, e.score,
+			ts_headline('russian', e.title, q.query, '%[1]s, HighlightAll=true'),
+			ts_headline('russian', concat_ws(E'\n', e.description, e.resolutions, e.attachments), q.query,
+				'%[1]s, MaxFragments=2, MinWords=5, MaxWords=25')
+		FROM (
+			SELECT e.*, ts_rank_cd(e.tsv, q.query) AS score
+			FROM search_entries e, q
+			WHERE %[2]s
+			ORDER BY score DESC, e.document_date DESC NULLS LAST, e.entity_id DESC
+			LIMIT $%[3]d OFFSET $%[4]d
+		) e
+		CROSS JOIN q
+		LEFT JOIN document_status s ON s.id = e.status_id
+		LEFT JOIN organizations o ON o.id = e.organization_id
+		ORDER BY e.score DESC, e.document_date DESC NULLS LAST, e.entity_id DESC`,
+		searchHeadlineOptions, where, len(args)+1, len(args)+2)
+
+	rows, err := r.db.QueryContext(ctx, hitsQuery, append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query hits: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var h search.Hit
+		var number, typeName, statusName, orgName, snippet sql.NullString
+		var documentDate sql.NullTime
+		var typeID, statusID sql.NullInt32
+		var orgID sql.NullInt64
+		if err := rows.Scan(&h.EntityType, &h.EntityID, &h.Title, &number, &documentDate, &typeID, &typeName,
+			&statusID, &statusName, &orgID, &orgName, &h.Rank, &h.TitleHighlight, &snippet); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan hit: %w", op, err)
+		}
+		if number.Valid {
+			h.Number = &number.String
+		}
+		if documentDate.Valid {
+			h.DocumentDate = &documentDate.Time
+		}
+		if typeID.Valid {
+			v := int(typeID.Int32)
+			h.TypeID = &v
+		}
+		if typeName.Valid {
+			h.TypeName = &typeName.String
+		}
+		if statusID.Valid {
+			v := int(statusID.Int32)
+			h.StatusID = &v
+		}
+		if statusName.Valid {
+			h.StatusName = &statusName.String
+		}
+		if orgID.Valid {
+			h.OrganizationID = &orgID.Int64
+		}
+		if orgName.Valid {
+			h.OrganizationName = &orgName.String
+		}
+		// ts_headline returns the start of the text when nothing in it
+		// matched; only keep snippets that show a match.
+		if snippet.Valid && strings.Contains(snippet.String, "<mark>") {
+			h.Snippet = snippet.String
+		}
+		result.Hits = append(result.Hits, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows iteration error: %w", op, err)
+	}
+
+	facets, err := r.searchFacets(ctx, q, scope)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	result.Facets = *facets
+
+	return result, nil
+}
+
+func (r *Repo) searchFacets(ctx context.Context, q search.Query, scope search.Scope) (*search.Facets, error) {
+	where, args := searchConditions(q, scope, false)
+
+	query := searchQueryCTE + `,
+		m AS (
+			SELECT e.entity_type, e.type_id, e.type_name, e.status_id, e.document_date
+			FROM search_entries e, q
+			WHERE ` + where + `
+		)
+		SELECT 'kind', m.entity_type, COALESCE(k.name, 'Нормативно-правовой документ'), COUNT(*)
+		FROM m LEFT JOIN document_kinds k ON k.code = m.entity_type
+		GROUP BY m.entity_type, k.name
+		UNION ALL
+		SELECT 'type', m.entity_type || ':' || m.type_id, m.type_name, COUNT(*)
+		FROM m WHERE m.type_id IS NOT NULL
+		GROUP BY m.entity_type, m.type_id, m.type_name
+		UNION ALL
+		SELECT 'status', m.status_id::TEXT, s.name, COUNT(*)
+		FROM m JOIN document_status s ON s.id = m.status_id
+		GROUP BY m.status_id, s.name
+		UNION ALL
+		SELECT 'year', EXTRACT(YEAR FROM m.document_date)::INTEGER::TEXT,
+			EXTRACT(YEAR FROM m.document_date)::INTEGER::TEXT, COUNT(*)
+		FROM m WHERE m.document_date IS NOT NULL
+		GROUP BY EXTRACT(YEAR FROM m.document_date)
+		ORDER BY 1, 4 DESC, 2`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query facets: %w", err)
+	}
+	defer rows.Close()
+
+	facets := &search.Facets{
+		Kinds:    make([]search.FacetValue, 0),
+		Types:    make([]search.FacetValue, 0),
+		Statuses: make([]search.FacetValue, 0),
+		Years:    make([]search.FacetValue, 0),
+	}
+	for rows.Next() {
+		var facet string
+		var v search.FacetValue
+		var label sql.NullString
+		if err := rows.Scan(&facet, &v.Key, &label, &v.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan facet: %w", err)
+		}
+		v.Label = label.String
+		switch facet {
+		case "kind":
+			facets.Kinds = append(facets.Kinds, v)
+		case "type":
+			facets.Types = append(facets.Types, v)
+		case "status":
+			facets.Statuses = append(facets.Statuses, v)
+		case "year":
+			facets.Years = append(facets.Years, v)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("facet rows iteration error: %w", err)
+	}
+	return facets, nil
+}
+
+// GetPendingFileTexts returns attachments waiting for text extraction,
+// oldest first.
+func (r *Repo) GetPendingFileTexts(ctx context.Context, limit int) ([]search.PendingFile, error) {
+	const op = "storage.repo.GetPendingFileTexts"
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT ft.file_id, f.file_name, f.object_key, f.mime_type, f.size_bytes, ft.attempts
+		FROM file_texts ft
+		JOIN files f ON f.id = ft.file_id
+		WHERE ft.status = 'pending'
+		ORDER BY ft.created_at, ft.file_id
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query pending files: %w", op, err)
+	}
+	defer rows.Close()
+
+	files := make([]search.PendingFile, 0)
+	for rows.Next() {
+		var f search.PendingFile
+		var mimeType sql.NullString
+		var size sql.NullInt64
+		if err := rows.Scan(&f.FileID, &f.FileName, &f.ObjectKey, &mimeType, &size, &f.Attempts); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan pending file: %w", op, err)
+		}
+		if mimeType.Valid {
+			f.MimeType = &mimeType.String
+		}
+		if size.Valid {
+			f.SizeBytes = &size.Int64
+		}
+		files = append(files, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows iteration error: %w", op, err)
+	}
+	return files, nil
+}
+
+// SaveFileText records an extraction attempt. Status pending keeps the file
+// queued for a retry; extracted text reindexes the documents it is attached
+// to through the file_texts trigger.
+func (r *Repo) SaveFileText(ctx context.Context, fileID int64, status string, content, errMsg *string) error {
+	const op = "storage.repo.SaveFileText"
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE file_texts SET
+			status = $2,
+			content = $3,
+			error = $4,
+			attempts = attempts + 1,
+			extracted_at = CASE WHEN $2 = 'extracted' THEN NOW() ELSE extracted_at END
+		WHERE file_id = $1`,
+		fileID, status, content, errMsg)
+	if err != nil {
+		return r.translator.Translate(err, op)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get affected rows: %w", op, err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// ReindexSearch requeues failed extractions and rebuilds search_entries.
+// Returns the number of indexed entities.
+func (r *Repo) ReindexSearch(ctx context.Context) (int, error) {
+	const op = "storage.repo.ReindexSearch"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE file_texts SET status = 'pending', attempts = 0, error = NULL
+		WHERE status = 'failed'`); err != nil {
+		return 0, fmt.Errorf("%s: failed to requeue files: %w", op, err)
+	}
+
+	var n int
+	if err := tx.QueryRowContext(ctx, "SELECT search_reindex_all()").Scan(&n); err != nil {
+		return 0, fmt.Errorf("%s: failed to rebuild index: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+	return n, nil
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"srmt-admin/internal/lib/dto"
+	document_kind "srmt-admin/internal/lib/model/document-kind"
 	"srmt-admin/internal/lib/model/signature"
 	"srmt-admin/internal/lib/model/user"
 	"srmt-admin/internal/storage"
@@ -28,8 +29,11 @@ func (r *Repo) GetStatusIDByCode(ctx context.Context, code string) (int, error)
 	return id, nil
 }
 
-// GetPendingSignatureDocuments returns all documents waiting for signature across all document types
-func (r *Repo) GetPendingSignatureDocuments(ctx context.Context) ([]signature.PendingDocument, error) {
+// GetPendingSignatureDocuments returns the documents waiting for userID's
+// signature across all document kinds: documents without an active approval
+// route, which anyone with access may sign, and documents whose route is at a
+// stage with a pending step of userID or of a signer who delegated to userID.
+func (r *Repo) GetPendingSignatureDocuments(ctx context.Context, userID int64) ([]signature.PendingDocument, error) {
 	const op = "storage.repo.GetPendingSignatureDocuments"
 
 	// Get the status ID for 'pending_signature'
@@ -38,80 +42,36 @@ func (r *Repo) GetPendingSignatureDocuments(ctx context.Context) ([]signature.Pe
 		return nil, fmt.Errorf("%s: failed to get status ID: %w", op, err)
 	}
 
-	// Union query for all document types
-	const query = `
-		SELECT 'decree' as document_type, d.id, d.name, d.number, d.document_date,
-			   dt.id as type_id, dt.name as type_name,
+	// Documents of every registered kind
+	query := fmt.Sprintf(`
+		SELECT d.document_type, d.id, d.name, d.number, d.document_date,
+			   d.type_id, d.type_name,
 			   o.name as organization, d.organization_id,
 			   rc.fio as responsible_name, d.responsible_contact_id,
-			   d.created_at, uc.fio as created_by
-		FROM decrees d
-		LEFT JOIN decree_type dt ON d.type_id = dt.id
+			   d.created_at, uc.fio as created_by,
+			   %s
+		FROM registry_documents d
 		LEFT JOIN organizations o ON d.organization_id = o.id
 		LEFT JOIN contacts rc ON d.responsible_contact_id = rc.id
 		LEFT JOIN users u ON d.created_by_user_id = u.id
 		LEFT JOIN contacts uc ON u.contact_id = uc.id
-		WHERE d.status_id = $1
-
-		UNION ALL
-
-		SELECT 'report' as document_type, r.id, r.name, r.number, r.document_date,
-			   rt.id as type_id, rt.name as type_name,
-			   o.name as organization, r.organization_id,
-			   rc.fio as responsible_name, r.responsible_contact_id,
-			   r.created_at, uc.fio as created_by
-		FROM reports r
-		LEFT JOIN report_type rt ON r.type_id = rt.id
-		LEFT JOIN organizations o ON r.organization_id = o.id
-		LEFT JOIN contacts rc ON r.responsible_contact_id = rc.id
-		LEFT JOIN users u ON r.created_by_user_id = u.id
-		LEFT JOIN contacts uc ON u.contact_id = uc.id
-		WHERE r.status_id = $1
-
-		UNION ALL
-
-		SELECT 'letter' as document_type, l.id, l.name, l.number, l.document_date,
-			   lt.id as type_id, lt.name as type_name,
-			   o.name as organization, l.organization_id,
-			   rc.fio as responsible_name, l.responsible_contact_id,
-			   l.created_at, uc.fio as created_by
-		FROM letters l
-		LEFT JOIN letter_type lt ON l.type_id = lt.id
-		LEFT JOIN organizations o ON l.organization_id = o.id
-		LEFT JOIN contacts rc ON l.responsible_contact_id = rc.id
-		LEFT JOIN users u ON l.created_by_user_id = u.id
-		LEFT JOIN contacts uc ON u.contact_id = uc.id
-		WHERE l.status_id = $1
-
-		UNION ALL
-
-		SELECT 'instruction' as document_type, i.id, i.name, i.number, i.document_date,
-			   it.id as type_id, it.name as type_name,
-			   o.name as organization, i.organization_id,
-			   rc.fio as responsible_name, i.responsible_contact_id,
-			   i.created_at, uc.fio as created_by
-		FROM instructions i
-		LEFT JOIN instruction_type it ON i.type_id = it.id
-		LEFT JOIN organizations o ON i.organization_id = o.id
-		LEFT JOIN contacts rc ON i.responsible_contact_id = rc.id
-		LEFT JOIN users u ON i.created_by_user_id = u.id
-		LEFT JOIN contacts uc ON u.contact_id = uc.id
-		WHERE i.status_id = $1
+		WHERE d.status_id = $1 AND %s
+		ORDER BY d.created_at DESC
+	`, pendingStepColumns("d"), pendingTurnFilter("d"))
 
-		ORDER BY created_at DESC
-	`
-
-	rows, err := r.db.QueryContext(ctx, query, statusID)
+	rows, err := r.db.QueryContext(ctx, query, statusID, userID)
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to query: %w", op, err)
 	}
 	defer rows.Close()
 
-	var documents []signature.PendingDocument
+	documents := make([]signature.PendingDocument, 0)
 	for rows.Next() {
 		var doc signature.PendingDocument
 		var organization, responsibleName, createdBy sql.NullString
 		var organizationID, responsibleID sql.NullInt64
+		var stage sql.NullInt64
+		var stepDueDate sql.NullTime
 
 		err := rows.Scan(
 			&doc.DocumentType,
@@ -127,6 +87,8 @@ func (r *Repo) GetPendingSignatureDocuments(ctx context.Context) ([]signature.Pe
 			&responsibleID,
 			&doc.CreatedAt,
 			&createdBy,
+			&stage,
+			&stepDueDate,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("%s: failed to scan: %w", op, err)
@@ -147,6 +109,13 @@ func (r *Repo) GetPendingSignatureDocuments(ctx context.Context) ([]signature.Pe
 		if createdBy.Valid {
 			doc.CreatedBy = &createdBy.String
 		}
+		if stage.Valid {
+			v := int(stage.Int64)
+			doc.ApprovalStage = &v
+		}
+		if stepDueDate.Valid {
+			doc.StepDueDate = &stepDueDate.Time
+		}
 
 		documents = append(documents, doc)
 	}
@@ -158,48 +127,71 @@ func (r *Repo) GetPendingSignatureDocuments(ctx context.Context) ([]signature.Pe
 	return documents, nil
 }
 
-// SignDocument signs a document and optionally assigns executor and due date
-func (r *Repo) SignDocument(ctx context.Context, docType string, docID int64, req dto.SignDocumentRequest, userID int64) error {
+// SignDocument signs a document and optionally assigns executor and due date.
+// When the document is on an active approval route, the signature closes the
+// user's step of the current stage (or a step delegated to them) and the
+// document moves to signed only once the last stage is complete; the executor
+// and due date of the final signature are applied then, and a document with
+// an executor is put on execution control. Returns whether the document is
+// now signed. A user without a pending step in the current stage gets
+// storage.ErrNotYourTurn. A request carrying a seal is refused with
+// storage.ErrContentChanged when the document no longer matches it.
+func (r *Repo) SignDocument(ctx context.Context, docType string, docID int64, req dto.SignDocumentRequest, userID int64) (bool, error) {
 	const op = "storage.repo.SignDocument"
 
-	// Validate document type
-	if !signature.IsValidDocumentType(docType) {
-		return fmt.Errorf("%s: invalid document type: %s", op, docType)
+	tableName, err := r.documentTable(ctx, docType)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
 	}
 
 	// Get status IDs
 	pendingStatusID, err := r.GetStatusIDByCode(ctx, "pending_signature")
 	if err != nil {
-		return fmt.Errorf("%s: failed to get pending_signature status: %w", op, err)
+		return false, fmt.Errorf("%s: failed to get pending_signature status: %w", op, err)
 	}
 
 	signedStatusID, err := r.GetStatusIDByCode(ctx, "signed")
 	if err != nil {
-		return fmt.Errorf("%s: failed to get signed status: %w", op, err)
+		return false, fmt.Errorf("%s: failed to get signed status: %w", op, err)
 	}
 
 	// Start transaction
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+		return false, fmt.Errorf("%s: failed to begin transaction: %w", op, err)
 	}
 	defer tx.Rollback()
 
 	// Check document exists and is in pending_signature status
-	tableName := getTableName(docType)
-	checkQuery := fmt.Sprintf(`SELECT status_id FROM %s WHERE id = $1`, tableName)
+	checkQuery := fmt.Sprintf(`SELECT status_id FROM %s WHERE id = $1 FOR UPDATE`, tableName)
 
 	var currentStatusID int
 	err = tx.QueryRowContext(ctx, checkQuery, docID).Scan(&currentStatusID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return fmt.Errorf("%s: document not found: %w", op, storage.ErrNotFound)
+			return false, fmt.Errorf("%s: document not found: %w", op, storage.ErrNotFound)
 		}
-		return fmt.Errorf("%s: failed to check document: %w", op, err)
+		return false, fmt.Errorf("%s: failed to check document: %w", op, err)
 	}
 
 	if currentStatusID != pendingStatusID {
-		return fmt.Errorf("%s: document is not in pending_signature status", op)
+		return false, fmt.Errorf("%s: document is not in pending_signature status", op)
+	}
+
+	// The seal was made before the row was locked; refuse it if the
+	// document has changed since
+	if req.Seal != nil {
+		content, err := loadDocumentContent(ctx, tx, document_kind.Model{Code: docType, TableName: tableName}, docID)
+		if err != nil {
+			return false, fmt.Errorf("%s: failed to load content: %w", op, err)
+		}
+		if !req.Seal.Manifest.Covers(*content) {
+			return false, fmt.Errorf("%s: %w", op, storage.ErrContentChanged)
+		}
+	}
+	sealColumns, err := insertSealColumns(req.Seal)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
 	}
 
 	// Parse due date if provided
@@ -207,56 +199,93 @@ func (r *Repo) SignDocument(ctx context.Context, docType string, docID int64, re
 	if req.AssignedDueDate != nil && *req.AssignedDueDate != "" {
 		parsed, err := time.Parse("2006-01-02", *req.AssignedDueDate)
 		if err != nil {
-			return fmt.Errorf("%s: invalid due date format: %w", op, err)
+			return false, fmt.Errorf("%s: invalid due date format: %w", op, err)
 		}
 		assignedDueDate = &parsed
 	}
 
+	// Close the user's step when the document is on an approval route
+	route, err := lockActiveRoute(ctx, tx, docType, docID)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to load approval route: %w", op, err)
+	}
+	completed := true
+	if route != nil {
+		if err := claimRouteStep(ctx, tx, route, userID, signature.StepSigned, req.ResolutionText); err != nil {
+			return false, fmt.Errorf("%s: %w", op, err)
+		}
+		completed, err = advanceRoute(ctx, tx, route)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
 	// Insert signature record
 	insertQuery := `
 		INSERT INTO document_signatures (
 			document_type, document_id, action, resolution_text,
-			assigned_executor_id, assigned_due_date, signed_by_user_id
+			assigned_executor_id, assigned_due_date, signed_by_user_id,
+			content_digest, content_manifest, seal_provider, seal_value, seal_certificate
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
-	_, err = tx.ExecContext(ctx, insertQuery,
+	args := append([]interface{}{
 		docType, docID, signature.ActionSigned, req.ResolutionText, req.AssignedExecutorID, assignedDueDate, userID,
-	)
+	}, sealColumns...)
+	_, err = tx.ExecContext(ctx, insertQuery, args...)
 	if err != nil {
-		return fmt.Errorf("%s: failed to insert signature: %w", op, err)
+		return false, fmt.Errorf("%s: failed to insert signature: %w", op, err)
 	}
 
-	// Update document status and optionally executor/due_date
-	updateQuery := fmt.Sprintf(`
-		UPDATE %s SET
-			status_id = $1,
-			updated_by_user_id = $2,
-			executor_contact_id = COALESCE($3, executor_contact_id),
-			due_date = COALESCE($4, due_date)
-		WHERE id = $5
-	`, tableName)
+	if completed {
+		// Update document status and optionally executor/due_date
+		updateQuery := fmt.Sprintf(`
+			UPDATE %s SET
+				status_id = $1,
+				updated_by_user_id = $2,
+				executor_contact_id = COALESCE($3, executor_contact_id),
+				due_date = COALESCE($4, due_date)
+			WHERE id = $5
+			RETURNING executor_contact_id, due_date
+		`, tableName)
 
-	_, err = tx.ExecContext(ctx, updateQuery, signedStatusID, userID, req.AssignedExecutorID, assignedDueDate, docID)
-	if err != nil {
-		return fmt.Errorf("%s: failed to update document: %w", op, err)
+		var executorID sql.NullInt64
+		var dueDate sql.NullTime
+		err = tx.QueryRowContext(ctx, updateQuery, signedStatusID, userID, req.AssignedExecutorID, assignedDueDate, docID).
+			Scan(&executorID, &dueDate)
+		if err != nil {
+			return false, fmt.Errorf("%s: failed to update document: %w", op, err)
+		}
+
+		// A document signed with an executor goes on execution control
+		if executorID.Valid {
+			var due *time.Time
+			if dueDate.Valid {
+				due = &dueDate.Time
+			}
+			if err := upsertExecutionControl(ctx, tx, docType, docID, executorID.Int64, due, userID, req.ResolutionText); err != nil {
+				return false, fmt.Errorf("%s: %w", op, err)
+			}
+		}
 	}
 
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("%s: failed to commit: %w", op, err)
+		return false, fmt.Errorf("%s: failed to commit: %w", op, err)
 	}
 
-	return nil
+	return completed, nil
 }
 
-// RejectSignature rejects a document signature
+// RejectSignature rejects a document signature. On an approval route only a
+// user with a pending step in the current stage may reject; the rejection
+// closes the whole route.
 func (r *Repo) RejectSignature(ctx context.Context, docType string, docID int64, reason *string, userID int64) error {
 	const op = "storage.repo.RejectSignature"
 
-	// Validate document type
-	if !signature.IsValidDocumentType(docType) {
-		return fmt.Errorf("%s: invalid document type: %s", op, docType)
+	tableName, err := r.documentTable(ctx, docType)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	// Get status IDs
@@ -278,8 +307,7 @@ func (r *Repo) RejectSignature(ctx context.Context, docType string, docID int64,
 	defer tx.Rollback()
 
 	// Check document exists and is in pending_signature status
-	tableName := getTableName(docType)
-	checkQuery := fmt.Sprintf(`SELECT status_id FROM %s WHERE id = $1`, tableName)
+	checkQuery := fmt.Sprintf(`SELECT status_id FROM %s WHERE id = $1 FOR UPDATE`, tableName)
 
 	var currentStatusID int
 	err = tx.QueryRowContext(ctx, checkQuery, docID).Scan(&currentStatusID)
@@ -294,6 +322,20 @@ func (r *Repo) RejectSignature(ctx context.Context, docType string, docID int64,
 		return fmt.Errorf("%s: document is not in pending_signature status", op)
 	}
 
+	// Close the route when the document is on one
+	route, err := lockActiveRoute(ctx, tx, docType, docID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to load approval route: %w", op, err)
+	}
+	if route != nil {
+		if err := claimRouteStep(ctx, tx, route, userID, signature.StepRejected, reason); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err := rejectRoute(ctx, tx, route); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
 	// Insert signature record
 	insertQuery := `
 		INSERT INTO document_signatures (
@@ -325,11 +367,6 @@ func (r *Repo) RejectSignature(ctx context.Context, docType string, docID int64,
 func (r *Repo) GetDocumentSignatures(ctx context.Context, docType string, docID int64) ([]signature.Signature, error) {
 	const op = "storage.repo.GetDocumentSignatures"
 
-	// Validate document type
-	if !signature.IsValidDocumentType(docType) {
-		return nil, fmt.Errorf("%s: invalid document type: %s", op, docType)
-	}
-
 	const query = `
 		SELECT
 			s.id, s.document_type, s.document_id, s.action,
@@ -431,19 +468,3 @@ func (r *Repo) GetSignatureRejectedStatusInfo(ctx context.Context) (*dto.StatusI
 	}
 	return &status, nil
 }
-
-// getTableName returns the table name for a document type
-func getTableName(docType string) string {
-	switch docType {
-	case signature.DocTypeDecree:
-		return "decrees"
-	case signature.DocTypeReport:
-		return "reports"
-	case signature.DocTypeLetter:
-		return "letters"
-	case signature.DocTypeInstruction:
-		return "instructions"
-	default:
-		return ""
-	}
-}
@@ -0,0 +1,202 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	document_kind "srmt-admin/internal/lib/model/document-kind"
+	"srmt-admin/internal/lib/model/file"
+	"srmt-admin/internal/lib/model/signature"
+	"srmt-admin/internal/lib/model/user"
+	"srmt-admin/internal/storage"
+)
+
+type contentQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// loadDocumentContent reads the key fields and attachments a seal covers.
+func loadDocumentContent(ctx context.Context, q contentQuerier, kind document_kind.Model, id int64) (*signature.Content, error) {
+	fieldsQuery := fmt.Sprintf(`
+		SELECT name, number, TO_CHAR(document_date, 'YYYY-MM-DD'), description, type_id,
+			organization_id, responsible_contact_id, parent_document_id
+		FROM %s WHERE id = $1`, kind.TableName)
+
+	var c signature.Content
+	var number, description sql.NullString
+	var orgID, responsibleID, parentID sql.NullInt64
+	err := q.QueryRowContext(ctx, fieldsQuery, id).Scan(
+		&c.Fields.Name, &number, &c.Fields.DocumentDate, &description, &c.Fields.TypeID,
+		&orgID, &responsibleID, &parentID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read document: %w", err)
+	}
+	if number.Valid {
+		c.Fields.Number = &number.String
+	}
+	if description.Valid {
+		c.Fields.Description = &description.String
+	}
+	if orgID.Valid {
+		c.Fields.OrganizationID = &orgID.Int64
+	}
+	if responsibleID.Valid {
+		c.Fields.ResponsibleContactID = &responsibleID.Int64
+	}
+	if parentID.Valid {
+		c.Fields.ParentDocumentID = &parentID.Int64
+	}
+
+	filesQuery := fmt.Sprintf(`
+		SELECT f.id, f.file_name, f.object_key, f.category_id, COALESCE(f.mime_type, ''),
+			COALESCE(f.size_bytes, 0), f.created_at
+		FROM %s l
+		JOIN files f ON f.id = l.file_id
+		WHERE l.%s = $1
+		ORDER BY f.id`, kind.FileLinksTable(), kind.ForeignKey())
+
+	rows, err := q.QueryContext(ctx, filesQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	defer rows.Close()
+
+	c.Files = make([]file.Model, 0)
+	for rows.Next() {
+		var f file.Model
+		if err := rows.Scan(&f.ID, &f.FileName, &f.ObjectKey, &f.CategoryID, &f.MimeType, &f.SizeBytes, &f.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		c.Files = append(c.Files, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("attachments iteration error: %w", err)
+	}
+	return &c, nil
+}
+
+// GetDocumentContent returns the key fields and attachments of a document
+// of kind, the content a signature seals.
+func (r *Repo) GetDocumentContent(ctx context.Context, kind document_kind.Model, id int64) (*signature.Content, error) {
+	const op = "storage.repo.GetDocumentContent"
+
+	c, err := loadDocumentContent(ctx, r.db, kind, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return c, nil
+}
+
+// GetSignatureSeals returns the signings of a document, newest first, with
+// their seals.
+func (r *Repo) GetSignatureSeals(ctx context.Context, docType string, docID int64) ([]signature.SealedSignature, error) {
+	const op = "storage.repo.GetSignatureSeals"
+
+	const query = `
+		SELECT s.id, s.signed_by_user_id, uc.fio, s.signed_at, s.resolution_text,
+			s.content_digest, s.content_manifest, s.seal_provider, s.seal_value, s.seal_certificate
+		FROM document_signatures s
+		LEFT JOIN users u ON u.id = s.signed_by_user_id
+		LEFT JOIN contacts uc ON uc.id = u.contact_id
+		WHERE s.document_type = $1 AND s.document_id = $2 AND s.action = $3
+		ORDER BY s.signed_at DESC, s.id DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, docType, docID, signature.ActionSigned)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to query: %w", op, err)
+	}
+	defer rows.Close()
+
+	sigs := make([]signature.SealedSignature, 0)
+	for rows.Next() {
+		var s signature.SealedSignature
+		var signedByID sql.NullInt64
+		var signedByName, digest, provider, value, certificate sql.NullString
+		var manifest []byte
+		if err := rows.Scan(&s.ID, &signedByID, &signedByName, &s.SignedAt, &s.ResolutionText,
+			&digest, &manifest, &provider, &value, &certificate); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan: %w", op, err)
+		}
+		if signedByID.Valid {
+			name := signedByName.String
+			s.SignedBy = &user.ShortInfo{ID: signedByID.Int64, Name: &name}
+		}
+		if digest.Valid {
+			seal := &signature.Seal{
+				Algorithm: signature.DigestAlgorithm,
+				Digest:    digest.String,
+				Provider:  provider.String,
+			}
+			if err := json.Unmarshal(manifest, &seal.Manifest); err != nil {
+				return nil, fmt.Errorf("%s: failed to decode manifest of signature %d: %w", op, s.ID, err)
+			}
+			if value.Valid {
+				seal.Value = &value.String
+			}
+			if certificate.Valid {
+				seal.Certificate = &certificate.String
+			}
+			s.Seal = seal
+		}
+		sigs = append(sigs, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+	return sigs, nil
+}
+
+// DocumentAttachmentLock reports whether the attachments of a document of
+// kind are locked by a signature, and which files are attached.
+func (r *Repo) DocumentAttachmentLock(ctx context.Context, kind document_kind.Model, docID int64) (bool, []int64, error) {
+	const op = "storage.repo.DocumentAttachmentLock"
+
+	var locked bool
+	if err := r.db.QueryRowContext(ctx, `SELECT document_attachments_locked($1, $2)`, kind.Code, docID).Scan(&locked); err != nil {
+		return false, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := fmt.Sprintf(`SELECT file_id FROM %s WHERE %s = $1 ORDER BY file_id`, kind.FileLinksTable(), kind.ForeignKey())
+	rows, err := r.db.QueryContext(ctx, query, docID)
+	if err != nil {
+		return false, nil, fmt.Errorf("%s: failed to query files: %w", op, err)
+	}
+	defer rows.Close()
+
+	fileIDs := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return false, nil, fmt.Errorf("%s: failed to scan file id: %w", op, err)
+		}
+		fileIDs = append(fileIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		return false, nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+	return locked, fileIDs, nil
+}
+
+// insertSealColumns returns the seal columns of a signature insert: digest,
+// manifest, provider, value and certificate, all NULL without a seal.
+func insertSealColumns(seal *signature.Seal) ([]interface{}, error) {
+	if seal == nil {
+		return []interface{}{nil, nil, nil, nil, nil}, nil
+	}
+	manifest, err := json.Marshal(seal.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	return []interface{}{seal.Digest, manifest, seal.Provider, seal.Value, seal.Certificate}, nil
+}
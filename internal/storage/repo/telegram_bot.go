@@ -0,0 +1,144 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"srmt-admin/internal/storage"
+)
+
+// SaveTelegramLinkCode stores the hash of a new link code of a user,
+// replacing the previous one, and drops the expired codes of everyone.
+func (r *Repo) SaveTelegramLinkCode(ctx context.Context, userID int64, codeHash string, expiresAt time.Time) error {
+	const op = "storage.repo.SaveTelegramLinkCode"
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM telegram_link_codes WHERE expires_at < NOW()`); err != nil {
+		return fmt.Errorf("%s: failed to delete expired codes: %w", op, err)
+	}
+
+	const query = `
+		INSERT INTO telegram_link_codes (code_hash, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id)
+		DO UPDATE SET code_hash = EXCLUDED.code_hash, expires_at = EXCLUDED.expires_at, created_at = NOW()`
+	if _, err := r.db.ExecContext(ctx, query, codeHash, userID, expiresAt); err != nil {
+		return r.translator.Translate(err, op)
+	}
+	return nil
+}
+
+// RedeemTelegramLinkCode consumes a link code and links chatID to the
+// contact of its user, replacing an earlier chat of the contact and an
+// earlier contact of the chat. It returns the user ID, or ErrNotFound when
+// the code is unknown, expired or its user is inactive.
+func (r *Repo) RedeemTelegramLinkCode(ctx context.Context, codeHash string, chatID int64) (int64, error) {
+	const op = "storage.repo.RedeemTelegramLinkCode"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// The code is deleted even when it can no longer be used
+	var userID, contactID int64
+	var usable bool
+	err = tx.QueryRowContext(ctx, `
+		DELETE FROM telegram_link_codes c
+		USING users u
+		WHERE c.code_hash = $1 AND u.id = c.user_id
+		RETURNING u.id, u.contact_id, c.expires_at > NOW() AND u.is_active`, codeHash).Scan(&userID, &contactID, &usable)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, storage.ErrNotFound
+		}
+		return 0, fmt.Errorf("%s: failed to consume code: %w", op, err)
+	}
+	if !usable {
+		if err := tx.Commit(); err != nil {
+			return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+		}
+		return 0, storage.ErrNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM notification_telegram_chats WHERE chat_id = $1 AND contact_id <> $2`,
+		chatID, contactID); err != nil {
+		return 0, fmt.Errorf("%s: failed to unlink previous contact: %w", op, err)
+	}
+	const link = `
+		INSERT INTO notification_telegram_chats (contact_id, chat_id)
+		VALUES ($1, $2)
+		ON CONFLICT (contact_id)
+		DO UPDATE SET chat_id = EXCLUDED.chat_id, linked_at = NOW()`
+	if _, err := tx.ExecContext(ctx, link, contactID, chatID); err != nil {
+		return 0, r.translator.Translate(err, op)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+	return userID, nil
+}
+
+// GetTelegramChatUserID returns the active user whose contact the chat is
+// linked to.
+func (r *Repo) GetTelegramChatUserID(ctx context.Context, chatID int64) (int64, error) {
+	const op = "storage.repo.GetTelegramChatUserID"
+	const query = `
+		SELECT u.id
+		FROM notification_telegram_chats t
+		JOIN users u ON u.contact_id = t.contact_id
+		WHERE t.chat_id = $1 AND u.is_active`
+
+	var userID int64
+	if err := r.db.QueryRowContext(ctx, query, chatID).Scan(&userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, storage.ErrNotFound
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return userID, nil
+}
+
+// GetTelegramLinkedAt returns when the contact linked its chat.
+func (r *Repo) GetTelegramLinkedAt(ctx context.Context, contactID int64) (time.Time, error) {
+	const op = "storage.repo.GetTelegramLinkedAt"
+
+	var linkedAt time.Time
+	err := r.db.QueryRowContext(ctx,
+		`SELECT linked_at FROM notification_telegram_chats WHERE contact_id = $1`, contactID).Scan(&linkedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, storage.ErrNotFound
+		}
+		return time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return linkedAt, nil
+}
+
+// DeleteTelegramChat unlinks the chat of a contact, if any.
+func (r *Repo) DeleteTelegramChat(ctx context.Context, contactID int64) error {
+	const op = "storage.repo.DeleteTelegramChat"
+
+	if _, err := r.db.ExecContext(ctx,
+		`DELETE FROM notification_telegram_chats WHERE contact_id = $1`, contactID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// DeleteTelegramChatByChatID unlinks a chat from whichever contact it is
+// linked to.
+func (r *Repo) DeleteTelegramChatByChatID(ctx context.Context, chatID int64) error {
+	const op = "storage.repo.DeleteTelegramChatByChatID"
+
+	if _, err := r.db.ExecContext(ctx,
+		`DELETE FROM notification_telegram_chats WHERE chat_id = $1`, chatID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
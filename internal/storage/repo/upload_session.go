@@ -0,0 +1,161 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"srmt-admin/internal/lib/model/file"
+	"srmt-admin/internal/storage"
+)
+
+const uploadSessionColumns = `
+	id, object_key, multipart_id, file_name, content_type, size_bytes, offset_bytes, parts,
+	category_id, target_date, created_by_user_id, assembled_at, file_id, created_at, expires_at`
+
+func scanUploadSession(row interface{ Scan(...any) error }) (*file.UploadSession, error) {
+	var s file.UploadSession
+	var parts []byte
+	var assembledAt sql.NullTime
+	var fileID sql.NullInt64
+	if err := row.Scan(&s.ID, &s.ObjectKey, &s.MultipartID, &s.FileName, &s.ContentType, &s.Size,
+		&s.Offset, &parts, &s.CategoryID, &s.TargetDate, &s.CreatedBy, &assembledAt, &fileID,
+		&s.CreatedAt, &s.ExpiresAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(parts, &s.Parts); err != nil {
+		return nil, fmt.Errorf("failed to decode parts: %w", err)
+	}
+	if assembledAt.Valid {
+		s.AssembledAt = &assembledAt.Time
+	}
+	if fileID.Valid {
+		s.FileID = &fileID.Int64
+	}
+	return &s, nil
+}
+
+// AddUploadSession records a new resumable upload.
+func (r *Repo) AddUploadSession(ctx context.Context, s file.UploadSession) error {
+	const op = "storage.repo.AddUploadSession"
+	const query = `
+		INSERT INTO upload_sessions (
+			id, object_key, multipart_id, file_name, content_type, size_bytes,
+			category_id, target_date, created_by_user_id, expires_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := r.db.ExecContext(ctx, query, s.ID, s.ObjectKey, s.MultipartID, s.FileName, s.ContentType,
+		s.Size, s.CategoryID, s.TargetDate, s.CreatedBy, s.ExpiresAt)
+	if err != nil {
+		return r.translator.Translate(err, op)
+	}
+	return nil
+}
+
+// GetUploadSession returns a resumable upload or storage.ErrNotFound.
+func (r *Repo) GetUploadSession(ctx context.Context, id string) (*file.UploadSession, error) {
+	const op = "storage.repo.GetUploadSession"
+
+	s, err := scanUploadSession(r.db.QueryRowContext(ctx,
+		`SELECT `+uploadSessionColumns+` FROM upload_sessions WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return s, nil
+}
+
+// AdvanceUploadSession appends an accepted part and moves the offset past it,
+// provided the offset is still the one the part was written at. It reports
+// false when another chunk got there first.
+func (r *Repo) AdvanceUploadSession(ctx context.Context, id string, offset int64, part file.UploadPart, expiresAt time.Time) (bool, error) {
+	const op = "storage.repo.AdvanceUploadSession"
+	const query = `
+		UPDATE upload_sessions
+		SET offset_bytes = offset_bytes + $3,
+			parts = parts || jsonb_build_array($4::jsonb),
+			expires_at = $5
+		WHERE id = $1 AND offset_bytes = $2 AND assembled_at IS NULL`
+
+	encoded, err := json.Marshal(part)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to encode part: %w", op, err)
+	}
+	res, err := r.db.ExecContext(ctx, query, id, offset, part.Size, string(encoded), expiresAt)
+	if err != nil {
+		return false, r.translator.Translate(err, op)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to get affected rows: %w", op, err)
+	}
+	return n > 0, nil
+}
+
+// MarkUploadAssembled records that the parts were assembled into the object.
+func (r *Repo) MarkUploadAssembled(ctx context.Context, id string) error {
+	const op = "storage.repo.MarkUploadAssembled"
+
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE upload_sessions SET assembled_at = NOW() WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// FinishUploadSession links the session to the file it produced. The session
+// is kept until it expires so that a repeated finish finds the file.
+func (r *Repo) FinishUploadSession(ctx context.Context, id string, fileID int64, expiresAt time.Time) error {
+	const op = "storage.repo.FinishUploadSession"
+
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE upload_sessions SET file_id = $2, expires_at = $3 WHERE id = $1`, id, fileID, expiresAt); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// DeleteUploadSession removes a resumable upload.
+func (r *Repo) DeleteUploadSession(ctx context.Context, id string) error {
+	const op = "storage.repo.DeleteUploadSession"
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM upload_sessions WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetExpiredUploadSessions returns up to limit sessions past their expiry,
+// oldest first.
+func (r *Repo) GetExpiredUploadSessions(ctx context.Context, limit int) ([]*file.UploadSession, error) {
+	const op = "storage.repo.GetExpiredUploadSessions"
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+uploadSessionColumns+`
+		FROM upload_sessions
+		WHERE expires_at < NOW()
+		ORDER BY expires_at
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	sessions := make([]*file.UploadSession, 0)
+	for rows.Next() {
+		s, err := scanUploadSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to scan session: %w", op, err)
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+	return sessions, nil
+}
@@ -29,8 +29,13 @@ var (
 
 	ErrUniqueViolation = errors.New("unique violation")
 
-	ErrNotFound      = errors.New("not found")
-	ErrInvalidStatus = errors.New("invalid status for operation")
+	ErrNotFound            = errors.New("not found")
+	ErrInvalidStatus       = errors.New("invalid status for operation")
+	ErrNotYourTurn         = errors.New("no pending approval step for this user")
+	ErrUnknownDocumentKind = errors.New("unknown document kind")
+	ErrNumberAssigned      = errors.New("number is assigned by the numbering scheme")
+	ErrAttachmentsLocked   = errors.New("attachments of a signed document are locked")
+	ErrContentChanged      = errors.New("document changed while it was being signed")
 
 	// HRM errors
 	ErrPersonnelRecordNotFound = errors.New("personnel record not found")
@@ -42,23 +47,47 @@ var (
 	ErrInvalidDateRange        = errors.New("invalid date range")
 	ErrStartDateInPast         = errors.New("start date cannot be in the past")
 	ErrNotificationNotFound    = errors.New("notification not found")
+	ErrEntitlementRuleNotFound = errors.New("leave entitlement rule not found")
+	ErrEntitlementRuleExists   = errors.New("leave entitlement rule already set for this position and seniority")
+	ErrVacationYearClosed      = errors.New("vacation year is closed")
+	ErrAccrualPeriodNotOver    = errors.New("accrual period is not over yet")
 
 	// Timesheet errors
 	ErrTimesheetEntryNotFound = errors.New("timesheet entry not found")
 	ErrHolidayNotFound        = errors.New("holiday not found")
 	ErrHolidayAlreadyExists   = errors.New("holiday already exists for this date")
 	ErrCorrectionNotFound     = errors.New("timesheet correction not found")
+	ErrAbsenceNotFound        = errors.New("absence not found")
+
+	// Calendar errors
+	ErrDateOutsideYear      = errors.New("date is outside the calendar year")
+	ErrCalendarDayDuplicate = errors.New("calendar day is listed more than once")
+	ErrWorkScheduleNotFound = errors.New("work schedule not found")
 
 	// Salary errors
 	ErrSalaryNotFound          = errors.New("salary record not found")
 	ErrSalaryStructureNotFound = errors.New("salary structure not found")
 	ErrSalaryAlreadyExists     = errors.New("salary record already exists for this period")
+	ErrPayrollRateNotFound     = errors.New("payroll rate not found")
+	ErrPayrollRateExists       = errors.New("payroll rate already set for this date")
+	ErrBankAccountNotFound     = errors.New("bank account not found")
 
 	// Recruiting errors
-	ErrVacancyNotFound     = errors.New("vacancy not found")
-	ErrCandidateNotFound   = errors.New("candidate not found")
-	ErrInterviewNotFound   = errors.New("interview not found")
-	ErrVacancyNotPublished = errors.New("vacancy is not in published status")
+	ErrVacancyNotFound        = errors.New("vacancy not found")
+	ErrCandidateNotFound      = errors.New("candidate not found")
+	ErrInterviewNotFound      = errors.New("interview not found")
+	ErrVacancyNotPublished    = errors.New("vacancy is not in published status")
+	ErrOfferNotFound          = errors.New("job offer not found")
+	ErrOnboardingNotFound     = errors.New("onboarding not found")
+	ErrOnboardingTaskNotFound = errors.New("onboarding task not found")
+
+	// Lifecycle order errors
+	ErrHROrderNotFound   = errors.New("hr order not found")
+	ErrHROrderScheduled  = errors.New("employee already has a transfer or dismissal waiting to take effect")
+	ErrOfferNotAccepted  = errors.New("job offer is not accepted")
+	ErrOfferAlreadyHired = errors.New("job offer has already been hired on")
+	ErrEmployeeDismissed = errors.New("employee is dismissed")
+	ErrNothingToTransfer = errors.New("transfer changes neither department, position nor salary")
 
 	// HR Document errors
 	ErrHRDocumentNotFound      = errors.New("hr document not found")
@@ -73,6 +102,17 @@ var (
 	ErrOngoingDischargeExists      = errors.New("ongoing idle discharge already exists for this organization")
 	ErrDischargeEndBeforeStart     = errors.New("cannot close discharge: new start time is before existing discharge start time")
 
+	// Duty roster errors
+	ErrShiftPatternNotFound = errors.New("shift pattern not found")
+	ErrShiftPatternExists   = errors.New("shift pattern with this name already exists for the organization")
+	ErrInvalidShiftPattern  = errors.New("shift pattern has duplicate shift codes or a cycle day with an undefined code")
+	ErrShiftNotFound        = errors.New("duty shift not found")
+	ErrShiftOverlap         = errors.New("employee already has a duty shift at this time")
+	ErrShiftAlreadyEnded    = errors.New("duty shift has already ended")
+	ErrSwapNotFound         = errors.New("shift swap not found")
+	ErrSwapPending          = errors.New("shift already has a pending swap request")
+	ErrSwapSelfDecision     = errors.New("swap request cannot be decided by its requester")
+
 	// Constraint errors
 	ErrNotNullViolation         = errors.New("required field is missing")
 	ErrCheckConstraintViolation = errors.New("value violates constraint")
@@ -97,4 +137,7 @@ var (
 	ErrDevelopmentGoalNotFound = errors.New("development goal not found")
 	ErrTrainingFull            = errors.New("training has reached maximum participants")
 	ErrAlreadyEnrolled         = errors.New("employee is already enrolled in this training")
+
+	// Certification compliance errors
+	ErrCertificationTypeNotFound = errors.New("certification type not found")
 )
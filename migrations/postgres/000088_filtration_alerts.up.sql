@@ -0,0 +1,35 @@
+-- Dam-safety alerts: readings from filtration_measurements /
+-- piezometer_measurements that exceed the instrument norm or fall outside
+-- the statistically expected band for the reservoir level on that date.
+-- Rows are produced by the dam-safety analytics service after every
+-- measurement upsert (and by its daily sweep); unacknowledged rows for an
+-- org+date are rebuilt on re-evaluation, acknowledged ones are kept.
+
+CREATE TABLE filtration_alerts (
+    id                      BIGSERIAL PRIMARY KEY,
+    organization_id         BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    location_id             BIGINT REFERENCES filtration_locations(id) ON DELETE CASCADE,
+    piezometer_id           BIGINT REFERENCES piezometers(id) ON DELETE CASCADE,
+    date                    DATE NOT NULL,
+    reason                  TEXT NOT NULL CHECK (reason IN ('norm_exceeded', 'out_of_band')),
+    value                   DOUBLE PRECISION NOT NULL,
+    reservoir_level         DOUBLE PRECISION,
+    norm                    DOUBLE PRECISION,
+    expected                DOUBLE PRECISION,
+    band_low                DOUBLE PRECISION,
+    band_high               DOUBLE PRECISION,
+    created_at              TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    acknowledged_at         TIMESTAMPTZ,
+    acknowledged_by_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT filtration_alerts_one_instrument
+        CHECK ((location_id IS NULL) <> (piezometer_id IS NULL))
+);
+
+CREATE UNIQUE INDEX uq_filtration_alerts_location
+    ON filtration_alerts(location_id, date, reason) WHERE location_id IS NOT NULL;
+CREATE UNIQUE INDEX uq_filtration_alerts_piezometer
+    ON filtration_alerts(piezometer_id, date, reason) WHERE piezometer_id IS NOT NULL;
+CREATE INDEX idx_filtration_alerts_org_date
+    ON filtration_alerts(organization_id, date DESC);
+CREATE INDEX idx_filtration_alerts_open
+    ON filtration_alerts(organization_id) WHERE acknowledged_at IS NULL;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS filtration_measurement_schedules;
@@ -0,0 +1,32 @@
+-- Measurement schedule for filtration locations and piezometers.
+-- A row with location_id and piezometer_id both NULL is the organization
+-- default; a row with exactly one of them set overrides the default for
+-- that instrument. Instruments with neither are not tracked for missed
+-- readings.
+
+CREATE TABLE filtration_measurement_schedules (
+    id                 BIGSERIAL PRIMARY KEY,
+    organization_id    BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    location_id        BIGINT REFERENCES filtration_locations(id) ON DELETE CASCADE,
+    piezometer_id      BIGINT REFERENCES piezometers(id) ON DELETE CASCADE,
+    frequency          TEXT NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly')),
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_by_user_id BIGINT REFERENCES users(id),
+    updated_by_user_id BIGINT REFERENCES users(id),
+    CONSTRAINT filtration_schedules_single_instrument
+        CHECK (location_id IS NULL OR piezometer_id IS NULL)
+);
+
+CREATE UNIQUE INDEX uq_filtration_schedules_org_default
+    ON filtration_measurement_schedules(organization_id)
+    WHERE location_id IS NULL AND piezometer_id IS NULL;
+CREATE UNIQUE INDEX uq_filtration_schedules_location
+    ON filtration_measurement_schedules(location_id) WHERE location_id IS NOT NULL;
+CREATE UNIQUE INDEX uq_filtration_schedules_piezometer
+    ON filtration_measurement_schedules(piezometer_id) WHERE piezometer_id IS NOT NULL;
+
+CREATE TRIGGER set_timestamp_filtration_measurement_schedules
+    BEFORE UPDATE ON filtration_measurement_schedules
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
@@ -0,0 +1 @@
+ALTER TABLE reservoirs DROP COLUMN IF EXISTS organization_id;
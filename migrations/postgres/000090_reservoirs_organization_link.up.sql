@@ -0,0 +1,21 @@
+-- Links the legacy reservoirs (daily inflow history in `data`) to the
+-- organization whose catchment carries the snow cover rows in `modsnow`.
+-- The seasonal runoff forecast needs both series for the same reservoir.
+--
+-- Best-effort backfill by name: a reservoir is linked only when exactly one
+-- organization name starts with the reservoir name. Anything ambiguous is
+-- left NULL and must be set by hand.
+
+ALTER TABLE reservoirs
+    ADD COLUMN organization_id BIGINT REFERENCES organizations (id) ON DELETE SET NULL;
+
+UPDATE reservoirs r
+SET organization_id = m.organization_id
+FROM (
+    SELECT r2.id AS reservoir_id, MIN(o.id) AS organization_id
+    FROM reservoirs r2
+    JOIN organizations o ON o.name ILIKE r2.name || '%'
+    GROUP BY r2.id
+    HAVING COUNT(*) = 1
+) m
+WHERE r.id = m.reservoir_id;
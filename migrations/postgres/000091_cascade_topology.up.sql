@@ -0,0 +1,27 @@
+-- Hydraulic topology of a GES cascade. One row per station: the station its
+-- outflow feeds (NULL for the last station of the chain) and the travel time
+-- of water between the two. Several stations may feed the same downstream
+-- station (tributaries join); cycles are rejected by the application.
+--
+-- tolerance_pct is the largest |inflow - upstream outflow| / upstream outflow
+-- accepted before the routing view flags the pair as an unexplained gain or
+-- loss (lateral inflow, withdrawals and evaporation live inside it).
+
+CREATE TABLE cascade_topology (
+    id                BIGSERIAL PRIMARY KEY,
+    cascade_org_id    BIGINT  NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    station_org_id    BIGINT  NOT NULL UNIQUE REFERENCES organizations (id) ON DELETE CASCADE,
+    downstream_org_id BIGINT  REFERENCES organizations (id) ON DELETE SET NULL,
+    lag_hours         NUMERIC NOT NULL DEFAULT 0 CHECK (lag_hours >= 0),
+    tolerance_pct     NUMERIC NOT NULL DEFAULT 10 CHECK (tolerance_pct > 0),
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_cascade_topology_not_self CHECK (downstream_org_id IS NULL OR downstream_org_id <> station_org_id)
+);
+
+CREATE INDEX idx_cascade_topology_cascade ON cascade_topology (cascade_org_id);
+
+CREATE TRIGGER set_timestamp_cascade_topology
+    BEFORE UPDATE ON cascade_topology
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
@@ -0,0 +1,59 @@
+-- Allowed status transitions per document type. Change-status, edit and
+-- signing only move a document along an edge listed here; anything else is
+-- rejected with the list of allowed next statuses.
+--
+-- from_status_id NULL marks the statuses a document may be created in.
+-- allowed_roles empty means any role that can reach the endpoint.
+
+CREATE TABLE document_status_transitions (
+    id                  SERIAL PRIMARY KEY,
+    document_type       VARCHAR(50) NOT NULL,
+    from_status_id      INTEGER REFERENCES document_status (id) ON DELETE CASCADE,
+    to_status_id        INTEGER NOT NULL REFERENCES document_status (id) ON DELETE CASCADE,
+    allowed_roles       TEXT[]      NOT NULL DEFAULT '{}',
+    require_comment     BOOLEAN     NOT NULL DEFAULT FALSE,
+    require_attachments BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_dst_document_type CHECK (document_type IN ('decree', 'report', 'letter', 'instruction')),
+    CONSTRAINT chk_dst_not_self CHECK (from_status_id IS DISTINCT FROM to_status_id)
+);
+
+CREATE UNIQUE INDEX uq_document_status_transitions_edge
+    ON document_status_transitions (document_type, COALESCE(from_status_id, 0), to_status_id);
+
+CREATE TRIGGER set_timestamp_document_status_transitions
+    BEFORE UPDATE ON document_status_transitions
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+-- Default graph, identical for all four document types. Terminal statuses
+-- (rejected, executed, cancelled, signature_rejected) have no outgoing edges.
+INSERT INTO document_status_transitions (document_type, from_status_id, to_status_id, require_comment, require_attachments)
+SELECT t.document_type, fs.id, ts.id, e.require_comment, e.require_attachments
+FROM (VALUES ('decree'), ('report'), ('letter'), ('instruction')) AS t (document_type)
+CROSS JOIN (VALUES
+    (NULL,                'draft',              FALSE, FALSE),
+    ('draft',             'pending_approval',   FALSE, FALSE),
+    ('draft',             'pending_signature',  FALSE, FALSE),
+    ('draft',             'cancelled',          TRUE,  FALSE),
+    ('pending_approval',  'approved',           FALSE, FALSE),
+    ('pending_approval',  'rejected',           TRUE,  FALSE),
+    ('pending_approval',  'draft',              TRUE,  FALSE),
+    ('approved',          'pending_signature',  FALSE, FALSE),
+    ('approved',          'in_execution',       FALSE, FALSE),
+    ('approved',          'cancelled',          TRUE,  FALSE),
+    ('pending_signature', 'signed',             FALSE, FALSE),
+    ('pending_signature', 'signature_rejected', TRUE,  FALSE),
+    ('pending_signature', 'draft',              TRUE,  FALSE),
+    ('signed',            'in_execution',       FALSE, FALSE),
+    ('in_execution',      'executed',           FALSE, TRUE),
+    ('in_execution',      'cancelled',          TRUE,  FALSE)
+) AS e (from_code, to_code, require_comment, require_attachments)
+LEFT JOIN document_status fs ON fs.code = e.from_code
+JOIN document_status ts ON ts.code = e.to_code
+WHERE e.from_code IS NULL OR fs.id IS NOT NULL;
+
+COMMENT ON TABLE document_status_transitions IS 'Разрешённые переходы статусов документов по типу документа';
+COMMENT ON COLUMN document_status_transitions.from_status_id IS 'Исходный статус; NULL — статус при создании документа';
+COMMENT ON COLUMN document_status_transitions.allowed_roles IS 'Роли, которым разрешён переход; пусто — без ограничений';
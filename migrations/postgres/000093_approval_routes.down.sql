@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS approval_delegations;
+DROP TABLE IF EXISTS approval_route_steps;
+DROP TABLE IF EXISTS approval_routes;
@@ -0,0 +1,67 @@
+-- Approval routes: an ordered list of signers a document must pass before it
+-- reaches 'signed'. Steps sharing a stage number are signed in parallel; the
+-- route moves to the next stage once every step of the current one is signed.
+-- A rejection at any step rejects the whole route.
+
+CREATE TABLE approval_routes (
+    id                 BIGSERIAL PRIMARY KEY,
+    document_type      VARCHAR(50) NOT NULL,
+    document_id        BIGINT      NOT NULL,
+    status             VARCHAR(20) NOT NULL DEFAULT 'active',
+    current_stage      INTEGER     NOT NULL DEFAULT 1,
+    created_by_user_id BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at       TIMESTAMPTZ,
+    CONSTRAINT chk_approval_route_document_type CHECK (document_type IN ('decree', 'report', 'letter', 'instruction')),
+    CONSTRAINT chk_approval_route_status CHECK (status IN ('active', 'completed', 'rejected', 'cancelled'))
+);
+
+-- At most one active route per document.
+CREATE UNIQUE INDEX uq_approval_routes_active
+    ON approval_routes (document_type, document_id) WHERE status = 'active';
+CREATE INDEX idx_approval_routes_document ON approval_routes (document_type, document_id);
+
+CREATE TRIGGER set_timestamp_approval_routes
+    BEFORE UPDATE ON approval_routes
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+CREATE TABLE approval_route_steps (
+    id               BIGSERIAL PRIMARY KEY,
+    route_id         BIGINT      NOT NULL REFERENCES approval_routes (id) ON DELETE CASCADE,
+    stage            INTEGER     NOT NULL CHECK (stage >= 1),
+    signer_user_id   BIGINT      NOT NULL REFERENCES users (id) ON DELETE RESTRICT,
+    due_date         DATE,
+    status           VARCHAR(20) NOT NULL DEFAULT 'pending',
+    -- The user who actually acted: the signer or their delegate.
+    acted_by_user_id BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    acted_at         TIMESTAMPTZ,
+    comment          TEXT,
+    CONSTRAINT chk_approval_step_status CHECK (status IN ('pending', 'signed', 'rejected')),
+    CONSTRAINT uq_approval_step_signer UNIQUE (route_id, stage, signer_user_id)
+);
+
+CREATE INDEX idx_approval_route_steps_route ON approval_route_steps (route_id, stage);
+CREATE INDEX idx_approval_route_steps_signer_pending ON approval_route_steps (signer_user_id) WHERE status = 'pending';
+
+-- Signing delegations: while a signer is away, the delegate may act on their
+-- pending steps. Both dates are inclusive.
+CREATE TABLE approval_delegations (
+    id                 BIGSERIAL PRIMARY KEY,
+    user_id            BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    delegate_user_id   BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    date_from          DATE   NOT NULL,
+    date_to            DATE   NOT NULL,
+    created_by_user_id BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_approval_delegation_dates CHECK (date_to >= date_from),
+    CONSTRAINT chk_approval_delegation_not_self CHECK (delegate_user_id <> user_id)
+);
+
+CREATE INDEX idx_approval_delegations_delegate ON approval_delegations (delegate_user_id, date_from, date_to);
+CREATE INDEX idx_approval_delegations_user ON approval_delegations (user_id);
+
+COMMENT ON TABLE approval_routes IS 'Маршруты согласования (подписания) документов';
+COMMENT ON TABLE approval_route_steps IS 'Шаги маршрута: подписанты по этапам; один этап — параллельное подписание';
+COMMENT ON TABLE approval_delegations IS 'Делегирование права подписи на период отсутствия';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS document_execution_report_files;
+DROP TABLE IF EXISTS document_execution_reports;
+DROP TABLE IF EXISTS document_execution_controls;
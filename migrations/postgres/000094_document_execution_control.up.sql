@@ -0,0 +1,82 @@
+-- Execution control of signed resolutions. A control item is opened when a
+-- document is signed with an executor; the executor reports progress and
+-- completion, and the signer (controller) accepts the work or returns it.
+
+CREATE TABLE document_execution_controls (
+    id                  BIGSERIAL PRIMARY KEY,
+    document_type       VARCHAR(50) NOT NULL,
+    document_id         BIGINT      NOT NULL,
+    executor_contact_id BIGINT      NOT NULL REFERENCES contacts (id) ON DELETE RESTRICT,
+    controller_user_id  BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    resolution_text     TEXT,
+    due_date            DATE,
+    status              VARCHAR(20) NOT NULL DEFAULT 'on_control',
+    accepted_at         TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_execution_control_document_type CHECK (document_type IN ('decree', 'report', 'letter', 'instruction')),
+    CONSTRAINT chk_execution_control_status CHECK (status IN ('on_control', 'submitted', 'returned', 'accepted')),
+    CONSTRAINT uq_execution_control_document UNIQUE (document_type, document_id)
+);
+
+CREATE INDEX idx_execution_controls_executor ON document_execution_controls (executor_contact_id);
+CREATE INDEX idx_execution_controls_open_due ON document_execution_controls (due_date) WHERE status <> 'accepted';
+
+CREATE TRIGGER set_timestamp_document_execution_controls
+    BEFORE UPDATE ON document_execution_controls
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+CREATE TABLE document_execution_reports (
+    id                   BIGSERIAL PRIMARY KEY,
+    control_id           BIGINT      NOT NULL REFERENCES document_execution_controls (id) ON DELETE CASCADE,
+    kind                 VARCHAR(20) NOT NULL,
+    text                 TEXT        NOT NULL,
+    submitted_by_user_id BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    submitted_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- Controller's decision, set on completion reports only.
+    decision             VARCHAR(20),
+    decision_comment     TEXT,
+    decided_by_user_id   BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    decided_at           TIMESTAMPTZ,
+    CONSTRAINT chk_execution_report_kind CHECK (kind IN ('progress', 'completion')),
+    CONSTRAINT chk_execution_report_decision CHECK (decision IS NULL OR (kind = 'completion' AND decision IN ('accepted', 'returned')))
+);
+
+CREATE INDEX idx_execution_reports_control ON document_execution_reports (control_id, submitted_at);
+
+CREATE TABLE document_execution_report_files (
+    report_id  BIGINT NOT NULL REFERENCES document_execution_reports (id) ON DELETE CASCADE,
+    file_id    BIGINT NOT NULL REFERENCES files (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (report_id, file_id)
+);
+
+CREATE INDEX idx_execution_report_files_file ON document_execution_report_files (file_id);
+
+-- Put documents already signed or in execution with an executor on control.
+-- The controller is the author of the latest signature.
+INSERT INTO document_execution_controls (document_type, document_id, executor_contact_id, controller_user_id, resolution_text, due_date)
+SELECT t.document_type, t.id, t.executor_contact_id,
+       (SELECT s.signed_by_user_id FROM document_signatures s
+        WHERE s.document_type = t.document_type AND s.document_id = t.id AND s.action = 'signed'
+        ORDER BY s.signed_at DESC LIMIT 1),
+       (SELECT s.resolution_text FROM document_signatures s
+        WHERE s.document_type = t.document_type AND s.document_id = t.id AND s.action = 'signed'
+        ORDER BY s.signed_at DESC LIMIT 1),
+       t.due_date
+FROM (
+    SELECT 'decree' AS document_type, id, executor_contact_id, due_date, status_id FROM decrees
+    UNION ALL
+    SELECT 'report', id, executor_contact_id, due_date, status_id FROM reports
+    UNION ALL
+    SELECT 'letter', id, executor_contact_id, due_date, status_id FROM letters
+    UNION ALL
+    SELECT 'instruction', id, executor_contact_id, due_date, status_id FROM instructions
+) t
+JOIN document_status ds ON ds.id = t.status_id
+WHERE ds.code IN ('signed', 'in_execution')
+  AND t.executor_contact_id IS NOT NULL;
+
+COMMENT ON TABLE document_execution_controls IS 'Контроль исполнения резолюций: исполнитель, срок, статус';
+COMMENT ON TABLE document_execution_reports IS 'Отчёты исполнителя о ходе и завершении исполнения, решения контролёра';
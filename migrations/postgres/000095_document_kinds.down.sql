@@ -0,0 +1,22 @@
+DROP FUNCTION IF EXISTS create_document_kind(TEXT, TEXT, TEXT, INTEGER);
+DROP VIEW IF EXISTS registry_documents;
+DROP FUNCTION IF EXISTS refresh_registry_documents_view();
+DROP FUNCTION IF EXISTS log_document_status_change();
+
+ALTER TABLE document_execution_controls
+    DROP CONSTRAINT fk_execution_control_document_kind,
+    ADD CONSTRAINT chk_execution_control_document_type CHECK (document_type IN ('decree', 'report', 'letter', 'instruction'));
+
+ALTER TABLE approval_routes
+    DROP CONSTRAINT fk_approval_route_document_kind,
+    ADD CONSTRAINT chk_approval_route_document_type CHECK (document_type IN ('decree', 'report', 'letter', 'instruction'));
+
+ALTER TABLE document_status_transitions
+    DROP CONSTRAINT fk_dst_document_kind,
+    ADD CONSTRAINT chk_dst_document_type CHECK (document_type IN ('decree', 'report', 'letter', 'instruction'));
+
+ALTER TABLE document_signatures
+    DROP CONSTRAINT fk_document_signatures_kind,
+    ADD CONSTRAINT chk_document_type CHECK (document_type IN ('decree', 'report', 'letter', 'instruction'));
+
+DROP TABLE IF EXISTS document_kinds;
@@ -0,0 +1,200 @@
+-- Document kind catalogue (Виды документов)
+--
+-- Decrees, reports, letters and instructions become kinds of one document
+-- registry served by one set of endpoints. Every kind keeps the standard
+-- table layout the four original modules already share:
+--
+--   <table_name>              documents of the kind
+--   <code>_type               types of the kind
+--   <code>_file_links         attached files (<code>_id, file_id)
+--   <code>_document_links     links to other documents
+--   <code>_status_history     status changes, written by a trigger
+--
+-- A new kind is added with SELECT create_document_kind(code, name, table),
+-- which creates these tables, copies the decree status graph, registers the
+-- kind and rebuilds the registry_documents view. No code change is needed.
+
+CREATE TABLE document_kinds (
+    code            VARCHAR(30)  PRIMARY KEY,
+    name            VARCHAR(255) NOT NULL,
+    table_name      VARCHAR(63)  NOT NULL UNIQUE,
+    required_fields TEXT[]       NOT NULL DEFAULT '{}',
+    unique_number   BOOLEAN      NOT NULL DEFAULT FALSE,
+    is_active       BOOLEAN      NOT NULL DEFAULT TRUE,
+    display_order   INTEGER      NOT NULL DEFAULT 0,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ,
+    CONSTRAINT chk_document_kind_code CHECK (code ~ '^[a-z][a-z0-9_]*$' AND code <> 'legal_document'),
+    CONSTRAINT chk_document_kind_table CHECK (table_name ~ '^[a-z][a-z0-9_]*$'),
+    CONSTRAINT chk_document_kind_required_fields CHECK (required_fields <@ ARRAY[
+        'number', 'description', 'responsible_contact_id', 'organization_id',
+        'executor_contact_id', 'due_date', 'parent_document_id', 'file_ids'
+    ]::TEXT[])
+);
+
+CREATE TRIGGER set_timestamp_document_kinds
+    BEFORE UPDATE ON document_kinds
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+INSERT INTO document_kinds (code, name, table_name, display_order) VALUES
+    ('decree', 'Приказ', 'decrees', 1),
+    ('report', 'Рапорт', 'reports', 2),
+    ('letter', 'Письмо', 'letters', 3),
+    ('instruction', 'Инструкция', 'instructions', 4);
+
+-- The catalogue replaces the hard-coded document type lists
+ALTER TABLE document_signatures
+    DROP CONSTRAINT chk_document_type,
+    ADD CONSTRAINT fk_document_signatures_kind
+        FOREIGN KEY (document_type) REFERENCES document_kinds (code) ON DELETE RESTRICT;
+
+ALTER TABLE document_status_transitions
+    DROP CONSTRAINT chk_dst_document_type,
+    ADD CONSTRAINT fk_dst_document_kind
+        FOREIGN KEY (document_type) REFERENCES document_kinds (code) ON DELETE CASCADE;
+
+ALTER TABLE approval_routes
+    DROP CONSTRAINT chk_approval_route_document_type,
+    ADD CONSTRAINT fk_approval_route_document_kind
+        FOREIGN KEY (document_type) REFERENCES document_kinds (code) ON DELETE RESTRICT;
+
+ALTER TABLE document_execution_controls
+    DROP CONSTRAINT chk_execution_control_document_type,
+    ADD CONSTRAINT fk_execution_control_document_kind
+        FOREIGN KEY (document_type) REFERENCES document_kinds (code) ON DELETE RESTRICT;
+
+-- Status history trigger for kinds created by create_document_kind.
+-- TG_ARGV[0] is the kind code.
+CREATE OR REPLACE FUNCTION log_document_status_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    EXECUTE format(
+        'INSERT INTO %I (%I, from_status_id, to_status_id, changed_by_user_id) VALUES ($1, $2, $3, $4)',
+        TG_ARGV[0] || '_status_history', TG_ARGV[0] || '_id')
+    USING NEW.id, OLD.status_id, NEW.status_id, NEW.updated_by_user_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- registry_documents lists the documents of every kind with the kind code in
+-- document_type. Cross-kind queries (pending signatures, execution control,
+-- linked document names) read it instead of naming the kind tables.
+CREATE OR REPLACE FUNCTION refresh_registry_documents_view()
+RETURNS VOID AS $$
+DECLARE
+    k     RECORD;
+    parts TEXT[] := '{}';
+BEGIN
+    FOR k IN SELECT code, table_name FROM document_kinds ORDER BY display_order, code LOOP
+        parts := parts || format(
+            'SELECT %L::VARCHAR(30) AS document_type, d.id, d.name, d.number, d.document_date, d.description,
+                    d.type_id, t.name AS type_name, d.status_id, d.responsible_contact_id, d.organization_id,
+                    d.executor_contact_id, d.due_date, d.parent_document_id,
+                    d.created_at, d.created_by_user_id, d.updated_at, d.updated_by_user_id
+             FROM %I d JOIN %I t ON t.id = d.type_id',
+            k.code, k.table_name, k.code || '_type');
+    END LOOP;
+    EXECUTE 'CREATE OR REPLACE VIEW registry_documents AS ' || array_to_string(parts, ' UNION ALL ');
+END;
+$$ LANGUAGE plpgsql;
+
+SELECT refresh_registry_documents_view();
+
+CREATE OR REPLACE FUNCTION create_document_kind(p_code TEXT, p_name TEXT, p_table_name TEXT, p_display_order INTEGER DEFAULT 0)
+RETURNS VOID AS $$
+BEGIN
+    -- The catalogue checks validate the identifiers used below
+    INSERT INTO document_kinds (code, name, table_name, display_order)
+    VALUES (p_code, p_name, p_table_name, p_display_order);
+
+    EXECUTE format('
+        CREATE TABLE %I (
+            id SERIAL PRIMARY KEY,
+            name VARCHAR(500) NOT NULL UNIQUE,
+            description TEXT,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )', p_code || '_type');
+
+    EXECUTE format('
+        CREATE TABLE %1$I (
+            id BIGSERIAL PRIMARY KEY,
+            name VARCHAR(500) NOT NULL,
+            number VARCHAR(100),
+            document_date DATE NOT NULL,
+            description TEXT,
+            type_id INTEGER NOT NULL REFERENCES %2$I(id) ON DELETE RESTRICT,
+            status_id INTEGER NOT NULL REFERENCES document_status(id) ON DELETE RESTRICT DEFAULT 1,
+            responsible_contact_id BIGINT REFERENCES contacts(id) ON DELETE SET NULL,
+            organization_id BIGINT REFERENCES organizations(id) ON DELETE SET NULL,
+            executor_contact_id BIGINT REFERENCES contacts(id) ON DELETE SET NULL,
+            due_date DATE,
+            parent_document_id BIGINT REFERENCES %1$I(id) ON DELETE SET NULL,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+            created_by_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+            updated_at TIMESTAMPTZ,
+            updated_by_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL
+        )', p_table_name, p_code || '_type');
+
+    EXECUTE format('
+        CREATE TABLE %I (
+            %I BIGINT NOT NULL REFERENCES %I(id) ON DELETE CASCADE,
+            file_id BIGINT NOT NULL REFERENCES files(id) ON DELETE CASCADE,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+            PRIMARY KEY (%2$I, file_id)
+        )', p_code || '_file_links', p_code || '_id', p_table_name);
+
+    EXECUTE format('
+        CREATE TABLE %I (
+            id BIGSERIAL PRIMARY KEY,
+            %I BIGINT NOT NULL REFERENCES %I(id) ON DELETE CASCADE,
+            linked_document_type VARCHAR(50) NOT NULL,
+            linked_document_id BIGINT NOT NULL,
+            link_description TEXT,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+            created_by_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+            UNIQUE (%2$I, linked_document_type, linked_document_id)
+        )', p_code || '_document_links', p_code || '_id', p_table_name);
+
+    EXECUTE format('
+        CREATE TABLE %I (
+            id BIGSERIAL PRIMARY KEY,
+            %I BIGINT NOT NULL REFERENCES %I(id) ON DELETE CASCADE,
+            from_status_id INTEGER REFERENCES document_status(id) ON DELETE SET NULL,
+            to_status_id INTEGER NOT NULL REFERENCES document_status(id) ON DELETE RESTRICT,
+            changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+            changed_by_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+            comment TEXT
+        )', p_code || '_status_history', p_code || '_id', p_table_name);
+
+    EXECUTE format('
+        CREATE TRIGGER %I BEFORE UPDATE ON %I
+        FOR EACH ROW EXECUTE FUNCTION trigger_set_timestamp()',
+        'set_timestamp_' || p_table_name, p_table_name);
+
+    EXECUTE format('
+        CREATE TRIGGER %I AFTER UPDATE ON %I
+        FOR EACH ROW WHEN (OLD.status_id IS DISTINCT FROM NEW.status_id)
+        EXECUTE FUNCTION log_document_status_change(%L)',
+        'log_' || p_code || '_status_change_trigger', p_table_name, p_code);
+
+    EXECUTE format('CREATE INDEX %I ON %I (status_id)', 'idx_' || p_table_name || '_status', p_table_name);
+    EXECUTE format('CREATE INDEX %I ON %I (document_date DESC)', 'idx_' || p_table_name || '_date', p_table_name);
+    EXECUTE format('CREATE INDEX %I ON %I (number)', 'idx_' || p_table_name || '_number', p_table_name);
+    EXECUTE format('CREATE INDEX %I ON %I (executor_contact_id)', 'idx_' || p_table_name || '_executor', p_table_name);
+    EXECUTE format('CREATE INDEX %I ON %I (%I)', 'idx_' || p_code || '_status_history_doc', p_code || '_status_history', p_code || '_id');
+
+    -- A new kind starts with the decree status graph; rais can replace it
+    -- through PUT /document-statuses/transitions/{type}.
+    INSERT INTO document_status_transitions (document_type, from_status_id, to_status_id, allowed_roles, require_comment, require_attachments)
+    SELECT p_code, from_status_id, to_status_id, allowed_roles, require_comment, require_attachments
+    FROM document_status_transitions
+    WHERE document_type = 'decree';
+
+    PERFORM refresh_registry_documents_view();
+END;
+$$ LANGUAGE plpgsql;
+
+COMMENT ON TABLE document_kinds IS 'Справочник видов документов реестра';
+COMMENT ON COLUMN document_kinds.required_fields IS 'Поля, обязательные при создании документа этого вида';
+COMMENT ON COLUMN document_kinds.unique_number IS 'Номер документа уникален в пределах вида';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS registration_numbers;
+DROP TABLE IF EXISTS numbering_counters;
+DROP TABLE IF EXISTS numbering_schemes;
@@ -0,0 +1,69 @@
+-- Automatic registration numbering. A scheme gives the number pattern of a
+-- document kind, either for one organization or as the kind's default
+-- (organization_id NULL). Counters hand out sequence values per scheme and
+-- period; every number issued, reserved or cancelled is kept in the journal.
+
+CREATE TABLE numbering_schemes (
+    id                 BIGSERIAL PRIMARY KEY,
+    document_kind      VARCHAR(30)  NOT NULL REFERENCES document_kinds (code) ON DELETE CASCADE,
+    organization_id    BIGINT REFERENCES organizations (id) ON DELETE CASCADE,
+    org_code           VARCHAR(30),
+    pattern            VARCHAR(100) NOT NULL,
+    reset_period       VARCHAR(10)  NOT NULL DEFAULT 'yearly',
+    allow_reservation  BOOLEAN      NOT NULL DEFAULT FALSE,
+    is_active          BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_by_user_id BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    created_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_numbering_scheme_reset CHECK (reset_period IN ('yearly', 'never'))
+);
+
+-- One scheme per kind and organization, and one default per kind.
+CREATE UNIQUE INDEX uq_numbering_schemes_kind_org
+    ON numbering_schemes (document_kind, COALESCE(organization_id, 0));
+
+-- Numbers are unique per kind, so the org codes that tell the organizations'
+-- schemes apart must be too.
+CREATE UNIQUE INDEX uq_numbering_schemes_kind_org_code
+    ON numbering_schemes (document_kind, org_code)
+    WHERE organization_id IS NOT NULL;
+
+CREATE TRIGGER set_timestamp_numbering_schemes
+    BEFORE UPDATE ON numbering_schemes
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+-- period is the year for yearly schemes and 0 for schemes that never reset.
+CREATE TABLE numbering_counters (
+    scheme_id BIGINT  NOT NULL REFERENCES numbering_schemes (id) ON DELETE CASCADE,
+    period    INTEGER NOT NULL,
+    last_seq  INTEGER NOT NULL,
+    PRIMARY KEY (scheme_id, period)
+);
+
+CREATE TABLE registration_numbers (
+    id                   BIGSERIAL PRIMARY KEY,
+    scheme_id            BIGINT       NOT NULL REFERENCES numbering_schemes (id) ON DELETE RESTRICT,
+    document_kind        VARCHAR(30)  NOT NULL REFERENCES document_kinds (code) ON DELETE RESTRICT,
+    organization_id      BIGINT REFERENCES organizations (id) ON DELETE SET NULL,
+    period               INTEGER      NOT NULL,
+    seq                  INTEGER      NOT NULL,
+    number               VARCHAR(100) NOT NULL,
+    registered_on        DATE         NOT NULL,
+    status               VARCHAR(20)  NOT NULL,
+    document_id          BIGINT,
+    reserved_by_user_id  BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    issued_at            TIMESTAMPTZ,
+    cancelled_at         TIMESTAMPTZ,
+    cancelled_by_user_id BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    cancel_reason        TEXT,
+    created_at           TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_registration_number_status CHECK (status IN ('reserved', 'issued', 'cancelled')),
+    CONSTRAINT chk_registration_number_document CHECK (status <> 'issued' OR document_id IS NOT NULL),
+    CONSTRAINT uq_registration_numbers_seq UNIQUE (scheme_id, period, seq),
+    -- Cancelled numbers stay in the journal and are never handed out again.
+    CONSTRAINT uq_registration_numbers_number UNIQUE (document_kind, number)
+);
+
+CREATE INDEX idx_registration_numbers_document ON registration_numbers (document_kind, document_id);
+CREATE INDEX idx_registration_numbers_period ON registration_numbers (document_kind, period);
@@ -0,0 +1,33 @@
+DO $$
+DECLARE
+    k RECORD;
+BEGIN
+    FOR k IN SELECT code, table_name FROM document_kinds LOOP
+        EXECUTE format('DROP TRIGGER IF EXISTS %I ON %I', 'search_' || k.code || '_changed', k.table_name);
+        EXECUTE format('DROP TRIGGER IF EXISTS %I ON %I', 'search_' || k.code || '_file_link_changed', k.code || '_file_links');
+    END LOOP;
+END;
+$$;
+
+DROP FUNCTION IF EXISTS create_document_kind(TEXT, TEXT, TEXT, INTEGER);
+ALTER FUNCTION create_document_kind_tables(TEXT, TEXT, TEXT, INTEGER) RENAME TO create_document_kind;
+
+DROP TRIGGER IF EXISTS search_legal_file_link_changed ON legal_document_file_links;
+DROP TRIGGER IF EXISTS search_legal_document_changed ON legal_documents;
+DROP TRIGGER IF EXISTS search_signature_changed ON document_signatures;
+
+DROP FUNCTION IF EXISTS search_reindex_all();
+DROP FUNCTION IF EXISTS attach_document_search(TEXT);
+DROP FUNCTION IF EXISTS search_file_text_trigger() CASCADE;
+DROP FUNCTION IF EXISTS search_legal_file_link_trigger();
+DROP FUNCTION IF EXISTS search_legal_document_trigger();
+DROP FUNCTION IF EXISTS search_signature_trigger();
+DROP FUNCTION IF EXISTS search_registry_file_link_trigger();
+DROP FUNCTION IF EXISTS search_registry_document_trigger();
+DROP FUNCTION IF EXISTS search_index_legal_document(BIGINT);
+DROP FUNCTION IF EXISTS search_index_registry_document(TEXT, BIGINT);
+
+DROP TABLE IF EXISTS search_entries;
+DROP TABLE IF EXISTS file_texts;
+
+DROP TEXT SEARCH CONFIGURATION IF EXISTS uzbek;
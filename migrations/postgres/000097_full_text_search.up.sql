@@ -0,0 +1,316 @@
+-- Full-text search (Полнотекстовый поиск)
+--
+-- search_entries holds one row per searchable entity: registry documents of
+-- every kind (entity_type = kind code) and legal documents (entity_type =
+-- 'legal_document'). Rows are maintained by triggers on the source tables,
+-- so every write path is covered. Attachment text is extracted from MinIO by
+-- the application into file_texts; updating a file_texts row reindexes the
+-- documents the file is attached to.
+--
+-- Text is indexed with the russian configuration (stemming) and with uzbek,
+-- a copy of simple, since Postgres ships no Uzbek stemmer. Queries match
+-- either.
+
+CREATE TEXT SEARCH CONFIGURATION uzbek (COPY = simple);
+
+CREATE TABLE file_texts (
+    file_id      BIGINT      PRIMARY KEY REFERENCES files (id) ON DELETE CASCADE,
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending',
+    content      TEXT,
+    error        TEXT,
+    attempts     INTEGER     NOT NULL DEFAULT 0,
+    extracted_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_file_text_status CHECK (status IN ('pending', 'extracted', 'unsupported', 'failed'))
+);
+
+CREATE INDEX idx_file_texts_pending ON file_texts (created_at) WHERE status = 'pending';
+
+CREATE TABLE search_entries (
+    entity_type     VARCHAR(30)  NOT NULL,
+    entity_id       BIGINT       NOT NULL,
+    organization_id BIGINT,
+    type_id         INTEGER,
+    type_name       VARCHAR(500),
+    status_id       INTEGER,
+    document_date   DATE,
+    title           TEXT         NOT NULL,
+    number          VARCHAR(100),
+    description     TEXT,
+    resolutions     TEXT,
+    attachments     TEXT,
+    tsv             TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', coalesce(number, '') || ' ' || title), 'A') ||
+        setweight(to_tsvector('uzbek', coalesce(number, '') || ' ' || title), 'A') ||
+        setweight(to_tsvector('russian', coalesce(description, '') || ' ' || coalesce(resolutions, '')), 'B') ||
+        setweight(to_tsvector('uzbek', coalesce(description, '') || ' ' || coalesce(resolutions, '')), 'B') ||
+        setweight(to_tsvector('russian', coalesce(attachments, '')), 'C') ||
+        setweight(to_tsvector('uzbek', coalesce(attachments, '')), 'C')
+    ) STORED,
+    updated_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (entity_type, entity_id)
+);
+
+CREATE INDEX idx_search_entries_tsv ON search_entries USING GIN (tsv);
+CREATE INDEX idx_search_entries_org ON search_entries (organization_id);
+CREATE INDEX idx_search_entries_date ON search_entries (document_date DESC);
+
+-- Attachment text is capped so the combined tsvector stays under the 1 MB
+-- limit.
+CREATE OR REPLACE FUNCTION search_index_registry_document(p_kind TEXT, p_id BIGINT)
+RETURNS VOID AS $$
+DECLARE
+    d           RECORD;
+    resolutions TEXT;
+    attachments TEXT;
+BEGIN
+    SELECT * INTO d FROM registry_documents WHERE document_type = p_kind AND id = p_id;
+    IF NOT FOUND THEN
+        DELETE FROM search_entries WHERE entity_type = p_kind AND entity_id = p_id;
+        RETURN;
+    END IF;
+
+    SELECT string_agg(s.resolution_text, E'\n' ORDER BY s.signed_at)
+    INTO resolutions
+    FROM document_signatures s
+    WHERE s.document_type = p_kind AND s.document_id = p_id AND s.resolution_text IS NOT NULL;
+
+    EXECUTE format(
+        'SELECT left(string_agg(ft.content, E''\n''), 400000)
+         FROM %I l JOIN file_texts ft ON ft.file_id = l.file_id AND ft.status = ''extracted''
+         WHERE l.%I = $1',
+        p_kind || '_file_links', p_kind || '_id')
+    INTO attachments
+    USING p_id;
+
+    INSERT INTO search_entries (entity_type, entity_id, organization_id, type_id, type_name, status_id,
+                                document_date, title, number, description, resolutions, attachments, updated_at)
+    VALUES (p_kind, p_id, d.organization_id, d.type_id, d.type_name, d.status_id,
+            d.document_date, d.name, d.number, d.description, resolutions, attachments, NOW())
+    ON CONFLICT (entity_type, entity_id) DO UPDATE SET
+        organization_id = EXCLUDED.organization_id,
+        type_id         = EXCLUDED.type_id,
+        type_name       = EXCLUDED.type_name,
+        status_id       = EXCLUDED.status_id,
+        document_date   = EXCLUDED.document_date,
+        title           = EXCLUDED.title,
+        number          = EXCLUDED.number,
+        description     = EXCLUDED.description,
+        resolutions     = EXCLUDED.resolutions,
+        attachments     = EXCLUDED.attachments,
+        updated_at      = NOW();
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION search_index_legal_document(p_id BIGINT)
+RETURNS VOID AS $$
+DECLARE
+    d           RECORD;
+    attachments TEXT;
+BEGIN
+    SELECT ld.id, ld.name, ld.number, ld.document_date, ld.type_id, t.name AS type_name
+    INTO d
+    FROM legal_documents ld JOIN legal_document_type t ON t.id = ld.type_id
+    WHERE ld.id = p_id;
+    IF NOT FOUND THEN
+        DELETE FROM search_entries WHERE entity_type = 'legal_document' AND entity_id = p_id;
+        RETURN;
+    END IF;
+
+    SELECT left(string_agg(ft.content, E'\n'), 400000)
+    INTO attachments
+    FROM legal_document_file_links l
+    JOIN file_texts ft ON ft.file_id = l.file_id AND ft.status = 'extracted'
+    WHERE l.document_id = p_id;
+
+    INSERT INTO search_entries (entity_type, entity_id, type_id, type_name, document_date, title, number, attachments, updated_at)
+    VALUES ('legal_document', p_id, d.type_id, d.type_name, d.document_date, d.name, d.number, attachments, NOW())
+    ON CONFLICT (entity_type, entity_id) DO UPDATE SET
+        type_id       = EXCLUDED.type_id,
+        type_name     = EXCLUDED.type_name,
+        document_date = EXCLUDED.document_date,
+        title         = EXCLUDED.title,
+        number        = EXCLUDED.number,
+        attachments   = EXCLUDED.attachments,
+        updated_at    = NOW();
+END;
+$$ LANGUAGE plpgsql;
+
+-- Kind tables. TG_ARGV[0] is the kind code.
+CREATE OR REPLACE FUNCTION search_registry_document_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        DELETE FROM search_entries WHERE entity_type = TG_ARGV[0] AND entity_id = OLD.id;
+        RETURN OLD;
+    END IF;
+    PERFORM search_index_registry_document(TG_ARGV[0], NEW.id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Kind file link tables. A newly attached file is queued for extraction.
+CREATE OR REPLACE FUNCTION search_registry_file_link_trigger()
+RETURNS TRIGGER AS $$
+DECLARE
+    link JSONB;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        link := to_jsonb(OLD);
+    ELSE
+        link := to_jsonb(NEW);
+        INSERT INTO file_texts (file_id) VALUES (NEW.file_id) ON CONFLICT (file_id) DO NOTHING;
+    END IF;
+    PERFORM search_index_registry_document(TG_ARGV[0], (link ->> (TG_ARGV[0] || '_id'))::BIGINT);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION search_signature_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM search_index_registry_document(NEW.document_type, NEW.document_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION search_legal_document_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        DELETE FROM search_entries WHERE entity_type = 'legal_document' AND entity_id = OLD.id;
+        RETURN OLD;
+    END IF;
+    PERFORM search_index_legal_document(NEW.id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION search_legal_file_link_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM search_index_legal_document(OLD.document_id);
+    ELSE
+        INSERT INTO file_texts (file_id) VALUES (NEW.file_id) ON CONFLICT (file_id) DO NOTHING;
+        PERFORM search_index_legal_document(NEW.document_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Extracted text reindexes every document the file is attached to.
+CREATE OR REPLACE FUNCTION search_file_text_trigger()
+RETURNS TRIGGER AS $$
+DECLARE
+    k      RECORD;
+    doc_id BIGINT;
+BEGIN
+    FOR k IN SELECT code FROM document_kinds LOOP
+        FOR doc_id IN EXECUTE format('SELECT %I FROM %I WHERE file_id = $1', k.code || '_id', k.code || '_file_links')
+            USING NEW.file_id
+        LOOP
+            PERFORM search_index_registry_document(k.code, doc_id);
+        END LOOP;
+    END LOOP;
+
+    FOR doc_id IN SELECT document_id FROM legal_document_file_links WHERE file_id = NEW.file_id LOOP
+        PERFORM search_index_legal_document(doc_id);
+    END LOOP;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER search_file_text_changed
+    AFTER UPDATE OF status, content ON file_texts
+    FOR EACH ROW
+    WHEN (NEW.status = 'extracted' OR OLD.status = 'extracted')
+    EXECUTE FUNCTION search_file_text_trigger();
+
+CREATE TRIGGER search_signature_changed
+    AFTER INSERT OR UPDATE OF resolution_text ON document_signatures
+    FOR EACH ROW
+    EXECUTE FUNCTION search_signature_trigger();
+
+CREATE TRIGGER search_legal_document_changed
+    AFTER INSERT OR UPDATE OR DELETE ON legal_documents
+    FOR EACH ROW
+    EXECUTE FUNCTION search_legal_document_trigger();
+
+CREATE TRIGGER search_legal_file_link_changed
+    AFTER INSERT OR DELETE ON legal_document_file_links
+    FOR EACH ROW
+    EXECUTE FUNCTION search_legal_file_link_trigger();
+
+-- attach_document_search installs the search triggers on a kind's tables.
+CREATE OR REPLACE FUNCTION attach_document_search(p_code TEXT)
+RETURNS VOID AS $$
+DECLARE
+    tbl TEXT;
+BEGIN
+    SELECT table_name INTO tbl FROM document_kinds WHERE code = p_code;
+
+    EXECUTE format('
+        CREATE TRIGGER %I AFTER INSERT OR UPDATE OR DELETE ON %I
+        FOR EACH ROW EXECUTE FUNCTION search_registry_document_trigger(%L)',
+        'search_' || p_code || '_changed', tbl, p_code);
+
+    EXECUTE format('
+        CREATE TRIGGER %I AFTER INSERT OR DELETE ON %I
+        FOR EACH ROW EXECUTE FUNCTION search_registry_file_link_trigger(%L)',
+        'search_' || p_code || '_file_link_changed', p_code || '_file_links', p_code);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Kinds created from now on get the triggers too.
+ALTER FUNCTION create_document_kind(TEXT, TEXT, TEXT, INTEGER) RENAME TO create_document_kind_tables;
+
+CREATE OR REPLACE FUNCTION create_document_kind(p_code TEXT, p_name TEXT, p_table_name TEXT, p_display_order INTEGER DEFAULT 0)
+RETURNS VOID AS $$
+BEGIN
+    PERFORM create_document_kind_tables(p_code, p_name, p_table_name, p_display_order);
+    PERFORM attach_document_search(p_code);
+END;
+$$ LANGUAGE plpgsql;
+
+-- search_reindex_all rebuilds every entry from the source tables.
+CREATE OR REPLACE FUNCTION search_reindex_all()
+RETURNS INTEGER AS $$
+DECLARE
+    d RECORD;
+    n INTEGER := 0;
+BEGIN
+    DELETE FROM search_entries;
+    FOR d IN SELECT document_type, id FROM registry_documents LOOP
+        PERFORM search_index_registry_document(d.document_type, d.id);
+        n := n + 1;
+    END LOOP;
+    FOR d IN SELECT id FROM legal_documents LOOP
+        PERFORM search_index_legal_document(d.id);
+        n := n + 1;
+    END LOOP;
+    RETURN n;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Existing kinds: install triggers, queue attachments, build the index
+DO $$
+DECLARE
+    k RECORD;
+BEGIN
+    FOR k IN SELECT code FROM document_kinds LOOP
+        PERFORM attach_document_search(k.code);
+        EXECUTE format('INSERT INTO file_texts (file_id) SELECT DISTINCT file_id FROM %I ON CONFLICT (file_id) DO NOTHING',
+                       k.code || '_file_links');
+    END LOOP;
+END;
+$$;
+
+INSERT INTO file_texts (file_id)
+SELECT DISTINCT file_id FROM legal_document_file_links
+ON CONFLICT (file_id) DO NOTHING;
+
+SELECT search_reindex_all();
+
+COMMENT ON TABLE search_entries IS 'Индекс полнотекстового поиска по документам';
+COMMENT ON TABLE file_texts IS 'Текст, извлечённый из вложений, для полнотекстового поиска';
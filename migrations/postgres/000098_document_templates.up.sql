@@ -0,0 +1,28 @@
+-- Document templates (Шаблоны документов)
+--
+-- DOCX forms per document kind with {{placeholder}} fields. The template file
+-- is stored in MinIO like any other file; placeholders lists the fields found
+-- in it when it was uploaded.
+
+CREATE TABLE document_templates (
+    id                 BIGSERIAL    PRIMARY KEY,
+    document_kind      VARCHAR(30)  NOT NULL REFERENCES document_kinds (code) ON DELETE CASCADE,
+    name               VARCHAR(255) NOT NULL,
+    description        TEXT,
+    file_id            BIGINT       NOT NULL REFERENCES files (id) ON DELETE RESTRICT,
+    placeholders       TEXT[]       NOT NULL DEFAULT '{}',
+    is_active          BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_by_user_id BIGINT       REFERENCES users (id) ON DELETE SET NULL,
+    created_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ,
+    CONSTRAINT uq_document_template_name UNIQUE (document_kind, name)
+);
+
+CREATE TRIGGER set_timestamp_document_templates
+    BEFORE UPDATE ON document_templates
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+CREATE INDEX idx_document_templates_kind ON document_templates (document_kind) WHERE is_active;
+
+COMMENT ON TABLE document_templates IS 'Шаблоны DOCX для формирования документов реестра';
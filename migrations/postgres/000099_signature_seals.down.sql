@@ -0,0 +1,28 @@
+DO $$
+DECLARE
+    k RECORD;
+BEGIN
+    FOR k IN SELECT code FROM document_kinds LOOP
+        EXECUTE format('DROP TRIGGER IF EXISTS %I ON %I', 'lock_' || k.code || '_attachments', k.code || '_file_links');
+    END LOOP;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION create_document_kind(p_code TEXT, p_name TEXT, p_table_name TEXT, p_display_order INTEGER DEFAULT 0)
+RETURNS VOID AS $$
+BEGIN
+    PERFORM create_document_kind_tables(p_code, p_name, p_table_name, p_display_order);
+    PERFORM attach_document_search(p_code);
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS attach_attachment_lock(TEXT);
+DROP FUNCTION IF EXISTS document_attachments_lock_trigger();
+DROP FUNCTION IF EXISTS document_attachments_locked(TEXT, BIGINT);
+
+ALTER TABLE document_signatures
+    DROP COLUMN IF EXISTS seal_certificate,
+    DROP COLUMN IF EXISTS seal_value,
+    DROP COLUMN IF EXISTS seal_provider,
+    DROP COLUMN IF EXISTS content_manifest,
+    DROP COLUMN IF EXISTS content_digest;
@@ -0,0 +1,110 @@
+-- Tamper-evident signatures (Контроль целостности подписанных документов)
+--
+-- Signing records a manifest of the document's key fields and the SHA-256 of
+-- every attached file, the SHA-256 of that manifest (content_digest) and what
+-- the signature provider returned for the digest. While a document has such a
+-- signature and is not back in draft, its attachments cannot be changed.
+
+ALTER TABLE document_signatures
+    ADD COLUMN content_digest   CHAR(64),
+    ADD COLUMN content_manifest JSONB,
+    ADD COLUMN seal_provider    VARCHAR(50),
+    ADD COLUMN seal_value       TEXT,
+    ADD COLUMN seal_certificate TEXT;
+
+COMMENT ON COLUMN document_signatures.content_digest IS 'SHA-256 манифеста содержимого документа на момент подписания';
+COMMENT ON COLUMN document_signatures.content_manifest IS 'Ключевые поля документа и SHA-256 вложений на момент подписания';
+COMMENT ON COLUMN document_signatures.seal_provider IS 'Провайдер подписи: sha256, hmac-sha256 или провайдер ЭЦП';
+COMMENT ON COLUMN document_signatures.seal_value IS 'Подпись провайдера над content_digest (base64)';
+COMMENT ON COLUMN document_signatures.seal_certificate IS 'Сертификат подписанта, если провайдер его возвращает';
+
+-- document_attachments_locked reports whether the attachments of a document
+-- are sealed by a signature. The document row is locked FOR SHARE first, so
+-- a link change waits for a signing in progress (which holds it FOR UPDATE)
+-- and then sees its signature.
+CREATE OR REPLACE FUNCTION document_attachments_locked(p_kind TEXT, p_id BIGINT)
+RETURNS BOOLEAN AS $$
+DECLARE
+    tbl         TEXT;
+    status_code TEXT;
+BEGIN
+    SELECT table_name INTO tbl FROM document_kinds WHERE code = p_kind;
+    IF tbl IS NULL THEN
+        RETURN FALSE;
+    END IF;
+
+    EXECUTE format('
+        SELECT s.code FROM %I d JOIN document_status s ON s.id = d.status_id
+        WHERE d.id = $1 FOR SHARE OF d', tbl)
+        INTO status_code USING p_id;
+
+    -- A deleted document or one returned to draft for revision is open
+    IF status_code IS NULL OR status_code = 'draft' THEN
+        RETURN FALSE;
+    END IF;
+
+    RETURN EXISTS (
+        SELECT 1 FROM document_signatures
+        WHERE document_type = p_kind AND document_id = p_id
+          AND action = 'signed' AND content_digest IS NOT NULL
+    );
+END;
+$$ LANGUAGE plpgsql;
+
+-- SR001 is a SQLSTATE of our own, so the driver reports only this lock as
+-- "attachments locked".
+CREATE OR REPLACE FUNCTION document_attachments_lock_trigger()
+RETURNS TRIGGER AS $$
+DECLARE
+    fk     TEXT := TG_ARGV[0] || '_id';
+    doc_id BIGINT;
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        doc_id := (to_jsonb(OLD) ->> fk)::BIGINT;
+        IF document_attachments_locked(TG_ARGV[0], doc_id) THEN
+            RAISE EXCEPTION 'attachments of signed % % are locked', TG_ARGV[0], doc_id
+                USING ERRCODE = 'SR001';
+        END IF;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        doc_id := (to_jsonb(NEW) ->> fk)::BIGINT;
+        IF document_attachments_locked(TG_ARGV[0], doc_id) THEN
+            RAISE EXCEPTION 'attachments of signed % % are locked', TG_ARGV[0], doc_id
+                USING ERRCODE = 'SR001';
+        END IF;
+        RETURN NEW;
+    END IF;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+-- attach_attachment_lock installs the lock trigger on a kind's file links.
+CREATE OR REPLACE FUNCTION attach_attachment_lock(p_code TEXT)
+RETURNS VOID AS $$
+BEGIN
+    EXECUTE format('
+        CREATE TRIGGER %I BEFORE INSERT OR UPDATE OR DELETE ON %I
+        FOR EACH ROW EXECUTE FUNCTION document_attachments_lock_trigger(%L)',
+        'lock_' || p_code || '_attachments', p_code || '_file_links', p_code);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Kinds created from now on get the lock too.
+CREATE OR REPLACE FUNCTION create_document_kind(p_code TEXT, p_name TEXT, p_table_name TEXT, p_display_order INTEGER DEFAULT 0)
+RETURNS VOID AS $$
+BEGIN
+    PERFORM create_document_kind_tables(p_code, p_name, p_table_name, p_display_order);
+    PERFORM attach_document_search(p_code);
+    PERFORM attach_attachment_lock(p_code);
+END;
+$$ LANGUAGE plpgsql;
+
+DO $$
+DECLARE
+    k RECORD;
+BEGIN
+    FOR k IN SELECT code FROM document_kinds LOOP
+        PERFORM attach_attachment_lock(k.code);
+    END LOOP;
+END;
+$$;
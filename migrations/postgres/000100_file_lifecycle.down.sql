@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS file_cleanup_runs;
+DROP TABLE IF EXISTS file_retention_policies;
+
+-- Fails while deduplicated uploads still share an object.
+DROP INDEX IF EXISTS idx_files_object_key;
+ALTER TABLE files ADD CONSTRAINT files_object_key_key UNIQUE (object_key);
+
+DROP INDEX IF EXISTS idx_files_content_sha256;
+ALTER TABLE files DROP COLUMN IF EXISTS content_sha256;
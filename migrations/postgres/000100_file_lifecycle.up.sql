@@ -0,0 +1,64 @@
+-- File storage lifecycle (Жизненный цикл файлов)
+--
+-- Uploads are deduplicated by content: a file whose SHA-256 matches a stored
+-- one gets its own files row pointing at the existing object, so several rows
+-- may share an object_key. An object is removed once no row refers to it.
+--
+-- Retention policies are per file category: files older than retention_days
+-- are purged, and with purge_orphans files no table refers to are purged once
+-- orphan_grace_hours have passed (uploads are linked by a later request).
+-- Categories without a policy are left alone: their files may be read by
+-- category and date without ever being linked.
+
+ALTER TABLE files ADD COLUMN content_sha256 CHAR(64);
+
+CREATE INDEX idx_files_content_sha256 ON files (content_sha256) WHERE content_sha256 IS NOT NULL;
+
+ALTER TABLE files DROP CONSTRAINT IF EXISTS files_object_key_key;
+CREATE INDEX idx_files_object_key ON files (object_key);
+
+COMMENT ON COLUMN files.content_sha256 IS 'SHA-256 содержимого, по нему загрузки дедуплицируются';
+
+CREATE TABLE file_retention_policies (
+    category_id        BIGINT      PRIMARY KEY REFERENCES categories (id) ON DELETE CASCADE,
+    retention_days     INTEGER,
+    purge_orphans      BOOLEAN     NOT NULL DEFAULT FALSE,
+    orphan_grace_hours INTEGER     NOT NULL DEFAULT 24,
+    updated_by_user_id BIGINT      REFERENCES users (id) ON DELETE SET NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ,
+    CONSTRAINT chk_retention_days CHECK (retention_days IS NULL OR retention_days > 0),
+    CONSTRAINT chk_orphan_grace_hours CHECK (orphan_grace_hours > 0)
+);
+
+CREATE TRIGGER set_timestamp_file_retention_policies
+    BEFORE UPDATE ON file_retention_policies
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+COMMENT ON TABLE file_retention_policies IS 'Сроки хранения файлов и очистка непривязанных файлов по категориям';
+
+-- Categories whose files are only ever attached to something
+INSERT INTO file_retention_policies (category_id, purge_orphans)
+SELECT id, TRUE FROM categories
+WHERE name IN ('events', 'incidents', 'ges-shutdowns', 'discharges', 'visits', 'generated-documents')
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE file_cleanup_runs (
+    id                   BIGSERIAL   PRIMARY KEY,
+    dry_run              BOOLEAN     NOT NULL,
+    triggered_by_user_id BIGINT      REFERENCES users (id) ON DELETE SET NULL,
+    started_at           TIMESTAMPTZ NOT NULL,
+    finished_at          TIMESTAMPTZ NOT NULL,
+    expired_files        INTEGER     NOT NULL DEFAULT 0,
+    orphan_files         INTEGER     NOT NULL DEFAULT 0,
+    orphan_objects       INTEGER     NOT NULL DEFAULT 0,
+    held_files           INTEGER     NOT NULL DEFAULT 0,
+    reclaimed_bytes      BIGINT      NOT NULL DEFAULT 0,
+    categories           JSONB       NOT NULL DEFAULT '[]',
+    errors               TEXT[]      NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX idx_file_cleanup_runs_started ON file_cleanup_runs (started_at DESC);
+
+COMMENT ON TABLE file_cleanup_runs IS 'Отчёты очистки хранилища: удалённые файлы и объекты, освобождённое место';
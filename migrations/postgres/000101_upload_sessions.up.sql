@@ -0,0 +1,43 @@
+-- Resumable uploads (Возобновляемая загрузка файлов)
+--
+-- A session is a MinIO multipart upload of a single file sent in chunks.
+-- Each accepted chunk becomes one part and moves offset_bytes forward; the
+-- client resumes after a broken link from the offset it reads back. Once the
+-- whole file is sent it is assembled into the object and recorded in files,
+-- and file_id is set so a repeated finish returns the same file. Sessions
+-- not touched until expires_at are aborted by a periodic cleanup.
+
+CREATE TABLE upload_sessions (
+    id                 UUID        PRIMARY KEY,
+    object_key         TEXT        NOT NULL,
+    multipart_id       TEXT        NOT NULL,
+    file_name          TEXT        NOT NULL,
+    content_type       TEXT        NOT NULL,
+    size_bytes         BIGINT      NOT NULL,
+    offset_bytes       BIGINT      NOT NULL DEFAULT 0,
+    parts              JSONB       NOT NULL DEFAULT '[]',
+    category_id        BIGINT      NOT NULL REFERENCES categories (id),
+    target_date        DATE        NOT NULL,
+    created_by_user_id BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    assembled_at       TIMESTAMPTZ,
+    file_id            BIGINT      REFERENCES files (id) ON DELETE SET NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ,
+    expires_at         TIMESTAMPTZ NOT NULL,
+    CONSTRAINT chk_upload_sessions_size CHECK (size_bytes > 0),
+    CONSTRAINT chk_upload_sessions_offset CHECK (offset_bytes >= 0 AND offset_bytes <= size_bytes)
+);
+
+CREATE INDEX idx_upload_sessions_expires_at ON upload_sessions (expires_at);
+
+CREATE TRIGGER set_timestamp_upload_sessions
+    BEFORE UPDATE ON upload_sessions
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+COMMENT ON TABLE upload_sessions IS 'Сеансы возобновляемой загрузки файлов частями';
+COMMENT ON COLUMN upload_sessions.multipart_id IS 'Идентификатор multipart-загрузки MinIO';
+COMMENT ON COLUMN upload_sessions.offset_bytes IS 'Сколько байт уже принято; следующая часть начинается с этого смещения';
+COMMENT ON COLUMN upload_sessions.parts IS 'Принятые части: номер и ETag, по ним собирается объект';
+COMMENT ON COLUMN upload_sessions.assembled_at IS 'Когда части собраны в объект';
+COMMENT ON COLUMN upload_sessions.file_id IS 'Файл, созданный при завершении загрузки';
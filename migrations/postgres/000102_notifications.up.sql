@@ -0,0 +1,57 @@
+-- Notifications (Уведомления)
+--
+-- Services raise typed events (vacation approved, salary paid, document
+-- waiting for signature, ...); each recipient gets the event on the channels
+-- chosen in notification_preferences, or on the event's default channels
+-- when there is no row. Every channel delivery is one row of
+-- notification_deliveries: in-app deliveries are written straight away,
+-- email and Telegram ones are sent by a background dispatcher that retries
+-- failures with a growing delay until max attempts.
+
+CREATE TABLE notification_preferences (
+    contact_id BIGINT      NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+    event_type VARCHAR(64) NOT NULL,
+    channels   TEXT[]      NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (contact_id, event_type),
+    CONSTRAINT chk_notification_preferences_channels
+        CHECK (channels <@ ARRAY ['in_app', 'email', 'telegram']::TEXT[])
+);
+
+-- Telegram chats linked to contacts; the address of the telegram channel.
+CREATE TABLE notification_telegram_chats (
+    contact_id BIGINT      PRIMARY KEY REFERENCES contacts (id) ON DELETE CASCADE,
+    chat_id    BIGINT      NOT NULL UNIQUE,
+    linked_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE notification_deliveries (
+    id              BIGSERIAL PRIMARY KEY,
+    contact_id      BIGINT      NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+    event_type      VARCHAR(64) NOT NULL,
+    channel         VARCHAR(16) NOT NULL,
+    severity        VARCHAR(20) NOT NULL,
+    title           TEXT        NOT NULL,
+    message         TEXT        NOT NULL,
+    link            TEXT,
+    status          VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error      TEXT,
+    notification_id BIGINT      REFERENCES hrm_notifications (id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at         TIMESTAMPTZ,
+    CONSTRAINT chk_notification_deliveries_channel CHECK (channel IN ('in_app', 'email', 'telegram')),
+    CONSTRAINT chk_notification_deliveries_status CHECK (status IN ('pending', 'sent', 'failed', 'skipped'))
+);
+
+CREATE INDEX idx_notification_deliveries_due
+    ON notification_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_notification_deliveries_contact ON notification_deliveries (contact_id, created_at DESC);
+CREATE INDEX idx_notification_deliveries_created_at ON notification_deliveries (created_at DESC);
+
+COMMENT ON TABLE notification_preferences IS 'Каналы доставки уведомлений, выбранные пользователем для типа события';
+COMMENT ON TABLE notification_telegram_chats IS 'Чаты Telegram, привязанные к контактам';
+COMMENT ON TABLE notification_deliveries IS 'Журнал доставки уведомлений по каналам';
+COMMENT ON COLUMN notification_deliveries.next_attempt_at IS 'Когда доставку можно (повторно) попытаться выполнить';
+COMMENT ON COLUMN notification_deliveries.notification_id IS 'Уведомление в приложении, созданное доставкой канала in_app';
@@ -0,0 +1,16 @@
+-- Telegram bot (Telegram-бот)
+--
+-- A user links a Telegram chat to the account by sending the bot a one-time
+-- code issued in the web app. The link itself is the existing row of
+-- notification_telegram_chats; the bot finds the user of a chat through
+-- users.contact_id. Only a SHA-256 hash of the code is stored; a user has
+-- at most one live code.
+
+CREATE TABLE telegram_link_codes (
+    code_hash  CHAR(64)    PRIMARY KEY,
+    user_id    BIGINT      NOT NULL UNIQUE REFERENCES users (id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_telegram_link_codes_expires ON telegram_link_codes (expires_at);
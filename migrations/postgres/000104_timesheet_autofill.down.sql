@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS employee_absences;
+
+ALTER TABLE timesheet_entries DROP COLUMN IF EXISTS source;
@@ -0,0 +1,28 @@
+-- Timesheet auto-fill (автозаполнение табеля)
+--
+-- The generator fills timesheet_entries from approved vacations, absences,
+-- the holiday calendar and access-control logs. Rows it writes are marked
+-- 'auto' and are rewritten whenever a source changes; rows entered by hand
+-- or through an approved correction are 'manual' and are never touched.
+-- Existing rows were all entered by hand.
+
+ALTER TABLE timesheet_entries
+    ADD COLUMN source VARCHAR(10) NOT NULL DEFAULT 'manual'
+        CHECK (source IN ('manual', 'auto'));
+
+-- Sick leave and business trips have no module of their own; HR records
+-- the period here from the sick note or the travel order.
+CREATE TABLE employee_absences (
+    id          BIGSERIAL PRIMARY KEY,
+    employee_id BIGINT      NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+    type        VARCHAR(20) NOT NULL CHECK (type IN ('sick_leave', 'business_trip')),
+    start_date  DATE        NOT NULL,
+    end_date    DATE        NOT NULL,
+    note        TEXT,
+    created_by  BIGINT      REFERENCES contacts (id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_employee_absences_dates CHECK (end_date >= start_date)
+);
+
+CREATE INDEX idx_employee_absences_employee_dates ON employee_absences (employee_id, start_date, end_date);
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS access_zone_presence;
+
+DROP INDEX IF EXISTS idx_access_logs_alert;
+DROP INDEX IF EXISTS uq_access_logs_controller_event;
+
+ALTER TABLE access_logs
+    DROP COLUMN IF EXISTS received_at,
+    DROP COLUMN IF EXISTS alert,
+    DROP COLUMN IF EXISTS event_id,
+    DROP COLUMN IF EXISTS controller_id,
+    DROP COLUMN IF EXISTS card_number;
+
+DELETE FROM access_logs WHERE employee_id IS NULL;
+ALTER TABLE access_logs ALTER COLUMN employee_id SET NOT NULL;
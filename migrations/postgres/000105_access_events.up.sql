@@ -0,0 +1,33 @@
+-- Access-control event ingestion (события СКУД)
+--
+-- Turnstile and door controllers post their events; each is matched to a
+-- card and an employee by card number. An unknown card leaves employee_id
+-- empty. alert highlights what security should look at: an attempt with a
+-- blocked, lost, expired or unknown card, an anti-passback violation, or an
+-- entry over the zone's max_occupancy. (controller_id, event_id) makes a
+-- resent event a no-op.
+
+ALTER TABLE access_logs ALTER COLUMN employee_id DROP NOT NULL;
+
+ALTER TABLE access_logs
+    ADD COLUMN card_number   VARCHAR(100),
+    ADD COLUMN controller_id VARCHAR(100),
+    ADD COLUMN event_id      VARCHAR(100),
+    ADD COLUMN alert         VARCHAR(30)
+        CHECK (alert IN ('card_blocked', 'card_lost', 'card_expired', 'card_deactivated',
+                         'unknown_card', 'anti_passback', 'occupancy_exceeded')),
+    ADD COLUMN received_at   TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE UNIQUE INDEX uq_access_logs_controller_event
+    ON access_logs (controller_id, event_id) WHERE event_id IS NOT NULL;
+CREATE INDEX idx_access_logs_alert ON access_logs (timestamp) WHERE alert IS NOT NULL;
+
+-- Who is inside each zone now: a granted entry adds the employee, a granted
+-- exit removes them. Entering while inside, or leaving while not, is an
+-- anti-passback violation.
+CREATE TABLE access_zone_presence (
+    zone_id     BIGINT      NOT NULL REFERENCES access_zones (id) ON DELETE CASCADE,
+    employee_id BIGINT      NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+    entered_at  TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (zone_id, employee_id)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS organization_work_schedules;
+DROP TABLE IF EXISTS calendar_years;
+DROP TABLE IF EXISTS calendar_exceptions;
@@ -0,0 +1,35 @@
+-- Production calendar (производственный календарь)
+--
+-- A working day is Monday to Saturday on a six-day week and Monday to
+-- Friday on a five-day week, unless the calendar says otherwise: holidays
+-- are days off, and calendar_exceptions transfer days between working and
+-- non-working (a Saturday worked in place of a bridge day) or shorten a
+-- working day. A day before a holiday is shortened by one hour without an
+-- exception. An exception may apply to one week schedule only.
+
+CREATE TABLE calendar_exceptions (
+    id         BIGSERIAL PRIMARY KEY,
+    date       DATE        NOT NULL,
+    type       VARCHAR(10) NOT NULL CHECK (type IN ('working', 'day_off', 'short')),
+    week_days  SMALLINT    CHECK (week_days IN (5, 6)),
+    note       TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX uq_calendar_exceptions_date_week ON calendar_exceptions (date, COALESCE(week_days, 0));
+
+-- Years whose holidays and exceptions HR has published as final
+CREATE TABLE calendar_years (
+    year         INT PRIMARY KEY,
+    published_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    published_by BIGINT      REFERENCES contacts (id) ON DELETE SET NULL
+);
+
+-- Week schedule of an organization; organizations without a row work a
+-- six-day week of 8-hour days.
+CREATE TABLE organization_work_schedules (
+    organization_id BIGINT PRIMARY KEY REFERENCES organizations (id) ON DELETE CASCADE,
+    week_days       SMALLINT     NOT NULL CHECK (week_days IN (5, 6)),
+    day_hours       NUMERIC(4,2) NOT NULL CHECK (day_hours > 0 AND day_hours <= 12),
+    updated_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
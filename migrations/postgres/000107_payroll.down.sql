@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS employee_bank_accounts;
+DROP TABLE IF EXISTS payroll_rates;
+
+ALTER TABLE salaries
+    DROP COLUMN IF EXISTS from_timesheet,
+    DROP COLUMN IF EXISTS sick_amount,
+    DROP COLUMN IF EXISTS sick_days,
+    DROP COLUMN IF EXISTS night_amount,
+    DROP COLUMN IF EXISTS night_hours;
+
+ALTER TABLE timesheet_entries DROP COLUMN IF EXISTS night_hours;
@@ -0,0 +1,49 @@
+-- Payroll driven by the timesheet
+--
+-- Night hours (22:00-06:00) are tracked per timesheet entry and paid with a
+-- surcharge; sick days are paid at a share of the daily base salary. A
+-- salary calculated from the timesheet is recalculated while it is still a
+-- draft whenever the timesheet of its month changes.
+
+ALTER TABLE timesheet_entries ADD COLUMN night_hours DECIMAL(5,2);
+
+ALTER TABLE salaries
+    ADD COLUMN night_hours    DECIMAL(6,2)  NOT NULL DEFAULT 0,
+    ADD COLUMN night_amount   DECIMAL(12,2) NOT NULL DEFAULT 0,
+    ADD COLUMN sick_days      INTEGER       NOT NULL DEFAULT 0,
+    ADD COLUMN sick_amount    DECIMAL(12,2) NOT NULL DEFAULT 0,
+    ADD COLUMN from_timesheet BOOLEAN       NOT NULL DEFAULT FALSE;
+
+-- Tax, contribution and pay rates. The row in effect on a date is the one
+-- with the latest effective_from on or before it.
+CREATE TABLE payroll_rates (
+    id             BIGSERIAL PRIMARY KEY,
+    code           VARCHAR(20)  NOT NULL
+                   CHECK (code IN ('ndfl', 'social', 'pension', 'health', 'trade_union',
+                                   'overtime', 'night', 'sick_pay')),
+    rate           DECIMAL(7,4) NOT NULL CHECK (rate >= 0),
+    effective_from DATE         NOT NULL,
+    note           TEXT,
+    created_by     BIGINT       REFERENCES contacts (id) ON DELETE SET NULL,
+    created_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_payroll_rates_code_date UNIQUE (code, effective_from)
+);
+
+INSERT INTO payroll_rates (code, rate, effective_from, note) VALUES
+    ('ndfl',        0.12,  '2020-01-01', 'Налог на доходы физических лиц'),
+    ('social',      0.005, '2020-01-01', 'Социальный налог'),
+    ('pension',     0.03,  '2020-01-01', 'Взнос в пенсионный фонд'),
+    ('health',      0.005, '2020-01-01', 'Медицинское страхование'),
+    ('trade_union', 0.01,  '2020-01-01', 'Профсоюзный взнос'),
+    ('overtime',    1.5,   '2020-01-01', 'Множитель часовой ставки за сверхурочные'),
+    ('night',       0.5,   '2020-01-01', 'Доплата к часовой ставке за ночные часы'),
+    ('sick_pay',    0.6,   '2020-01-01', 'Доля дневной ставки за день больничного');
+
+-- Accounts salaries are paid to, for the bank payment register
+CREATE TABLE employee_bank_accounts (
+    employee_id    BIGINT PRIMARY KEY REFERENCES contacts (id) ON DELETE CASCADE,
+    bank_name      VARCHAR(255) NOT NULL,
+    bank_code      VARCHAR(5)   NOT NULL,
+    account_number VARCHAR(20)  NOT NULL,
+    updated_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS vacation_year_closings;
+DROP TABLE IF EXISTS vacation_ledger;
+DROP TABLE IF EXISTS leave_policy;
+DROP TABLE IF EXISTS leave_entitlement_rules;
+
+ALTER TABLE personnel_records DROP COLUMN IF EXISTS dismissal_date;
@@ -0,0 +1,76 @@
+-- Vacation accrual
+--
+-- Annual leave accrues monthly: a twelfth of the yearly entitlement for each
+-- month worked, prorated for the month of hire and of dismissal. The
+-- entitlement depends on position and seniority; work with a hazard
+-- allowance earns additional days. At year end what is left carries over up
+-- to a cap and the rest expires. Every movement is a ledger row, and
+-- vacation_balances holds the ledger sums.
+
+ALTER TABLE personnel_records ADD COLUMN dismissal_date DATE;
+
+-- Yearly entitlement. The rule for an employee is the one of their position
+-- (else the one for all positions) with the highest seniority they reached.
+CREATE TABLE leave_entitlement_rules (
+    id                  BIGSERIAL PRIMARY KEY,
+    position_id         BIGINT      REFERENCES positions (id) ON DELETE CASCADE,
+    min_seniority_years INTEGER     NOT NULL DEFAULT 0 CHECK (min_seniority_years >= 0),
+    annual_days         INTEGER     NOT NULL CHECK (annual_days BETWEEN 0 AND 366),
+    note                TEXT,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX uq_leave_entitlement_rules_position_seniority
+    ON leave_entitlement_rules (COALESCE(position_id, 0), min_seniority_years);
+
+INSERT INTO leave_entitlement_rules (position_id, min_seniority_years, annual_days, note)
+VALUES (NULL, 0, 15, 'Минимальный ежегодный отпуск по Трудовому кодексу');
+
+-- Policy shared by all employees; a single row
+CREATE TABLE leave_policy (
+    id             BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    hazard_days    INTEGER     NOT NULL DEFAULT 6 CHECK (hazard_days >= 0),
+    carry_over_cap INTEGER     NOT NULL DEFAULT 15 CHECK (carry_over_cap >= 0),
+    updated_by     BIGINT      REFERENCES contacts (id) ON DELETE SET NULL,
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO leave_policy DEFAULT VALUES;
+
+-- Days earned and lost. Usage is not stored: it is read from the vacations.
+CREATE TABLE vacation_ledger (
+    id          BIGSERIAL PRIMARY KEY,
+    employee_id BIGINT       NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+    year        INTEGER      NOT NULL,
+    entry_date  DATE         NOT NULL,
+    kind        VARCHAR(20)  NOT NULL
+                CHECK (kind IN ('opening', 'accrual', 'hazard', 'adjustment',
+                                'carry_over', 'carried_forward', 'expiry')),
+    days        DECIMAL(6,2) NOT NULL,
+    note        TEXT,
+    created_by  BIGINT       REFERENCES contacts (id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_vacation_ledger_employee_year ON vacation_ledger (employee_id, year, entry_date);
+CREATE INDEX idx_vacation_ledger_kind_date ON vacation_ledger (kind, entry_date);
+CREATE UNIQUE INDEX uq_vacation_ledger_system_rows
+    ON vacation_ledger (employee_id, kind, entry_date) WHERE kind <> 'adjustment';
+
+CREATE TABLE vacation_year_closings (
+    year      INTEGER PRIMARY KEY,
+    closed_by BIGINT      REFERENCES contacts (id) ON DELETE SET NULL,
+    closed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Balances entered by hand become opening rows, so they survive the first
+-- recount; years that have one are not accrued.
+INSERT INTO vacation_ledger (employee_id, year, entry_date, kind, days, note)
+SELECT employee_id, year, make_date(year, 1, 1), 'opening', total_days, 'Баланс до запуска начислений'
+FROM vacation_balances
+WHERE total_days <> 0;
+
+INSERT INTO vacation_ledger (employee_id, year, entry_date, kind, days, note)
+SELECT employee_id, year, make_date(year, 1, 1), 'carry_over', carried_over, 'Перенос до запуска начислений'
+FROM vacation_balances
+WHERE carried_over <> 0;
@@ -0,0 +1,22 @@
+CREATE OR REPLACE FUNCTION reservoir_flood_hourly_is_all_null(r reservoir_flood_hourly)
+RETURNS BOOLEAN
+LANGUAGE SQL IMMUTABLE AS $$
+    SELECT r.water_level_m       IS NULL
+       AND r.water_volume_mln_m3 IS NULL
+       AND r.inflow_m3s          IS NULL
+       AND r.outflow_m3s         IS NULL
+       AND r.ges_flow_m3s        IS NULL
+       AND r.idle_discharge_m3s  IS NULL
+       AND r.capacity_mwt        IS NULL
+       AND r.duty_name           IS NULL
+       AND r.weather_condition   IS NULL
+       AND r.temperature_c       IS NULL
+$$;
+
+ALTER TABLE duty_violations DROP COLUMN IF EXISTS duty_officer_id;
+ALTER TABLE reservoir_flood_hourly DROP COLUMN IF EXISTS duty_employee_id;
+
+DROP TABLE IF EXISTS duty_shift_swaps;
+DROP TABLE IF EXISTS duty_shifts;
+DROP TABLE IF EXISTS shift_pattern_members;
+DROP TABLE IF EXISTS shift_patterns;
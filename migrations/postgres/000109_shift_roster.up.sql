@@ -0,0 +1,114 @@
+-- Графики дежурств (shift roster) for stations.
+--
+-- A shift pattern describes one organization's rotation: the shift
+-- definitions (code, local start time, length) and a day-by-day cycle of
+-- shift codes ("-" = day off). Each member walks the cycle from its own
+-- offset, so a classic four-crew 12h day/night rotation is one pattern
+-- with cycle {D,N,-,-} and members at offsets 0..3.
+--
+-- duty_shifts is the materialized roster: generated from a pattern for a
+-- month, entered manually, or reassigned through an approved swap.
+
+CREATE TABLE shift_patterns (
+    id                 BIGSERIAL PRIMARY KEY,
+    organization_id    BIGINT NOT NULL
+                       REFERENCES organizations(id) ON DELETE CASCADE,
+    name               TEXT NOT NULL,
+    shifts             JSONB NOT NULL,
+    cycle              TEXT[] NOT NULL,
+    anchor_date        DATE NOT NULL,
+    created_by_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT shift_patterns_name_not_blank CHECK (length(trim(name)) > 0),
+    CONSTRAINT shift_patterns_cycle_not_empty CHECK (cardinality(cycle) > 0),
+    UNIQUE (organization_id, name)
+);
+
+CREATE TRIGGER set_timestamp_shift_patterns
+    BEFORE UPDATE ON shift_patterns
+    FOR EACH ROW EXECUTE FUNCTION trigger_set_timestamp();
+
+CREATE TABLE shift_pattern_members (
+    pattern_id  BIGINT NOT NULL REFERENCES shift_patterns(id) ON DELETE CASCADE,
+    employee_id BIGINT NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    offset_days INTEGER NOT NULL DEFAULT 0 CHECK (offset_days >= 0),
+    PRIMARY KEY (pattern_id, employee_id)
+);
+
+-- rostered_employee_id is who the pattern put on the shift; employee_id is
+-- who actually stands it (differs after an approved swap). Regenerating a
+-- month keys on the former, so a swapped shift is not generated twice.
+CREATE TABLE duty_shifts (
+    id                   BIGSERIAL PRIMARY KEY,
+    organization_id      BIGINT NOT NULL
+                         REFERENCES organizations(id) ON DELETE CASCADE,
+    pattern_id           BIGINT REFERENCES shift_patterns(id) ON DELETE SET NULL,
+    employee_id          BIGINT NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    rostered_employee_id BIGINT REFERENCES contacts(id) ON DELETE SET NULL,
+    shift_code           TEXT NOT NULL,
+    starts_at            TIMESTAMPTZ NOT NULL,
+    ends_at              TIMESTAMPTZ NOT NULL,
+    source               TEXT NOT NULL DEFAULT 'manual'
+                         CHECK (source IN ('generated', 'manual', 'swap')),
+    created_by_user_id   BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT duty_shifts_time_range CHECK (ends_at > starts_at)
+);
+
+CREATE UNIQUE INDEX idx_duty_shifts_pattern_slot
+    ON duty_shifts(pattern_id, rostered_employee_id, shift_code, starts_at)
+    WHERE pattern_id IS NOT NULL;
+CREATE INDEX idx_duty_shifts_org_time
+    ON duty_shifts(organization_id, starts_at);
+CREATE INDEX idx_duty_shifts_employee_time
+    ON duty_shifts(employee_id, starts_at);
+
+CREATE TRIGGER set_timestamp_duty_shifts
+    BEFORE UPDATE ON duty_shifts
+    FOR EACH ROW EXECUTE FUNCTION trigger_set_timestamp();
+
+CREATE TABLE duty_shift_swaps (
+    id                   BIGSERIAL PRIMARY KEY,
+    shift_id             BIGINT NOT NULL REFERENCES duty_shifts(id) ON DELETE CASCADE,
+    original_employee_id BIGINT NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    replacement_id       BIGINT NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    reason               TEXT,
+    status               TEXT NOT NULL DEFAULT 'pending'
+                         CHECK (status IN ('pending', 'approved', 'rejected')),
+    requested_by_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    decided_by_user_id   BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    decided_at           TIMESTAMPTZ,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One open request per shift at a time.
+CREATE UNIQUE INDEX idx_duty_shift_swaps_pending
+    ON duty_shift_swaps(shift_id) WHERE status = 'pending';
+CREATE INDEX idx_duty_shift_swaps_status ON duty_shift_swaps(status);
+
+-- Link free-text duty names to employees. The text columns stay as the
+-- fallback for history recorded before the roster existed.
+ALTER TABLE reservoir_flood_hourly
+    ADD COLUMN duty_employee_id BIGINT REFERENCES contacts(id) ON DELETE SET NULL;
+
+ALTER TABLE duty_violations
+    ADD COLUMN duty_officer_id BIGINT REFERENCES contacts(id) ON DELETE SET NULL;
+
+-- The empty-row prune (000079/000080) must treat a linked officer as data.
+CREATE OR REPLACE FUNCTION reservoir_flood_hourly_is_all_null(r reservoir_flood_hourly)
+RETURNS BOOLEAN
+LANGUAGE SQL IMMUTABLE AS $$
+    SELECT r.water_level_m       IS NULL
+       AND r.water_volume_mln_m3 IS NULL
+       AND r.inflow_m3s          IS NULL
+       AND r.outflow_m3s         IS NULL
+       AND r.ges_flow_m3s        IS NULL
+       AND r.idle_discharge_m3s  IS NULL
+       AND r.capacity_mwt        IS NULL
+       AND r.duty_name           IS NULL
+       AND r.duty_employee_id    IS NULL
+       AND r.weather_condition   IS NULL
+       AND r.temperature_c       IS NULL
+$$;
@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS hr_orders;
+
+ALTER TABLE onboarding_tasks
+    DROP COLUMN IF EXISTS completed_at,
+    DROP COLUMN IF EXISTS sort_order;
+
+DROP INDEX IF EXISTS idx_onboarding_tasks_onboarding_id;
+DROP INDEX IF EXISTS idx_onboardings_employee_id;
+
+ALTER TABLE onboardings
+    DROP COLUMN IF EXISTS offer_id,
+    DROP COLUMN IF EXISTS employee_id;
+
+DROP INDEX IF EXISTS idx_job_offers_candidate_id;
+
+ALTER TABLE job_offers
+    DROP COLUMN IF EXISTS decided_at;
@@ -0,0 +1,76 @@
+-- Employee lifecycle
+--
+-- Hire, transfer and dismissal each produce an HR order. A hire turns an
+-- accepted job offer into a contact, a personnel record, a salary structure,
+-- optionally a user account, and an onboarding checklist. Transfers and
+-- dismissals take effect on their date: until then the order is scheduled
+-- and nothing about the employee changes.
+
+ALTER TABLE job_offers
+    ADD COLUMN decided_at TIMESTAMPTZ;
+
+CREATE INDEX idx_job_offers_candidate_id ON job_offers (candidate_id);
+
+-- Onboardings are of the hired employee, not only of the candidate
+ALTER TABLE onboardings
+    ADD COLUMN employee_id BIGINT REFERENCES contacts (id) ON DELETE SET NULL,
+    ADD COLUMN offer_id    BIGINT REFERENCES job_offers (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_onboardings_employee_id ON onboardings (employee_id);
+CREATE INDEX idx_onboarding_tasks_onboarding_id ON onboarding_tasks (onboarding_id);
+
+ALTER TABLE onboarding_tasks
+    ADD COLUMN sort_order   INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN completed_at TIMESTAMPTZ;
+
+CREATE TABLE hr_orders (
+    id                 BIGSERIAL PRIMARY KEY,
+    kind               VARCHAR(20)  NOT NULL CHECK (kind IN ('hire', 'transfer', 'dismissal')),
+    year               INTEGER      NOT NULL,
+    number             VARCHAR(100) NOT NULL,
+    order_date         DATE         NOT NULL,
+    effective_date     DATE         NOT NULL,
+    status             VARCHAR(20)  NOT NULL DEFAULT 'scheduled'
+                       CHECK (status IN ('scheduled', 'applied', 'cancelled')),
+    employee_id        BIGINT       NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+    record_id          BIGINT       REFERENCES personnel_records (id) ON DELETE SET NULL,
+    document_id        BIGINT       REFERENCES hr_documents (id) ON DELETE SET NULL,
+
+    -- Hire
+    offer_id           BIGINT       REFERENCES job_offers (id) ON DELETE SET NULL,
+    onboarding_id      BIGINT       REFERENCES onboardings (id) ON DELETE SET NULL,
+    user_id            BIGINT       REFERENCES users (id) ON DELETE SET NULL,
+
+    -- Transfer: where from and to, and the salary structure from the
+    -- effective date
+    from_department_id BIGINT       REFERENCES departments (id) ON DELETE SET NULL,
+    to_department_id   BIGINT       REFERENCES departments (id) ON DELETE SET NULL,
+    from_position_id   BIGINT       REFERENCES positions (id) ON DELETE SET NULL,
+    to_position_id     BIGINT       REFERENCES positions (id) ON DELETE SET NULL,
+    salary             JSONB,
+    transfer_id        BIGINT       REFERENCES personnel_transfers (id) ON DELETE SET NULL,
+
+    -- Dismissal: the final settlement worked out when it took effect
+    settlement         JSONB,
+
+    reason             TEXT,
+    created_by         BIGINT       REFERENCES contacts (id) ON DELETE SET NULL,
+    applied_at         TIMESTAMPTZ,
+    created_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_hr_orders_year_number UNIQUE (year, number)
+);
+
+CREATE INDEX idx_hr_orders_employee_id ON hr_orders (employee_id);
+CREATE INDEX idx_hr_orders_status_effective ON hr_orders (status, effective_date);
+
+-- An offer is hired on once, and an employee has at most one transfer or
+-- dismissal waiting to take effect
+CREATE UNIQUE INDEX uq_hr_orders_offer ON hr_orders (offer_id) WHERE offer_id IS NOT NULL;
+CREATE UNIQUE INDEX uq_hr_orders_scheduled_employee ON hr_orders (employee_id) WHERE status = 'scheduled';
+
+CREATE TRIGGER set_timestamp_hr_orders
+    BEFORE UPDATE ON hr_orders
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
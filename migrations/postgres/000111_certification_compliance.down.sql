@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_certificates_type_employee;
+
+ALTER TABLE certificates
+    DROP COLUMN IF EXISTS expiry_alert,
+    DROP COLUMN IF EXISTS certification_type_id;
+
+DROP INDEX IF EXISTS idx_trainings_certification_type;
+
+ALTER TABLE trainings
+    DROP COLUMN IF EXISTS certification_type_id;
+
+DROP TABLE IF EXISTS position_certifications;
+DROP TABLE IF EXISTS certification_types;
@@ -0,0 +1,50 @@
+-- Certification compliance
+--
+-- Positions require certifications (electrical safety group, work-at-height
+-- permit, ...) that are valid for a number of months. A certificate of a
+-- certification type is issued by a training granting it or registered by
+-- hand; its expiry date follows from the validity of the type. Holders are
+-- alerted ahead of expiry, once when it draws near and once when it passes.
+
+CREATE TABLE certification_types (
+    id              BIGSERIAL PRIMARY KEY,
+    name            VARCHAR(255) NOT NULL UNIQUE,
+    description     TEXT,
+    validity_months INTEGER CHECK (validity_months IS NULL OR validity_months > 0),
+    alert_days      INTEGER NOT NULL DEFAULT 30 CHECK (alert_days >= 0),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN certification_types.validity_months IS 'Срок действия, мес.; NULL — бессрочно';
+COMMENT ON COLUMN certification_types.alert_days IS 'За сколько дней до истечения предупреждать';
+
+CREATE TRIGGER set_timestamp_certification_types
+    BEFORE UPDATE ON certification_types
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+-- A type required by positions cannot be deleted until it is no longer
+CREATE TABLE position_certifications (
+    position_id           BIGINT NOT NULL REFERENCES positions (id) ON DELETE CASCADE,
+    certification_type_id BIGINT NOT NULL REFERENCES certification_types (id) ON DELETE RESTRICT,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (position_id, certification_type_id)
+);
+
+CREATE INDEX idx_position_certifications_type ON position_certifications (certification_type_id);
+
+ALTER TABLE trainings
+    ADD COLUMN certification_type_id BIGINT REFERENCES certification_types (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_trainings_certification_type ON trainings (certification_type_id, start_date)
+    WHERE certification_type_id IS NOT NULL;
+
+ALTER TABLE certificates
+    ADD COLUMN certification_type_id BIGINT REFERENCES certification_types (id) ON DELETE SET NULL,
+    ADD COLUMN expiry_alert          VARCHAR(10) CHECK (expiry_alert IN ('expiring', 'expired'));
+
+COMMENT ON COLUMN certificates.expiry_alert IS 'Последнее отправленное предупреждение об истечении';
+
+CREATE INDEX idx_certificates_type_employee ON certificates (certification_type_id, employee_id)
+    WHERE certification_type_id IS NOT NULL;